@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -23,12 +22,14 @@ type Config struct {
 	ReadTimeout    time.Duration
 	BufferSize     int
 	Logger         *logrus.Logger
+	// Transport opens the event stream. Defaults to an HTTPTransport.
+	Transport Transport
 }
 
 type Client struct {
-	config     Config
-	httpClient *http.Client
-	logger     *logrus.Logger
+	config    Config
+	transport Transport
+	logger    *logrus.Logger
 }
 
 type Frame struct {
@@ -59,26 +60,15 @@ func NewClient(config Config) *Client {
 		config.BufferSize = 100
 	}
 
-	transport := &http.Transport{
-		DisableCompression:    true,
-		ExpectContinueTimeout: 0,
-		ResponseHeaderTimeout: config.ConnectTimeout,
-		DisableKeepAlives:     false,
-		MaxIdleConns:          1,
-		MaxIdleConnsPerHost:   1,
-		IdleConnTimeout:       90 * time.Second,
-		TLSHandshakeTimeout:   10 * time.Second,
-	}
-
-	httpClient := &http.Client{
-		Transport: transport,
-		Timeout:   0,
+	transport := config.Transport
+	if transport == nil {
+		transport = NewHTTPTransport(nil, config.ConnectTimeout)
 	}
 
 	return &Client{
-		config:     config,
-		httpClient: httpClient,
-		logger:     config.Logger,
+		config:    config,
+		transport: transport,
+		logger:    config.Logger,
 	}
 }
 
@@ -98,23 +88,17 @@ func (c *Client) stream(opts StreamOptions) (<-chan Frame, <-chan error, error)
 		return nil, nil, fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(
-		opts.Context,
-		http.MethodPost,
-		c.config.Endpoint,
-		bytes.NewReader(payloadBytes),
-	)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	headers := map[string]string{
+		"Content-Type":  "application/json",
+		"Accept":        "text/event-stream",
+		"Cache-Control": "no-cache",
+		"Connection":    "keep-alive",
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "text/event-stream")
-	req.Header.Set("Cache-Control", "no-cache")
-	req.Header.Set("Connection", "keep-alive")
-
 	if c.config.APIKey != "" {
-		authHeader := c.config.AuthHeader
+		// The key is canonical, as those of the request headers are, so that
+		// a request header overrides it whatever the case of either
+		authHeader := http.CanonicalHeaderKey(c.config.AuthHeader)
 		if authHeader == "" {
 			authHeader = "Authorization"
 		}
@@ -126,60 +110,48 @@ func (c *Client) stream(opts StreamOptions) (<-chan Frame, <-chan error, error)
 			if c.config.AuthScheme != "" {
 				scheme = c.config.AuthScheme
 			}
-			req.Header.Set(authHeader, scheme+" "+c.config.APIKey)
+			headers[authHeader] = scheme + " " + c.config.APIKey
 		} else {
 			// For custom headers like X-API-Key, use the key directly
-			req.Header.Set(authHeader, c.config.APIKey)
+			headers[authHeader] = c.config.APIKey
 		}
 	}
 
 	for key, value := range opts.Headers {
-		req.Header.Set(key, value)
+		headers[http.CanonicalHeaderKey(key)] = value
 	}
 
 	if c.logger != nil {
 		c.logger.WithFields(logrus.Fields{
 			"endpoint": c.config.Endpoint,
-			"method":   req.Method,
-			"headers":  req.Header,
+			"headers":  headers,
 		}).Debug("Initiating SSE connection")
 	}
 
-	resp, err := c.httpClient.Do(req)
+	body, err := c.transport.Do(opts.Context, TransportInput{
+		Endpoint: c.config.Endpoint,
+		Payload:  payloadBytes,
+		Headers:  headers,
+	})
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		_ = resp.Body.Close()
-		return nil, nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
-	}
-
-	contentType := resp.Header.Get("Content-Type")
-	if !strings.HasPrefix(contentType, "text/event-stream") {
-		_ = resp.Body.Close()
-		return nil, nil, fmt.Errorf("unexpected content-type: %s", contentType)
+		return nil, nil, err
 	}
 
 	if c.logger != nil {
-		c.logger.WithFields(logrus.Fields{
-			"status":       resp.StatusCode,
-			"content_type": contentType,
-		}).Info("SSE connection established")
+		c.logger.WithField("endpoint", c.config.Endpoint).Info("SSE connection established")
 	}
 
 	frames := make(chan Frame, c.config.BufferSize)
 	errors := make(chan error, 1)
 
-	go c.readStream(opts.Context, resp, frames, errors)
+	go c.readStream(opts.Context, body, frames, errors)
 
 	return frames, errors, nil
 }
 
-func (c *Client) readStream(ctx context.Context, resp *http.Response, frames chan<- Frame, errors chan<- error) {
+func (c *Client) readStream(ctx context.Context, body io.ReadCloser, frames chan<- Frame, errors chan<- error) {
 	defer func() {
-		_ = resp.Body.Close()
+		_ = body.Close()
 		close(frames)
 		close(errors)
 		if c.logger != nil {
@@ -187,7 +159,7 @@ func (c *Client) readStream(ctx context.Context, resp *http.Response, frames cha
 		}
 	}()
 
-	reader := bufio.NewReader(resp.Body)
+	reader := bufio.NewReader(body)
 	var buffer bytes.Buffer
 	var frameCount int64
 	var byteCount int64
@@ -304,6 +276,8 @@ func (c *Client) readStream(ctx context.Context, resp *http.Response, frames cha
 }
 
 func (c *Client) Close() error {
-	c.httpClient.CloseIdleConnections()
+	if closer, ok := c.transport.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
 	return nil
 }
@@ -498,7 +498,7 @@ func TestReadStream(t *testing.T) {
 		frames := make(chan Frame, 10)
 		errors := make(chan error, 1)
 		
-		go client.readStream(context.Background(), resp, frames, errors)
+		go client.readStream(context.Background(), resp.Body, frames, errors)
 		
 		// Write some data then close
 		go func() {
@@ -537,7 +537,7 @@ func TestReadStream(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
 		defer cancel()
 		
-		go client.readStream(ctx, resp, frames, errors)
+		go client.readStream(ctx, resp.Body, frames, errors)
 		
 		// Write data with carriage returns
 		go func() {
@@ -567,7 +567,7 @@ func TestReadStream(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
 		defer cancel()
 		
-		go client.readStream(ctx, resp, frames, errors)
+		go client.readStream(ctx, resp.Body, frames, errors)
 		
 		go func() {
 			// Multiple empty lines should be ignored
@@ -597,7 +597,7 @@ func TestReadStream(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
 		defer cancel()
 		
-		go client.readStream(ctx, resp, frames, errors)
+		go client.readStream(ctx, resp.Body, frames, errors)
 		
 		go func() {
 			// Lines without "data: " prefix should be ignored
@@ -649,7 +649,7 @@ func TestReadStreamWithErrors(t *testing.T) {
 		frames := make(chan Frame, 10)
 		errors := make(chan error, 1)
 		
-		go client.readStream(context.Background(), resp, frames, errors)
+		go client.readStream(context.Background(), resp.Body, frames, errors)
 		
 		select {
 		case err := <-errors:
@@ -724,7 +724,7 @@ func BenchmarkReadStream(b *testing.B) {
 		client := NewClient(Config{})
 		
 		ctx, cancel := context.WithCancel(context.Background())
-		go client.readStream(ctx, resp, frames, errors)
+		go client.readStream(ctx, resp.Body, frames, errors)
 		
 		count := 0
 		for range frames {
@@ -40,8 +40,8 @@ func TestNewClient(t *testing.T) {
 			if client == nil {
 				t.Fatal("expected non-nil client")
 			}
-			if client.httpClient == nil {
-				t.Fatal("expected non-nil http client")
+			if client.transport == nil {
+				t.Fatal("expected non-nil transport")
 			}
 			if client.logger == nil {
 				t.Fatal("expected non-nil logger")
@@ -0,0 +1,88 @@
+package sse
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TransportInput describes a single streaming request handed to a Transport
+type TransportInput struct {
+	Endpoint string
+	Payload  []byte
+	Headers  map[string]string
+}
+
+// Transport opens the raw event stream for a request. The client parses
+// the returned body as SSE and closes it when the stream ends, so a
+// transport only needs to deliver bytes (for example over a proxy or
+// gRPC-web).
+type Transport interface {
+	Do(ctx context.Context, input TransportInput) (io.ReadCloser, error)
+}
+
+// HTTPTransport is the default Transport which POSTs the payload over
+// net/http and expects a text/event-stream response
+type HTTPTransport struct {
+	client *http.Client
+}
+
+// NewHTTPTransport creates an HTTPTransport. A nil client uses one tuned
+// for long-lived streams with the given response header timeout.
+func NewHTTPTransport(client *http.Client, connectTimeout time.Duration) *HTTPTransport {
+	if client == nil {
+		client = &http.Client{
+			Transport: &http.Transport{
+				DisableCompression:    true,
+				ExpectContinueTimeout: 0,
+				ResponseHeaderTimeout: connectTimeout,
+				DisableKeepAlives:     false,
+				MaxIdleConns:          1,
+				MaxIdleConnsPerHost:   1,
+				IdleConnTimeout:       90 * time.Second,
+				TLSHandshakeTimeout:   10 * time.Second,
+			},
+			Timeout: 0,
+		}
+	}
+	return &HTTPTransport{client: client}
+}
+
+// Do executes the request and returns the response body
+func (t *HTTPTransport) Do(ctx context.Context, input TransportInput) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, input.Endpoint, bytes.NewReader(input.Payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, value := range input.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "text/event-stream") {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("unexpected content-type: %s", contentType)
+	}
+
+	return resp.Body, nil
+}
+
+// CloseIdleConnections closes idle connections of the underlying client
+func (t *HTTPTransport) CloseIdleConnections() {
+	t.client.CloseIdleConnections()
+}
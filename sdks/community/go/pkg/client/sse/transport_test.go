@@ -0,0 +1,139 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTransport returns a canned stream and records the request
+type fakeTransport struct {
+	stream string
+	err    error
+	ctx    context.Context
+	input  TransportInput
+	closed bool
+}
+
+func (f *fakeTransport) Do(ctx context.Context, input TransportInput) (io.ReadCloser, error) {
+	f.ctx = ctx
+	f.input = input
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &trackedBody{Reader: strings.NewReader(f.stream), closed: &f.closed}, nil
+}
+
+type trackedBody struct {
+	io.Reader
+	closed *bool
+}
+
+func (b *trackedBody) Close() error {
+	*b.closed = true
+	return nil
+}
+
+func TestClientTransport(t *testing.T) {
+	t.Run("canned stream", func(t *testing.T) {
+		transport := &fakeTransport{
+			stream: "data: {\"type\":\"RUN_STARTED\"}\n\n" +
+				": comment\n" +
+				"data: {\"type\":\n" +
+				"data: \"RUN_FINISHED\"}\n\n",
+		}
+		client := NewClient(Config{
+			Endpoint:  "grpc-web://agent",
+			APIKey:    "secret",
+			Transport: transport,
+		})
+
+		ctx := context.WithValue(context.Background(), struct{}{}, "marker")
+		frames, errs, err := client.Stream(StreamOptions{
+			Context: ctx,
+			Payload: map[string]string{"threadId": "t1"},
+			Headers: map[string]string{"x-trace": "abc"},
+		})
+		require.NoError(t, err)
+
+		var data []string
+		for frame := range frames {
+			data = append(data, string(frame.Data))
+		}
+		for err := range errs {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		assert.Equal(t, []string{`{"type":"RUN_STARTED"}`, "{\"type\":\n\"RUN_FINISHED\"}"}, data)
+		assert.True(t, transport.closed)
+		assert.Equal(t, ctx, transport.ctx)
+		assert.Equal(t, "grpc-web://agent", transport.input.Endpoint)
+		assert.JSONEq(t, `{"threadId":"t1"}`, string(transport.input.Payload))
+		assert.Equal(t, "Bearer secret", transport.input.Headers["Authorization"])
+		assert.Equal(t, "text/event-stream", transport.input.Headers["Accept"])
+		assert.Equal(t, "abc", transport.input.Headers["X-Trace"])
+	})
+
+	t.Run("auth header case", func(t *testing.T) {
+		transport := &fakeTransport{}
+		client := NewClient(Config{
+			APIKey:     "secret",
+			AuthHeader: "x-api-key",
+			Transport:  transport,
+		})
+
+		_, errs, err := client.Stream(StreamOptions{
+			Payload: map[string]string{},
+			Headers: map[string]string{"X-API-KEY": "override"},
+		})
+		require.NoError(t, err)
+		for range errs {
+		}
+
+		assert.Equal(t, map[string]string{"X-Api-Key": "override"}, authHeaders(transport.input.Headers))
+
+		// A lowercase Authorization header still gets the scheme
+		transport = &fakeTransport{}
+		client = NewClient(Config{APIKey: "secret", AuthHeader: "authorization", Transport: transport})
+		_, errs, err = client.Stream(StreamOptions{Payload: map[string]string{}})
+		require.NoError(t, err)
+		for range errs {
+		}
+		assert.Equal(t, map[string]string{"Authorization": "Bearer secret"}, authHeaders(transport.input.Headers))
+	})
+
+	t.Run("transport error", func(t *testing.T) {
+		failure := errors.New("proxy refused connection")
+		client := NewClient(Config{Transport: &fakeTransport{err: failure}})
+
+		frames, errs, err := client.Stream(StreamOptions{Payload: map[string]string{}})
+		assert.ErrorIs(t, err, failure)
+		assert.Nil(t, frames)
+		assert.Nil(t, errs)
+	})
+
+	t.Run("default transport", func(t *testing.T) {
+		client := NewClient(Config{})
+		_, ok := client.transport.(*HTTPTransport)
+		assert.True(t, ok)
+		assert.NoError(t, client.Close())
+	})
+}
+
+// authHeaders returns the request headers other than the fixed SSE ones
+func authHeaders(headers map[string]string) map[string]string {
+	auth := make(map[string]string)
+	for key, value := range headers {
+		switch key {
+		case "Content-Type", "Accept", "Cache-Control", "Connection":
+		default:
+			auth[key] = value
+		}
+	}
+	return auth
+}
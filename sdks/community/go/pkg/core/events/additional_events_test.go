@@ -2,6 +2,7 @@ package events
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -188,6 +189,79 @@ func TestToolCallChunkEvent(t *testing.T) {
 		assert.Equal(t, "{\"query\":", decoded["delta"])
 		assert.Equal(t, "msg-789", decoded["parentMessageId"])
 	})
+
+	t.Run("SplitToStartArgsEnd", func(t *testing.T) {
+		// Every combination of set and unset fields
+		for mask := 0; mask < 16; mask++ {
+			hasID, hasName, hasParent, hasDelta := mask&1 != 0, mask&2 != 0, mask&4 != 0, mask&8 != 0
+
+			chunk := NewToolCallChunkEvent()
+			chunk.SetTimestamp(1234)
+			if hasID {
+				chunk.WithToolCallChunkID("tool-1")
+			}
+			if hasName {
+				chunk.WithToolCallChunkName("get_weather")
+			}
+			if hasParent {
+				chunk.WithToolCallChunkParentMessageID("msg-1")
+			}
+			if hasDelta {
+				chunk.WithToolCallChunkDelta(`{"city":"SF"}`)
+			}
+
+			starts, args, ends := chunk.SplitToStartArgsEnd()
+			name := fmt.Sprintf("id=%v name=%v parent=%v delta=%v", hasID, hasName, hasParent, hasDelta)
+
+			if !hasID {
+				assert.Empty(t, starts, name)
+				assert.Empty(t, args, name)
+				assert.Empty(t, ends, name)
+				continue
+			}
+
+			if hasName {
+				require.Len(t, starts, 1, name)
+				assert.Equal(t, "tool-1", starts[0].ToolCallID, name)
+				assert.Equal(t, "get_weather", starts[0].ToolCallName, name)
+				if hasParent {
+					require.NotNil(t, starts[0].ParentMessageID, name)
+					assert.Equal(t, "msg-1", *starts[0].ParentMessageID, name)
+				} else {
+					assert.Nil(t, starts[0].ParentMessageID, name)
+				}
+				assert.Equal(t, int64(1234), *starts[0].Timestamp(), name)
+				assert.NoError(t, starts[0].Validate(), name)
+			} else {
+				assert.Empty(t, starts, name)
+			}
+
+			if hasDelta {
+				require.Len(t, args, 1, name)
+				assert.Equal(t, "tool-1", args[0].ToolCallID, name)
+				assert.Equal(t, `{"city":"SF"}`, args[0].Delta, name)
+				assert.Equal(t, int64(1234), *args[0].Timestamp(), name)
+				assert.NoError(t, args[0].Validate(), name)
+			} else {
+				assert.Empty(t, args, name)
+			}
+
+			require.Len(t, ends, 1, name)
+			assert.Equal(t, "tool-1", ends[0].ToolCallID, name)
+			assert.Equal(t, int64(1234), *ends[0].Timestamp(), name)
+			assert.NoError(t, ends[0].Validate(), name)
+		}
+
+		// An empty delta produces no args event, which would not validate
+		_, args, _ := NewToolCallChunkEvent().WithToolCallChunkID("tool-1").WithToolCallChunkDelta("").SplitToStartArgsEnd()
+		assert.Empty(t, args)
+
+		// The split events do not share memory with the chunk
+		chunk := NewToolCallChunkEvent().WithToolCallChunkID("tool-1").WithToolCallChunkName("search").WithToolCallChunkParentMessageID("msg-1")
+		starts, _, _ := chunk.SplitToStartArgsEnd()
+		*starts[0].ParentMessageID = "changed"
+		assert.Equal(t, "msg-1", *chunk.ParentMessageID)
+	})
 }
 
 func TestToolCallResultEvent(t *testing.T) {
@@ -517,6 +591,73 @@ func TestStepEvents_ToJSON(t *testing.T) {
 	})
 }
 
+func TestThreadEvents_ToJSON(t *testing.T) {
+	t.Run("ThreadCreatedEvent", func(t *testing.T) {
+		event := NewThreadCreatedEvent("thread-1", WithThreadCreatedMetadata(map[string]any{"title": "Trip planning"}))
+
+		jsonData, err := event.ToJSON()
+		require.NoError(t, err)
+
+		var decoded map[string]interface{}
+		err = json.Unmarshal(jsonData, &decoded)
+		require.NoError(t, err)
+
+		assert.Equal(t, string(EventTypeThreadCreated), decoded["type"])
+		assert.Equal(t, "thread-1", decoded["threadId"])
+		assert.Equal(t, map[string]interface{}{"title": "Trip planning"}, decoded["metadata"])
+	})
+
+	t.Run("ThreadDeletedEvent", func(t *testing.T) {
+		event := NewThreadDeletedEvent("thread-1")
+
+		jsonData, err := event.ToJSON()
+		require.NoError(t, err)
+
+		var decoded map[string]interface{}
+		err = json.Unmarshal(jsonData, &decoded)
+		require.NoError(t, err)
+
+		assert.Equal(t, string(EventTypeThreadDeleted), decoded["type"])
+		assert.Equal(t, "thread-1", decoded["threadId"])
+		assert.NotContains(t, decoded, "metadata")
+	})
+}
+
+func TestThreadEvents_Validate(t *testing.T) {
+	assert.NoError(t, NewThreadCreatedEvent("thread-1").Validate())
+	assert.NoError(t, NewThreadDeletedEvent("thread-1", WithThreadDeletedMetadata(map[string]any{"reason": "expired"})).Validate())
+	assert.Error(t, NewThreadCreatedEvent("").Validate())
+	assert.Error(t, NewThreadDeletedEvent("").Validate())
+
+	event := NewThreadCreatedEvent("", WithAutoThreadIDCreated())
+	assert.NoError(t, event.Validate())
+	assert.True(t, strings.HasPrefix(event.ThreadID(), "thread-"))
+}
+
+func TestMetadataEvent(t *testing.T) {
+	data := map[string]any{"theme": "dark", "flags": map[string]any{"beta": true}}
+	event := NewMetadataEvent(data)
+	assert.NoError(t, event.Validate())
+
+	// The event owns its data
+	data["flags"].(map[string]any)["beta"] = false
+	assert.Equal(t, true, event.Data["flags"].(map[string]any)["beta"])
+
+	jsonData, err := event.ToJSON()
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	err = json.Unmarshal(jsonData, &decoded)
+	require.NoError(t, err)
+
+	assert.Equal(t, string(EventTypeMetadata), decoded["type"])
+	assert.Equal(t, map[string]interface{}{"theme": "dark", "flags": map[string]interface{}{"beta": true}}, decoded["data"])
+	assert.Equal(t, `METADATA(keys="flags,theme")`, event.String())
+
+	assert.NoError(t, NewMetadataEvent(map[string]any{}).Validate())
+	assert.Error(t, NewMetadataEvent(nil).Validate())
+}
+
 func TestTextMessageEndEvent_ToJSON(t *testing.T) {
 	event := NewTextMessageEndEvent("msg-123")
 
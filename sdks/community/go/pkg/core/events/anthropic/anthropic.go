@@ -0,0 +1,291 @@
+// Package anthropic converts Anthropic Messages API streams into AG-UI
+// events.
+package anthropic
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// streamEvent is the payload of an Anthropic stream event. Each event type
+// fills in a different subset of the fields.
+type streamEvent struct {
+	Type         string        `json:"type"`
+	Message      *message      `json:"message,omitempty"`
+	Index        int           `json:"index"`
+	ContentBlock *contentBlock `json:"content_block,omitempty"`
+	Delta        *blockDelta   `json:"delta,omitempty"`
+	Error        *streamError  `json:"error,omitempty"`
+}
+
+// message is the message announced by a message_start event
+type message struct {
+	ID   string `json:"id"`
+	Role string `json:"role"`
+}
+
+// contentBlock is the block opened by a content_block_start event
+type contentBlock struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Thinking string `json:"thinking,omitempty"`
+}
+
+// blockDelta is the delta of a content_block_delta event
+type blockDelta struct {
+	Type        string `json:"type"`
+	Text        string `json:"text,omitempty"`
+	PartialJSON string `json:"partial_json,omitempty"`
+	Thinking    string `json:"thinking,omitempty"`
+}
+
+// streamError is the error of an error event
+type streamError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// Converter converts the events of one Anthropic message stream into AG-UI
+// events. Anthropic announces the message ID only in message_start and the
+// kind of a content block only in content_block_start, so a converter keeps
+// track of the current message and its open blocks; use a new converter for
+// every stream. It is not safe for concurrent use.
+type Converter struct {
+	threadID string
+	runID    string
+
+	messageID string
+	blocks    map[int]contentBlock
+}
+
+// ConverterOption defines options for creating converters
+type ConverterOption func(*Converter)
+
+// WithThreadID sets the thread ID of the RUN_FINISHED event produced when the
+// message stops. It defaults to the message ID.
+func WithThreadID(threadID string) ConverterOption {
+	return func(c *Converter) {
+		c.threadID = threadID
+	}
+}
+
+// WithRunID sets the run ID of the RUN_FINISHED and RUN_ERROR events. It
+// defaults to the message ID.
+func WithRunID(runID string) ConverterOption {
+	return func(c *Converter) {
+		c.runID = runID
+	}
+}
+
+// NewConverter creates a converter for one Anthropic message stream
+func NewConverter(options ...ConverterOption) *Converter {
+	c := &Converter{blocks: make(map[int]contentBlock)}
+
+	for _, opt := range options {
+		opt(c)
+	}
+
+	return c
+}
+
+// FromAnthropicEvent converts one server-sent event of an Anthropic message
+// stream, given its event name and data, into AG-UI events:
+//
+//   - message_start produces a TEXT_MESSAGE_START
+//   - text deltas produce TEXT_MESSAGE_CONTENT events
+//   - a tool_use block produces a TOOL_CALL_START, its input_json deltas
+//     TOOL_CALL_ARGS events and its end a TOOL_CALL_END
+//   - a thinking block produces THINKING_START and
+//     THINKING_TEXT_MESSAGE_START, its thinking deltas
+//     THINKING_TEXT_MESSAGE_CONTENT events, and its end
+//     THINKING_TEXT_MESSAGE_END and THINKING_END
+//   - message_stop produces a TEXT_MESSAGE_END followed by a RUN_FINISHED
+//   - an error event produces a RUN_ERROR
+//
+// Keepalive pings, message_delta events, signature deltas, redacted
+// thinking and unknown event types produce no events. When eventName is
+// empty, the event type is taken from the data.
+//
+// It is a method rather than a package-level function because deltas and
+// block ends carry neither the message ID nor the kind of their block, which
+// only the converter remembers from the message_start and
+// content_block_start events before them.
+func (c *Converter) FromAnthropicEvent(eventName string, data []byte) ([]events.Event, error) {
+	if eventName == "ping" {
+		return nil, nil
+	}
+
+	var event streamEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("invalid Anthropic %s event: %w", eventName, err)
+	}
+	if eventName == "" {
+		eventName = event.Type
+	}
+	if event.Type != "" && event.Type != eventName {
+		return nil, fmt.Errorf("invalid Anthropic %s event: data has type %q", eventName, event.Type)
+	}
+
+	switch eventName {
+	case "ping", "message_delta":
+		return nil, nil
+
+	case "message_start":
+		if event.Message == nil || event.Message.ID == "" {
+			return nil, fmt.Errorf("invalid Anthropic message_start event: message id is required")
+		}
+		c.messageID = event.Message.ID
+		role := event.Message.Role
+		if role == "" {
+			role = "assistant"
+		}
+		return []events.Event{events.NewTextMessageStartEvent(c.messageID, events.WithRole(role))}, nil
+
+	case "content_block_start":
+		if err := c.requireMessage(eventName); err != nil {
+			return nil, err
+		}
+		if event.ContentBlock == nil {
+			return nil, fmt.Errorf("invalid Anthropic content_block_start event: content_block is required")
+		}
+		return c.startBlock(event.Index, *event.ContentBlock), nil
+
+	case "content_block_delta":
+		if err := c.requireMessage(eventName); err != nil {
+			return nil, err
+		}
+		if event.Delta == nil {
+			return nil, fmt.Errorf("invalid Anthropic content_block_delta event: delta is required")
+		}
+		return c.blockDelta(event.Index, *event.Delta)
+
+	case "content_block_stop":
+		if err := c.requireMessage(eventName); err != nil {
+			return nil, err
+		}
+		return c.stopBlock(event.Index), nil
+
+	case "message_stop":
+		if err := c.requireMessage(eventName); err != nil {
+			return nil, err
+		}
+		return []events.Event{
+			events.NewTextMessageEndEvent(c.messageID),
+			events.NewRunFinishedEvent(c.thread(), c.run()),
+		}, nil
+
+	case "error":
+		if event.Error == nil {
+			return nil, fmt.Errorf("invalid Anthropic error event: error is required")
+		}
+		options := []events.RunErrorOption{events.WithErrorCode(event.Error.Type)}
+		if run := c.run(); run != "" {
+			options = append(options, events.WithRunID(run))
+		}
+		return []events.Event{events.NewRunErrorEvent(event.Error.Message, options...)}, nil
+
+	default:
+		// Anthropic may add event types, which clients should ignore
+		return nil, nil
+	}
+}
+
+// startBlock records a content block and returns the events that open it
+func (c *Converter) startBlock(index int, block contentBlock) []events.Event {
+	c.blocks[index] = block
+
+	switch block.Type {
+	case "text":
+		if block.Text == "" {
+			return nil
+		}
+		return []events.Event{events.NewTextMessageContentEvent(c.messageID, block.Text)}
+	case "tool_use":
+		return []events.Event{events.NewToolCallStartEvent(block.ID, block.Name, events.WithParentMessageID(c.messageID))}
+	case "thinking":
+		result := []events.Event{events.NewThinkingStartEvent(), events.NewThinkingTextMessageStartEvent()}
+		if block.Thinking != "" {
+			result = append(result, events.NewThinkingTextMessageContentEvent(block.Thinking))
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// blockDelta returns the events for a delta of an open content block
+func (c *Converter) blockDelta(index int, delta blockDelta) ([]events.Event, error) {
+	block, ok := c.blocks[index]
+	if !ok {
+		return nil, fmt.Errorf("invalid Anthropic content_block_delta event: content block %d was not started", index)
+	}
+
+	switch delta.Type {
+	case "text_delta":
+		if delta.Text == "" {
+			return nil, nil
+		}
+		return []events.Event{events.NewTextMessageContentEvent(c.messageID, delta.Text)}, nil
+	case "input_json_delta":
+		if block.Type != "tool_use" {
+			return nil, fmt.Errorf("invalid Anthropic content_block_delta event: input_json_delta for %s block %d", block.Type, index)
+		}
+		if delta.PartialJSON == "" {
+			return nil, nil
+		}
+		return []events.Event{events.NewToolCallArgsEvent(block.ID, delta.PartialJSON)}, nil
+	case "thinking_delta":
+		if delta.Thinking == "" {
+			return nil, nil
+		}
+		return []events.Event{events.NewThinkingTextMessageContentEvent(delta.Thinking)}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// stopBlock forgets a content block and returns the events that close it
+func (c *Converter) stopBlock(index int) []events.Event {
+	block, ok := c.blocks[index]
+	if !ok {
+		return nil
+	}
+	delete(c.blocks, index)
+
+	switch block.Type {
+	case "tool_use":
+		return []events.Event{events.NewToolCallEndEvent(block.ID)}
+	case "thinking":
+		return []events.Event{events.NewThinkingTextMessageEndEvent(), events.NewThinkingEndEvent()}
+	default:
+		return nil
+	}
+}
+
+// requireMessage fails if no message_start event has been converted yet
+func (c *Converter) requireMessage(eventName string) error {
+	if c.messageID == "" {
+		return fmt.Errorf("invalid Anthropic %s event: no message_start received", eventName)
+	}
+	return nil
+}
+
+// thread returns the thread ID of the run
+func (c *Converter) thread() string {
+	if c.threadID != "" {
+		return c.threadID
+	}
+	return c.messageID
+}
+
+// run returns the run ID of the run
+func (c *Converter) run() string {
+	if c.runID != "" {
+		return c.runID
+	}
+	return c.messageID
+}
@@ -0,0 +1,153 @@
+package anthropic
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// convertStream converts every frame of a captured Anthropic stream
+func convertStream(t *testing.T, name string, converter *Converter) []events.Event {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+
+	var result []events.Event
+	var eventName string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if value, ok := bytes.CutPrefix(line, []byte("event: ")); ok {
+			eventName = string(value)
+		}
+		if payload, ok := bytes.CutPrefix(line, []byte("data: ")); ok {
+			converted, err := converter.FromAnthropicEvent(eventName, payload)
+			require.NoError(t, err)
+			result = append(result, converted...)
+		}
+	}
+	require.NoError(t, scanner.Err())
+
+	for _, event := range result {
+		require.NoError(t, event.Validate())
+	}
+	return result
+}
+
+// eventTypes returns the types of the events
+func eventTypes(evts []events.Event) []events.EventType {
+	types := make([]events.EventType, len(evts))
+	for i, event := range evts {
+		types[i] = event.Type()
+	}
+	return types
+}
+
+func TestFromAnthropicEvent(t *testing.T) {
+	t.Run("CapturedStream", func(t *testing.T) {
+		result := convertStream(t, "testdata/tool_use_stream.txt", NewConverter(WithThreadID("thread-1"), WithRunID("run-1")))
+		require.Equal(t, []events.EventType{
+			events.EventTypeTextMessageStart,
+			events.EventTypeThinkingStart,
+			events.EventTypeThinkingTextMessageStart,
+			events.EventTypeThinkingTextMessageContent,
+			events.EventTypeThinkingTextMessageContent,
+			events.EventTypeThinkingTextMessageEnd,
+			events.EventTypeThinkingEnd,
+			events.EventTypeTextMessageContent,
+			events.EventTypeTextMessageContent,
+			events.EventTypeToolCallStart,
+			events.EventTypeToolCallArgs,
+			events.EventTypeToolCallArgs,
+			events.EventTypeToolCallEnd,
+			events.EventTypeTextMessageEnd,
+			events.EventTypeRunFinished,
+		}, eventTypes(result))
+
+		const messageID = "msg_01XFDUDYJgAACzvnptvVoYEL"
+		start := result[0].(*events.TextMessageStartEvent)
+		assert.Equal(t, messageID, start.MessageID)
+		require.NotNil(t, start.Role)
+		assert.Equal(t, "assistant", *start.Role)
+
+		thinking := result[3].(*events.ThinkingTextMessageContentEvent).Delta +
+			result[4].(*events.ThinkingTextMessageContentEvent).Delta
+		assert.Equal(t, "The user wants the weather, so I should call the tool.", thinking)
+
+		text := result[7].(*events.TextMessageContentEvent).Delta + result[8].(*events.TextMessageContentEvent).Delta
+		assert.Equal(t, "Okay, let's check the weather for San Francisco, CA:", text)
+		assert.Equal(t, messageID, result[7].(*events.TextMessageContentEvent).MessageID)
+
+		toolStart := result[9].(*events.ToolCallStartEvent)
+		assert.Equal(t, "toolu_01T1x1fJ34qAmk2tNTrN7Up6", toolStart.ToolCallID)
+		assert.Equal(t, "get_weather", toolStart.ToolCallName)
+		require.NotNil(t, toolStart.ParentMessageID)
+		assert.Equal(t, messageID, *toolStart.ParentMessageID)
+
+		args := result[10].(*events.ToolCallArgsEvent).Delta + result[11].(*events.ToolCallArgsEvent).Delta
+		assert.JSONEq(t, `{"location": "San Francisco, CA"}`, args)
+		assert.Equal(t, toolStart.ToolCallID, result[12].(*events.ToolCallEndEvent).ToolCallID)
+
+		finished := result[14].(*events.RunFinishedEvent)
+		assert.Equal(t, "thread-1", finished.ThreadID())
+		assert.Equal(t, "run-1", finished.RunID())
+	})
+
+	t.Run("Ping", func(t *testing.T) {
+		converter := NewConverter()
+		result, err := converter.FromAnthropicEvent("ping", []byte(`{"type": "ping"}`))
+		require.NoError(t, err)
+		assert.Empty(t, result)
+
+		result, err = converter.FromAnthropicEvent("", []byte(`{"type": "ping"}`))
+		require.NoError(t, err)
+		assert.Empty(t, result)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		converter := NewConverter()
+		_, err := converter.FromAnthropicEvent("message_start", []byte(`{"type":"message_start","message":{"id":"msg_1","role":"assistant"}}`))
+		require.NoError(t, err)
+
+		result, err := converter.FromAnthropicEvent("error", []byte(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`))
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		runError := result[0].(*events.RunErrorEvent)
+		assert.Equal(t, "Overloaded", runError.Message)
+		require.NotNil(t, runError.Code)
+		assert.Equal(t, "overloaded_error", *runError.Code)
+		assert.Equal(t, "msg_1", runError.RunIDValue)
+	})
+
+	t.Run("InvalidEvents", func(t *testing.T) {
+		_, err := NewConverter().FromAnthropicEvent("content_block_delta",
+			[]byte(`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"hi"}}`))
+		assert.Error(t, err, "delta before message_start")
+
+		converter := NewConverter()
+		_, err = converter.FromAnthropicEvent("message_start", []byte(`{"type":"message_start","message":{"id":"msg_1"}}`))
+		require.NoError(t, err)
+
+		_, err = converter.FromAnthropicEvent("content_block_delta",
+			[]byte(`{"type":"content_block_delta","index":3,"delta":{"type":"text_delta","text":"hi"}}`))
+		assert.Error(t, err, "delta for an unknown block")
+
+		_, err = converter.FromAnthropicEvent("message_stop", []byte(`{"type":"message_start"}`))
+		assert.Error(t, err, "mismatched type")
+
+		_, err = converter.FromAnthropicEvent("message_stop", []byte(`{`))
+		assert.Error(t, err, "invalid JSON")
+
+	})
+
+	t.Run("UnknownEvents", func(t *testing.T) {
+		result, err := NewConverter().FromAnthropicEvent("future_event", []byte(`{"type":"future_event"}`))
+		require.NoError(t, err)
+		assert.Empty(t, result)
+	})
+}
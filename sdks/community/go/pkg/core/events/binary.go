@@ -0,0 +1,85 @@
+package events
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+)
+
+// binaryFormatVersion identifies the layout of the binary event encoding
+const binaryFormatVersion byte = 1
+
+// marshalEventBinary encodes an event in its binary form: a format version
+// byte, the length-prefixed event type tag, and the compact JSON encoding of
+// the event. The type tag allows UnmarshalEventBinary to reconstruct the
+// concrete event type without inspecting the payload.
+func marshalEventBinary(event Event) ([]byte, error) {
+	payload, err := event.ToJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s event: %w", event.Type(), err)
+	}
+
+	tag := string(event.Type())
+	data := make([]byte, 0, 1+binary.MaxVarintLen64+len(tag)+len(payload))
+	data = append(data, binaryFormatVersion)
+	data = binary.AppendUvarint(data, uint64(len(tag)))
+	data = append(data, tag...)
+	data = append(data, payload...)
+	return data, nil
+}
+
+// decodeBinaryHeader splits a binary-encoded event into its type tag and payload
+func decodeBinaryHeader(data []byte) (EventType, []byte, error) {
+	if len(data) == 0 {
+		return "", nil, fmt.Errorf("binary event is empty")
+	}
+	if data[0] != binaryFormatVersion {
+		return "", nil, fmt.Errorf("unsupported binary event format version %d", data[0])
+	}
+
+	length, n := binary.Uvarint(data[1:])
+	if n <= 0 || length > uint64(len(data)-1-n) {
+		return "", nil, fmt.Errorf("binary event has a malformed type tag")
+	}
+
+	start := 1 + n
+	end := start + int(length)
+	return EventType(data[start:end]), data[end:], nil
+}
+
+// unmarshalEventBinary decodes a binary-encoded event into target, checking
+// that the encoded type tag matches the expected event type
+func unmarshalEventBinary(data []byte, expected EventType, target Event) error {
+	eventType, payload, err := decodeBinaryHeader(data)
+	if err != nil {
+		return err
+	}
+	if eventType != expected {
+		return fmt.Errorf("cannot decode %s event into %s event", eventType, expected)
+	}
+
+	if err := json.Unmarshal(payload, target); err != nil {
+		return newDecodeError(eventType, err)
+	}
+	return nil
+}
+
+// UnmarshalEventBinary decodes an event produced by an event's MarshalBinary
+// method, reconstructing its concrete type from the encoded type tag
+func UnmarshalEventBinary(data []byte) (Event, error) {
+	eventType, _, err := decodeBinaryHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	event := newEventOfType(eventType)
+	if event == nil {
+		return nil, fmt.Errorf("unknown event type: %s", eventType)
+	}
+
+	if err := unmarshalEventBinary(data, eventType, event); err != nil {
+		return nil, err
+	}
+	assignSequence(event)
+	return event, nil
+}
@@ -0,0 +1,75 @@
+package events
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clearSequence resets the decode sequence of an event so that it can be
+// compared with the event it was encoded from
+func clearSequence(event Event) Event {
+	event.GetBaseEvent().sequence = 0
+	return event
+}
+
+func TestBinaryEncoding(t *testing.T) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		for _, event := range copyableEvents() {
+			marshaler, ok := event.(encoding.BinaryMarshaler)
+			require.True(t, ok, event.Type())
+
+			data, err := marshaler.MarshalBinary()
+			require.NoError(t, err)
+
+			decoded, err := UnmarshalEventBinary(data)
+			require.NoError(t, err)
+			assert.Positive(t, decoded.GetBaseEvent().Sequence())
+			assert.Equal(t, event, clearSequence(decoded))
+		}
+	})
+
+	t.Run("ConcreteType", func(t *testing.T) {
+		original := NewToolCallResultEvent("msg-1", "tool-1", "42", WithPriority(1))
+		data, err := original.MarshalBinary()
+		require.NoError(t, err)
+
+		var decoded ToolCallResultEvent
+		require.NoError(t, decoded.UnmarshalBinary(data))
+		assert.Equal(t, original, &decoded)
+
+		// The type tag must match the target type
+		var wrong TextMessageStartEvent
+		err = wrong.UnmarshalBinary(data)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "TOOL_CALL_RESULT")
+	})
+
+	t.Run("Gob", func(t *testing.T) {
+		original := NewStateSnapshotEvent(map[string]any{"count": float64(3)})
+
+		var buf bytes.Buffer
+		require.NoError(t, gob.NewEncoder(&buf).Encode(original))
+
+		var decoded StateSnapshotEvent
+		require.NoError(t, gob.NewDecoder(&buf).Decode(&decoded))
+		assert.Equal(t, original, &decoded)
+	})
+
+	t.Run("MalformedData", func(t *testing.T) {
+		for name, data := range map[string][]byte{
+			"Empty":          nil,
+			"UnknownVersion": {99, 0},
+			"TruncatedTag":   {binaryFormatVersion, 20, 'R', 'U', 'N'},
+			"UnknownType":    append([]byte{binaryFormatVersion, 3}, []byte("FOO{}")...),
+			"InvalidPayload": append([]byte{binaryFormatVersion, 12}, []byte("STEP_STARTEDnot json")...),
+		} {
+			_, err := UnmarshalEventBinary(data)
+			assert.Error(t, err, name)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// MergeEventChannels forwards the events of every channel to a single output
+// channel, for example to combine the streams of several agents. The events
+// of each channel keep their order, while events of different channels are
+// interleaved in the order they are read. The output channel is closed once
+// every input channel is closed, or once ctx is done; events read after ctx
+// is done are dropped.
+func MergeEventChannels(ctx context.Context, channels ...<-chan Event) <-chan Event {
+	out := make(chan Event)
+
+	var wg sync.WaitGroup
+	wg.Add(len(channels))
+	for _, ch := range channels {
+		go func(ch <-chan Event) {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case e, ok := <-ch:
+					if !ok {
+						return
+					}
+					select {
+					case out <- e:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}(ch)
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// EventPair is a pair of correlated events from two streams
+type EventPair struct {
+	A Event
+	B Event
+}
+
+// ZipEventChannels correlates the events of two streams, such as the requests
+// of a coordinator and the replies of a worker agent. Every event is held
+// until an event of the other stream matches it, with matcher called as
+// matcher(a, b); it is then sent as a pair and not matched again. Events
+// are matched with the earliest held event of the other stream that
+// matches. The output channel is closed once both input channels are
+// closed, or once ctx is done; the events that were never matched are
+// dropped. Held events are not bounded, so streams with many events that
+// never match should be filtered first.
+func ZipEventChannels(ctx context.Context, a, b <-chan Event, matcher func(Event, Event) bool) <-chan EventPair {
+	out := make(chan EventPair)
+
+	go func() {
+		defer close(out)
+
+		var heldA, heldB []Event
+		for a != nil || b != nil {
+			var pair *EventPair
+			select {
+			case <-ctx.Done():
+				return
+
+			case e, ok := <-a:
+				if !ok {
+					a = nil
+					continue
+				}
+				if i := matchHeld(heldB, func(held Event) bool { return matcher(e, held) }); i >= 0 {
+					pair = &EventPair{A: e, B: heldB[i]}
+					heldB = append(heldB[:i], heldB[i+1:]...)
+				} else {
+					heldA = append(heldA, e)
+				}
+
+			case e, ok := <-b:
+				if !ok {
+					b = nil
+					continue
+				}
+				if i := matchHeld(heldA, func(held Event) bool { return matcher(held, e) }); i >= 0 {
+					pair = &EventPair{A: heldA[i], B: e}
+					heldA = append(heldA[:i], heldA[i+1:]...)
+				} else {
+					heldB = append(heldB, e)
+				}
+			}
+
+			if pair == nil {
+				continue
+			}
+			select {
+			case out <- *pair:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// matchHeld returns the index of the first held event accepted by match, or
+// -1 if there is none
+func matchHeld(held []Event, match func(Event) bool) int {
+	for i, e := range held {
+		if match(e) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,153 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sendEvents returns a channel sending the events, closed afterwards
+func sendEvents(events ...Event) <-chan Event {
+	ch := make(chan Event, len(events))
+	for _, e := range events {
+		ch <- e
+	}
+	close(ch)
+	return ch
+}
+
+// receiveAll collects the events of a channel until it is closed, failing
+// the test if it is not closed in time
+func receiveAll[T any](t *testing.T, ch <-chan T) []T {
+	t.Helper()
+	var received []T
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				return received
+			}
+			received = append(received, e)
+		case <-timeout:
+			require.FailNow(t, "channel not closed")
+		}
+	}
+}
+
+// stepName returns the step name of a step event
+func stepName(e Event) string {
+	return e.(*StepStartedEvent).StepName
+}
+
+func TestMergeEventChannels(t *testing.T) {
+	t.Run("KeepsOrderOfEachChannel", func(t *testing.T) {
+		a1, a2, a3 := NewStepStartedEvent("a1"), NewStepStartedEvent("a2"), NewStepStartedEvent("a3")
+		b1, b2 := NewStepStartedEvent("b1"), NewStepStartedEvent("b2")
+
+		received := receiveAll(t, MergeEventChannels(context.Background(),
+			sendEvents(a1, a2, a3), sendEvents(b1, b2), sendEvents()))
+
+		require.Len(t, received, 5)
+		var fromA, fromB []Event
+		for _, e := range received {
+			if stepName(e)[0] == 'a' {
+				fromA = append(fromA, e)
+			} else {
+				fromB = append(fromB, e)
+			}
+		}
+		assert.Equal(t, []Event{a1, a2, a3}, fromA)
+		assert.Equal(t, []Event{b1, b2}, fromB)
+	})
+
+	t.Run("NoChannels", func(t *testing.T) {
+		assert.Empty(t, receiveAll(t, MergeEventChannels(context.Background())))
+	})
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		open := make(chan Event)
+		out := MergeEventChannels(ctx, open)
+
+		open <- NewStepStartedEvent("first")
+		cancel()
+		received := receiveAll(t, out)
+		assert.LessOrEqual(t, len(received), 1)
+	})
+}
+
+func TestZipEventChannels(t *testing.T) {
+	sameStep := func(a, b Event) bool {
+		return stepName(a) == stepName(b)
+	}
+
+	t.Run("MatchesAcrossStreams", func(t *testing.T) {
+		a1, a2, a3 := NewStepStartedEvent("one"), NewStepStartedEvent("two"), NewStepStartedEvent("unmatched")
+		b1, b2 := NewStepStartedEvent("two"), NewStepStartedEvent("one")
+		a := make(chan Event)
+		b := make(chan Event)
+		out := ZipEventChannels(context.Background(), a, b, sameStep)
+
+		go func() {
+			a <- a1
+			a <- a2
+			b <- b1
+			b <- b2
+			a <- a3
+			close(a)
+			close(b)
+		}()
+
+		assert.Equal(t, []EventPair{{A: a2, B: b1}, {A: a1, B: b2}}, receiveAll(t, out))
+	})
+
+	t.Run("MatchesEarliestHeldEvent", func(t *testing.T) {
+		b1, b2 := NewStepStartedEvent("step"), NewStepStartedEvent("step")
+		a1 := NewStepStartedEvent("step")
+		b := make(chan Event)
+		a := make(chan Event)
+		out := ZipEventChannels(context.Background(), a, b, sameStep)
+
+		go func() {
+			b <- b1
+			b <- b2
+			close(b)
+			a <- a1
+			close(a)
+		}()
+
+		assert.Equal(t, []EventPair{{A: a1, B: b1}}, receiveAll(t, out))
+	})
+
+	t.Run("MatcherArgumentOrder", func(t *testing.T) {
+		var calls [][2]string
+		matcher := func(a, b Event) bool {
+			calls = append(calls, [2]string{stepName(a), stepName(b)})
+			return true
+		}
+		a := make(chan Event)
+		b := make(chan Event)
+		out := ZipEventChannels(context.Background(), a, b, matcher)
+
+		go func() {
+			b <- NewStepStartedEvent("from-b")
+			a <- NewStepStartedEvent("from-a")
+			close(a)
+			close(b)
+		}()
+
+		assert.Len(t, receiveAll(t, out), 1)
+		assert.Equal(t, [][2]string{{"from-a", "from-b"}}, calls)
+	})
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		out := ZipEventChannels(ctx, make(chan Event), make(chan Event), sameStep)
+		cancel()
+		assert.Empty(t, receiveAll(t, out))
+	})
+}
@@ -0,0 +1,243 @@
+package events
+
+import (
+	"fmt"
+	"sort"
+)
+
+// textChunkExpander is the stage returned by ExpandTextMessageChunks
+type textChunkExpander struct {
+	current string
+}
+
+// ExpandTextMessageChunks returns a stage that replaces TEXT_MESSAGE_CHUNK
+// events with TEXT_MESSAGE_START, CONTENT and END events, for consumers that
+// only understand the lifecycle form. The first chunk of a message emits its
+// start, and each delta a content event. As in MessageAccumulator, a chunk
+// without a message ID continues the current message, and the message ends
+// at the first chunk with a different ID, at RUN_FINISHED or RUN_ERROR, or
+// at Flush. A message ID is generated when the first chunk has none. Other
+// events pass through unchanged. It is not safe for concurrent use.
+func ExpandTextMessageChunks() EventStage {
+	return &textChunkExpander{}
+}
+
+// Process implements EventStage
+func (x *textChunkExpander) Process(e Event) ([]Event, error) {
+	switch event := e.(type) {
+	case *TextMessageChunkEvent:
+		id := x.current
+		if event.MessageID != nil && *event.MessageID != "" {
+			id = *event.MessageID
+		}
+		if id == "" {
+			id = GenerateMessageID()
+		}
+
+		var out []Event
+		if id != x.current {
+			out = append(out, x.Flush()...)
+			start := NewTextMessageStartEvent(id)
+			start.Role = cloneString(event.Role)
+			start.TimestampMs = cloneInt64(event.TimestampMs)
+			out = append(out, start)
+			x.current = id
+		}
+		if event.Delta != nil && *event.Delta != "" {
+			content := NewTextMessageContentEvent(id, *event.Delta)
+			content.TimestampMs = cloneInt64(event.TimestampMs)
+			out = append(out, content)
+		}
+		return out, nil
+
+	case *RunFinishedEvent, *RunErrorEvent:
+		return append(x.Flush(), e), nil
+	}
+	return []Event{e}, nil
+}
+
+// Flush implements EventStage, ending the current message
+func (x *textChunkExpander) Flush() []Event {
+	if x.current == "" {
+		return nil
+	}
+	end := NewTextMessageEndEvent(x.current)
+	x.current = ""
+	return []Event{end}
+}
+
+// toolChunkExpander is the stage returned by ExpandToolCallChunks
+type toolChunkExpander struct {
+	current string
+}
+
+// ExpandToolCallChunks returns a stage that replaces TOOL_CALL_CHUNK events
+// with TOOL_CALL_START, ARGS and END events, following the rules of
+// ExpandTextMessageChunks. The first chunk of a tool call must carry its
+// name, which the start event needs; a tool call ID is generated when it
+// has none. It is not safe for concurrent use.
+func ExpandToolCallChunks() EventStage {
+	return &toolChunkExpander{}
+}
+
+// Process implements EventStage
+func (x *toolChunkExpander) Process(e Event) ([]Event, error) {
+	switch event := e.(type) {
+	case *ToolCallChunkEvent:
+		id := x.current
+		if event.ToolCallID != nil && *event.ToolCallID != "" {
+			id = *event.ToolCallID
+		}
+		if id == "" {
+			id = GenerateToolCallID()
+		}
+
+		var out []Event
+		if id != x.current {
+			if event.ToolCallName == nil || *event.ToolCallName == "" {
+				return nil, fmt.Errorf("first chunk of tool call %s has no tool call name", id)
+			}
+			out = append(out, x.Flush()...)
+			start := NewToolCallStartEvent(id, *event.ToolCallName)
+			start.ParentMessageID = cloneString(event.ParentMessageID)
+			start.TimestampMs = cloneInt64(event.TimestampMs)
+			out = append(out, start)
+			x.current = id
+		}
+		if event.Delta != nil && *event.Delta != "" {
+			args := NewToolCallArgsEvent(id, *event.Delta)
+			args.TimestampMs = cloneInt64(event.TimestampMs)
+			out = append(out, args)
+		}
+		return out, nil
+
+	case *RunFinishedEvent, *RunErrorEvent:
+		return append(x.Flush(), e), nil
+	}
+	return []Event{e}, nil
+}
+
+// Flush implements EventStage, ending the current tool call
+func (x *toolChunkExpander) Flush() []Event {
+	if x.current == "" {
+		return nil
+	}
+	end := NewToolCallEndEvent(x.current)
+	x.current = ""
+	return []Event{end}
+}
+
+// chunkCompactor is the stage returned by CompactToChunks
+type chunkCompactor struct {
+	// messages and toolCalls hold the started messages and tool calls that
+	// have not been sent as a chunk yet
+	messages  map[string]*TextMessageStartEvent
+	toolCalls map[string]*ToolCallStartEvent
+}
+
+// CompactToChunks returns a stage that replaces the TEXT_MESSAGE_START,
+// CONTENT and END events of messages with TEXT_MESSAGE_CHUNK events, and the
+// TOOL_CALL_START, ARGS and END events of tool calls with TOOL_CALL_CHUNK
+// events, for bandwidth-sensitive paths. Start events are folded into the
+// first chunk, which carries the role, or the tool call name and parent
+// message ID; end events are dropped, except for messages and tool calls
+// without content, which are sent as a single chunk.
+//
+// Chunks end a message or tool call implicitly, when the next one starts, so
+// the stage is meant for streams whose lifecycles do not interleave. Chunks
+// cannot carry a TextEdit, so content events with one fail the stage. Other
+// events pass through unchanged. It is not safe for concurrent use.
+func CompactToChunks() EventStage {
+	return &chunkCompactor{
+		messages:  make(map[string]*TextMessageStartEvent),
+		toolCalls: make(map[string]*ToolCallStartEvent),
+	}
+}
+
+// Process implements EventStage
+func (c *chunkCompactor) Process(e Event) ([]Event, error) {
+	switch event := e.(type) {
+	case *TextMessageStartEvent:
+		c.messages[event.MessageID] = event
+		return nil, nil
+
+	case *TextMessageContentEvent:
+		if event.Edit != nil {
+			return nil, fmt.Errorf("cannot compact text edit of message %s into a chunk", event.MessageID)
+		}
+		chunk := c.textChunk(event.MessageID, event.TimestampMs)
+		chunk.Delta = cloneString(&event.Delta)
+		return []Event{chunk}, nil
+
+	case *TextMessageEndEvent:
+		if _, pending := c.messages[event.MessageID]; !pending {
+			return nil, nil
+		}
+		return []Event{c.textChunk(event.MessageID, event.TimestampMs)}, nil
+
+	case *ToolCallStartEvent:
+		c.toolCalls[event.ToolCallID] = event
+		return nil, nil
+
+	case *ToolCallArgsEvent:
+		chunk := c.toolChunk(event.ToolCallID, event.TimestampMs)
+		chunk.Delta = cloneString(&event.Delta)
+		return []Event{chunk}, nil
+
+	case *ToolCallEndEvent:
+		if _, pending := c.toolCalls[event.ToolCallID]; !pending {
+			return nil, nil
+		}
+		return []Event{c.toolChunk(event.ToolCallID, event.TimestampMs)}, nil
+	}
+	return []Event{e}, nil
+}
+
+// textChunk creates a chunk of a message, carrying its role if it is the
+// first chunk of the message
+func (c *chunkCompactor) textChunk(id string, timestamp *int64) *TextMessageChunkEvent {
+	chunk := NewTextMessageChunkEvent(&id, nil, nil)
+	chunk.TimestampMs = cloneInt64(timestamp)
+	if start, ok := c.messages[id]; ok {
+		delete(c.messages, id)
+		chunk.Role = cloneString(start.Role)
+	}
+	return chunk
+}
+
+// toolChunk creates a chunk of a tool call, carrying its name and parent
+// message ID if it is the first chunk of the tool call
+func (c *chunkCompactor) toolChunk(id string, timestamp *int64) *ToolCallChunkEvent {
+	chunk := NewToolCallChunkEvent().WithToolCallChunkID(id)
+	chunk.TimestampMs = cloneInt64(timestamp)
+	if start, ok := c.toolCalls[id]; ok {
+		delete(c.toolCalls, id)
+		chunk.ToolCallName = cloneString(&start.ToolCallName)
+		chunk.ParentMessageID = cloneString(start.ParentMessageID)
+	}
+	return chunk
+}
+
+// Flush implements EventStage, sending the messages and tool calls that
+// started without ending as chunks
+func (c *chunkCompactor) Flush() []Event {
+	messageIDs := make([]string, 0, len(c.messages))
+	for id := range c.messages {
+		messageIDs = append(messageIDs, id)
+	}
+	toolCallIDs := make([]string, 0, len(c.toolCalls))
+	for id := range c.toolCalls {
+		toolCallIDs = append(toolCallIDs, id)
+	}
+	sort.Strings(messageIDs)
+	sort.Strings(toolCallIDs)
+
+	var out []Event
+	for _, id := range messageIDs {
+		out = append(out, c.textChunk(id, c.messages[id].TimestampMs))
+	}
+	for _, id := range toolCallIDs {
+		out = append(out, c.toolChunk(id, c.toolCalls[id].TimestampMs))
+	}
+	return out
+}
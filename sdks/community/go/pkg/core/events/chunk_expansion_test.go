@@ -0,0 +1,206 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// describeChunkEvents summarizes the events of chunk expansion tests
+func describeChunkEvents(events []Event) []string {
+	describe := func(p *string) string {
+		if p == nil {
+			return "-"
+		}
+		return *p
+	}
+
+	result := make([]string, 0, len(events))
+	for _, e := range events {
+		var fields []string
+		switch event := e.(type) {
+		case *TextMessageStartEvent:
+			fields = []string{event.MessageID, describe(event.Role)}
+		case *TextMessageContentEvent:
+			fields = []string{event.MessageID, event.Delta}
+		case *TextMessageEndEvent:
+			fields = []string{event.MessageID}
+		case *TextMessageChunkEvent:
+			fields = []string{describe(event.MessageID), describe(event.Role), describe(event.Delta)}
+		case *ToolCallStartEvent:
+			fields = []string{event.ToolCallID, event.ToolCallName, describe(event.ParentMessageID)}
+		case *ToolCallArgsEvent:
+			fields = []string{event.ToolCallID, event.Delta}
+		case *ToolCallEndEvent:
+			fields = []string{event.ToolCallID}
+		case *ToolCallChunkEvent:
+			fields = []string{describe(event.ToolCallID), describe(event.ToolCallName), describe(event.ParentMessageID), describe(event.Delta)}
+		}
+		result = append(result, fmt.Sprintf("%s %s", e.Type(), strings.Join(fields, " ")))
+	}
+	return result
+}
+
+func textChunk(id, role, delta string) *TextMessageChunkEvent {
+	chunk := NewTextMessageChunkEvent(nil, nil, nil)
+	if id != "" {
+		chunk.WithChunkMessageID(id)
+	}
+	if role != "" {
+		chunk.WithChunkRole(role)
+	}
+	if delta != "" {
+		chunk.WithChunkDelta(delta)
+	}
+	return chunk
+}
+
+func TestExpandTextMessageChunks(t *testing.T) {
+	t.Run("Lifecycle", func(t *testing.T) {
+		out, err := ApplyStages([]Event{
+			NewRunStartedEvent("thread-1", "run-1"),
+			textChunk("msg-1", RoleAssistant, "Hel"),
+			textChunk("", "", "lo"),
+			textChunk("msg-2", RoleUser, "Hi"),
+			NewRunFinishedEvent("thread-1", "run-1"),
+		}, ExpandTextMessageChunks())
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"RUN_STARTED ",
+			"TEXT_MESSAGE_START msg-1 assistant",
+			"TEXT_MESSAGE_CONTENT msg-1 Hel",
+			"TEXT_MESSAGE_CONTENT msg-1 lo",
+			"TEXT_MESSAGE_END msg-1",
+			"TEXT_MESSAGE_START msg-2 user",
+			"TEXT_MESSAGE_CONTENT msg-2 Hi",
+			"TEXT_MESSAGE_END msg-2",
+			"RUN_FINISHED ",
+		}, describeChunkEvents(out))
+		for _, e := range out {
+			assert.NoError(t, e.Validate(), e.Type())
+		}
+	})
+
+	t.Run("GeneratesMessageID", func(t *testing.T) {
+		stage := ExpandTextMessageChunks()
+		out, err := stage.Process(textChunk("", "", "Hello"))
+		require.NoError(t, err)
+		require.Len(t, out, 2)
+
+		start := out[0].(*TextMessageStartEvent)
+		assert.NotEmpty(t, start.MessageID)
+		assert.Equal(t, start.MessageID, out[1].(*TextMessageContentEvent).MessageID)
+
+		end := stage.Flush()
+		require.Len(t, end, 1)
+		assert.Equal(t, start.MessageID, end[0].(*TextMessageEndEvent).MessageID)
+		assert.Empty(t, stage.Flush())
+	})
+}
+
+func TestExpandToolCallChunks(t *testing.T) {
+	t.Run("Lifecycle", func(t *testing.T) {
+		out, err := ApplyStages([]Event{
+			NewToolCallChunkEvent().WithToolCallChunkID("call-1").WithToolCallChunkName("search").
+				WithToolCallChunkParentMessageID("msg-1").WithToolCallChunkDelta(`{"q":`),
+			NewToolCallChunkEvent().WithToolCallChunkDelta(`"go"}`),
+			NewToolCallChunkEvent().WithToolCallChunkID("call-2").WithToolCallChunkName("weather"),
+		}, ExpandToolCallChunks())
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"TOOL_CALL_START call-1 search msg-1",
+			`TOOL_CALL_ARGS call-1 {"q":`,
+			`TOOL_CALL_ARGS call-1 "go"}`,
+			"TOOL_CALL_END call-1",
+			"TOOL_CALL_START call-2 weather -",
+			"TOOL_CALL_END call-2",
+		}, describeChunkEvents(out))
+	})
+
+	t.Run("MissingName", func(t *testing.T) {
+		_, err := ExpandToolCallChunks().Process(NewToolCallChunkEvent().WithToolCallChunkID("call-1").WithToolCallChunkDelta("{}"))
+		assert.Error(t, err)
+	})
+}
+
+func TestCompactToChunks_RoundTrip(t *testing.T) {
+	lifecycle := []Event{
+		NewRunStartedEvent("thread-1", "run-1"),
+		NewTextMessageStartEvent("msg-1", WithRole(RoleAssistant)),
+		NewTextMessageContentEvent("msg-1", "Let me "),
+		NewTextMessageContentEvent("msg-1", "check."),
+		NewTextMessageEndEvent("msg-1"),
+		NewToolCallStartEvent("call-1", "search", WithParentMessageID("msg-1")),
+		NewToolCallArgsEvent("call-1", `{"q":`),
+		NewToolCallArgsEvent("call-1", `"go"}`),
+		NewToolCallEndEvent("call-1"),
+		NewTextMessageStartEvent("msg-2"),
+		NewTextMessageEndEvent("msg-2"),
+		NewRunFinishedEvent("thread-1", "run-1"),
+	}
+
+	t.Run("CompactThenExpand", func(t *testing.T) {
+		chunks, err := ApplyStages(lifecycle, CompactToChunks())
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"RUN_STARTED ",
+			"TEXT_MESSAGE_CHUNK msg-1 assistant Let me ",
+			"TEXT_MESSAGE_CHUNK msg-1 - check.",
+			`TOOL_CALL_CHUNK call-1 search msg-1 {"q":`,
+			`TOOL_CALL_CHUNK call-1 - - "go"}`,
+			"TEXT_MESSAGE_CHUNK msg-2 - -",
+			"RUN_FINISHED ",
+		}, describeChunkEvents(chunks))
+
+		// The ends are implicit in chunk streams, so they move to the next
+		// chunk of another ID or to the end of the run
+		expanded, err := ApplyStages(chunks, ExpandTextMessageChunks(), ExpandToolCallChunks())
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"RUN_STARTED ",
+			"TEXT_MESSAGE_START msg-1 assistant",
+			"TEXT_MESSAGE_CONTENT msg-1 Let me ",
+			"TEXT_MESSAGE_CONTENT msg-1 check.",
+			"TOOL_CALL_START call-1 search msg-1",
+			`TOOL_CALL_ARGS call-1 {"q":`,
+			`TOOL_CALL_ARGS call-1 "go"}`,
+			"TEXT_MESSAGE_END msg-1",
+			"TEXT_MESSAGE_START msg-2 -",
+			"TEXT_MESSAGE_END msg-2",
+			"TOOL_CALL_END call-1",
+			"RUN_FINISHED ",
+		}, describeChunkEvents(expanded))
+	})
+
+	t.Run("ExpandThenCompact", func(t *testing.T) {
+		chunks := []Event{
+			textChunk("msg-1", RoleAssistant, "Hel"),
+			textChunk("msg-1", "", "lo"),
+			NewToolCallChunkEvent().WithToolCallChunkID("call-1").WithToolCallChunkName("search").WithToolCallChunkDelta("{}"),
+			textChunk("msg-2", "", "Bye"),
+		}
+
+		expanded, err := ApplyStages(chunks, ExpandTextMessageChunks(), ExpandToolCallChunks())
+		require.NoError(t, err)
+		compacted, err := ApplyStages(expanded, CompactToChunks())
+		require.NoError(t, err)
+		assert.Equal(t, describeChunkEvents(chunks), describeChunkEvents(compacted))
+	})
+
+	t.Run("UnendedStartIsFlushed", func(t *testing.T) {
+		out, err := ApplyStages([]Event{
+			NewToolCallStartEvent("call-1", "search"),
+			NewTextMessageStartEvent("msg-1", WithRole(RoleAssistant)),
+		}, CompactToChunks())
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"TEXT_MESSAGE_CHUNK msg-1 assistant -",
+			"TOOL_CALL_CHUNK call-1 search - -",
+		}, describeChunkEvents(out))
+	})
+}
@@ -0,0 +1,221 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by a circuit breaker while it rejects events
+// without calling the handler it protects
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreakerStateChangedEvent is the name of the custom event emitted on
+// circuit breaker state changes, see WithCircuitStateEvents
+const CircuitBreakerStateChangedEvent = "circuit_breaker.state_changed"
+
+// CircuitState is the state of a circuit breaker
+type CircuitState int
+
+const (
+	// CircuitClosed passes every event to the handler
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen rejects every event with ErrCircuitOpen
+	CircuitOpen
+
+	// CircuitHalfOpen passes a single probe event to the handler, whose
+	// outcome closes or reopens the circuit
+	CircuitHalfOpen
+)
+
+// String returns the name of the state
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerOption configures a circuit breaker
+type CircuitBreakerOption func(*circuitBreaker)
+
+// WithCircuitBreakerLogger sets the logger receiving state changes, instead
+// of a default logrus logger
+func WithCircuitBreakerLogger(logger EventLogger) CircuitBreakerOption {
+	return func(b *circuitBreaker) {
+		if logger != nil {
+			b.logger = logger
+		}
+	}
+}
+
+// WithCircuitBreakerClock sets the clock used to time the open state,
+// instead of time.Now
+func WithCircuitBreakerClock(clock EventClock) CircuitBreakerOption {
+	return func(b *circuitBreaker) {
+		if clock != nil {
+			b.now = clock
+		}
+	}
+}
+
+// WithCircuitStateEvents calls emit with a CUSTOM event named
+// CircuitBreakerStateChangedEvent on every state change. Its value is an
+// object holding the "from" and "to" states and the number of consecutive
+// "failures". emit is called without holding the breaker's lock, but may be
+// called concurrently when events are handled concurrently.
+func WithCircuitStateEvents(emit func(*CustomEvent)) CircuitBreakerOption {
+	return func(b *circuitBreaker) {
+		b.emit = emit
+	}
+}
+
+// circuitBreaker holds the state shared by every handler wrapped by a
+// circuit breaker middleware
+type circuitBreaker struct {
+	threshold int
+	timeout   time.Duration
+	logger    EventLogger
+	now       EventClock
+	emit      func(*CustomEvent)
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// circuitTransition is a state change to report once the lock is released
+type circuitTransition struct {
+	from, to CircuitState
+	failures int
+}
+
+// NewCircuitBreakerMiddleware returns a middleware that stops calling the
+// handler after threshold consecutive errors. The circuit then opens, and
+// events are rejected with ErrCircuitOpen until timeout has elapsed. The
+// next event is passed to the handler as a probe: if it succeeds the
+// circuit closes, otherwise it opens again for another timeout. Events
+// arriving while the probe is in flight are rejected.
+//
+// The state is shared by every handler the middleware wraps, so wrapping
+// several handlers with the same middleware makes them trip together. A
+// threshold below 1 is treated as 1. Any error returned by the handler,
+// including context errors, counts as a failure.
+func NewCircuitBreakerMiddleware(threshold int, timeout time.Duration, options ...CircuitBreakerOption) EventMiddleware {
+	b := &circuitBreaker{
+		threshold: max(threshold, 1),
+		timeout:   timeout,
+		now:       time.Now,
+	}
+	for _, opt := range options {
+		opt(b)
+	}
+	if b.logger == nil {
+		b.logger = NewLogrusEventLogger(nil)
+	}
+
+	return func(next EventHandler) EventHandler {
+		return EventHandlerFunc(func(ctx context.Context, event Event) error {
+			probe, transition, err := b.acquire()
+			b.report(transition)
+			if err != nil {
+				return err
+			}
+
+			err = next.HandleEvent(ctx, event)
+			b.report(b.release(probe, err))
+			return err
+		})
+	}
+}
+
+// acquire decides whether an event may be passed to the handler, and
+// whether it is the probe of a half-open circuit
+func (b *circuitBreaker) acquire() (probe bool, transition *circuitTransition, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitClosed:
+		return false, nil, nil
+	case CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.timeout {
+			return false, nil, ErrCircuitOpen
+		}
+		transition = b.transition(CircuitHalfOpen)
+	}
+
+	if b.probing {
+		return false, transition, ErrCircuitOpen
+	}
+	b.probing = true
+	return true, transition, nil
+}
+
+// release records the outcome of a call to the handler
+func (b *circuitBreaker) release(probe bool, err error) *circuitTransition {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+	}
+
+	if err == nil {
+		b.failures = 0
+		if probe && b.state == CircuitHalfOpen {
+			return b.transition(CircuitClosed)
+		}
+		return nil
+	}
+
+	b.failures++
+	switch {
+	case probe && b.state == CircuitHalfOpen:
+		b.openedAt = b.now()
+		return b.transition(CircuitOpen)
+	case b.state == CircuitClosed && b.failures >= b.threshold:
+		b.openedAt = b.now()
+		return b.transition(CircuitOpen)
+	}
+	return nil
+}
+
+// transition changes the state, which must differ from the current one
+func (b *circuitBreaker) transition(to CircuitState) *circuitTransition {
+	t := &circuitTransition{from: b.state, to: to, failures: b.failures}
+	b.state = to
+	return t
+}
+
+// report logs a state change and emits its custom event
+func (b *circuitBreaker) report(t *circuitTransition) {
+	if t == nil {
+		return
+	}
+
+	fields := map[string]any{"from": t.from.String(), "to": t.to.String(), "failures": t.failures}
+	if t.to == CircuitOpen {
+		b.logger.Warn("circuit breaker opened", fields)
+	} else {
+		b.logger.Info("circuit breaker "+t.to.String(), fields)
+	}
+
+	if b.emit != nil {
+		b.emit(NewCustomEvent(CircuitBreakerStateChangedEvent, WithValue(map[string]any{
+			"from":     t.from.String(),
+			"to":       t.to.String(),
+			"failures": t.failures,
+		})))
+	}
+}
@@ -0,0 +1,145 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreakerMiddleware(t *testing.T) {
+	errPersist := errors.New("persist failed")
+	ctx := context.Background()
+	event := NewRunStartedEvent("thread-1", "run-1")
+
+	now := time.Unix(1700000000, 0)
+	clock := func() time.Time { return now }
+
+	var calls int
+	var fail bool
+	persist := EventHandlerFunc(func(ctx context.Context, event Event) error {
+		calls++
+		if fail {
+			return errPersist
+		}
+		return nil
+	})
+
+	logger := &recordingEventLogger{}
+	var emitted []*CustomEvent
+	handler := NewCircuitBreakerMiddleware(3, time.Minute,
+		WithCircuitBreakerClock(clock),
+		WithCircuitBreakerLogger(logger),
+		WithCircuitStateEvents(func(e *CustomEvent) { emitted = append(emitted, e) }),
+	)(persist)
+
+	// Successes reset the count of consecutive failures
+	fail = true
+	require.ErrorIs(t, handler.HandleEvent(ctx, event), errPersist)
+	require.ErrorIs(t, handler.HandleEvent(ctx, event), errPersist)
+	fail = false
+	require.NoError(t, handler.HandleEvent(ctx, event))
+	assert.Empty(t, emitted)
+
+	// The third consecutive failure opens the circuit
+	fail = true
+	for i := 0; i < 3; i++ {
+		require.ErrorIs(t, handler.HandleEvent(ctx, event), errPersist)
+	}
+	assert.Equal(t, 6, calls)
+	require.Len(t, emitted, 1)
+	assert.Equal(t, CircuitBreakerStateChangedEvent, emitted[0].Name)
+	assert.Equal(t, map[string]any{"from": "closed", "to": "open", "failures": 3}, emitted[0].Value)
+	assert.NoError(t, emitted[0].Validate())
+
+	// While open, events are rejected without calling the handler
+	fail = false
+	now = now.Add(59 * time.Second)
+	assert.ErrorIs(t, handler.HandleEvent(ctx, event), ErrCircuitOpen)
+	assert.Equal(t, 6, calls)
+
+	// After the timeout a failing probe reopens the circuit for another
+	// timeout
+	fail = true
+	now = now.Add(time.Second)
+	require.ErrorIs(t, handler.HandleEvent(ctx, event), errPersist)
+	assert.Equal(t, 7, calls)
+	assert.ErrorIs(t, handler.HandleEvent(ctx, event), ErrCircuitOpen)
+	assert.Equal(t, 7, calls)
+
+	// A successful probe closes the circuit
+	fail = false
+	now = now.Add(time.Minute)
+	require.NoError(t, handler.HandleEvent(ctx, event))
+	require.NoError(t, handler.HandleEvent(ctx, event))
+	assert.Equal(t, 9, calls)
+
+	var transitions []string
+	for _, e := range emitted {
+		value := e.Value.(map[string]any)
+		transitions = append(transitions, value["from"].(string)+"->"+value["to"].(string))
+	}
+	assert.Equal(t, []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}, transitions)
+
+	var messages []string
+	for _, entry := range logger.entries {
+		messages = append(messages, entry.msg)
+	}
+	assert.Equal(t, []string{
+		"warn: circuit breaker opened",
+		"info: circuit breaker half-open",
+		"warn: circuit breaker opened",
+		"info: circuit breaker half-open",
+		"info: circuit breaker closed",
+	}, messages)
+}
+
+func TestCircuitBreakerMiddleware_SingleProbe(t *testing.T) {
+	errPersist := errors.New("persist failed")
+	ctx := context.Background()
+	event := NewRunStartedEvent("thread-1", "run-1")
+
+	now := time.Unix(1700000000, 0)
+	clock := func() time.Time { return now }
+
+	probing := make(chan struct{})
+	release := make(chan struct{})
+	fail := true
+	handler := NewCircuitBreakerMiddleware(1, time.Second,
+		WithCircuitBreakerClock(clock),
+		WithCircuitBreakerLogger(&recordingEventLogger{}),
+	)(EventHandlerFunc(func(ctx context.Context, event Event) error {
+		if fail {
+			return errPersist
+		}
+		close(probing)
+		<-release
+		return nil
+	}))
+
+	// A threshold of one opens the circuit on the first failure
+	require.ErrorIs(t, handler.HandleEvent(ctx, event), errPersist)
+	assert.ErrorIs(t, handler.HandleEvent(ctx, event), ErrCircuitOpen)
+
+	fail = false
+	now = now.Add(time.Second)
+	done := make(chan error)
+	go func() { done <- handler.HandleEvent(ctx, event) }()
+	<-probing
+
+	// Events are rejected while the probe is in flight
+	assert.ErrorIs(t, handler.HandleEvent(ctx, event), ErrCircuitOpen)
+
+	close(release)
+	require.NoError(t, <-done)
+}
+
+func TestCircuitState_String(t *testing.T) {
+	assert.Equal(t, "closed", CircuitClosed.String())
+	assert.Equal(t, "open", CircuitOpen.String())
+	assert.Equal(t, "half-open", CircuitHalfOpen.String())
+	assert.Equal(t, "unknown", CircuitState(42).String())
+}
@@ -0,0 +1,178 @@
+package events
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultContentInterval is how long a ContentCoalescer buffers content by
+// default before emitting it
+const DefaultContentInterval = 50 * time.Millisecond
+
+// DefaultContentMaxBytes is the buffered content size at which a
+// ContentCoalescer emits it by default
+const DefaultContentMaxBytes = 1024
+
+// ContentCoalescer merges consecutive TEXT_MESSAGE_CONTENT events of the same
+// message into fewer events, so that models streaming one event per token do
+// not flood the network and the renderers of the frontends. Content is
+// buffered until the interval has elapsed, the size limit is reached, or any
+// other event arrives: content of another message, edits, lifecycle events
+// and tool events are never delayed, and are only emitted after the buffered
+// content that precedes them.
+//
+// The merged events are built like those of CompactContentEvents. When the
+// interval or the size limit is reached in the middle of a UTF-8 sequence
+// split across deltas, the incomplete sequence stays buffered, so every
+// emitted delta is valid UTF-8 if the concatenated deltas are. It is not
+// safe for concurrent use.
+type ContentCoalescer struct {
+	interval time.Duration
+	maxBytes int
+	now      EventClock
+
+	first       *TextMessageContentEvent
+	content     strings.Builder
+	windowStart time.Time
+}
+
+// ContentCoalescerOption defines options for creating content coalescers
+type ContentCoalescerOption func(*ContentCoalescer)
+
+// WithContentInterval sets how long content is buffered, counted from the
+// first buffered event. Zero disables the time limit.
+func WithContentInterval(d time.Duration) ContentCoalescerOption {
+	return func(c *ContentCoalescer) {
+		c.interval = d
+	}
+}
+
+// WithContentMaxBytes emits the buffered content once it holds n bytes or
+// more. Zero disables the size limit.
+func WithContentMaxBytes(n int) ContentCoalescerOption {
+	return func(c *ContentCoalescer) {
+		c.maxBytes = n
+	}
+}
+
+// NewContentCoalescer creates a content coalescer buffering content for
+// DefaultContentInterval or up to DefaultContentMaxBytes unless configured
+// otherwise
+func NewContentCoalescer(opts ...ContentCoalescerOption) *ContentCoalescer {
+	c := &ContentCoalescer{
+		interval: DefaultContentInterval,
+		maxBytes: DefaultContentMaxBytes,
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Process implements EventStage. Content events are buffered, and the merged
+// content is returned once the interval or size limit is reached. Any other
+// event, including content of another message, first flushes the buffer.
+func (c *ContentCoalescer) Process(e Event) ([]Event, error) {
+	event, ok := e.(*TextMessageContentEvent)
+	if !ok || event == nil || event.Edit != nil {
+		return append(c.Flush(), e), nil
+	}
+
+	var out []Event
+	if c.first != nil && c.first.MessageID != event.MessageID {
+		out = c.Flush()
+	}
+	if c.first == nil {
+		c.first = event
+		c.windowStart = c.now()
+	}
+	c.content.WriteString(event.Delta)
+
+	return append(out, c.FlushIfDue()...), nil
+}
+
+// FlushIfDue returns the merged content if the interval has elapsed or the
+// size limit has been reached, for callers polling while the stream is idle.
+// A trailing incomplete UTF-8 sequence is kept buffered.
+func (c *ContentCoalescer) FlushIfDue() []Event {
+	if c.first == nil {
+		return nil
+	}
+	due := c.maxBytes > 0 && c.content.Len() >= c.maxBytes
+	if !due && c.interval > 0 && c.now().Sub(c.windowStart) >= c.interval {
+		due = true
+	}
+	if !due {
+		return nil
+	}
+
+	content := c.content.String()
+	cut := completeUTF8Prefix(content)
+	if cut == 0 {
+		return nil
+	}
+
+	out := c.emit(content[:cut])
+	if rest := content[cut:]; rest != "" {
+		// The merged event keeps the metadata of the first event of the
+		// buffer, which the held bytes now start
+		c.first = out.Clone()
+		c.first.Delta = ""
+		c.content.WriteString(rest)
+		c.windowStart = c.now()
+	}
+	return []Event{out}
+}
+
+// Pending reports whether content is buffered, for callers scheduling a
+// FlushIfDue
+func (c *ContentCoalescer) Pending() bool {
+	return c.first != nil
+}
+
+// Flush implements EventStage, returning the buffered content, if any, and
+// emptying the buffer
+func (c *ContentCoalescer) Flush() []Event {
+	if c.first == nil {
+		return nil
+	}
+	return []Event{c.emit(c.content.String())}
+}
+
+// emit returns the buffered content as a single event with the given delta
+// and empties the buffer
+func (c *ContentCoalescer) emit(delta string) *TextMessageContentEvent {
+	first := c.first
+	c.first = nil
+	c.content.Reset()
+
+	if first.Delta == delta {
+		return first
+	}
+	merged := first.Clone()
+	merged.Delta = delta
+	if merged.BaseEvent != nil {
+		merged.RawEvent = nil
+	}
+	return merged
+}
+
+// completeUTF8Prefix returns the length of s without a trailing incomplete
+// UTF-8 sequence
+func completeUTF8Prefix(s string) int {
+	// A sequence is at most utf8.UTFMax bytes, so only the last few bytes
+	// can belong to an incomplete one
+	for i := len(s) - 1; i >= 0 && i >= len(s)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(s[i]) {
+			if utf8.FullRuneInString(s[i:]) {
+				return len(s)
+			}
+			return i
+		}
+	}
+	return len(s)
+}
@@ -0,0 +1,165 @@
+package events
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// coalesceContent passes events through a content coalescer and flushes it
+func coalesceContent(t *testing.T, c *ContentCoalescer, events []Event) []Event {
+	t.Helper()
+	var out []Event
+	for _, e := range events {
+		emitted, err := c.Process(e)
+		require.NoError(t, err)
+		out = append(out, emitted...)
+	}
+	return append(out, c.Flush()...)
+}
+
+// contentOf concatenates the deltas of a message
+func contentOf(events []Event, messageID string) string {
+	var b strings.Builder
+	for _, e := range events {
+		if content, ok := e.(*TextMessageContentEvent); ok && content.MessageID == messageID {
+			b.WriteString(content.Delta)
+		}
+	}
+	return b.String()
+}
+
+func TestContentCoalescer(t *testing.T) {
+	t.Run("PreservesContentAndOrder", func(t *testing.T) {
+		var events []Event
+		events = append(events, NewTextMessageStartEvent("msg-1", WithRole(RoleAssistant)))
+		for _, token := range strings.Fields("The quick brown fox jumps over the lazy dog") {
+			events = append(events, NewTextMessageContentEvent("msg-1", token+" "))
+		}
+		events = append(events,
+			NewToolCallStartEvent("call-1", "search", WithParentMessageID("msg-1")),
+			NewTextMessageContentEvent("msg-1", "and "),
+			NewTextMessageContentEvent("msg-1", "more"),
+			NewTextMessageEndEvent("msg-1"),
+			NewToolCallEndEvent("call-1"),
+		)
+
+		c := NewContentCoalescer(WithContentMaxBytes(16), WithContentInterval(0))
+		out := coalesceContent(t, c, events)
+
+		assert.Equal(t, contentOf(events, "msg-1"), contentOf(out, "msg-1"))
+		assert.Less(t, len(out), len(events))
+
+		var types []EventType
+		for _, e := range out {
+			if e.Type() != EventTypeTextMessageContent {
+				types = append(types, e.Type())
+			}
+			if content, ok := e.(*TextMessageContentEvent); ok {
+				assert.NoError(t, content.Validate())
+			}
+		}
+		assert.Equal(t, []EventType{
+			EventTypeTextMessageStart,
+			EventTypeToolCallStart,
+			EventTypeTextMessageEnd,
+			EventTypeToolCallEnd,
+		}, types)
+
+		// The content emitted before the tool call start is that of the
+		// events before it
+		for i, e := range out {
+			if e.Type() == EventTypeToolCallStart {
+				assert.Equal(t, "The quick brown fox jumps over the lazy dog ", contentOf(out[:i], "msg-1"))
+			}
+			if e.Type() == EventTypeTextMessageEnd {
+				assert.Equal(t, contentOf(events, "msg-1"), contentOf(out[:i], "msg-1"))
+			}
+		}
+	})
+
+	t.Run("Interval", func(t *testing.T) {
+		now := time.Unix(1700000000, 0)
+		c := NewContentCoalescer(WithContentInterval(50*time.Millisecond), WithContentMaxBytes(0))
+		c.now = func() time.Time { return now }
+
+		out, err := c.Process(NewTextMessageContentEvent("msg-1", "Hel"))
+		require.NoError(t, err)
+		assert.Empty(t, out)
+
+		now = now.Add(30 * time.Millisecond)
+		out, err = c.Process(NewTextMessageContentEvent("msg-1", "lo"))
+		require.NoError(t, err)
+		assert.Empty(t, out)
+		assert.Empty(t, c.FlushIfDue())
+
+		now = now.Add(20 * time.Millisecond)
+		out = c.FlushIfDue()
+		require.Len(t, out, 1)
+		assert.Equal(t, "Hello", out[0].(*TextMessageContentEvent).Delta)
+		assert.Empty(t, c.Flush())
+	})
+
+	t.Run("MessageChange", func(t *testing.T) {
+		c := NewContentCoalescer()
+		out := coalesceContent(t, c, []Event{
+			NewTextMessageContentEvent("msg-1", "a"),
+			NewTextMessageContentEvent("msg-1", "b"),
+			NewTextMessageContentEvent("msg-2", "c"),
+			NewTextMessageContentEvent("msg-1", "d"),
+		})
+		require.Len(t, out, 3)
+		assert.Equal(t, "ab", out[0].(*TextMessageContentEvent).Delta)
+		assert.Equal(t, "c", out[1].(*TextMessageContentEvent).Delta)
+		assert.Equal(t, "d", out[2].(*TextMessageContentEvent).Delta)
+	})
+
+	t.Run("EditsAreNotMerged", func(t *testing.T) {
+		edit := NewTextMessageContentEventWithOptions("msg-1", "", WithTextEdit(TextEdit{Offset: 0, Delete: 1}))
+		out := coalesceContent(t, NewContentCoalescer(), []Event{
+			NewTextMessageContentEvent("msg-1", "a"),
+			NewTextMessageContentEvent("msg-1", "b"),
+			edit,
+			NewTextMessageContentEvent("msg-1", "c"),
+		})
+		require.Len(t, out, 3)
+		assert.Equal(t, "ab", out[0].(*TextMessageContentEvent).Delta)
+		assert.Same(t, edit, out[1])
+		assert.Equal(t, "c", out[2].(*TextMessageContentEvent).Delta)
+
+		compacted := CompactContentEvents([]Event{edit, NewTextMessageContentEvent("msg-1", "d")})
+		assert.Len(t, compacted, 2)
+	})
+
+	t.Run("SingleEventIsKept", func(t *testing.T) {
+		event := NewTextMessageContentEvent("msg-1", "Hello")
+		out := coalesceContent(t, NewContentCoalescer(), []Event{event})
+		require.Len(t, out, 1)
+		assert.Same(t, event, out[0])
+	})
+
+	t.Run("UTF8Integrity", func(t *testing.T) {
+		// "café 🎉!" with both multi-byte sequences split across deltas
+		deltas := []string{"caf", "\xc3", "\xa9 \xf0\x9f", "\x8e", "\x89!"}
+		var events []Event
+		for _, delta := range deltas {
+			events = append(events, NewTextMessageContentEvent("msg-1", delta))
+		}
+
+		for maxBytes := 1; maxBytes <= 12; maxBytes++ {
+			c := NewContentCoalescer(WithContentMaxBytes(maxBytes), WithContentInterval(0))
+			out := coalesceContent(t, c, events)
+
+			assert.Equal(t, "café 🎉!", contentOf(out, "msg-1"), "max bytes %d", maxBytes)
+			for _, e := range out {
+				delta := e.(*TextMessageContentEvent).Delta
+				assert.True(t, utf8.ValidString(delta), "max bytes %d: %q", maxBytes, delta)
+				assert.NotEmpty(t, delta)
+			}
+		}
+	})
+}
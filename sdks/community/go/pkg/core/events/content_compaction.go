@@ -0,0 +1,80 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MergeContentEvents merges two consecutive content events of the same
+// message into one whose delta is the concatenation of both. The result is a
+// new event carrying the timestamp, correlation ID and sequence of a, and no
+// raw event, since neither original payload describes it. It fails if either
+// event is nil or carries a TextEdit, or if the message IDs differ.
+func MergeContentEvents(a, b *TextMessageContentEvent) (*TextMessageContentEvent, error) {
+	if a == nil || b == nil {
+		return nil, fmt.Errorf("cannot merge nil content event")
+	}
+	if a.MessageID != b.MessageID {
+		return nil, fmt.Errorf("cannot merge content of message %q into message %q", b.MessageID, a.MessageID)
+	}
+	if a.Edit != nil || b.Edit != nil {
+		return nil, fmt.Errorf("cannot merge text edits of message %q", a.MessageID)
+	}
+
+	merged := a.Clone()
+	merged.Delta = a.Delta + b.Delta
+	if merged.BaseEvent != nil {
+		merged.RawEvent = nil
+	}
+	return merged, nil
+}
+
+// CompactContentEvents collapses every run of consecutive TEXT_MESSAGE_CONTENT
+// events of the same message into a single event, as MergeContentEvents
+// does, for post-processing recorded streams. Other events, content events
+// carrying a TextEdit, and content events that are not part of a run, are
+// kept as they are and in order. The input slice is not modified.
+func CompactContentEvents(events []Event) []Event {
+	compacted := make([]Event, 0, len(events))
+
+	for i := 0; i < len(events); {
+		first, ok := events[i].(*TextMessageContentEvent)
+		if !ok || first == nil || first.Edit != nil {
+			compacted = append(compacted, events[i])
+			i++
+			continue
+		}
+
+		// Find the end of the run of content for the same message
+		end := i + 1
+		for end < len(events) {
+			next, ok := events[end].(*TextMessageContentEvent)
+			if !ok || next == nil || next.Edit != nil || next.MessageID != first.MessageID {
+				break
+			}
+			end++
+		}
+
+		if end-i == 1 {
+			compacted = append(compacted, first)
+			i = end
+			continue
+		}
+
+		// Build the delta in one pass rather than merging pairwise, which
+		// would copy it once per event
+		var delta strings.Builder
+		for _, e := range events[i:end] {
+			delta.WriteString(e.(*TextMessageContentEvent).Delta)
+		}
+		merged := first.Clone()
+		merged.Delta = delta.String()
+		if merged.BaseEvent != nil {
+			merged.RawEvent = nil
+		}
+		compacted = append(compacted, merged)
+		i = end
+	}
+
+	return compacted
+}
@@ -0,0 +1,95 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeContentEvents(t *testing.T) {
+	a := NewTextMessageContentEvent("msg-1", "Hello, ")
+	a.SetTimestamp(1000)
+	a.RawEvent = map[string]any{"delta": "Hello, "}
+	b := NewTextMessageContentEvent("msg-1", "world")
+	b.SetTimestamp(2000)
+
+	merged, err := MergeContentEvents(a, b)
+	require.NoError(t, err)
+	assert.Equal(t, "msg-1", merged.MessageID)
+	assert.Equal(t, "Hello, world", merged.Delta)
+	require.NotNil(t, merged.Timestamp())
+	assert.Equal(t, int64(1000), *merged.Timestamp())
+	assert.Nil(t, merged.RawEvent)
+	require.NoError(t, merged.Validate())
+
+	assert.Equal(t, "Hello, ", a.Delta, "inputs must not be modified")
+	assert.NotNil(t, a.RawEvent)
+
+	_, err = MergeContentEvents(a, NewTextMessageContentEvent("msg-2", "other"))
+	assert.Error(t, err)
+
+	_, err = MergeContentEvents(a, nil)
+	assert.Error(t, err)
+	_, err = MergeContentEvents(nil, b)
+	assert.Error(t, err)
+}
+
+func TestCompactContentEvents(t *testing.T) {
+	stream := []Event{
+		NewRunStartedEvent("thread-1", "run-1"),
+		NewTextMessageStartEvent("msg-1"),
+		NewTextMessageContentEvent("msg-1", "The "),
+		NewTextMessageContentEvent("msg-1", "quick "),
+		NewTextMessageContentEvent("msg-1", "fox"),
+		NewTextMessageStartEvent("msg-2"),
+		NewTextMessageContentEvent("msg-2", "Lone"),
+		NewTextMessageContentEvent("msg-1", " jumps"),
+		NewTextMessageContentEvent("msg-1", " over"),
+		NewTextMessageEndEvent("msg-1"),
+		NewTextMessageContentEvent("msg-2", " delta"),
+		NewTextMessageEndEvent("msg-2"),
+		NewRunFinishedEvent("thread-1", "run-1"),
+	}
+	original := append([]Event(nil), stream...)
+
+	compacted := CompactContentEvents(stream)
+
+	var summary []string
+	for _, e := range compacted {
+		if content, ok := e.(*TextMessageContentEvent); ok {
+			summary = append(summary, content.MessageID+":"+content.Delta)
+		} else {
+			summary = append(summary, string(e.Type()))
+		}
+	}
+	assert.Equal(t, []string{
+		"RUN_STARTED",
+		"TEXT_MESSAGE_START",
+		"msg-1:The quick fox",
+		"TEXT_MESSAGE_START",
+		"msg-2:Lone",
+		"msg-1: jumps over",
+		"TEXT_MESSAGE_END",
+		"msg-2: delta",
+		"TEXT_MESSAGE_END",
+		"RUN_FINISHED",
+	}, summary)
+
+	// Events outside runs are passed through as they are
+	assert.Same(t, stream[0], compacted[0])
+	assert.Same(t, stream[6], compacted[4])
+
+	// The input is left untouched
+	assert.Equal(t, original, stream)
+	assert.Equal(t, "The ", stream[2].(*TextMessageContentEvent).Delta)
+
+	// The compacted stream still validates as a sequence
+	_, err := ValidateSequence(compacted, DefaultProfile())
+	assert.NoError(t, err)
+}
+
+func TestCompactContentEvents_Empty(t *testing.T) {
+	assert.Empty(t, CompactContentEvents(nil))
+	assert.NotNil(t, CompactContentEvents(nil))
+}
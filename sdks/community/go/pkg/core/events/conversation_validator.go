@@ -0,0 +1,188 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidRoleTransition is returned when a message's role may not follow
+// the role of the previous message
+var ErrInvalidRoleTransition = errors.New("invalid message role transition")
+
+// Rules reported by the ConversationValidator
+const (
+	RuleInvalidRoleTransition    = "invalid_role_transition"
+	RuleUnansweredUserMessage    = "unanswered_user_message"
+	RuleToolMessageWithoutResult = "tool_message_without_result"
+)
+
+// DefaultRoleTransitions lists, for the role of each message, the roles the
+// next message may have. The empty role stands for the start of the
+// conversation. Tool messages only follow assistant or tool messages, and
+// system or developer messages may be inserted anywhere else. The validator
+// additionally requires an assistant message between two user messages,
+// which transitions between consecutive roles cannot express.
+func DefaultRoleTransitions() map[string][]string {
+	return map[string][]string{
+		"":            {RoleSystem, RoleDeveloper, RoleUser, RoleAssistant},
+		RoleSystem:    {RoleSystem, RoleDeveloper, RoleUser, RoleAssistant},
+		RoleDeveloper: {RoleSystem, RoleDeveloper, RoleUser, RoleAssistant},
+		RoleUser:      {RoleSystem, RoleDeveloper, RoleAssistant},
+		RoleAssistant: {RoleSystem, RoleDeveloper, RoleUser, RoleAssistant, RoleTool},
+		RoleTool:      {RoleAssistant, RoleTool},
+	}
+}
+
+// ConversationValidator checks that the roles of successive messages follow
+// a state machine of allowed transitions. With the default transitions, a
+// user message must also be answered by an assistant message before the next
+// user message, even when system or developer messages come in between. In
+// addition, every tool message must be preceded by a TOOL_CALL_RESULT event
+// whose messageId is the ID of the tool message, which ties the message to
+// the result of its tool call. Its errors are SequenceErrors wrapping
+// ErrInvalidRoleTransition. It is not safe for concurrent use.
+type ConversationValidator struct {
+	transitions map[string]map[string]bool
+
+	// index is the position of the next event given to Process
+	index int
+
+	// answerUsers requires an assistant message between user messages, and
+	// unanswered records a user message awaiting its answer
+	answerUsers bool
+	unanswered  bool
+
+	lastRole string
+
+	// toolResults holds the tool call IDs of the results recorded by
+	// Process, by the ID of the tool message expected to carry them
+	toolResults map[string]string
+}
+
+// ConversationValidatorOption defines options for creating conversation
+// validators
+type ConversationValidatorOption func(*ConversationValidator)
+
+// WithRoleTransitions replaces the default role transitions, including the
+// rule that user messages are answered before the next one. The map lists,
+// for each role, the roles allowed to follow it, with the empty role
+// standing for the start of the conversation. A role without an entry may
+// not be followed by any message.
+func WithRoleTransitions(transitions map[string][]string) ConversationValidatorOption {
+	return func(v *ConversationValidator) {
+		v.transitions = roleTransitionSet(transitions)
+		v.answerUsers = false
+	}
+}
+
+// NewConversationValidator creates a conversation validator using
+// DefaultRoleTransitions unless WithRoleTransitions is given
+func NewConversationValidator(options ...ConversationValidatorOption) *ConversationValidator {
+	v := &ConversationValidator{
+		transitions: roleTransitionSet(DefaultRoleTransitions()),
+		answerUsers: true,
+		toolResults: make(map[string]string),
+	}
+
+	for _, opt := range options {
+		opt(v)
+	}
+
+	return v
+}
+
+// roleTransitionSet indexes transitions for lookup
+func roleTransitionSet(transitions map[string][]string) map[string]map[string]bool {
+	set := make(map[string]map[string]bool, len(transitions))
+	for from, roles := range transitions {
+		set[from] = make(map[string]bool, len(roles))
+		for _, to := range roles {
+			set[from][to] = true
+		}
+	}
+	return set
+}
+
+// Process validates TEXT_MESSAGE_START events with ValidateMessage and
+// records TOOL_CALL_RESULT events, each of which allows the tool message
+// with its messageId. Other events are ignored.
+func (v *ConversationValidator) Process(e Event) error {
+	index := v.index
+	v.index++
+
+	switch event := e.(type) {
+	case *TextMessageStartEvent:
+		return v.validateMessage(event, index)
+	case *ToolCallResultEvent:
+		v.toolResults[event.MessageID] = event.ToolCallID
+	}
+	return nil
+}
+
+// ValidateMessage checks that the role of the message may follow the role of
+// the previous message, and records it as the previous role when it does. A
+// message without a role is an assistant message. Tool messages also require
+// the TOOL_CALL_RESULT event of their tool call, recorded by Process.
+func (v *ConversationValidator) ValidateMessage(e *TextMessageStartEvent) error {
+	return v.validateMessage(e, -1)
+}
+
+// validateMessage validates a message started by the event at index, or -1
+// if the event is not part of the sequence given to Process
+func (v *ConversationValidator) validateMessage(e *TextMessageStartEvent, index int) error {
+	role := RoleAssistant
+	if e.Role != nil {
+		role = *e.Role
+	}
+
+	if !v.transitions[v.lastRole][role] {
+		from := v.lastRole
+		if from == "" {
+			from = "start of conversation"
+		}
+		return conversationError(index, RuleInvalidRoleTransition,
+			"message %s with role %s cannot follow %s", e.MessageID, role, from)
+	}
+	if role == RoleUser && v.answerUsers && v.unanswered {
+		return conversationError(index, RuleUnansweredUserMessage,
+			"user message %s follows a user message without an assistant message in between", e.MessageID)
+	}
+	if role == RoleTool {
+		if _, ok := v.toolResults[e.MessageID]; !ok {
+			return conversationError(index, RuleToolMessageWithoutResult,
+				"tool message %s does not follow the result of its tool call", e.MessageID)
+		}
+		delete(v.toolResults, e.MessageID)
+	}
+
+	switch role {
+	case RoleUser:
+		v.unanswered = true
+	case RoleAssistant:
+		v.unanswered = false
+	}
+	v.lastRole = role
+	return nil
+}
+
+// conversationError creates the error for a message breaking the rule
+func conversationError(index int, rule, format string, args ...any) *SequenceError {
+	return &SequenceError{
+		EventError: EventError{
+			EventType: EventTypeTextMessageStart,
+			Field:     "role",
+			Message:   ErrInvalidRoleTransition.Error() + ": " + fmt.Sprintf(format, args...),
+		},
+		EventIndex: index,
+		Rule:       rule,
+		Err:        ErrInvalidRoleTransition,
+	}
+}
+
+// Reset forgets the previous messages and tool call results
+func (v *ConversationValidator) Reset() {
+	v.index = 0
+	v.unanswered = false
+	v.lastRole = ""
+	v.toolResults = make(map[string]string)
+}
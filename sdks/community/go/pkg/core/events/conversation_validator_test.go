@@ -0,0 +1,111 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConversationValidator(t *testing.T) {
+	start := func(id, role string) *TextMessageStartEvent {
+		return NewTextMessageStartEvent(id, WithRole(role))
+	}
+	result := func(messageID, toolCallID string) *ToolCallResultEvent {
+		return NewToolCallResultEvent(messageID, toolCallID, "sunny")
+	}
+
+	t.Run("ValidConversation", func(t *testing.T) {
+		v := NewConversationValidator()
+		for _, e := range []Event{
+			start("m1", RoleSystem),
+			start("m2", RoleUser),
+			NewTextMessageStartEvent("m3"), // assistant by default
+			NewToolCallStartEvent("call-1", "weather"),
+			NewToolCallStartEvent("call-2", "weather"),
+			result("m5", "call-2"),
+			result("m4", "call-1"),
+			start("m4", RoleTool),
+			start("m5", RoleTool),
+			start("m6", RoleAssistant),
+			start("m7", RoleSystem),
+			start("m8", RoleUser),
+		} {
+			require.NoError(t, v.Process(e))
+		}
+	})
+
+	tests := []struct {
+		name   string
+		events []Event
+	}{
+		{"UserAfterUser", []Event{start("m1", RoleUser), start("m2", RoleUser)}},
+		{"UserAfterUserWithSystemInBetween", []Event{
+			start("m1", RoleUser), start("m2", RoleSystem), start("m3", RoleUser),
+		}},
+		{"UserAfterUserWithDeveloperInBetween", []Event{
+			start("m1", RoleUser), start("m2", RoleDeveloper), start("m3", RoleUser),
+		}},
+		{"ToolWithoutResult", []Event{start("m1", RoleUser), start("m2", RoleAssistant), start("m3", RoleTool)}},
+		{"ToolAfterUser", []Event{start("m1", RoleUser), result("m2", "call-1"), start("m2", RoleTool)}},
+		{"ToolAtStart", []Event{result("m1", "call-1"), start("m1", RoleTool)}},
+		{"ResultOfAnotherToolMessage", []Event{
+			start("m1", RoleAssistant), result("m2", "call-1"), start("m3", RoleTool),
+		}},
+		{"OneResultPerToolMessage", []Event{
+			start("m1", RoleAssistant), result("m2", "call-1"), start("m2", RoleTool), start("m2", RoleTool),
+		}},
+		{"UnknownRole", []Event{start("m1", "narrator")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewConversationValidator()
+			var err error
+			for _, e := range tt.events {
+				if err = v.Process(e); err != nil {
+					break
+				}
+			}
+			assert.ErrorIs(t, err, ErrInvalidRoleTransition)
+		})
+	}
+
+	t.Run("RejectedMessagesAreNotRecorded", func(t *testing.T) {
+		v := NewConversationValidator()
+		require.NoError(t, v.ValidateMessage(start("m1", RoleUser)))
+		require.Error(t, v.ValidateMessage(start("m2", RoleUser)))
+		assert.NoError(t, v.ValidateMessage(start("m3", RoleAssistant)))
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		v := NewConversationValidator()
+		require.NoError(t, v.ValidateMessage(start("m1", RoleUser)))
+		v.Reset()
+		assert.NoError(t, v.ValidateMessage(start("m2", RoleUser)))
+	})
+}
+
+func TestConversationValidator_CustomTransitions(t *testing.T) {
+	// Strict turn taking between the user and the assistant
+	v := NewConversationValidator(WithRoleTransitions(map[string][]string{
+		"":            {RoleUser},
+		RoleUser:      {RoleAssistant},
+		RoleAssistant: {RoleUser},
+	}))
+
+	assert.ErrorIs(t, v.ValidateMessage(NewTextMessageStartEvent("m1")), ErrInvalidRoleTransition)
+	require.NoError(t, v.ValidateMessage(NewTextMessageStartEvent("m1", WithRole(RoleUser))))
+	require.NoError(t, v.ValidateMessage(NewTextMessageStartEvent("m2")))
+
+	err := v.ValidateMessage(NewTextMessageStartEvent("m3", WithRole(RoleAssistant)))
+	require.ErrorIs(t, err, ErrInvalidRoleTransition)
+	assert.Contains(t, err.Error(), "message m3 with role assistant cannot follow assistant")
+
+	// Custom transitions also replace the rule that user messages are answered
+	v = NewConversationValidator(WithRoleTransitions(map[string][]string{
+		"":       {RoleUser},
+		RoleUser: {RoleUser},
+	}))
+	require.NoError(t, v.ValidateMessage(NewTextMessageStartEvent("m1", WithRole(RoleUser))))
+	assert.NoError(t, v.ValidateMessage(NewTextMessageStartEvent("m2", WithRole(RoleUser))))
+}
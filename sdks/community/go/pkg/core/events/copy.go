@@ -0,0 +1,200 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// CopyEvent returns a deep copy of the event, so that callers such as
+// middleware can modify the copy without affecting the original. Known event
+// types are copied with their Clone method; raw events are copied through a
+// JSON round-trip. Free-form values (snapshots, results, custom values) are
+// copied when they are made of generic JSON values (map[string]any, []any and
+// scalars); values of other types are shared with the original.
+func CopyEvent(event Event) (Event, error) {
+	switch e := event.(type) {
+	case nil:
+		return nil, fmt.Errorf("cannot copy nil event")
+	case *TextMessageStartEvent:
+		return e.Clone(), nil
+	case *TextMessageContentEvent:
+		return e.Clone(), nil
+	case *TextMessageEndEvent:
+		return e.Clone(), nil
+	case *TextMessageChunkEvent:
+		return e.Clone(), nil
+	case *ToolCallStartEvent:
+		return e.Clone(), nil
+	case *ToolCallArgsEvent:
+		return e.Clone(), nil
+	case *ToolCallEndEvent:
+		return e.Clone(), nil
+	case *ToolCallResultEvent:
+		return e.Clone(), nil
+	case *ToolCallChunkEvent:
+		return e.Clone(), nil
+	case *StateSnapshotEvent:
+		return e.Clone(), nil
+	case *StateDeltaEvent:
+		return e.Clone(), nil
+	case *MessagesSnapshotEvent:
+		return e.Clone(), nil
+	case *RunStartedEvent:
+		return e.Clone(), nil
+	case *RunFinishedEvent:
+		return e.Clone(), nil
+	case *RunErrorEvent:
+		return e.Clone(), nil
+	case *StepStartedEvent:
+		return e.Clone(), nil
+	case *StepFinishedEvent:
+		return e.Clone(), nil
+	case *ThreadCreatedEvent:
+		return e.Clone(), nil
+	case *ThreadDeletedEvent:
+		return e.Clone(), nil
+	case *MetadataEvent:
+		return e.Clone(), nil
+	case *ThinkingStartEvent:
+		return e.Clone(), nil
+	case *ThinkingEndEvent:
+		return e.Clone(), nil
+	case *ThinkingTextMessageStartEvent:
+		return e.Clone(), nil
+	case *ThinkingTextMessageContentEvent:
+		return e.Clone(), nil
+	case *ThinkingTextMessageEndEvent:
+		return e.Clone(), nil
+	case *CustomEvent:
+		return e.Clone(), nil
+	case *RawEvent:
+		return copyRawEvent(e)
+	default:
+		return nil, fmt.Errorf("cannot copy unknown event type %T", event)
+	}
+}
+
+// copyRawEvent copies a raw event through a JSON round-trip, since its payload
+// is arbitrary
+func copyRawEvent(e *RawEvent) (*RawEvent, error) {
+	data, err := e.ToJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy raw event: %w", err)
+	}
+
+	clone := &RawEvent{}
+	if err := json.Unmarshal(data, clone); err != nil {
+		return nil, fmt.Errorf("failed to copy raw event: %w", err)
+	}
+	if clone.BaseEvent == nil {
+		clone.BaseEvent = &BaseEvent{EventType: EventTypeRaw}
+	}
+	return clone, nil
+}
+
+// copyBase returns a deep copy of the base event
+func (b *BaseEvent) copyBase() *BaseEvent {
+	if b == nil {
+		return nil
+	}
+	clone := *b
+	clone.TimestampMs = cloneInt64(b.TimestampMs)
+	clone.CorrelationIDValue = cloneString(b.CorrelationIDValue)
+	clone.SignatureValue = cloneString(b.SignatureValue)
+	clone.RawEvent = deepCopyJSONValue(b.RawEvent)
+	return &clone
+}
+
+// cloneString copies an optional string
+func cloneString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	clone := *s
+	return &clone
+}
+
+// cloneBool copies an optional boolean
+func cloneBool(b *bool) *bool {
+	if b == nil {
+		return nil
+	}
+	clone := *b
+	return &clone
+}
+
+// cloneInt copies an optional integer
+func cloneInt(i *int) *int {
+	if i == nil {
+		return nil
+	}
+	clone := *i
+	return &clone
+}
+
+// cloneInt64 copies an optional integer
+func cloneInt64(i *int64) *int64 {
+	if i == nil {
+		return nil
+	}
+	clone := *i
+	return &clone
+}
+
+// cloneUint64 copies an optional unsigned integer
+func cloneUint64(i *uint64) *uint64 {
+	if i == nil {
+		return nil
+	}
+	clone := *i
+	return &clone
+}
+
+// cloneTime copies an optional time
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	clone := *t
+	return &clone
+}
+
+// cloneJSONPatchOperations copies a list of JSON Patch operations
+func cloneJSONPatchOperations(ops []JSONPatchOperation) []JSONPatchOperation {
+	if ops == nil {
+		return nil
+	}
+	clone := make([]JSONPatchOperation, len(ops))
+	for i, op := range ops {
+		clone[i] = op
+		clone[i].Value = deepCopyJSONValue(op.Value)
+	}
+	return clone
+}
+
+// cloneMetadata copies an optional metadata map
+func cloneMetadata(metadata map[string]any) map[string]any {
+	if metadata == nil {
+		return nil
+	}
+	return deepCopyJSONValue(metadata).(map[string]any)
+}
+
+// cloneMessages copies a list of messages, including their tool calls
+func cloneMessages(messages []Message) []Message {
+	if messages == nil {
+		return nil
+	}
+	clone := make([]Message, len(messages))
+	for i, msg := range messages {
+		clone[i] = msg
+		clone[i].Content = cloneString(msg.Content)
+		clone[i].Name = cloneString(msg.Name)
+		clone[i].ToolCallID = cloneString(msg.ToolCallID)
+		if msg.ToolCalls != nil {
+			clone[i].ToolCalls = append([]ToolCall(nil), msg.ToolCalls...)
+		}
+	}
+	return clone
+}
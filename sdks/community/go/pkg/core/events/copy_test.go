@@ -0,0 +1,171 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// copyableEvents returns one populated event of every known type
+func copyableEvents() []Event {
+	chunk := NewToolCallChunkEvent()
+	chunk.ToolCallID = strPtr("tool-1")
+	chunk.ToolCallName = strPtr("search")
+	chunk.ParentMessageID = strPtr("msg-1")
+	chunk.Delta = strPtr(`{"q":`)
+
+	thinking := NewThinkingStartEvent()
+	thinking.Title = strPtr("Planning")
+
+	finished := NewRunFinishedEvent("thread-1", "run-1")
+	finished.Result = map[string]any{"answer": []any{"a", "b"}}
+
+	return []Event{
+		NewTextMessageStartEvent("msg-1", WithRole("assistant"), WithFunctionCallID("call-1")),
+		NewTextMessageContentEvent("msg-1", "Hello"),
+		NewTextMessageEndEvent("msg-1"),
+		NewTextMessageChunkEvent(strPtr("msg-1"), strPtr("assistant"), strPtr("Hi")),
+		NewToolCallStartEvent("tool-1", "search", WithParentMessageID("msg-1"), WithTimeoutMs(500), WithExpiresAt(time.Unix(1700000000, 0).UTC())),
+		NewToolCallArgsEvent("tool-1", `{"q":"go"}`),
+		NewToolCallEndEvent("tool-1"),
+		NewToolCallResultEvent("msg-2", "tool-1", "42", WithCacheKey("search:42"), WithCacheHit(true), WithCacheTTL(time.Minute),
+			WithStreamingHint(StreamingHint{Enabled: true, ChunkSize: 8})),
+		chunk,
+		NewStateSnapshotEvent(map[string]any{"nested": map[string]any{"count": float64(1)}}),
+		NewStateDeltaEventWithOptions([]JSONPatchOperation{{Op: "add", Path: "/items", Value: []any{"x"}}}, WithBaseVersion(3), WithNewVersion(4)),
+		NewMessagesSnapshotEvent([]Message{{
+			ID:        "msg-1",
+			Role:      RoleAssistant,
+			Content:   strPtr("Hello"),
+			ToolCalls: []ToolCall{{ID: "tool-1", Type: "function", Function: Function{Name: "search", Arguments: "{}"}}},
+		}}),
+		NewRunStartedEventWithOptions("thread-1", "run-1", WithAgentID("agent"), WithAgentVersion("1.0"), WithConversationIDStarted("conv-1"), WithModelID("gpt-4o"), WithModelProvider(ProviderOpenAI)),
+		finished,
+		NewRunErrorEvent("boom", WithErrorCode("E1"), WithRunID("run-1")),
+		NewStepStartedEvent("plan"),
+		NewStepFinishedEvent("plan"),
+		NewThreadCreatedEvent("thread-1", WithThreadCreatedMetadata(map[string]any{"tags": []any{"a"}})),
+		NewThreadDeletedEvent("thread-1", WithThreadDeletedMetadata(map[string]any{"reason": "expired"})),
+		NewMetadataEvent(map[string]any{"flags": map[string]any{"beta": true}}),
+		thinking,
+		NewThinkingEndEvent(),
+		NewThinkingTextMessageStartEvent(),
+		NewThinkingTextMessageContentEvent("hmm"),
+		NewThinkingTextMessageEndEvent(),
+		NewCustomEvent("progress", WithValue(map[string]any{"percent": float64(50)})),
+		NewRawEvent(map[string]any{"vendor": "data"}, WithSource("upstream")),
+	}
+}
+
+func TestCopyEvent(t *testing.T) {
+	for _, event := range copyableEvents() {
+		t.Run(string(event.Type()), func(t *testing.T) {
+			clone, err := CopyEvent(event)
+			require.NoError(t, err)
+
+			assert.Equal(t, event, clone)
+			assert.NotSame(t, event, clone)
+			assert.NotSame(t, event.GetBaseEvent(), clone.GetBaseEvent())
+
+			// Modifying the copy leaves the original untouched
+			original, err := event.ToJSON()
+			require.NoError(t, err)
+			clone.SetTimestamp(1)
+			mutateEvent(clone)
+			after, err := event.ToJSON()
+			require.NoError(t, err)
+			assert.JSONEq(t, string(original), string(after))
+		})
+	}
+
+	t.Run("UnknownAndNil", func(t *testing.T) {
+		_, err := CopyEvent(nil)
+		assert.Error(t, err)
+
+		_, err = CopyEvent(&BaseEvent{EventType: EventTypeUnknown})
+		assert.Error(t, err)
+	})
+}
+
+// mutateEvent modifies the nested values of an event in place
+func mutateEvent(event Event) {
+	switch e := event.(type) {
+	case *TextMessageStartEvent:
+		*e.Role = "user"
+		*e.FunctionCallID = "changed"
+	case *TextMessageChunkEvent:
+		*e.Delta = "changed"
+	case *ToolCallStartEvent:
+		*e.ParentMessageID = "changed"
+	case *ToolCallResultEvent:
+		*e.Role = "changed"
+		*e.CacheKey = "changed"
+		*e.CacheHit = false
+		*e.CacheTTLSeconds = 1
+	case *ToolCallChunkEvent:
+		*e.Delta = "changed"
+	case *StateSnapshotEvent:
+		e.Snapshot.(map[string]any)["nested"].(map[string]any)["count"] = float64(2)
+	case *StateDeltaEvent:
+		e.Delta[0].Value.([]any)[0] = "changed"
+		*e.BaseVersion = 99
+	case *MessagesSnapshotEvent:
+		*e.Messages[0].Content = "changed"
+		e.Messages[0].ToolCalls[0].ID = "changed"
+	case *RunStartedEvent:
+		*e.AgentID = "changed"
+		*e.ConversationID = "changed"
+		*e.ModelID = "changed"
+	case *RunFinishedEvent:
+		e.Result.(map[string]any)["answer"].([]any)[0] = "changed"
+	case *RunErrorEvent:
+		*e.Code = "changed"
+	case *ThinkingStartEvent:
+		*e.Title = "changed"
+	case *CustomEvent:
+		e.Value.(map[string]any)["percent"] = float64(100)
+	case *RawEvent:
+		e.Event.(map[string]any)["vendor"] = "changed"
+	}
+}
+
+// jsonRoundTrip copies an event by serializing and decoding it
+func jsonRoundTrip(decoder *EventDecoder, event Event) (Event, error) {
+	data, err := event.ToJSON()
+	if err != nil {
+		return nil, err
+	}
+	return decoder.DecodeEvent(string(event.Type()), data)
+}
+
+func BenchmarkCopyEvent(b *testing.B) {
+	events := copyableEvents()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, event := range events {
+			if _, err := CopyEvent(event); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkCopyEventJSONRoundTrip(b *testing.B) {
+	events := copyableEvents()
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	decoder := NewEventDecoder(logger)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, event := range events {
+			if _, err := jsonRoundTrip(decoder, event); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// CorrelationIDMiddleware creates a middleware that propagates correlation
+// IDs through an event stream, as PropagateCorrelationIDs does, before
+// handing each event on
+func CorrelationIDMiddleware() EventMiddleware {
+	propagate := PropagateCorrelationIDs()
+	return func(next EventHandler) EventHandler {
+		return EventHandlerFunc(func(ctx context.Context, event Event) error {
+			event, err := propagate(event)
+			if err != nil {
+				return err
+			}
+			return next.HandleEvent(ctx, event)
+		})
+	}
+}
+
+// PropagateCorrelationIDs returns a hook that propagates correlation IDs
+// through an event stream. It remembers the correlation ID of every
+// RUN_STARTED event that carries one and sets it on the following events of
+// the same run: events that name a run ID, such as RUN_FINISHED, are matched
+// by that ID, and other events belong to the most recently started run.
+// Events that already have a correlation ID keep it. A run is forgotten once
+// it finishes or fails.
+//
+// The hook modifies the events it receives and returns them. It can be
+// registered with WithPostDecodeHook, and is safe for concurrent use,
+// although events of one stream must be passed to it in order.
+func PropagateCorrelationIDs() func(Event) (Event, error) {
+	var (
+		mu      sync.Mutex
+		runs    = make(map[string]string)
+		current string
+	)
+
+	return func(e Event) (Event, error) {
+		if e == nil {
+			return e, nil
+		}
+		base := e.GetBaseEvent()
+		if base == nil {
+			return e, nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		runID := e.RunID()
+		if e.Type() == EventTypeRunStarted {
+			current = runID
+			if id := base.CorrelationID(); id != "" {
+				runs[runID] = id
+			} else {
+				delete(runs, runID)
+			}
+			return e, nil
+		}
+
+		if runID == "" {
+			runID = current
+		}
+		if id, ok := runs[runID]; ok && base.CorrelationIDValue == nil {
+			base.SetCorrelationID(id)
+		}
+
+		if e.Type() == EventTypeRunFinished || e.Type() == EventTypeRunError {
+			delete(runs, runID)
+			if runID == current {
+				current = ""
+			}
+		}
+		return e, nil
+	}
+}
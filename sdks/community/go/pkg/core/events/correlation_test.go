@@ -0,0 +1,122 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorrelationID(t *testing.T) {
+	t.Run("RunStartedOption", func(t *testing.T) {
+		event := NewRunStartedEventWithOptions("thread-1", "run-1", WithCorrelationID("corr-1"))
+		assert.Equal(t, "corr-1", event.CorrelationID())
+		assert.Empty(t, NewRunStartedEvent("thread-1", "run-1").CorrelationID())
+
+		data, err := event.ToJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"correlationId":"corr-1"`)
+
+		decoded, err := EventFromJSON(data)
+		require.NoError(t, err)
+		assert.Equal(t, "corr-1", decoded.GetBaseEvent().CorrelationID())
+
+		clone := event.Clone()
+		*event.CorrelationIDValue = "changed"
+		assert.Equal(t, "corr-1", clone.CorrelationID())
+	})
+
+	t.Run("Propagation", func(t *testing.T) {
+		propagate := PropagateCorrelationIDs()
+		stream := []Event{
+			NewRunStartedEventWithOptions("thread-1", "run-1", WithCorrelationID("corr-1")),
+			NewTextMessageStartEvent("msg-1"),
+			NewTextMessageContentEvent("msg-1", "hi"),
+			NewTextMessageEndEvent("msg-1"),
+			NewRunFinishedEvent("thread-1", "run-1"),
+			NewStepStartedEvent("after-run"),
+		}
+		for _, event := range stream {
+			out, err := propagate(event)
+			require.NoError(t, err)
+			assert.Same(t, event, out)
+		}
+
+		for _, event := range stream[:5] {
+			assert.Equal(t, "corr-1", event.GetBaseEvent().CorrelationID(), "%s", event.Type())
+		}
+		assert.Empty(t, stream[5].GetBaseEvent().CorrelationID(), "events after the run")
+	})
+
+	t.Run("InterleavedRuns", func(t *testing.T) {
+		propagate := PropagateCorrelationIDs()
+		run1Finished := NewRunFinishedEvent("thread-1", "run-1")
+		run2Content := NewTextMessageContentEvent("msg-2", "hi")
+		run2Error := NewRunErrorEvent("boom", WithRunID("run-2"))
+		own := NewStepStartedEvent("own")
+		own.SetCorrelationID("explicit")
+
+		for _, event := range []Event{
+			NewRunStartedEventWithOptions("thread-1", "run-1", WithCorrelationID("corr-1")),
+			NewRunStartedEventWithOptions("thread-1", "run-2", WithCorrelationID("corr-2")),
+			run2Content,
+			own,
+			run1Finished,
+			run2Error,
+		} {
+			_, err := propagate(event)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, "corr-2", run2Content.CorrelationID())
+		assert.Equal(t, "explicit", own.CorrelationID())
+		assert.Equal(t, "corr-1", run1Finished.CorrelationID())
+		assert.Equal(t, "corr-2", run2Error.CorrelationID())
+	})
+
+	t.Run("RunWithoutCorrelationID", func(t *testing.T) {
+		propagate := PropagateCorrelationIDs()
+		content := NewTextMessageContentEvent("msg-1", "hi")
+		for _, event := range []Event{NewRunStartedEvent("thread-1", "run-1"), content} {
+			_, err := propagate(event)
+			require.NoError(t, err)
+		}
+		assert.Empty(t, content.CorrelationID())
+	})
+
+	t.Run("DecoderHook", func(t *testing.T) {
+		logger := logrus.New()
+		logger.SetLevel(logrus.PanicLevel)
+		decoder := NewEventDecoder(logger, WithPostDecodeHook(PropagateCorrelationIDs()))
+
+		started, err := NewRunStartedEventWithOptions("thread-1", "run-1", WithCorrelationID("corr-1")).ToJSON()
+		require.NoError(t, err)
+		_, err = decoder.DecodeEvent(string(EventTypeRunStarted), started)
+		require.NoError(t, err)
+
+		content, err := NewTextMessageContentEvent("msg-1", "hi").ToJSON()
+		require.NoError(t, err)
+		decoded, err := decoder.DecodeEvent(string(EventTypeTextMessageContent), content)
+		require.NoError(t, err)
+		assert.Equal(t, "corr-1", decoded.GetBaseEvent().CorrelationID())
+	})
+
+	t.Run("Middleware", func(t *testing.T) {
+		var handled []Event
+		handler := CorrelationIDMiddleware()(EventHandlerFunc(func(ctx context.Context, event Event) error {
+			handled = append(handled, event)
+			return nil
+		}))
+
+		for _, event := range []Event{
+			NewRunStartedEventWithOptions("thread-1", "run-1", WithCorrelationID("corr-1")),
+			NewTextMessageStartEvent("msg-1"),
+		} {
+			require.NoError(t, handler.HandleEvent(context.Background(), event))
+		}
+		require.Len(t, handled, 2)
+		assert.Equal(t, "corr-1", handled[1].GetBaseEvent().CorrelationID())
+	})
+}
@@ -2,9 +2,13 @@ package events
 
 import (
 	"encoding/json"
-	"fmt"
+	"strings"
 )
 
+// CustomNamespaceSeparator separates the namespace of a custom event name
+// from its local name, as in "acme/progress"
+const CustomNamespaceSeparator = "/"
+
 // RawEvent contains raw event data that should be passed through without processing
 type RawEvent struct {
 	*BaseEvent
@@ -43,7 +47,7 @@ func (e *RawEvent) Validate() error {
 	}
 
 	if e.Event == nil {
-		return fmt.Errorf("RawEvent validation failed: event field is required")
+		return newValidationError(EventTypeRaw, "event", "event field is required")
 	}
 
 	return nil
@@ -54,6 +58,21 @@ func (e *RawEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// String returns a concise human-readable summary of the event
+func (e *RawEvent) String() string {
+	return formatEvent(EventTypeRaw, appendOptionalField(nil, "source", e.Source)...)
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *RawEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *RawEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeRaw, e)
+}
+
 // CustomEvent contains custom application-specific event data
 type CustomEvent struct {
 	*BaseEvent
@@ -92,13 +111,65 @@ func (e *CustomEvent) Validate() error {
 	}
 
 	if e.Name == "" {
-		return fmt.Errorf("CustomEvent validation failed: name field is required")
+		return newValidationError(EventTypeCustom, "name", "name field is required")
+	}
+
+	if strings.Contains(e.Name, CustomNamespaceSeparator) {
+		for _, segment := range strings.Split(e.Name, CustomNamespaceSeparator) {
+			if segment == "" {
+				return newValidationError(EventTypeCustom, "name", "namespaced name %q has an empty segment", e.Name)
+			}
+		}
 	}
 
 	return nil
 }
 
+// Namespace returns the namespace of the event name, which is everything
+// before the last separator, such as "acme" for "acme/progress". Bare names
+// have no namespace and return an empty string.
+func (e *CustomEvent) Namespace() string {
+	i := strings.LastIndex(e.Name, CustomNamespaceSeparator)
+	if i < 0 {
+		return ""
+	}
+	return e.Name[:i]
+}
+
+// LocalName returns the event name without its namespace, such as
+// "progress" for "acme/progress". Bare names are returned unchanged.
+func (e *CustomEvent) LocalName() string {
+	i := strings.LastIndex(e.Name, CustomNamespaceSeparator)
+	return e.Name[i+1:]
+}
+
 // ToJSON serializes the event to JSON
 func (e *CustomEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
+
+// String returns a concise human-readable summary of the event
+func (e *CustomEvent) String() string {
+	return formatEvent(EventTypeCustom, summaryField("name", e.Name))
+}
+
+// Clone returns a deep copy of the event
+func (e *CustomEvent) Clone() *CustomEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	clone.Value = deepCopyJSONValue(e.Value)
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *CustomEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *CustomEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeCustom, e)
+}
@@ -1,207 +1,218 @@
 package events
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/sirupsen/logrus"
 )
 
-// EventDecoder handles decoding of SSE events to Go SDK event types
-type EventDecoder struct {
-	logger *logrus.Logger
-}
+// HookError wraps an error returned by a decoder hook, which aborts decoding
+type HookError struct {
+	// Stage is "pre-decode" or "post-decode"
+	Stage string
 
-// NewEventDecoder creates a new event decoder
-func NewEventDecoder(logger *logrus.Logger) *EventDecoder {
-	if logger == nil {
-		logger = logrus.New()
-	}
-	return &EventDecoder{logger: logger}
+	// Index is the position of the hook in registration order
+	Index int
+
+	// EventType is the type of the event being decoded
+	EventType EventType
+
+	// Err is the error returned by the hook
+	Err error
 }
 
-// DecodeEvent decodes a raw SSE event into the appropriate Go SDK event type
-func (ed *EventDecoder) DecodeEvent(eventName string, data []byte) (Event, error) {
-	eventType := EventType(eventName)
+// Error implements the error interface
+func (e *HookError) Error() string {
+	return fmt.Sprintf("%s hook %d failed for %s: %v", e.Stage, e.Index, e.EventType, e.Err)
+}
 
-	// Check if this is a valid event type
-	if !isValidEventType(eventType) {
-		ed.logger.WithField("event", eventName).Warn("Unknown event type")
-		return nil, fmt.Errorf("unknown event type: %s", eventName)
-	}
+// Unwrap returns the error returned by the hook
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
 
-	// Decode based on event type
-	switch eventType {
-	case EventTypeRunStarted:
-		var evt RunStartedEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode RUN_STARTED: %w", err)
-		}
-		return &evt, nil
+// EventDecoder handles decoding of SSE events to Go SDK event types.
+//
+// A decoder is configured once, by the options passed to NewEventDecoder,
+// and is not modified by decoding, so it is safe for concurrent use and may
+// be shared by several streams. Its hooks and logger are then called
+// concurrently, and must be safe for concurrent use themselves.
+type EventDecoder struct {
+	logger    EventLogger
+	preHooks  []func(eventType string, data []byte) ([]byte, error)
+	postHooks []func(Event) (Event, error)
 
-	case EventTypeRunFinished:
-		var evt RunFinishedEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode RUN_FINISHED: %w", err)
-		}
-		return &evt, nil
+	// useNumber decodes free-form numbers as json.Number
+	useNumber bool
 
-	case EventTypeRunError:
-		var evt RunErrorEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode RUN_ERROR: %w", err)
-		}
-		return &evt, nil
+	// fieldCase is the casing of the field names in the payloads
+	fieldCase FieldCase
+}
 
-	case EventTypeTextMessageStart:
-		var evt TextMessageStartEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode TEXT_MESSAGE_START: %w", err)
-		}
-		return &evt, nil
+// EventDecoderOption defines options for creating event decoders. Options are
+// only applied by NewEventDecoder: hooks cannot be registered on a decoder
+// already in use.
+type EventDecoderOption func(*EventDecoder)
+
+// WithPreDecodeHook adds a hook that transforms the raw event data before it
+// is unmarshalled, for example to decrypt or decompress it. Hooks run in
+// registration order, each receiving the output of the previous one.
+func WithPreDecodeHook(fn func(eventType string, data []byte) ([]byte, error)) EventDecoderOption {
+	return func(ed *EventDecoder) {
+		ed.preHooks = append(ed.preHooks, fn)
+	}
+}
 
-	case EventTypeTextMessageChunk:
-		var evt TextMessageChunkEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode TEXT_MESSAGE_CHUNK: %w", err)
-		}
-		return &evt, nil
+// WithPostDecodeHook adds a hook that receives each decoded event and returns
+// the event to pass on, for example to validate or enrich it. Hooks run in
+// registration order, each receiving the output of the previous one.
+func WithPostDecodeHook(fn func(Event) (Event, error)) EventDecoderOption {
+	return func(ed *EventDecoder) {
+		ed.postHooks = append(ed.postHooks, fn)
+	}
+}
 
-	case EventTypeTextMessageContent:
-		var evt TextMessageContentEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode TEXT_MESSAGE_CONTENT: %w", err)
-		}
-		return &evt, nil
+// WithDecodedNumbers decodes the numbers of free-form values, such as state
+// snapshots, delta operation values and custom event values, as json.Number
+// instead of float64, so that integers beyond float64 precision are not
+// rounded. Combine it with a StateStore created with WithPreserveNumbers to
+// keep them exact end to end.
+func WithDecodedNumbers() EventDecoderOption {
+	return func(ed *EventDecoder) {
+		ed.useNumber = true
+	}
+}
 
-	case EventTypeTextMessageEnd:
-		var evt TextMessageEndEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode TEXT_MESSAGE_END: %w", err)
-		}
-		return &evt, nil
+// WithEventLogger sets the logger used by the decoder, taking precedence over
+// the logrus logger passed to NewEventDecoder
+func WithEventLogger(logger EventLogger) EventDecoderOption {
+	return func(ed *EventDecoder) {
+		ed.logger = logger
+	}
+}
 
-	case EventTypeToolCallStart:
-		var evt ToolCallStartEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode TOOL_CALL_START: %w", err)
-		}
-		return &evt, nil
+// NewEventDecoder creates a new event decoder logging to the given logrus
+// logger, or to a new one if it is nil. Use WithEventLogger for other
+// logging backends.
+func NewEventDecoder(logger *logrus.Logger, opts ...EventDecoderOption) *EventDecoder {
+	ed := &EventDecoder{logger: NewLogrusEventLogger(logger)}
 
-	case EventTypeToolCallArgs:
-		var evt ToolCallArgsEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode TOOL_CALL_ARGS: %w", err)
-		}
-		return &evt, nil
+	for _, opt := range opts {
+		opt(ed)
+	}
 
-	case EventTypeToolCallEnd:
-		var evt ToolCallEndEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode TOOL_CALL_END: %w", err)
-		}
-		return &evt, nil
+	return ed
+}
 
-	case EventTypeToolCallResult:
-		var evt ToolCallResultEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode TOOL_CALL_RESULT: %w", err)
-		}
-		return &evt, nil
+// DecodeEvent decodes a raw SSE event into the appropriate Go SDK event type.
+// Pre-decode hooks run on the data first and post-decode hooks on the decoded
+// event; an error from either aborts decoding with a *HookError.
+func (ed *EventDecoder) DecodeEvent(eventName string, data []byte) (Event, error) {
+	eventType := EventType(eventName)
 
-	case EventTypeStateSnapshot:
-		var evt StateSnapshotEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode STATE_SNAPSHOT: %w", err)
+	for i, hook := range ed.preHooks {
+		transformed, err := hook(eventName, data)
+		if err != nil {
+			return nil, &HookError{Stage: "pre-decode", Index: i, EventType: eventType, Err: err}
 		}
-		return &evt, nil
+		data = transformed
+	}
 
-	case EventTypeStateDelta:
-		var evt StateDeltaEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode STATE_DELTA: %w", err)
+	if ed.fieldCase == FieldCaseSnake && isValidEventType(eventType) {
+		camel, err := camelCaseFields(eventType, data)
+		if err != nil {
+			return nil, newDecodeError(eventType, err)
 		}
-		return &evt, nil
+		data = camel
+	}
 
-	case EventTypeMessagesSnapshot:
-		var evt MessagesSnapshotEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode MESSAGES_SNAPSHOT: %w", err)
-		}
-		return &evt, nil
+	event, err := ed.decodeEvent(eventName, data)
+	if err != nil {
+		return nil, err
+	}
 
-	case EventTypeStepStarted:
-		var evt StepStartedEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode STEP_STARTED: %w", err)
+	for i, hook := range ed.postHooks {
+		event, err = hook(event)
+		if err != nil {
+			return nil, &HookError{Stage: "post-decode", Index: i, EventType: eventType, Err: err}
 		}
-		return &evt, nil
-
-	case EventTypeStepFinished:
-		var evt StepFinishedEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode STEP_FINISHED: %w", err)
+		if event == nil {
+			return nil, &HookError{Stage: "post-decode", Index: i, EventType: eventType, Err: errors.New("hook returned a nil event")}
 		}
-		return &evt, nil
+	}
 
-	case EventTypeThinkingStart:
-		var evt ThinkingStartEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode THINKING_START: %w", err)
-		}
-		return &evt, nil
+	assignSequence(event)
+	return event, nil
+}
 
-	case EventTypeThinkingEnd:
-		var evt ThinkingEndEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode THINKING_END: %w", err)
-		}
-		return &evt, nil
+// DecodeTypedJSON decodes an event whose type is carried in the "type" field
+// of its JSON payload, as on transports without a separate event name such as
+// WebSocket. Hooks run as in DecodeEvent.
+func (ed *EventDecoder) DecodeTypedJSON(data []byte) (Event, error) {
+	var envelope struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, newDecodeError("", err)
+	}
+	if envelope.Type == "" {
+		return nil, newDecodeError("", errors.New("missing event type"))
+	}
+	return ed.DecodeEvent(envelope.Type, data)
+}
 
-	case EventTypeThinkingTextMessageStart:
-		var evt ThinkingTextMessageStartEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode THINKING_TEXT_MESSAGE_START: %w", err)
-		}
-		return &evt, nil
+// unmarshal decodes JSON data into v, honoring WithDecodedNumbers
+func (ed *EventDecoder) unmarshal(data []byte, v any) error {
+	if !ed.useNumber {
+		return json.Unmarshal(data, v)
+	}
+	return unmarshalUseNumber(data, v)
+}
 
-	case EventTypeThinkingTextMessageContent:
-		var evt ThinkingTextMessageContentEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode THINKING_TEXT_MESSAGE_CONTENT: %w", err)
-		}
-		return &evt, nil
+// unmarshalUseNumber decodes JSON data into v like json.Unmarshal, but
+// decodes numbers in free-form values as json.Number. Like json.Unmarshal,
+// it rejects data with anything but whitespace after the value.
+func unmarshalUseNumber(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if rest := bytes.TrimLeft(data[decoder.InputOffset():], " \t\r\n"); len(rest) > 0 {
+		return fmt.Errorf("invalid character %q after top-level value", rest[0])
+	}
+	return nil
+}
 
-	case EventTypeThinkingTextMessageEnd:
-		var evt ThinkingTextMessageEndEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode THINKING_TEXT_MESSAGE_END: %w", err)
-		}
-		return &evt, nil
+// decodeEvent unmarshals the event data into the Go type of the event
+func (ed *EventDecoder) decodeEvent(eventName string, data []byte) (Event, error) {
+	eventType := EventType(eventName)
 
-	case EventTypeCustom:
-		var evt CustomEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode CUSTOM: %w", err)
-		}
-		return &evt, nil
+	// Check if this is a valid event type
+	if !isValidEventType(eventType) {
+		ed.logger.Warn("Unknown event type", map[string]any{"event": eventName})
+		return nil, newUnknownTypeError(eventType)
+	}
 
-	case EventTypeRaw:
-		var evt RawEvent
-		if err := json.Unmarshal(data, &evt); err != nil {
-			return nil, fmt.Errorf("failed to decode RAW: %w", err)
+	evt := acquireEvent(eventType)
+	if evt != nil {
+		if err := ed.unmarshal(data, evt); err != nil {
+			ReleaseEvent(evt)
+			return nil, newDecodeError(eventType, err)
 		}
-		return &evt, nil
-
-	default:
-		// For any other event types, return a raw event
-		source := string(eventType)
-		return &RawEvent{
-			BaseEvent: &BaseEvent{
-				EventType: eventType,
-			},
-			Event:  json.RawMessage(data),
-			Source: &source,
-		}, nil
+		return evt, nil
 	}
+
+	// For any other event types, return a raw event
+	source := string(eventType)
+	return &RawEvent{
+		BaseEvent: &BaseEvent{
+			EventType: eventType,
+		},
+		Event:  json.RawMessage(data),
+		Source: &source,
+	}, nil
 }
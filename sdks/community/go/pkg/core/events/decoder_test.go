@@ -1,6 +1,12 @@
 package events
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/sirupsen/logrus"
@@ -19,7 +25,18 @@ func TestEventDecoder(t *testing.T) {
 		customLogger := logrus.New()
 		decoder = NewEventDecoder(customLogger)
 		assert.NotNil(t, decoder)
-		assert.Equal(t, customLogger, decoder.logger)
+		assert.Equal(t, NewLogrusEventLogger(customLogger), decoder.logger)
+	})
+
+	t.Run("WithEventLogger", func(t *testing.T) {
+		logger := &recordingEventLogger{}
+		decoder := NewEventDecoder(nil, WithEventLogger(logger))
+
+		_, err := decoder.DecodeEvent("UNKNOWN_EVENT", []byte(`{}`))
+		require.Error(t, err)
+		require.Len(t, logger.entries, 1)
+		assert.Equal(t, "warn: Unknown event type", logger.entries[0].msg)
+		assert.Equal(t, map[string]any{"event": "UNKNOWN_EVENT"}, logger.entries[0].fields)
 	})
 
 	t.Run("DecodeEvent_RunStarted", func(t *testing.T) {
@@ -230,6 +247,48 @@ func TestEventDecoder(t *testing.T) {
 		assert.Equal(t, "step-1", stepEvent.StepName)
 	})
 
+	t.Run("DecodeEvent_ThreadCreated", func(t *testing.T) {
+		decoder := NewEventDecoder(nil)
+		data := []byte(`{"threadId": "thread-1", "metadata": {"title": "Trip planning"}}`)
+
+		event, err := decoder.DecodeEvent("THREAD_CREATED", data)
+		require.NoError(t, err)
+		require.NotNil(t, event)
+
+		threadEvent, ok := event.(*ThreadCreatedEvent)
+		require.True(t, ok)
+		assert.Equal(t, "thread-1", threadEvent.ThreadID())
+		assert.Equal(t, map[string]any{"title": "Trip planning"}, threadEvent.Metadata)
+	})
+
+	t.Run("DecodeEvent_ThreadDeleted", func(t *testing.T) {
+		decoder := NewEventDecoder(nil)
+		data := []byte(`{"threadId": "thread-1"}`)
+
+		event, err := decoder.DecodeEvent("THREAD_DELETED", data)
+		require.NoError(t, err)
+		require.NotNil(t, event)
+
+		threadEvent, ok := event.(*ThreadDeletedEvent)
+		require.True(t, ok)
+		assert.Equal(t, "thread-1", threadEvent.ThreadID())
+		assert.Nil(t, threadEvent.Metadata)
+	})
+
+	t.Run("DecodeEvent_Metadata", func(t *testing.T) {
+		decoder := NewEventDecoder(nil)
+		data := []byte(`{"data": {"locale": "nl-NL", "features": ["voice"]}}`)
+
+		event, err := decoder.DecodeEvent("METADATA", data)
+		require.NoError(t, err)
+		require.NotNil(t, event)
+
+		metadataEvent, ok := event.(*MetadataEvent)
+		require.True(t, ok)
+		assert.Equal(t, map[string]any{"locale": "nl-NL", "features": []any{"voice"}}, metadataEvent.Data)
+		assert.NoError(t, metadataEvent.Validate())
+	})
+
 	t.Run("DecodeEvent_ThinkingEvents", func(t *testing.T) {
 		decoder := NewEventDecoder(nil)
 
@@ -306,6 +365,19 @@ func TestEventDecoder(t *testing.T) {
 		assert.Contains(t, err.Error(), "unknown event type")
 	})
 
+	t.Run("DecodeEvent_AssignsSequence", func(t *testing.T) {
+		decoder := NewEventDecoder(nil)
+		data := []byte(`{"type": "STEP_STARTED", "stepName": "plan", "timestamp": 100}`)
+
+		first, err := decoder.DecodeEvent("STEP_STARTED", data)
+		require.NoError(t, err)
+		second, err := decoder.DecodeEvent("STEP_STARTED", data)
+		require.NoError(t, err)
+
+		assert.Positive(t, first.GetBaseEvent().Sequence())
+		assert.Greater(t, second.GetBaseEvent().Sequence(), first.GetBaseEvent().Sequence())
+	})
+
 	t.Run("DecodeEvent_InvalidJSON", func(t *testing.T) {
 		decoder := NewEventDecoder(nil)
 		data := []byte(`{invalid json}`)
@@ -314,4 +386,285 @@ func TestEventDecoder(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, event)
 	})
+
+	t.Run("DecodeEvent_TypeMismatch", func(t *testing.T) {
+		decoder := NewEventDecoder(nil)
+		data := []byte(`{"type": "RUN_STARTED", "threadId": 42, "runId": "run-456"}`)
+
+		event, err := decoder.DecodeEvent("RUN_STARTED", data)
+		assert.Nil(t, event)
+
+		var decodeErr *DecodeError
+		require.ErrorAs(t, err, &decodeErr)
+		assert.Equal(t, EventTypeRunStarted, decodeErr.EventType)
+		assert.Equal(t, "threadId", decodeErr.Field)
+		assert.Equal(t, int64(bytes.Index(data, []byte("42"))+2), decodeErr.Offset)
+		assert.Contains(t, err.Error(), `failed to decode RUN_STARTED field "threadId" at offset`)
+	})
+
+	t.Run("DecodeEvent_NestedTypeMismatch", func(t *testing.T) {
+		decoder := NewEventDecoder(nil)
+		data := []byte(`{"type": "MESSAGES_SNAPSHOT", "messages": [{"id": "msg-1", "role": "user"}, {"id": "msg-2", "role": 7}]}`)
+
+		_, err := decoder.DecodeEvent("MESSAGES_SNAPSHOT", data)
+		var decodeErr *DecodeError
+		require.ErrorAs(t, err, &decodeErr)
+		assert.Equal(t, "messages.1.role", decodeErr.Field)
+		assert.Positive(t, decodeErr.Offset)
+	})
+
+	t.Run("DecodeEvent_SyntaxErrorOffset", func(t *testing.T) {
+		decoder := NewEventDecoder(nil)
+		data := []byte(`{"threadId": "thread-123", "runId": }`)
+
+		_, err := decoder.DecodeEvent("RUN_STARTED", data)
+		var decodeErr *DecodeError
+		require.ErrorAs(t, err, &decodeErr)
+		assert.Empty(t, decodeErr.Field)
+		assert.Equal(t, int64(bytes.IndexByte(data, '}')+1), decodeErr.Offset)
+
+		var syntaxErr *json.SyntaxError
+		assert.ErrorAs(t, err, &syntaxErr)
+	})
+
+	t.Run("EventFromJSON_DecodeError", func(t *testing.T) {
+		_, err := EventFromJSON([]byte(`{"type": "TEXT_MESSAGE_CONTENT", "messageId": "msg-1", "delta": false}`))
+		var decodeErr *DecodeError
+		require.ErrorAs(t, err, &decodeErr)
+		assert.Equal(t, EventTypeTextMessageContent, decodeErr.EventType)
+		assert.Equal(t, "delta", decodeErr.Field)
+
+		_, err = EventFromJSON([]byte(`{"type": 1}`))
+		require.ErrorAs(t, err, &decodeErr)
+		assert.Empty(t, decodeErr.EventType)
+		assert.Equal(t, "type", decodeErr.Field)
+		assert.Equal(t, int64(10), decodeErr.Offset)
+	})
+
+	t.Run("DecodeEvent_PreDecodeHooks", func(t *testing.T) {
+		var calls []string
+		decoder := NewEventDecoder(nil,
+			WithPreDecodeHook(func(eventType string, data []byte) ([]byte, error) {
+				calls = append(calls, "first:"+eventType)
+				return bytes.ReplaceAll(data, []byte("PLACEHOLDER"), []byte("plan")), nil
+			}),
+			WithPreDecodeHook(func(eventType string, data []byte) ([]byte, error) {
+				calls = append(calls, "second:"+eventType)
+				return bytes.ReplaceAll(data, []byte("plan"), []byte("plan-step")), nil
+			}),
+		)
+
+		event, err := decoder.DecodeEvent("STEP_STARTED", []byte(`{"type": "STEP_STARTED", "stepName": "PLACEHOLDER"}`))
+		require.NoError(t, err)
+		assert.Equal(t, "plan-step", event.(*StepStartedEvent).StepName)
+		assert.Equal(t, []string{"first:STEP_STARTED", "second:STEP_STARTED"}, calls)
+	})
+
+	t.Run("DecodeEvent_PostDecodeHooks", func(t *testing.T) {
+		decoder := NewEventDecoder(nil,
+			WithPostDecodeHook(func(event Event) (Event, error) {
+				step := event.(*StepStartedEvent)
+				step.StepName += "-enriched"
+				return step, nil
+			}),
+			WithPostDecodeHook(func(event Event) (Event, error) {
+				return NewStepFinishedEvent(event.(*StepStartedEvent).StepName), nil
+			}),
+		)
+
+		event, err := decoder.DecodeEvent("STEP_STARTED", []byte(`{"type": "STEP_STARTED", "stepName": "plan"}`))
+		require.NoError(t, err)
+		finished, ok := event.(*StepFinishedEvent)
+		require.True(t, ok)
+		assert.Equal(t, "plan-enriched", finished.StepName)
+		assert.Positive(t, finished.Sequence())
+	})
+
+	t.Run("DecodeEvent_HookErrors", func(t *testing.T) {
+		errDecrypt := errors.New("decryption failed")
+		postCalled := false
+		decoder := NewEventDecoder(nil,
+			WithPreDecodeHook(func(string, []byte) ([]byte, error) { return nil, errDecrypt }),
+			WithPostDecodeHook(func(event Event) (Event, error) {
+				postCalled = true
+				return event, nil
+			}),
+		)
+
+		event, err := decoder.DecodeEvent("STEP_STARTED", []byte(`{"type": "STEP_STARTED", "stepName": "plan"}`))
+		assert.Nil(t, event)
+		var hookErr *HookError
+		require.ErrorAs(t, err, &hookErr)
+		assert.Equal(t, "pre-decode", hookErr.Stage)
+		assert.Equal(t, 0, hookErr.Index)
+		assert.Equal(t, EventTypeStepStarted, hookErr.EventType)
+		assert.ErrorIs(t, err, errDecrypt)
+		assert.False(t, postCalled)
+
+		errInvalid := errors.New("invalid step")
+		decoder = NewEventDecoder(nil,
+			WithPostDecodeHook(func(event Event) (Event, error) { return event, nil }),
+			WithPostDecodeHook(func(Event) (Event, error) { return nil, errInvalid }),
+		)
+		_, err = decoder.DecodeEvent("STEP_STARTED", []byte(`{"type": "STEP_STARTED", "stepName": "plan"}`))
+		require.ErrorAs(t, err, &hookErr)
+		assert.Equal(t, "post-decode", hookErr.Stage)
+		assert.Equal(t, 1, hookErr.Index)
+		assert.ErrorIs(t, err, errInvalid)
+
+		decoder = NewEventDecoder(nil, WithPostDecodeHook(func(Event) (Event, error) { return nil, nil }))
+		_, err = decoder.DecodeEvent("STEP_STARTED", []byte(`{"type": "STEP_STARTED", "stepName": "plan"}`))
+		require.ErrorAs(t, err, &hookErr)
+		assert.Contains(t, err.Error(), "nil event")
+	})
+
+	t.Run("DecodeTypedJSON", func(t *testing.T) {
+		decoder := NewEventDecoder(nil)
+
+		event, err := decoder.DecodeTypedJSON([]byte(`{"type": "STEP_STARTED", "stepName": "plan"}`))
+		require.NoError(t, err)
+		step, ok := event.(*StepStartedEvent)
+		require.True(t, ok)
+		assert.Equal(t, "plan", step.StepName)
+		assert.NotZero(t, step.Sequence())
+
+		var decodeErr *DecodeError
+		_, err = decoder.DecodeTypedJSON([]byte(`{"stepName": "plan"}`))
+		require.ErrorAs(t, err, &decodeErr)
+		assert.Contains(t, err.Error(), "missing event type")
+
+		_, err = decoder.DecodeTypedJSON([]byte(`{"type": `))
+		require.ErrorAs(t, err, &decodeErr)
+
+		_, err = decoder.DecodeTypedJSON([]byte(`{"type": "NOT_A_TYPE"}`))
+		assert.Error(t, err)
+	})
+}
+
+func TestEventDecoder_FieldCase(t *testing.T) {
+	t.Run("snake_case text message start", func(t *testing.T) {
+		decoder := NewEventDecoder(nil, WithFieldCase(FieldCaseSnake))
+		data := []byte(`{"type": "TEXT_MESSAGE_START", "message_id": "msg-1", "role": "assistant", "timestamp": 100}`)
+
+		event, err := decoder.DecodeEvent("TEXT_MESSAGE_START", data)
+		require.NoError(t, err)
+
+		start, ok := event.(*TextMessageStartEvent)
+		require.True(t, ok)
+		assert.Equal(t, "msg-1", start.MessageID)
+		require.NotNil(t, start.Role)
+		assert.Equal(t, "assistant", *start.Role)
+	})
+
+	t.Run("nested fields", func(t *testing.T) {
+		decoder := NewEventDecoder(nil, WithFieldCase(FieldCaseSnake))
+		data := []byte(`{"type": "MESSAGES_SNAPSHOT", "messages": [
+			{"id": "msg-1", "role": "assistant", "tool_calls": [{"id": "call-1", "type": "function", "function": {"name": "search", "arguments": "{}"}}]},
+			{"id": "msg-2", "role": "tool", "content": "done", "tool_call_id": "call-1"}
+		]}`)
+
+		event, err := decoder.DecodeEvent("MESSAGES_SNAPSHOT", data)
+		require.NoError(t, err)
+
+		snapshot := event.(*MessagesSnapshotEvent)
+		require.Len(t, snapshot.Messages, 2)
+		require.Len(t, snapshot.Messages[0].ToolCalls, 1)
+		assert.Equal(t, "search", snapshot.Messages[0].ToolCalls[0].Function.Name)
+		require.NotNil(t, snapshot.Messages[1].ToolCallID)
+		assert.Equal(t, "call-1", *snapshot.Messages[1].ToolCallID)
+	})
+
+	t.Run("free-form keys are preserved", func(t *testing.T) {
+		decoder := NewEventDecoder(nil, WithFieldCase(FieldCaseSnake))
+		data := []byte(`{"type": "STATE_SNAPSHOT", "snapshot": {"user_name": "ada", "message_id": 1}}`)
+
+		event, err := decoder.DecodeEvent("STATE_SNAPSHOT", data)
+		require.NoError(t, err)
+
+		snapshot := event.(*StateSnapshotEvent)
+		assert.Equal(t, map[string]any{"user_name": "ada", "message_id": 1.0}, snapshot.Snapshot)
+	})
+
+	t.Run("camel case is the default", func(t *testing.T) {
+		data := []byte(`{"type": "TEXT_MESSAGE_START", "message_id": "msg-1"}`)
+
+		event, err := NewEventDecoder(nil).DecodeEvent("TEXT_MESSAGE_START", data)
+		require.NoError(t, err)
+		assert.Empty(t, event.(*TextMessageStartEvent).MessageID)
+
+		event, err = NewEventDecoder(nil, WithFieldCase(FieldCaseSnake)).
+			DecodeEvent("TEXT_MESSAGE_START", []byte(`{"type": "TEXT_MESSAGE_START", "messageId": "msg-1"}`))
+		require.NoError(t, err)
+		assert.Equal(t, "msg-1", event.(*TextMessageStartEvent).MessageID)
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		decoder := NewEventDecoder(nil, WithFieldCase(FieldCaseSnake))
+		_, err := decoder.DecodeEvent("TEXT_MESSAGE_START", []byte(`{invalid`))
+
+		var decodeErr *DecodeError
+		assert.ErrorAs(t, err, &decodeErr)
+	})
+}
+
+func TestEventDecoder_Concurrent(t *testing.T) {
+	var decoded atomic.Int64
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	decoder := NewEventDecoder(logger,
+		WithFieldCase(FieldCaseSnake),
+		WithDecodedNumbers(),
+		WithPreDecodeHook(func(eventType string, data []byte) ([]byte, error) {
+			return bytes.TrimSpace(data), nil
+		}),
+		WithPostDecodeHook(func(e Event) (Event, error) {
+			decoded.Add(1)
+			return e, nil
+		}),
+	)
+
+	payloads := map[string]string{
+		"TEXT_MESSAGE_START":   `{"type": "TEXT_MESSAGE_START", "message_id": "msg-1", "role": "assistant"}`,
+		"TEXT_MESSAGE_CONTENT": `{"type": "TEXT_MESSAGE_CONTENT", "messageId": "msg-1", "delta": "Hello"}`,
+		"STATE_SNAPSHOT":       `{"type": "STATE_SNAPSHOT", "snapshot": {"count": 12345678901234567890}}`,
+		"TOOL_CALL_START":      `{"type": "TOOL_CALL_START", "tool_call_id": "call-1", "tool_call_name": "search"}`,
+		"NOT_A_TYPE":           `{}`,
+	}
+
+	const goroutines, iterations = 16, 50
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines)
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				for name, payload := range payloads {
+					event, err := decoder.DecodeEvent(name, []byte(payload))
+					if name == "NOT_A_TYPE" {
+						if err == nil {
+							errs <- errors.New("expected an error for an unknown type")
+							return
+						}
+						continue
+					}
+					if err != nil {
+						errs <- err
+						return
+					}
+					if event.Type() != EventType(name) {
+						errs <- fmt.Errorf("decoded %s as %s", name, event.Type())
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	assert.Equal(t, int64(goroutines*iterations*(len(payloads)-1)), decoded.Load())
 }
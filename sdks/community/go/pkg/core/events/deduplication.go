@@ -0,0 +1,272 @@
+package events
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// HashEvent returns a stable content hash of the event for deduplication:
+// the hex-encoded SHA-256 of its canonical JSON. The timestamp, from which
+// the event ID is derived, the correlation ID and the signature are
+// excluded, so a redelivered event hashes the same as the original.
+func HashEvent(e Event) (string, error) {
+	clone, err := CopyEvent(e)
+	if err != nil {
+		return "", err
+	}
+	if base := clone.GetBaseEvent(); base != nil {
+		base.TimestampMs = nil
+		base.CorrelationIDValue = nil
+		base.SignatureValue = nil
+	}
+	data, err := canonicalEventJSON(clone)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// canonicalEventJSON serializes an event with the object keys sorted at every
+// level, including in raw JSON carried by the event, so that equal events
+// always produce the same bytes
+func canonicalEventJSON(e Event) ([]byte, error) {
+	data, err := e.ToJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize event: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var canonical any
+	if err := decoder.Decode(&canonical); err != nil {
+		return nil, fmt.Errorf("failed to canonicalize event: %w", err)
+	}
+	if data, err = json.Marshal(canonical); err != nil {
+		return nil, fmt.Errorf("failed to canonicalize event: %w", err)
+	}
+	return data, nil
+}
+
+// DeduplicationCache remembers event hashes for a limited time. Implement
+// it to share deduplication state between processes, for example in Redis.
+//
+// Checking a key with Has and then recording it with Set is not atomic, so
+// concurrent deliveries of the same event may all be let through. Caches
+// that can check and record a key atomically, as Redis does with SET NX,
+// should also implement AtomicDeduplicationCache.
+type DeduplicationCache interface {
+	// Set records the key for the given duration
+	Set(key string, ttl time.Duration)
+	// Has reports whether the key was recorded and has not expired
+	Has(key string) bool
+}
+
+// AtomicDeduplicationCache is a DeduplicationCache that can check and record
+// a key in a single atomic operation, which Deduplicator uses instead of Has
+// and Set
+type AtomicDeduplicationCache interface {
+	DeduplicationCache
+	// SetIfAbsent records the key for the given duration unless it was
+	// recorded and has not expired, and reports whether it recorded it
+	SetIfAbsent(key string, ttl time.Duration) bool
+}
+
+// Deduplicator drops events whose content hash was seen within the TTL, to
+// cope with transports that deliver events more than once.
+//
+// Only events that carry an identity are deduplicated: the start and end of
+// runs, text messages and tool calls, tool call results, run errors with a
+// run ID, and thread events. Other events are passed on, since their content
+// is repeated legitimately and their hash cannot tell a redelivery from a
+// repetition: streamed deltas and chunks repeat tokens, a run may hold
+// several thinking blocks or enter the same step twice, and identical state
+// deltas such as appends to an array each change the state.
+type Deduplicator struct {
+	cache DeduplicationCache
+	ttl   time.Duration
+}
+
+// NewDeduplicator creates a deduplicator recording event hashes in the cache
+// for ttl
+func NewDeduplicator(cache DeduplicationCache, ttl time.Duration) *Deduplicator {
+	return &Deduplicator{cache: cache, ttl: ttl}
+}
+
+// NewDeduplicatingMiddleware creates a middleware that does not pass on
+// events duplicating an event seen within ttl, recording event hashes in the
+// cache
+func NewDeduplicatingMiddleware(cache DeduplicationCache, ttl time.Duration) EventMiddleware {
+	return NewDeduplicator(cache, ttl).Middleware()
+}
+
+// Process returns the event, or nothing if it duplicates an event seen
+// within the TTL. Events without an identity and events that cannot be
+// hashed are passed on.
+func (d *Deduplicator) Process(e Event) []Event {
+	if d.isDuplicate(e) {
+		return nil
+	}
+	return []Event{e}
+}
+
+// isDuplicate reports whether the event duplicates an event seen within the
+// TTL, recording its hash otherwise
+func (d *Deduplicator) isDuplicate(e Event) bool {
+	if !hasEventIdentity(e) {
+		return false
+	}
+	hash, err := HashEvent(e)
+	if err != nil {
+		return false
+	}
+	if cache, ok := d.cache.(AtomicDeduplicationCache); ok {
+		return !cache.SetIfAbsent(hash, d.ttl)
+	}
+	if d.cache.Has(hash) {
+		return true
+	}
+	d.cache.Set(hash, d.ttl)
+	return false
+}
+
+// hasEventIdentity reports whether an event carries the ID of the run,
+// message, tool call or thread it belongs to and occurs once for that ID, so
+// that an event with the same content is a redelivery
+func hasEventIdentity(e Event) bool {
+	switch event := e.(type) {
+	case *RunStartedEvent, *RunFinishedEvent,
+		*TextMessageStartEvent, *TextMessageEndEvent,
+		*ToolCallStartEvent, *ToolCallEndEvent, *ToolCallResultEvent,
+		*ThreadCreatedEvent, *ThreadDeletedEvent:
+		return true
+	case *RunErrorEvent:
+		return event.RunID() != ""
+	}
+	return false
+}
+
+// Middleware returns the deduplicator as an EventMiddleware, which drops
+// duplicates without calling the next handler
+func (d *Deduplicator) Middleware() EventMiddleware {
+	return func(next EventHandler) EventHandler {
+		return EventHandlerFunc(func(ctx context.Context, event Event) error {
+			if d.isDuplicate(event) {
+				return nil
+			}
+			return next.HandleEvent(ctx, event)
+		})
+	}
+}
+
+// Stage returns the deduplicator as an EventStage, which holds no events
+func (d *Deduplicator) Stage() EventStage {
+	return infallibleStage{process: d.Process}
+}
+
+// DefaultDeduplicationCacheSize is the capacity of a MemoryDeduplicationCache
+// created with a non-positive size
+const DefaultDeduplicationCacheSize = 10000
+
+// MemoryDeduplicationCache is an in-process DeduplicationCache that evicts
+// the least recently set key once it is full. It is safe for concurrent use.
+type MemoryDeduplicationCache struct {
+	mu      sync.Mutex
+	size    int
+	now     EventClock
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// dedupEntry is a key of a MemoryDeduplicationCache with its expiry
+type dedupEntry struct {
+	key     string
+	expires time.Time
+}
+
+// NewMemoryDeduplicationCache creates a cache holding up to size keys
+func NewMemoryDeduplicationCache(size int) *MemoryDeduplicationCache {
+	if size <= 0 {
+		size = DefaultDeduplicationCacheSize
+	}
+	return &MemoryDeduplicationCache{
+		size:    size,
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Set records the key for ttl, evicting the oldest key if the cache is full
+func (c *MemoryDeduplicationCache) Set(key string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(key, ttl)
+}
+
+// set records the key for ttl. The caller must hold the lock.
+func (c *MemoryDeduplicationCache) set(key string, ttl time.Duration) {
+	expires := c.now().Add(ttl)
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*dedupEntry).expires = expires
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&dedupEntry{key: key, expires: expires})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*dedupEntry).key)
+	}
+}
+
+// SetIfAbsent records the key for ttl unless it is cached and unexpired, and
+// reports whether it recorded it
+func (c *MemoryDeduplicationCache) SetIfAbsent(key string, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.has(key) {
+		return false
+	}
+	c.set(key, ttl)
+	return true
+}
+
+// Has reports whether the key is cached and unexpired
+func (c *MemoryDeduplicationCache) Has(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.has(key)
+}
+
+// has reports whether the key is cached and unexpired, evicting it if it has
+// expired. The caller must hold the lock.
+func (c *MemoryDeduplicationCache) has(key string) bool {
+	elem, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+	if !c.now().Before(elem.Value.(*dedupEntry).expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return false
+	}
+	return true
+}
+
+// Len returns the number of cached keys, including expired keys that have
+// not been evicted yet
+func (c *MemoryDeduplicationCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
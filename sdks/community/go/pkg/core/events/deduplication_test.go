@@ -0,0 +1,203 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashEvent(t *testing.T) {
+	mustHash := func(e Event) string {
+		t.Helper()
+		hash, err := HashEvent(e)
+		require.NoError(t, err)
+		return hash
+	}
+
+	t.Run("IgnoresEnvelopeFields", func(t *testing.T) {
+		first := NewTextMessageContentEvent("msg-1", "hello")
+		first.SetTimestamp(1000)
+		second := NewTextMessageContentEvent("msg-1", "hello")
+		second.SetTimestamp(2000)
+		second.SetCorrelationID("corr-1")
+
+		hash := mustHash(first)
+		assert.Len(t, hash, 64)
+		assert.Equal(t, hash, mustHash(second))
+		assert.Equal(t, int64(1000), *first.TimestampMs, "hashing must not modify the event")
+	})
+
+	t.Run("DependsOnContent", func(t *testing.T) {
+		base := mustHash(NewTextMessageContentEvent("msg-1", "hello"))
+		assert.NotEqual(t, base, mustHash(NewTextMessageContentEvent("msg-1", "hello!")))
+		assert.NotEqual(t, base, mustHash(NewTextMessageContentEvent("msg-2", "hello")))
+		assert.NotEqual(t, mustHash(NewStepStartedEvent("a")), mustHash(NewStepFinishedEvent("a")))
+	})
+
+	t.Run("CanonicalKeyOrder", func(t *testing.T) {
+		first := NewCustomEvent("c", WithValue(json.RawMessage(`{"b":1,"a":{"y":2,"x":3}}`)))
+		second := NewCustomEvent("c", WithValue(map[string]any{"a": map[string]any{"x": 3, "y": 2}, "b": 1}))
+		assert.Equal(t, mustHash(first), mustHash(second))
+	})
+
+	t.Run("DecodedEvent", func(t *testing.T) {
+		event := NewStateDeltaEvent([]JSONPatchOperation{{Op: "add", Path: "/n", Value: 42}})
+		data, err := event.ToJSON()
+		require.NoError(t, err)
+		decoded, err := NewEventDecoder(nil).DecodeEvent(string(EventTypeStateDelta), data)
+		require.NoError(t, err)
+		assert.Equal(t, mustHash(event), mustHash(decoded))
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		_, err := HashEvent(nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestDeduplicator(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	cache := NewMemoryDeduplicationCache(0)
+	cache.now = func() time.Time { return now }
+	middleware := NewDeduplicator(cache, time.Minute)
+
+	event := NewToolCallResultEvent("msg-1", "call-1", "Sunny")
+	redelivered := event.Clone()
+	redelivered.SetTimestamp(time.Now().UnixMilli())
+
+	assert.Equal(t, []Event{event}, middleware.Process(event))
+	assert.Empty(t, middleware.Process(redelivered))
+
+	other := NewToolCallResultEvent("msg-2", "call-2", "Sunny")
+	assert.Equal(t, []Event{other}, middleware.Process(other))
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, []Event{redelivered}, middleware.Process(redelivered))
+	assert.Empty(t, middleware.Process(event))
+
+	// Events without an identity repeat legitimately, as repeated tokens,
+	// repeated steps or appends to the same array
+	for _, repeated := range []Event{
+		NewTextMessageContentEvent("msg-1", "l"),
+		NewThinkingTextMessageContentEvent("l"),
+		NewToolCallArgsEvent("call-1", "0"),
+		NewTextMessageChunkEvent(strPtr("msg-1"), nil, strPtr("l")),
+		NewToolCallChunkEvent(),
+		NewStepStartedEvent("search"),
+		NewStateDeltaEvent([]JSONPatchOperation{{Op: "add", Path: "/list/-", Value: 1}}),
+		NewRunErrorEvent("failed"),
+	} {
+		assert.Equal(t, []Event{repeated}, middleware.Process(repeated))
+		assert.Equal(t, []Event{repeated}, middleware.Process(repeated))
+	}
+}
+
+func TestDeduplicator_ThinkingBlocks(t *testing.T) {
+	thinkingBlock := func() []Event {
+		return []Event{
+			NewThinkingStartEvent(),
+			NewThinkingTextMessageStartEvent(),
+			NewThinkingTextMessageContentEvent("Let me think"),
+			NewThinkingTextMessageEndEvent(),
+			NewThinkingEndEvent(),
+		}
+	}
+	run := []Event{NewRunStartedEvent("thread-1", "run-1")}
+	run = append(run, thinkingBlock()...)
+	run = append(run, thinkingBlock()...)
+	run = append(run, NewRunFinishedEvent("thread-1", "run-1"))
+
+	deduplicator := NewDeduplicator(NewMemoryDeduplicationCache(1000), time.Minute)
+	var passed []Event
+	for _, event := range run {
+		passed = append(passed, deduplicator.Process(event)...)
+	}
+	assert.Equal(t, run, passed)
+
+	// A redelivered run boundary is still dropped
+	assert.Empty(t, deduplicator.Process(NewRunFinishedEvent("thread-1", "run-1")))
+}
+
+// setHasCache is a DeduplicationCache without SetIfAbsent
+type setHasCache struct {
+	cache *MemoryDeduplicationCache
+}
+
+func (c setHasCache) Set(key string, ttl time.Duration) { c.cache.Set(key, ttl) }
+func (c setHasCache) Has(key string) bool               { return c.cache.Has(key) }
+
+func TestDeduplicatingMiddleware(t *testing.T) {
+	var handled []Event
+	handler := NewDeduplicatingMiddleware(setHasCache{NewMemoryDeduplicationCache(0)}, time.Minute)(
+		EventHandlerFunc(func(ctx context.Context, event Event) error {
+			handled = append(handled, event)
+			return nil
+		}),
+	)
+
+	event := NewToolCallResultEvent("msg-1", "call-1", "Sunny")
+	content := NewTextMessageContentEvent("msg-1", "l")
+	for _, e := range []Event{event, event.Clone(), content, content} {
+		require.NoError(t, handler.HandleEvent(context.Background(), e))
+	}
+	assert.Equal(t, []Event{event, content, content}, handled)
+}
+
+func TestMemoryDeduplicationCache(t *testing.T) {
+	t.Run("Expiry", func(t *testing.T) {
+		now := time.Unix(1700000000, 0)
+		cache := NewMemoryDeduplicationCache(10)
+		cache.now = func() time.Time { return now }
+
+		cache.Set("a", time.Second)
+		assert.True(t, cache.Has("a"))
+		assert.False(t, cache.Has("b"))
+		assert.False(t, cache.SetIfAbsent("a", time.Hour))
+
+		now = now.Add(time.Second)
+		assert.False(t, cache.Has("a"))
+		assert.Equal(t, 0, cache.Len())
+		assert.True(t, cache.SetIfAbsent("a", time.Second))
+		assert.False(t, cache.SetIfAbsent("a", time.Second))
+	})
+
+	t.Run("EvictsOldest", func(t *testing.T) {
+		cache := NewMemoryDeduplicationCache(3)
+		for i := 0; i < 3; i++ {
+			cache.Set(fmt.Sprint(i), time.Hour)
+		}
+		// Setting a key again makes it the most recent
+		cache.Set("0", time.Hour)
+		cache.Set("3", time.Hour)
+
+		assert.Equal(t, 3, cache.Len())
+		assert.True(t, cache.Has("0"))
+		assert.False(t, cache.Has("1"))
+		assert.True(t, cache.Has("2"))
+		assert.True(t, cache.Has("3"))
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		middleware := NewDeduplicator(NewMemoryDeduplicationCache(100), time.Hour)
+		results := make(chan int, 8)
+		for i := 0; i < 8; i++ {
+			go func() {
+				passed := 0
+				for j := 0; j < 50; j++ {
+					passed += len(middleware.Process(NewToolCallStartEvent(fmt.Sprint("call-", j), "search")))
+				}
+				results <- passed
+			}()
+		}
+		total := 0
+		for i := 0; i < 8; i++ {
+			total += <-results
+		}
+		assert.Equal(t, 50, total)
+	})
+}
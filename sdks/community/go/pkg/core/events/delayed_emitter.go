@@ -0,0 +1,177 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrEmitterClosed is returned when scheduling an event on a closed
+// DelayedEventEmitter
+var ErrEmitterClosed = errors.New("delayed event emitter is closed")
+
+// DelayedEventEmitter schedules events for delivery to a handler at a later
+// time, for agents that know a status update will be ready in a while. Each
+// scheduled event is delivered by its own goroutine, so events scheduled for
+// the same time may be delivered in any order. It is safe for concurrent use.
+type DelayedEventEmitter struct {
+	handler EventHandler
+	logger  EventLogger
+
+	// ctx is passed to the handler, and canceled when Close gives up on
+	// the pending events
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	pending map[uint64]chan struct{}
+	nextID  uint64
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// DelayedEventEmitterOption configures a delayed event emitter
+type DelayedEventEmitterOption func(*DelayedEventEmitter)
+
+// WithDelayedEmitterLogger sets the logger receiving delivery failures,
+// instead of a default logrus logger
+func WithDelayedEmitterLogger(logger EventLogger) DelayedEventEmitterOption {
+	return func(d *DelayedEventEmitter) {
+		if logger != nil {
+			d.logger = logger
+		}
+	}
+}
+
+// NewDelayedEventEmitter creates an emitter delivering scheduled events to
+// handler. Errors returned by the handler are logged, since nobody waits
+// for the delivery.
+func NewDelayedEventEmitter(handler EventHandler, options ...DelayedEventEmitterOption) *DelayedEventEmitter {
+	d := &DelayedEventEmitter{
+		handler: handler,
+		pending: make(map[uint64]chan struct{}),
+	}
+	for _, opt := range options {
+		opt(d)
+	}
+	if d.logger == nil {
+		d.logger = NewLogrusEventLogger(nil)
+	}
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+	return d
+}
+
+// Schedule delivers e to the handler once delay has elapsed, or immediately
+// if it is not positive. The returned function cancels the delivery; it may
+// be called any number of times, and does nothing once the event is being
+// delivered.
+func (d *DelayedEventEmitter) Schedule(e Event, delay time.Duration) (cancelFunc func(), err error) {
+	if e == nil {
+		return nil, errors.New("cannot schedule a nil event")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return nil, ErrEmitterClosed
+	}
+
+	id := d.nextID
+	d.nextID++
+	canceled := make(chan struct{})
+	d.pending[id] = canceled
+
+	d.wg.Add(1)
+	go d.deliver(id, e, delay, canceled)
+
+	return func() { d.cancelPending(id) }, nil
+}
+
+// ScheduleAt delivers e to the handler at t, or immediately if t is in the
+// past. The returned function cancels the delivery, as for Schedule.
+func (d *DelayedEventEmitter) ScheduleAt(e Event, t time.Time) (cancelFunc func(), err error) {
+	return d.Schedule(e, time.Until(t))
+}
+
+// deliver waits for the delay of a scheduled event and delivers it, unless
+// it is canceled first
+func (d *DelayedEventEmitter) deliver(id uint64, e Event, delay time.Duration, canceled <-chan struct{}) {
+	defer d.wg.Done()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-canceled:
+		return
+	case <-timer.C:
+	}
+
+	// Claim the event, unless a cancellation raced with the timer
+	d.mu.Lock()
+	_, ok := d.pending[id]
+	delete(d.pending, id)
+	d.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	if err := d.handler.HandleEvent(d.ctx, e); err != nil {
+		d.logger.Error("delayed event delivery failed", map[string]any{
+			"event": string(e.Type()),
+			"error": err.Error(),
+		})
+	}
+}
+
+// cancelPending cancels a scheduled event that has not been delivered
+func (d *DelayedEventEmitter) cancelPending(id uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if canceled, ok := d.pending[id]; ok {
+		delete(d.pending, id)
+		close(canceled)
+	}
+}
+
+// PendingCount returns the number of scheduled events that have been
+// neither delivered nor canceled
+func (d *DelayedEventEmitter) PendingCount() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.pending)
+}
+
+// Close stops accepting events and waits for the pending ones to be
+// delivered. If ctx is done first, the events still pending are canceled,
+// the context passed to the deliveries in progress is canceled, and the
+// error of ctx is returned without waiting for them any longer. Pass a
+// canceled context to cancel every pending event at once.
+func (d *DelayedEventEmitter) Close(ctx context.Context) error {
+	d.mu.Lock()
+	d.closed = true
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+	}
+
+	d.mu.Lock()
+	for id, canceled := range d.pending {
+		delete(d.pending, id)
+		close(canceled)
+	}
+	d.mu.Unlock()
+	d.cancel()
+	return ctx.Err()
+}
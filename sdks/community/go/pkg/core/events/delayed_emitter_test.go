@@ -0,0 +1,102 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// channelHandler sends the handled events to a channel
+func channelHandler(received chan<- Event) EventHandler {
+	return EventHandlerFunc(func(ctx context.Context, e Event) error {
+		received <- e
+		return nil
+	})
+}
+
+func TestDelayedEventEmitter(t *testing.T) {
+	t.Run("Schedule", func(t *testing.T) {
+		received := make(chan Event, 2)
+		d := NewDelayedEventEmitter(channelHandler(received))
+
+		later := NewStepStartedEvent("later")
+		sooner := NewStepStartedEvent("sooner")
+		_, err := d.Schedule(later, 60*time.Millisecond)
+		require.NoError(t, err)
+		_, err = d.ScheduleAt(sooner, time.Now().Add(10*time.Millisecond))
+		require.NoError(t, err)
+		assert.Equal(t, 2, d.PendingCount())
+
+		assert.Same(t, sooner, <-received)
+		assert.Same(t, later, <-received)
+		require.NoError(t, d.Close(context.Background()))
+		assert.Zero(t, d.PendingCount())
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		received := make(chan Event, 2)
+		d := NewDelayedEventEmitter(channelHandler(received))
+
+		cancel, err := d.Schedule(NewStepStartedEvent("canceled"), 20*time.Millisecond)
+		require.NoError(t, err)
+		kept := NewStepStartedEvent("kept")
+		_, err = d.Schedule(kept, 30*time.Millisecond)
+		require.NoError(t, err)
+
+		cancel()
+		cancel()
+		assert.Equal(t, 1, d.PendingCount())
+
+		require.NoError(t, d.Close(context.Background()))
+		close(received)
+		var delivered []Event
+		for e := range received {
+			delivered = append(delivered, e)
+		}
+		assert.Equal(t, []Event{kept}, delivered)
+
+		// Canceling after delivery does nothing
+		assert.NotPanics(t, cancel)
+	})
+
+	t.Run("CloseCancelsOnDeadline", func(t *testing.T) {
+		received := make(chan Event, 1)
+		d := NewDelayedEventEmitter(channelHandler(received))
+		_, err := d.Schedule(NewStepStartedEvent("never"), time.Hour)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, d.Close(ctx), context.DeadlineExceeded)
+		assert.Zero(t, d.PendingCount())
+		assert.Empty(t, received)
+
+		_, err = d.Schedule(NewStepStartedEvent("late"), 0)
+		assert.ErrorIs(t, err, ErrEmitterClosed)
+	})
+
+	t.Run("LogsHandlerErrors", func(t *testing.T) {
+		logger := &recordingEventLogger{}
+		d := NewDelayedEventEmitter(EventHandlerFunc(func(ctx context.Context, e Event) error {
+			return errors.New("sink unavailable")
+		}), WithDelayedEmitterLogger(logger))
+
+		_, err := d.Schedule(NewStepStartedEvent("plan"), 0)
+		require.NoError(t, err)
+		require.NoError(t, d.Close(context.Background()))
+
+		require.Len(t, logger.entries, 1)
+		assert.Equal(t, "error: delayed event delivery failed", logger.entries[0].msg)
+		assert.Equal(t, "sink unavailable", logger.entries[0].fields["error"])
+	})
+
+	t.Run("NilEvent", func(t *testing.T) {
+		d := NewDelayedEventEmitter(channelHandler(make(chan Event)))
+		_, err := d.Schedule(nil, time.Second)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,374 @@
+package events
+
+import (
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/jsonpointer"
+)
+
+// DefaultDeltaWindow is how long a DeltaCoalescer buffers operations by
+// default before emitting them
+const DefaultDeltaWindow = 50 * time.Millisecond
+
+// DeltaCoalescer batches outgoing state deltas, so that agents patching
+// state in tight loops emit one consolidated STATE_DELTA per window instead
+// of one per change. Redundant operations are merged as they are buffered:
+// a later add or replace of a path supersedes earlier ones, changes inside a
+// value that is later replaced or removed are dropped, and an add undone by a
+// remove cancels out when the path is known to have been absent before.
+//
+// The merging never looks at the state, so it is correct for any state the
+// original operations apply to: applying the consolidated delta yields the
+// same state as applying the buffered deltas in order. When in doubt, for
+// example around moves, copies and tests, operations are kept as they are.
+// It is not safe for concurrent use.
+type DeltaCoalescer struct {
+	window time.Duration
+	maxOps int
+	now    EventClock
+
+	ops         []coalescedOp
+	received    int
+	windowStart time.Time
+	baseVersion *uint64
+	newVersion  *uint64
+}
+
+// coalescedOp is a buffered operation with its parsed paths
+type coalescedOp struct {
+	op   JSONPatchOperation
+	path []string
+	from []string
+
+	// parsed is false if a path is malformed, in which case the operation
+	// is treated as touching everything
+	parsed bool
+}
+
+// DeltaCoalescerOption defines options for creating delta coalescers
+type DeltaCoalescerOption func(*DeltaCoalescer)
+
+// WithDeltaWindow sets how long operations are buffered, counted from the
+// first buffered delta. Zero disables the time limit.
+func WithDeltaWindow(d time.Duration) DeltaCoalescerOption {
+	return func(c *DeltaCoalescer) {
+		c.window = d
+	}
+}
+
+// WithDeltaMaxOps emits the buffered operations once n operations have been
+// received since the last emission, however many remain after merging. Zero
+// disables the count limit.
+func WithDeltaMaxOps(n int) DeltaCoalescerOption {
+	return func(c *DeltaCoalescer) {
+		c.maxOps = n
+	}
+}
+
+// NewDeltaCoalescer creates a delta coalescer buffering operations for
+// DefaultDeltaWindow unless configured otherwise
+func NewDeltaCoalescer(opts ...DeltaCoalescerOption) *DeltaCoalescer {
+	c := &DeltaCoalescer{
+		window: DefaultDeltaWindow,
+		now:    time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Process records an outgoing event and returns the events to emit in its
+// place. State deltas are buffered, and the consolidated delta is returned
+// once the window or operation limit is reached. Any other event first
+// flushes the buffer, so that event order is preserved, except for state
+// snapshots, which supersede the buffered operations and discard them.
+func (c *DeltaCoalescer) Process(e Event) []Event {
+	switch event := e.(type) {
+	case *StateDeltaEvent:
+		if c.received == 0 {
+			c.windowStart = c.now()
+			c.baseVersion = cloneUint64(event.BaseVersion)
+		}
+		c.newVersion = cloneUint64(event.NewVersion)
+		for _, op := range event.Delta {
+			c.push(op)
+		}
+		c.received += len(event.Delta)
+		return c.FlushIfDue()
+
+	case *StateSnapshotEvent:
+		c.reset()
+		return []Event{e}
+
+	default:
+		return append(c.Flush(), e)
+	}
+}
+
+// FlushIfDue returns the consolidated delta if the window has elapsed or the
+// operation limit has been reached, for callers polling while the stream is
+// idle
+func (c *DeltaCoalescer) FlushIfDue() []Event {
+	if c.received == 0 {
+		return nil
+	}
+	if c.maxOps > 0 && c.received >= c.maxOps {
+		return c.Flush()
+	}
+	if c.window > 0 && c.now().Sub(c.windowStart) >= c.window {
+		return c.Flush()
+	}
+	return nil
+}
+
+// Flush returns the consolidated delta of the buffered operations, if any
+// remain, and empties the buffer
+func (c *DeltaCoalescer) Flush() []Event {
+	defer c.reset()
+	if len(c.ops) == 0 {
+		return nil
+	}
+
+	ops := make([]JSONPatchOperation, len(c.ops))
+	for i, buffered := range c.ops {
+		ops[i] = buffered.op
+	}
+
+	var options []StateDeltaOption
+	if c.baseVersion != nil {
+		options = append(options, WithBaseVersion(*c.baseVersion))
+	}
+	if c.newVersion != nil {
+		options = append(options, WithNewVersion(*c.newVersion))
+	}
+	return []Event{NewStateDeltaEventWithOptions(ops, options...)}
+}
+
+// Stage returns the coalescer as an EventStage, whose Flush emits the
+// buffered operations
+func (c *DeltaCoalescer) Stage() EventStage {
+	return infallibleStage{process: c.Process, flush: c.Flush}
+}
+
+// reset empties the buffer
+func (c *DeltaCoalescer) reset() {
+	c.ops = nil
+	c.received = 0
+	c.baseVersion = nil
+	c.newVersion = nil
+}
+
+// push buffers an operation, merging it with the latest buffered operation
+// it interacts with. The operations buffered after that one are independent
+// of both, so the pair can be treated as adjacent and its result moved to
+// the end of the buffer.
+func (c *DeltaCoalescer) push(op JSONPatchOperation) {
+	next := parseCoalescedOp(op)
+	for {
+		j := c.lastConflict(len(c.ops), next)
+		if j < 0 {
+			c.ops = append(c.ops, next)
+			return
+		}
+
+		merged, action := c.combine(j, next)
+		switch action {
+		case keepBoth:
+			c.ops = append(c.ops, next)
+			return
+		case cancelBoth:
+			c.ops = append(c.ops[:j], c.ops[j+1:]...)
+			return
+		case dropEarlier:
+			c.ops = append(c.ops[:j], c.ops[j+1:]...)
+		case mergeBoth:
+			c.ops = append(c.ops[:j], c.ops[j+1:]...)
+			next = merged
+		}
+	}
+}
+
+// mergeAction is the outcome of combining two interacting operations
+type mergeAction int
+
+const (
+	keepBoth mergeAction = iota
+	cancelBoth
+	dropEarlier
+	mergeBoth
+)
+
+// combine decides how the buffered operation at index j and the following
+// operation next, which interact, can be merged
+func (c *DeltaCoalescer) combine(j int, next coalescedOp) (coalescedOp, mergeAction) {
+	earlier := c.ops[j]
+	if !earlier.parsed || !next.parsed {
+		return next, keepBoth
+	}
+	if !isPathOp(earlier.op.Op) || !isPathOp(next.op.Op) {
+		return next, keepBoth
+	}
+
+	// A value that is replaced or removed as a whole makes earlier changes
+	// inside it irrelevant. Adds overwrite object members but insert into
+	// arrays, so only adds of object members qualify.
+	if isProperPrefix(next.path, earlier.path) &&
+		(next.op.Op != "add" || !isIndexToken(lastToken(next.path))) {
+		return next, dropEarlier
+	}
+
+	if !equalTokens(earlier.path, next.path) || lastToken(next.path) == "-" {
+		return next, keepBoth
+	}
+
+	switch earlier.op.Op + " " + next.op.Op {
+	case "add replace", "replace replace":
+		// The later value wins at the same location
+		next.op = JSONPatchOperation{Op: earlier.op.Op, Path: next.op.Path, Value: next.op.Value}
+		return next, mergeBoth
+
+	case "add add", "replace add":
+		// An object member is overwritten, but an array element would be
+		// inserted next to the earlier one
+		if isIndexToken(lastToken(next.path)) {
+			return next, keepBoth
+		}
+		next.op = JSONPatchOperation{Op: earlier.op.Op, Path: next.op.Path, Value: next.op.Value}
+		return next, mergeBoth
+
+	case "replace remove":
+		return next, mergeBoth
+
+	case "remove add":
+		// Removing and re-adding an object member or array element
+		// replaces it
+		next.op = JSONPatchOperation{Op: "replace", Path: next.op.Path, Value: next.op.Value}
+		return next, mergeBoth
+
+	case "add remove":
+		// The pair is a no-op if the location was empty before the add,
+		// which is known when the previous operation there removed it.
+		// Inserting and removing an array element is always a no-op, but
+		// numeric tokens may also name object members, so the same proof
+		// is required.
+		if k := c.lastConflict(j, earlier); k >= 0 {
+			previous := c.ops[k]
+			if previous.parsed && previous.op.Op == "remove" && equalTokens(previous.path, earlier.path) {
+				return next, cancelBoth
+			}
+		}
+		return next, keepBoth
+	}
+
+	return next, keepBoth
+}
+
+// lastConflict returns the index of the latest of the first n buffered
+// operations that interacts with op, or -1 if there is none
+func (c *DeltaCoalescer) lastConflict(n int, op coalescedOp) int {
+	for i := n - 1; i >= 0; i-- {
+		if c.ops[i].conflicts(op) {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseCoalescedOp parses the paths of an operation
+func parseCoalescedOp(op JSONPatchOperation) coalescedOp {
+	parsed := coalescedOp{op: op, parsed: true}
+
+	path, err := jsonpointer.Parse(op.Path)
+	if err != nil {
+		parsed.parsed = false
+		return parsed
+	}
+	parsed.path = path
+
+	if op.Op == "move" || op.Op == "copy" {
+		from, err := jsonpointer.Parse(op.From)
+		if err != nil {
+			parsed.parsed = false
+			return parsed
+		}
+		parsed.from = from
+	}
+	return parsed
+}
+
+// paths returns the locations the operation reads or writes
+func (o coalescedOp) paths() [][]string {
+	if o.op.Op == "move" || o.op.Op == "copy" {
+		return [][]string{o.path, o.from}
+	}
+	return [][]string{o.path}
+}
+
+// conflicts reports whether two operations may not commute
+func (o coalescedOp) conflicts(other coalescedOp) bool {
+	if !o.parsed || !other.parsed {
+		return true
+	}
+	for _, a := range o.paths() {
+		for _, b := range other.paths() {
+			if pathsInteract(a, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pathsInteract reports whether two locations overlap, or lie inside what
+// may be different elements of the same array, whose indices shift when
+// elements are inserted or removed
+func pathsInteract(a, b []string) bool {
+	k := 0
+	for k < len(a) && k < len(b) && a[k] == b[k] {
+		k++
+	}
+	if k == len(a) || k == len(b) {
+		return true
+	}
+	return isIndexToken(a[k]) && isIndexToken(b[k])
+}
+
+// isPathOp reports whether an operation only touches its path
+func isPathOp(op string) bool {
+	return op == "add" || op == "replace" || op == "remove"
+}
+
+// isIndexToken reports whether a reference token may refer to an array
+// element
+func isIndexToken(token string) bool {
+	if token == "-" {
+		return true
+	}
+	_, ok := jsonpointer.ParseIndex(token)
+	return ok
+}
+
+// lastToken returns the last reference token of a path, or an empty string
+// for the root
+func lastToken(path []string) string {
+	if len(path) == 0 {
+		return ""
+	}
+	return path[len(path)-1]
+}
+
+// equalTokens reports whether two paths are equal
+func equalTokens(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,331 @@
+package events
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// coalesce feeds the operations to a coalescer one delta at a time and
+// returns the operations of the consolidated delta
+func coalesce(t *testing.T, ops ...JSONPatchOperation) []JSONPatchOperation {
+	t.Helper()
+	c := NewDeltaCoalescer(WithDeltaWindow(0))
+	for _, op := range ops {
+		require.Empty(t, c.Process(NewStateDeltaEvent([]JSONPatchOperation{op})))
+	}
+	flushed := c.Flush()
+	if len(flushed) == 0 {
+		return nil
+	}
+	require.Len(t, flushed, 1)
+	return flushed[0].(*StateDeltaEvent).Delta
+}
+
+func TestDeltaCoalescerMerging(t *testing.T) {
+	cases := []struct {
+		name string
+		ops  []JSONPatchOperation
+		want []JSONPatchOperation
+	}{
+		{
+			name: "ReplaceSupersedesReplace",
+			ops: []JSONPatchOperation{
+				{Op: "replace", Path: "/count", Value: 1},
+				{Op: "replace", Path: "/other", Value: "x"},
+				{Op: "replace", Path: "/count", Value: 2},
+				{Op: "replace", Path: "/count", Value: 3},
+			},
+			want: []JSONPatchOperation{
+				{Op: "replace", Path: "/other", Value: "x"},
+				{Op: "replace", Path: "/count", Value: 3},
+			},
+		},
+		{
+			name: "ReplaceAfterAddKeepsAdd",
+			ops: []JSONPatchOperation{
+				{Op: "add", Path: "/status", Value: "new"},
+				{Op: "replace", Path: "/status", Value: "done"},
+			},
+			want: []JSONPatchOperation{{Op: "add", Path: "/status", Value: "done"}},
+		},
+		{
+			name: "AddThenRemoveOfAbsentMemberCancels",
+			ops: []JSONPatchOperation{
+				{Op: "remove", Path: "/tmp"},
+				{Op: "add", Path: "/tmp", Value: 1},
+				{Op: "remove", Path: "/tmp"},
+			},
+			want: []JSONPatchOperation{{Op: "remove", Path: "/tmp"}},
+		},
+		{
+			// The member may have existed before the add overwrote it
+			name: "AddThenRemoveOfUnknownMemberIsKept",
+			ops: []JSONPatchOperation{
+				{Op: "add", Path: "/tmp", Value: 1},
+				{Op: "remove", Path: "/tmp"},
+			},
+			want: []JSONPatchOperation{
+				{Op: "add", Path: "/tmp", Value: 1},
+				{Op: "remove", Path: "/tmp"},
+			},
+		},
+		{
+			name: "RemoveThenAddBecomesReplace",
+			ops: []JSONPatchOperation{
+				{Op: "remove", Path: "/items/1"},
+				{Op: "add", Path: "/items/1", Value: "b"},
+			},
+			want: []JSONPatchOperation{{Op: "replace", Path: "/items/1", Value: "b"}},
+		},
+		{
+			name: "ChangesInsideReplacedValueAreDropped",
+			ops: []JSONPatchOperation{
+				{Op: "add", Path: "/user/name", Value: "Ada"},
+				{Op: "remove", Path: "/user/email"},
+				{Op: "replace", Path: "/user", Value: map[string]any{}},
+			},
+			want: []JSONPatchOperation{{Op: "replace", Path: "/user", Value: map[string]any{}}},
+		},
+		{
+			// Inserting at an index shifts the element the earlier
+			// operation changed instead of overwriting it
+			name: "ArrayInsertsAreKept",
+			ops: []JSONPatchOperation{
+				{Op: "replace", Path: "/items/0", Value: "a"},
+				{Op: "add", Path: "/items/0", Value: "b"},
+			},
+			want: []JSONPatchOperation{
+				{Op: "replace", Path: "/items/0", Value: "a"},
+				{Op: "add", Path: "/items/0", Value: "b"},
+			},
+		},
+		{
+			name: "MovesAreBarriers",
+			ops: []JSONPatchOperation{
+				{Op: "replace", Path: "/a", Value: 1},
+				{Op: "move", From: "/a", Path: "/b"},
+				{Op: "replace", Path: "/a", Value: 2},
+			},
+			want: []JSONPatchOperation{
+				{Op: "replace", Path: "/a", Value: 1},
+				{Op: "move", From: "/a", Path: "/b"},
+				{Op: "replace", Path: "/a", Value: 2},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, coalesce(t, tc.ops...))
+		})
+	}
+
+	t.Run("EverythingCancels", func(t *testing.T) {
+		c := NewDeltaCoalescer(WithDeltaWindow(0))
+		c.Process(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "remove", Path: "/items/0"},
+			{Op: "add", Path: "/items/0", Value: 1},
+		}))
+		c.Process(NewStateDeltaEvent([]JSONPatchOperation{{Op: "replace", Path: "/", Value: 1}}))
+		assert.Len(t, c.Flush(), 1)
+		assert.Empty(t, c.Flush())
+	})
+}
+
+func TestDeltaCoalescerEquivalence(t *testing.T) {
+	rng := rand.New(rand.NewSource(1618))
+	for run := 0; run < 2000; run++ {
+		doc := randomPatchDocument(rng, 3)
+		state := deepCopyJSONValue(doc)
+
+		var ops []JSONPatchOperation
+		for i := rng.Intn(25); i > 0; i-- {
+			op, ok := randomValidOperation(rng, state)
+			if !ok {
+				continue
+			}
+			next, err := applyPatch(state, []JSONPatchOperation{op})
+			require.NoError(t, err)
+			state = next
+			ops = append(ops, op)
+		}
+
+		coalesced := coalesce(t, ops...)
+		assert.LessOrEqual(t, len(coalesced), len(ops))
+		result, err := ApplyPatch(doc, coalesced)
+		require.NoError(t, err, "run %d: %v -> %v", run, ops, coalesced)
+		require.True(t, jsonValuesEqual(state, result), "run %d:\noriginal  %v\ncoalesced %v", run, ops, coalesced)
+	}
+}
+
+func TestDeltaCoalescerWindows(t *testing.T) {
+	delta := func(value int) *StateDeltaEvent {
+		return NewStateDeltaEvent([]JSONPatchOperation{{Op: "replace", Path: "/count", Value: value}})
+	}
+
+	t.Run("Count", func(t *testing.T) {
+		c := NewDeltaCoalescer(WithDeltaWindow(0), WithDeltaMaxOps(3))
+		assert.Empty(t, c.Process(delta(1)))
+		assert.Empty(t, c.Process(delta(2)))
+		out := c.Process(delta(3))
+		require.Len(t, out, 1)
+		assert.Equal(t, []JSONPatchOperation{{Op: "replace", Path: "/count", Value: 3}}, out[0].(*StateDeltaEvent).Delta)
+		assert.Empty(t, c.Process(delta(4)))
+	})
+
+	t.Run("Time", func(t *testing.T) {
+		now := time.Unix(1700000000, 0)
+		c := NewDeltaCoalescer(WithDeltaWindow(100 * time.Millisecond))
+		c.now = func() time.Time { return now }
+
+		assert.Empty(t, c.Process(delta(1)))
+		now = now.Add(60 * time.Millisecond)
+		assert.Empty(t, c.Process(delta(2)))
+		assert.Empty(t, c.FlushIfDue())
+
+		now = now.Add(40 * time.Millisecond)
+		out := c.FlushIfDue()
+		require.Len(t, out, 1)
+		assert.Equal(t, []JSONPatchOperation{{Op: "replace", Path: "/count", Value: 2}}, out[0].(*StateDeltaEvent).Delta)
+
+		// The next window starts with the next delta
+		now = now.Add(time.Second)
+		assert.Empty(t, c.Process(delta(3)))
+	})
+
+	t.Run("OtherEventsFlushFirst", func(t *testing.T) {
+		c := NewDeltaCoalescer()
+		c.Process(delta(1))
+		step := NewStepStartedEvent("plan")
+		out := c.Process(step)
+		require.Len(t, out, 2)
+		assert.Equal(t, EventTypeStateDelta, out[0].Type())
+		assert.Same(t, step, out[1])
+
+		assert.Equal(t, []Event{step}, c.Process(step))
+	})
+
+	t.Run("SnapshotsDiscardBufferedDeltas", func(t *testing.T) {
+		c := NewDeltaCoalescer()
+		c.Process(delta(1))
+		snapshot := NewStateSnapshotEvent(map[string]any{"count": 5})
+		assert.Equal(t, []Event{snapshot}, c.Process(snapshot))
+		assert.Empty(t, c.Flush())
+	})
+
+	t.Run("Versions", func(t *testing.T) {
+		c := NewDeltaCoalescer(WithDeltaWindow(0))
+		c.Process(NewStateDeltaEventWithOptions(delta(1).Delta, WithBaseVersion(4), WithNewVersion(5)))
+		c.Process(NewStateDeltaEventWithOptions(delta(2).Delta, WithBaseVersion(5), WithNewVersion(6)))
+		out := c.Flush()
+		require.Len(t, out, 1)
+		consolidated := out[0].(*StateDeltaEvent)
+		assert.Equal(t, uint64(4), *consolidated.BaseVersion)
+		assert.Equal(t, uint64(6), *consolidated.NewVersion)
+	})
+}
+
+// randomPatchDocument builds a random document with randomJSONValue whose
+// root is an object
+func randomPatchDocument(rng *rand.Rand, depth int) map[string]any {
+	for {
+		if doc, ok := randomJSONValue(rng, depth).(map[string]any); ok {
+			return doc
+		}
+	}
+}
+
+// patchLocation is a location in a document
+type patchLocation struct {
+	pointer string
+	value   any
+}
+
+// patchLocations lists every location of a document below the root
+func patchLocations(pointer string, value any, out []patchLocation) []patchLocation {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			child := appendJSONPointer(pointer, key)
+			out = append(out, patchLocation{child, item})
+			out = patchLocations(child, item, out)
+		}
+	case []any:
+		for i, item := range v {
+			child := fmt.Sprintf("%s/%d", pointer, i)
+			out = append(out, patchLocation{child, item})
+			out = patchLocations(child, item, out)
+		}
+	}
+	return out
+}
+
+// randomTarget picks a location where a value can be added
+func randomTarget(rng *rand.Rand, state any, exclude string) (string, bool) {
+	containers := []patchLocation{{"", state}}
+	for _, loc := range patchLocations("", state, nil) {
+		switch loc.value.(type) {
+		case map[string]any, []any:
+			if exclude == "" || !isPrefixOrEqualPointer(exclude, loc.pointer) {
+				containers = append(containers, loc)
+			}
+		}
+	}
+	container := containers[rng.Intn(len(containers))]
+	switch v := container.value.(type) {
+	case map[string]any:
+		return appendJSONPointer(container.pointer, []string{"a", "b", "c", "d"}[rng.Intn(4)]), true
+	case []any:
+		if rng.Intn(4) == 0 {
+			return container.pointer + "/-", true
+		}
+		return fmt.Sprintf("%s/%d", container.pointer, rng.Intn(len(v)+1)), true
+	}
+	return "", false
+}
+
+// isPrefixOrEqualPointer reports whether the location prefix contains path
+func isPrefixOrEqualPointer(prefix, path string) bool {
+	return path == prefix || len(path) > len(prefix) && path[:len(prefix)] == prefix && path[len(prefix)] == '/'
+}
+
+// randomValidOperation returns a random operation that applies to state
+func randomValidOperation(rng *rand.Rand, state any) (JSONPatchOperation, bool) {
+	locations := patchLocations("", state, nil)
+	if len(locations) == 0 {
+		target, ok := randomTarget(rng, state, "")
+		return JSONPatchOperation{Op: "add", Path: target, Value: randomJSONValue(rng, 2)}, ok
+	}
+	existing := locations[rng.Intn(len(locations))]
+
+	switch rng.Intn(7) {
+	case 0, 1:
+		target, ok := randomTarget(rng, state, "")
+		return JSONPatchOperation{Op: "add", Path: target, Value: randomJSONValue(rng, 2)}, ok
+	case 2:
+		return JSONPatchOperation{Op: "remove", Path: existing.pointer}, true
+	case 3, 4:
+		return JSONPatchOperation{Op: "replace", Path: existing.pointer, Value: randomJSONValue(rng, 2)}, true
+	case 5:
+		target, ok := randomTarget(rng, state, existing.pointer)
+		if !ok {
+			return JSONPatchOperation{}, false
+		}
+		op := JSONPatchOperation{Op: "move", From: existing.pointer, Path: target}
+		if rng.Intn(2) == 0 {
+			op.Op = "copy"
+		}
+		// Moving an element within its own array shifts the target, so
+		// let the patch engine decide whether the move is valid
+		if _, err := applyPatch(deepCopyJSONValue(state), []JSONPatchOperation{op}); err != nil {
+			return JSONPatchOperation{}, false
+		}
+		return op, true
+	default:
+		return JSONPatchOperation{Op: "test", Path: existing.pointer, Value: deepCopyJSONValue(existing.value)}, true
+	}
+}
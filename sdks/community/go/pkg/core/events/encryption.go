@@ -0,0 +1,156 @@
+package events
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+// EncryptedEventSource is the source of raw events produced by EventEncryptor
+const EncryptedEventSource = "encrypted"
+
+// EventEncryptor encrypts events with AES-256-GCM so that sensitive payloads,
+// such as tool results carrying credentials, stay confidential end to end.
+// An encrypted event travels as a RAW event whose source is
+// EncryptedEventSource and whose payload is the base64 encoding of the nonce
+// followed by the sealed JSON of the event. It is safe for concurrent use.
+type EventEncryptor struct {
+	aead cipher.AEAD
+}
+
+// NewEventEncryptor creates an encryptor from a 32-byte AES-256 key
+func NewEventEncryptor(key []byte) (*EventEncryptor, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("invalid encryption key: AES-256 requires 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return &EventEncryptor{aead: aead}, nil
+}
+
+// Encrypt serializes the event to JSON, encrypts it with a fresh random nonce
+// and wraps it in a raw event carrying the event's timestamp
+func (c *EventEncryptor) Encrypt(e Event) (*RawEvent, error) {
+	if e == nil {
+		return nil, fmt.Errorf("event cannot be nil")
+	}
+
+	plaintext, err := e.ToJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize %s event: %w", e.Type(), err)
+	}
+
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := c.aead.Seal(nonce, nonce, plaintext, nil)
+
+	raw := NewRawEvent(base64.StdEncoding.EncodeToString(sealed), WithSource(EncryptedEventSource))
+	if timestamp := e.Timestamp(); timestamp != nil {
+		raw.SetTimestamp(*timestamp)
+	}
+	return raw, nil
+}
+
+// Decrypt decrypts a raw event produced by Encrypt and decodes the original
+// event. It fails if the event was not encrypted, was tampered with or was
+// encrypted with a different key.
+func (c *EventEncryptor) Decrypt(e *RawEvent) (Event, error) {
+	if e == nil {
+		return nil, fmt.Errorf("event cannot be nil")
+	}
+	if e.Source == nil || *e.Source != EncryptedEventSource {
+		return nil, fmt.Errorf("raw event is not encrypted")
+	}
+
+	encoded, ok := e.Event.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid encrypted payload: expected a string, got %T", e.Event)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted payload: %w", err)
+	}
+	if len(sealed) < c.aead.NonceSize() {
+		return nil, fmt.Errorf("invalid encrypted payload: too short")
+	}
+
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt event: %w", err)
+	}
+
+	event, err := EventFromJSON(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode decrypted event: %w", err)
+	}
+	return event, nil
+}
+
+// NewEncryptingMiddleware returns a middleware that encrypts the events of
+// the given types with an EventEncryptor for key before handing them on, and
+// hands other events on unchanged. With no types, every event is encrypted.
+// If the key is invalid, every event is rejected with the error of
+// NewEventEncryptor.
+func NewEncryptingMiddleware(key []byte, types ...EventType) EventMiddleware {
+	encryptor, keyErr := NewEventEncryptor(key)
+	var encrypt func(Event) (Event, error)
+	if keyErr == nil {
+		encrypt = encryptor.EncryptTypes(types...)
+	}
+	return func(next EventHandler) EventHandler {
+		return EventHandlerFunc(func(ctx context.Context, event Event) error {
+			if keyErr != nil {
+				return keyErr
+			}
+			encrypted, err := encrypt(event)
+			if err != nil {
+				return err
+			}
+			return next.HandleEvent(ctx, encrypted)
+		})
+	}
+}
+
+// EncryptTypes returns a hook that encrypts events of the given types and
+// passes other events through, for pipelines built from hook functions
+// rather than EventMiddleware. With no types, every event is encrypted.
+func (c *EventEncryptor) EncryptTypes(types ...EventType) func(Event) (Event, error) {
+	selected := make(map[EventType]bool, len(types))
+	for _, t := range types {
+		selected[t] = true
+	}
+
+	return func(e Event) (Event, error) {
+		if e != nil && len(selected) > 0 && !selected[e.Type()] {
+			return e, nil
+		}
+		return c.Encrypt(e)
+	}
+}
+
+// DecryptEvents returns a hook that decrypts encrypted raw events and
+// passes other events through. It can be registered with WithPostDecodeHook
+// to decrypt incoming events transparently.
+func (c *EventEncryptor) DecryptEvents() func(Event) (Event, error) {
+	return func(e Event) (Event, error) {
+		raw, ok := e.(*RawEvent)
+		if !ok || raw.Source == nil || *raw.Source != EncryptedEventSource {
+			return e, nil
+		}
+		return c.Decrypt(raw)
+	}
+}
@@ -0,0 +1,142 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventEncryptor(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	encryptor, err := NewEventEncryptor(key)
+	require.NoError(t, err)
+
+	secret := NewToolCallResultEvent("msg-1", "call-1", `{"password":"hunter2"}`)
+	secret.SetTimestamp(1700000000000)
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		raw, err := encryptor.Encrypt(secret)
+		require.NoError(t, err)
+		require.NotNil(t, raw.Source)
+		assert.Equal(t, EncryptedEventSource, *raw.Source)
+		assert.Equal(t, secret.Timestamp(), raw.Timestamp())
+
+		data, err := raw.ToJSON()
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "hunter2")
+		assert.NotContains(t, string(data), "call-1")
+
+		decrypted, err := encryptor.Decrypt(raw)
+		require.NoError(t, err)
+		assert.Equal(t, secret, clearSequence(decrypted))
+	})
+
+	t.Run("FreshNonces", func(t *testing.T) {
+		first, err := encryptor.Encrypt(secret)
+		require.NoError(t, err)
+		second, err := encryptor.Encrypt(secret)
+		require.NoError(t, err)
+		assert.NotEqual(t, first.Event, second.Event)
+	})
+
+	t.Run("InvalidKey", func(t *testing.T) {
+		_, err := NewEventEncryptor([]byte("too short"))
+		assert.Error(t, err)
+	})
+
+	t.Run("DecryptFailures", func(t *testing.T) {
+		raw, err := encryptor.Encrypt(secret)
+		require.NoError(t, err)
+
+		other, err := NewEventEncryptor(bytes.Repeat([]byte{8}, 32))
+		require.NoError(t, err)
+		_, err = other.Decrypt(raw)
+		assert.Error(t, err, "wrong key")
+
+		tampered := []byte(raw.Event.(string))
+		tampered[len(tampered)/2] ^= 'A' ^ 'B'
+		_, err = encryptor.Decrypt(NewRawEvent(string(tampered), WithSource(EncryptedEventSource)))
+		assert.Error(t, err, "tampered payload")
+
+		_, err = encryptor.Decrypt(NewRawEvent(raw.Event))
+		assert.Error(t, err, "missing source")
+
+		_, err = encryptor.Decrypt(NewRawEvent(map[string]any{"a": 1}, WithSource(EncryptedEventSource)))
+		assert.Error(t, err, "non-string payload")
+
+		_, err = encryptor.Decrypt(NewRawEvent("AAAA", WithSource(EncryptedEventSource)))
+		assert.Error(t, err, "short payload")
+	})
+
+	t.Run("EncryptTypes", func(t *testing.T) {
+		encrypt := encryptor.EncryptTypes(EventTypeToolCallResult)
+
+		out, err := encrypt(secret)
+		require.NoError(t, err)
+		assert.Equal(t, EventTypeRaw, out.Type())
+
+		plain := NewTextMessageContentEvent("msg-1", "hello")
+		out, err = encrypt(plain)
+		require.NoError(t, err)
+		assert.Same(t, plain, out)
+
+		out, err = encryptor.EncryptTypes()(plain)
+		require.NoError(t, err)
+		assert.Equal(t, EventTypeRaw, out.Type())
+	})
+
+	t.Run("Middleware", func(t *testing.T) {
+		var handled []Event
+		handler := NewEncryptingMiddleware(key, EventTypeToolCallResult)(EventHandlerFunc(func(ctx context.Context, e Event) error {
+			handled = append(handled, e)
+			return nil
+		}))
+
+		plain := NewTextMessageContentEvent("msg-1", "hello")
+		require.NoError(t, handler.HandleEvent(context.Background(), secret))
+		require.NoError(t, handler.HandleEvent(context.Background(), plain))
+		require.Len(t, handled, 2)
+		assert.Same(t, plain, handled[1])
+
+		raw, ok := handled[0].(*RawEvent)
+		require.True(t, ok, "expected a raw event, got %T", handled[0])
+		decrypted, err := encryptor.Decrypt(raw)
+		require.NoError(t, err)
+		assert.Equal(t, secret.Content, decrypted.(*ToolCallResultEvent).Content)
+
+		// An invalid key rejects every event without calling the handler
+		handler = NewEncryptingMiddleware([]byte("short"))(EventHandlerFunc(func(ctx context.Context, e Event) error {
+			t.Fatalf("unexpected event %s", e.Type())
+			return nil
+		}))
+		assert.Error(t, handler.HandleEvent(context.Background(), plain))
+	})
+
+	t.Run("DecryptingDecoder", func(t *testing.T) {
+		logger := logrus.New()
+		logger.SetLevel(logrus.PanicLevel)
+		decoder := NewEventDecoder(logger, WithPostDecodeHook(encryptor.DecryptEvents()))
+
+		raw, err := encryptor.Encrypt(secret)
+		require.NoError(t, err)
+		data, err := raw.ToJSON()
+		require.NoError(t, err)
+
+		decoded, err := decoder.DecodeEvent(string(EventTypeRaw), data)
+		require.NoError(t, err)
+		result, ok := decoded.(*ToolCallResultEvent)
+		require.True(t, ok, "expected a tool call result, got %T", decoded)
+		assert.Equal(t, secret.Content, result.Content)
+
+		// Unencrypted events pass through
+		data, err = NewRawEvent("plain").ToJSON()
+		require.NoError(t, err)
+		decoded, err = decoder.DecodeEvent(string(EventTypeRaw), data)
+		require.NoError(t, err)
+		assert.Equal(t, "plain", decoded.(*RawEvent).Event)
+	})
+}
@@ -0,0 +1,212 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// EventError describes a problem with an event. It is the common part of the
+// typed errors of the events package: ValidationError, DecodeError,
+// SequenceError and UnknownTypeError all match it with errors.As, so callers
+// can inspect the event type and field of any of them, and match the
+// specific type to tell the failures apart.
+type EventError struct {
+	// EventType is the type of the event concerned, if known
+	EventType EventType
+
+	// Field is the name or dotted path of the field concerned, if any, such
+	// as "messageId" or "messages.0.role"
+	Field string
+
+	// Message describes the problem
+	Message string
+}
+
+// Error implements the error interface
+func (e *EventError) Error() string {
+	if e.EventType == "" {
+		return e.Message
+	}
+	return string(e.EventType) + ": " + e.Message
+}
+
+// eventErrorSubject names the event concerned by an error
+func eventErrorSubject(t EventType) string {
+	if t == "" {
+		return "event"
+	}
+	return string(t)
+}
+
+// ValidationError is returned by Validate when an event breaks a protocol
+// rule, such as a missing required field
+type ValidationError struct {
+	EventError
+
+	// Err is the underlying error, such as the error of an invalid message
+	// in a snapshot, or nil
+	Err error
+}
+
+// newValidationError creates a validation error for a field of an event
+func newValidationError(t EventType, field, format string, args ...any) *ValidationError {
+	return &ValidationError{EventError: EventError{EventType: t, Field: field, Message: fmt.Sprintf(format, args...)}}
+}
+
+// wrapValidationError creates a validation error caused by err
+func wrapValidationError(t EventType, field string, err error, format string, args ...any) *ValidationError {
+	validationErr := newValidationError(t, field, format, args...)
+	validationErr.Err = err
+	return validationErr
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return eventErrorSubject(e.EventType) + " validation failed: " + e.detail()
+}
+
+// detail describes the error and its cause. A cause that is itself a
+// validation error, such as the error of a message in a snapshot, is
+// described without repeating the event type.
+func (e *ValidationError) detail() string {
+	switch cause := e.Err.(type) {
+	case nil:
+		return e.Message
+	case *ValidationError:
+		return e.Message + ": " + cause.detail()
+	default:
+		return e.Message + ": " + e.Err.Error()
+	}
+}
+
+// Unwrap returns the underlying error
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// As matches *EventError targets
+func (e *ValidationError) As(target any) bool {
+	return asEventError(&e.EventError, target)
+}
+
+// DecodeError describes a failure to decode an event payload, locating the
+// problem in the payload when the JSON decoder reports where it occurred
+type DecodeError struct {
+	EventError
+
+	// Offset is the byte offset in the payload at which decoding failed, or
+	// -1 if it is not known
+	Offset int64
+
+	// Err is the underlying decoding error
+	Err error
+}
+
+// newDecodeError wraps a decoding error, extracting its offset and field from
+// the encoding/json error types
+func newDecodeError(eventType EventType, err error) *DecodeError {
+	decodeErr := &DecodeError{
+		EventError: EventError{EventType: eventType, Message: err.Error()},
+		Offset:     -1,
+		Err:        err,
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		decodeErr.Offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		decodeErr.Offset = typeErr.Offset
+		decodeErr.Field = typeErr.Field
+	}
+	return decodeErr
+}
+
+// Error implements the error interface
+func (e *DecodeError) Error() string {
+	msg := "failed to decode event"
+	if e.EventType != "" {
+		msg = "failed to decode " + string(e.EventType)
+	}
+	if e.Field != "" {
+		msg += fmt.Sprintf(" field %q", e.Field)
+	}
+	if e.Offset >= 0 {
+		msg += fmt.Sprintf(" at offset %d", e.Offset)
+	}
+	return msg + ": " + e.Message
+}
+
+// Unwrap returns the underlying decoding error
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// As matches *EventError targets
+func (e *DecodeError) As(target any) bool {
+	return asEventError(&e.EventError, target)
+}
+
+// SequenceError describes an event that breaks the ordering rules of the
+// protocol, such as content for a message that never started. The findings
+// returned by SequenceValidationResult.Err match it with errors.As.
+type SequenceError struct {
+	EventError
+
+	// EventIndex is the position of the event in the sequence, or -1 for
+	// problems with the sequence as a whole
+	EventIndex int
+
+	// Rule is the code of the broken rule, such as RuleMessageNotStarted
+	Rule string
+
+	// Err is the underlying error, or nil
+	Err error
+}
+
+// Error implements the error interface
+func (e *SequenceError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the underlying error
+func (e *SequenceError) Unwrap() error {
+	return e.Err
+}
+
+// As matches *EventError targets
+func (e *SequenceError) As(target any) bool {
+	return asEventError(&e.EventError, target)
+}
+
+// UnknownTypeError is returned when decoding or constructing an event of a
+// type the package does not know
+type UnknownTypeError struct {
+	EventError
+}
+
+// newUnknownTypeError creates an error for an unknown event type
+func newUnknownTypeError(t EventType) *UnknownTypeError {
+	return &UnknownTypeError{EventError: EventError{EventType: t, Field: "type", Message: "unknown event type"}}
+}
+
+// Error implements the error interface
+func (e *UnknownTypeError) Error() string {
+	return "unknown event type: " + string(e.EventType)
+}
+
+// As matches *EventError targets
+func (e *UnknownTypeError) As(target any) bool {
+	return asEventError(&e.EventError, target)
+}
+
+// asEventError implements errors.As for the errors embedding an EventError
+func asEventError(e *EventError, target any) bool {
+	if t, ok := target.(**EventError); ok {
+		*t = e
+		return true
+	}
+	return false
+}
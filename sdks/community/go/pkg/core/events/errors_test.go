@@ -0,0 +1,156 @@
+package events
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventErrors(t *testing.T) {
+	t.Run("ValidationError", func(t *testing.T) {
+		err := NewToolCallStartEvent("", "search").Validate()
+
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, EventTypeToolCallStart, validationErr.EventType)
+		assert.Equal(t, "toolCallId", validationErr.Field)
+		assert.Equal(t, "TOOL_CALL_START validation failed: toolCallId field is required", err.Error())
+
+		var decodeErr *DecodeError
+		assert.False(t, errors.As(err, &decodeErr))
+	})
+
+	t.Run("ValidationError wrapping a cause", func(t *testing.T) {
+		event := NewMessagesSnapshotEvent([]Message{
+			diffMessage("msg-1", RoleUser, "Hi"),
+			{ID: "msg-2", Role: "narrator"},
+		})
+		err := event.Validate()
+
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, EventTypeMessagesSnapshot, validationErr.EventType)
+		assert.Equal(t, "messages.1", validationErr.Field)
+		assert.Contains(t, err.Error(), "invalid message at index 1: message role must be one of")
+		assert.NotNil(t, errors.Unwrap(err))
+	})
+
+	t.Run("ValidationError of a nested field", func(t *testing.T) {
+		err := NewMessagesSnapshotEvent([]Message{{
+			ID:        "msg-1",
+			Role:      RoleAssistant,
+			ToolCalls: []ToolCall{{ID: "call-1", Type: "function"}},
+		}}).Validate()
+		assert.Equal(t, "MESSAGES_SNAPSHOT validation failed: invalid message at index 0: "+
+			"invalid tool call at index 0: function name field is required", err.Error())
+
+		var fields []string
+		for cause := err; cause != nil; cause = errors.Unwrap(cause) {
+			validationErr, ok := cause.(*ValidationError)
+			require.True(t, ok, "cause %T is not a validation error", cause)
+			assert.Equal(t, EventTypeMessagesSnapshot, validationErr.EventType)
+			fields = append(fields, validationErr.Field)
+		}
+		assert.Equal(t, []string{"messages.0", "toolCalls.0", "function.name"}, fields)
+
+		err = NewStateDeltaEvent([]JSONPatchOperation{{Op: "add", Path: "/count"}}).Validate()
+		var validationErr *ValidationError
+		require.ErrorAs(t, errors.Unwrap(err), &validationErr)
+		assert.Equal(t, EventTypeStateDelta, validationErr.EventType)
+		assert.Equal(t, "value", validationErr.Field)
+
+		var messageErr *ValidationError
+		require.ErrorAs(t, Message{ID: "msg-1"}.Validate(), &messageErr)
+		assert.Equal(t, "role", messageErr.Field)
+	})
+
+	t.Run("DecodeError", func(t *testing.T) {
+		_, err := EventFromJSON([]byte(`{"type": "RUN_STARTED", "threadId": 42}`))
+
+		var decodeErr *DecodeError
+		require.ErrorAs(t, err, &decodeErr)
+		assert.Equal(t, EventTypeRunStarted, decodeErr.EventType)
+		assert.Equal(t, "threadId", decodeErr.Field)
+
+		var validationErr *ValidationError
+		assert.False(t, errors.As(err, &validationErr))
+	})
+
+	t.Run("UnknownTypeError", func(t *testing.T) {
+		_, err := EventFromJSON([]byte(`{"type": "NOT_A_TYPE"}`))
+
+		var unknownErr *UnknownTypeError
+		require.ErrorAs(t, err, &unknownErr)
+		assert.Equal(t, EventType("NOT_A_TYPE"), unknownErr.EventType)
+		assert.Equal(t, "unknown event type: NOT_A_TYPE", err.Error())
+
+		_, err = NewEvent("NOT_A_TYPE", nil)
+		assert.ErrorAs(t, err, &unknownErr)
+		_, err = NewEventDecoder(nil).DecodeEvent("NOT_A_TYPE", []byte(`{}`))
+		assert.ErrorAs(t, err, &unknownErr)
+	})
+
+	t.Run("SequenceError", func(t *testing.T) {
+		_, err := ValidateSequence([]Event{
+			NewRunStartedEvent("thread-1", "run-1"),
+			NewTextMessageContentEvent("msg-1", "Hello"),
+		}, DefaultProfile())
+
+		var sequenceErr *SequenceError
+		require.ErrorAs(t, err, &sequenceErr)
+		assert.Equal(t, RuleMessageNotStarted, sequenceErr.Rule)
+		assert.Equal(t, 1, sequenceErr.EventIndex)
+		assert.Equal(t, EventTypeTextMessageContent, sequenceErr.EventType)
+		assert.Equal(t, err.Error(), sequenceErr.Error())
+	})
+
+	t.Run("SequenceError from validators", func(t *testing.T) {
+		conversation := NewConversationValidator()
+		require.NoError(t, conversation.Process(NewTextMessageStartEvent("m1", WithRole(RoleUser))))
+		require.NoError(t, conversation.Process(NewStepStartedEvent("plan")))
+		err := conversation.Process(NewTextMessageStartEvent("m2", WithRole(RoleTool)))
+
+		var sequenceErr *SequenceError
+		require.ErrorAs(t, err, &sequenceErr)
+		assert.Equal(t, RuleInvalidRoleTransition, sequenceErr.Rule)
+		assert.Equal(t, 2, sequenceErr.EventIndex)
+		assert.Equal(t, EventTypeTextMessageStart, sequenceErr.EventType)
+		assert.Equal(t, "role", sequenceErr.Field)
+		assert.ErrorIs(t, err, ErrInvalidRoleTransition)
+
+		thinking := NewThinkingBlockValidator()
+		thinking.ValidateEvent(NewThinkingEndEvent())
+		require.ErrorAs(t, thinking.Result().Err(), &sequenceErr)
+		assert.Equal(t, RuleThinkingNotStarted, sequenceErr.Rule)
+		assert.Equal(t, EventTypeThinkingEnd, sequenceErr.EventType)
+
+		deadline := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+		expiry := NewToolCallExpiryValidator()
+		expiry.ValidateEvent(NewToolCallStartEvent("call-1", "search", WithExpiresAt(deadline)))
+		expiry.Finish()
+		require.ErrorAs(t, expiry.Result().Err(), &sequenceErr)
+		assert.Equal(t, RuleToolCallExpired, sequenceErr.Rule)
+		assert.Equal(t, EventTypeToolCallStart, sequenceErr.EventType)
+	})
+
+	t.Run("EventError matches every type", func(t *testing.T) {
+		_, decodeErr := EventFromJSON([]byte(`{"type": "RUN_STARTED", "threadId": 42}`))
+		_, unknownErr := EventFromJSON([]byte(`{"type": "NOT_A_TYPE"}`))
+		_, sequenceErr := ValidateSequence([]Event{NewTextMessageEndEvent("msg-1")}, DefaultProfile())
+
+		for _, err := range []error{
+			NewRunStartedEvent("thread-1", "").Validate(),
+			decodeErr,
+			unknownErr,
+			sequenceErr,
+		} {
+			var eventErr *EventError
+			require.ErrorAs(t, err, &eventErr, err.Error())
+			assert.NotEmpty(t, eventErr.EventType, err.Error())
+			assert.NotEmpty(t, eventErr.Message, err.Error())
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package events
+
+import (
+	"reflect"
+	"sync"
+)
+
+// eventPools and eventPoolsByGoType hold a pool of reusable events per
+// event type, indexed by event type and by Go type. They are built once and
+// only read afterwards.
+var eventPools, eventPoolsByGoType = newEventPools()
+
+// newEventPools creates a pool for every known event type
+func newEventPools() (map[EventType]*sync.Pool, map[reflect.Type]*sync.Pool) {
+	pools := make(map[EventType]*sync.Pool, len(validEventTypes))
+	byGoType := make(map[reflect.Type]*sync.Pool, len(validEventTypes))
+	for t := range validEventTypes {
+		event := newEventOfType(t)
+		if event == nil {
+			continue
+		}
+		pool := &sync.Pool{New: func() any { return newEventOfType(t) }}
+		pools[t] = pool
+		byGoType[reflect.TypeOf(event)] = pool
+	}
+	return pools, byGoType
+}
+
+// acquireEvent returns an empty event of the given type, reused from its
+// pool if one has been released, or nil if the type is unknown
+func acquireEvent(t EventType) Event {
+	pool, ok := eventPools[t]
+	if !ok {
+		return nil
+	}
+	event := pool.Get().(Event)
+	event.GetBaseEvent().EventType = t
+	return event
+}
+
+// ReleaseEvent returns a decoded event to a pool, so that a later decode of
+// the same type reuses its memory instead of allocating, which reduces
+// garbage collection on servers decoding many events.
+//
+// Releasing transfers ownership of the event to the decoder: neither the
+// event nor any value read from its fields, such as slices, maps or
+// pointers, may be used after the call, and an event must not be released
+// twice. Events still referenced elsewhere, for example buffered for a
+// replay or sent to another goroutine, must not be released. Releasing
+// is optional; events that are not released are garbage collected as usual.
+// Nil events and events of types unknown to the package are ignored.
+func ReleaseEvent(e Event) {
+	if e == nil {
+		return
+	}
+	pool, ok := eventPoolsByGoType[reflect.TypeOf(e)]
+	if !ok {
+		return
+	}
+	value := reflect.ValueOf(e)
+	if value.IsNil() {
+		return
+	}
+
+	resetEvent(value.Elem(), e.GetBaseEvent())
+	pool.Put(e)
+}
+
+// resetEvent zeroes an event struct, keeping its base event allocation
+func resetEvent(event reflect.Value, base *BaseEvent) {
+	if base == nil {
+		base = &BaseEvent{}
+	} else {
+		*base = BaseEvent{}
+	}
+	event.SetZero()
+	event.FieldByName("BaseEvent").Set(reflect.ValueOf(base))
+}
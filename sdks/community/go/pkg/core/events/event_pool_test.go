@@ -0,0 +1,96 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseEvent(t *testing.T) {
+	t.Run("ResetsFields", func(t *testing.T) {
+		decoder := NewEventDecoder(nil)
+		event, err := decoder.DecodeEvent("TOOL_CALL_START", []byte(`{"type": "TOOL_CALL_START", "toolCallId": "call-1",
+			"toolCallName": "search", "parentMessageId": "msg-1", "timeoutMs": 500, "timestamp": 100, "correlationId": "corr-1"}`))
+		require.NoError(t, err)
+		start := event.(*ToolCallStartEvent)
+		base := start.BaseEvent
+
+		ReleaseEvent(start)
+		assert.Same(t, base, start.BaseEvent)
+		assert.Equal(t, ToolCallStartEvent{BaseEvent: &BaseEvent{}}, *start)
+	})
+
+	t.Run("ReusedEventHasNoStaleFields", func(t *testing.T) {
+		decoder := NewEventDecoder(nil)
+		full := []byte(`{"type": "TEXT_MESSAGE_START", "messageId": "msg-1", "role": "assistant", "timestamp": 100}`)
+		minimal := []byte(`{"type": "TEXT_MESSAGE_START", "messageId": "msg-2"}`)
+
+		// The pool may drop released events, so repeat until one is reused
+		for i := 0; i < 100; i++ {
+			first, err := decoder.DecodeEvent("TEXT_MESSAGE_START", full)
+			require.NoError(t, err)
+			ReleaseEvent(first)
+
+			second, err := decoder.DecodeEvent("TEXT_MESSAGE_START", minimal)
+			require.NoError(t, err)
+			start := second.(*TextMessageStartEvent)
+			assert.Equal(t, "msg-2", start.MessageID)
+			assert.Nil(t, start.Role)
+			assert.Nil(t, start.Timestamp())
+			assert.Equal(t, EventTypeTextMessageStart, start.Type())
+			assert.Positive(t, start.Sequence())
+			ReleaseEvent(second)
+		}
+	})
+
+	t.Run("MapsAreNotMerged", func(t *testing.T) {
+		decoder := NewEventDecoder(nil)
+		for i := 0; i < 100; i++ {
+			first, err := decoder.DecodeEvent("STATE_SNAPSHOT", []byte(`{"type": "STATE_SNAPSHOT", "snapshot": {"a": 1}}`))
+			require.NoError(t, err)
+			ReleaseEvent(first)
+
+			second, err := decoder.DecodeEvent("STATE_SNAPSHOT", []byte(`{"type": "STATE_SNAPSHOT", "snapshot": {"b": 2}}`))
+			require.NoError(t, err)
+			assert.Equal(t, map[string]any{"b": 2.0}, second.(*StateSnapshotEvent).Snapshot)
+			ReleaseEvent(second)
+		}
+	})
+
+	t.Run("NilAndEmptyEvents", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			ReleaseEvent(nil)
+			ReleaseEvent((*TextMessageStartEvent)(nil))
+			ReleaseEvent(&TextMessageEndEvent{})
+		})
+	})
+}
+
+func BenchmarkDecodeEvent(b *testing.B) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	decoder := NewEventDecoder(logger)
+	data := []byte(`{"type": "TEXT_MESSAGE_CONTENT", "messageId": "msg-1", "delta": "Hello", "timestamp": 100}`)
+
+	b.Run("Allocate", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := decoder.DecodeEvent("TEXT_MESSAGE_CONTENT", data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Release", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			event, err := decoder.DecodeEvent("TEXT_MESSAGE_CONTENT", data)
+			if err != nil {
+				b.Fatal(err)
+			}
+			ReleaseEvent(event)
+		}
+	})
+}
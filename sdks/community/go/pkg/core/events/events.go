@@ -1,9 +1,14 @@
 package events
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 )
 
 // EventType represents the type of AG-UI event
@@ -31,6 +36,9 @@ const (
 	EventTypeRunError           EventType = "RUN_ERROR"
 	EventTypeStepStarted        EventType = "STEP_STARTED"
 	EventTypeStepFinished       EventType = "STEP_FINISHED"
+	EventTypeThreadCreated      EventType = "THREAD_CREATED"
+	EventTypeThreadDeleted      EventType = "THREAD_DELETED"
+	EventTypeMetadata           EventType = "METADATA"
 
 	// Thinking events for reasoning phase support
 	EventTypeThinkingStart              EventType = "THINKING_START"
@@ -64,6 +72,9 @@ var validEventTypes = map[EventType]bool{
 	EventTypeRunError:                   true,
 	EventTypeStepStarted:                true,
 	EventTypeStepFinished:               true,
+	EventTypeThreadCreated:              true,
+	EventTypeThreadDeleted:              true,
+	EventTypeMetadata:                   true,
 	EventTypeThinkingStart:              true,
 	EventTypeThinkingEnd:                true,
 	EventTypeThinkingTextMessageStart:   true,
@@ -103,6 +114,29 @@ type BaseEvent struct {
 	EventType   EventType `json:"type"`
 	TimestampMs *int64    `json:"timestamp,omitempty"`
 	RawEvent    any       `json:"rawEvent,omitempty"`
+
+	// CorrelationIDValue ties the event to a run across services,
+	// independently of the run and thread IDs
+	CorrelationIDValue *string `json:"correlationId,omitempty"`
+
+	// SignatureValue is the HMAC signature set by SignEvent, for deployments
+	// that require tamper-evident events
+	SignatureValue *string `json:"signature,omitempty"`
+
+	// sequence orders decoded events that share a timestamp. It is assigned
+	// from a process-wide counter when the event is decoded and is zero for
+	// events built in code.
+	sequence uint64
+}
+
+// decodeSequence is the last sequence number assigned to a decoded event
+var decodeSequence atomic.Uint64
+
+// assignSequence stamps a freshly decoded event with the next sequence number
+func assignSequence(event Event) {
+	if base := event.GetBaseEvent(); base != nil {
+		base.sequence = decodeSequence.Add(1)
+	}
 }
 
 // Type returns the event type
@@ -146,11 +180,36 @@ func (b *BaseEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(eventData)
 }
 
+// CorrelationID returns the correlation ID of the event, or an empty string
+// if it has none
+func (b *BaseEvent) CorrelationID() string {
+	if b.CorrelationIDValue == nil {
+		return ""
+	}
+	return *b.CorrelationIDValue
+}
+
+// SetCorrelationID sets the correlation ID of the event
+func (b *BaseEvent) SetCorrelationID(id string) {
+	b.CorrelationIDValue = &id
+}
+
 // GetBaseEvent returns the base event
 func (b *BaseEvent) GetBaseEvent() *BaseEvent {
 	return b
 }
 
+// String returns a concise human-readable summary of the event
+func (b *BaseEvent) String() string {
+	return formatEvent(b.EventType)
+}
+
+// Sequence returns the decode sequence number of the event, or zero if the
+// event was not produced by a decoder
+func (b *BaseEvent) Sequence() uint64 {
+	return b.sequence
+}
+
 // ThreadID returns the thread ID (default implementation returns empty string)
 func (b *BaseEvent) ThreadID() string {
 	return ""
@@ -173,226 +232,250 @@ func NewBaseEvent(eventType EventType) *BaseEvent {
 // Validate validates the base event structure
 func (b *BaseEvent) Validate() error {
 	if b.EventType == "" {
-		return fmt.Errorf("BaseEvent validation failed: type field is required")
+		return newValidationError("", "type", "type field is required")
 	}
 
 	if !isValidEventType(b.EventType) {
-		return fmt.Errorf("BaseEvent validation failed: invalid event type '%s'", b.EventType)
+		return newValidationError(b.EventType, "type", "invalid event type '%s'", b.EventType)
 	}
 
 	return nil
 }
 
-// isValidEventType checks if the given event type is valid
-func isValidEventType(eventType EventType) bool {
-	return validEventTypes[eventType]
+// maxSummaryTextLength is the maximum number of runes of free-form text
+// (deltas, messages, content) included in an event summary
+const maxSummaryTextLength = 32
+
+// formatEvent builds an event summary of the form TYPE(key=value ...)
+func formatEvent(eventType EventType, fields ...string) string {
+	return fmt.Sprintf("%s(%s)", eventType, strings.Join(fields, " "))
 }
 
-// ValidateSequence validates a sequence of events according to AG-UI protocol rules
-func ValidateSequence(events []Event) error {
-	if len(events) == 0 {
-		return nil
+// summaryField formats a key=value pair for an event summary
+func summaryField(key, value string) string {
+	return key + "=" + value
+}
+
+// summaryText formats free-form text as a quoted key=value pair, truncating
+// long values so summaries stay on a single readable line
+func summaryText(key, value string) string {
+	if utf8.RuneCountInString(value) > maxSummaryTextLength {
+		runes := []rune(value)
+		value = string(runes[:maxSummaryTextLength]) + "..."
+	}
+	return fmt.Sprintf("%s=%q", key, value)
+}
+
+// appendOptionalField appends a key=value pair when the value is set
+func appendOptionalField(fields []string, key string, value *string) []string {
+	if value == nil {
+		return fields
 	}
+	return append(fields, summaryField(key, *value))
+}
 
-	// Track active runs, messages, tool calls, and steps
-	activeRuns := make(map[string]bool)
-	activeMessages := make(map[string]bool)
-	activeToolCalls := make(map[string]bool)
-	activeSteps := make(map[string]bool)
-	finishedRuns := make(map[string]bool)
+// ToJSONWithoutTimestamp serializes the event to JSON without its timestamp,
+// producing deterministic output for golden files and content hashing. The
+// event itself is left unchanged.
+func ToJSONWithoutTimestamp(event Event) ([]byte, error) {
+	clone, err := CopyEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	if base := clone.GetBaseEvent(); base != nil {
+		base.TimestampMs = nil
+	}
+	return clone.ToJSON()
+}
 
+// ValidationResult is the outcome of validating one event of a batch
+type ValidationResult struct {
+	// Index is the position of the event in the batch
+	Index int
+
+	// Event is the validated event
+	Event Event
+
+	// Err is the validation error, or nil if the event is valid
+	Err error
+}
+
+// BatchValidate validates every event in the batch and returns one result
+// per event, in order
+func BatchValidate(events []Event) []ValidationResult {
+	results := make([]ValidationResult, len(events))
 	for i, event := range events {
-		if err := event.Validate(); err != nil {
-			return fmt.Errorf("event %d validation failed: %w", i, err)
+		results[i] = ValidationResult{Index: i, Event: event}
+		if event == nil {
+			results[i].Err = &EventError{Message: fmt.Sprintf("event at index %d is nil", i)}
+			continue
 		}
+		results[i].Err = event.Validate()
+	}
+	return results
+}
+
+// HasValidationErrors reports whether any event in the batch failed validation
+func HasValidationErrors(results []ValidationResult) bool {
+	return FirstValidationError(results) != nil
+}
 
-		// Check sequence-specific validation rules
-		switch event.Type() {
-		case EventTypeRunStarted:
-			if runEvent, ok := event.(*RunStartedEvent); ok {
-				if activeRuns[runEvent.RunID()] {
-					return fmt.Errorf("run %s already started", runEvent.RunID())
-				}
-				if finishedRuns[runEvent.RunID()] {
-					return fmt.Errorf("cannot restart finished run %s", runEvent.RunID())
-				}
-				activeRuns[runEvent.RunID()] = true
-			}
-
-		case EventTypeRunFinished:
-			if runEvent, ok := event.(*RunFinishedEvent); ok {
-				if !activeRuns[runEvent.RunID()] {
-					return fmt.Errorf("cannot finish run %s that was not started", runEvent.RunID())
-				}
-				delete(activeRuns, runEvent.RunID())
-				finishedRuns[runEvent.RunID()] = true
-			}
-
-		case EventTypeRunError:
-			if runEvent, ok := event.(*RunErrorEvent); ok {
-				if runEvent.RunID() != "" && !activeRuns[runEvent.RunID()] {
-					return fmt.Errorf("cannot error run %s that was not started", runEvent.RunID())
-				}
-				if runEvent.RunID() != "" {
-					delete(activeRuns, runEvent.RunID())
-					finishedRuns[runEvent.RunID()] = true
-				}
-			}
-
-		case EventTypeStepStarted:
-			if stepEvent, ok := event.(*StepStartedEvent); ok {
-				if activeSteps[stepEvent.StepName] {
-					return fmt.Errorf("step %s already started", stepEvent.StepName)
-				}
-				activeSteps[stepEvent.StepName] = true
-			}
-
-		case EventTypeStepFinished:
-			if stepEvent, ok := event.(*StepFinishedEvent); ok {
-				if !activeSteps[stepEvent.StepName] {
-					return fmt.Errorf("cannot finish step %s that was not started", stepEvent.StepName)
-				}
-				delete(activeSteps, stepEvent.StepName)
-			}
-
-		case EventTypeTextMessageStart:
-			if msgEvent, ok := event.(*TextMessageStartEvent); ok {
-				if activeMessages[msgEvent.MessageID] {
-					return fmt.Errorf("message %s already started", msgEvent.MessageID)
-				}
-				activeMessages[msgEvent.MessageID] = true
-			}
-
-		case EventTypeTextMessageContent:
-			if msgEvent, ok := event.(*TextMessageContentEvent); ok {
-				if !activeMessages[msgEvent.MessageID] {
-					return fmt.Errorf("cannot add content to message %s that was not started", msgEvent.MessageID)
-				}
-				// Content events are valid between start and end
-			}
-
-		case EventTypeTextMessageEnd:
-			if msgEvent, ok := event.(*TextMessageEndEvent); ok {
-				if !activeMessages[msgEvent.MessageID] {
-					return fmt.Errorf("cannot end message %s that was not started", msgEvent.MessageID)
-				}
-				delete(activeMessages, msgEvent.MessageID)
-			}
-
-		case EventTypeToolCallStart:
-			if toolEvent, ok := event.(*ToolCallStartEvent); ok {
-				if activeToolCalls[toolEvent.ToolCallID] {
-					return fmt.Errorf("tool call %s already started", toolEvent.ToolCallID)
-				}
-				activeToolCalls[toolEvent.ToolCallID] = true
-			}
-
-		case EventTypeToolCallArgs:
-			if toolEvent, ok := event.(*ToolCallArgsEvent); ok {
-				if !activeToolCalls[toolEvent.ToolCallID] {
-					return fmt.Errorf("cannot add args to tool call %s that was not started", toolEvent.ToolCallID)
-				}
-				// Args events are valid between start and end
-			}
-
-		case EventTypeToolCallEnd:
-			if toolEvent, ok := event.(*ToolCallEndEvent); ok {
-				if !activeToolCalls[toolEvent.ToolCallID] {
-					return fmt.Errorf("cannot end tool call %s that was not started", toolEvent.ToolCallID)
-				}
-				delete(activeToolCalls, toolEvent.ToolCallID)
-			}
-
-		case EventTypeStateSnapshot:
-			// State snapshot events are always valid in sequence context
-			// They represent complete state at any point in time
-			// Additional validation could be added if needed (e.g., frequency limits)
-
-		case EventTypeStateDelta:
-			// State delta events are always valid in sequence context
-			// They represent incremental changes at any point in time
-			// Additional validation could be added if needed (e.g., conflict detection)
-
-		case EventTypeMessagesSnapshot:
-			// Message snapshot events are always valid in sequence context
-			// They represent complete message state at any point in time
-			// Additional validation could be added if needed (e.g., consistency checks)
-
-		case EventTypeRaw:
-			// Raw events are always valid in sequence context
-			// They contain external data that should be passed through
-			// Additional validation could be added via custom validators
-
-		case EventTypeCustom:
-			// Custom events are always valid in sequence context
-			// They contain application-specific data
-			// Additional validation could be added via custom validators
-
-		default:
-			// This should not happen due to prior validation, but add safety check
-			return fmt.Errorf("unknown event type in sequence: %s", event.Type())
+// FirstValidationError returns the first validation error in the batch, or
+// nil if every event is valid
+func FirstValidationError(results []ValidationResult) error {
+	for _, result := range results {
+		if result.Err != nil {
+			return result.Err
 		}
 	}
-
 	return nil
 }
 
-// EventFromJSON parses an event from JSON data
-func EventFromJSON(data []byte) (Event, error) {
-	// First, parse the base event to determine the type
-	var base struct {
-		Type EventType `json:"type"`
+// SortEvents sorts events in place by timestamp, for example after merging
+// the streams of several sources. Events with equal timestamps are ordered
+// by their decode sequence, and the sort is stable, so events that share
+// both keep their relative order. Events without a timestamp sort last.
+func SortEvents(events []Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		ti, tj := events[i].Timestamp(), events[j].Timestamp()
+		switch {
+		case ti == nil || tj == nil:
+			return ti != nil && tj == nil
+		case *ti != *tj:
+			return *ti < *tj
+		}
+		return events[i].GetBaseEvent().Sequence() < events[j].GetBaseEvent().Sequence()
+	})
+}
+
+// isValidEventType checks if the given event type is valid
+func isValidEventType(eventType EventType) bool {
+	return validEventTypes[eventType]
+}
+
+// payloadlessEventTypes are the event types that carry no fields besides
+// those of the base event
+var payloadlessEventTypes = map[EventType]bool{
+	EventTypeThinkingEnd:              true,
+	EventTypeThinkingTextMessageStart: true,
+	EventTypeThinkingTextMessageEnd:   true,
+}
+
+// NewEvent constructs an event of the given type from its JSON encoding, as
+// produced by ToJSON. Data may be empty for event types that carry no fields
+// besides the base event. If the data has a type field, it must match t.
+func NewEvent(t EventType, data []byte) (Event, error) {
+	event := newEventOfType(t)
+	if event == nil {
+		return nil, newUnknownTypeError(t)
 	}
 
-	if err := json.Unmarshal(data, &base); err != nil {
-		return nil, fmt.Errorf("failed to parse event type: %w", err)
+	if len(bytes.TrimSpace(data)) == 0 {
+		if !payloadlessEventTypes[t] {
+			return nil, newDecodeError(t, fmt.Errorf("data is required for %s events", t))
+		}
+	} else {
+		if err := json.Unmarshal(data, event); err != nil {
+			return nil, newDecodeError(t, err)
+		}
+		if event.Type() != t {
+			decodeErr := newDecodeError(t, fmt.Errorf("event data has type %s, expected %s", event.Type(), t))
+			decodeErr.Field = "type"
+			return nil, decodeErr
+		}
 	}
 
-	// Create the appropriate event type based on the type field
-	var event Event
-	switch base.Type {
+	assignSequence(event)
+	return event, nil
+}
+
+// newEventOfType returns an empty event of the given type, ready to be
+// unmarshalled into, or nil if the type is unknown
+func newEventOfType(t EventType) Event {
+	base := &BaseEvent{EventType: t}
+
+	switch t {
 	case EventTypeRunStarted:
-		event = &RunStartedEvent{}
+		return &RunStartedEvent{BaseEvent: base}
 	case EventTypeRunFinished:
-		event = &RunFinishedEvent{}
+		return &RunFinishedEvent{BaseEvent: base}
 	case EventTypeRunError:
-		event = &RunErrorEvent{}
+		return &RunErrorEvent{BaseEvent: base}
 	case EventTypeStepStarted:
-		event = &StepStartedEvent{}
+		return &StepStartedEvent{BaseEvent: base}
 	case EventTypeStepFinished:
-		event = &StepFinishedEvent{}
+		return &StepFinishedEvent{BaseEvent: base}
+	case EventTypeThreadCreated:
+		return &ThreadCreatedEvent{BaseEvent: base}
+	case EventTypeThreadDeleted:
+		return &ThreadDeletedEvent{BaseEvent: base}
+	case EventTypeMetadata:
+		return &MetadataEvent{BaseEvent: base}
 	case EventTypeTextMessageStart:
-		event = &TextMessageStartEvent{}
+		return &TextMessageStartEvent{BaseEvent: base}
 	case EventTypeTextMessageContent:
-		event = &TextMessageContentEvent{}
+		return &TextMessageContentEvent{BaseEvent: base}
 	case EventTypeTextMessageEnd:
-		event = &TextMessageEndEvent{}
+		return &TextMessageEndEvent{BaseEvent: base}
+	case EventTypeTextMessageChunk:
+		return &TextMessageChunkEvent{BaseEvent: base}
 	case EventTypeToolCallStart:
-		event = &ToolCallStartEvent{}
+		return &ToolCallStartEvent{BaseEvent: base}
 	case EventTypeToolCallArgs:
-		event = &ToolCallArgsEvent{}
+		return &ToolCallArgsEvent{BaseEvent: base}
 	case EventTypeToolCallEnd:
-		event = &ToolCallEndEvent{}
+		return &ToolCallEndEvent{BaseEvent: base}
+	case EventTypeToolCallChunk:
+		return &ToolCallChunkEvent{BaseEvent: base}
 	case EventTypeToolCallResult:
-		event = &ToolCallResultEvent{}
+		return &ToolCallResultEvent{BaseEvent: base}
 	case EventTypeStateSnapshot:
-		event = &StateSnapshotEvent{}
+		return &StateSnapshotEvent{BaseEvent: base}
 	case EventTypeStateDelta:
-		event = &StateDeltaEvent{}
+		return &StateDeltaEvent{BaseEvent: base}
 	case EventTypeMessagesSnapshot:
-		event = &MessagesSnapshotEvent{}
+		return &MessagesSnapshotEvent{BaseEvent: base}
+	case EventTypeThinkingStart:
+		return &ThinkingStartEvent{BaseEvent: base}
+	case EventTypeThinkingEnd:
+		return &ThinkingEndEvent{BaseEvent: base}
+	case EventTypeThinkingTextMessageStart:
+		return &ThinkingTextMessageStartEvent{BaseEvent: base}
+	case EventTypeThinkingTextMessageContent:
+		return &ThinkingTextMessageContentEvent{BaseEvent: base}
+	case EventTypeThinkingTextMessageEnd:
+		return &ThinkingTextMessageEndEvent{BaseEvent: base}
 	case EventTypeRaw:
-		event = &RawEvent{}
+		return &RawEvent{BaseEvent: base}
 	case EventTypeCustom:
-		event = &CustomEvent{}
+		return &CustomEvent{BaseEvent: base}
 	default:
-		return nil, fmt.Errorf("unknown event type: %s", base.Type)
+		return nil
+	}
+}
+
+// EventFromJSON parses an event from JSON data
+func EventFromJSON(data []byte) (Event, error) {
+	// First, parse the base event to determine the type
+	var base struct {
+		Type EventType `json:"type"`
+	}
+
+	if err := json.Unmarshal(data, &base); err != nil {
+		return nil, newDecodeError("", err)
+	}
+
+	// Create the appropriate event type based on the type field
+	event := newEventOfType(base.Type)
+	if event == nil {
+		return nil, newUnknownTypeError(base.Type)
 	}
 
 	// Unmarshal into the specific event type
 	if err := json.Unmarshal(data, event); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal event: %w", err)
+		return nil, newDecodeError(base.Type, err)
 	}
 
+	assignSequence(event)
 	return event, nil
 }
@@ -1,6 +1,10 @@
 package events
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -68,6 +72,54 @@ func TestRunEvents(t *testing.T) {
 		assert.Error(t, event.Validate())
 	})
 
+	t.Run("RunStartedEvent_WithAgent", func(t *testing.T) {
+		event := NewRunStartedEventWithOptions("thread-123", "run-456",
+			WithAgentID("planner"), WithAgentVersion("1.2.0"))
+
+		require.NotNil(t, event.AgentID)
+		require.NotNil(t, event.AgentVersion)
+		assert.Equal(t, "planner", *event.AgentID)
+		assert.Equal(t, "1.2.0", *event.AgentVersion)
+		assert.NoError(t, event.Validate())
+
+		jsonData, err := event.ToJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(jsonData), `"agentId":"planner"`)
+		assert.Contains(t, string(jsonData), `"agentVersion":"1.2.0"`)
+
+		// Agent metadata is omitted when not set
+		jsonData, err = NewRunStartedEvent("thread-123", "run-456").ToJSON()
+		require.NoError(t, err)
+		assert.NotContains(t, string(jsonData), "agentId")
+		assert.NotContains(t, string(jsonData), "agentVersion")
+	})
+
+	t.Run("RunStartedEvent_WithModel", func(t *testing.T) {
+		event := NewRunStartedEventWithOptions("thread-123", "run-456",
+			WithModelID("claude-sonnet-4"), WithModelProvider(ProviderAnthropic))
+
+		require.NotNil(t, event.ModelID)
+		require.NotNil(t, event.ModelProvider)
+		assert.Equal(t, "claude-sonnet-4", *event.ModelID)
+		assert.Equal(t, "anthropic", *event.ModelProvider)
+		assert.NoError(t, event.Validate())
+
+		jsonData, err := event.ToJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(jsonData), `"modelId":"claude-sonnet-4"`)
+		assert.Contains(t, string(jsonData), `"modelProvider":"anthropic"`)
+
+		// Model metadata is omitted when not set
+		jsonData, err = NewRunStartedEvent("thread-123", "run-456").ToJSON()
+		require.NoError(t, err)
+		assert.NotContains(t, string(jsonData), "modelId")
+		assert.NotContains(t, string(jsonData), "modelProvider")
+
+		report := AuditRun([]Event{event, NewRunFinishedEvent("thread-123", "run-456")})
+		assert.Equal(t, "claude-sonnet-4", report.ModelID)
+		assert.Equal(t, ProviderAnthropic, report.ModelProvider)
+	})
+
 	t.Run("RunFinishedEvent", func(t *testing.T) {
 		threadID := "thread-123"
 		runID := "run-456"
@@ -85,6 +137,65 @@ func TestRunEvents(t *testing.T) {
 		assert.Contains(t, string(jsonData), threadID)
 	})
 
+	t.Run("RunFinishedEvent_ResultAs", func(t *testing.T) {
+		type summary struct {
+			Answer string   `json:"answer"`
+			Tokens int      `json:"tokens"`
+			Tags   []string `json:"tags"`
+		}
+
+		event := NewRunFinishedEventWithOptions("thread-123", "run-456",
+			WithResult(map[string]any{"answer": "42", "tokens": 7, "tags": []string{"final"}}))
+		jsonData, err := event.ToJSON()
+		require.NoError(t, err)
+
+		// The decoded result is generic JSON
+		decoded, err := EventFromJSON(jsonData)
+		require.NoError(t, err)
+		finished := decoded.(*RunFinishedEvent)
+
+		result, err := ResultAs[summary](finished)
+		require.NoError(t, err)
+		assert.Equal(t, summary{Answer: "42", Tokens: 7, Tags: []string{"final"}}, result)
+
+		_, err = ResultAs[[]string](finished)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrNoResult)
+
+		_, err = ResultAs[summary](NewRunFinishedEvent("thread-123", "run-456"))
+		assert.ErrorIs(t, err, ErrNoResult)
+		_, err = ResultAs[summary](nil)
+		assert.ErrorIs(t, err, ErrNoResult)
+	})
+
+	t.Run("RunEvents_WithConversationID", func(t *testing.T) {
+		started := NewRunStartedEventWithOptions("thread-123", "run-456", WithConversationIDStarted("slack-C123"))
+		finished := NewRunFinishedEventWithOptions("thread-123", "run-456", WithConversationID("slack-C123"))
+
+		require.NotNil(t, started.ConversationID)
+		require.NotNil(t, finished.ConversationID)
+		assert.Equal(t, "slack-C123", *started.ConversationID)
+		assert.Equal(t, "slack-C123", *finished.ConversationID)
+
+		for _, event := range []Event{started, finished} {
+			jsonData, err := event.ToJSON()
+			require.NoError(t, err)
+			assert.Contains(t, string(jsonData), `"conversationId":"slack-C123"`)
+
+			decoded, err := EventFromJSON(jsonData)
+			require.NoError(t, err)
+			assert.Equal(t, event, clearSequence(decoded))
+		}
+
+		// The conversation ID is omitted when not set
+		jsonData, err := NewRunFinishedEvent("thread-123", "run-456").ToJSON()
+		require.NoError(t, err)
+		assert.NotContains(t, string(jsonData), "conversationId")
+
+		report := AuditRun([]Event{started, finished})
+		assert.Equal(t, "slack-C123", report.ConversationID)
+	})
+
 	t.Run("RunErrorEvent", func(t *testing.T) {
 		message := "Something went wrong"
 		code := "ERROR_CODE"
@@ -103,6 +214,43 @@ func TestRunEvents(t *testing.T) {
 		assert.Error(t, event.Validate())
 	})
 
+	t.Run("RunErrorEvent_FromError", func(t *testing.T) {
+		// A plain error has no code
+		event := NewRunErrorFromError(errors.New("connection reset"), WithRunID("run-456"))
+		assert.Equal(t, "connection reset", event.Message)
+		assert.Nil(t, event.Code)
+		assert.Equal(t, "run-456", event.RunID())
+		assert.NoError(t, event.Validate())
+
+		// A coded error sets the code
+		event = NewRunErrorFromError(codedError{code: "RATE_LIMITED", message: "too many requests"})
+		assert.Equal(t, "too many requests", event.Message)
+		require.NotNil(t, event.Code)
+		assert.Equal(t, "RATE_LIMITED", *event.Code)
+
+		// The code of a wrapped sentinel is found, while the message is the
+		// full message
+		errQuota := codedError{code: "QUOTA_EXCEEDED", message: "quota exceeded"}
+		wrapped := fmt.Errorf("calling model: %w", fmt.Errorf("tenant acme: %w", errQuota))
+		event = NewRunErrorFromError(wrapped)
+		assert.Equal(t, "calling model: tenant acme: quota exceeded", event.Message)
+		require.NotNil(t, event.Code)
+		assert.Equal(t, "QUOTA_EXCEEDED", *event.Code)
+
+		// Empty codes are skipped, and joined errors are searched
+		event = NewRunErrorFromError(errors.Join(codedError{message: "no code"}, errors.New("plain"), errQuota))
+		require.NotNil(t, event.Code)
+		assert.Equal(t, "QUOTA_EXCEEDED", *event.Code)
+
+		// Options override the code
+		event = NewRunErrorFromError(errQuota, WithErrorCode("CUSTOM"))
+		assert.Equal(t, "CUSTOM", *event.Code)
+
+		event = NewRunErrorFromError(nil)
+		assert.Equal(t, "unknown error", event.Message)
+		assert.NoError(t, event.Validate())
+	})
+
 	t.Run("StepStartedEvent", func(t *testing.T) {
 		stepName := "step-1"
 
@@ -150,6 +298,28 @@ func TestMessageEvents(t *testing.T) {
 		assert.Error(t, event.Validate())
 	})
 
+	t.Run("TextMessageStartEvent_WithFunctionCallID", func(t *testing.T) {
+		event := NewTextMessageStartEvent("msg-123", WithRole("assistant"), WithFunctionCallID("call-789"))
+
+		require.NotNil(t, event.FunctionCallID)
+		assert.Equal(t, "call-789", *event.FunctionCallID)
+		assert.NoError(t, event.Validate())
+
+		jsonData, err := event.ToJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(jsonData), `"functionCallId":"call-789"`)
+
+		decoded, err := NewEventDecoder(nil).DecodeEvent(string(EventTypeTextMessageStart), jsonData)
+		require.NoError(t, err)
+		require.NotNil(t, decoded.(*TextMessageStartEvent).FunctionCallID)
+		assert.Equal(t, "call-789", *decoded.(*TextMessageStartEvent).FunctionCallID)
+
+		// The function call ID is omitted when not set
+		jsonData, err = NewTextMessageStartEvent("msg-123").ToJSON()
+		require.NoError(t, err)
+		assert.NotContains(t, string(jsonData), "functionCallId")
+	})
+
 	t.Run("TextMessageContentEvent", func(t *testing.T) {
 		messageID := "msg-123"
 		delta := "Hello"
@@ -170,6 +340,58 @@ func TestMessageEvents(t *testing.T) {
 		assert.Error(t, event.Validate())
 	})
 
+	t.Run("TextMessageContentEvent_WithTextEdit", func(t *testing.T) {
+		event := NewTextMessageContentEventWithOptions("msg-123", "ignored",
+			WithTextEdit(TextEdit{Offset: 6, Delete: 5, Insert: "there"}))
+
+		assert.Empty(t, event.Delta)
+		require.NotNil(t, event.Edit)
+		assert.NoError(t, event.Validate())
+
+		jsonData, err := event.ToJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(jsonData), `"edit":{"offset":6,"delete":5,"insert":"there"}`)
+		assert.NotContains(t, string(jsonData), "delta")
+
+		decoded, err := EventFromJSON(jsonData)
+		require.NoError(t, err)
+		assert.Equal(t, event, clearSequence(decoded))
+
+		clone := event.Clone()
+		clone.Edit.Insert = "changed"
+		assert.Equal(t, "there", event.Edit.Insert)
+
+		// Exactly one of delta and edit is set
+		event.Delta = "Hello"
+		assert.Error(t, event.Validate())
+		event.Delta = ""
+		event.Edit = &TextEdit{Offset: 3}
+		assert.Error(t, event.Validate())
+		event.Edit = &TextEdit{Offset: -1, Insert: "x"}
+		assert.Error(t, event.Validate())
+		event.Edit = &TextEdit{Offset: 0, Delete: 2}
+		assert.NoError(t, event.Validate())
+	})
+
+	t.Run("TextEdit_Apply", func(t *testing.T) {
+		edited, err := TextEdit{Offset: 6, Delete: 5, Insert: "there"}.Apply("Hello world!")
+		require.NoError(t, err)
+		assert.Equal(t, "Hello there!", edited)
+
+		edited, err = TextEdit{Offset: 5, Insert: ", dear"}.Apply("Hello")
+		require.NoError(t, err)
+		assert.Equal(t, "Hello, dear", edited)
+
+		_, err = TextEdit{Offset: 4, Delete: 2}.Apply("Hello")
+		assert.Error(t, err)
+
+		// "é" is two bytes
+		_, err = TextEdit{Offset: 1, Delete: 1}.Apply("café")
+		assert.NoError(t, err)
+		_, err = TextEdit{Offset: 4, Delete: 1}.Apply("café")
+		assert.Error(t, err)
+	})
+
 	t.Run("TextMessageEndEvent", func(t *testing.T) {
 		messageID := "msg-123"
 
@@ -241,8 +463,92 @@ func TestToolEvents(t *testing.T) {
 		event.ToolCallID = ""
 		assert.Error(t, event.Validate())
 	})
+
+	t.Run("ToolCallResultEvent_Priority", func(t *testing.T) {
+		event := NewToolCallResultEvent("msg-1", "tool-1", "done", WithPriority(2))
+		require.NotNil(t, event.Priority)
+		assert.Equal(t, 2, *event.Priority)
+		assert.NoError(t, event.Validate())
+
+		jsonData, err := event.ToJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(jsonData), `"priority":2`)
+
+		// Priority is omitted when not set
+		jsonData, err = NewToolCallResultEvent("msg-1", "tool-1", "done").ToJSON()
+		require.NoError(t, err)
+		assert.NotContains(t, string(jsonData), "priority")
+	})
+
+	t.Run("ToolCallResultEvent_Cache", func(t *testing.T) {
+		event := NewToolCallResultEvent("msg-1", "tool-1", "done",
+			WithCacheKey("search:golang"), WithCacheHit(true), WithCacheTTL(90*time.Second))
+		require.NotNil(t, event.CacheKey)
+		assert.Equal(t, "search:golang", *event.CacheKey)
+		require.NotNil(t, event.CacheHit)
+		assert.True(t, *event.CacheHit)
+		require.NotNil(t, event.CacheTTLSeconds)
+		assert.Equal(t, int64(90), *event.CacheTTLSeconds)
+		assert.NoError(t, event.Validate())
+
+		jsonData, err := event.ToJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(jsonData), `"cacheKey":"search:golang","cacheHit":true,"cacheTtlSeconds":90`)
+
+		decoded, err := EventFromJSON(jsonData)
+		require.NoError(t, err)
+		assert.Equal(t, event.CacheKey, decoded.(*ToolCallResultEvent).CacheKey)
+		assert.Equal(t, event.CacheHit, decoded.(*ToolCallResultEvent).CacheHit)
+		assert.Equal(t, event.CacheTTLSeconds, decoded.(*ToolCallResultEvent).CacheTTLSeconds)
+
+		// A cache miss is sent explicitly, and partial seconds round up
+		miss := NewToolCallResultEvent("msg-1", "tool-1", "done", WithCacheHit(false), WithCacheTTL(1500*time.Millisecond))
+		jsonData, err = miss.ToJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(jsonData), `"cacheHit":false`)
+		assert.Equal(t, int64(2), *miss.CacheTTLSeconds)
+
+		// The fields are omitted when not set
+		jsonData, err = NewToolCallResultEvent("msg-1", "tool-1", "done").ToJSON()
+		require.NoError(t, err)
+		assert.NotContains(t, string(jsonData), "cache")
+
+		negative := NewToolCallResultEvent("msg-1", "tool-1", "done", WithCacheTTL(-time.Second))
+		assert.Error(t, negative.Validate())
+	})
+
+	t.Run("SortByPriority", func(t *testing.T) {
+		unsetA := NewToolCallResultEvent("msg-1", "unset-a", "a")
+		low := NewToolCallResultEvent("msg-2", "low", "b", WithPriority(5))
+		unsetB := NewToolCallResultEvent("msg-3", "unset-b", "c")
+		def := NewToolCallResultEvent("msg-4", "default", "d", WithPriority(0))
+		high := NewToolCallResultEvent("msg-5", "high", "e", WithPriority(-1))
+		lowToo := NewToolCallResultEvent("msg-6", "low-too", "f", WithPriority(5))
+
+		results := []*ToolCallResultEvent{unsetA, low, unsetB, def, high, lowToo}
+		sorted := SortByPriority(results)
+
+		ids := make([]string, len(sorted))
+		for i, result := range sorted {
+			ids[i] = result.ToolCallID
+		}
+		assert.Equal(t, []string{"high", "default", "low", "low-too", "unset-a", "unset-b"}, ids)
+
+		// The input is left in its original order
+		assert.Equal(t, "unset-a", results[0].ToolCallID)
+		assert.Empty(t, SortByPriority(nil))
+	})
+}
+
+// codedError is an error carrying a code
+type codedError struct {
+	code    string
+	message string
 }
 
+func (e codedError) Error() string { return e.message }
+func (e codedError) Code() string  { return e.code }
+
 func TestStateEvents(t *testing.T) {
 	t.Run("StateSnapshotEvent", func(t *testing.T) {
 		snapshot := map[string]any{
@@ -261,6 +567,178 @@ func TestStateEvents(t *testing.T) {
 		assert.Error(t, event.Validate())
 	})
 
+	t.Run("StateSnapshotEvent_OwnsSnapshot", func(t *testing.T) {
+		tags := []string{"admin"}
+		source := map[string]any{
+			"user":  map[string]any{"name": "Ada", "tags": tags},
+			"items": []any{map[string]any{"id": 1}},
+		}
+		event := NewStateSnapshotEvent(source)
+		before, err := event.ToJSON()
+		require.NoError(t, err)
+
+		source["user"].(map[string]any)["name"] = "Grace"
+		source["items"].([]any)[0].(map[string]any)["id"] = 2
+		source["extra"] = true
+		tags[0] = "guest"
+
+		after, err := event.ToJSON()
+		require.NoError(t, err)
+		assert.JSONEq(t, string(before), string(after))
+		assert.Equal(t, 1, event.Snapshot.(map[string]any)["items"].([]any)[0].(map[string]any)["id"])
+	})
+
+	t.Run("StateSnapshotEvent_OwnsStructSnapshot", func(t *testing.T) {
+		type state struct {
+			Tags []string `json:"tags"`
+		}
+		source := &state{Tags: []string{"admin"}}
+		event := NewStateSnapshotEvent(source)
+		source.Tags[0] = "guest"
+
+		data, err := event.ToJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"snapshot":{"tags":["admin"]}`)
+
+		// The copy keeps the type of the value
+		copied, ok := event.Snapshot.(*state)
+		require.True(t, ok, "snapshot has type %T", event.Snapshot)
+		assert.NotSame(t, source, copied)
+		assert.Equal(t, []string{"admin"}, event.Clone().Snapshot.(*state).Tags)
+
+		// Typed maps, generic values nested in structs, and cycles are
+		// copied too
+		type node struct {
+			Meta map[string]any `json:"meta"`
+			Next *node          `json:"-"`
+		}
+		counts := map[string][]int{"a": {1}}
+		looped := &node{Meta: map[string]any{"items": []any{"x"}}}
+		looped.Next = looped
+		event = NewStateSnapshotEvent(map[string]any{"counts": counts, "node": looped})
+		counts["a"][0] = 2
+		looped.Meta["items"].([]any)[0] = "y"
+
+		snapshot := event.Snapshot.(map[string]any)
+		assert.Equal(t, map[string][]int{"a": {1}}, snapshot["counts"])
+		copiedNode := snapshot["node"].(*node)
+		assert.Equal(t, []any{"x"}, copiedNode.Meta["items"])
+		assert.Same(t, copiedNode, copiedNode.Next)
+	})
+
+	t.Run("StateSnapshotEvent_WithSharedSnapshot", func(t *testing.T) {
+		source := map[string]any{"count": 1}
+		event := NewStateSnapshotEvent(source, WithSharedSnapshot())
+		source["count"] = 2
+		assert.Equal(t, 2, event.Snapshot.(map[string]any)["count"])
+	})
+
+	t.Run("StateSnapshotEvent_Paths", func(t *testing.T) {
+		event := NewStateSnapshotEvent(map[string]any{
+			"user":  map[string]any{"name": "Ada", "tags": []any{"admin"}},
+			"a/b":   1,
+			"count": 3,
+		})
+
+		value, err := event.PathGet("/user/name")
+		require.NoError(t, err)
+		assert.Equal(t, "Ada", value)
+
+		value, err = event.PathGet("/user/tags/0")
+		require.NoError(t, err)
+		assert.Equal(t, "admin", value)
+
+		value, err = event.PathGet("/a~1b")
+		require.NoError(t, err)
+		assert.Equal(t, 1, value)
+
+		_, err = event.PathGet("/user/missing")
+		assert.Error(t, err)
+		_, err = event.PathGet("user")
+		assert.Error(t, err)
+
+		// Set replaces existing values and adds new ones
+		require.NoError(t, event.PathSet("/user/name", "Grace"))
+		require.NoError(t, event.PathSet("/user/email", "grace@example.com"))
+		require.NoError(t, event.PathSet("/user/tags/-", "owner"))
+		assert.Error(t, event.PathSet("/missing/child", 1))
+
+		// Delete removes keys and array elements
+		require.NoError(t, event.PathDelete("/count"))
+		require.NoError(t, event.PathDelete("/user/tags/0"))
+		assert.Error(t, event.PathDelete("/count"))
+
+		assert.Equal(t, map[string]any{
+			"user": map[string]any{
+				"name":  "Grace",
+				"email": "grace@example.com",
+				"tags":  []any{"owner"},
+			},
+			"a/b": 1,
+		}, event.Snapshot)
+	})
+
+	t.Run("StateSnapshotEvent_ValidateSerializable", func(t *testing.T) {
+		event := NewStateSnapshotEvent(map[string]any{
+			"user":  map[string]any{"name": "Ada", "tags": []string{"admin"}},
+			"count": 3,
+		})
+		assert.NoError(t, event.ValidateSerializable())
+
+		type settings struct {
+			Theme   string      `json:"theme"`
+			Updates chan string `json:"updates"`
+		}
+		type state struct {
+			Name     string   `json:"name"`
+			Settings settings `json:"settings"`
+		}
+		event = NewStateSnapshotEvent(&state{Name: "Ada", Settings: settings{Updates: make(chan string)}})
+		assert.NoError(t, event.Validate(), "Validate does not marshal the snapshot")
+		err := event.ValidateSerializable()
+		require.Error(t, err)
+		var validationErr *ValidationError
+		require.True(t, errors.As(err, &validationErr))
+		assert.Equal(t, "snapshot", validationErr.Field)
+		assert.Contains(t, err.Error(), `"/settings/updates"`)
+		assert.Contains(t, err.Error(), "chan string")
+		var unsupported *json.UnsupportedTypeError
+		assert.True(t, errors.As(err, &unsupported))
+
+		// Values nested in generic containers are located too
+		event = NewStateSnapshotEvent(map[string]any{"items": []any{1, func() {}}})
+		err = event.ValidateSerializable()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `"/items/1"`)
+
+		// Invalid raw JSON is not mistaken for a value nested too deeply
+		event = NewStateSnapshotEvent(map[string]any{"a": json.RawMessage("{bad")})
+		assert.Error(t, event.ValidateSerializable())
+	})
+
+	t.Run("StateSnapshotEvent_PathsOnStructSnapshot", func(t *testing.T) {
+		type user struct {
+			Name string `json:"name"`
+		}
+		event := NewStateSnapshotEvent(struct {
+			User user `json:"user"`
+		}{User: user{Name: "Ada"}})
+
+		value, err := event.PathGet("/user/name")
+		require.NoError(t, err)
+		assert.Equal(t, "Ada", value)
+
+		require.NoError(t, event.PathSet("/user/age", 36))
+		assert.Equal(t, map[string]any{
+			"user": map[string]any{"name": "Ada", "age": float64(36)},
+		}, event.Snapshot)
+
+		// A nil snapshot starts as an empty object
+		empty := NewStateSnapshotEvent(nil)
+		require.NoError(t, empty.PathSet("/status", "ready"))
+		assert.Equal(t, map[string]any{"status": "ready"}, empty.Snapshot)
+	})
+
 	t.Run("StateDeltaEvent", func(t *testing.T) {
 		delta := []JSONPatchOperation{
 			{Op: "add", Path: "/counter", Value: 42},
@@ -289,6 +767,18 @@ func TestStateEvents(t *testing.T) {
 		}
 		assert.Error(t, event.Validate())
 
+		// Path that is not a JSON Pointer
+		event.Delta = []JSONPatchOperation{
+			{Op: "add", Path: "counter", Value: 42},
+		}
+		assert.Error(t, event.Validate())
+
+		// Invalid escape in path
+		event.Delta = []JSONPatchOperation{
+			{Op: "add", Path: "/a~2b", Value: 42},
+		}
+		assert.Error(t, event.Validate())
+
 		// Missing value for add operation
 		event.Delta = []JSONPatchOperation{
 			{Op: "add", Path: "/counter"},
@@ -300,6 +790,12 @@ func TestStateEvents(t *testing.T) {
 			{Op: "move", Path: "/counter"},
 		}
 		assert.Error(t, event.Validate())
+
+		// Invalid from pointer
+		event.Delta = []JSONPatchOperation{
+			{Op: "copy", Path: "/counter", From: "status"},
+		}
+		assert.Error(t, event.Validate())
 	})
 
 	t.Run("MessagesSnapshotEvent", func(t *testing.T) {
@@ -356,6 +852,101 @@ func TestStateEvents(t *testing.T) {
 		event.Messages = invalidMessages
 		assert.Error(t, event.Validate())
 	})
+
+	t.Run("MessageRoles", func(t *testing.T) {
+		system := Message{ID: "msg-1", Role: RoleSystem, Content: strPtr("You are helpful")}
+		assert.NoError(t, system.Validate())
+
+		developer := Message{ID: "msg-2", Role: RoleDeveloper, Content: strPtr("Use tools sparingly")}
+		assert.NoError(t, developer.Validate())
+
+		tool := Message{ID: "msg-3", Role: RoleTool, Content: strPtr("72F")}
+		err := tool.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "toolCallId")
+
+		tool.ToolCallID = strPtr("tool-1")
+		assert.NoError(t, tool.Validate())
+
+		missingContent := Message{ID: "msg-4", Role: RoleSystem}
+		assert.Error(t, missingContent.Validate())
+
+		unknownRole := Message{ID: "msg-5", Role: "narrator", Content: strPtr("Once upon a time")}
+		assert.Error(t, unknownRole.Validate())
+
+		event := NewMessagesSnapshotEvent([]Message{system, {ID: "msg-6", Role: RoleTool, Content: strPtr("72F")}})
+		err = event.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "index 1")
+	})
+
+	t.Run("ValidateCollect", func(t *testing.T) {
+		event := NewMessagesSnapshotEvent([]Message{
+			{ID: "msg-1", Role: RoleUser, Content: strPtr("Hello")},
+			{ID: "msg-2", Role: RoleSystem},
+			{ID: "msg-3", Role: RoleAssistant, Content: strPtr("Hi")},
+			{ID: "msg-4", Role: "narrator", Content: strPtr("Once upon a time")},
+		})
+
+		errs := event.ValidateCollect()
+		require.Len(t, errs, 2)
+		assert.Contains(t, errs[0].Error(), "index 1")
+		assert.Contains(t, errs[0].Error(), "content field is required")
+		assert.Contains(t, errs[1].Error(), "index 3")
+		assert.Contains(t, errs[1].Error(), "role must be one of")
+
+		// Validate stops at the first problem
+		err := event.Validate()
+		require.Error(t, err)
+		assert.Equal(t, errs[0].Error(), err.Error())
+
+		valid := NewMessagesSnapshotEvent([]Message{{ID: "msg-1", Role: RoleUser, Content: strPtr("Hello")}})
+		assert.Nil(t, valid.ValidateCollect())
+
+		delta := NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "add", Path: "/a", Value: 1},
+			{Op: "invalid", Path: "/b"},
+			{Op: "add", Path: "c", Value: 2},
+		})
+		errs = delta.ValidateCollect()
+		require.Len(t, errs, 2)
+		assert.Contains(t, errs[0].Error(), "index 1")
+		assert.Contains(t, errs[1].Error(), "index 2")
+		assert.Len(t, NewStateDeltaEvent(nil).ValidateCollect(), 1)
+	})
+
+	t.Run("FilterByRole", func(t *testing.T) {
+		messages := []Message{
+			{ID: "msg-1", Role: RoleSystem, Content: strPtr("You are helpful")},
+			{ID: "msg-2", Role: RoleUser, Content: strPtr("Weather in SF?")},
+			{ID: "msg-3", Role: RoleAssistant, ToolCalls: []ToolCall{
+				{ID: "tool-1", Type: "function", Function: Function{Name: "get_weather", Arguments: "{}"}},
+			}},
+			{ID: "msg-4", Role: RoleTool, Content: strPtr("72F"), ToolCallID: strPtr("tool-1")},
+			{ID: "msg-5", Role: RoleAssistant, Content: strPtr("It is 72F")},
+			{ID: "msg-6", Role: RoleDeveloper, Content: strPtr("Be brief")},
+		}
+
+		filtered := FilterMessages(messages, RoleUser, RoleAssistant)
+		ids := make([]string, len(filtered))
+		for i, msg := range filtered {
+			ids[i] = msg.ID
+		}
+		assert.Equal(t, []string{"msg-2", "msg-3", "msg-5"}, ids)
+		assert.Len(t, messages, 6)
+		assert.Empty(t, FilterMessages(messages))
+
+		event := NewMessagesSnapshotEvent(messages)
+		visible := event.FilterByRole(RoleUser, RoleAssistant)
+		assert.Equal(t, filtered, visible.Messages)
+		assert.Equal(t, event.Timestamp(), visible.Timestamp())
+		assert.NoError(t, visible.Validate())
+
+		// The original event is left untouched
+		assert.Len(t, event.Messages, 6)
+		*visible.Messages[0].Content = "changed"
+		assert.Equal(t, "Weather in SF?", *event.Messages[1].Content)
+	})
 }
 
 func TestCustomEvents(t *testing.T) {
@@ -390,6 +981,31 @@ func TestCustomEvents(t *testing.T) {
 		event.Name = ""
 		assert.Error(t, event.Validate())
 	})
+
+	t.Run("CustomEventNamespaces", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			namespace string
+			localName string
+		}{
+			{"progress", "", "progress"},
+			{"acme/progress", "acme", "progress"},
+			{"acme/tools/progress", "acme/tools", "progress"},
+		}
+
+		for _, tt := range tests {
+			event := NewCustomEvent(tt.name)
+			assert.NoError(t, event.Validate(), tt.name)
+			assert.Equal(t, tt.namespace, event.Namespace(), tt.name)
+			assert.Equal(t, tt.localName, event.LocalName(), tt.name)
+		}
+
+		for _, name := range []string{"/progress", "acme/", "acme//progress", "/"} {
+			err := NewCustomEvent(name).Validate()
+			require.Error(t, err, name)
+			assert.Contains(t, err.Error(), "empty segment")
+		}
+	})
 }
 
 func TestEventSequenceValidation(t *testing.T) {
@@ -405,7 +1021,8 @@ func TestEventSequenceValidation(t *testing.T) {
 			NewRunFinishedEvent("thread-1", "run-1"),
 		}
 
-		assert.NoError(t, ValidateSequence(events))
+		_, err := ValidateSequence(events, DefaultProfile())
+		assert.NoError(t, err)
 	})
 
 	t.Run("InvalidSequence_DuplicateRunStart", func(t *testing.T) {
@@ -414,7 +1031,8 @@ func TestEventSequenceValidation(t *testing.T) {
 			NewRunStartedEvent("thread-1", "run-1"), // Duplicate
 		}
 
-		assert.Error(t, ValidateSequence(events))
+		_, err := ValidateSequence(events, DefaultProfile())
+		assert.Error(t, err)
 	})
 
 	t.Run("InvalidSequence_FinishNonExistentRun", func(t *testing.T) {
@@ -422,7 +1040,8 @@ func TestEventSequenceValidation(t *testing.T) {
 			NewRunFinishedEvent("thread-1", "run-1"), // Not started
 		}
 
-		assert.Error(t, ValidateSequence(events))
+		_, err := ValidateSequence(events, DefaultProfile())
+		assert.Error(t, err)
 	})
 
 	t.Run("InvalidSequence_RestartFinishedRun", func(t *testing.T) {
@@ -432,7 +1051,8 @@ func TestEventSequenceValidation(t *testing.T) {
 			NewRunStartedEvent("thread-1", "run-1"), // Cannot restart
 		}
 
-		assert.Error(t, ValidateSequence(events))
+		_, err := ValidateSequence(events, DefaultProfile())
+		assert.Error(t, err)
 	})
 
 	t.Run("InvalidSequence_DuplicateMessageStart", func(t *testing.T) {
@@ -441,7 +1061,8 @@ func TestEventSequenceValidation(t *testing.T) {
 			NewTextMessageStartEvent("msg-1"), // Duplicate
 		}
 
-		assert.Error(t, ValidateSequence(events))
+		_, err := ValidateSequence(events, DefaultProfile())
+		assert.Error(t, err)
 	})
 
 	t.Run("InvalidSequence_EndNonExistentMessage", func(t *testing.T) {
@@ -449,7 +1070,8 @@ func TestEventSequenceValidation(t *testing.T) {
 			NewTextMessageEndEvent("msg-1"), // Not started
 		}
 
-		assert.Error(t, ValidateSequence(events))
+		_, err := ValidateSequence(events, DefaultProfile())
+		assert.Error(t, err)
 	})
 
 	t.Run("InvalidSequence_DuplicateToolCallStart", func(t *testing.T) {
@@ -458,7 +1080,8 @@ func TestEventSequenceValidation(t *testing.T) {
 			NewToolCallStartEvent("tool-1", "get_weather"), // Duplicate
 		}
 
-		assert.Error(t, ValidateSequence(events))
+		_, err := ValidateSequence(events, DefaultProfile())
+		assert.Error(t, err)
 	})
 
 	t.Run("InvalidSequence_EndNonExistentToolCall", func(t *testing.T) {
@@ -466,7 +1089,8 @@ func TestEventSequenceValidation(t *testing.T) {
 			NewToolCallEndEvent("tool-1"), // Not started
 		}
 
-		assert.Error(t, ValidateSequence(events))
+		_, err := ValidateSequence(events, DefaultProfile())
+		assert.Error(t, err)
 	})
 }
 
@@ -511,9 +1135,254 @@ func TestJSONSerialization(t *testing.T) {
 		_, err := EventFromJSON(invalidJSON)
 		assert.Error(t, err)
 	})
+
+	t.Run("WithoutTimestamp", func(t *testing.T) {
+		for _, event := range copyableEvents() {
+			event.SetTimestamp(1234567890)
+
+			jsonData, err := ToJSONWithoutTimestamp(event)
+			require.NoError(t, err, event.Type())
+
+			var decoded map[string]any
+			require.NoError(t, json.Unmarshal(jsonData, &decoded))
+			assert.NotContains(t, decoded, "timestamp", event.Type())
+			assert.Equal(t, string(event.Type()), decoded["type"])
+
+			// All other fields are preserved
+			withTimestamp, err := event.ToJSON()
+			require.NoError(t, err)
+			var expected map[string]any
+			require.NoError(t, json.Unmarshal(withTimestamp, &expected))
+			delete(expected, "timestamp")
+			assert.Equal(t, expected, decoded, event.Type())
+
+			// The event keeps its timestamp
+			require.NotNil(t, event.Timestamp())
+			assert.Equal(t, int64(1234567890), *event.Timestamp())
+		}
+
+		// Output is deterministic across events created at different times
+		first, err := ToJSONWithoutTimestamp(NewRunStartedEvent("thread-1", "run-1"))
+		require.NoError(t, err)
+		second := NewRunStartedEvent("thread-1", "run-1")
+		second.SetTimestamp(1)
+		secondData, err := ToJSONWithoutTimestamp(second)
+		require.NoError(t, err)
+		assert.Equal(t, string(first), string(secondData))
+	})
+}
+
+func TestEventString(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    Event
+		expected string
+	}{
+		{
+			name:     "RunStarted",
+			event:    NewRunStartedEvent("t", "r"),
+			expected: "RUN_STARTED(thread=t run=r)",
+		},
+		{
+			name:     "TextMessageContent",
+			event:    NewTextMessageContentEvent("m", "Hello"),
+			expected: `TEXT_MESSAGE_CONTENT(msg=m delta="Hello")`,
+		},
+		{
+			name:     "TextMessageContentTruncated",
+			event:    NewTextMessageContentEvent("m", strings.Repeat("a", 40)),
+			expected: `TEXT_MESSAGE_CONTENT(msg=m delta="` + strings.Repeat("a", 32) + `...")`,
+		},
+		{
+			name:     "ToolCallStartWithParent",
+			event:    NewToolCallStartEvent("tc", "search", WithParentMessageID("m")),
+			expected: "TOOL_CALL_START(tool=tc name=search parent=m)",
+		},
+		{
+			name:     "RunErrorWithCode",
+			event:    NewRunErrorEvent("boom", WithErrorCode("E1"), WithRunID("r")),
+			expected: `RUN_ERROR(run=r code=E1 message="boom")`,
+		},
+		{
+			name:     "StateDelta",
+			event:    NewStateDeltaEvent([]JSONPatchOperation{{Op: "add", Path: "/a", Value: 1}}),
+			expected: "STATE_DELTA(ops=1)",
+		},
+		{
+			name:     "ThinkingEnd",
+			event:    NewThinkingEndEvent(),
+			expected: "THINKING_END()",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, fmt.Sprint(tt.event))
+		})
+	}
+
+	t.Run("TruncatesOnRuneBoundary", func(t *testing.T) {
+		event := NewTextMessageContentEvent("m", strings.Repeat("é", 40))
+		assert.Equal(t, `TEXT_MESSAGE_CONTENT(msg=m delta="`+strings.Repeat("é", 32)+`...")`, event.String())
+	})
 }
 
 // Helper function to create string pointers
 func strPtr(s string) *string {
 	return &s
 }
+
+func TestSortEvents(t *testing.T) {
+	decode := func(t *testing.T, data string) Event {
+		t.Helper()
+		event, err := EventFromJSON([]byte(data))
+		require.NoError(t, err)
+		return event
+	}
+
+	t.Run("DuplicateTimestampsUseDecodeOrder", func(t *testing.T) {
+		// Events from two sources, decoded in arrival order
+		first := decode(t, `{"type":"TEXT_MESSAGE_CONTENT","messageId":"a","delta":"1","timestamp":100}`)
+		second := decode(t, `{"type":"TEXT_MESSAGE_CONTENT","messageId":"b","delta":"1","timestamp":100}`)
+		third := decode(t, `{"type":"TEXT_MESSAGE_CONTENT","messageId":"a","delta":"2","timestamp":100}`)
+		earlier := decode(t, `{"type":"TEXT_MESSAGE_CONTENT","messageId":"b","delta":"0","timestamp":50}`)
+
+		assert.Less(t, first.GetBaseEvent().Sequence(), second.GetBaseEvent().Sequence())
+		assert.Less(t, second.GetBaseEvent().Sequence(), third.GetBaseEvent().Sequence())
+
+		merged := []Event{third, first, earlier, second}
+		SortEvents(merged)
+		assert.Equal(t, []Event{earlier, first, second, third}, merged)
+	})
+
+	t.Run("StableForUndecodedEvents", func(t *testing.T) {
+		a := NewTextMessageContentEvent("msg-1", "a")
+		b := NewTextMessageContentEvent("msg-1", "b")
+		c := NewTextMessageContentEvent("msg-1", "c")
+		for _, event := range []Event{a, b, c} {
+			event.GetBaseEvent().SetTimestamp(100)
+			assert.Zero(t, event.GetBaseEvent().Sequence())
+		}
+
+		events := []Event{c, a, b}
+		SortEvents(events)
+		assert.Equal(t, []Event{c, a, b}, events)
+	})
+
+	t.Run("MissingTimestampsSortLast", func(t *testing.T) {
+		untimed := NewStepStartedEvent("plan")
+		untimed.TimestampMs = nil
+		timed := NewStepFinishedEvent("plan")
+		timed.SetTimestamp(1)
+
+		events := []Event{untimed, timed}
+		SortEvents(events)
+		assert.Equal(t, []Event{timed, untimed}, events)
+	})
+}
+
+func TestBatchValidate(t *testing.T) {
+	t.Run("AllValid", func(t *testing.T) {
+		results := BatchValidate([]Event{
+			NewRunStartedEvent("thread-1", "run-1"),
+			NewTextMessageContentEvent("msg-1", "Hello"),
+		})
+
+		require.Len(t, results, 2)
+		for i, result := range results {
+			assert.Equal(t, i, result.Index)
+			assert.NoError(t, result.Err)
+		}
+		assert.False(t, HasValidationErrors(results))
+		assert.NoError(t, FirstValidationError(results))
+	})
+
+	t.Run("CollectsAllErrors", func(t *testing.T) {
+		invalidContent := NewTextMessageContentEvent("msg-1", "")
+		invalidStart := NewToolCallStartEvent("tool-1", "")
+		events := []Event{
+			NewRunStartedEvent("thread-1", "run-1"),
+			invalidContent,
+			nil,
+			invalidStart,
+		}
+
+		results := BatchValidate(events)
+		require.Len(t, results, 4)
+		assert.NoError(t, results[0].Err)
+		assert.Same(t, invalidContent, results[1].Event)
+		require.Error(t, results[1].Err)
+		assert.Contains(t, results[1].Err.Error(), "delta field must not be empty")
+		assert.EqualError(t, results[2].Err, "event at index 2 is nil")
+		require.Error(t, results[3].Err)
+		assert.Contains(t, results[3].Err.Error(), "toolCallName field is required")
+
+		assert.True(t, HasValidationErrors(results))
+		assert.Equal(t, results[1].Err, FirstValidationError(results))
+	})
+
+	t.Run("EmptyBatch", func(t *testing.T) {
+		results := BatchValidate(nil)
+		assert.Empty(t, results)
+		assert.False(t, HasValidationErrors(results))
+	})
+}
+
+func TestNewEvent(t *testing.T) {
+	t.Run("InverseOfToJSON", func(t *testing.T) {
+		for _, event := range copyableEvents() {
+			data, err := event.ToJSON()
+			require.NoError(t, err)
+
+			decoded, err := NewEvent(event.Type(), data)
+			require.NoError(t, err, event.Type())
+			assert.Positive(t, decoded.GetBaseEvent().Sequence())
+			clearSequence(decoded)
+			assert.Equal(t, event, decoded, event.Type())
+		}
+	})
+
+	t.Run("PayloadlessTypes", func(t *testing.T) {
+		for _, eventType := range []EventType{EventTypeThinkingEnd, EventTypeThinkingTextMessageStart, EventTypeThinkingTextMessageEnd} {
+			for _, data := range []string{"", "{}", " "} {
+				event, err := NewEvent(eventType, []byte(data))
+				require.NoError(t, err, "%s %q", eventType, data)
+				assert.Equal(t, eventType, event.Type())
+				assert.Nil(t, event.Timestamp())
+				assert.NoError(t, event.Validate())
+			}
+		}
+
+		_, err := NewEvent(EventTypeRunStarted, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "data is required")
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := NewEvent("NOT_A_TYPE", []byte(`{}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown event type")
+
+		_, err = NewEvent(EventTypeStepStarted, []byte(`{"type": "STEP_FINISHED", "stepName": "plan"}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "expected STEP_STARTED")
+
+		_, err = NewEvent(EventTypeStepStarted, []byte(`{"stepName": 1}`))
+		var decodeErr *DecodeError
+		require.ErrorAs(t, err, &decodeErr)
+		assert.Equal(t, EventTypeStepStarted, decodeErr.EventType)
+		assert.Equal(t, "stepName", decodeErr.Field)
+	})
+
+	t.Run("EventFromJSONSupportsAllTypes", func(t *testing.T) {
+		for _, event := range copyableEvents() {
+			data, err := event.ToJSON()
+			require.NoError(t, err)
+
+			decoded, err := EventFromJSON(data)
+			require.NoError(t, err, event.Type())
+			clearSequence(decoded)
+			assert.Equal(t, event, decoded, event.Type())
+		}
+	})
+}
@@ -0,0 +1,168 @@
+package events
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// FieldCase is the casing of the field names in event payloads
+type FieldCase int
+
+const (
+	// FieldCaseCamel is the camelCase of the protocol, as in "messageId"
+	FieldCaseCamel FieldCase = iota
+
+	// FieldCaseSnake is snake_case, as in "message_id"
+	FieldCaseSnake
+)
+
+// WithFieldCase sets the casing of the field names in the payloads the
+// decoder receives. With FieldCaseSnake, snake_case field names are renamed
+// to their camelCase form before decoding, for servers that do not follow
+// the protocol's casing. Only the names of the fields the SDK defines are
+// renamed: the keys of free-form values, such as state snapshots, custom
+// event values and raw events, are left as they are. The default is
+// FieldCaseCamel.
+func WithFieldCase(c FieldCase) EventDecoderOption {
+	return func(ed *EventDecoder) {
+		ed.fieldCase = c
+	}
+}
+
+// camelCaseFields renames the snake_case field names of an event payload of
+// the given type to camelCase
+func camelCaseFields(eventType EventType, data []byte) ([]byte, error) {
+	event := newEventOfType(eventType)
+	if event == nil {
+		return data, nil
+	}
+
+	var payload any
+	if err := unmarshalUseNumber(data, &payload); err != nil {
+		return nil, err
+	}
+	if !renameSnakeFields(payload, reflect.TypeOf(event)) {
+		return data, nil
+	}
+	return json.Marshal(payload)
+}
+
+// renameSnakeFields renames, in place, the snake_case keys of a decoded value
+// that name fields of the Go type t, recursing into the values of struct,
+// slice and map fields. It reports whether any key was renamed.
+func renameSnakeFields(value any, t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	renamed := false
+	switch v := value.(type) {
+	case map[string]any:
+		switch t.Kind() {
+		case reflect.Struct:
+			fields := jsonFieldTypes(t)
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			for _, key := range keys {
+				member := v[key]
+				name := key
+				if strings.Contains(key, "_") {
+					camel := snakeToCamel(key)
+					if _, known := fields[camel]; known {
+						if _, taken := v[camel]; !taken {
+							delete(v, key)
+							v[camel] = member
+							name = camel
+							renamed = true
+						}
+					}
+				}
+				if fieldType, ok := fields[name]; ok {
+					renamed = renameSnakeFields(member, fieldType) || renamed
+				}
+			}
+		case reflect.Map:
+			for _, member := range v {
+				renamed = renameSnakeFields(member, t.Elem()) || renamed
+			}
+		}
+	case []any:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for _, item := range v {
+				renamed = renameSnakeFields(item, t.Elem()) || renamed
+			}
+		}
+	}
+	return renamed
+}
+
+// jsonFieldCache maps struct types to their JSON field types by name
+var jsonFieldCache sync.Map
+
+// jsonFieldTypes returns the types of the JSON fields of a struct type by
+// name, including the fields of embedded structs
+func jsonFieldTypes(t reflect.Type) map[string]reflect.Type {
+	if cached, ok := jsonFieldCache.Load(t); ok {
+		return cached.(map[string]reflect.Type)
+	}
+
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		fieldType := field.Type
+		if field.Anonymous && name == "" {
+			for fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				for embeddedName, embeddedType := range jsonFieldTypes(fieldType) {
+					if _, ok := fields[embeddedName]; !ok {
+						fields[embeddedName] = embeddedType
+					}
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = fieldType
+	}
+
+	jsonFieldCache.Store(t, fields)
+	return fields
+}
+
+// snakeToCamel converts a snake_case name to camelCase
+func snakeToCamel(name string) string {
+	var b strings.Builder
+	b.Grow(len(name))
+	upper := false
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '_' && b.Len() > 0:
+			upper = true
+		case upper && c >= 'a' && c <= 'z':
+			b.WriteByte(c - 'a' + 'A')
+			upper = false
+		default:
+			b.WriteByte(c)
+			upper = false
+		}
+	}
+	return b.String()
+}
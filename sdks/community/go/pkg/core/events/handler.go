@@ -0,0 +1,112 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// EventHandler processes events delivered through an event pipeline
+type EventHandler interface {
+	// HandleEvent processes a single event
+	HandleEvent(ctx context.Context, event Event) error
+}
+
+// EventHandlerFunc adapts an ordinary function to the EventHandler interface
+type EventHandlerFunc func(ctx context.Context, event Event) error
+
+// HandleEvent calls f(ctx, event)
+func (f EventHandlerFunc) HandleEvent(ctx context.Context, event Event) error {
+	return f(ctx, event)
+}
+
+// EventMiddleware wraps an event handler to add behavior around it, such as
+// retries or failure isolation. It is the common shape of the middlewares of
+// this package: NewCircuitBreakerMiddleware, NewDeduplicatingMiddleware,
+// NewEncryptingMiddleware, CorrelationIDMiddleware, InterceptorChain and
+// ZerologEventMiddleware return one, and ToolCallTimeoutMiddleware and
+// ToolCallLatencyMiddleware provide one with their Middleware method.
+// Middlewares that transform a stream rather than single events are also
+// available as an EventStage, and those that only rewrite events as a
+// post-decode hook.
+type EventMiddleware func(next EventHandler) EventHandler
+
+// Chain composes handlers into a single handler that calls each of them in
+// order, stopping at the first error
+func Chain(handlers ...EventHandler) EventHandler {
+	return EventHandlerFunc(func(ctx context.Context, event Event) error {
+		for i, handler := range handlers {
+			if err := handler.HandleEvent(ctx, event); err != nil {
+				return &HandlerError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
+}
+
+// ChainAll composes handlers into a single handler that calls every handler
+// in order, even when earlier handlers fail. If any handler fails, a
+// *ChainedEventHandlerError describing every failure is returned.
+func ChainAll(handlers ...EventHandler) EventHandler {
+	return EventHandlerFunc(func(ctx context.Context, event Event) error {
+		var errs []HandlerError
+		for i, handler := range handlers {
+			if err := handler.HandleEvent(ctx, event); err != nil {
+				errs = append(errs, HandlerError{Index: i, Err: err})
+			}
+		}
+
+		if len(errs) > 0 {
+			return &ChainedEventHandlerError{Errors: errs}
+		}
+		return nil
+	})
+}
+
+// HandlerError records the failure of a single handler within a chain
+type HandlerError struct {
+	// Index is the position of the failing handler in the chain
+	Index int
+
+	// Err is the error returned by the handler
+	Err error
+}
+
+// Error implements the error interface
+func (e *HandlerError) Error() string {
+	return fmt.Sprintf("handler %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the error returned by the handler
+func (e *HandlerError) Unwrap() error {
+	return e.Err
+}
+
+// ChainedEventHandlerError collects the errors of every failing handler in a
+// chain built with ChainAll
+type ChainedEventHandlerError struct {
+	Errors []HandlerError
+}
+
+// Error implements the error interface
+func (e *ChainedEventHandlerError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+
+	msgs := make([]string, len(e.Errors))
+	for i := range e.Errors {
+		msgs[i] = e.Errors[i].Error()
+	}
+	return fmt.Sprintf("%d handlers failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors of all failing handlers so that errors.Is and
+// errors.As match any of them
+func (e *ChainedEventHandlerError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i := range e.Errors {
+		errs[i] = &e.Errors[i]
+	}
+	return errs
+}
@@ -0,0 +1,72 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerChains(t *testing.T) {
+	errPersist := errors.New("persist failed")
+	errForward := errors.New("forward failed")
+
+	recording := func(calls *[]string, name string, err error) EventHandler {
+		return EventHandlerFunc(func(ctx context.Context, event Event) error {
+			*calls = append(*calls, name)
+			return err
+		})
+	}
+
+	event := NewRunStartedEvent("thread-1", "run-1")
+
+	t.Run("ChainStopsAtFirstError", func(t *testing.T) {
+		var calls []string
+		handler := Chain(
+			recording(&calls, "log", nil),
+			recording(&calls, "persist", errPersist),
+			recording(&calls, "forward", errForward),
+		)
+
+		err := handler.HandleEvent(context.Background(), event)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, errPersist)
+		assert.Equal(t, []string{"log", "persist"}, calls)
+
+		var handlerErr *HandlerError
+		require.ErrorAs(t, err, &handlerErr)
+		assert.Equal(t, 1, handlerErr.Index)
+	})
+
+	t.Run("ChainAllRunsEveryHandler", func(t *testing.T) {
+		var calls []string
+		handler := ChainAll(
+			recording(&calls, "log", nil),
+			recording(&calls, "persist", errPersist),
+			recording(&calls, "forward", errForward),
+		)
+
+		err := handler.HandleEvent(context.Background(), event)
+		require.Error(t, err)
+		assert.Equal(t, []string{"log", "persist", "forward"}, calls)
+
+		var chainErr *ChainedEventHandlerError
+		require.ErrorAs(t, err, &chainErr)
+		require.Len(t, chainErr.Errors, 2)
+		assert.Equal(t, 1, chainErr.Errors[0].Index)
+		assert.Equal(t, 2, chainErr.Errors[1].Index)
+		assert.ErrorIs(t, err, errPersist)
+		assert.ErrorIs(t, err, errForward)
+		assert.Contains(t, err.Error(), "2 handlers failed")
+	})
+
+	t.Run("ChainAllSucceeds", func(t *testing.T) {
+		var calls []string
+		handler := ChainAll(recording(&calls, "log", nil), recording(&calls, "persist", nil))
+
+		assert.NoError(t, handler.HandleEvent(context.Background(), event))
+		assert.Equal(t, []string{"log", "persist"}, calls)
+	})
+}
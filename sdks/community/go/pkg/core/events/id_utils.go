@@ -1,13 +1,17 @@
 package events
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-// IDGenerator provides methods for generating unique event IDs
+// IDGenerator provides methods for generating unique event IDs.
+// Implementations must be safe for concurrent use.
 type IDGenerator interface {
 	// GenerateRunID generates a unique run ID
 	GenerateRunID() string
@@ -25,7 +29,8 @@ type IDGenerator interface {
 	GenerateStepID() string
 }
 
-// DefaultIDGenerator implements IDGenerator using UUID v4
+// DefaultIDGenerator implements IDGenerator using UUID v4, whose 122 bits
+// come from crypto/rand
 type DefaultIDGenerator struct{}
 
 // NewDefaultIDGenerator creates a new default ID generator
@@ -58,7 +63,91 @@ func (g *DefaultIDGenerator) GenerateStepID() string {
 	return fmt.Sprintf("step-%s", uuid.New().String())
 }
 
-// TimestampIDGenerator implements IDGenerator using timestamps and short UUIDs
+// UUIDv4Generator implements IDGenerator with bare UUID v4 strings, without
+// the type prefixes of DefaultIDGenerator, for systems that require IDs to
+// be UUIDs
+type UUIDv4Generator struct{}
+
+// NewUUIDv4Generator creates a new UUID v4 ID generator
+func NewUUIDv4Generator() *UUIDv4Generator {
+	return &UUIDv4Generator{}
+}
+
+// GenerateRunID generates a UUID v4 run ID
+func (g *UUIDv4Generator) GenerateRunID() string {
+	return uuid.New().String()
+}
+
+// GenerateMessageID generates a UUID v4 message ID
+func (g *UUIDv4Generator) GenerateMessageID() string {
+	return uuid.New().String()
+}
+
+// GenerateToolCallID generates a UUID v4 tool call ID
+func (g *UUIDv4Generator) GenerateToolCallID() string {
+	return uuid.New().String()
+}
+
+// GenerateThreadID generates a UUID v4 thread ID
+func (g *UUIDv4Generator) GenerateThreadID() string {
+	return uuid.New().String()
+}
+
+// GenerateStepID generates a UUID v4 step ID
+func (g *UUIDv4Generator) GenerateStepID() string {
+	return uuid.New().String()
+}
+
+// UUIDv7Generator implements IDGenerator with bare UUID v7 strings, which
+// start with a millisecond timestamp and are monotonic within a process, so
+// that IDs sort lexically by creation time
+type UUIDv7Generator struct{}
+
+// NewUUIDv7Generator creates a new UUID v7 ID generator
+func NewUUIDv7Generator() *UUIDv7Generator {
+	return &UUIDv7Generator{}
+}
+
+// GenerateRunID generates a UUID v7 run ID
+func (g *UUIDv7Generator) GenerateRunID() string {
+	return newUUIDv7()
+}
+
+// GenerateMessageID generates a UUID v7 message ID
+func (g *UUIDv7Generator) GenerateMessageID() string {
+	return newUUIDv7()
+}
+
+// GenerateToolCallID generates a UUID v7 tool call ID
+func (g *UUIDv7Generator) GenerateToolCallID() string {
+	return newUUIDv7()
+}
+
+// GenerateThreadID generates a UUID v7 thread ID
+func (g *UUIDv7Generator) GenerateThreadID() string {
+	return newUUIDv7()
+}
+
+// GenerateStepID generates a UUID v7 step ID
+func (g *UUIDv7Generator) GenerateStepID() string {
+	return newUUIDv7()
+}
+
+// newUUIDv7 returns a UUID v7 string
+func newUUIDv7() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		// NewV7 only fails if crypto/rand does; fall back to a UUID v4,
+		// which is unique but not time-sortable
+		return uuid.New().String()
+	}
+	return id.String()
+}
+
+// TimestampIDGenerator implements IDGenerator using millisecond timestamps
+// followed by a 64-bit random suffix from crypto/rand, so that IDs sort
+// roughly by creation time and do not collide even when many are generated
+// in the same millisecond
 type TimestampIDGenerator struct {
 	prefix string
 }
@@ -96,24 +185,44 @@ func (g *TimestampIDGenerator) GenerateStepID() string {
 // generateTimestampID generates a timestamp-based ID with the given type prefix
 func (g *TimestampIDGenerator) generateTimestampID(typePrefix string) string {
 	timestamp := time.Now().UnixMilli()
-	shortUUID := uuid.New().String()[:8]
+	suffix := randomSuffix()
 
 	if g.prefix != "" {
-		return fmt.Sprintf("%s-%s-%d-%s", g.prefix, typePrefix, timestamp, shortUUID)
+		return fmt.Sprintf("%s-%s-%d-%s", g.prefix, typePrefix, timestamp, suffix)
 	}
-	return fmt.Sprintf("%s-%d-%s", typePrefix, timestamp, shortUUID)
+	return fmt.Sprintf("%s-%d-%s", typePrefix, timestamp, suffix)
 }
 
-// Global default ID generator instance
-var defaultIDGenerator IDGenerator = NewDefaultIDGenerator()
+// randomSuffix returns 64 random bits from crypto/rand, hex-encoded
+func randomSuffix() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand does not fail on supported platforms; fall back to a
+		// UUID, which has its own source of randomness
+		return uuid.New().String()[:16]
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// Global default ID generator instance, guarded by defaultIDGeneratorMu so
+// that it can be replaced while IDs are being generated
+var (
+	defaultIDGeneratorMu sync.RWMutex
+	defaultIDGenerator   IDGenerator = NewDefaultIDGenerator()
+)
 
-// SetDefaultIDGenerator sets the global default ID generator
+// SetDefaultIDGenerator sets the global default ID generator. It is safe to
+// call concurrently with the Generate functions.
 func SetDefaultIDGenerator(generator IDGenerator) {
+	defaultIDGeneratorMu.Lock()
+	defer defaultIDGeneratorMu.Unlock()
 	defaultIDGenerator = generator
 }
 
 // GetDefaultIDGenerator returns the current default ID generator
 func GetDefaultIDGenerator() IDGenerator {
+	defaultIDGeneratorMu.RLock()
+	defer defaultIDGeneratorMu.RUnlock()
 	return defaultIDGenerator
 }
 
@@ -121,25 +230,25 @@ func GetDefaultIDGenerator() IDGenerator {
 
 // GenerateRunID generates a unique run ID using the default generator
 func GenerateRunID() string {
-	return defaultIDGenerator.GenerateRunID()
+	return GetDefaultIDGenerator().GenerateRunID()
 }
 
 // GenerateMessageID generates a unique message ID using the default generator
 func GenerateMessageID() string {
-	return defaultIDGenerator.GenerateMessageID()
+	return GetDefaultIDGenerator().GenerateMessageID()
 }
 
 // GenerateToolCallID generates a unique tool call ID using the default generator
 func GenerateToolCallID() string {
-	return defaultIDGenerator.GenerateToolCallID()
+	return GetDefaultIDGenerator().GenerateToolCallID()
 }
 
 // GenerateThreadID generates a unique thread ID using the default generator
 func GenerateThreadID() string {
-	return defaultIDGenerator.GenerateThreadID()
+	return GetDefaultIDGenerator().GenerateThreadID()
 }
 
 // GenerateStepID generates a unique step ID using the default generator
 func GenerateStepID() string {
-	return defaultIDGenerator.GenerateStepID()
+	return GetDefaultIDGenerator().GenerateStepID()
 }
@@ -2,9 +2,11 @@ package events
 
 import (
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -310,4 +312,126 @@ func TestGlobalIDGenerator(t *testing.T) {
 
 		assert.Equal(t, 100, len(ids))
 	})
-}
\ No newline at end of file
+}
+
+func TestUUIDGenerators(t *testing.T) {
+	generators := map[string]struct {
+		gen     IDGenerator
+		version uuid.Version
+	}{
+		"UUIDv4Generator": {NewUUIDv4Generator(), 4},
+		"UUIDv7Generator": {NewUUIDv7Generator(), 7},
+	}
+
+	for name, tt := range generators {
+		t.Run(name, func(t *testing.T) {
+			for _, generate := range []func() string{
+				tt.gen.GenerateRunID,
+				tt.gen.GenerateThreadID,
+				tt.gen.GenerateMessageID,
+				tt.gen.GenerateToolCallID,
+				tt.gen.GenerateStepID,
+			} {
+				id, err := uuid.Parse(generate())
+				require.NoError(t, err)
+				assert.Equal(t, tt.version, id.Version())
+			}
+		})
+	}
+
+	t.Run("UUIDv7_Ordering", func(t *testing.T) {
+		gen := NewUUIDv7Generator()
+		previous := gen.GenerateMessageID()
+		for i := 0; i < 1000; i++ {
+			id := gen.GenerateMessageID()
+			require.Greater(t, id, previous)
+			previous = id
+		}
+	})
+
+	t.Run("AutoIDOptions", func(t *testing.T) {
+		original := GetDefaultIDGenerator()
+		defer SetDefaultIDGenerator(original)
+
+		SetDefaultIDGenerator(NewUUIDv7Generator())
+		start := NewTextMessageStartEvent("", WithAutoMessageID())
+		id, err := uuid.Parse(start.MessageID)
+		require.NoError(t, err)
+		assert.Equal(t, uuid.Version(7), id.Version())
+
+		toolCall := NewToolCallStartEvent("", "search", WithAutoToolCallID())
+		_, err = uuid.Parse(toolCall.ToolCallID)
+		assert.NoError(t, err)
+	})
+}
+
+func TestIDGeneratorCollisions(t *testing.T) {
+	const goroutines, perGoroutine = 50, 2000
+
+	// generateConcurrently generates goroutines*perGoroutine IDs in parallel
+	// and fails on the first duplicate
+	generateConcurrently := func(t *testing.T, generate func() string) {
+		var wg sync.WaitGroup
+		results := make([][]string, goroutines)
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				ids := make([]string, perGoroutine)
+				for i := range ids {
+					ids[i] = generate()
+				}
+				results[g] = ids
+			}(g)
+		}
+		wg.Wait()
+
+		seen := make(map[string]bool, goroutines*perGoroutine)
+		for _, ids := range results {
+			for _, id := range ids {
+				require.False(t, seen[id], "duplicate ID %s", id)
+				seen[id] = true
+			}
+		}
+		assert.Len(t, seen, goroutines*perGoroutine)
+	}
+
+	t.Run("DefaultIDGenerator", func(t *testing.T) {
+		generateConcurrently(t, NewDefaultIDGenerator().GenerateMessageID)
+	})
+
+	t.Run("TimestampIDGenerator", func(t *testing.T) {
+		// Most of these IDs share their millisecond, so uniqueness rests on
+		// the random suffix
+		generateConcurrently(t, NewTimestampIDGenerator("app").GenerateMessageID)
+	})
+
+	t.Run("UUIDv7Generator", func(t *testing.T) {
+		generateConcurrently(t, NewUUIDv7Generator().GenerateMessageID)
+	})
+
+	t.Run("GlobalFunctionsWhileReplacingGenerator", func(t *testing.T) {
+		original := GetDefaultIDGenerator()
+		defer SetDefaultIDGenerator(original)
+
+		done := make(chan struct{})
+		var swapper sync.WaitGroup
+		swapper.Add(1)
+		go func() {
+			defer swapper.Done()
+			generators := []IDGenerator{NewDefaultIDGenerator(), NewTimestampIDGenerator("")}
+			for i := 0; ; i++ {
+				select {
+				case <-done:
+					return
+				default:
+					SetDefaultIDGenerator(generators[i%2])
+				}
+			}
+		}()
+
+		generateConcurrently(t, GenerateRunID)
+		close(done)
+		swapper.Wait()
+	})
+}
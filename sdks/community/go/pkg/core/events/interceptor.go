@@ -0,0 +1,138 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// EventInterceptor inspects events on their way through a pipeline and may
+// replace them. Intercept returns the event to deliver in place of e, which
+// may be e itself, or nil to drop the event. An error aborts processing of
+// the event. Interceptors should not modify the event they receive; use
+// CopyEvent to build a modified version.
+type EventInterceptor interface {
+	Intercept(ctx context.Context, e Event) (Event, error)
+}
+
+// EventInterceptorFunc adapts a function to the EventInterceptor interface
+type EventInterceptorFunc func(ctx context.Context, e Event) (Event, error)
+
+// Intercept calls f(ctx, e)
+func (f EventInterceptorFunc) Intercept(ctx context.Context, e Event) (Event, error) {
+	return f(ctx, e)
+}
+
+// interceptorChain applies interceptors in order
+type interceptorChain []EventInterceptor
+
+// InterceptorChain returns a middleware that passes each event through the
+// given interceptors in order, each receiving the event returned by the
+// previous one, and hands the result to the next handler. An event dropped by
+// an interceptor is not delivered. If an interceptor fails, the next handler
+// is not called and the error identifies the interceptor by its position.
+func InterceptorChain(interceptors ...EventInterceptor) EventMiddleware {
+	chain := interceptorChain(append([]EventInterceptor(nil), interceptors...))
+	return func(next EventHandler) EventHandler {
+		return EventHandlerFunc(func(ctx context.Context, event Event) error {
+			event, err := chain.intercept(ctx, event)
+			if err != nil || event == nil {
+				return err
+			}
+			return next.HandleEvent(ctx, event)
+		})
+	}
+}
+
+// intercept applies the interceptors to e, stopping as soon as one of them
+// drops the event or fails
+func (c interceptorChain) intercept(ctx context.Context, e Event) (Event, error) {
+	for i, interceptor := range c {
+		if e == nil {
+			return nil, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var err error
+		e, err = interceptor.Intercept(ctx, e)
+		if err != nil {
+			return nil, fmt.Errorf("interceptor %d: %w", i, err)
+		}
+	}
+	return e, nil
+}
+
+// ContentFilterInterceptor returns an interceptor that redacts the given
+// words from the text of message and thinking content deltas, replacing
+// each occurrence with asterisks of the same length. Words are matched
+// case-insensitively on word boundaries. Events containing a word are copied
+// before redaction, and other events pass through unchanged.
+//
+// Each delta is filtered on its own, so a word split across two deltas is not
+// redacted; combine it with a DeltaCoalescer to filter larger chunks.
+func ContentFilterInterceptor(badWords []string) EventInterceptor {
+	var alternatives []string
+	for _, word := range badWords {
+		if word != "" {
+			alternatives = append(alternatives, regexp.QuoteMeta(word))
+		}
+	}
+	if len(alternatives) == 0 {
+		return EventInterceptorFunc(func(_ context.Context, e Event) (Event, error) {
+			return e, nil
+		})
+	}
+	pattern := regexp.MustCompile(`(?i)\b(?:` + strings.Join(alternatives, "|") + `)\b`)
+
+	redact := func(text string) string {
+		return pattern.ReplaceAllStringFunc(text, func(match string) string {
+			return strings.Repeat("*", len([]rune(match)))
+		})
+	}
+
+	return EventInterceptorFunc(func(_ context.Context, e Event) (Event, error) {
+		var delta string
+		switch event := e.(type) {
+		case *TextMessageContentEvent:
+			delta = event.Delta
+			if event.Edit != nil {
+				delta = event.Edit.Insert
+			}
+		case *TextMessageChunkEvent:
+			if event.Delta == nil {
+				return e, nil
+			}
+			delta = *event.Delta
+		case *ThinkingTextMessageContentEvent:
+			delta = event.Delta
+		default:
+			return e, nil
+		}
+
+		if !pattern.MatchString(delta) {
+			return e, nil
+		}
+		redacted := redact(delta)
+
+		clone, err := CopyEvent(e)
+		if err != nil {
+			return nil, err
+		}
+		switch event := clone.(type) {
+		case *TextMessageContentEvent:
+			if event.Edit != nil {
+				event.Edit.Insert = redacted
+			} else {
+				event.Delta = redacted
+			}
+		case *TextMessageChunkEvent:
+			event.Delta = &redacted
+		case *ThinkingTextMessageContentEvent:
+			event.Delta = redacted
+		}
+		return clone, nil
+	})
+}
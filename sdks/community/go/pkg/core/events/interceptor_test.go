@@ -0,0 +1,165 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterceptorChain(t *testing.T) {
+	ctx := context.Background()
+
+	var calls []string
+	var delivered []Event
+	record := func(name string) EventInterceptor {
+		return EventInterceptorFunc(func(_ context.Context, e Event) (Event, error) {
+			calls = append(calls, name)
+			return e, nil
+		})
+	}
+	handler := EventHandlerFunc(func(_ context.Context, e Event) error {
+		calls = append(calls, "handler")
+		delivered = append(delivered, e)
+		return nil
+	})
+
+	t.Run("AppliesInterceptorsInOrder", func(t *testing.T) {
+		calls, delivered = nil, nil
+		replacement := NewStepStartedEvent("replaced")
+		pipeline := InterceptorChain(
+			record("first"),
+			EventInterceptorFunc(func(_ context.Context, e Event) (Event, error) {
+				calls = append(calls, "replace")
+				return replacement, nil
+			}),
+			EventInterceptorFunc(func(_ context.Context, e Event) (Event, error) {
+				calls = append(calls, "last")
+				assert.Same(t, replacement, e)
+				return e, nil
+			}),
+		)(handler)
+
+		require.NoError(t, pipeline.HandleEvent(ctx, NewStepStartedEvent("plan")))
+		assert.Equal(t, []Event{replacement}, delivered)
+		assert.Equal(t, []string{"first", "replace", "last", "handler"}, calls)
+	})
+
+	t.Run("NilDropsTheEvent", func(t *testing.T) {
+		calls, delivered = nil, nil
+		pipeline := InterceptorChain(
+			EventInterceptorFunc(func(context.Context, Event) (Event, error) { return nil, nil }),
+			record("after"),
+		)(handler)
+
+		require.NoError(t, pipeline.HandleEvent(ctx, NewStepStartedEvent("plan")))
+		assert.Empty(t, delivered)
+		assert.Empty(t, calls)
+	})
+
+	t.Run("ErrorAbortsProcessing", func(t *testing.T) {
+		calls, delivered = nil, nil
+		errRejected := errors.New("rejected")
+		pipeline := InterceptorChain(
+			record("first"),
+			EventInterceptorFunc(func(context.Context, Event) (Event, error) { return nil, errRejected }),
+			record("after"),
+		)(handler)
+
+		err := pipeline.HandleEvent(ctx, NewStepStartedEvent("plan"))
+		assert.ErrorIs(t, err, errRejected)
+		assert.Contains(t, err.Error(), "interceptor 1")
+		assert.Empty(t, delivered)
+		assert.Equal(t, []string{"first"}, calls)
+	})
+
+	t.Run("CancelledContext", func(t *testing.T) {
+		calls, delivered = nil, nil
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		err := InterceptorChain(record("first"))(handler).HandleEvent(cancelled, NewStepStartedEvent("plan"))
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, calls)
+	})
+
+	t.Run("EmptyChain", func(t *testing.T) {
+		calls, delivered = nil, nil
+		event := NewStepStartedEvent("plan")
+		require.NoError(t, InterceptorChain()(handler).HandleEvent(ctx, event))
+		assert.Equal(t, []Event{event}, delivered)
+	})
+
+	t.Run("ComposesWithChain", func(t *testing.T) {
+		calls, delivered = nil, nil
+		var audited []Event
+		audit := EventHandlerFunc(func(_ context.Context, e Event) error {
+			audited = append(audited, e)
+			return nil
+		})
+		redacting := InterceptorChain(ContentFilterInterceptor([]string{"heck"}))(handler)
+
+		pipeline := Chain(audit, redacting)
+		require.NoError(t, pipeline.HandleEvent(ctx, NewTextMessageContentEvent("msg-1", "oh heck")))
+		require.Len(t, delivered, 1)
+		assert.Equal(t, "oh ****", delivered[0].(*TextMessageContentEvent).Delta)
+		assert.Equal(t, "oh heck", audited[0].(*TextMessageContentEvent).Delta)
+	})
+}
+
+func TestContentFilterInterceptor(t *testing.T) {
+	ctx := context.Background()
+	filter := ContentFilterInterceptor([]string{"darn", "heck", ""})
+
+	t.Run("RedactsMessageContent", func(t *testing.T) {
+		event := NewTextMessageContentEvent("msg-1", "Darn it, what the heck")
+		out, err := filter.Intercept(ctx, event)
+		require.NoError(t, err)
+
+		content, ok := out.(*TextMessageContentEvent)
+		require.True(t, ok)
+		assert.Equal(t, "**** it, what the ****", content.Delta)
+		assert.Equal(t, "msg-1", content.MessageID)
+		assert.Equal(t, "Darn it, what the heck", event.Delta, "original must not be modified")
+	})
+
+	t.Run("RedactsChunksAndThinkingContent", func(t *testing.T) {
+		delta := "oh heck"
+		out, err := filter.Intercept(ctx, NewTextMessageChunkEvent(nil, nil, &delta))
+		require.NoError(t, err)
+		assert.Equal(t, "oh ****", *out.(*TextMessageChunkEvent).Delta)
+		assert.Equal(t, "oh heck", delta)
+
+		out, err = filter.Intercept(ctx, NewThinkingTextMessageContentEvent("darn"))
+		require.NoError(t, err)
+		assert.Equal(t, "****", out.(*ThinkingTextMessageContentEvent).Delta)
+	})
+
+	t.Run("MatchesWholeWordsOnly", func(t *testing.T) {
+		event := NewTextMessageContentEvent("msg-1", "Checkered darning")
+		out, err := filter.Intercept(ctx, event)
+		require.NoError(t, err)
+		assert.Same(t, event, out)
+	})
+
+	t.Run("PassesOtherEventsThrough", func(t *testing.T) {
+		event := NewToolCallArgsEvent("call-1", `{"q":"heck"}`)
+		out, err := filter.Intercept(ctx, event)
+		require.NoError(t, err)
+		assert.Same(t, event, out)
+
+		chunk := NewTextMessageChunkEvent(nil, nil, nil)
+		out, err = filter.Intercept(ctx, chunk)
+		require.NoError(t, err)
+		assert.Same(t, chunk, out)
+	})
+
+	t.Run("NoWords", func(t *testing.T) {
+		event := NewTextMessageContentEvent("msg-1", "heck")
+		out, err := ContentFilterInterceptor(nil).Intercept(ctx, event)
+		require.NoError(t, err)
+		assert.Same(t, event, out)
+	})
+}
@@ -0,0 +1,520 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/jsonpointer"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/jsonwalk"
+)
+
+// PatchError records the failure of a single JSON Patch operation
+type PatchError struct {
+	// Index is the position of the failing operation in the patch
+	Index int
+
+	// Op is the failing operation
+	Op JSONPatchOperation
+
+	// Err describes why the operation failed
+	Err error
+}
+
+// Error implements the error interface
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("operation %d (%s %s) failed: %v", e.Index, e.Op.Op, e.Op.Path, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// PatchOption configures ApplyPatch
+type PatchOption func(*patchConfig)
+
+// patchConfig holds the options of ApplyPatch
+type patchConfig struct {
+	preserveNumbers bool
+}
+
+// PreserveNumbers makes ApplyPatch represent the numbers of the document and
+// of the operation values as json.Number rather than float64, so integers
+// beyond float64 precision stay exact
+func PreserveNumbers() PatchOption {
+	return func(c *patchConfig) {
+		c.preserveNumbers = true
+	}
+}
+
+// ApplyPatch applies RFC 6902 JSON Patch operations to a document and returns
+// the patched document. The document is converted to its generic JSON form
+// first, so the input is never modified, and the patch is applied atomically:
+// when an operation fails, no partial result is returned and the failure is
+// reported as a *PatchError carrying the operation's index and path. Values
+// are compared with JSON semantics by "test" operations, so numbers match by
+// value whatever their Go type.
+func ApplyPatch(doc any, ops []JSONPatchOperation, options ...PatchOption) (any, error) {
+	var config patchConfig
+	for _, opt := range options {
+		opt(&config)
+	}
+
+	normalized, err := normalizeJSON(doc, config.preserveNumbers)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+	patched, err := applyPatchNumbers(normalized, ops, config.preserveNumbers)
+	if err != nil {
+		return nil, err
+	}
+	return patched, nil
+}
+
+// applyPatch applies JSON Patch operations to a JSON document in order and
+// returns the resulting document. The document must be made of generic JSON
+// values (map[string]any, []any, string, float64, bool and nil). It is never
+// modified: the objects and arrays along each modified path are copied, and
+// the result shares everything else with the input.
+func applyPatch(doc any, ops []JSONPatchOperation) (any, error) {
+	return applyPatchNumbers(doc, ops, false)
+}
+
+// applyPatchNumbers applies JSON Patch operations like applyPatch, keeping
+// the numbers of the operation values as json.Number if preserveNumbers is
+// set
+func applyPatchNumbers(doc any, ops []JSONPatchOperation, preserveNumbers bool) (any, error) {
+	for i, op := range ops {
+		var err error
+		doc, err = applyOperation(doc, op, preserveNumbers)
+		if err != nil {
+			return doc, &PatchError{Index: i, Op: op, Err: err}
+		}
+	}
+	return doc, nil
+}
+
+// applyOperation applies a single JSON Patch operation to a document
+func applyOperation(doc any, op JSONPatchOperation, preserveNumbers bool) (any, error) {
+	path, err := jsonpointer.Parse(op.Path)
+	if err != nil {
+		return doc, err
+	}
+
+	switch op.Op {
+	case "add":
+		value, err := normalizeJSON(op.Value, preserveNumbers)
+		if err != nil {
+			return doc, err
+		}
+		return addValue(doc, path, value)
+
+	case "remove":
+		doc, _, err := removeValue(doc, path)
+		return doc, err
+
+	case "replace":
+		value, err := normalizeJSON(op.Value, preserveNumbers)
+		if err != nil {
+			return doc, err
+		}
+		return replaceValue(doc, path, value)
+
+	case "move":
+		from, err := jsonpointer.Parse(op.From)
+		if err != nil {
+			return doc, err
+		}
+		if isProperPrefix(from, path) {
+			return doc, fmt.Errorf("cannot move %s into one of its children", op.From)
+		}
+		doc, value, err := removeValue(doc, from)
+		if err != nil {
+			return doc, err
+		}
+		return addValue(doc, path, value)
+
+	case "copy":
+		from, err := jsonpointer.Parse(op.From)
+		if err != nil {
+			return doc, err
+		}
+		value, err := getValue(doc, from)
+		if err != nil {
+			return doc, err
+		}
+		return addValue(doc, path, deepCopyJSONValue(value))
+
+	case "test":
+		expected, err := normalizeJSON(op.Value, preserveNumbers)
+		if err != nil {
+			return doc, err
+		}
+		actual, err := getValue(doc, path)
+		if err != nil {
+			return doc, err
+		}
+		if !jsonValuesEqual(actual, expected) {
+			return doc, fmt.Errorf("test failed: value at %s does not match", op.Path)
+		}
+		return doc, nil
+
+	default:
+		return doc, fmt.Errorf("unsupported operation: %s", op.Op)
+	}
+}
+
+// appendJSONPointer appends an escaped reference token to a JSON Pointer
+func appendJSONPointer(pointer, token string) string {
+	return pointer + "/" + jsonpointer.Escape(token)
+}
+
+// isProperPrefix reports whether prefix is a proper prefix of path
+func isProperPrefix(prefix, path []string) bool {
+	if len(prefix) >= len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// getValue returns the value at the given path
+func getValue(doc any, path []string) (any, error) {
+	current := doc
+	for _, token := range path {
+		switch container := current.(type) {
+		case map[string]any:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("path not found: key %q does not exist", token)
+			}
+			current = value
+		case []any:
+			index, err := arrayIndex(token, len(container), false)
+			if err != nil {
+				return nil, err
+			}
+			current = container[index]
+		default:
+			return nil, fmt.Errorf("path not found: cannot traverse into %T at %q", current, token)
+		}
+	}
+	return current, nil
+}
+
+// updateParent navigates to the parent of the path's last token, applies fn
+// to it, and returns a copy of the document with the updated parent in its
+// place. Only the containers along the path are copied; fn must likewise
+// return a new container rather than modify the one it is given.
+func updateParent(doc any, path []string, fn func(parent any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+
+	token := path[0]
+	switch container := doc.(type) {
+	case map[string]any:
+		child, ok := container[token]
+		if !ok {
+			return doc, fmt.Errorf("path not found: key %q does not exist", token)
+		}
+		updated, err := updateParent(child, path[1:], fn)
+		if err != nil {
+			return doc, err
+		}
+		copied := cloneJSONObject(container)
+		copied[token] = updated
+		return copied, nil
+
+	case []any:
+		index, err := arrayIndex(token, len(container), false)
+		if err != nil {
+			return doc, err
+		}
+		updated, err := updateParent(container[index], path[1:], fn)
+		if err != nil {
+			return doc, err
+		}
+		copied := append([]any(nil), container...)
+		copied[index] = updated
+		return copied, nil
+
+	default:
+		return doc, fmt.Errorf("path not found: cannot traverse into %T at %q", doc, token)
+	}
+}
+
+// cloneJSONObject returns a shallow copy of an object
+func cloneJSONObject(obj map[string]any) map[string]any {
+	copied := make(map[string]any, len(obj)+1)
+	for key, value := range obj {
+		copied[key] = value
+	}
+	return copied
+}
+
+// addValue adds a value at the given path, inserting into arrays
+func addValue(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return updateParent(doc, path, func(parent any, token string) (any, error) {
+		switch container := parent.(type) {
+		case map[string]any:
+			copied := cloneJSONObject(container)
+			copied[token] = value
+			return copied, nil
+		case []any:
+			index, err := arrayIndex(token, len(container), true)
+			if err != nil {
+				return parent, err
+			}
+			copied := make([]any, 0, len(container)+1)
+			copied = append(copied, container[:index]...)
+			copied = append(copied, value)
+			return append(copied, container[index:]...), nil
+		default:
+			return parent, fmt.Errorf("cannot add to %T", parent)
+		}
+	})
+}
+
+// removeValue removes the value at the given path and returns it
+func removeValue(doc any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, doc, nil
+	}
+
+	var removed any
+	doc, err := updateParent(doc, path, func(parent any, token string) (any, error) {
+		switch container := parent.(type) {
+		case map[string]any:
+			value, ok := container[token]
+			if !ok {
+				return parent, fmt.Errorf("path not found: key %q does not exist", token)
+			}
+			removed = value
+			copied := cloneJSONObject(container)
+			delete(copied, token)
+			return copied, nil
+		case []any:
+			index, err := arrayIndex(token, len(container), false)
+			if err != nil {
+				return parent, err
+			}
+			removed = container[index]
+			return append(container[:index:index], container[index+1:]...), nil
+		default:
+			return parent, fmt.Errorf("cannot remove from %T", parent)
+		}
+	})
+	return doc, removed, err
+}
+
+// replaceValue replaces the existing value at the given path
+func replaceValue(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return updateParent(doc, path, func(parent any, token string) (any, error) {
+		switch container := parent.(type) {
+		case map[string]any:
+			if _, ok := container[token]; !ok {
+				return parent, fmt.Errorf("path not found: key %q does not exist", token)
+			}
+			copied := cloneJSONObject(container)
+			copied[token] = value
+			return copied, nil
+		case []any:
+			index, err := arrayIndex(token, len(container), false)
+			if err != nil {
+				return parent, err
+			}
+			copied := append([]any(nil), container...)
+			copied[index] = value
+			return copied, nil
+		default:
+			return parent, fmt.Errorf("cannot replace in %T", parent)
+		}
+	})
+}
+
+// arrayIndex parses an array reference token. When forAdd is true, the index
+// may equal the array length and "-" refers to the end of the array.
+func arrayIndex(token string, length int, forAdd bool) (int, error) {
+	if token == "-" {
+		if forAdd {
+			return length, nil
+		}
+		return 0, fmt.Errorf("index - refers to a nonexistent array element")
+	}
+
+	index, ok := jsonpointer.ParseIndex(token)
+	if !ok {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	limit := length - 1
+	if forAdd {
+		limit = length
+	}
+	if index > limit {
+		return 0, fmt.Errorf("array index %d out of bounds (length %d)", index, length)
+	}
+	return index, nil
+}
+
+// normalizeJSONValue converts a value into its generic JSON representation
+// (map[string]any, []any, string, float64, bool or nil), producing a copy
+// that does not share memory with the input
+func normalizeJSONValue(value any) (any, error) {
+	return normalizeJSON(value, false)
+}
+
+// normalizeJSON converts a value into its generic JSON representation like
+// normalizeJSONValue. When preserveNumbers is set, numbers are represented as
+// json.Number instead of float64, so integers beyond float64 precision stay
+// exact.
+func normalizeJSON(value any, preserveNumbers bool) (any, error) {
+	switch value.(type) {
+	case nil, string, bool:
+		return value, nil
+	case float64:
+		if !preserveNumbers {
+			return value, nil
+		}
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("value is not JSON-serializable: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if preserveNumbers {
+		decoder.UseNumber()
+	}
+	var normalized any
+	if err := decoder.Decode(&normalized); err != nil {
+		return nil, fmt.Errorf("value is not JSON-serializable: %w", err)
+	}
+	return normalized, nil
+}
+
+// deepCopyJSONValue copies a generic JSON value so that the copy shares no
+// maps or slices with the original
+func deepCopyJSONValue(value any) any {
+	switch value.(type) {
+	case map[string]any, []any:
+	default:
+		return value
+	}
+
+	copied, _ := jsonwalk.Transform(value, func(_ []string, v any) (any, error) {
+		return v, nil
+	})
+	return copied
+}
+
+// jsonValuesEqual reports whether two generic JSON values are equal under
+// JSON semantics: numbers are compared by value whatever their Go type, and
+// objects and arrays are compared member by member. Nested values are
+// compared with a stack of pairs rather than recursion, so deep documents
+// cannot exhaust the stack.
+func jsonValuesEqual(a, b any) bool {
+	pairs := [][2]any{{a, b}}
+	for len(pairs) > 0 {
+		pair := pairs[len(pairs)-1]
+		pairs = pairs[:len(pairs)-1]
+
+		switch av := pair[0].(type) {
+		case map[string]any:
+			bv, ok := pair[1].(map[string]any)
+			if !ok || len(av) != len(bv) {
+				return false
+			}
+			for key, item := range av {
+				other, ok := bv[key]
+				if !ok {
+					return false
+				}
+				pairs = append(pairs, [2]any{item, other})
+			}
+
+		case []any:
+			bv, ok := pair[1].([]any)
+			if !ok || len(av) != len(bv) {
+				return false
+			}
+			for i := range av {
+				pairs = append(pairs, [2]any{av[i], bv[i]})
+			}
+
+		default:
+			if !jsonScalarsEqual(pair[0], pair[1]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// jsonScalarsEqual reports whether two values that are not both objects or
+// both arrays are equal under JSON semantics
+func jsonScalarsEqual(a, b any) bool {
+	if af, ok := a.(float64); ok {
+		if bf, ok := b.(float64); ok {
+			return af == bf
+		}
+	}
+	if an, ok := jsonNumber(a); ok {
+		bn, ok := jsonNumber(b)
+		return ok && an.Cmp(bn) == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// jsonNumber converts a numeric value to an exact rational number
+func jsonNumber(value any) (*big.Rat, bool) {
+	switch v := value.(type) {
+	case float64:
+		r := new(big.Rat).SetFloat64(v)
+		return r, r != nil
+	case float32:
+		r := new(big.Rat).SetFloat64(float64(v))
+		return r, r != nil
+	case int:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case int8:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case int16:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case int32:
+		return new(big.Rat).SetInt64(int64(v)), true
+	case int64:
+		return new(big.Rat).SetInt64(v), true
+	case uint:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case uint8:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case uint16:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case uint32:
+		return new(big.Rat).SetUint64(uint64(v)), true
+	case uint64:
+		return new(big.Rat).SetUint64(v), true
+	case json.Number:
+		return new(big.Rat).SetString(string(v))
+	default:
+		return nil, false
+	}
+}
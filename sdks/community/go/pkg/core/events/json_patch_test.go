@@ -0,0 +1,364 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeJSON decodes a JSON literal used in a test table
+func decodeJSON(t *testing.T, data string, target any) {
+	t.Helper()
+	require.NoError(t, json.Unmarshal([]byte(data), target))
+}
+
+func TestApplyPatch(t *testing.T) {
+	// Cases A.1 to A.16 are ported from RFC 6902 Appendix A. A.13 (duplicate
+	// "op" members) is not representable once the patch is decoded.
+	tests := []struct {
+		name     string
+		doc      string
+		patch    string
+		expected string
+
+		// Index and path of the failing operation, when an error is expected
+		failIndex int
+		failPath  string
+	}{
+		{
+			name:     "A.1 AddObjectMember",
+			doc:      `{"foo": "bar"}`,
+			patch:    `[{"op": "add", "path": "/baz", "value": "qux"}]`,
+			expected: `{"baz": "qux", "foo": "bar"}`,
+		},
+		{
+			name:     "A.2 AddArrayElement",
+			doc:      `{"foo": ["bar", "baz"]}`,
+			patch:    `[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+			expected: `{"foo": ["bar", "qux", "baz"]}`,
+		},
+		{
+			name:     "A.3 RemoveObjectMember",
+			doc:      `{"baz": "qux", "foo": "bar"}`,
+			patch:    `[{"op": "remove", "path": "/baz"}]`,
+			expected: `{"foo": "bar"}`,
+		},
+		{
+			name:     "A.4 RemoveArrayElement",
+			doc:      `{"foo": ["bar", "qux", "baz"]}`,
+			patch:    `[{"op": "remove", "path": "/foo/1"}]`,
+			expected: `{"foo": ["bar", "baz"]}`,
+		},
+		{
+			name:     "A.5 ReplaceValue",
+			doc:      `{"baz": "qux", "foo": "bar"}`,
+			patch:    `[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+			expected: `{"baz": "boo", "foo": "bar"}`,
+		},
+		{
+			name:     "A.6 MoveValue",
+			doc:      `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			patch:    `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			expected: `{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+		},
+		{
+			name:     "A.7 MoveArrayElement",
+			doc:      `{"foo": ["all", "grass", "cows", "eat"]}`,
+			patch:    `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+			expected: `{"foo": ["all", "cows", "eat", "grass"]}`,
+		},
+		{
+			name: "A.8 TestSuccess",
+			doc:  `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			patch: `[
+				{"op": "test", "path": "/baz", "value": "qux"},
+				{"op": "test", "path": "/foo/1", "value": 2}
+			]`,
+			expected: `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+		},
+		{
+			name:      "A.9 TestFailure",
+			doc:       `{"baz": "qux"}`,
+			patch:     `[{"op": "test", "path": "/baz", "value": "bar"}]`,
+			failIndex: 0,
+			failPath:  "/baz",
+		},
+		{
+			name:     "A.10 AddNestedMember",
+			doc:      `{"foo": "bar"}`,
+			patch:    `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`,
+			expected: `{"foo": "bar", "child": {"grandchild": {}}}`,
+		},
+		{
+			name:     "A.11 IgnoreUnrecognizedMembers",
+			doc:      `{"foo": "bar"}`,
+			patch:    `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`,
+			expected: `{"foo": "bar", "baz": "qux"}`,
+		},
+		{
+			name:      "A.12 AddToNonexistentTarget",
+			doc:       `{"foo": "bar"}`,
+			patch:     `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`,
+			failIndex: 0,
+			failPath:  "/baz/bat",
+		},
+		{
+			name:     "A.14 EscapeOrdering",
+			doc:      `{"/": 9, "~1": 10}`,
+			patch:    `[{"op": "test", "path": "/~01", "value": 10}]`,
+			expected: `{"/": 9, "~1": 10}`,
+		},
+		{
+			name:      "A.15 CompareStringsAndNumbers",
+			doc:       `{"/": 9, "~1": 10}`,
+			patch:     `[{"op": "test", "path": "/~01", "value": "10"}]`,
+			failIndex: 0,
+			failPath:  "/~01",
+		},
+		{
+			name:     "A.16 AddArrayValue",
+			doc:      `{"foo": ["bar"]}`,
+			patch:    `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`,
+			expected: `{"foo": ["bar", ["abc", "def"]]}`,
+		},
+		{
+			name:     "CopyValue",
+			doc:      `{"a": {"b": [1, 2]}}`,
+			patch:    `[{"op": "copy", "from": "/a/b", "path": "/c"}, {"op": "add", "path": "/c/-", "value": 3}]`,
+			expected: `{"a": {"b": [1, 2]}, "c": [1, 2, 3]}`,
+		},
+		{
+			name:     "ReplaceRoot",
+			doc:      `{"foo": "bar"}`,
+			patch:    `[{"op": "replace", "path": "", "value": [1]}]`,
+			expected: `[1]`,
+		},
+		{
+			name:     "EscapedSlashInKey",
+			doc:      `{"a/b": 1}`,
+			patch:    `[{"op": "replace", "path": "/a~1b", "value": 2}]`,
+			expected: `{"a/b": 2}`,
+		},
+		{
+			name:      "ReportsFailingOperationIndex",
+			doc:       `{"foo": ["bar"]}`,
+			patch:     `[{"op": "add", "path": "/baz", "value": 1}, {"op": "remove", "path": "/foo/5"}]`,
+			failIndex: 1,
+			failPath:  "/foo/5",
+		},
+		{
+			name:      "AppendFormOnlyForAdd",
+			doc:       `{"foo": ["bar"]}`,
+			patch:     `[{"op": "remove", "path": "/foo/-"}]`,
+			failIndex: 0,
+			failPath:  "/foo/-",
+		},
+		{
+			name:      "LeadingZeroIndex",
+			doc:       `{"foo": ["bar", "baz"]}`,
+			patch:     `[{"op": "replace", "path": "/foo/01", "value": "qux"}]`,
+			failIndex: 0,
+			failPath:  "/foo/01",
+		},
+		{
+			name:      "MoveIntoOwnChild",
+			doc:       `{"a": {"b": {}}}`,
+			patch:     `[{"op": "move", "from": "/a", "path": "/a/b/c"}]`,
+			failIndex: 0,
+			failPath:  "/a/b/c",
+		},
+		{
+			name:      "ReplaceMissingMember",
+			doc:       `{"foo": "bar"}`,
+			patch:     `[{"op": "replace", "path": "/baz", "value": "qux"}]`,
+			failIndex: 0,
+			failPath:  "/baz",
+		},
+		{
+			name:      "UnsupportedOperation",
+			doc:       `{}`,
+			patch:     `[{"op": "merge", "path": "/a", "value": 1}]`,
+			failIndex: 0,
+			failPath:  "/a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc any
+			var ops []JSONPatchOperation
+			decodeJSON(t, tt.doc, &doc)
+			decodeJSON(t, tt.patch, &ops)
+
+			result, err := ApplyPatch(doc, ops)
+			if tt.expected == "" {
+				var patchErr *PatchError
+				require.ErrorAs(t, err, &patchErr)
+				assert.Equal(t, tt.failIndex, patchErr.Index)
+				assert.Equal(t, tt.failPath, patchErr.Op.Path)
+				assert.Contains(t, err.Error(), tt.failPath)
+				return
+			}
+
+			require.NoError(t, err)
+			var expected any
+			decodeJSON(t, tt.expected, &expected)
+			assert.Equal(t, expected, result)
+		})
+	}
+}
+
+func TestApplyPatchInputs(t *testing.T) {
+	t.Run("DoesNotModifyInput", func(t *testing.T) {
+		doc := map[string]any{"items": []any{"a"}, "nested": map[string]any{"n": 1}}
+
+		result, err := ApplyPatch(doc, []JSONPatchOperation{
+			{Op: "add", Path: "/items/-", Value: "b"},
+			{Op: "remove", Path: "/nested/n"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]any{"items": []any{"a", "b"}, "nested": map[string]any{}}, result)
+		assert.Equal(t, map[string]any{"items": []any{"a"}, "nested": map[string]any{"n": 1}}, doc)
+	})
+
+	t.Run("TypedDocument", func(t *testing.T) {
+		type state struct {
+			Progress int      `json:"progress"`
+			Steps    []string `json:"steps"`
+		}
+
+		result, err := ApplyPatch(state{Progress: 10, Steps: []string{"plan"}}, []JSONPatchOperation{
+			{Op: "replace", Path: "/progress", Value: 50},
+			{Op: "add", Path: "/steps/-", Value: "act"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"progress": float64(50), "steps": []any{"plan", "act"}}, result)
+	})
+
+	t.Run("UnserializableDocument", func(t *testing.T) {
+		_, err := ApplyPatch(map[string]any{"ch": make(chan int)}, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("StateDeltaApplyTo", func(t *testing.T) {
+		delta := NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "replace", Path: "/status", Value: "done"},
+			{Op: "test", Path: "/status", Value: "running"},
+		})
+
+		_, err := delta.ApplyTo(map[string]any{"status": "running"})
+		var patchErr *PatchError
+		require.ErrorAs(t, err, &patchErr)
+		assert.Equal(t, 1, patchErr.Index)
+		assert.Equal(t, "operation 1 (test /status) failed: test failed: value at /status does not match", err.Error())
+
+		result, err := delta.ApplyTo(map[string]any{"status": "pending"})
+		require.Error(t, err)
+		assert.Nil(t, result)
+
+		delta.Delta = delta.Delta[:1]
+		result, err = delta.ApplyTo(map[string]any{"status": "running"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"status": "done"}, result)
+	})
+}
+
+func TestJSONValuesEqual(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  any
+		equal bool
+	}{
+		{"IntAndFloat", 10, 10.0, true},
+		{"Int64AndUint8", int64(7), uint8(7), true},
+		{"JSONNumberAndFloat", json.Number("10"), 10.0, true},
+		{"JSONNumberExponent", json.Number("1e1"), 10, true},
+		{"JSONNumberFraction", json.Number("0.5"), float32(0.5), true},
+		{"DifferentNumbers", 10, 10.5, false},
+		{"NumberAndString", 10, "10", false},
+		{"NullAndZero", nil, 0, false},
+		{"Nulls", nil, nil, true},
+		{"Strings", "a", "a", true},
+		{"Bools", true, false, false},
+		{"NestedNumbers", map[string]any{"x": []any{1, 2.0}}, map[string]any{"x": []any{1.0, json.Number("2")}}, true},
+		{"ExtraKey", map[string]any{"a": 1}, map[string]any{"a": 1, "b": 2}, false},
+		{"ArrayOrder", []any{1, 2}, []any{2, 1}, false},
+		{"ObjectAndArray", map[string]any{}, []any{}, false},
+		{"LargeIntegers", int64(1) << 60, uint64(1) << 60, true},
+		{"LargeIntegersDiffer", int64(1)<<60 + 1, json.Number("1152921504606846976"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.equal, jsonValuesEqual(tt.a, tt.b))
+			assert.Equal(t, tt.equal, jsonValuesEqual(tt.b, tt.a))
+		})
+	}
+}
+
+func TestApplyPatchAtomicity(t *testing.T) {
+	doc := map[string]any{"a": 1, "b": map[string]any{"count": 2}, "list": []any{"x"}}
+	ops := []JSONPatchOperation{
+		{Op: "replace", Path: "/a", Value: 2},
+		{Op: "add", Path: "/list/-", Value: "y"},
+		{Op: "remove", Path: "/b/count"},
+		{Op: "test", Path: "/a", Value: 3},
+		{Op: "add", Path: "/c", Value: true},
+	}
+
+	t.Run("FailedTestAbortsPatch", func(t *testing.T) {
+		result, err := ApplyPatch(doc, ops)
+		var patchErr *PatchError
+		require.ErrorAs(t, err, &patchErr)
+		assert.Equal(t, 3, patchErr.Index)
+		assert.Equal(t, "test", patchErr.Op.Op)
+		assert.Nil(t, result)
+		assert.Equal(t, map[string]any{"a": 1, "b": map[string]any{"count": 2}, "list": []any{"x"}}, doc)
+	})
+
+	t.Run("TestComparesNumberForms", func(t *testing.T) {
+		result, err := ApplyPatch(doc, []JSONPatchOperation{
+			{Op: "test", Path: "/a", Value: 1.0},
+			{Op: "test", Path: "/a", Value: json.Number("1")},
+			{Op: "test", Path: "/b", Value: map[string]any{"count": json.Number("2.0")}},
+			{Op: "test", Path: "/list", Value: []string{"x"}},
+			{Op: "replace", Path: "/a", Value: 5},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, float64(5), result.(map[string]any)["a"])
+
+		_, err = ApplyPatch(doc, []JSONPatchOperation{{Op: "test", Path: "/a", Value: 1.5}})
+		assert.Error(t, err)
+	})
+
+	t.Run("StateStoreRollsBack", func(t *testing.T) {
+		store := NewStateStore()
+		store.ApplySnapshot(NewStateSnapshotEvent(doc))
+		before := store.Current()
+
+		fired := false
+		_, err := store.Subscribe("/a", func(old, new any) { fired = true })
+		require.NoError(t, err)
+
+		err = store.Apply(NewStateDeltaEvent(ops))
+		var patchErr *PatchError
+		require.ErrorAs(t, err, &patchErr)
+		assert.Contains(t, err.Error(), "operation 3 (test /a) failed")
+
+		assert.Equal(t, before, store.Current())
+		assert.Equal(t, uint64(1), store.Version())
+		assert.False(t, fired)
+
+		// The store keeps working after a failed delta
+		require.NoError(t, store.Apply(NewStateDeltaEvent(ops[:3])))
+		assert.Equal(t, map[string]any{
+			"a":    float64(2),
+			"b":    map[string]any{},
+			"list": []any{"x", "y"},
+		}, store.Current())
+		assert.True(t, fired)
+	})
+}
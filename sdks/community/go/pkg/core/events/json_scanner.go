@@ -0,0 +1,302 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/jsonpointer"
+)
+
+// jsonScanner checks a JSON document that arrives in fragments against the
+// JSON grammar, one byte at a time, so that errors are reported by the write
+// of the offending fragment. Fragments may split the document anywhere,
+// including inside a token or an escape sequence. The document must be a
+// JSON object or array. It backs both PartialJSONParser and
+// ProgressiveJSONValidator.
+type jsonScanner struct {
+	data []byte
+
+	// stack holds the objects and arrays being scanned, outermost first
+	stack []jsonScanFrame
+
+	// start is the offset of the string or literal being scanned, which is
+	// a string if inString is set
+	start     int
+	inString  bool
+	inLiteral bool
+	done      bool
+
+	// escaped is set after a backslash in a string, and hexDigits counts
+	// the digits of a \u escape still expected
+	escaped   bool
+	hexDigits int
+
+	// onValue, if set, is called with the span of every complete value,
+	// while pointer still locates it
+	onValue func(start, end int)
+
+	// err is the first syntax error, after which writes are rejected
+	err error
+}
+
+// jsonScanFrame is an object or array being scanned
+type jsonScanFrame struct {
+	array  bool
+	start  int
+	key    string // key of the current member of an object
+	index  int    // index of the current element of an array
+	expect jsonScanExpect
+}
+
+// jsonScanExpect is what a frame expects next
+type jsonScanExpect int
+
+const (
+	expectFirstKey   jsonScanExpect = iota // a key or '}'
+	expectKey                              // a key, after a comma
+	expectColon                            // ':' after a key
+	expectValue                            // a member value or an element
+	expectFirstValue                       // an element or ']'
+	expectComma                            // ',' or the closing bracket
+)
+
+// write appends the next fragment of the document. Its error is sticky.
+func (s *jsonScanner) write(delta string) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	offset := len(s.data)
+	s.data = append(s.data, delta...)
+
+	for i := offset; i < len(s.data); i++ {
+		if err := s.scan(i); err != nil {
+			s.err = fmt.Errorf("invalid JSON at offset %d: %w", i, err)
+			return s.err
+		}
+	}
+	return nil
+}
+
+// scan advances the scanner by the byte at offset i
+func (s *jsonScanner) scan(i int) error {
+	c := s.data[i]
+
+	if s.inString {
+		return s.scanString(c, i)
+	}
+
+	if s.inLiteral {
+		if isJSONLiteralByte(c) {
+			return nil
+		}
+		s.inLiteral = false
+		if !json.Valid(s.data[s.start:i]) {
+			return fmt.Errorf("invalid literal %q", s.data[s.start:i])
+		}
+		s.complete(s.start, i)
+	}
+
+	if isJSONWhitespace(c) {
+		return nil
+	}
+	if s.done {
+		return fmt.Errorf("unexpected %q after end of document", c)
+	}
+	if len(s.stack) == 0 {
+		if c != '{' && c != '[' {
+			return fmt.Errorf("document must start with '{' or '[', found %q", c)
+		}
+		s.open(c == '[', i)
+		return nil
+	}
+
+	top := &s.stack[len(s.stack)-1]
+	switch top.expect {
+	case expectFirstKey, expectKey:
+		switch {
+		case c == '"':
+			s.inString, s.start = true, i
+		case c == '}' && top.expect == expectFirstKey:
+			s.close(i)
+		default:
+			return fmt.Errorf("unexpected %q, expected object key", c)
+		}
+
+	case expectColon:
+		if c != ':' {
+			return fmt.Errorf("unexpected %q, expected ':'", c)
+		}
+		top.expect = expectValue
+
+	case expectValue, expectFirstValue:
+		switch {
+		case c == '{' || c == '[':
+			s.open(c == '[', i)
+		case c == '"':
+			s.inString, s.start = true, i
+		case c == '-' || (c >= '0' && c <= '9') || c == 't' || c == 'f' || c == 'n':
+			s.inLiteral, s.start = true, i
+		case c == ']' && top.expect == expectFirstValue:
+			s.close(i)
+		default:
+			return fmt.Errorf("unexpected %q, expected value", c)
+		}
+
+	case expectComma:
+		switch {
+		case c == ',' && top.array:
+			top.index++
+			top.expect = expectValue
+		case c == ',':
+			top.expect = expectKey
+		case (c == ']' && top.array) || (c == '}' && !top.array):
+			s.close(i)
+		case top.array:
+			return fmt.Errorf("unexpected %q, expected ',' or ']'", c)
+		default:
+			return fmt.Errorf("unexpected %q, expected ',' or '}'", c)
+		}
+	}
+	return nil
+}
+
+// scanString advances the scanner by a byte of a string at offset i
+func (s *jsonScanner) scanString(c byte, i int) error {
+	switch {
+	case s.hexDigits > 0:
+		if !isHexDigit(c) {
+			return fmt.Errorf("invalid character %q in \\u escape", c)
+		}
+		s.hexDigits--
+	case s.escaped:
+		s.escaped = false
+		switch c {
+		case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+		case 'u':
+			s.hexDigits = 4
+		default:
+			return fmt.Errorf("invalid escape %q in string", `\`+string(c))
+		}
+	case c == '\\':
+		s.escaped = true
+	case c == '"':
+		s.inString = false
+		return s.endString(i + 1)
+	case c < 0x20:
+		return fmt.Errorf("control character %q in string", c)
+	}
+	return nil
+}
+
+// endString handles the closing quote of a key or a string value
+func (s *jsonScanner) endString(end int) error {
+	top := &s.stack[len(s.stack)-1]
+	if top.expect != expectFirstKey && top.expect != expectKey {
+		s.complete(s.start, end)
+		return nil
+	}
+
+	var key string
+	if err := json.Unmarshal(s.data[s.start:end], &key); err != nil {
+		return fmt.Errorf("invalid object key: %w", err)
+	}
+	top.key = key
+	top.expect = expectColon
+	return nil
+}
+
+// open starts an object or array at offset i
+func (s *jsonScanner) open(array bool, i int) {
+	expect := expectFirstKey
+	if array {
+		expect = expectFirstValue
+	}
+	s.stack = append(s.stack, jsonScanFrame{array: array, start: i, expect: expect})
+}
+
+// close ends the innermost object or array with the bracket at offset i
+func (s *jsonScanner) close(i int) {
+	start := s.stack[len(s.stack)-1].start
+	s.stack = s.stack[:len(s.stack)-1]
+	s.complete(start, i+1)
+}
+
+// complete records that a value ending at offset end is complete
+func (s *jsonScanner) complete(start, end int) {
+	if s.onValue != nil {
+		s.onValue(start, end)
+	}
+	if len(s.stack) == 0 {
+		s.done = true
+		return
+	}
+	s.stack[len(s.stack)-1].expect = expectComma
+}
+
+// pointer returns the JSON pointer of the value being scanned
+func (s *jsonScanner) pointer() string {
+	var b strings.Builder
+	for _, frame := range s.stack {
+		b.WriteByte('/')
+		if frame.array {
+			b.WriteString(strconv.Itoa(frame.index))
+		} else {
+			b.WriteString(jsonpointer.Escape(frame.key))
+		}
+	}
+	return b.String()
+}
+
+// started reports whether the outermost object or array has been opened
+func (s *jsonScanner) started() bool {
+	return s.done || len(s.stack) > 0
+}
+
+// isComplete reports whether the outermost object or array has been closed
+// without error
+func (s *jsonScanner) isComplete() bool {
+	return s.err == nil && s.done
+}
+
+// final returns the complete document, without surrounding whitespace. It
+// fails if the document is incomplete or invalid.
+func (s *jsonScanner) final() (json.RawMessage, error) {
+	switch {
+	case s.err != nil:
+		return nil, s.err
+	case !s.started():
+		return nil, fmt.Errorf("incomplete JSON: document is empty")
+	case s.inString:
+		return nil, fmt.Errorf("incomplete JSON: unterminated string")
+	case !s.done:
+		return nil, fmt.Errorf("incomplete JSON: %d unclosed object or array", len(s.stack))
+	}
+
+	document := bytes.TrimSpace(s.data)
+	if !json.Valid(document) {
+		var value any
+		err := json.Unmarshal(document, &value)
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return json.RawMessage(append([]byte(nil), document...)), nil
+}
+
+// isJSONWhitespace reports whether c is insignificant whitespace in JSON
+func isJSONWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// isJSONLiteralByte reports whether c can be part of a number, true, false
+// or null
+func isJSONLiteralByte(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || c == '-' || c == '+' || c == '.' || c == 'E'
+}
+
+// isHexDigit reports whether c is a hexadecimal digit
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,99 @@
+package events
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// EventLogger is the logging interface used by the events package, so that
+// callers can plug in the logging backend their service already uses
+type EventLogger interface {
+	Debug(msg string, fields map[string]any)
+	Info(msg string, fields map[string]any)
+	Warn(msg string, fields map[string]any)
+	Error(msg string, fields map[string]any)
+}
+
+// LogrusEventLogger adapts a logrus logger to EventLogger
+type LogrusEventLogger struct {
+	Logger *logrus.Logger
+}
+
+// NewLogrusEventLogger wraps a logrus logger. A nil logger is replaced by a
+// new logrus logger with default settings.
+func NewLogrusEventLogger(logger *logrus.Logger) *LogrusEventLogger {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &LogrusEventLogger{Logger: logger}
+}
+
+// Debug logs a message at debug level
+func (l *LogrusEventLogger) Debug(msg string, fields map[string]any) {
+	l.Logger.WithFields(fields).Debug(msg)
+}
+
+// Info logs a message at info level
+func (l *LogrusEventLogger) Info(msg string, fields map[string]any) {
+	l.Logger.WithFields(fields).Info(msg)
+}
+
+// Warn logs a message at warning level
+func (l *LogrusEventLogger) Warn(msg string, fields map[string]any) {
+	l.Logger.WithFields(fields).Warn(msg)
+}
+
+// Error logs a message at error level
+func (l *LogrusEventLogger) Error(msg string, fields map[string]any) {
+	l.Logger.WithFields(fields).Error(msg)
+}
+
+// EventLogFields returns the fields that identify an event in log output:
+// type, run_id, thread_id, id and timestamp. Fields that the event does not
+// carry are omitted.
+func EventLogFields(event Event) map[string]any {
+	fields := map[string]any{"type": string(event.Type())}
+
+	if runID := event.RunID(); runID != "" {
+		fields["run_id"] = runID
+	}
+	if threadID := event.ThreadID(); threadID != "" {
+		fields["thread_id"] = threadID
+	}
+	if id := eventLogID(event); id != "" {
+		fields["id"] = id
+	}
+	if ts := event.Timestamp(); ts != nil {
+		fields["timestamp"] = *ts
+	}
+
+	return fields
+}
+
+// eventLogID returns the message or tool call ID an event refers to
+func eventLogID(event Event) string {
+	switch e := event.(type) {
+	case *TextMessageStartEvent:
+		return e.MessageID
+	case *TextMessageContentEvent:
+		return e.MessageID
+	case *TextMessageEndEvent:
+		return e.MessageID
+	case *TextMessageChunkEvent:
+		if e.MessageID != nil {
+			return *e.MessageID
+		}
+	case *ToolCallStartEvent:
+		return e.ToolCallID
+	case *ToolCallArgsEvent:
+		return e.ToolCallID
+	case *ToolCallEndEvent:
+		return e.ToolCallID
+	case *ToolCallResultEvent:
+		return e.ToolCallID
+	case *ToolCallChunkEvent:
+		if e.ToolCallID != nil {
+			return *e.ToolCallID
+		}
+	}
+	return ""
+}
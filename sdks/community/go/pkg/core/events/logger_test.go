@@ -0,0 +1,64 @@
+package events
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type loggedEntry struct {
+	msg    string
+	fields map[string]any
+}
+
+// recordingEventLogger is an EventLogger that keeps every entry in memory
+type recordingEventLogger struct {
+	entries []loggedEntry
+}
+
+func (l *recordingEventLogger) log(level, msg string, fields map[string]any) {
+	l.entries = append(l.entries, loggedEntry{msg: level + ": " + msg, fields: fields})
+}
+
+func (l *recordingEventLogger) Debug(msg string, fields map[string]any) { l.log("debug", msg, fields) }
+func (l *recordingEventLogger) Info(msg string, fields map[string]any)  { l.log("info", msg, fields) }
+func (l *recordingEventLogger) Warn(msg string, fields map[string]any)  { l.log("warn", msg, fields) }
+func (l *recordingEventLogger) Error(msg string, fields map[string]any) { l.log("error", msg, fields) }
+
+func TestLogrusEventLogger(t *testing.T) {
+	var buf bytes.Buffer
+	base := logrus.New()
+	base.SetOutput(&buf)
+	base.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true})
+
+	var logger EventLogger = NewLogrusEventLogger(base)
+	logger.Warn("event dropped", map[string]any{"type": "RUN_STARTED"})
+	logger.Debug("not shown at info level", nil)
+
+	assert.Contains(t, buf.String(), `level=warning msg="event dropped" type=RUN_STARTED`)
+	assert.NotContains(t, buf.String(), "not shown")
+	assert.NotNil(t, NewLogrusEventLogger(nil).Logger)
+}
+
+func TestEventLogFields(t *testing.T) {
+	started := NewRunStartedEvent("thread-1", "run-1")
+	started.SetTimestamp(1000)
+	assert.Equal(t, map[string]any{
+		"type":      "RUN_STARTED",
+		"run_id":    "run-1",
+		"thread_id": "thread-1",
+		"timestamp": int64(1000),
+	}, EventLogFields(started))
+
+	content := NewTextMessageContentEvent("msg-1", "Hello")
+	content.TimestampMs = nil
+	assert.Equal(t, map[string]any{"type": "TEXT_MESSAGE_CONTENT", "id": "msg-1"}, EventLogFields(content))
+
+	args := NewToolCallArgsEvent("tool-1", "{}")
+	assert.Equal(t, "tool-1", EventLogFields(args)["id"])
+
+	step := NewStepStartedEvent("plan")
+	assert.NotContains(t, EventLogFields(step), "id")
+}
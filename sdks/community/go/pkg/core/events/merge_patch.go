@@ -0,0 +1,237 @@
+package events
+
+import (
+	"fmt"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/jsonwalk"
+)
+
+// MergePatchEventName is the name of the custom event that carries an RFC
+// 7386 JSON Merge Patch of the state, for producers that find merge patches
+// easier to build than operation lists. StateStore applies these events like
+// state deltas.
+const MergePatchEventName = "state/merge-patch"
+
+// NewMergePatchEvent creates a custom event carrying a merge patch of the
+// state
+func NewMergePatchEvent(patch any) *CustomEvent {
+	return NewCustomEvent(MergePatchEventName, WithValue(patch))
+}
+
+// MergePatchFromEvent returns the merge patch carried by an event, if it is
+// a merge patch event
+func MergePatchFromEvent(e Event) (any, bool) {
+	custom, ok := e.(*CustomEvent)
+	if !ok || custom.Name != MergePatchEventName {
+		return nil, false
+	}
+	return custom.Value, true
+}
+
+// ApplyMergePatch applies an RFC 7386 JSON Merge Patch to a document and
+// returns the result. An object patch merges into the document member by
+// member: null members delete the target member, object members are merged
+// recursively and other members replace the target member. Any other patch,
+// including an array, replaces the document. Neither input is modified.
+// Values that cannot be represented as JSON are used as they are.
+func ApplyMergePatch(doc, patch any) any {
+	return mergePatch(jsonValueOrSelf(doc), jsonValueOrSelf(patch))
+}
+
+// mergeTask is a pending step of mergePatch and diffMergePatch: the values
+// to merge or compare, and the object member receiving the result, or none
+// for the result as a whole. Nested objects are handled with a stack of
+// tasks rather than recursion, so deep documents cannot exhaust the stack.
+type mergeTask struct {
+	from, to any
+	parent   map[string]any
+	key      string
+}
+
+// store sets the result of the task, or returns it as the result as a whole
+func (t mergeTask) store(value any, result *any) {
+	if t.parent == nil {
+		*result = value
+		return
+	}
+	t.parent[t.key] = value
+}
+
+// mergePatch applies a generic JSON merge patch to a generic JSON document
+// that it may share but does not modify
+func mergePatch(doc, patch any) any {
+	var result any
+	tasks := []mergeTask{{from: doc, to: patch}}
+	for len(tasks) > 0 {
+		task := tasks[len(tasks)-1]
+		tasks = tasks[:len(tasks)-1]
+
+		patchObj, ok := task.to.(map[string]any)
+		if !ok {
+			task.store(task.to, &result)
+			continue
+		}
+
+		merged := map[string]any{}
+		if docObj, ok := task.from.(map[string]any); ok {
+			merged = cloneJSONObject(docObj)
+		}
+		for key, value := range patchObj {
+			if value == nil {
+				delete(merged, key)
+				continue
+			}
+			tasks = append(tasks, mergeTask{from: merged[key], to: value, parent: merged, key: key})
+		}
+		task.store(merged, &result)
+	}
+	return result
+}
+
+// DiffMergePatch computes the RFC 7386 JSON Merge Patch that transforms
+// before into after. Changed object members are patched recursively, and
+// any other change replaces the value wholesale. Merge patches cannot set a
+// value to null, so null members of after are treated as absent; use
+// MergePatchFromOps to detect that case. Equal documents produce an empty
+// object.
+func DiffMergePatch(before, after any) any {
+	patch, _ := diffMergePatch(jsonValueOrSelf(before), jsonValueOrSelf(after))
+	return patch
+}
+
+// diffMergePatch computes the merge patch from before to after, and reports
+// whether it reproduces after exactly, which fails when after sets object
+// members to null
+func diffMergePatch(before, after any) (any, bool) {
+	var result any
+	exact := true
+
+	// Objects in both documents are diffed without comparing them first, so
+	// the work stays linear in the depth of the documents. The patches of
+	// unchanged objects are empty and pruned at the end, children first.
+	var nested []mergeTask
+	tasks := []mergeTask{{from: before, to: after}}
+	for len(tasks) > 0 {
+		task := tasks[len(tasks)-1]
+		tasks = tasks[:len(tasks)-1]
+
+		beforeObj, ok1 := task.from.(map[string]any)
+		afterObj, ok2 := task.to.(map[string]any)
+		if !ok1 || !ok2 {
+			exact = exact && !containsNullMember(task.to)
+			task.store(task.to, &result)
+			continue
+		}
+
+		patch := map[string]any{}
+		for key := range beforeObj {
+			if _, ok := afterObj[key]; !ok {
+				patch[key] = nil
+			}
+		}
+		for key, value := range afterObj {
+			previous, existed := beforeObj[key]
+			if value == nil {
+				if existed && previous == nil {
+					continue
+				}
+				// A null member can only be expressed as a deletion
+				exact = false
+				if existed {
+					patch[key] = nil
+				}
+				continue
+			}
+			if !existed {
+				previous = nil
+			}
+			_, fromObj := previous.(map[string]any)
+			_, toObj := value.(map[string]any)
+			if existed && !(fromObj && toObj) && jsonValuesEqual(previous, value) {
+				continue
+			}
+			tasks = append(tasks, mergeTask{from: previous, to: value, parent: patch, key: key})
+		}
+		task.store(patch, &result)
+		if task.parent != nil {
+			nested = append(nested, mergeTask{to: patch, parent: task.parent, key: task.key})
+		}
+	}
+
+	for i := len(nested) - 1; i >= 0; i-- {
+		if len(nested[i].to.(map[string]any)) == 0 {
+			delete(nested[i].parent, nested[i].key)
+		}
+	}
+	return result, exact
+}
+
+// containsNullMember reports whether an object, or an object nested in its
+// members, has a null member. Arrays are not merged but replaced as they
+// are, so nulls inside them survive a merge patch and are not reported.
+func containsNullMember(value any) bool {
+	if _, ok := value.(map[string]any); !ok {
+		return false
+	}
+
+	found := false
+	_ = jsonwalk.Walk(value, func(path []string, v any) error {
+		switch {
+		case v == nil:
+			found = true
+			return jsonwalk.SkipAll
+		case len(path) > 0:
+			if _, ok := v.([]any); ok {
+				return jsonwalk.SkipChildren
+			}
+		}
+		return nil
+	})
+	return found
+}
+
+// MergePatchToOps converts a merge patch of doc into the equivalent JSON
+// Patch operations, which is always possible given the document the merge
+// patch applies to
+func MergePatchToOps(doc, patch any) ([]JSONPatchOperation, error) {
+	from, err := normalizeJSONValue(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+	normalized, err := normalizeJSONValue(patch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %w", err)
+	}
+	return DiffState(from, mergePatch(from, normalized))
+}
+
+// MergePatchFromOps converts JSON Patch operations on doc into the
+// equivalent merge patch. Array edits become replacements of the whole
+// array. It fails if the operations fail or set an object member to null,
+// which a merge patch cannot express.
+func MergePatchFromOps(doc any, ops []JSONPatchOperation) (any, error) {
+	from, err := normalizeJSONValue(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+	to, err := applyPatch(from, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	patch, exact := diffMergePatch(from, to)
+	if !exact {
+		return nil, fmt.Errorf("cannot convert to merge patch: the result sets an object member to null")
+	}
+	return patch, nil
+}
+
+// jsonValueOrSelf returns the generic JSON form of a value, or the value
+// itself if it cannot be represented as JSON
+func jsonValueOrSelf(value any) any {
+	normalized, err := normalizeJSONValue(value)
+	if err != nil {
+		return value
+	}
+	return normalized
+}
@@ -0,0 +1,205 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mustJSON decodes a JSON literal into a generic value
+func mustJSON(t *testing.T, s string) any {
+	t.Helper()
+	var value any
+	require.NoError(t, json.Unmarshal([]byte(s), &value))
+	return value
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	// The test cases of RFC 7386 Appendix A
+	cases := []struct{ doc, patch, want string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for i, tc := range cases {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			doc := mustJSON(t, tc.doc)
+			got := ApplyMergePatch(doc, mustJSON(t, tc.patch))
+			assert.Equal(t, mustJSON(t, tc.want), got)
+			assert.Equal(t, mustJSON(t, tc.doc), doc, "the document must not be modified")
+		})
+	}
+
+	t.Run("TypedInputs", func(t *testing.T) {
+		type user struct {
+			Name string `json:"name"`
+			Age  int    `json:"age"`
+		}
+		got := ApplyMergePatch(user{Name: "Ada", Age: 36}, map[string]any{"age": 37})
+		assert.Equal(t, map[string]any{"name": "Ada", "age": 37.0}, got)
+	})
+}
+
+func TestDiffMergePatch(t *testing.T) {
+	t.Run("Examples", func(t *testing.T) {
+		before := mustJSON(t, `{"title":"Draft","author":{"name":"Ada","email":"ada@example.com"},"tags":["a","b"]}`)
+		after := mustJSON(t, `{"title":"Final","author":{"name":"Ada"},"tags":["a"],"published":true}`)
+		assert.Equal(t, mustJSON(t, `{"title":"Final","author":{"email":null},"tags":["a"],"published":true}`),
+			DiffMergePatch(before, after))
+		assert.Equal(t, map[string]any{}, DiffMergePatch(before, before))
+		assert.Equal(t, "x", DiffMergePatch(before, "x"))
+	})
+
+	t.Run("RoundTripWithoutArrays", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(7386))
+		for i := 0; i < 500; i++ {
+			before, after := randomObject(rng, 3), randomObject(rng, 3)
+
+			patch := DiffMergePatch(before, after)
+			assert.Equal(t, after, ApplyMergePatch(before, patch))
+
+			// Merge patch to operations and back
+			ops, err := MergePatchToOps(before, patch)
+			require.NoError(t, err)
+			patched, err := ApplyPatch(before, ops)
+			require.NoError(t, err)
+			assert.Equal(t, after, patched)
+
+			again, err := MergePatchFromOps(before, ops)
+			require.NoError(t, err)
+			assert.Equal(t, after, ApplyMergePatch(before, again))
+		}
+	})
+}
+
+func TestMergePatchFromOps(t *testing.T) {
+	doc := mustJSON(t, `{"a":{"b":1},"list":[1,2,3]}`)
+
+	t.Run("ArrayEditsReplaceArrays", func(t *testing.T) {
+		patch, err := MergePatchFromOps(doc, []JSONPatchOperation{
+			{Op: "remove", Path: "/list/1"},
+			{Op: "add", Path: "/a/c", Value: "x"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, mustJSON(t, `{"a":{"c":"x"},"list":[1,3]}`), patch)
+	})
+
+	t.Run("NullMembersCannotBeExpressed", func(t *testing.T) {
+		_, err := MergePatchFromOps(doc, []JSONPatchOperation{{Op: "replace", Path: "/a/b", Value: nil}})
+		assert.Error(t, err)
+
+		// Nulls inside arrays survive, since arrays are replaced as they are
+		patch, err := MergePatchFromOps(doc, []JSONPatchOperation{{Op: "add", Path: "/list/-", Value: nil}})
+		require.NoError(t, err)
+		assert.Equal(t, mustJSON(t, `{"list":[1,2,3,null]}`), patch)
+	})
+
+	t.Run("FailingOperations", func(t *testing.T) {
+		_, err := MergePatchFromOps(doc, []JSONPatchOperation{{Op: "remove", Path: "/missing"}})
+		var patchErr *PatchError
+		assert.ErrorAs(t, err, &patchErr)
+	})
+}
+
+func TestMergePatchEvents(t *testing.T) {
+	event := NewMergePatchEvent(map[string]any{"status": "done", "draft": nil})
+	require.NoError(t, event.Validate())
+	assert.Equal(t, "state", event.Namespace())
+
+	patch, ok := MergePatchFromEvent(event)
+	require.True(t, ok)
+	assert.Equal(t, map[string]any{"status": "done", "draft": nil}, patch)
+	_, ok = MergePatchFromEvent(NewCustomEvent("other"))
+	assert.False(t, ok)
+
+	t.Run("StateStore", func(t *testing.T) {
+		store := NewStateStore()
+		assert.Error(t, store.Apply(event), "merge patch before the first snapshot")
+
+		store.ApplySnapshot(NewStateSnapshotEvent(map[string]any{"status": "running", "draft": true, "n": 1}))
+		changes := recordChanges(t, store, "/status")
+
+		require.NoError(t, store.Apply(event))
+		assert.Equal(t, map[string]any{"status": "done", "n": 1.0}, store.Current())
+		assert.Equal(t, uint64(2), store.Version())
+		assert.Equal(t, []stateChange{{Old: "running", New: "done"}}, *changes)
+
+		// A patch that changes nothing leaves the version alone
+		require.NoError(t, store.Apply(NewMergePatchEvent(map[string]any{"n": 1})))
+		assert.Equal(t, uint64(2), store.Version())
+	})
+
+	t.Run("RoundTripsThroughJSON", func(t *testing.T) {
+		data, err := event.ToJSON()
+		require.NoError(t, err)
+		decoded, err := EventFromJSON(data)
+		require.NoError(t, err)
+		patch, ok := MergePatchFromEvent(decoded)
+		require.True(t, ok)
+		assert.Equal(t, map[string]any{"status": "done", "draft": nil}, patch)
+	})
+}
+
+// randomObject builds a random JSON object with randomJSONValue, without
+// the arrays and null members a merge patch cannot express
+func randomObject(rng *rand.Rand, depth int) map[string]any {
+	for {
+		if obj, ok := randomJSONValue(rng, depth).(map[string]any); ok {
+			makeMergeable(obj)
+			return obj
+		}
+	}
+}
+
+// makeMergeable deletes the array and null members of an object at every
+// level, and turns integers into float64 as decoding JSON would
+func makeMergeable(obj map[string]any) {
+	for key, value := range obj {
+		switch v := value.(type) {
+		case nil, []any:
+			delete(obj, key)
+		case int:
+			obj[key] = float64(v)
+		case map[string]any:
+			makeMergeable(v)
+		}
+	}
+}
+
+func TestMergePatch_DeepNesting(t *testing.T) {
+	// Recursive merging needs well over 1 MiB of stack for these documents
+	// and would crash the test binary
+	const depth = 200_000
+	defer debug.SetMaxStack(debug.SetMaxStack(1 << 20))
+
+	doc, target := nestedValue(depth), nestedValue(depth)
+	patch, exact := diffMergePatch(doc, target)
+	assert.True(t, exact)
+	assert.Equal(t, map[string]any{}, patch)
+
+	deepestObject(target)["child"] = "changed"
+	patch, exact = diffMergePatch(doc, target)
+	assert.True(t, exact)
+	assert.Equal(t, "changed", deepestObject(patch)["child"])
+
+	merged := mergePatch(doc, patch)
+	assert.Equal(t, "changed", deepestObject(merged)["child"])
+	assert.Equal(t, "leaf", deepestObject(doc)["child"], "the document is not modified")
+}
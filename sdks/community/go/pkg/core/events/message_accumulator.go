@@ -0,0 +1,299 @@
+package events
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PartialMessage is a message that is still being streamed
+type PartialMessage struct {
+	ID      string
+	Role    string
+	Content string
+
+	// Started reports whether the start event of the message has arrived.
+	// It is false for content buffered with BufferEarlyContent, whose role
+	// is not known yet.
+	Started bool
+}
+
+// MessageAccumulator reconstructs messages from their streaming events. It
+// follows any number of interleaved TEXT_MESSAGE_START, CONTENT and END
+// lifecycles, as well as TEXT_MESSAGE_CHUNK streams, which carry a message
+// ID and deltas without start or end events. A chunked message is completed
+// by the first chunk with a different ID, by RUN_FINISHED or RUN_ERROR, or
+// by Flush. It is not safe for concurrent use.
+type MessageAccumulator struct {
+	bufferEarlyContent bool
+	onDelta            func(messageID, appended string, fullLen int)
+	onEdit             func(messageID string, edit TextEdit, fullLen int)
+
+	open      map[string]*openMessage
+	chunkID   string
+	completed []Message
+
+	// starts counts the messages started so far, numbering them in the
+	// order they started
+	starts int
+}
+
+// openMessage is a message that has started, or received buffered content,
+// but not ended
+type openMessage struct {
+	role    string
+	started bool
+	chunked bool
+	content strings.Builder
+
+	// startSeq numbers the message among the started ones
+	startSeq int
+}
+
+// MessageAccumulatorOption defines options for creating message accumulators
+type MessageAccumulatorOption func(*MessageAccumulator)
+
+// BufferEarlyContent makes the accumulator keep content that arrives before
+// the start event of its message, instead of failing. The buffered content
+// is prepended to the message once it starts.
+func BufferEarlyContent() MessageAccumulatorOption {
+	return func(a *MessageAccumulator) {
+		a.bufferEarlyContent = true
+	}
+}
+
+// OnDelta registers a function called whenever text is appended to a
+// message, with the appended text and the length in bytes of the content
+// once appended, so that live renderers can append it instead of reading the
+// whole content. It is called for content events and chunks with a
+// non-empty delta, including content buffered with BufferEarlyContent, and
+// for text edits appending at the end of the content. Other edits are
+// reported to the OnEdit function instead, which renderers need to stay in
+// sync with messages that are edited.
+func OnDelta(fn func(messageID, appended string, fullLen int)) MessageAccumulatorOption {
+	return func(a *MessageAccumulator) {
+		a.onDelta = fn
+	}
+}
+
+// OnEdit registers a function called whenever a text edit other than an
+// append at the end changes the content of a message, with the edit and the
+// length in bytes of the content once edited. Renderers can apply the edit
+// to the text they display, or read the content again from Open.
+func OnEdit(fn func(messageID string, edit TextEdit, fullLen int)) MessageAccumulatorOption {
+	return func(a *MessageAccumulator) {
+		a.onEdit = fn
+	}
+}
+
+// NewMessageAccumulator creates a new message accumulator
+func NewMessageAccumulator(options ...MessageAccumulatorOption) *MessageAccumulator {
+	a := &MessageAccumulator{
+		open: make(map[string]*openMessage),
+	}
+
+	for _, opt := range options {
+		opt(a)
+	}
+
+	return a
+}
+
+// Process records the next event. Content events append their delta, or
+// apply their edit to the content received so far. Events that are not part
+// of a text message are ignored, except RUN_FINISHED and RUN_ERROR, which
+// complete the current chunked message. A start event without a role
+// produces an assistant message, as does a chunked message whose chunks
+// carry no role.
+func (a *MessageAccumulator) Process(event Event) error {
+	switch e := event.(type) {
+	case *TextMessageStartEvent:
+		msg, ok := a.open[e.MessageID]
+		switch {
+		case !ok:
+			msg = &openMessage{}
+			a.open[e.MessageID] = msg
+		case msg.started:
+			return fmt.Errorf("message %s already started", e.MessageID)
+		}
+		a.start(msg)
+		msg.role = RoleAssistant
+		if e.Role != nil {
+			msg.role = *e.Role
+		}
+
+	case *TextMessageContentEvent:
+		msg, ok := a.open[e.MessageID]
+		if !ok || msg.chunked {
+			if !a.bufferEarlyContent || ok {
+				return fmt.Errorf("message %s received content before start", e.MessageID)
+			}
+			msg = &openMessage{}
+			a.open[e.MessageID] = msg
+		}
+		if e.Edit == nil {
+			a.appendContent(e.MessageID, msg, e.Delta)
+			break
+		}
+		if e.Edit.Offset == msg.content.Len() && e.Edit.Delete == 0 {
+			a.appendContent(e.MessageID, msg, e.Edit.Insert)
+			break
+		}
+		edited, err := e.Edit.Apply(msg.content.String())
+		if err != nil {
+			return fmt.Errorf("message %s: %w", e.MessageID, err)
+		}
+		msg.content.Reset()
+		msg.content.WriteString(edited)
+		if a.onEdit != nil {
+			a.onEdit(e.MessageID, *e.Edit, msg.content.Len())
+		}
+
+	case *TextMessageEndEvent:
+		msg, ok := a.open[e.MessageID]
+		if !ok || !msg.started || msg.chunked {
+			return fmt.Errorf("message %s ended before start", e.MessageID)
+		}
+		a.complete(e.MessageID, msg)
+
+	case *TextMessageChunkEvent:
+		return a.processChunk(e)
+
+	case *RunFinishedEvent, *RunErrorEvent:
+		a.Flush()
+	}
+
+	return nil
+}
+
+// processChunk records a TEXT_MESSAGE_CHUNK event. Chunks without a message
+// ID continue the current chunked message.
+func (a *MessageAccumulator) processChunk(e *TextMessageChunkEvent) error {
+	id := a.chunkID
+	if e.MessageID != nil && *e.MessageID != "" {
+		id = *e.MessageID
+	}
+	if id == "" {
+		return fmt.Errorf("message chunk without a message ID")
+	}
+	if id != a.chunkID {
+		a.Flush()
+	}
+
+	msg, ok := a.open[id]
+	if !ok {
+		msg = &openMessage{role: RoleAssistant, chunked: true}
+		a.start(msg)
+		a.open[id] = msg
+		a.chunkID = id
+	} else if !msg.chunked {
+		return fmt.Errorf("message %s received a chunk while streamed with start and end events", id)
+	}
+
+	if e.Role != nil {
+		msg.role = *e.Role
+	}
+	if e.Delta != nil {
+		a.appendContent(id, msg, *e.Delta)
+	}
+	return nil
+}
+
+// start marks an open message as started
+func (a *MessageAccumulator) start(msg *openMessage) {
+	msg.started = true
+	msg.startSeq = a.starts
+	a.starts++
+}
+
+// appendContent appends text to the content of a message and reports it to
+// the OnDelta function
+func (a *MessageAccumulator) appendContent(id string, msg *openMessage, text string) {
+	if text == "" {
+		return
+	}
+	msg.content.WriteString(text)
+	if a.onDelta != nil {
+		a.onDelta(id, text, msg.content.Len())
+	}
+}
+
+// Flush completes the current chunked message, if any. Call it at the end of
+// a stream that does not close with RUN_FINISHED or RUN_ERROR.
+func (a *MessageAccumulator) Flush() {
+	if a.chunkID == "" {
+		return
+	}
+	a.complete(a.chunkID, a.open[a.chunkID])
+}
+
+// complete moves an open message to the completed ones
+func (a *MessageAccumulator) complete(id string, msg *openMessage) {
+	delete(a.open, id)
+	if id == a.chunkID {
+		a.chunkID = ""
+	}
+
+	content := msg.content.String()
+	a.completed = append(a.completed, Message{ID: id, Role: msg.role, Content: &content})
+}
+
+// Messages returns copies of the completed messages, in the order in which
+// they were completed
+func (a *MessageAccumulator) Messages() []Message {
+	return cloneMessages(a.completed)
+}
+
+// Open returns the messages that have not been completed yet, by ID
+func (a *MessageAccumulator) Open() map[string]PartialMessage {
+	open := make(map[string]PartialMessage, len(a.open))
+	for id, msg := range a.open {
+		open[id] = PartialMessage{
+			ID:      id,
+			Role:    msg.role,
+			Content: msg.content.String(),
+			Started: msg.started,
+		}
+	}
+	return open
+}
+
+// completedCount returns the number of completed messages
+func (a *MessageAccumulator) completedCount() int {
+	return len(a.completed)
+}
+
+// completedMessage returns the completed message at index i, in completion
+// order, without copying it
+func (a *MessageAccumulator) completedMessage(i int) Message {
+	return a.completed[i]
+}
+
+// currentChunkID returns the ID of the chunked message being streamed, or ""
+func (a *MessageAccumulator) currentChunkID() string {
+	return a.chunkID
+}
+
+// partialMessage returns the open message with the given ID
+func (a *MessageAccumulator) partialMessage(id string) (PartialMessage, bool) {
+	msg, ok := a.open[id]
+	if !ok {
+		return PartialMessage{}, false
+	}
+	return PartialMessage{ID: id, Role: msg.role, Content: msg.content.String(), Started: msg.started}, true
+}
+
+// startedOpenIDs returns the IDs of the open messages that have started, in
+// the order they started
+func (a *MessageAccumulator) startedOpenIDs() []string {
+	var ids []string
+	for id, msg := range a.open {
+		if msg.started {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return a.open[ids[i]].startSeq < a.open[ids[j]].startSeq
+	})
+	return ids
+}
@@ -0,0 +1,204 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func processAll(t *testing.T, a *MessageAccumulator, events ...Event) {
+	t.Helper()
+	for _, e := range events {
+		require.NoError(t, a.Process(e))
+	}
+}
+
+func TestMessageAccumulator_Interleaved(t *testing.T) {
+	a := NewMessageAccumulator()
+
+	processAll(t, a,
+		NewTextMessageStartEvent("m1", WithRole(RoleUser)),
+		NewTextMessageStartEvent("m2"),
+		NewTextMessageContentEvent("m1", "Plan "),
+		NewTextMessageContentEvent("m2", "Sure, "),
+		NewToolCallStartEvent("call-1", "search"),
+		NewTextMessageContentEvent("m1", "a trip"),
+		NewTextMessageContentEvent("m2", "where to?"),
+	)
+
+	assert.Empty(t, a.Messages())
+	assert.Equal(t, map[string]PartialMessage{
+		"m1": {ID: "m1", Role: RoleUser, Content: "Plan a trip", Started: true},
+		"m2": {ID: "m2", Role: RoleAssistant, Content: "Sure, where to?", Started: true},
+	}, a.Open())
+
+	// Messages are returned in order of completion
+	processAll(t, a, NewTextMessageEndEvent("m2"), NewTextMessageEndEvent("m1"))
+	assert.Equal(t, []Message{
+		diffMessage("m2", RoleAssistant, "Sure, where to?"),
+		diffMessage("m1", RoleUser, "Plan a trip"),
+	}, a.Messages())
+	assert.Empty(t, a.Open())
+
+	// Messages returns copies
+	*a.Messages()[0].Content = "modified"
+	assert.Equal(t, "Sure, where to?", *a.Messages()[0].Content)
+}
+
+func TestMessageAccumulator_TextEdits(t *testing.T) {
+	edit := func(id string, offset, del int, insert string) Event {
+		return NewTextMessageContentEventWithOptions(id, "", WithTextEdit(TextEdit{Offset: offset, Delete: del, Insert: insert}))
+	}
+
+	a := NewMessageAccumulator()
+	processAll(t, a,
+		NewTextMessageStartEvent("m1"),
+		NewTextMessageContentEvent("m1", "The answer is 41"),
+		edit("m1", 14, 2, "42"),
+		NewTextMessageContentEvent("m1", "."),
+		edit("m1", 0, 0, "I think: "),
+	)
+	assert.Equal(t, "I think: The answer is 42.", a.Open()["m1"].Content)
+
+	processAll(t, a, NewTextMessageEndEvent("m1"))
+	assert.Equal(t, []Message{diffMessage("m1", RoleAssistant, "I think: The answer is 42.")}, a.Messages())
+
+	// Edits outside the content fail, and leave the content unchanged
+	processAll(t, a, NewTextMessageStartEvent("m2"), NewTextMessageContentEvent("m2", "Hi"))
+	assert.Error(t, a.Process(edit("m2", 1, 5, "")))
+	assert.Equal(t, "Hi", a.Open()["m2"].Content)
+}
+
+func TestMessageAccumulator_OnDeltaAndOnEdit(t *testing.T) {
+	type delta struct {
+		messageID string
+		appended  string
+		fullLen   int
+	}
+	var deltas []delta
+
+	// A renderer following the callbacks only
+	rendered := map[string]string{}
+	a := NewMessageAccumulator(BufferEarlyContent(),
+		OnDelta(func(messageID, appended string, fullLen int) {
+			deltas = append(deltas, delta{messageID, appended, fullLen})
+			rendered[messageID] += appended
+		}),
+		OnEdit(func(messageID string, edit TextEdit, fullLen int) {
+			edited, err := edit.Apply(rendered[messageID])
+			require.NoError(t, err)
+			assert.Len(t, edited, fullLen)
+			rendered[messageID] = edited
+		}))
+
+	processAll(t, a,
+		NewTextMessageContentEvent("m1", "Early "),
+		NewTextMessageStartEvent("m1"),
+		NewTextMessageContentEvent("m1", "héllo"),
+		NewTextMessageStartEvent("m2"),
+		NewTextMessageContentEvent("m2", "Other"),
+		NewTextMessageContentEventWithOptions("m1", "", WithTextEdit(TextEdit{Offset: 12, Insert: "!"})),
+		NewTextMessageContentEventWithOptions("m1", "", WithTextEdit(TextEdit{Offset: 0, Delete: 6})),
+		NewTextMessageEndEvent("m1"),
+		NewTextMessageChunkEvent(nil, nil, nil).WithChunkMessageID("m3").WithChunkDelta("Chunk"),
+		NewTextMessageChunkEvent(nil, nil, nil).WithChunkRole(RoleAssistant),
+		NewTextMessageChunkEvent(nil, nil, nil).WithChunkDelta("ed"),
+	)
+
+	// The edit deleting text is reported to OnEdit only
+	assert.Equal(t, []delta{
+		{"m1", "Early ", 6},
+		{"m1", "héllo", 12},
+		{"m2", "Other", 5},
+		{"m1", "!", 13},
+		{"m3", "Chunk", 5},
+		{"m3", "ed", 7},
+	}, deltas)
+	assert.Equal(t, "héllo!", *a.Messages()[0].Content)
+	assert.Equal(t, map[string]string{"m1": "héllo!", "m2": "Other", "m3": "Chunked"}, rendered)
+}
+
+func TestMessageAccumulator_Chunks(t *testing.T) {
+	chunk := func(id, role, delta string) *TextMessageChunkEvent {
+		e := NewTextMessageChunkEvent(nil, nil, nil).WithChunkDelta(delta)
+		if id != "" {
+			e = e.WithChunkMessageID(id)
+		}
+		if role != "" {
+			e = e.WithChunkRole(role)
+		}
+		return e
+	}
+
+	a := NewMessageAccumulator()
+	processAll(t, a,
+		chunk("m1", RoleUser, "Hi"),
+		chunk("", "", " there"),
+		chunk("m2", "", "Hello"),
+	)
+
+	// A chunk with a different ID completes the previous message
+	assert.Equal(t, []Message{diffMessage("m1", RoleUser, "Hi there")}, a.Messages())
+	assert.Equal(t, map[string]PartialMessage{
+		"m2": {ID: "m2", Role: RoleAssistant, Content: "Hello", Started: true},
+	}, a.Open())
+
+	// The end of the run completes the last one
+	processAll(t, a, chunk("m2", "", "!"), NewRunFinishedEvent("thread-1", "run-1"))
+	assert.Equal(t, []Message{
+		diffMessage("m1", RoleUser, "Hi there"),
+		diffMessage("m2", RoleAssistant, "Hello!"),
+	}, a.Messages())
+	assert.Empty(t, a.Open())
+
+	// Flush completes a message when the stream ends without a run event
+	processAll(t, a, chunk("m3", "", "Bye"))
+	a.Flush()
+	a.Flush()
+	assert.Len(t, a.Messages(), 3)
+	assert.Empty(t, a.Open())
+
+	// A chunk without an ID needs a current chunked message
+	assert.Error(t, a.Process(chunk("", "", "orphan")))
+
+	// A message streamed with lifecycle events cannot receive chunks
+	require.NoError(t, a.Process(NewTextMessageStartEvent("m4")))
+	assert.Error(t, a.Process(chunk("m4", "", "mixed")))
+}
+
+func TestMessageAccumulator_EarlyContent(t *testing.T) {
+	t.Run("fails by default", func(t *testing.T) {
+		a := NewMessageAccumulator()
+		assert.Error(t, a.Process(NewTextMessageContentEvent("m1", "early")))
+		assert.Empty(t, a.Open())
+	})
+
+	t.Run("buffered", func(t *testing.T) {
+		a := NewMessageAccumulator(BufferEarlyContent())
+		processAll(t, a, NewTextMessageContentEvent("m1", "Hello"))
+		assert.Equal(t, map[string]PartialMessage{"m1": {ID: "m1", Content: "Hello"}}, a.Open())
+
+		// The message cannot end before it starts
+		assert.Error(t, a.Process(NewTextMessageEndEvent("m1")))
+
+		processAll(t, a,
+			NewTextMessageStartEvent("m1", WithRole(RoleAssistant)),
+			NewTextMessageContentEvent("m1", " world"),
+			NewTextMessageEndEvent("m1"),
+		)
+		assert.Equal(t, []Message{diffMessage("m1", RoleAssistant, "Hello world")}, a.Messages())
+	})
+}
+
+func TestMessageAccumulator_Errors(t *testing.T) {
+	a := NewMessageAccumulator()
+
+	assert.Error(t, a.Process(NewTextMessageEndEvent("m1")))
+
+	require.NoError(t, a.Process(NewTextMessageStartEvent("m1")))
+	assert.Error(t, a.Process(NewTextMessageStartEvent("m1")))
+
+	// Other events are ignored
+	assert.NoError(t, a.Process(NewStepStartedEvent("step")))
+}
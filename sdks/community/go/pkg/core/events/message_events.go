@@ -3,6 +3,7 @@ package events
 import (
 	"encoding/json"
 	"fmt"
+	"unicode/utf8"
 )
 
 // TextMessageStartEvent indicates the start of a streaming text message
@@ -10,6 +11,10 @@ type TextMessageStartEvent struct {
 	*BaseEvent
 	MessageID string  `json:"messageId"`
 	Role      *string `json:"role,omitempty"`
+
+	// FunctionCallID is the ID of the LLM function call that produced this
+	// message, for tracing. It is unrelated to the IDs of running tool calls.
+	FunctionCallID *string `json:"functionCallId,omitempty"`
 }
 
 // NewTextMessageStartEvent creates a new text message start event
@@ -36,6 +41,13 @@ func WithRole(role string) TextMessageStartOption {
 	}
 }
 
+// WithFunctionCallID sets the ID of the function call that produced the message
+func WithFunctionCallID(id string) TextMessageStartOption {
+	return func(e *TextMessageStartEvent) {
+		e.FunctionCallID = &id
+	}
+}
+
 // WithAutoMessageID automatically generates a unique message ID if the provided messageID is empty
 func WithAutoMessageID() TextMessageStartOption {
 	return func(e *TextMessageStartEvent) {
@@ -52,7 +64,7 @@ func (e *TextMessageStartEvent) Validate() error {
 	}
 
 	if e.MessageID == "" {
-		return fmt.Errorf("TextMessageStartEvent validation failed: messageId field is required")
+		return newValidationError(EventTypeTextMessageStart, "messageId", "messageId field is required")
 	}
 
 	return nil
@@ -63,11 +75,73 @@ func (e *TextMessageStartEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// String returns a concise human-readable summary of the event
+func (e *TextMessageStartEvent) String() string {
+	fields := []string{summaryField("msg", e.MessageID)}
+	fields = appendOptionalField(fields, "role", e.Role)
+	return formatEvent(EventTypeTextMessageStart, fields...)
+}
+
+// Clone returns a deep copy of the event
+func (e *TextMessageStartEvent) Clone() *TextMessageStartEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	clone.Role = cloneString(e.Role)
+	clone.FunctionCallID = cloneString(e.FunctionCallID)
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *TextMessageStartEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *TextMessageStartEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeTextMessageStart, e)
+}
+
 // TextMessageContentEvent contains a piece of streaming text message content
 type TextMessageContentEvent struct {
 	*BaseEvent
 	MessageID string `json:"messageId"`
-	Delta     string `json:"delta"`
+	Delta     string `json:"delta,omitempty"`
+
+	// Edit changes the content received so far instead of appending a
+	// delta, for streaming protocols that revise earlier text. Exactly one
+	// of Delta and Edit is set.
+	Edit *TextEdit `json:"edit,omitempty"`
+}
+
+// TextEdit replaces Delete bytes of the content of a message, starting at
+// byte Offset, with Insert. Offsets count bytes of the UTF-8 content, as Go
+// strings do, and must not split a character.
+type TextEdit struct {
+	Offset int    `json:"offset"`
+	Delete int    `json:"delete,omitempty"`
+	Insert string `json:"insert,omitempty"`
+}
+
+// Apply returns content with the edit applied. It fails if the edited range
+// is outside content or splits a character.
+func (t TextEdit) Apply(content string) (string, error) {
+	end := t.Offset + t.Delete
+	if t.Offset < 0 || t.Delete < 0 || end > len(content) {
+		return "", fmt.Errorf("edit of bytes %d to %d is outside content of %d bytes", t.Offset, end, len(content))
+	}
+	if !isRuneBoundary(content, t.Offset) || !isRuneBoundary(content, end) {
+		return "", fmt.Errorf("edit of bytes %d to %d splits a character", t.Offset, end)
+	}
+	return content[:t.Offset] + t.Insert + content[end:], nil
+}
+
+// isRuneBoundary reports whether i is the start of a character of s, or its
+// end
+func isRuneBoundary(s string, i int) bool {
+	return i == len(s) || utf8.RuneStart(s[i])
 }
 
 // NewTextMessageContentEvent creates a new text message content event
@@ -97,6 +171,15 @@ func NewTextMessageContentEventWithOptions(messageID, delta string, options ...T
 // TextMessageContentOption defines options for creating text message content events
 type TextMessageContentOption func(*TextMessageContentEvent)
 
+// WithTextEdit makes the event apply edit to the content of the message
+// instead of appending a delta. The delta is cleared.
+func WithTextEdit(edit TextEdit) TextMessageContentOption {
+	return func(e *TextMessageContentEvent) {
+		e.Delta = ""
+		e.Edit = &edit
+	}
+}
+
 // WithAutoMessageIDContent automatically generates a unique message ID if the provided messageID is empty
 func WithAutoMessageIDContent() TextMessageContentOption {
 	return func(e *TextMessageContentEvent) {
@@ -113,11 +196,24 @@ func (e *TextMessageContentEvent) Validate() error {
 	}
 
 	if e.MessageID == "" {
-		return fmt.Errorf("TextMessageContentEvent validation failed: messageId field is required")
+		return newValidationError(EventTypeTextMessageContent, "messageId", "messageId field is required")
+	}
+
+	if e.Edit != nil {
+		if e.Delta != "" {
+			return newValidationError(EventTypeTextMessageContent, "edit", "delta and edit fields are mutually exclusive")
+		}
+		if e.Edit.Offset < 0 || e.Edit.Delete < 0 {
+			return newValidationError(EventTypeTextMessageContent, "edit", "edit offset and delete count must not be negative")
+		}
+		if e.Edit.Delete == 0 && e.Edit.Insert == "" {
+			return newValidationError(EventTypeTextMessageContent, "edit", "edit must delete or insert text")
+		}
+		return nil
 	}
 
 	if e.Delta == "" {
-		return fmt.Errorf("TextMessageContentEvent validation failed: delta field must not be empty")
+		return newValidationError(EventTypeTextMessageContent, "delta", "delta field must not be empty")
 	}
 
 	return nil
@@ -128,6 +224,39 @@ func (e *TextMessageContentEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// String returns a concise human-readable summary of the event
+func (e *TextMessageContentEvent) String() string {
+	if e.Edit != nil {
+		return formatEvent(EventTypeTextMessageContent, summaryField("msg", e.MessageID),
+			summaryField("edit", fmt.Sprintf("%d+%d", e.Edit.Offset, e.Edit.Delete)), summaryText("insert", e.Edit.Insert))
+	}
+	return formatEvent(EventTypeTextMessageContent, summaryField("msg", e.MessageID), summaryText("delta", e.Delta))
+}
+
+// Clone returns a deep copy of the event
+func (e *TextMessageContentEvent) Clone() *TextMessageContentEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	if e.Edit != nil {
+		edit := *e.Edit
+		clone.Edit = &edit
+	}
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *TextMessageContentEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *TextMessageContentEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeTextMessageContent, e)
+}
+
 // TextMessageEndEvent indicates the end of a streaming text message
 type TextMessageEndEvent struct {
 	*BaseEvent
@@ -175,7 +304,7 @@ func (e *TextMessageEndEvent) Validate() error {
 	}
 
 	if e.MessageID == "" {
-		return fmt.Errorf("TextMessageEndEvent validation failed: messageId field is required")
+		return newValidationError(EventTypeTextMessageEnd, "messageId", "messageId field is required")
 	}
 
 	return nil
@@ -186,6 +315,31 @@ func (e *TextMessageEndEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// String returns a concise human-readable summary of the event
+func (e *TextMessageEndEvent) String() string {
+	return formatEvent(EventTypeTextMessageEnd, summaryField("msg", e.MessageID))
+}
+
+// Clone returns a deep copy of the event
+func (e *TextMessageEndEvent) Clone() *TextMessageEndEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *TextMessageEndEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *TextMessageEndEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeTextMessageEnd, e)
+}
+
 // TextMessageChunkEvent represents a chunk of text message data
 type TextMessageChunkEvent struct {
 	*BaseEvent
@@ -230,7 +384,7 @@ func (e *TextMessageChunkEvent) Validate() error {
 
 	// At least one field should be present
 	if e.MessageID == nil && e.Role == nil && e.Delta == nil {
-		return fmt.Errorf("TextMessageChunkEvent validation failed: at least one of messageId, role, or delta must be present")
+		return newValidationError(EventTypeTextMessageChunk, "", "at least one of messageId, role, or delta must be present")
 	}
 
 	return nil
@@ -240,3 +394,37 @@ func (e *TextMessageChunkEvent) Validate() error {
 func (e *TextMessageChunkEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
+
+// String returns a concise human-readable summary of the event
+func (e *TextMessageChunkEvent) String() string {
+	var fields []string
+	fields = appendOptionalField(fields, "msg", e.MessageID)
+	fields = appendOptionalField(fields, "role", e.Role)
+	if e.Delta != nil {
+		fields = append(fields, summaryText("delta", *e.Delta))
+	}
+	return formatEvent(EventTypeTextMessageChunk, fields...)
+}
+
+// Clone returns a deep copy of the event
+func (e *TextMessageChunkEvent) Clone() *TextMessageChunkEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	clone.MessageID = cloneString(e.MessageID)
+	clone.Role = cloneString(e.Role)
+	clone.Delta = cloneString(e.Delta)
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *TextMessageChunkEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *TextMessageChunkEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeTextMessageChunk, e)
+}
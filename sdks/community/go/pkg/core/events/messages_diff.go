@@ -0,0 +1,123 @@
+package events
+
+// MessagesDiff describes how a messages snapshot differs from the previous
+// one, so that UI layers can update only the affected messages instead of
+// re-rendering the whole conversation. Messages are matched by ID.
+type MessagesDiff struct {
+	// Replace is set when the snapshots cannot be reconciled message by
+	// message, because messages were reordered, inserted before existing
+	// ones, or an ID was duplicated or reused for a message of another role.
+	// The new snapshot should then replace the old one as a whole, and the
+	// other fields are empty.
+	Replace bool
+
+	// Appended holds the new messages that follow the last kept message, in
+	// order
+	Appended []Message
+
+	// Removed holds the IDs of the old messages missing from the new
+	// snapshot, in their old order
+	Removed []string
+
+	// Changed holds the new version of every kept message whose content,
+	// name, tool calls or tool call ID changed, in order
+	Changed []Message
+}
+
+// IsEmpty reports whether the snapshots are equal
+func (d MessagesDiff) IsEmpty() bool {
+	return !d.Replace && len(d.Appended) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffMessages compares two messages snapshots. Kept messages must appear in
+// the same relative order in both, and new messages may only follow them;
+// anything else, as well as duplicate or empty IDs, produces a diff with
+// Replace set. The messages of the diff are copies, sharing nothing with the
+// inputs.
+func DiffMessages(old, new []Message) MessagesDiff {
+	replace := MessagesDiff{Replace: true}
+
+	oldIndex, ok := indexMessages(old)
+	if !ok {
+		return replace
+	}
+	if _, ok := indexMessages(new); !ok {
+		return replace
+	}
+
+	var diff MessagesDiff
+	kept := make(map[string]bool, len(new))
+	last := -1 // old index of the last kept message
+	for _, msg := range new {
+		i, exists := oldIndex[msg.ID]
+		if !exists {
+			diff.Appended = append(diff.Appended, msg)
+			continue
+		}
+
+		// A kept message after an appended one, or out of its old order,
+		// cannot be expressed as appends and removals
+		if len(diff.Appended) > 0 || i < last || old[i].Role != msg.Role {
+			return replace
+		}
+		last = i
+		kept[msg.ID] = true
+
+		if !messagesEqual(old[i], msg) {
+			diff.Changed = append(diff.Changed, msg)
+		}
+	}
+
+	for _, msg := range old {
+		if !kept[msg.ID] {
+			diff.Removed = append(diff.Removed, msg.ID)
+		}
+	}
+
+	diff.Appended = cloneMessages(diff.Appended)
+	diff.Changed = cloneMessages(diff.Changed)
+	return diff
+}
+
+// indexMessages maps message IDs to their index, failing on empty or
+// duplicate IDs
+func indexMessages(messages []Message) (map[string]int, bool) {
+	index := make(map[string]int, len(messages))
+	for i, msg := range messages {
+		if msg.ID == "" {
+			return nil, false
+		}
+		if _, duplicate := index[msg.ID]; duplicate {
+			return nil, false
+		}
+		index[msg.ID] = i
+	}
+	return index, true
+}
+
+// messagesEqual reports whether two messages have the same fields, treating
+// missing and empty tool call lists alike
+func messagesEqual(a, b Message) bool {
+	if a.ID != b.ID || a.Role != b.Role ||
+		!stringPointersEqual(a.Content, b.Content) ||
+		!stringPointersEqual(a.Name, b.Name) ||
+		!stringPointersEqual(a.ToolCallID, b.ToolCallID) ||
+		len(a.ToolCalls) != len(b.ToolCalls) {
+		return false
+	}
+	for i := range a.ToolCalls {
+		if a.ToolCalls[i] != b.ToolCalls[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// stringPointersEqual reports whether two optional strings are both missing
+// or hold the same value
+func stringPointersEqual(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,163 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func diffMessage(id, role, content string) Message {
+	return Message{ID: id, Role: role, Content: &content}
+}
+
+func TestDiffMessages(t *testing.T) {
+	history := []Message{
+		diffMessage("m1", "user", "Hi"),
+		diffMessage("m2", "assistant", "Hello! How can I help?"),
+		diffMessage("m3", "user", "Plan a trip"),
+		diffMessage("m4", "assistant", "Where to?"),
+	}
+
+	tests := []struct {
+		name     string
+		old      []Message
+		new      []Message
+		expected MessagesDiff
+	}{
+		{
+			name:     "unchanged",
+			old:      history,
+			new:      cloneMessages(history),
+			expected: MessagesDiff{},
+		},
+		{
+			name: "append only",
+			old:  history,
+			new: append(cloneMessages(history),
+				diffMessage("m5", "user", "Lisbon"),
+				diffMessage("m6", "assistant", "Great choice"),
+			),
+			expected: MessagesDiff{Appended: []Message{
+				diffMessage("m5", "user", "Lisbon"),
+				diffMessage("m6", "assistant", "Great choice"),
+			}},
+		},
+		{
+			name:     "from empty history",
+			old:      nil,
+			new:      history[:2],
+			expected: MessagesDiff{Appended: history[:2]},
+		},
+		{
+			name: "edited message mid-history",
+			old:  history,
+			new: []Message{
+				history[0],
+				diffMessage("m2", "assistant", "Hello! What can I do for you?"),
+				history[2],
+				history[3],
+			},
+			expected: MessagesDiff{Changed: []Message{
+				diffMessage("m2", "assistant", "Hello! What can I do for you?"),
+			}},
+		},
+		{
+			name: "tool calls added",
+			old:  history,
+			new: func() []Message {
+				messages := cloneMessages(history)
+				messages[3].ToolCalls = []ToolCall{{ID: "call-1", Type: "function", Function: Function{Name: "search"}}}
+				return messages
+			}(),
+			expected: MessagesDiff{Changed: []Message{{
+				ID:        "m4",
+				Role:      "assistant",
+				Content:   history[3].Content,
+				ToolCalls: []ToolCall{{ID: "call-1", Type: "function", Function: Function{Name: "search"}}},
+			}}},
+		},
+		{
+			name:     "truncated history",
+			old:      history,
+			new:      history[:2],
+			expected: MessagesDiff{Removed: []string{"m3", "m4"}},
+		},
+		{
+			name: "removed mid-history, edited and appended",
+			old:  history,
+			new: []Message{
+				history[0],
+				diffMessage("m3", "user", "Plan a trip to Lisbon"),
+				history[3],
+				diffMessage("m5", "user", "Next week"),
+			},
+			expected: MessagesDiff{
+				Appended: []Message{diffMessage("m5", "user", "Next week")},
+				Removed:  []string{"m2"},
+				Changed:  []Message{diffMessage("m3", "user", "Plan a trip to Lisbon")},
+			},
+		},
+		{
+			name:     "cleared",
+			old:      history,
+			new:      []Message{},
+			expected: MessagesDiff{Removed: []string{"m1", "m2", "m3", "m4"}},
+		},
+		{
+			name:     "reordered",
+			old:      history,
+			new:      []Message{history[1], history[0], history[2], history[3]},
+			expected: MessagesDiff{Replace: true},
+		},
+		{
+			name:     "inserted before existing message",
+			old:      history,
+			new:      []Message{history[0], diffMessage("m9", "user", "Wait"), history[1], history[2], history[3]},
+			expected: MessagesDiff{Replace: true},
+		},
+		{
+			name:     "ID reused for another role",
+			old:      history,
+			new:      []Message{history[0], history[1], history[2], diffMessage("m4", "user", "Reused")},
+			expected: MessagesDiff{Replace: true},
+		},
+		{
+			name:     "duplicate IDs",
+			old:      history,
+			new:      append(cloneMessages(history), history[0]),
+			expected: MessagesDiff{Replace: true},
+		},
+		{
+			name:     "empty ID",
+			old:      []Message{diffMessage("", "user", "Hi")},
+			new:      history,
+			expected: MessagesDiff{Replace: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := DiffMessages(tt.old, tt.new)
+			assert.Equal(t, tt.expected, diff)
+			assert.Equal(t, tt.expected.IsEmpty(), diff.IsEmpty())
+		})
+	}
+}
+
+func TestDiffMessages_Copies(t *testing.T) {
+	old := []Message{diffMessage("m1", "user", "Hi")}
+	updated := []Message{diffMessage("m1", "user", "Hi there"), diffMessage("m2", "assistant", "Hello")}
+
+	diff := DiffMessages(old, updated)
+	*diff.Changed[0].Content = "modified"
+	*diff.Appended[0].Content = "modified"
+
+	assert.Equal(t, "Hi there", *updated[0].Content)
+	assert.Equal(t, "Hello", *updated[1].Content)
+}
+
+func TestDiffMessages_NilAndEmptyToolCalls(t *testing.T) {
+	old := []Message{{ID: "m1", Role: "assistant", ToolCalls: nil}}
+	updated := []Message{{ID: "m1", Role: "assistant", ToolCalls: []ToolCall{}}}
+	assert.True(t, DiffMessages(old, updated).IsEmpty())
+}
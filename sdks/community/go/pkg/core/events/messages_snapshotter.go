@@ -0,0 +1,197 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// MessagesSnapshotter builds MESSAGES_SNAPSHOT events from streamed text
+// messages, for subscribers joining a stream that only carries deltas. It
+// feeds a MessageAccumulator with the events it passes through, and builds a
+// snapshot on demand with SnapshotNow, or injects one periodically, as
+// SnapshotPolicy does for the state.
+//
+// A snapshot holds the messages that have started so far, in the order they
+// started, after the messages of the last MESSAGES_SNAPSHOT event of the
+// stream if any. Messages still streaming are included with their partial
+// content followed by the partial message marker. Since a snapshot is built
+// from the events already passed through, it never holds content that has
+// not been streamed: a subscriber applying it and then the following events
+// reconstructs the same conversation as one that followed the whole stream,
+// provided the marker is empty or stripped. Content buffered before the start
+// of its message is left out until the message starts.
+//
+// It implements EventStage and is safe for concurrent use, so SnapshotNow
+// may be called while another goroutine processes events.
+type MessagesSnapshotter struct {
+	interval time.Duration
+	marker   string
+	now      EventClock
+
+	mu       sync.Mutex
+	messages *MessageAccumulator
+	lastTime time.Time
+
+	// base holds the messages of the last snapshot of the stream, and order
+	// the IDs of the messages started since, in the order they started
+	base    []Message
+	inBase  map[string]bool
+	order   []string
+	started map[string]bool
+
+	// completed indexes the completed messages of the accumulator by ID,
+	// up to indexed
+	completed map[string]int
+	indexed   int
+}
+
+// MessagesSnapshotterOption defines options for creating messages snapshotters
+type MessagesSnapshotterOption func(*MessagesSnapshotter)
+
+// WithMessagesSnapshotInterval injects a snapshot after the first text
+// message event processed once d has passed since the last snapshot. Zero,
+// the default, disables periodic snapshots.
+func WithMessagesSnapshotInterval(d time.Duration) MessagesSnapshotterOption {
+	return func(s *MessagesSnapshotter) {
+		s.interval = d
+	}
+}
+
+// WithPartialMessageMarker appends marker, such as "…", to the content of
+// the messages still streaming in snapshots, for subscribers displaying the
+// snapshot as it is. Subscribers resuming from the snapshot must strip it.
+func WithPartialMessageMarker(marker string) MessagesSnapshotterOption {
+	return func(s *MessagesSnapshotter) {
+		s.marker = marker
+	}
+}
+
+// NewMessagesSnapshotter creates a messages snapshotter emitting snapshots
+// on demand only unless an interval is configured
+func NewMessagesSnapshotter(opts ...MessagesSnapshotterOption) *MessagesSnapshotter {
+	s := &MessagesSnapshotter{
+		now:       time.Now,
+		messages:  NewMessageAccumulator(),
+		inBase:    make(map[string]bool),
+		started:   make(map[string]bool),
+		completed: make(map[string]int),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.lastTime = s.now()
+	return s
+}
+
+// Process implements EventStage. It returns the event, followed by a
+// snapshot that includes it when the event belongs to a text message and the
+// snapshot interval has passed. It fails if the message events are
+// inconsistent, as MessageAccumulator does.
+func (s *MessagesSnapshotter) Process(e Event) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch event := e.(type) {
+	case *MessagesSnapshotEvent:
+		s.restart(event)
+		return []Event{e}, nil
+
+	case *TextMessageStartEvent, *TextMessageContentEvent, *TextMessageEndEvent, *TextMessageChunkEvent:
+		if err := s.messages.Process(e); err != nil {
+			return nil, err
+		}
+		s.track(e)
+		if s.interval > 0 && s.now().Sub(s.lastTime) >= s.interval {
+			return []Event{e, s.snapshot()}, nil
+		}
+		return []Event{e}, nil
+
+	default:
+		// Run events complete the current chunked message
+		if err := s.messages.Process(e); err != nil {
+			return nil, err
+		}
+		return []Event{e}, nil
+	}
+}
+
+// Flush implements EventStage. The snapshotter holds no events.
+func (s *MessagesSnapshotter) Flush() []Event {
+	return nil
+}
+
+// SnapshotNow returns a snapshot of the messages streamed so far, and
+// restarts the snapshot interval
+func (s *MessagesSnapshotter) SnapshotNow() *MessagesSnapshotEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshot()
+}
+
+// restart makes a snapshot of the stream the base of the next snapshots
+func (s *MessagesSnapshotter) restart(snapshot *MessagesSnapshotEvent) {
+	s.base = cloneMessages(snapshot.Messages)
+	s.inBase = make(map[string]bool, len(s.base))
+	for _, msg := range s.base {
+		s.inBase[msg.ID] = true
+	}
+	s.order = nil
+	s.started = make(map[string]bool)
+	s.completed = make(map[string]int)
+	s.indexed = s.messages.completedCount()
+	s.lastTime = s.now()
+
+	// Messages still streaming continue after the snapshot, in the order
+	// they started
+	for _, id := range s.messages.startedOpenIDs() {
+		if !s.inBase[id] {
+			s.started[id] = true
+			s.order = append(s.order, id)
+		}
+	}
+}
+
+// track records the start of the message of an event
+func (s *MessagesSnapshotter) track(e Event) {
+	var id string
+	switch event := e.(type) {
+	case *TextMessageStartEvent:
+		id = event.MessageID
+	case *TextMessageChunkEvent:
+		id = s.messages.currentChunkID()
+	default:
+		return
+	}
+	if id != "" && !s.started[id] && !s.inBase[id] {
+		s.started[id] = true
+		s.order = append(s.order, id)
+	}
+}
+
+// snapshot builds a snapshot of the messages streamed so far
+func (s *MessagesSnapshotter) snapshot() *MessagesSnapshotEvent {
+	s.lastTime = s.now()
+
+	for i := s.indexed; i < s.messages.completedCount(); i++ {
+		s.completed[s.messages.completedMessage(i).ID] = i
+	}
+	s.indexed = s.messages.completedCount()
+
+	messages := cloneMessages(s.base)
+	for _, id := range s.order {
+		if i, ok := s.completed[id]; ok {
+			messages = append(messages, cloneMessages([]Message{s.messages.completedMessage(i)})...)
+			continue
+		}
+		if msg, ok := s.messages.partialMessage(id); ok {
+			content := msg.Content + s.marker
+			messages = append(messages, Message{ID: id, Role: msg.Role, Content: &content})
+		}
+	}
+	if messages == nil {
+		messages = []Message{}
+	}
+	return NewMessagesSnapshotEvent(messages)
+}
@@ -0,0 +1,187 @@
+package events
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conversation reconstructs the messages of a stream as a subscriber would:
+// a snapshot replaces the messages, and streamed events add to them
+func conversation(t *testing.T, events []Event) []Message {
+	t.Helper()
+	var messages []Message
+	find := func(id string) *Message {
+		for i := range messages {
+			if messages[i].ID == id {
+				return &messages[i]
+			}
+		}
+		return nil
+	}
+	appendContent := func(id, delta string) {
+		msg := find(id)
+		require.NotNil(t, msg, "content for unknown message %s", id)
+		content := stringValue(msg.Content) + delta
+		msg.Content = &content
+	}
+
+	for _, e := range events {
+		switch event := e.(type) {
+		case *MessagesSnapshotEvent:
+			messages = cloneMessages(event.Messages)
+		case *TextMessageStartEvent:
+			role := RoleAssistant
+			if event.Role != nil {
+				role = *event.Role
+			}
+			messages = append(messages, Message{ID: event.MessageID, Role: role, Content: strPtr("")})
+		case *TextMessageContentEvent:
+			appendContent(event.MessageID, event.Delta)
+		case *TextMessageChunkEvent:
+			id := *event.MessageID
+			if find(id) == nil {
+				messages = append(messages, Message{ID: id, Role: RoleAssistant, Content: strPtr("")})
+			}
+			appendContent(id, stringValue(event.Delta))
+		}
+	}
+	return messages
+}
+
+// snapshotterStream is a stream of interleaved, chunked and unfinished
+// messages, after an earlier snapshot of the conversation
+func snapshotterStream() []Event {
+	return []Event{
+		NewMessagesSnapshotEvent([]Message{diffMessage("msg-0", RoleSystem, "Be brief.")}),
+		NewRunStartedEvent("thread-1", "run-1"),
+		NewTextMessageStartEvent("msg-1", WithRole(RoleUser)),
+		NewTextMessageContentEvent("msg-1", "Weather "),
+		NewTextMessageStartEvent("msg-2"),
+		NewTextMessageContentEvent("msg-1", "in Paris?"),
+		NewTextMessageContentEvent("msg-2", "Checking"),
+		NewTextMessageEndEvent("msg-1"),
+		NewTextMessageContentEvent("msg-2", "..."),
+		NewTextMessageEndEvent("msg-2"),
+		NewTextMessageChunkEvent(nil, nil, nil).WithChunkMessageID("msg-3").WithChunkDelta("Rain "),
+		NewTextMessageChunkEvent(nil, nil, nil).WithChunkMessageID("msg-3").WithChunkDelta("today."),
+		NewTextMessageStartEvent("msg-4"),
+		NewTextMessageContentEvent("msg-4", "Anything "),
+		NewTextMessageContentEvent("msg-4", "else?"),
+	}
+}
+
+func TestMessagesSnapshotter(t *testing.T) {
+	t.Run("LateSubscriberMatchesFromStart", func(t *testing.T) {
+		stream := snapshotterStream()
+		expected := conversation(t, stream)
+		require.Len(t, expected, 5)
+
+		// Whenever the subscriber joins, the snapshot and the following
+		// events give the same conversation
+		for join := 0; join <= len(stream); join++ {
+			s := NewMessagesSnapshotter()
+			out, err := ApplyStages(stream[:join], s)
+			require.NoError(t, err)
+			assert.Len(t, out, join)
+
+			late := append([]Event{s.SnapshotNow()}, stream[join:]...)
+			assert.Equal(t, expected, conversation(t, late), "joined after %d events", join)
+		}
+	})
+
+	t.Run("PartialMessages", func(t *testing.T) {
+		s := NewMessagesSnapshotter(WithPartialMessageMarker("…"))
+		_, err := ApplyStages(snapshotterStream()[:7], s)
+		require.NoError(t, err)
+
+		assert.Equal(t, []Message{
+			diffMessage("msg-0", RoleSystem, "Be brief."),
+			diffMessage("msg-1", RoleUser, "Weather in Paris?…"),
+			diffMessage("msg-2", RoleAssistant, "Checking…"),
+		}, s.SnapshotNow().Messages)
+
+		// The marker is only added to messages still streaming
+		_, err = s.Process(NewTextMessageEndEvent("msg-1"))
+		require.NoError(t, err)
+		assert.Equal(t, "Weather in Paris?", *s.SnapshotNow().Messages[1].Content)
+	})
+
+	t.Run("StreamSnapshotKeepsStartOrder", func(t *testing.T) {
+		s := NewMessagesSnapshotter()
+		_, err := ApplyStages([]Event{
+			NewTextMessageStartEvent("msg-b", WithRole(RoleAssistant)),
+			NewTextMessageStartEvent("msg-a", WithRole(RoleAssistant)),
+			NewMessagesSnapshotEvent([]Message{diffMessage("msg-0", RoleUser, "Hi")}),
+			NewTextMessageContentEvent("msg-a", "second"),
+			NewTextMessageContentEvent("msg-b", "first"),
+		}, s)
+		require.NoError(t, err)
+
+		assert.Equal(t, []Message{
+			diffMessage("msg-0", RoleUser, "Hi"),
+			diffMessage("msg-b", RoleAssistant, "first"),
+			diffMessage("msg-a", RoleAssistant, "second"),
+		}, s.SnapshotNow().Messages)
+	})
+
+	t.Run("Interval", func(t *testing.T) {
+		now := time.Unix(0, 0)
+		s := NewMessagesSnapshotter(WithMessagesSnapshotInterval(time.Second))
+		s.now = func() time.Time { return now }
+		s.lastTime = now
+
+		out, err := ApplyStages(snapshotterStream()[:4], s)
+		require.NoError(t, err)
+		assert.Len(t, out, 4)
+
+		// Only message events are followed by a snapshot
+		now = now.Add(time.Second)
+		out, err = s.Process(NewStepStartedEvent("plan"))
+		require.NoError(t, err)
+		assert.Len(t, out, 1)
+
+		content := NewTextMessageContentEvent("msg-1", "in Paris?")
+		out, err = s.Process(content)
+		require.NoError(t, err)
+		require.Len(t, out, 2)
+		assert.Same(t, content, out[0])
+		snapshot := out[1].(*MessagesSnapshotEvent)
+		assert.Equal(t, "Weather in Paris?", *snapshot.Messages[1].Content)
+
+		// The interval restarts with each snapshot
+		out, err = s.Process(NewTextMessageEndEvent("msg-1"))
+		require.NoError(t, err)
+		assert.Len(t, out, 1)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		snapshot := NewMessagesSnapshotter().SnapshotNow()
+		assert.NotNil(t, snapshot.Messages)
+		assert.Empty(t, snapshot.Messages)
+	})
+
+	t.Run("InconsistentEvents", func(t *testing.T) {
+		_, err := NewMessagesSnapshotter().Process(NewTextMessageContentEvent("msg-1", "orphan"))
+		assert.Error(t, err)
+	})
+
+	t.Run("ConcurrentSnapshots", func(t *testing.T) {
+		s := NewMessagesSnapshotter()
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				assert.NotNil(t, s.SnapshotNow())
+			}
+		}()
+		_, err := ApplyStages(snapshotterStream(), s)
+		require.NoError(t, err)
+		wg.Wait()
+		assert.Len(t, s.SnapshotNow().Messages, 5)
+	})
+}
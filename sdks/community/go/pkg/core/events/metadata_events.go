@@ -0,0 +1,75 @@
+package events
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// MetadataEvent carries out-of-band metadata about the run, such as user
+// preferences, session configuration or feature flags. Unlike the metadata
+// of other events, it is an event of its own, so it can be routed, filtered
+// and stored independently of the run and message events.
+type MetadataEvent struct {
+	*BaseEvent
+	Data map[string]any `json:"data"`
+}
+
+// NewMetadataEvent creates a new metadata event. The event owns its data:
+// the map is deep-copied, so later changes to the caller's map do not affect
+// the event.
+func NewMetadataEvent(data map[string]any) *MetadataEvent {
+	return &MetadataEvent{
+		BaseEvent: NewBaseEvent(EventTypeMetadata),
+		Data:      cloneMetadata(data),
+	}
+}
+
+// Validate validates the metadata event
+func (e *MetadataEvent) Validate() error {
+	if err := e.BaseEvent.Validate(); err != nil {
+		return err
+	}
+
+	if e.Data == nil {
+		return newValidationError(EventTypeMetadata, "data", "data field is required")
+	}
+
+	return nil
+}
+
+// ToJSON serializes the event to JSON
+func (e *MetadataEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// String returns a concise human-readable summary of the event
+func (e *MetadataEvent) String() string {
+	keys := make([]string, 0, len(e.Data))
+	for key := range e.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return formatEvent(EventTypeMetadata, summaryText("keys", strings.Join(keys, ",")))
+}
+
+// Clone returns a deep copy of the event
+func (e *MetadataEvent) Clone() *MetadataEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	clone.Data = cloneMetadata(e.Data)
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *MetadataEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *MetadataEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeMetadata, e)
+}
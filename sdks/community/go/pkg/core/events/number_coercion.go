@@ -0,0 +1,62 @@
+package events
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+)
+
+// Number is the set of Go numeric types CoerceNumber converts to
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// CoerceNumber converts a numeric state value to T. It accepts json.Number,
+// as kept by stores created with WithPreserveNumbers, as well as float64 and
+// the other Go numeric types. Conversions to an integer type fail for values
+// with a fractional part or outside the range of T, rather than truncating.
+// Conversions to a floating-point type round to the nearest representable
+// value.
+func CoerceNumber[T Number](value any) (T, error) {
+	var zero T
+	r, ok := jsonNumber(value)
+	if !ok || r == nil {
+		return zero, fmt.Errorf("cannot convert %T to %T: not a number", value, zero)
+	}
+
+	switch kind := reflect.TypeOf(zero).Kind(); kind {
+	case reflect.Float32, reflect.Float64:
+		f, _ := r.Float64()
+		if kind == reflect.Float32 && math.Abs(f) > math.MaxFloat32 {
+			return zero, fmt.Errorf("cannot convert %s to %T: out of range", r.RatString(), zero)
+		}
+		return T(f), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if !r.IsInt() {
+			return zero, fmt.Errorf("cannot convert %s to %T: not an integer", r.RatString(), zero)
+		}
+		n := r.Num()
+		limit := new(big.Int).SetUint64(math.MaxUint64 >> (64 - reflect.TypeOf(zero).Bits()))
+		if n.Sign() < 0 || n.Cmp(limit) > 0 {
+			return zero, fmt.Errorf("cannot convert %s to %T: out of range", n, zero)
+		}
+		return T(n.Uint64()), nil
+
+	default:
+		if !r.IsInt() {
+			return zero, fmt.Errorf("cannot convert %s to %T: not an integer", r.RatString(), zero)
+		}
+		n := r.Num()
+		bits := reflect.TypeOf(zero).Bits()
+		max := new(big.Int).SetInt64(math.MaxInt64 >> (64 - bits))
+		min := new(big.Int).SetInt64(math.MinInt64 >> (64 - bits))
+		if n.Cmp(min) < 0 || n.Cmp(max) > 0 {
+			return zero, fmt.Errorf("cannot convert %s to %T: out of range", n, zero)
+		}
+		return T(n.Int64()), nil
+	}
+}
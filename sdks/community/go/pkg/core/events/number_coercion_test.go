@@ -0,0 +1,184 @@
+package events
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bigCounter is 2^60, beyond the integers float64 represents exactly once
+// incremented
+const bigCounter int64 = 1 << 60
+
+func TestPreserveNumbers(t *testing.T) {
+	t.Run("DecodedDeltaOnLargeCounter", func(t *testing.T) {
+		decoder := NewEventDecoder(nil, WithDecodedNumbers())
+		decode := func(event Event) Event {
+			data, err := event.ToJSON()
+			require.NoError(t, err)
+			decoded, err := decoder.DecodeEvent(string(event.Type()), data)
+			require.NoError(t, err)
+			return decoded
+		}
+
+		store := NewStateStore(WithPreserveNumbers())
+		require.NoError(t, store.Apply(decode(NewStateSnapshotEvent(map[string]any{"count": bigCounter}))))
+		require.NoError(t, store.Apply(decode(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "test", Path: "/count", Value: bigCounter},
+			{Op: "replace", Path: "/count", Value: bigCounter + 1},
+		}))))
+
+		state := store.Current().(map[string]any)
+		assert.Equal(t, json.Number("1152921504606846977"), state["count"])
+		count, err := CoerceNumber[int64](state["count"])
+		require.NoError(t, err)
+		assert.Equal(t, bigCounter+1, count)
+		assert.JSONEq(t, `{"count":1152921504606846977}`, string(store.CurrentRaw()))
+	})
+
+	t.Run("DefaultRoundsToFloat64", func(t *testing.T) {
+		store := NewStateStore()
+		store.ApplySnapshot(NewStateSnapshotEvent(map[string]any{"count": bigCounter}))
+		require.NoError(t, store.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "replace", Path: "/count", Value: bigCounter + 1},
+		})))
+		assert.Equal(t, float64(bigCounter), store.Current().(map[string]any)["count"])
+	})
+
+	t.Run("MergePatch", func(t *testing.T) {
+		store := NewStateStore(WithPreserveNumbers(), WithEmptyInitialState())
+		require.NoError(t, store.ApplyMergePatch(map[string]any{"a": bigCounter + 1, "b": 2.5}))
+		assert.Equal(t, map[string]any{"a": json.Number("1152921504606846977"), "b": json.Number("2.5")}, store.Current())
+	})
+
+	t.Run("TypedStore", func(t *testing.T) {
+		type counterState struct {
+			Count int64 `json:"count"`
+		}
+		store := NewTypedStateStore[counterState](WithPreserveNumbers())
+		_, err := store.Set(counterState{Count: bigCounter})
+		require.NoError(t, err)
+
+		delta, err := store.Mutate(func(s *counterState) { s.Count++ })
+		require.NoError(t, err)
+		assert.Equal(t, []JSONPatchOperation{{Op: "replace", Path: "/count", Value: json.Number("1152921504606846977")}}, delta.Delta)
+
+		current, err := store.Current()
+		require.NoError(t, err)
+		assert.Equal(t, bigCounter+1, current.Count)
+	})
+
+	t.Run("Checkpoint", func(t *testing.T) {
+		store := NewStateStore(WithPreserveNumbers())
+		store.ApplySnapshot(NewStateSnapshotEvent(map[string]any{"count": bigCounter + 1}))
+		data, err := store.Checkpoint()
+		require.NoError(t, err)
+
+		restored, err := RestoreStateStore(data, WithPreserveNumbers())
+		require.NoError(t, err)
+		assert.Equal(t, store.Current(), restored.Current())
+	})
+
+	t.Run("ApplyPatch", func(t *testing.T) {
+		doc := map[string]any{"ids": []any{uint64(math.MaxUint64)}}
+		patched, err := ApplyPatch(doc, []JSONPatchOperation{
+			{Op: "add", Path: "/ids/-", Value: int64(math.MinInt64)},
+		}, PreserveNumbers())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"ids": []any{
+			json.Number("18446744073709551615"),
+			json.Number("-9223372036854775808"),
+		}}, patched)
+
+		patched, err = ApplyPatch(doc, nil)
+		require.NoError(t, err)
+		assert.IsType(t, float64(0), patched.(map[string]any)["ids"].([]any)[0])
+	})
+}
+
+func TestCoerceNumber(t *testing.T) {
+	t.Run("Integers", func(t *testing.T) {
+		for _, value := range []any{json.Number("42"), float64(42), 42, int8(42), uint64(42), float32(42)} {
+			n, err := CoerceNumber[int](value)
+			require.NoError(t, err, "%T", value)
+			assert.Equal(t, 42, n)
+		}
+
+		u, err := CoerceNumber[uint64](json.Number("18446744073709551615"))
+		require.NoError(t, err)
+		assert.Equal(t, uint64(math.MaxUint64), u)
+
+		i, err := CoerceNumber[int8](json.Number("-128"))
+		require.NoError(t, err)
+		assert.Equal(t, int8(-128), i)
+
+		type counter int32
+		c, err := CoerceNumber[counter](json.Number("7"))
+		require.NoError(t, err)
+		assert.Equal(t, counter(7), c)
+	})
+
+	t.Run("Floats", func(t *testing.T) {
+		f, err := CoerceNumber[float64](json.Number("2.5"))
+		require.NoError(t, err)
+		assert.Equal(t, 2.5, f)
+
+		f32, err := CoerceNumber[float32](7)
+		require.NoError(t, err)
+		assert.Equal(t, float32(7), f32)
+
+		f, err = CoerceNumber[float64](json.Number("1152921504606846977"))
+		require.NoError(t, err)
+		assert.Equal(t, float64(bigCounter), f)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		cases := []struct {
+			name string
+			fn   func() error
+		}{
+			{"Fraction", func() error { _, err := CoerceNumber[int](2.5); return err }},
+			{"Overflow", func() error { _, err := CoerceNumber[int8](json.Number("128")); return err }},
+			{"Underflow", func() error { _, err := CoerceNumber[int64](json.Number("-9223372036854775809")); return err }},
+			{"NegativeUnsigned", func() error { _, err := CoerceNumber[uint](-1); return err }},
+			{"UnsignedOverflow", func() error { _, err := CoerceNumber[uint16](70000); return err }},
+			{"Float32Overflow", func() error { _, err := CoerceNumber[float32](json.Number("1e300")); return err }},
+			{"String", func() error { _, err := CoerceNumber[int]("42"); return err }},
+			{"Nil", func() error { _, err := CoerceNumber[int](nil); return err }},
+			{"InvalidNumber", func() error { _, err := CoerceNumber[int](json.Number("abc")); return err }},
+			{"NaN", func() error { _, err := CoerceNumber[float64](math.NaN()); return err }},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				assert.Error(t, tc.fn())
+			})
+		}
+	})
+}
+
+func TestDecodedNumbers(t *testing.T) {
+	data := []byte(`{"type":"CUSTOM","name":"n","value":{"id":9007199254740993}}`)
+
+	event, err := NewEventDecoder(nil, WithDecodedNumbers()).DecodeEvent("CUSTOM", data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"id": json.Number("9007199254740993")}, event.(*CustomEvent).Value)
+
+	event, err = NewEventDecoder(nil).DecodeEvent("CUSTOM", data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"id": float64(9007199254740992)}, event.(*CustomEvent).Value)
+
+	// Trailing tokens are rejected as json.Unmarshal rejects them, including
+	// closing brackets and second values
+	for _, trailing := range []string{"x", "}", "]", " {}", "\n1"} {
+		for _, opts := range [][]EventDecoderOption{{WithDecodedNumbers()}, {WithDecodedNumbers(), WithFieldCase(FieldCaseSnake)}} {
+			_, err = NewEventDecoder(nil, opts...).DecodeEvent("CUSTOM", append(append([]byte{}, data...), trailing...))
+			assert.Error(t, err, "trailing %q", trailing)
+		}
+	}
+	event, err = NewEventDecoder(nil, WithDecodedNumbers()).DecodeEvent("CUSTOM", append(append([]byte{}, data...), " \r\n\t"...))
+	require.NoError(t, err)
+	assert.Equal(t, "n", event.(*CustomEvent).Name)
+}
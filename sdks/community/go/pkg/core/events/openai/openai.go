@@ -0,0 +1,164 @@
+// Package openai converts OpenAI-style chat completion streams into AG-UI
+// events.
+package openai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// doneMarker is the payload OpenAI sends as the last frame of a stream
+var doneMarker = []byte("[DONE]")
+
+// chatCompletionChunk is a "chat.completion.chunk" object
+type chatCompletionChunk struct {
+	ID      string        `json:"id"`
+	Object  string        `json:"object"`
+	Created int64         `json:"created"`
+	Choices []chunkChoice `json:"choices"`
+}
+
+// chunkChoice is one choice of a chat completion chunk
+type chunkChoice struct {
+	Index        int        `json:"index"`
+	Delta        chunkDelta `json:"delta"`
+	FinishReason *string    `json:"finish_reason"`
+}
+
+// chunkDelta is the incremental message content of a choice
+type chunkDelta struct {
+	Role      string          `json:"role,omitempty"`
+	Content   *string         `json:"content,omitempty"`
+	ToolCalls []toolCallDelta `json:"tool_calls,omitempty"`
+}
+
+// toolCallDelta is the incremental content of a tool call. Only the first
+// delta of a call carries its ID and function name.
+type toolCallDelta struct {
+	Index    int    `json:"index"`
+	ID       string `json:"id,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments,omitempty"`
+	} `json:"function"`
+}
+
+// chunkOptions holds the options of FromOpenAIChunk
+type chunkOptions struct {
+	threadID string
+	runID    string
+}
+
+// ChunkOption defines options for converting chunks
+type ChunkOption func(*chunkOptions)
+
+// WithThreadID sets the thread ID of the RUN_FINISHED events produced for
+// finished choices. It defaults to the completion ID.
+func WithThreadID(threadID string) ChunkOption {
+	return func(o *chunkOptions) {
+		o.threadID = threadID
+	}
+}
+
+// WithRunID sets the run ID of the RUN_FINISHED events produced for finished
+// choices. It defaults to the completion ID.
+func WithRunID(runID string) ChunkOption {
+	return func(o *chunkOptions) {
+		o.runID = runID
+	}
+}
+
+// FromOpenAIChunk converts the JSON payload of one OpenAI
+// "chat.completion.chunk" stream frame into AG-UI events. The conversion
+// needs no state, so the chunks of a stream can be converted one by one:
+//
+//   - a delta carrying the role, which opens every OpenAI stream, produces a
+//     TEXT_MESSAGE_START
+//   - delta content produces a TEXT_MESSAGE_CONTENT
+//   - each tool call delta produces a TOOL_CALL_CHUNK; only the first chunk
+//     of a call carries its ID and name, and receivers expand the chunks
+//     into TOOL_CALL_START, TOOL_CALL_ARGS and TOOL_CALL_END events
+//   - a finish reason produces a TEXT_MESSAGE_END, and a chunk with finished
+//     choices ends with a single RUN_FINISHED after the last of them
+//
+// Since chunks are converted on their own, a stream whose choices finish in
+// different chunks produces a RUN_FINISHED for each of those chunks; request
+// a single choice, or drop all but the last RUN_FINISHED, when the events
+// must form a valid run.
+//
+// Messages are identified by the completion ID, suffixed with the choice
+// index for choices other than the first. Events are timestamped with the
+// chunk's creation time. The "[DONE]" terminator and chunks without choices,
+// such as the final usage chunk, produce no events.
+func FromOpenAIChunk(raw []byte, options ...ChunkOption) ([]events.Event, error) {
+	raw = bytes.TrimSpace(raw)
+	if bytes.Equal(raw, doneMarker) {
+		return nil, nil
+	}
+
+	var chunk chatCompletionChunk
+	if err := json.Unmarshal(raw, &chunk); err != nil {
+		return nil, fmt.Errorf("invalid OpenAI chunk: %w", err)
+	}
+	if chunk.Object != "" && chunk.Object != "chat.completion.chunk" {
+		return nil, fmt.Errorf("invalid OpenAI chunk: unexpected object type %q", chunk.Object)
+	}
+	if chunk.ID == "" {
+		return nil, fmt.Errorf("invalid OpenAI chunk: id is required")
+	}
+
+	opts := chunkOptions{threadID: chunk.ID, runID: chunk.ID}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	var result []events.Event
+	finished := false
+	for _, choice := range chunk.Choices {
+		messageID := chunk.ID
+		if choice.Index > 0 {
+			messageID = fmt.Sprintf("%s-%d", chunk.ID, choice.Index)
+		}
+		delta := choice.Delta
+
+		if delta.Role != "" {
+			result = append(result, events.NewTextMessageStartEvent(messageID, events.WithRole(delta.Role)))
+		}
+		if delta.Content != nil && *delta.Content != "" {
+			result = append(result, events.NewTextMessageContentEvent(messageID, *delta.Content))
+		}
+		for _, call := range delta.ToolCalls {
+			event := events.NewToolCallChunkEvent().WithToolCallChunkParentMessageID(messageID)
+			if call.ID != "" {
+				event.WithToolCallChunkID(call.ID)
+			}
+			if call.Function.Name != "" {
+				event.WithToolCallChunkName(call.Function.Name)
+			}
+			if call.Function.Arguments != "" {
+				event.WithToolCallChunkDelta(call.Function.Arguments)
+			}
+			if event.ToolCallID == nil && event.ToolCallName == nil && event.Delta == nil {
+				continue
+			}
+			result = append(result, event)
+		}
+		if choice.FinishReason != nil && *choice.FinishReason != "" {
+			result = append(result, events.NewTextMessageEndEvent(messageID))
+			finished = true
+		}
+	}
+	if finished {
+		result = append(result, events.NewRunFinishedEvent(opts.threadID, opts.runID))
+	}
+
+	if chunk.Created > 0 {
+		for _, event := range result {
+			event.GetBaseEvent().SetTimestamp(chunk.Created * 1000)
+		}
+	}
+	return result, nil
+}
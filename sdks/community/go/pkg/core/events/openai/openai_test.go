@@ -0,0 +1,162 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// convertStream converts every data frame of a captured OpenAI stream
+func convertStream(t *testing.T, name string, options ...ChunkOption) []events.Event {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+
+	var result []events.Event
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		payload, ok := bytes.CutPrefix(scanner.Bytes(), []byte("data: "))
+		if !ok {
+			continue
+		}
+		converted, err := FromOpenAIChunk(payload, options...)
+		require.NoError(t, err)
+		result = append(result, converted...)
+	}
+	require.NoError(t, scanner.Err())
+
+	for _, event := range result {
+		require.NoError(t, event.Validate())
+	}
+	return result
+}
+
+// eventTypes returns the types of the events
+func eventTypes(evts []events.Event) []events.EventType {
+	types := make([]events.EventType, len(evts))
+	for i, event := range evts {
+		types[i] = event.Type()
+	}
+	return types
+}
+
+func TestFromOpenAIChunk(t *testing.T) {
+	t.Run("TextStream", func(t *testing.T) {
+		result := convertStream(t, "testdata/text_stream.txt")
+		require.Equal(t, []events.EventType{
+			events.EventTypeTextMessageStart,
+			events.EventTypeTextMessageContent,
+			events.EventTypeTextMessageContent,
+			events.EventTypeTextMessageContent,
+			events.EventTypeTextMessageEnd,
+			events.EventTypeRunFinished,
+		}, eventTypes(result))
+
+		start := result[0].(*events.TextMessageStartEvent)
+		assert.Equal(t, "chatcmpl-9xKz1", start.MessageID)
+		require.NotNil(t, start.Role)
+		assert.Equal(t, "assistant", *start.Role)
+
+		var text string
+		for _, event := range result[1:4] {
+			content := event.(*events.TextMessageContentEvent)
+			assert.Equal(t, "chatcmpl-9xKz1", content.MessageID)
+			text += content.Delta
+		}
+		assert.Equal(t, "Hello! How can I help?", text)
+
+		finished := result[5].(*events.RunFinishedEvent)
+		assert.Equal(t, "chatcmpl-9xKz1", finished.RunID())
+		assert.Equal(t, "chatcmpl-9xKz1", finished.ThreadID())
+
+		for _, event := range result {
+			require.NotNil(t, event.Timestamp())
+			assert.Equal(t, int64(1718036400000), *event.Timestamp())
+		}
+	})
+
+	t.Run("ToolCallStream", func(t *testing.T) {
+		result := convertStream(t, "testdata/tool_call_stream.txt", WithThreadID("thread-1"), WithRunID("run-1"))
+		require.Equal(t, []events.EventType{
+			events.EventTypeTextMessageStart,
+			events.EventTypeToolCallChunk,
+			events.EventTypeToolCallChunk,
+			events.EventTypeToolCallChunk,
+			events.EventTypeTextMessageEnd,
+			events.EventTypeRunFinished,
+		}, eventTypes(result))
+
+		first := result[1].(*events.ToolCallChunkEvent)
+		require.NotNil(t, first.ToolCallID)
+		require.NotNil(t, first.ToolCallName)
+		require.NotNil(t, first.ParentMessageID)
+		assert.Equal(t, "call_Qh3f9", *first.ToolCallID)
+		assert.Equal(t, "get_weather", *first.ToolCallName)
+		assert.Equal(t, "chatcmpl-9xL02", *first.ParentMessageID)
+		assert.Nil(t, first.Delta)
+
+		var args string
+		for _, event := range result[2:4] {
+			chunk := event.(*events.ToolCallChunkEvent)
+			assert.Nil(t, chunk.ToolCallID)
+			require.NotNil(t, chunk.Delta)
+			args += *chunk.Delta
+		}
+		assert.JSONEq(t, `{"location": "Paris"}`, args)
+
+		finished := result[5].(*events.RunFinishedEvent)
+		assert.Equal(t, "thread-1", finished.ThreadID())
+		assert.Equal(t, "run-1", finished.RunID())
+	})
+
+	t.Run("MultipleChoices", func(t *testing.T) {
+		result, err := FromOpenAIChunk([]byte(`{"id":"c1","object":"chat.completion.chunk","choices":[
+			{"index":0,"delta":{"content":"a"}},
+			{"index":1,"delta":{"content":"b"}}]}`))
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		assert.Equal(t, "c1", result[0].(*events.TextMessageContentEvent).MessageID)
+		assert.Equal(t, "c1-1", result[1].(*events.TextMessageContentEvent).MessageID)
+	})
+
+	t.Run("MultipleChoicesFinish", func(t *testing.T) {
+		result, err := FromOpenAIChunk([]byte(`{"id":"c1","object":"chat.completion.chunk","choices":[
+			{"index":0,"delta":{"content":"a"},"finish_reason":"stop"},
+			{"index":1,"delta":{"content":"b"},"finish_reason":"stop"}]}`))
+		require.NoError(t, err)
+		require.Equal(t, []events.EventType{
+			events.EventTypeTextMessageContent,
+			events.EventTypeTextMessageEnd,
+			events.EventTypeTextMessageContent,
+			events.EventTypeTextMessageEnd,
+			events.EventTypeRunFinished,
+		}, eventTypes(result))
+		assert.Equal(t, "c1", result[1].(*events.TextMessageEndEvent).MessageID)
+		assert.Equal(t, "c1-1", result[3].(*events.TextMessageEndEvent).MessageID)
+		assert.Equal(t, "c1", result[4].(*events.RunFinishedEvent).RunID())
+	})
+
+	t.Run("Done", func(t *testing.T) {
+		result, err := FromOpenAIChunk([]byte(" [DONE]\n"))
+		require.NoError(t, err)
+		assert.Empty(t, result)
+	})
+
+	t.Run("InvalidChunks", func(t *testing.T) {
+		for name, raw := range map[string]string{
+			"NotJSON":     `{"id":`,
+			"MissingID":   `{"object":"chat.completion.chunk","choices":[]}`,
+			"WrongObject": `{"id":"c1","object":"chat.completion","choices":[]}`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := FromOpenAIChunk([]byte(raw))
+				assert.Error(t, err)
+			})
+		}
+	})
+}
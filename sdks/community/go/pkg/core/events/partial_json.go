@@ -0,0 +1,89 @@
+package events
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/jsonpointer"
+)
+
+// PartialJSONParser parses a JSON document that arrives in fragments, such as
+// streamed tool call arguments, and makes every value available as soon as it
+// is complete. With the arguments of a weather call, TryGet("/location")
+// succeeds once the location string has been closed, long before the end of
+// the tool call. Fragments may split the document anywhere, including inside
+// a token or an escape sequence.
+//
+// It checks the document as ProgressiveJSONValidator does, so the document
+// must be a JSON object or array. A parser is not safe for concurrent use.
+type PartialJSONParser struct {
+	scanner jsonScanner
+
+	// values maps the JSON pointer of every complete value to its span
+	values map[string]partialJSONSpan
+}
+
+// partialJSONSpan locates a complete value in the document
+type partialJSONSpan struct {
+	start, end int
+}
+
+// NewPartialJSONParser creates a parser for an empty document
+func NewPartialJSONParser() *PartialJSONParser {
+	p := &PartialJSONParser{}
+	p.Reset()
+	return p
+}
+
+// Write appends the next fragment of the document. It returns an error if the
+// document is invalid so far; the error is sticky and returned by every later
+// call.
+func (p *PartialJSONParser) Write(delta string) error {
+	return p.scanner.write(delta)
+}
+
+// TryGet returns the value at the JSON pointer path if it has been received
+// completely, decoded as by json.Unmarshal into an any. The empty path is
+// the whole document.
+func (p *PartialJSONParser) TryGet(path string) (any, bool) {
+	tokens, err := jsonpointer.Parse(path)
+	if err != nil {
+		return nil, false
+	}
+	var canonical strings.Builder
+	for _, token := range tokens {
+		canonical.WriteByte('/')
+		canonical.WriteString(jsonpointer.Escape(token))
+	}
+
+	span, ok := p.values[canonical.String()]
+	if !ok {
+		return nil, false
+	}
+	var value any
+	if err := json.Unmarshal(p.scanner.data[span.start:span.end], &value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Complete reports whether the outermost object or array has been closed
+// without error
+func (p *PartialJSONParser) Complete() bool {
+	return p.scanner.isComplete()
+}
+
+// Final returns the complete document, without surrounding whitespace. It
+// fails if the document is incomplete or invalid.
+func (p *PartialJSONParser) Final() (json.RawMessage, error) {
+	return p.scanner.final()
+}
+
+// Reset clears the parser for a new document
+func (p *PartialJSONParser) Reset() {
+	p.scanner = jsonScanner{}
+	p.values = make(map[string]partialJSONSpan)
+	p.scanner.onValue = func(start, end int) {
+		p.values[p.scanner.pointer()] = partialJSONSpan{start: start, end: end}
+	}
+}
@@ -0,0 +1,157 @@
+package events
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// weatherArgs exercises escapes, unicode, numbers and nesting
+const weatherArgs = ` {"location": "Paris, \"Île-de-France\" é\\", "days": [1, -2.5e3, 0],
+	"units": {"temp": "celsius", "wind": null}, "alerts": true, "a~b/c": false, "empty": {}, "none": []} `
+
+func TestPartialJSONParser(t *testing.T) {
+	var expected any
+	require.NoError(t, json.Unmarshal([]byte(weatherArgs), &expected))
+
+	t.Run("every split point", func(t *testing.T) {
+		for i := 0; i <= len(weatherArgs); i++ {
+			p := NewPartialJSONParser()
+			require.NoError(t, p.Write(weatherArgs[:i]), "split at %d", i)
+			require.NoError(t, p.Write(weatherArgs[i:]), "split at %d", i)
+
+			require.True(t, p.Complete(), "split at %d", i)
+			final, err := p.Final()
+			require.NoError(t, err, "split at %d", i)
+			require.Equal(t, strings.TrimSpace(weatherArgs), string(final), "split at %d", i)
+
+			value, ok := p.TryGet("")
+			require.True(t, ok)
+			require.Equal(t, expected, value)
+		}
+	})
+
+	t.Run("byte by byte", func(t *testing.T) {
+		p := NewPartialJSONParser()
+		seen := map[string]int{}
+		paths := []string{"/location", "/days/1", "/days", "/units/wind", "/units", "/alerts", "/a~0b~1c", "/empty", "/none", ""}
+
+		for i := 0; i < len(weatherArgs); i++ {
+			require.NoError(t, p.Write(weatherArgs[i:i+1]))
+			for _, path := range paths {
+				if _, ok := seen[path]; ok {
+					continue
+				}
+				if _, ok := p.TryGet(path); ok {
+					seen[path] = i
+				}
+			}
+		}
+
+		// Each value is available as soon as its closing byte or the
+		// delimiter after it has been written
+		assert.Equal(t, strings.Index(weatherArgs, `\\"`)+2, seen["/location"])
+		assert.Equal(t, strings.Index(weatherArgs, "e3,")+2, seen["/days/1"])
+		assert.Less(t, seen["/location"], seen["/days"])
+
+		// The brace after null ends both the literal and its object
+		assert.Equal(t, strings.Index(weatherArgs, "null}")+4, seen["/units/wind"])
+		assert.Equal(t, seen["/units/wind"], seen["/units"])
+		assert.Equal(t, strings.LastIndex(weatherArgs, "}"), seen[""])
+
+		location, _ := p.TryGet("/location")
+		assert.Equal(t, `Paris, "Île-de-France" é\`, location)
+		days, _ := p.TryGet("/days")
+		assert.Equal(t, []any{1.0, -2500.0, 0.0}, days)
+		wind, ok := p.TryGet("/units/wind")
+		assert.True(t, ok)
+		assert.Nil(t, wind)
+		flag, _ := p.TryGet("/a~0b~1c")
+		assert.Equal(t, false, flag)
+	})
+
+	t.Run("incomplete values", func(t *testing.T) {
+		p := NewPartialJSONParser()
+		require.NoError(t, p.Write(`{"location": "Par`))
+		_, ok := p.TryGet("/location")
+		assert.False(t, ok)
+
+		// A number is not complete until a delimiter follows it
+		require.NoError(t, p.Write(`is", "days": 12`))
+		_, ok = p.TryGet("/days")
+		assert.False(t, ok)
+		require.NoError(t, p.Write(` `))
+		days, ok := p.TryGet("/days")
+		assert.True(t, ok)
+		assert.Equal(t, 12.0, days)
+
+		assert.False(t, p.Complete())
+		_, err := p.Final()
+		assert.Error(t, err)
+
+		_, ok = p.TryGet("/missing")
+		assert.False(t, ok)
+		_, ok = p.TryGet("invalid pointer")
+		assert.False(t, ok)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		p := NewPartialJSONParser()
+		require.NoError(t, p.Write(`{"a": 1}`))
+		p.Reset()
+		assert.False(t, p.Complete())
+		_, ok := p.TryGet("/a")
+		assert.False(t, ok)
+		require.NoError(t, p.Write(`[true]`))
+		assert.True(t, p.Complete())
+	})
+}
+
+func TestPartialJSONParser_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"scalar document", `"text"`},
+		{"mismatched bracket", `{"a": [1}`},
+		{"missing colon", `{"a" 1}`},
+		{"non-string key", `{1: 2}`},
+		{"bad literal", `{"a": tru}`},
+		{"bad number", `[01]`},
+		{"trailing comma", `[1,]`},
+		{"missing comma", `[1 2]`},
+		{"trailing data", `{} {}`},
+		{"control character", "[\"a\nb\"]"},
+		{"invalid escape", `["a\qb"]`},
+		{"short unicode escape", `["\u12"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPartialJSONParser()
+			err := p.Write(tt.input)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid JSON at offset")
+
+			// Errors are sticky
+			assert.Equal(t, err, p.Write("]"))
+			assert.False(t, p.Complete())
+			_, finalErr := p.Final()
+			assert.Equal(t, err, finalErr)
+		})
+	}
+
+	t.Run("invalid escape", func(t *testing.T) {
+		p := NewPartialJSONParser()
+		err := p.Write(`{"a": "\x"}`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `offset 8: invalid escape`)
+		_, ok := p.TryGet("/a")
+		assert.False(t, ok)
+		_, finalErr := p.Final()
+		assert.Equal(t, err, finalErr)
+	})
+}
@@ -0,0 +1,157 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/jsonpointer"
+)
+
+// PatchBuilder builds the JSON Patch operations of a state delta. Paths are
+// validated as each operation is added; the first invalid operation is
+// recorded and every later call is ignored, so a chain of calls only needs
+// its error checked once, through Err or NewStateDeltaEventFromBuilder.
+//
+//	ops := events.NewPatchBuilder().
+//		Append("/messages", message).
+//		Replace("/status", "done").
+//		Build()
+type PatchBuilder struct {
+	ops []JSONPatchOperation
+	err error
+}
+
+// NewPatchBuilder creates an empty patch builder
+func NewPatchBuilder() *PatchBuilder {
+	return &PatchBuilder{}
+}
+
+// Add adds an operation that sets the value at path, creating object members
+// and inserting into arrays
+func (b *PatchBuilder) Add(path string, value any) *PatchBuilder {
+	return b.push(JSONPatchOperation{Op: "add", Path: path, Value: value})
+}
+
+// Append adds an operation that appends the value to the array at path
+func (b *PatchBuilder) Append(path string, value any) *PatchBuilder {
+	return b.push(JSONPatchOperation{Op: "add", Path: path + "/-", Value: value})
+}
+
+// InsertAt adds an operation that inserts the value into the array at path
+// before index i, shifting the following elements
+func (b *PatchBuilder) InsertAt(path string, i int, value any) *PatchBuilder {
+	if i < 0 {
+		return b.fail(fmt.Errorf("insert into %q: negative index %d", path, i))
+	}
+	return b.push(JSONPatchOperation{Op: "add", Path: path + "/" + strconv.Itoa(i), Value: value})
+}
+
+// Remove adds an operation that removes the value at path
+func (b *PatchBuilder) Remove(path string) *PatchBuilder {
+	return b.push(JSONPatchOperation{Op: "remove", Path: path})
+}
+
+// RemoveAt adds an operation that removes element i of the array at path
+func (b *PatchBuilder) RemoveAt(path string, i int) *PatchBuilder {
+	if i < 0 {
+		return b.fail(fmt.Errorf("remove from %q: negative index %d", path, i))
+	}
+	return b.push(JSONPatchOperation{Op: "remove", Path: path + "/" + strconv.Itoa(i)})
+}
+
+// Replace adds an operation that replaces the existing value at path
+func (b *PatchBuilder) Replace(path string, value any) *PatchBuilder {
+	return b.push(JSONPatchOperation{Op: "replace", Path: path, Value: value})
+}
+
+// Move adds an operation that moves the value at from to to
+func (b *PatchBuilder) Move(from, to string) *PatchBuilder {
+	return b.push(JSONPatchOperation{Op: "move", From: from, Path: to})
+}
+
+// Copy adds an operation that copies the value at from to to
+func (b *PatchBuilder) Copy(from, to string) *PatchBuilder {
+	return b.push(JSONPatchOperation{Op: "copy", From: from, Path: to})
+}
+
+// Test adds an operation that checks that the value at path equals value
+func (b *PatchBuilder) Test(path string, value any) *PatchBuilder {
+	return b.push(JSONPatchOperation{Op: "test", Path: path, Value: value})
+}
+
+// Err returns the error of the first invalid operation, if any
+func (b *PatchBuilder) Err() error {
+	return b.err
+}
+
+// Len returns the number of operations built so far
+func (b *PatchBuilder) Len() int {
+	return len(b.ops)
+}
+
+// Build returns a copy of the operations built so far, without the invalid
+// operation and those following it
+func (b *PatchBuilder) Build() []JSONPatchOperation {
+	ops := make([]JSONPatchOperation, len(b.ops))
+	copy(ops, b.ops)
+	return ops
+}
+
+// push validates an operation and appends it
+func (b *PatchBuilder) push(op JSONPatchOperation) *PatchBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	if err := validatePatchBuilderOperation(op); err != nil {
+		return b.fail(fmt.Errorf("%s %q: %w", op.Op, op.Path, err))
+	}
+
+	b.ops = append(b.ops, op)
+	return b
+}
+
+// fail records the first invalid operation
+func (b *PatchBuilder) fail(err error) *PatchBuilder {
+	if b.err == nil {
+		b.err = fmt.Errorf("invalid patch operation %d: %w", len(b.ops), err)
+	}
+	return b
+}
+
+// validatePatchBuilderOperation checks the paths and value of an operation
+// without a document to apply it to
+func validatePatchBuilderOperation(op JSONPatchOperation) error {
+	path, err := jsonpointer.Parse(op.Path)
+	if err != nil {
+		return err
+	}
+
+	switch op.Op {
+	case "move", "copy":
+		from, err := jsonpointer.Parse(op.From)
+		if err != nil {
+			return fmt.Errorf("from: %w", err)
+		}
+		if op.Op == "move" && isProperPrefix(from, path) {
+			return fmt.Errorf("cannot move %q into its own child", op.From)
+		}
+	case "add", "replace", "test":
+		if _, err := json.Marshal(op.Value); err != nil {
+			return fmt.Errorf("value is not valid JSON: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// NewStateDeltaEventFromBuilder creates a state delta event carrying the
+// operations of the builder. It fails if the builder recorded an invalid
+// operation.
+func NewStateDeltaEventFromBuilder(b *PatchBuilder, options ...StateDeltaOption) (*StateDeltaEvent, error) {
+	if err := b.Err(); err != nil {
+		return nil, err
+	}
+	return NewStateDeltaEventWithOptions(b.Build(), options...), nil
+}
@@ -0,0 +1,137 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func patchBuilderFixture(t *testing.T) any {
+	t.Helper()
+	var doc any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"status": "running",
+		"items": ["a", "b", "c"],
+		"done": []
+	}`), &doc))
+	return doc
+}
+
+func TestPatchBuilder_Apply(t *testing.T) {
+	tests := []struct {
+		name     string
+		build    func(b *PatchBuilder) *PatchBuilder
+		expected string
+	}{
+		{
+			name:     "Append",
+			build:    func(b *PatchBuilder) *PatchBuilder { return b.Append("/items", "d") },
+			expected: `{"status":"running","items":["a","b","c","d"],"done":[]}`,
+		},
+		{
+			name:     "InsertAt",
+			build:    func(b *PatchBuilder) *PatchBuilder { return b.InsertAt("/items", 1, "x") },
+			expected: `{"status":"running","items":["a","x","b","c"],"done":[]}`,
+		},
+		{
+			name:     "RemoveAt",
+			build:    func(b *PatchBuilder) *PatchBuilder { return b.RemoveAt("/items", 0) },
+			expected: `{"status":"running","items":["b","c"],"done":[]}`,
+		},
+		{
+			name:     "Replace",
+			build:    func(b *PatchBuilder) *PatchBuilder { return b.Replace("/status", "done") },
+			expected: `{"status":"done","items":["a","b","c"],"done":[]}`,
+		},
+		{
+			name:     "Move",
+			build:    func(b *PatchBuilder) *PatchBuilder { return b.Move("/items/2", "/done/-") },
+			expected: `{"status":"running","items":["a","b"],"done":["c"]}`,
+		},
+		{
+			name: "Chain",
+			build: func(b *PatchBuilder) *PatchBuilder {
+				return b.Test("/status", "running").
+					Move("/items/0", "/done/0").
+					Append("/done", map[string]any{"id": 1}).
+					Copy("/status", "/previous").
+					Replace("/status", "done").
+					Add("/count", 2).
+					Remove("/items")
+			},
+			expected: `{"status":"done","previous":"running","count":2,"done":["a",{"id":1}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.build(NewPatchBuilder())
+			require.NoError(t, b.Err())
+
+			doc, err := ApplyPatch(patchBuilderFixture(t), b.Build())
+			require.NoError(t, err)
+
+			var expected any
+			require.NoError(t, json.Unmarshal([]byte(tt.expected), &expected))
+			assert.Equal(t, expected, doc)
+		})
+	}
+}
+
+func TestPatchBuilder_InvalidOperations(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(b *PatchBuilder) *PatchBuilder
+	}{
+		{"PathWithoutLeadingSlash", func(b *PatchBuilder) *PatchBuilder { return b.Replace("status", 1) }},
+		{"InvalidEscape", func(b *PatchBuilder) *PatchBuilder { return b.Append("/a~2b", 1) }},
+		{"NegativeInsertIndex", func(b *PatchBuilder) *PatchBuilder { return b.InsertAt("/items", -1, 1) }},
+		{"NegativeRemoveIndex", func(b *PatchBuilder) *PatchBuilder { return b.RemoveAt("/items", -1) }},
+		{"InvalidFrom", func(b *PatchBuilder) *PatchBuilder { return b.Move("items", "/done") }},
+		{"MoveIntoChild", func(b *PatchBuilder) *PatchBuilder { return b.Move("/items", "/items/0") }},
+		{"ValueNotJSON", func(b *PatchBuilder) *PatchBuilder { return b.Add("/f", func() {}) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.build(NewPatchBuilder().Replace("/status", "ok"))
+			require.Error(t, b.Err())
+			assert.Contains(t, b.Err().Error(), "operation 1")
+
+			// Later operations are ignored once one is invalid
+			b.Replace("/status", "ignored")
+			assert.Equal(t, []JSONPatchOperation{{Op: "replace", Path: "/status", Value: "ok"}}, b.Build())
+
+			_, err := NewStateDeltaEventFromBuilder(b)
+			assert.Equal(t, b.Err(), err)
+		})
+	}
+}
+
+func TestPatchBuilder_BuildCopies(t *testing.T) {
+	b := NewPatchBuilder().Replace("/status", "a")
+	ops := b.Build()
+	ops[0].Path = "/other"
+
+	b.Append("/items", "x")
+	assert.Equal(t, "/status", b.Build()[0].Path)
+	assert.Equal(t, 2, b.Len())
+}
+
+func TestNewStateDeltaEventFromBuilder(t *testing.T) {
+	b := NewPatchBuilder().Append("/items", "d").Replace("/status", "done")
+
+	event, err := NewStateDeltaEventFromBuilder(b, WithBaseVersion(3))
+	require.NoError(t, err)
+	require.NoError(t, event.Validate())
+	assert.Equal(t, b.Build(), event.Delta)
+	require.NotNil(t, event.BaseVersion)
+	assert.Equal(t, uint64(3), *event.BaseVersion)
+
+	store := NewStateStore()
+	store.ApplySnapshot(NewStateSnapshotEvent(patchBuilderFixture(t)))
+	require.NoError(t, store.ApplyDelta(event))
+	assert.Equal(t, "done", store.Current().(map[string]any)["status"])
+}
@@ -0,0 +1,44 @@
+package events
+
+// ProgressiveJSONValidator checks a JSON document that arrives in fragments,
+// such as streamed tool call arguments. Write checks each fragment against
+// the JSON grammar, so errors like mismatched brackets, missing commas or
+// invalid escapes are reported as soon as the offending fragment is written.
+// Validate checks that the document is complete. It scans documents like
+// PartialJSONParser, without keeping track of their values.
+//
+// The document must be a JSON object or array. A validator is not safe for
+// concurrent use.
+type ProgressiveJSONValidator struct {
+	scanner jsonScanner
+}
+
+// NewProgressiveJSONValidator creates a validator for an empty document
+func NewProgressiveJSONValidator() *ProgressiveJSONValidator {
+	return &ProgressiveJSONValidator{}
+}
+
+// Write appends the next fragment of the document. It returns an error if the
+// document is invalid so far; the error is sticky and returned by every later
+// call.
+func (v *ProgressiveJSONValidator) Write(delta string) error {
+	return v.scanner.write(delta)
+}
+
+// IsComplete reports whether the document written so far is complete: its
+// outermost object or array has been closed and no error was found
+func (v *ProgressiveJSONValidator) IsComplete() bool {
+	return v.scanner.isComplete()
+}
+
+// Validate reports whether the document written so far is complete, valid
+// JSON
+func (v *ProgressiveJSONValidator) Validate() error {
+	_, err := v.scanner.final()
+	return err
+}
+
+// Reset clears the validator for a new document
+func (v *ProgressiveJSONValidator) Reset() {
+	*v = ProgressiveJSONValidator{}
+}
@@ -0,0 +1,122 @@
+package events
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressiveJSONValidator(t *testing.T) {
+	t.Run("ValidDocumentsSplitAnywhere", func(t *testing.T) {
+		docs := []string{
+			`{}`,
+			`[]`,
+			` {"location": "Paris", "days": [1, 2, {"x": null}]} `,
+			`{"quote": "a \"}\" and a \\", "brackets": "[{"}`,
+			`[{"nested": [[], {}]}, "é", true, -1.5e3]`,
+		}
+		for _, doc := range docs {
+			for split := 0; split <= len(doc); split++ {
+				v := NewProgressiveJSONValidator()
+				require.NoError(t, v.Write(doc[:split]), "%s split at %d", doc, split)
+				assert.Equal(t, strings.TrimSpace(doc[:split]) == strings.TrimSpace(doc), v.IsComplete(),
+					"%s split at %d", doc, split)
+				require.NoError(t, v.Write(doc[split:]))
+				assert.True(t, v.IsComplete(), doc)
+				assert.NoError(t, v.Validate(), doc)
+			}
+		}
+	})
+
+	t.Run("Incomplete", func(t *testing.T) {
+		cases := map[string]string{
+			"":               "document is empty",
+			"  ":             "document is empty",
+			`{"a": [1, 2`:    "2 unclosed",
+			`{"a": "text`:    "unterminated string",
+			`["escaped \"]`:  "unterminated string",
+			`{"a": {"b": 1}`: "1 unclosed",
+		}
+		for input, message := range cases {
+			v := NewProgressiveJSONValidator()
+			require.NoError(t, v.Write(input))
+			assert.False(t, v.IsComplete(), input)
+			err := v.Validate()
+			require.Error(t, err, input)
+			assert.Contains(t, err.Error(), message, input)
+		}
+	})
+
+	t.Run("StructuralErrorsReportedOnWrite", func(t *testing.T) {
+		cases := map[string]string{
+			`{"a": [1, 2}`:           `offset 11: unexpected '}'`,
+			`]`:                      `offset 0: document must start`,
+			`"just a string"`:        `offset 0: document must start`,
+			`{"a": 1}}`:              `offset 8: unexpected '}' after end of document`,
+			`{"a": 1} {"b": 2}`:      `offset 9: unexpected '{' after end of document`,
+			"{\"line\nbreak\": 1}":   `offset 6: control character`,
+			`[{"a": 1]`:              `offset 8: unexpected ']'`,
+			`{"ok": "}"}  trailing`:  `offset 13: unexpected 't'`,
+			`{"s": "\\"}, "x": ]]}`:  `offset 11: unexpected ','`,
+			`{"s": "\\\"}"}]`:        `offset 14: unexpected ']'`,
+			"{\"tab\": \"a\tb\"}":    `offset 10: control character`,
+			`{"deep": [[[[]]]]]}`:    `offset 17: unexpected ']'`,
+			`{"a": 1}` + "\n\t x":    `offset 11: unexpected 'x'`,
+			`[1, 2], [3]`:            `offset 6: unexpected ','`,
+			`{"k": "v"}` + `"extra"`: `offset 10: unexpected '"'`,
+		}
+		for input, message := range cases {
+			v := NewProgressiveJSONValidator()
+			var err error
+			for i := 0; i < len(input) && err == nil; i++ {
+				err = v.Write(input[i : i+1])
+			}
+			require.Error(t, err, input)
+			assert.Contains(t, err.Error(), message, input)
+
+			// Errors are sticky
+			assert.Equal(t, err, v.Write("{}"))
+			assert.Equal(t, err, v.Validate())
+			assert.False(t, v.IsComplete())
+		}
+	})
+
+	t.Run("GrammarErrorsReportedOnWrite", func(t *testing.T) {
+		cases := map[string]string{
+			`{"a" 1}`:           `offset 5: unexpected '1', expected ':'`,
+			`{b: 1}`:            `offset 1: unexpected 'b', expected object key`,
+			`[1 2]`:             `offset 3: unexpected '2', expected ',' or ']'`,
+			`{"a": tru}`:        `offset 9: invalid literal "tru"`,
+			`{"a": "\x"}`:       `offset 8: invalid escape "\\x" in string`,
+			`{"a": "\u12G4"}`:   `offset 11: invalid character 'G' in \u escape`,
+			`{"a": [1,]}`:       `offset 9: unexpected ']', expected value`,
+			`{"a": "ok", }`:     `offset 12: unexpected '}', expected object key`,
+			`{"a": "\u00e9\/"}`: "",
+		}
+		for input, message := range cases {
+			v := NewProgressiveJSONValidator()
+			var err error
+			for i := 0; i < len(input) && err == nil; i++ {
+				err = v.Write(input[i : i+1])
+			}
+			if message == "" {
+				require.NoError(t, err, input)
+				assert.NoError(t, v.Validate(), input)
+				continue
+			}
+			require.Error(t, err, input)
+			assert.Contains(t, err.Error(), message, input)
+			assert.False(t, v.IsComplete(), input)
+		}
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		v := NewProgressiveJSONValidator()
+		require.Error(t, v.Write(`]`))
+		v.Reset()
+		require.NoError(t, v.Write(`{"a": 1}`))
+		assert.NoError(t, v.Validate())
+	})
+}
@@ -0,0 +1,352 @@
+package events
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Rule codes reported by the default end-of-run checks
+const (
+	RuleRunWithoutActivity = "run_without_activity"
+	RuleStateInconsistent  = "state_inconsistent"
+	RuleRunDuration        = "run_duration"
+	RuleUnmatchedPair      = "unmatched_pair"
+)
+
+// DefaultMaxRunDuration is the run duration above which CheckRunDuration reports a warning
+const DefaultMaxRunDuration = time.Hour
+
+// Kinds of paired events tracked by the RunAuditor
+const (
+	PairKindRun             = "run"
+	PairKindStep            = "step"
+	PairKindMessage         = "message"
+	PairKindToolCall        = "tool_call"
+	PairKindThinking        = "thinking"
+	PairKindThinkingMessage = "thinking_message"
+)
+
+// PairedDuration records the time between a start event and its matching end event
+type PairedDuration struct {
+	Kind       string `json:"kind"`
+	ID         string `json:"id,omitempty"`
+	StartIndex int    `json:"startIndex"`
+	EndIndex   int    `json:"endIndex"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// UnmatchedPair records a start event without an end event, or the reverse
+type UnmatchedPair struct {
+	Kind    string `json:"kind"`
+	ID      string `json:"id,omitempty"`
+	Index   int    `json:"index"`
+	Missing string `json:"missing"` // "start" or "end"
+}
+
+// RunReport summarizes a complete run
+type RunReport struct {
+	ThreadID   string            `json:"threadId,omitempty"`
+	RunID      string            `json:"runId,omitempty"`
+	EventCount int               `json:"eventCount"`
+	Counts     map[EventType]int `json:"counts"`
+	DurationMs int64             `json:"durationMs"`
+	Pairs      []PairedDuration  `json:"pairs"`
+	Unmatched  []UnmatchedPair   `json:"unmatched"`
+	StateError string            `json:"stateError,omitempty"`
+
+	// AgentID and AgentVersion identify the agent that handled the run, as
+	// recorded on its start event
+	AgentID      string `json:"agentId,omitempty"`
+	AgentVersion string `json:"agentVersion,omitempty"`
+
+	// ConversationID is the hosting platform's conversation ID, taken from
+	// the run's start or finish event
+	ConversationID string `json:"conversationId,omitempty"`
+
+	// ModelID and ModelProvider identify the LLM that handled the run, as
+	// recorded on its start event
+	ModelID       string `json:"modelId,omitempty"`
+	ModelProvider string `json:"modelProvider,omitempty"`
+
+	// Findings holds the results of the end-of-run checks. Run-level
+	// findings that do not refer to a single event have an EventIndex of -1.
+	Findings []ValidationFinding `json:"findings"`
+}
+
+// RunCheck is an end-of-run assertion evaluated against a completed report
+type RunCheck func(report *RunReport) []ValidationFinding
+
+// CheckRunActivity reports an error when the run contained no text message
+// and no tool call
+func CheckRunActivity() RunCheck {
+	return func(report *RunReport) []ValidationFinding {
+		activity := report.Counts[EventTypeTextMessageStart] +
+			report.Counts[EventTypeTextMessageChunk] +
+			report.Counts[EventTypeToolCallStart] +
+			report.Counts[EventTypeToolCallChunk]
+		if activity > 0 {
+			return nil
+		}
+		return []ValidationFinding{runFinding(SeverityError, RuleRunWithoutActivity,
+			"run produced no text messages or tool calls")}
+	}
+}
+
+// CheckStateConsistency reports an error when the state deltas of the run
+// could not be applied on top of its state snapshots
+func CheckStateConsistency() RunCheck {
+	return func(report *RunReport) []ValidationFinding {
+		if report.StateError == "" {
+			return nil
+		}
+		return []ValidationFinding{runFinding(SeverityError, RuleStateInconsistent,
+			"state is inconsistent: "+report.StateError)}
+	}
+}
+
+// CheckRunDuration reports a warning when the run took longer than max
+func CheckRunDuration(max time.Duration) RunCheck {
+	return func(report *RunReport) []ValidationFinding {
+		if report.DurationMs <= max.Milliseconds() {
+			return nil
+		}
+		return []ValidationFinding{runFinding(SeverityWarning, RuleRunDuration,
+			fmt.Sprintf("run took %dms, longer than %dms", report.DurationMs, max.Milliseconds()))}
+	}
+}
+
+// CheckPairsMatched reports a warning for every unmatched start or end event
+func CheckPairsMatched() RunCheck {
+	return func(report *RunReport) []ValidationFinding {
+		var findings []ValidationFinding
+		for _, pair := range report.Unmatched {
+			finding := runFinding(SeverityWarning, RuleUnmatchedPair,
+				fmt.Sprintf("%s %s is missing its %s event", pair.Kind, pair.ID, pair.Missing))
+			finding.EventIndex = pair.Index
+			findings = append(findings, finding)
+		}
+		return findings
+	}
+}
+
+// DefaultRunChecks returns the checks used when no checks are configured
+func DefaultRunChecks() []RunCheck {
+	return []RunCheck{
+		CheckRunActivity(),
+		CheckStateConsistency(),
+		CheckRunDuration(DefaultMaxRunDuration),
+		CheckPairsMatched(),
+	}
+}
+
+// runFinding creates a run-level finding that does not refer to a single event
+func runFinding(severity ValidationSeverity, rule, message string) ValidationFinding {
+	return ValidationFinding{Severity: severity, Rule: rule, Message: message, EventIndex: -1}
+}
+
+// openPair records a start event waiting for its end event
+type openPair struct {
+	index     int
+	timestamp *int64
+}
+
+// RunAuditor observes the events of a run and produces a RunReport
+type RunAuditor struct {
+	checks []RunCheck
+
+	index      int
+	counts     map[EventType]int
+	firstTS    *int64
+	lastTS     *int64
+	threadID   string
+	runID      string
+	convID     string
+	agent      string
+	agentVer   string
+	model      string
+	provider   string
+	pairs      []PairedDuration
+	unmatched  []UnmatchedPair
+	open       map[string]map[string]openPair
+	state      *StateStore
+	stateError string
+}
+
+// RunAuditorOption defines options for creating run auditors
+type RunAuditorOption func(*RunAuditor)
+
+// WithRunChecks replaces the default end-of-run checks
+func WithRunChecks(checks ...RunCheck) RunAuditorOption {
+	return func(a *RunAuditor) {
+		a.checks = checks
+	}
+}
+
+// NewRunAuditor creates a new run auditor
+func NewRunAuditor(options ...RunAuditorOption) *RunAuditor {
+	a := &RunAuditor{
+		checks: DefaultRunChecks(),
+		counts: make(map[EventType]int),
+		open:   make(map[string]map[string]openPair),
+		state:  NewStateStore(),
+	}
+
+	for _, opt := range options {
+		opt(a)
+	}
+
+	return a
+}
+
+// Observe records the next event of the run
+func (a *RunAuditor) Observe(event Event) {
+	index := a.index
+	a.index++
+
+	a.counts[event.Type()]++
+	if ts := event.Timestamp(); ts != nil {
+		if a.firstTS == nil || *ts < *a.firstTS {
+			a.firstTS = ts
+		}
+		if a.lastTS == nil || *ts > *a.lastTS {
+			a.lastTS = ts
+		}
+	}
+
+	switch e := event.(type) {
+	case *RunStartedEvent:
+		if a.runID == "" {
+			a.threadID = e.ThreadID()
+			a.runID = e.RunID()
+			if e.AgentID != nil {
+				a.agent = *e.AgentID
+			}
+			if e.AgentVersion != nil {
+				a.agentVer = *e.AgentVersion
+			}
+			if e.ModelID != nil {
+				a.model = *e.ModelID
+			}
+			if e.ModelProvider != nil {
+				a.provider = *e.ModelProvider
+			}
+		}
+		if e.ConversationID != nil && a.convID == "" {
+			a.convID = *e.ConversationID
+		}
+		a.start(PairKindRun, e.RunID(), index, event)
+	case *RunFinishedEvent:
+		if e.ConversationID != nil && a.convID == "" {
+			a.convID = *e.ConversationID
+		}
+		a.end(PairKindRun, e.RunID(), index, event)
+	case *RunErrorEvent:
+		if e.RunID() != "" {
+			a.end(PairKindRun, e.RunID(), index, event)
+		}
+	case *StepStartedEvent:
+		a.start(PairKindStep, e.StepName, index, event)
+	case *StepFinishedEvent:
+		a.end(PairKindStep, e.StepName, index, event)
+	case *TextMessageStartEvent:
+		a.start(PairKindMessage, e.MessageID, index, event)
+	case *TextMessageEndEvent:
+		a.end(PairKindMessage, e.MessageID, index, event)
+	case *ToolCallStartEvent:
+		a.start(PairKindToolCall, e.ToolCallID, index, event)
+	case *ToolCallEndEvent:
+		a.end(PairKindToolCall, e.ToolCallID, index, event)
+	case *ThinkingStartEvent:
+		a.start(PairKindThinking, "", index, event)
+	case *ThinkingEndEvent:
+		a.end(PairKindThinking, "", index, event)
+	case *ThinkingTextMessageStartEvent:
+		a.start(PairKindThinkingMessage, "", index, event)
+	case *ThinkingTextMessageEndEvent:
+		a.end(PairKindThinkingMessage, "", index, event)
+	case *StateSnapshotEvent:
+		a.state.ApplySnapshot(e)
+	case *StateDeltaEvent:
+		if err := a.state.ApplyDelta(e); err != nil && a.stateError == "" {
+			a.stateError = fmt.Sprintf("event %d: %v", index, err)
+		}
+	}
+}
+
+// start records a start event
+func (a *RunAuditor) start(kind, id string, index int, event Event) {
+	if a.open[kind] == nil {
+		a.open[kind] = make(map[string]openPair)
+	}
+	a.open[kind][id] = openPair{index: index, timestamp: event.Timestamp()}
+}
+
+// end matches an end event with its start event
+func (a *RunAuditor) end(kind, id string, index int, event Event) {
+	started, ok := a.open[kind][id]
+	if !ok {
+		a.unmatched = append(a.unmatched, UnmatchedPair{Kind: kind, ID: id, Index: index, Missing: "start"})
+		return
+	}
+	delete(a.open[kind], id)
+
+	pair := PairedDuration{Kind: kind, ID: id, StartIndex: started.index, EndIndex: index}
+	if ts := event.Timestamp(); ts != nil && started.timestamp != nil {
+		pair.DurationMs = *ts - *started.timestamp
+	}
+	a.pairs = append(a.pairs, pair)
+}
+
+// Report builds the report for the events observed so far and runs the
+// end-of-run checks against it
+func (a *RunAuditor) Report() *RunReport {
+	report := &RunReport{
+		ThreadID:       a.threadID,
+		RunID:          a.runID,
+		EventCount:     a.index,
+		Counts:         make(map[EventType]int, len(a.counts)),
+		Pairs:          append([]PairedDuration{}, a.pairs...),
+		Unmatched:      append([]UnmatchedPair{}, a.unmatched...),
+		StateError:     a.stateError,
+		AgentID:        a.agent,
+		AgentVersion:   a.agentVer,
+		ConversationID: a.convID,
+		ModelID:        a.model,
+		ModelProvider:  a.provider,
+		Findings:       []ValidationFinding{},
+	}
+
+	for eventType, count := range a.counts {
+		report.Counts[eventType] = count
+	}
+
+	if a.firstTS != nil && a.lastTS != nil {
+		report.DurationMs = *a.lastTS - *a.firstTS
+	}
+
+	// Start events still waiting for their end event, in order of appearance
+	var pending []UnmatchedPair
+	for kind, open := range a.open {
+		for id, started := range open {
+			pending = append(pending, UnmatchedPair{Kind: kind, ID: id, Index: started.index, Missing: "end"})
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Index < pending[j].Index })
+	report.Unmatched = append(report.Unmatched, pending...)
+
+	for _, check := range a.checks {
+		report.Findings = append(report.Findings, check(report)...)
+	}
+
+	return report
+}
+
+// AuditRun observes every event of a completed run and returns its report
+func AuditRun(events []Event, options ...RunAuditorOption) *RunReport {
+	auditor := NewRunAuditor(options...)
+	for _, event := range events {
+		auditor.Observe(event)
+	}
+	return auditor.Report()
+}
@@ -0,0 +1,168 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// timed sets the event's timestamp and returns it
+func timed[T Event](event T, timestamp int64) T {
+	event.SetTimestamp(timestamp)
+	return event
+}
+
+func cannedRun() []Event {
+	return []Event{
+		timed(NewRunStartedEvent("thread-1", "run-1"), 1000),
+		timed(NewStateSnapshotEvent(map[string]any{"count": 0}), 1005),
+		timed(NewStepStartedEvent("plan"), 1010),
+		timed(NewTextMessageStartEvent("msg-1", WithRole("assistant")), 1020),
+		timed(NewTextMessageContentEvent("msg-1", "Let me check"), 1030),
+		timed(NewTextMessageEndEvent("msg-1"), 1070),
+		timed(NewToolCallStartEvent("tool-1", "search"), 1100),
+		timed(NewToolCallArgsEvent("tool-1", `{"q":"go"}`), 1110),
+		timed(NewToolCallEndEvent("tool-1"), 1200),
+		timed(NewToolCallStartEvent("tool-2", "fetch"), 1210),
+		timed(NewToolCallEndEvent("tool-2"), 1260),
+		timed(NewStateDeltaEvent([]JSONPatchOperation{{Op: "replace", Path: "/count", Value: 2}}), 1270),
+		timed(NewStepFinishedEvent("plan"), 1300),
+		timed(NewTextMessageStartEvent("msg-2", WithRole("assistant")), 1310),
+		timed(NewTextMessageContentEvent("msg-2", "Done"), 1320),
+		timed(NewTextMessageEndEvent("msg-2"), 1400),
+		timed(NewRunFinishedEvent("thread-1", "run-1"), 1500),
+	}
+}
+
+func TestRunAuditor(t *testing.T) {
+	t.Run("CompleteRun", func(t *testing.T) {
+		report := AuditRun(cannedRun())
+
+		assert.Equal(t, "thread-1", report.ThreadID)
+		assert.Equal(t, "run-1", report.RunID)
+		assert.Equal(t, 17, report.EventCount)
+		assert.Equal(t, int64(500), report.DurationMs)
+
+		assert.Equal(t, 2, report.Counts[EventTypeTextMessageStart])
+		assert.Equal(t, 2, report.Counts[EventTypeTextMessageContent])
+		assert.Equal(t, 2, report.Counts[EventTypeToolCallStart])
+		assert.Equal(t, 1, report.Counts[EventTypeToolCallArgs])
+		assert.Equal(t, 1, report.Counts[EventTypeStateDelta])
+		assert.Equal(t, 1, report.Counts[EventTypeRunStarted])
+
+		assert.Equal(t, []PairedDuration{
+			{Kind: PairKindMessage, ID: "msg-1", StartIndex: 3, EndIndex: 5, DurationMs: 50},
+			{Kind: PairKindToolCall, ID: "tool-1", StartIndex: 6, EndIndex: 8, DurationMs: 100},
+			{Kind: PairKindToolCall, ID: "tool-2", StartIndex: 9, EndIndex: 10, DurationMs: 50},
+			{Kind: PairKindStep, ID: "plan", StartIndex: 2, EndIndex: 12, DurationMs: 290},
+			{Kind: PairKindMessage, ID: "msg-2", StartIndex: 13, EndIndex: 15, DurationMs: 90},
+			{Kind: PairKindRun, ID: "run-1", StartIndex: 0, EndIndex: 16, DurationMs: 500},
+		}, report.Pairs)
+
+		assert.Empty(t, report.Unmatched)
+		assert.Empty(t, report.StateError)
+		assert.Empty(t, report.Findings)
+	})
+
+	t.Run("UnmatchedPairs", func(t *testing.T) {
+		report := AuditRun([]Event{
+			NewRunStartedEvent("thread-1", "run-1"),
+			NewTextMessageStartEvent("msg-1"),
+			NewToolCallEndEvent("tool-9"),
+		})
+
+		assert.Equal(t, []UnmatchedPair{
+			{Kind: PairKindToolCall, ID: "tool-9", Index: 2, Missing: "start"},
+			{Kind: PairKindRun, ID: "run-1", Index: 0, Missing: "end"},
+			{Kind: PairKindMessage, ID: "msg-1", Index: 1, Missing: "end"},
+		}, report.Unmatched)
+
+		require.Len(t, report.Findings, 3)
+		for _, finding := range report.Findings {
+			assert.Equal(t, RuleUnmatchedPair, finding.Rule)
+			assert.Equal(t, SeverityWarning, finding.Severity)
+		}
+	})
+
+	t.Run("EndOfRunChecks", func(t *testing.T) {
+		report := AuditRun([]Event{
+			timed(NewRunStartedEvent("thread-1", "run-1"), 0),
+			timed(NewStateDeltaEvent([]JSONPatchOperation{{Op: "remove", Path: "/missing"}}), 10),
+			timed(NewRunFinishedEvent("thread-1", "run-1"), int64(2*time.Hour/time.Millisecond)),
+		})
+
+		rules := make(map[string]ValidationSeverity)
+		for _, finding := range report.Findings {
+			rules[finding.Rule] = finding.Severity
+			assert.Equal(t, -1, finding.EventIndex)
+		}
+		assert.Equal(t, map[string]ValidationSeverity{
+			RuleRunWithoutActivity: SeverityError,
+			RuleStateInconsistent:  SeverityError,
+			RuleRunDuration:        SeverityWarning,
+		}, rules)
+		assert.Contains(t, report.StateError, "event 1")
+	})
+
+	t.Run("RunIdentity", func(t *testing.T) {
+		report := AuditRun([]Event{
+			NewRunStartedEventWithOptions("thread-1", "run-1",
+				WithAgentID("planner"),
+				WithAgentVersion("1.2.0"),
+				WithModelID("gpt-4o"),
+				WithModelProvider("openai")),
+			NewRunFinishedEventWithOptions("thread-1", "run-1", WithConversationID("conv-1")),
+		})
+
+		assert.Equal(t, "planner", report.AgentID)
+		assert.Equal(t, "1.2.0", report.AgentVersion)
+		assert.Equal(t, "gpt-4o", report.ModelID)
+		assert.Equal(t, "openai", report.ModelProvider)
+		assert.Equal(t, "conv-1", report.ConversationID)
+	})
+
+	t.Run("CustomChecks", func(t *testing.T) {
+		report := AuditRun([]Event{NewRunStartedEvent("thread-1", "run-1")},
+			WithRunChecks(CheckRunActivity()))
+
+		require.Len(t, report.Findings, 1)
+		assert.Equal(t, RuleRunWithoutActivity, report.Findings[0].Rule)
+	})
+
+	t.Run("Incremental", func(t *testing.T) {
+		auditor := NewRunAuditor()
+		run := cannedRun()
+
+		for _, event := range run[:7] {
+			auditor.Observe(event)
+		}
+		partial := auditor.Report()
+		assert.Equal(t, 7, partial.EventCount)
+		assert.Len(t, partial.Pairs, 1)
+		assert.Len(t, partial.Unmatched, 3)
+
+		for _, event := range run[7:] {
+			auditor.Observe(event)
+		}
+		assert.Equal(t, AuditRun(run), auditor.Report())
+	})
+
+	t.Run("MarshalJSON", func(t *testing.T) {
+		report := AuditRun([]Event{NewRunStartedEvent("thread-1", "run-1")})
+
+		data, err := json.Marshal(report)
+		require.NoError(t, err)
+
+		var decoded map[string]any
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, "run-1", decoded["runId"])
+		assert.Equal(t, map[string]any{"RUN_STARTED": float64(1)}, decoded["counts"])
+
+		findings := decoded["findings"].([]any)
+		require.NotEmpty(t, findings)
+		assert.Equal(t, "error", findings[0].(map[string]any)["severity"])
+	})
+}
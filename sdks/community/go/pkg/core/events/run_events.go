@@ -2,16 +2,34 @@ package events
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 )
 
 // RunStartedEvent indicates that an agent run has started
 type RunStartedEvent struct {
 	*BaseEvent
-	ThreadIDValue string `json:"threadId"`
-	RunIDValue    string `json:"runId"`
+	ThreadIDValue string  `json:"threadId"`
+	RunIDValue    string  `json:"runId"`
+	AgentID       *string `json:"agentId,omitempty"`
+	AgentVersion  *string `json:"agentVersion,omitempty"`
+
+	// ConversationID links the run to a conversation in the hosting platform,
+	// which is distinct from the protocol's ThreadID
+	ConversationID *string `json:"conversationId,omitempty"`
+
+	// ModelID and ModelProvider identify the LLM handling the run
+	ModelID       *string `json:"modelId,omitempty"`
+	ModelProvider *string `json:"modelProvider,omitempty"`
 }
 
+// Standard model providers for WithModelProvider
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderGoogle    = "google"
+)
+
 // NewRunStartedEvent creates a new run started event
 func NewRunStartedEvent(threadID, runID string) *RunStartedEvent {
 	return &RunStartedEvent{
@@ -57,6 +75,52 @@ func WithAutoThreadID() RunStartedOption {
 	}
 }
 
+// WithAgentID sets the identifier of the agent handling the run
+func WithAgentID(id string) RunStartedOption {
+	return func(e *RunStartedEvent) {
+		e.AgentID = &id
+	}
+}
+
+// WithAgentVersion sets the version of the agent handling the run
+func WithAgentVersion(version string) RunStartedOption {
+	return func(e *RunStartedEvent) {
+		e.AgentVersion = &version
+	}
+}
+
+// WithConversationIDStarted sets the hosting platform's conversation ID for
+// the run
+func WithConversationIDStarted(id string) RunStartedOption {
+	return func(e *RunStartedEvent) {
+		e.ConversationID = &id
+	}
+}
+
+// WithModelID sets the identifier of the LLM model handling the run
+func WithModelID(model string) RunStartedOption {
+	return func(e *RunStartedEvent) {
+		e.ModelID = &model
+	}
+}
+
+// WithModelProvider sets the provider of the LLM model handling the run, such
+// as ProviderOpenAI
+func WithModelProvider(provider string) RunStartedOption {
+	return func(e *RunStartedEvent) {
+		e.ModelProvider = &provider
+	}
+}
+
+// WithCorrelationID sets the correlation ID of the run, which
+// CorrelationIDMiddleware and PropagateCorrelationIDs propagate to the run's
+// later events
+func WithCorrelationID(id string) RunStartedOption {
+	return func(e *RunStartedEvent) {
+		e.CorrelationIDValue = &id
+	}
+}
+
 // Validate validates the run started event
 func (e *RunStartedEvent) Validate() error {
 	if err := e.BaseEvent.Validate(); err != nil {
@@ -64,11 +128,11 @@ func (e *RunStartedEvent) Validate() error {
 	}
 
 	if e.ThreadIDValue == "" {
-		return fmt.Errorf("RunStartedEvent validation failed: threadId field is required")
+		return newValidationError(EventTypeRunStarted, "threadId", "threadId field is required")
 	}
 
 	if e.RunIDValue == "" {
-		return fmt.Errorf("RunStartedEvent validation failed: runId field is required")
+		return newValidationError(EventTypeRunStarted, "runId", "runId field is required")
 	}
 
 	return nil
@@ -89,12 +153,48 @@ func (e *RunStartedEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// String returns a concise human-readable summary of the event
+func (e *RunStartedEvent) String() string {
+	fields := []string{summaryField("thread", e.ThreadIDValue), summaryField("run", e.RunIDValue)}
+	fields = appendOptionalField(fields, "agent", e.AgentID)
+	return formatEvent(EventTypeRunStarted, fields...)
+}
+
+// Clone returns a deep copy of the event
+func (e *RunStartedEvent) Clone() *RunStartedEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	clone.AgentID = cloneString(e.AgentID)
+	clone.AgentVersion = cloneString(e.AgentVersion)
+	clone.ConversationID = cloneString(e.ConversationID)
+	clone.ModelID = cloneString(e.ModelID)
+	clone.ModelProvider = cloneString(e.ModelProvider)
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *RunStartedEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *RunStartedEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeRunStarted, e)
+}
+
 // RunFinishedEvent indicates that an agent run has finished successfully
 type RunFinishedEvent struct {
 	*BaseEvent
 	ThreadIDValue string      `json:"threadId"`
 	RunIDValue    string      `json:"runId"`
 	Result        interface{} `json:"result,omitempty"`
+
+	// ConversationID links the run to a conversation in the hosting platform,
+	// which is distinct from the protocol's ThreadID
+	ConversationID *string `json:"conversationId,omitempty"`
 }
 
 // NewRunFinishedEvent creates a new run finished event
@@ -149,6 +249,13 @@ func WithResult(result interface{}) RunFinishedOption {
 	}
 }
 
+// WithConversationID sets the hosting platform's conversation ID for the run
+func WithConversationID(id string) RunFinishedOption {
+	return func(e *RunFinishedEvent) {
+		e.ConversationID = &id
+	}
+}
+
 // Validate validates the run finished event
 func (e *RunFinishedEvent) Validate() error {
 	if err := e.BaseEvent.Validate(); err != nil {
@@ -156,11 +263,11 @@ func (e *RunFinishedEvent) Validate() error {
 	}
 
 	if e.ThreadIDValue == "" {
-		return fmt.Errorf("RunFinishedEvent validation failed: threadId field is required")
+		return newValidationError(EventTypeRunFinished, "threadId", "threadId field is required")
 	}
 
 	if e.RunIDValue == "" {
-		return fmt.Errorf("RunFinishedEvent validation failed: runId field is required")
+		return newValidationError(EventTypeRunFinished, "runId", "runId field is required")
 	}
 
 	return nil
@@ -181,6 +288,56 @@ func (e *RunFinishedEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// String returns a concise human-readable summary of the event
+func (e *RunFinishedEvent) String() string {
+	return formatEvent(EventTypeRunFinished, summaryField("thread", e.ThreadIDValue), summaryField("run", e.RunIDValue))
+}
+
+// Clone returns a deep copy of the event
+func (e *RunFinishedEvent) Clone() *RunFinishedEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	clone.Result = deepCopyJSONValue(e.Result)
+	clone.ConversationID = cloneString(e.ConversationID)
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *RunFinishedEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *RunFinishedEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeRunFinished, e)
+}
+
+// ErrNoResult is returned by ResultAs when the run finished without a result
+var ErrNoResult = errors.New("run finished without a result")
+
+// ResultAs converts the result of a run finished event into a T by encoding
+// it to JSON and decoding it again, so that a result decoded from the wire
+// as generic JSON can be read as a struct. It returns ErrNoResult when the
+// event has no result. It is a function rather than a method because Go
+// methods cannot have type parameters.
+func ResultAs[T any](e *RunFinishedEvent) (T, error) {
+	var value T
+	if e == nil || e.Result == nil {
+		return value, ErrNoResult
+	}
+	data, err := json.Marshal(e.Result)
+	if err != nil {
+		return value, fmt.Errorf("failed to encode run result: %w", err)
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, fmt.Errorf("run result does not match %T: %w", value, err)
+	}
+	return value, nil
+}
+
 // RunErrorEvent indicates that an agent run has encountered an error
 type RunErrorEvent struct {
 	*BaseEvent
@@ -203,6 +360,52 @@ func NewRunErrorEvent(message string, options ...RunErrorOption) *RunErrorEvent
 	return event
 }
 
+// CodedError is implemented by errors carrying a machine-readable code, such
+// as "RATE_LIMITED", which NewRunErrorFromError uses as the code of the event
+type CodedError interface {
+	error
+	Code() string
+}
+
+// NewRunErrorFromError creates a run error event from a Go error, for
+// servers reporting the errors they catch. The message is err.Error(), and
+// the code is that of the first error in the chain of err implementing
+// CodedError with a non-empty code, so that a coded sentinel wrapped with
+// fmt.Errorf("...: %w", ...) still provides it. Options are applied
+// afterwards, so WithErrorCode overrides the code. A nil err gives the
+// message "unknown error".
+func NewRunErrorFromError(err error, options ...RunErrorOption) *RunErrorEvent {
+	if err == nil {
+		return NewRunErrorEvent("unknown error", options...)
+	}
+
+	if code := errorCode(err); code != "" {
+		options = append([]RunErrorOption{WithErrorCode(code)}, options...)
+	}
+	return NewRunErrorEvent(err.Error(), options...)
+}
+
+// errorCode returns the first non-empty code in the chain of err, or ""
+func errorCode(err error) string {
+	if coded, ok := err.(CodedError); ok && coded.Code() != "" {
+		return coded.Code()
+	}
+
+	switch wrapped := err.(type) {
+	case interface{ Unwrap() error }:
+		if inner := wrapped.Unwrap(); inner != nil {
+			return errorCode(inner)
+		}
+	case interface{ Unwrap() []error }:
+		for _, inner := range wrapped.Unwrap() {
+			if code := errorCode(inner); code != "" {
+				return code
+			}
+		}
+	}
+	return ""
+}
+
 // RunErrorOption defines options for creating run error events
 type RunErrorOption func(*RunErrorEvent)
 
@@ -236,7 +439,7 @@ func (e *RunErrorEvent) Validate() error {
 	}
 
 	if e.Message == "" {
-		return fmt.Errorf("RunErrorEvent validation failed: message field is required")
+		return newValidationError(EventTypeRunError, "message", "message field is required")
 	}
 
 	return nil
@@ -252,6 +455,38 @@ func (e *RunErrorEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// String returns a concise human-readable summary of the event
+func (e *RunErrorEvent) String() string {
+	var fields []string
+	if e.RunIDValue != "" {
+		fields = append(fields, summaryField("run", e.RunIDValue))
+	}
+	fields = appendOptionalField(fields, "code", e.Code)
+	fields = append(fields, summaryText("message", e.Message))
+	return formatEvent(EventTypeRunError, fields...)
+}
+
+// Clone returns a deep copy of the event
+func (e *RunErrorEvent) Clone() *RunErrorEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	clone.Code = cloneString(e.Code)
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *RunErrorEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *RunErrorEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeRunError, e)
+}
+
 // StepStartedEvent indicates that an agent step has started
 type StepStartedEvent struct {
 	*BaseEvent
@@ -299,7 +534,7 @@ func (e *StepStartedEvent) Validate() error {
 	}
 
 	if e.StepName == "" {
-		return fmt.Errorf("StepStartedEvent validation failed: stepName field is required")
+		return newValidationError(EventTypeStepStarted, "stepName", "stepName field is required")
 	}
 
 	return nil
@@ -310,6 +545,31 @@ func (e *StepStartedEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// String returns a concise human-readable summary of the event
+func (e *StepStartedEvent) String() string {
+	return formatEvent(EventTypeStepStarted, summaryField("step", e.StepName))
+}
+
+// Clone returns a deep copy of the event
+func (e *StepStartedEvent) Clone() *StepStartedEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *StepStartedEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *StepStartedEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeStepStarted, e)
+}
+
 // StepFinishedEvent indicates that an agent step has finished
 type StepFinishedEvent struct {
 	*BaseEvent
@@ -357,7 +617,7 @@ func (e *StepFinishedEvent) Validate() error {
 	}
 
 	if e.StepName == "" {
-		return fmt.Errorf("StepFinishedEvent validation failed: stepName field is required")
+		return newValidationError(EventTypeStepFinished, "stepName", "stepName field is required")
 	}
 
 	return nil
@@ -367,3 +627,28 @@ func (e *StepFinishedEvent) Validate() error {
 func (e *StepFinishedEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
+
+// String returns a concise human-readable summary of the event
+func (e *StepFinishedEvent) String() string {
+	return formatEvent(EventTypeStepFinished, summaryField("step", e.StepName))
+}
+
+// Clone returns a deep copy of the event
+func (e *StepFinishedEvent) Clone() *StepFinishedEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *StepFinishedEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *StepFinishedEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeStepFinished, e)
+}
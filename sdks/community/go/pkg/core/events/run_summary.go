@@ -0,0 +1,232 @@
+package events
+
+// RunSummary is a compact record of a run, for storing after each run. It
+// holds counts, sizes and durations only, no content. Durations are computed
+// from event timestamps; a duration whose start or end event, or either
+// timestamp, is missing is zero and marked incomplete.
+type RunSummary struct {
+	ThreadID string `json:"threadId,omitempty"`
+	RunID    string `json:"runId,omitempty"`
+
+	DurationMs int64 `json:"durationMs"`
+	Incomplete bool  `json:"incomplete,omitempty"`
+
+	// MessageCount counts the streamed text messages, and ContentBytes the
+	// size of their deltas and of the text inserted by their edits
+	MessageCount int `json:"messageCount"`
+	ContentBytes int `json:"contentBytes"`
+
+	// ToolCalls holds the tool calls in the order they started
+	ToolCalls []ToolCallSummary `json:"toolCalls"`
+
+	// Error reports whether the run ended with RUN_ERROR, with its code
+	Error     bool   `json:"error"`
+	ErrorCode string `json:"errorCode,omitempty"`
+
+	// FinalStateBytes is the size of the state as JSON once the snapshots
+	// and deltas of the run are applied, or zero without state events.
+	// Deltas that cannot be applied are skipped.
+	FinalStateBytes int `json:"finalStateBytes"`
+}
+
+// ToolCallSummary records a tool call of a run. Its duration runs from its
+// TOOL_CALL_START event to its TOOL_CALL_RESULT event.
+type ToolCallSummary struct {
+	ToolCallID   string `json:"toolCallId"`
+	ToolCallName string `json:"toolCallName,omitempty"`
+	DurationMs   int64  `json:"durationMs"`
+	Incomplete   bool   `json:"incomplete,omitempty"`
+	ArgsBytes    int    `json:"argsBytes"`
+	ResultBytes  int    `json:"resultBytes"`
+}
+
+// RunSummarizer builds a RunSummary from the events of a run as they
+// arrive, without retaining their content, so it can follow long runs. Each
+// RUN_FINISHED or RUN_ERROR event yields the summary of the run and starts a
+// new one. The state of the run is kept to measure its final size. It is
+// not safe for concurrent use.
+type RunSummarizer struct {
+	summary RunSummary
+	startTS *int64
+
+	// toolCalls indexes the tool calls of the summary by ID, with the
+	// timestamp of their start event
+	toolCalls     map[string]int
+	toolCallStart map[string]*int64
+
+	chunkMessageID  string
+	chunkToolCallID string
+
+	state    *StateStore
+	hasState bool
+}
+
+// NewRunSummarizer creates a new run summarizer
+func NewRunSummarizer() *RunSummarizer {
+	s := &RunSummarizer{}
+	s.reset()
+	return s
+}
+
+// reset starts the summary of a new run
+func (s *RunSummarizer) reset() {
+	s.summary = RunSummary{ToolCalls: []ToolCallSummary{}}
+	s.startTS = nil
+	s.toolCalls = make(map[string]int)
+	s.toolCallStart = make(map[string]*int64)
+	s.chunkMessageID = ""
+	s.chunkToolCallID = ""
+	s.state = NewStateStore()
+	s.hasState = false
+}
+
+// Process records the next event. It returns the summary of the run at
+// RUN_FINISHED and RUN_ERROR events, and nil otherwise.
+func (s *RunSummarizer) Process(event Event) *RunSummary {
+	switch e := event.(type) {
+	case *RunStartedEvent:
+		s.summary.ThreadID = e.ThreadID()
+		s.summary.RunID = e.RunID()
+		s.startTS = cloneInt64(e.Timestamp())
+
+	case *RunFinishedEvent:
+		if s.summary.RunID == "" {
+			s.summary.ThreadID = e.ThreadID()
+			s.summary.RunID = e.RunID()
+		}
+		return s.finish(event)
+
+	case *RunErrorEvent:
+		if s.summary.RunID == "" {
+			s.summary.RunID = e.RunID()
+		}
+		s.summary.Error = true
+		if e.Code != nil {
+			s.summary.ErrorCode = *e.Code
+		}
+		return s.finish(event)
+
+	case *TextMessageStartEvent:
+		s.summary.MessageCount++
+
+	case *TextMessageContentEvent:
+		s.summary.ContentBytes += len(e.Delta)
+		if e.Edit != nil {
+			s.summary.ContentBytes += len(e.Edit.Insert)
+		}
+
+	case *TextMessageChunkEvent:
+		if e.MessageID != nil && *e.MessageID != "" && *e.MessageID != s.chunkMessageID {
+			s.chunkMessageID = *e.MessageID
+			s.summary.MessageCount++
+		}
+		if e.Delta != nil {
+			s.summary.ContentBytes += len(*e.Delta)
+		}
+
+	case *ToolCallStartEvent:
+		s.startToolCall(e.ToolCallID, e.ToolCallName, e.Timestamp())
+
+	case *ToolCallArgsEvent:
+		if call := s.toolCall(e.ToolCallID); call != nil {
+			call.ArgsBytes += len(e.Delta)
+		}
+
+	case *ToolCallChunkEvent:
+		if e.ToolCallID != nil && *e.ToolCallID != "" && *e.ToolCallID != s.chunkToolCallID {
+			s.chunkToolCallID = *e.ToolCallID
+			s.startToolCall(s.chunkToolCallID, stringValue(e.ToolCallName), e.Timestamp())
+		}
+		if call := s.toolCall(s.chunkToolCallID); call != nil && e.Delta != nil {
+			call.ArgsBytes += len(*e.Delta)
+		}
+
+	case *ToolCallResultEvent:
+		s.endToolCall(e)
+
+	case *StateSnapshotEvent:
+		s.hasState = true
+		s.state.ApplySnapshot(e)
+
+	case *StateDeltaEvent:
+		// A delta that cannot be applied leaves the state unchanged
+		s.hasState = true
+		_ = s.state.ApplyDelta(e)
+	}
+	return nil
+}
+
+// startToolCall adds a tool call to the summary, unless it already started
+func (s *RunSummarizer) startToolCall(id, name string, ts *int64) {
+	if _, ok := s.toolCalls[id]; ok {
+		return
+	}
+	s.toolCalls[id] = len(s.summary.ToolCalls)
+	s.toolCallStart[id] = cloneInt64(ts)
+	s.summary.ToolCalls = append(s.summary.ToolCalls, ToolCallSummary{
+		ToolCallID:   id,
+		ToolCallName: name,
+		Incomplete:   true,
+	})
+}
+
+// toolCall returns the summary of a started tool call, or nil
+func (s *RunSummarizer) toolCall(id string) *ToolCallSummary {
+	index, ok := s.toolCalls[id]
+	if !ok {
+		return nil
+	}
+	return &s.summary.ToolCalls[index]
+}
+
+// endToolCall records the result of a tool call. A result without a start
+// adds an incomplete tool call.
+func (s *RunSummarizer) endToolCall(e *ToolCallResultEvent) {
+	call := s.toolCall(e.ToolCallID)
+	if call == nil {
+		s.startToolCall(e.ToolCallID, "", nil)
+		call = s.toolCall(e.ToolCallID)
+		call.ResultBytes = len(e.Content)
+		return
+	}
+
+	call.ResultBytes = len(e.Content)
+	if duration, ok := durationMs(s.toolCallStart[e.ToolCallID], e.Timestamp()); ok {
+		call.DurationMs = duration
+		call.Incomplete = false
+	}
+}
+
+// finish completes the summary at a terminal event, returns it and starts a
+// new one
+func (s *RunSummarizer) finish(terminal Event) *RunSummary {
+	summary := s.Summary()
+	if duration, ok := durationMs(s.startTS, terminal.Timestamp()); ok {
+		summary.DurationMs = duration
+		summary.Incomplete = false
+	}
+	s.reset()
+	return &summary
+}
+
+// Summary returns the summary of the current run so far, for runs whose
+// stream ends without a terminal event. Its duration is incomplete.
+func (s *RunSummarizer) Summary() RunSummary {
+	summary := s.summary
+	summary.ToolCalls = append([]ToolCallSummary{}, s.summary.ToolCalls...)
+	summary.Incomplete = true
+
+	if s.hasState {
+		summary.FinalStateBytes = len(s.state.CurrentRaw())
+	}
+	return summary
+}
+
+// durationMs returns the milliseconds between two timestamps, and whether
+// both are known. Timestamps out of order give a zero duration.
+func durationMs(start, end *int64) (int64, bool) {
+	if start == nil || end == nil {
+		return 0, false
+	}
+	return max(*end-*start, 0), true
+}
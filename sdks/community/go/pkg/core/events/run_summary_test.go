@@ -0,0 +1,116 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// summarize processes events and returns the summaries they yielded
+func summarize(s *RunSummarizer, events ...Event) []*RunSummary {
+	var summaries []*RunSummary
+	for _, e := range events {
+		if summary := s.Process(e); summary != nil {
+			summaries = append(summaries, summary)
+		}
+	}
+	return summaries
+}
+
+func TestRunSummarizer(t *testing.T) {
+	t.Run("MultiToolRun", func(t *testing.T) {
+		s := NewRunSummarizer()
+		summaries := summarize(s,
+			timed(NewRunStartedEvent("thread-1", "run-1"), 1000),
+			timed(NewStateSnapshotEvent(map[string]any{"step": 1}), 1001),
+			timed(NewTextMessageStartEvent("msg-1"), 1010),
+			timed(NewTextMessageContentEvent("msg-1", "Looking "), 1020),
+			timed(NewTextMessageContentEvent("msg-1", "up"), 1030),
+			timed(NewTextMessageEndEvent("msg-1"), 1040),
+			timed(NewToolCallStartEvent("call-1", "search"), 1100),
+			timed(NewToolCallArgsEvent("call-1", `{"q":"go"}`), 1110),
+			timed(NewToolCallEndEvent("call-1"), 1120),
+			timed(NewToolCallStartEvent("call-2", "fetch"), 1150),
+			timed(NewToolCallArgsEvent("call-2", `{}`), 1160),
+			timed(NewToolCallResultEvent("res-1", "call-1", "three results"), 1400),
+			timed(NewToolCallResultEvent("res-2", "call-2", "page"), 1250),
+			timed(NewToolCallChunkEvent().WithToolCallChunkID("call-3").WithToolCallChunkName("sum").WithToolCallChunkDelta(`[1,`), 1500),
+			timed(NewToolCallChunkEvent().WithToolCallChunkDelta(`2]`), 1510),
+			timed(NewTextMessageChunkEvent(nil, nil, nil).WithChunkMessageID("msg-2").WithChunkDelta("Done"), 1600),
+			timed(NewStateDeltaEvent([]JSONPatchOperation{{Op: "replace", Path: "/step", Value: 2}}), 1700),
+			timed(NewRunFinishedEvent("thread-1", "run-1"), 2000),
+		)
+
+		require.Len(t, summaries, 1)
+		assert.Equal(t, &RunSummary{
+			ThreadID:     "thread-1",
+			RunID:        "run-1",
+			DurationMs:   1000,
+			MessageCount: 2,
+			ContentBytes: 14,
+			ToolCalls: []ToolCallSummary{
+				{ToolCallID: "call-1", ToolCallName: "search", DurationMs: 300, ArgsBytes: 10, ResultBytes: 13},
+				{ToolCallID: "call-2", ToolCallName: "fetch", DurationMs: 100, ArgsBytes: 2, ResultBytes: 4},
+				{ToolCallID: "call-3", ToolCallName: "sum", Incomplete: true, ArgsBytes: 5},
+			},
+			FinalStateBytes: len(`{"step":2}`),
+		}, summaries[0])
+
+		data, err := json.Marshal(summaries[0])
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"toolCalls":[{"toolCallId":"call-1","toolCallName":"search","durationMs":300,"argsBytes":10,"resultBytes":13}`)
+		assert.Contains(t, string(data), `"error":false`)
+	})
+
+	t.Run("ErroredRun", func(t *testing.T) {
+		s := NewRunSummarizer()
+		summaries := summarize(s,
+			timed(NewRunStartedEvent("thread-1", "run-2"), 5000),
+			timed(NewTextMessageStartEvent("msg-1"), 5010),
+			timed(NewTextMessageContentEvent("msg-1", "Partial"), 5020),
+			timed(NewToolCallStartEvent("call-1", "search"), 5100),
+			// A result without a start is kept as incomplete
+			timed(NewToolCallResultEvent("res-9", "call-9", "orphan"), 5200),
+			timed(NewRunErrorEvent("upstream failed", WithErrorCode("UPSTREAM"), WithRunID("run-2")), 5750),
+		)
+
+		require.Len(t, summaries, 1)
+		assert.Equal(t, &RunSummary{
+			ThreadID:     "thread-1",
+			RunID:        "run-2",
+			DurationMs:   750,
+			MessageCount: 1,
+			ContentBytes: 7,
+			ToolCalls: []ToolCallSummary{
+				{ToolCallID: "call-1", ToolCallName: "search", Incomplete: true},
+				{ToolCallID: "call-9", Incomplete: true, ResultBytes: 6},
+			},
+			Error:     true,
+			ErrorCode: "UPSTREAM",
+		}, summaries[0])
+	})
+
+	t.Run("MissingPairs", func(t *testing.T) {
+		s := NewRunSummarizer()
+
+		// Without a start event the run duration is incomplete
+		summaries := summarize(s, timed(NewRunFinishedEvent("thread-1", "run-3"), 100))
+		require.Len(t, summaries, 1)
+		assert.True(t, summaries[0].Incomplete)
+		assert.Equal(t, "run-3", summaries[0].RunID)
+		assert.Empty(t, summaries[0].ToolCalls)
+
+		// A new summary starts after each terminal event, and Summary
+		// reports the run still in progress
+		assert.Empty(t, summarize(s,
+			timed(NewRunStartedEvent("thread-1", "run-4"), 200),
+			NewTextMessageStartEvent("msg-1"),
+		))
+		summary := s.Summary()
+		assert.Equal(t, "run-4", summary.RunID)
+		assert.Equal(t, 1, summary.MessageCount)
+		assert.True(t, summary.Incomplete)
+	})
+}
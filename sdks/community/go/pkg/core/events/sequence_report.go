@@ -0,0 +1,213 @@
+package events
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Rule codes reported for a sequence as a whole by ValidateSequence
+const (
+	RuleEmptySequence     = "empty_sequence"
+	RuleMissingRunStarted = "missing_run_started"
+)
+
+// DefaultContextWindow is the number of neighboring events summarized on each
+// side of a finding by the built-in profiles
+const DefaultContextWindow = 2
+
+// Profile configures a batch sequence validation
+type Profile struct {
+	// Name identifies the profile in reports
+	Name string
+	// Options configure the underlying EventSequenceValidator
+	Options []SequenceValidatorOption
+	// ContextWindow is the number of neighboring events summarized on each
+	// side of a finding. A window of 0 includes only the event itself.
+	ContextWindow int
+}
+
+// DefaultProfile fails on protocol errors and reports warnings without failing
+func DefaultProfile() Profile {
+	return Profile{Name: "default", ContextWindow: DefaultContextWindow}
+}
+
+// StrictProfile fails on warnings as well as protocol errors
+func StrictProfile() Profile {
+	return Profile{
+		Name:          "strict",
+		Options:       []SequenceValidatorOption{FailOn(SeverityWarning)},
+		ContextWindow: DefaultContextWindow,
+	}
+}
+
+// EventSummary is the one-line summary of an event at a position in a sequence
+type EventSummary struct {
+	Index   int    `json:"index"`
+	Summary string `json:"summary"`
+}
+
+// ReportedFinding is a validation finding together with the events around it
+type ReportedFinding struct {
+	ValidationFinding
+	Context []EventSummary `json:"context,omitempty"`
+}
+
+// SequenceReport is the result of a batch sequence validation. Findings are
+// ordered by event index; findings about the sequence as a whole have an
+// EventIndex of -1 and no context.
+type SequenceReport struct {
+	Profile    string                    `json:"profile"`
+	EventCount int                       `json:"eventCount"`
+	Findings   []ReportedFinding         `json:"findings"`
+	Result     *SequenceValidationResult `json:"result"`
+}
+
+// Failed reports whether any finding reached the profile's failure severity
+func (r *SequenceReport) Failed() bool {
+	return r.Result.Failed
+}
+
+// FindingsAt returns the findings reported for the event at the given index
+func (r *SequenceReport) FindingsAt(index int) []ValidationFinding {
+	var findings []ValidationFinding
+	for _, finding := range r.Findings {
+		if finding.EventIndex == index {
+			findings = append(findings, finding.ValidationFinding)
+		}
+	}
+	return findings
+}
+
+// ByIndex groups the findings by the index of the event they refer to
+func (r *SequenceReport) ByIndex() map[int][]ValidationFinding {
+	byIndex := make(map[int][]ValidationFinding)
+	for _, finding := range r.Findings {
+		byIndex[finding.EventIndex] = append(byIndex[finding.EventIndex], finding.ValidationFinding)
+	}
+	return byIndex
+}
+
+// WriteText renders the report as human-readable text, showing the summaries
+// of the events around each finding and marking the offending event with >
+func (r *SequenceReport) WriteText(w io.Writer) error {
+	status := "passed"
+	if r.Failed() {
+		status = "FAILED"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "sequence validation %s (profile %s): %d events, %d errors, %d warnings, %d infos\n",
+		status, r.Profile, r.EventCount, len(r.Result.Errors), len(r.Result.Warnings), len(r.Result.Infos))
+
+	for _, finding := range r.Findings {
+		if finding.EventIndex < 0 {
+			fmt.Fprintf(&b, "\n[%s] %s: %s\n", finding.Severity, finding.Rule, finding.Message)
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n[%s] event %d %s: %s\n", finding.Severity, finding.EventIndex, finding.Rule, finding.Message)
+		for _, summary := range finding.Context {
+			marker := " "
+			if summary.Index == finding.EventIndex {
+				marker = ">"
+			}
+			fmt.Fprintf(&b, "  %s %4d  %s\n", marker, summary.Index, summary.Summary)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// String renders the report as human-readable text
+func (r *SequenceReport) String() string {
+	var b strings.Builder
+	_ = r.WriteText(&b)
+	return b.String()
+}
+
+// ValidateSequence validates a complete sequence of events according to
+// AG-UI protocol rules and the given profile. The report is always returned;
+// the error is the first finding at or above the profile's failure severity,
+// or nil if the sequence passed.
+func ValidateSequence(events []Event, profile Profile) (*SequenceReport, error) {
+	validator := NewEventSequenceValidator(profile.Options...)
+
+	if len(events) == 0 {
+		validator.result.add(ValidationFinding{
+			Severity:   SeverityError,
+			Rule:       RuleEmptySequence,
+			Message:    "sequence contains no events",
+			EventIndex: -1,
+		})
+	}
+
+	hasRunStarted := false
+	for _, event := range events {
+		if event != nil && event.Type() == EventTypeRunStarted {
+			hasRunStarted = true
+		}
+		validator.ValidateEvent(event)
+	}
+	validator.Finish()
+
+	if len(events) > 0 && !hasRunStarted {
+		validator.result.add(ValidationFinding{
+			Severity:   SeverityWarning,
+			Rule:       RuleMissingRunStarted,
+			Message:    "sequence does not contain a RUN_STARTED event",
+			EventIndex: -1,
+		})
+	}
+
+	result := validator.Result()
+	report := &SequenceReport{
+		Profile:    profile.Name,
+		EventCount: len(events),
+		Findings:   []ReportedFinding{},
+		Result:     result,
+	}
+
+	for _, findings := range [][]ValidationFinding{result.Errors, result.Warnings, result.Infos} {
+		for _, finding := range findings {
+			report.Findings = append(report.Findings, ReportedFinding{
+				ValidationFinding: finding,
+				Context:           eventContext(events, finding.EventIndex, profile.ContextWindow),
+			})
+		}
+	}
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		return report.Findings[i].EventIndex < report.Findings[j].EventIndex
+	})
+
+	return report, result.Err()
+}
+
+// eventContext summarizes the events within window positions of index
+func eventContext(events []Event, index, window int) []EventSummary {
+	if index < 0 || index >= len(events) {
+		return nil
+	}
+
+	start := max(index-window, 0)
+	end := min(index+window, len(events)-1)
+
+	summaries := make([]EventSummary, 0, end-start+1)
+	for i := start; i <= end; i++ {
+		summaries = append(summaries, EventSummary{Index: i, Summary: summarizeEvent(events[i])})
+	}
+	return summaries
+}
+
+// summarizeEvent returns the one-line summary of an event
+func summarizeEvent(event Event) string {
+	if event == nil {
+		return "<nil>"
+	}
+	if stringer, ok := event.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return formatEvent(event.Type())
+}
@@ -0,0 +1,133 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSequenceReport(t *testing.T) {
+	t.Run("CleanRun", func(t *testing.T) {
+		report, err := ValidateSequence([]Event{
+			NewRunStartedEvent("thread-1", "run-1"),
+			NewTextMessageStartEvent("msg-1", WithRole("assistant")),
+			NewTextMessageContentEvent("msg-1", "Hello"),
+			NewTextMessageEndEvent("msg-1"),
+			NewRunFinishedEvent("thread-1", "run-1"),
+		}, DefaultProfile())
+		require.NoError(t, err)
+
+		assert.False(t, report.Failed())
+		assert.Equal(t, "default", report.Profile)
+		assert.Equal(t, 5, report.EventCount)
+		assert.Empty(t, report.Findings)
+		assert.Contains(t, report.String(), "sequence validation passed (profile default): 5 events, 0 errors")
+	})
+
+	t.Run("BrokenRun", func(t *testing.T) {
+		events := []Event{
+			NewRunStartedEvent("thread-1", "run-1"),
+			NewTextMessageStartEvent("msg-1"),
+			NewTextMessageContentEvent("msg-1", "Hello"),
+			NewTextMessageEndEvent("msg-1"),
+			NewTextMessageEndEvent("msg-1"),
+			NewToolCallResultEvent("msg-2", "tool-1", "42"),
+			NewRunFinishedEvent("thread-1", "run-1"),
+		}
+
+		report, err := ValidateSequence(events, DefaultProfile())
+		require.Error(t, err)
+		assert.True(t, report.Failed())
+
+		require.Len(t, report.Findings, 2)
+		broken := report.Findings[0]
+		assert.Equal(t, RuleMessageNotStarted, broken.Rule)
+		assert.Equal(t, 4, broken.EventIndex)
+		assert.Equal(t, RuleToolResultUnknownToolCall, report.Findings[1].Rule)
+		assert.Equal(t, 5, report.Findings[1].EventIndex)
+
+		// The finding is addressable by event index
+		assert.Len(t, report.FindingsAt(4), 1)
+		assert.Empty(t, report.FindingsAt(0))
+		assert.Len(t, report.ByIndex(), 2)
+
+		// Neighboring events are summarized for context
+		indexes := make([]int, len(broken.Context))
+		for i, summary := range broken.Context {
+			indexes[i] = summary.Index
+		}
+		assert.Equal(t, []int{2, 3, 4, 5, 6}, indexes)
+		assert.Equal(t, "TEXT_MESSAGE_END(msg=msg-1)", broken.Context[2].Summary)
+
+		text := report.String()
+		assert.Contains(t, text, "sequence validation FAILED")
+		assert.Contains(t, text, "[error] event 4 message_not_started")
+		assert.Contains(t, text, ">    4  TEXT_MESSAGE_END(msg=msg-1)")
+
+		data, err := json.Marshal(report)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"context":[{"index":2`)
+	})
+
+	t.Run("ContextWindowClampsAtEdges", func(t *testing.T) {
+		report, _ := ValidateSequence([]Event{
+			NewTextMessageEndEvent("msg-1"),
+			NewRunStartedEvent("thread-1", "run-1"),
+		}, Profile{Name: "narrow", ContextWindow: 1})
+
+		require.Len(t, report.Findings, 1)
+		assert.Equal(t, 0, report.Findings[0].EventIndex)
+		assert.Equal(t, []EventSummary{
+			{Index: 0, Summary: "TEXT_MESSAGE_END(msg=msg-1)"},
+			{Index: 1, Summary: "RUN_STARTED(thread=thread-1 run=run-1)"},
+		}, report.Findings[0].Context)
+	})
+
+	t.Run("EmptySequence", func(t *testing.T) {
+		report, err := ValidateSequence(nil, DefaultProfile())
+		require.Error(t, err)
+
+		require.Len(t, report.Findings, 1)
+		assert.Equal(t, RuleEmptySequence, report.Findings[0].Rule)
+		assert.Equal(t, -1, report.Findings[0].EventIndex)
+		assert.Nil(t, report.Findings[0].Context)
+		assert.Contains(t, report.String(), "[error] empty_sequence: sequence contains no events")
+	})
+
+	t.Run("MissingRunStarted", func(t *testing.T) {
+		events := []Event{
+			NewTextMessageStartEvent("msg-1"),
+			NewTextMessageContentEvent("msg-1", "Hello"),
+			NewTextMessageEndEvent("msg-1"),
+		}
+
+		report, err := ValidateSequence(events, DefaultProfile())
+		require.NoError(t, err)
+		require.Len(t, report.Findings, 1)
+		assert.Equal(t, RuleMissingRunStarted, report.Findings[0].Rule)
+		assert.Equal(t, SeverityWarning, report.Findings[0].Severity)
+
+		// The strict profile fails on the same sequence
+		_, err = ValidateSequence(events, StrictProfile())
+		var finding *ValidationFinding
+		require.ErrorAs(t, err, &finding)
+		assert.Equal(t, RuleMissingRunStarted, finding.Rule)
+	})
+
+	t.Run("NilEvent", func(t *testing.T) {
+		report, err := ValidateSequence([]Event{
+			NewRunStartedEvent("thread-1", "run-1"),
+			nil,
+			NewRunFinishedEvent("thread-1", "run-1"),
+		}, DefaultProfile())
+		require.Error(t, err)
+
+		require.Len(t, report.Findings, 1)
+		assert.Equal(t, RuleEventInvalid, report.Findings[0].Rule)
+		assert.Equal(t, 1, report.Findings[0].EventIndex)
+		assert.Equal(t, "<nil>", report.Findings[0].Context[1].Summary)
+		assert.Contains(t, report.String(), "event at index 1 is nil")
+	})
+}
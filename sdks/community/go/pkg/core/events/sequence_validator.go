@@ -0,0 +1,727 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationSeverity classifies how serious a validation finding is
+type ValidationSeverity int
+
+const (
+	// SeverityInfo marks an informational finding
+	SeverityInfo ValidationSeverity = iota
+	// SeverityWarning marks a suspicious but protocol-legal pattern
+	SeverityWarning
+	// SeverityError marks a protocol violation
+	SeverityError
+)
+
+// String returns the string representation of the severity
+func (s ValidationSeverity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the severity as its string representation
+func (s ValidationSeverity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a severity from its string representation
+func (s *ValidationSeverity) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "info":
+		*s = SeverityInfo
+	case "warning":
+		*s = SeverityWarning
+	case "error":
+		*s = SeverityError
+	default:
+		return fmt.Errorf("unknown validation severity: %s", string(text))
+	}
+	return nil
+}
+
+// Rule codes reported by the EventSequenceValidator
+const (
+	RuleEventInvalid              = "event_invalid"
+	RuleRunAlreadyStarted         = "run_already_started"
+	RuleRunRestarted              = "run_restarted"
+	RuleRunNotStarted             = "run_not_started"
+	RuleStepAlreadyStarted        = "step_already_started"
+	RuleStepNotStarted            = "step_not_started"
+	RuleMessageAlreadyStarted     = "message_already_started"
+	RuleMessageNotStarted         = "message_not_started"
+	RuleToolCallAlreadyStarted    = "tool_call_already_started"
+	RuleToolCallNotStarted        = "tool_call_not_started"
+	RuleLargeDelta                = "large_delta"
+	RuleBlankToolResult           = "blank_tool_result"
+	RuleToolResultUnknownToolCall = "tool_result_unknown_tool_call"
+	RuleUnknownCustomEvent        = "unknown_custom_event"
+	RuleMixedMessageStyles        = "mixed_message_styles"
+	RuleMixedToolCallStyles       = "mixed_tool_call_styles"
+	RuleChunkWithoutDelta         = "chunk_without_delta"
+	RuleChunkRoleChanged          = "chunk_role_changed"
+	RuleEventTooLarge             = "event_too_large"
+	RuleValueTooDeep              = "value_too_deep"
+	RuleValueTooManyKeys          = "value_too_many_keys"
+)
+
+const (
+	// DefaultMaxDeltaLength is the delta size in bytes above which a warning is reported
+	DefaultMaxDeltaLength = 64 * 1024
+	// DefaultMaxEventSize is the serialized event size in bytes above which an error is reported
+	DefaultMaxEventSize = 1024 * 1024
+	// DefaultMaxValueDepth is the nesting depth of state snapshots and custom
+	// event values above which an error is reported
+	DefaultMaxValueDepth = 32
+	// DefaultMaxValueKeys is the total key count of state snapshots and custom
+	// event values above which an error is reported
+	DefaultMaxValueKeys = 10000
+)
+
+// ValidationFinding describes a single problem detected in an event sequence
+type ValidationFinding struct {
+	Severity   ValidationSeverity `json:"severity"`
+	Rule       string             `json:"rule"`
+	Message    string             `json:"message"`
+	EventIndex int                `json:"eventIndex"`
+	EventType  EventType          `json:"eventType,omitempty"`
+
+	cause error
+}
+
+// Error implements the error interface
+func (f *ValidationFinding) Error() string {
+	return f.Message
+}
+
+// Unwrap returns the underlying error, if any
+func (f *ValidationFinding) Unwrap() error {
+	return f.cause
+}
+
+// As matches *SequenceError and *EventError targets, describing the finding
+// as a SequenceError
+func (f *ValidationFinding) As(target any) bool {
+	sequenceErr := &SequenceError{
+		EventError: EventError{EventType: f.EventType, Message: f.Message},
+		EventIndex: f.EventIndex,
+		Rule:       f.Rule,
+		Err:        f.cause,
+	}
+	if t, ok := target.(**SequenceError); ok {
+		*t = sequenceErr
+		return true
+	}
+	return sequenceErr.As(target)
+}
+
+// SequenceValidationResult collects the findings of a sequence validation,
+// split by severity
+type SequenceValidationResult struct {
+	Errors   []ValidationFinding `json:"errors"`
+	Warnings []ValidationFinding `json:"warnings"`
+	Infos    []ValidationFinding `json:"infos"`
+	FailOn   ValidationSeverity  `json:"failOn"`
+	Failed   bool                `json:"failed"`
+}
+
+// add records a finding and updates the failure state
+func (r *SequenceValidationResult) add(finding ValidationFinding) {
+	switch finding.Severity {
+	case SeverityError:
+		r.Errors = append(r.Errors, finding)
+	case SeverityWarning:
+		r.Warnings = append(r.Warnings, finding)
+	default:
+		r.Infos = append(r.Infos, finding)
+	}
+
+	if finding.Severity >= r.FailOn {
+		r.Failed = true
+	}
+}
+
+// Err returns the first finding at or above the failure severity, or nil if
+// the sequence passed
+func (r *SequenceValidationResult) Err() error {
+	if !r.Failed {
+		return nil
+	}
+
+	for _, findings := range [][]ValidationFinding{r.Errors, r.Warnings, r.Infos} {
+		for i := range findings {
+			if findings[i].Severity >= r.FailOn {
+				return &findings[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+// EventSequenceValidator validates events incrementally according to AG-UI
+// protocol sequencing rules, collecting errors, warnings and informational
+// findings as it goes
+type EventSequenceValidator struct {
+	failOn            ValidationSeverity
+	maxDeltaLength    int
+	maxEventSize      int
+	maxValueDepth     int
+	maxValueKeys      int
+	knownCustomEvents map[string]bool
+
+	index           int
+	activeRuns      map[string]bool
+	finishedRuns    map[string]bool
+	activeMessages  map[string]bool
+	activeToolCalls map[string]bool
+	seenToolCalls   map[string]bool
+	activeSteps     map[string]bool
+	result          *SequenceValidationResult
+
+	// Chunk tracking. Lifecycle IDs are those seen in start/content/end
+	// (or start/args/end) events; chunk IDs are those seen in chunk events.
+	lifecycleMessages   map[string]bool
+	lifecycleToolCalls  map[string]bool
+	chunkMessages       map[string]*chunkStream
+	chunkToolCalls      map[string]*chunkStream
+	chunkOrder          []*chunkStream
+	lastChunkMessageID  string
+	lastChunkToolCallID string
+	mixedReported       map[string]bool
+
+	// modules are the optional checks enabled with options such as
+	// WithThinkingBlockValidation
+	modules []sequenceModule
+}
+
+// sequenceModule is an optional set of rules run by the sequence validator
+// in addition to its own
+type sequenceModule interface {
+	// check applies the rules to the event at the given index
+	check(event Event, index int, report findingReporter)
+
+	// finish returns the findings for the end of the sequence
+	finish() []ValidationFinding
+
+	// Reset clears all tracked state and findings
+	Reset()
+}
+
+// validateModuleEvent runs a module on its own over the event at the given
+// index, recording its findings in result
+func validateModuleEvent(module sequenceModule, event Event, index int, result *SequenceValidationResult) []ValidationFinding {
+	var findings []ValidationFinding
+	module.check(event, index, func(severity ValidationSeverity, rule string, cause error, format string, args ...any) {
+		findings = append(findings, ValidationFinding{
+			Severity:   severity,
+			Rule:       rule,
+			Message:    fmt.Sprintf(format, args...),
+			EventIndex: index,
+			EventType:  event.Type(),
+			cause:      cause,
+		})
+	})
+
+	for _, finding := range findings {
+		result.add(finding)
+	}
+	return findings
+}
+
+// chunkStream tracks the chunks seen for a single message or tool call ID
+type chunkStream struct {
+	id         string
+	eventType  EventType
+	firstIndex int
+	role       *string
+	hasDelta   bool
+}
+
+// SequenceValidatorOption defines options for creating sequence validators
+type SequenceValidatorOption func(*EventSequenceValidator)
+
+// FailOn sets the minimum severity that causes validation to fail.
+// The default is SeverityError, so warnings never fail a sequence.
+func FailOn(severity ValidationSeverity) SequenceValidatorOption {
+	return func(v *EventSequenceValidator) {
+		v.failOn = severity
+	}
+}
+
+// WithMaxDeltaLength sets the delta size in bytes above which a warning is reported
+func WithMaxDeltaLength(length int) SequenceValidatorOption {
+	return func(v *EventSequenceValidator) {
+		v.maxDeltaLength = length
+	}
+}
+
+// WithMaxEventSize sets the serialized event size in bytes above which an
+// error is reported. A size of 0 disables the check.
+func WithMaxEventSize(size int) SequenceValidatorOption {
+	return func(v *EventSequenceValidator) {
+		v.maxEventSize = size
+	}
+}
+
+// WithMaxValueDepth sets the nesting depth of state snapshots and custom event
+// values above which an error is reported. A depth of 0 disables the check.
+func WithMaxValueDepth(depth int) SequenceValidatorOption {
+	return func(v *EventSequenceValidator) {
+		v.maxValueDepth = depth
+	}
+}
+
+// WithMaxValueKeys sets the total key count of state snapshots and custom event
+// values above which an error is reported. A count of 0 disables the check.
+func WithMaxValueKeys(count int) SequenceValidatorOption {
+	return func(v *EventSequenceValidator) {
+		v.maxValueKeys = count
+	}
+}
+
+// WithKnownCustomEvents registers the expected custom event names. Custom
+// events with other names are reported as warnings. When no names are
+// registered, custom event names are not checked.
+func WithKnownCustomEvents(names ...string) SequenceValidatorOption {
+	return func(v *EventSequenceValidator) {
+		if v.knownCustomEvents == nil {
+			v.knownCustomEvents = make(map[string]bool, len(names))
+		}
+		for _, name := range names {
+			v.knownCustomEvents[name] = true
+		}
+	}
+}
+
+// NewEventSequenceValidator creates a new incremental sequence validator
+func NewEventSequenceValidator(options ...SequenceValidatorOption) *EventSequenceValidator {
+	v := &EventSequenceValidator{
+		failOn:         SeverityError,
+		maxDeltaLength: DefaultMaxDeltaLength,
+		maxEventSize:   DefaultMaxEventSize,
+		maxValueDepth:  DefaultMaxValueDepth,
+		maxValueKeys:   DefaultMaxValueKeys,
+	}
+
+	for _, opt := range options {
+		opt(v)
+	}
+
+	v.Reset()
+	return v
+}
+
+// Reset clears all tracked state and findings
+func (v *EventSequenceValidator) Reset() {
+	v.index = 0
+	v.activeRuns = make(map[string]bool)
+	v.finishedRuns = make(map[string]bool)
+	v.activeMessages = make(map[string]bool)
+	v.activeToolCalls = make(map[string]bool)
+	v.seenToolCalls = make(map[string]bool)
+	v.activeSteps = make(map[string]bool)
+	v.result = &SequenceValidationResult{FailOn: v.failOn}
+	v.lifecycleMessages = make(map[string]bool)
+	v.lifecycleToolCalls = make(map[string]bool)
+	v.chunkMessages = make(map[string]*chunkStream)
+	v.chunkToolCalls = make(map[string]*chunkStream)
+	v.chunkOrder = nil
+	v.lastChunkMessageID = ""
+	v.lastChunkToolCallID = ""
+	v.mixedReported = make(map[string]bool)
+	for _, module := range v.modules {
+		module.Reset()
+	}
+}
+
+// Result returns the findings collected so far
+func (v *EventSequenceValidator) Result() *SequenceValidationResult {
+	return v.result
+}
+
+// Finish applies the end-of-sequence rules and returns the findings they
+// produced. It should be called once after the last event has been validated.
+func (v *EventSequenceValidator) Finish() []ValidationFinding {
+	var findings []ValidationFinding
+	for _, stream := range v.chunkOrder {
+		if stream.hasDelta {
+			continue
+		}
+		kind := "message"
+		if stream.eventType == EventTypeToolCallChunk {
+			kind = "tool call"
+		}
+		findings = append(findings, ValidationFinding{
+			Severity:   SeverityWarning,
+			Rule:       RuleChunkWithoutDelta,
+			Message:    fmt.Sprintf("chunked %s %s never carried a delta", kind, stream.id),
+			EventIndex: stream.firstIndex,
+			EventType:  stream.eventType,
+		})
+	}
+	for _, module := range v.modules {
+		findings = append(findings, module.finish()...)
+	}
+
+	for _, finding := range findings {
+		v.result.add(finding)
+	}
+
+	return findings
+}
+
+// ValidateEvent validates the next event in the sequence and returns the
+// findings it produced. A nil event is reported as invalid.
+func (v *EventSequenceValidator) ValidateEvent(event Event) []ValidationFinding {
+	index := v.index
+	v.index++
+
+	if event == nil {
+		finding := ValidationFinding{
+			Severity:   SeverityError,
+			Rule:       RuleEventInvalid,
+			Message:    fmt.Sprintf("event at index %d is nil", index),
+			EventIndex: index,
+		}
+		v.result.add(finding)
+		return []ValidationFinding{finding}
+	}
+
+	var findings []ValidationFinding
+	report := func(severity ValidationSeverity, rule string, cause error, format string, args ...any) {
+		findings = append(findings, ValidationFinding{
+			Severity:   severity,
+			Rule:       rule,
+			Message:    fmt.Sprintf(format, args...),
+			EventIndex: index,
+			EventType:  event.Type(),
+			cause:      cause,
+		})
+	}
+
+	if err := event.Validate(); err != nil {
+		report(SeverityError, RuleEventInvalid, err, "event %d validation failed: %v", index, err)
+	} else {
+		v.checkLimits(event, report)
+		v.checkSequence(event, report)
+		for _, module := range v.modules {
+			module.check(event, index, report)
+		}
+	}
+
+	for _, finding := range findings {
+		v.result.add(finding)
+	}
+
+	return findings
+}
+
+// findingReporter records a finding for the event being validated
+type findingReporter func(severity ValidationSeverity, rule string, cause error, format string, args ...any)
+
+// checkSequence applies the sequencing rules for a single, structurally valid event
+func (v *EventSequenceValidator) checkSequence(event Event, report findingReporter) {
+	switch e := event.(type) {
+	case *RunStartedEvent:
+		if v.activeRuns[e.RunID()] {
+			report(SeverityError, RuleRunAlreadyStarted, nil, "run %s already started", e.RunID())
+			return
+		}
+		if v.finishedRuns[e.RunID()] {
+			report(SeverityError, RuleRunRestarted, nil, "cannot restart finished run %s", e.RunID())
+			return
+		}
+		v.activeRuns[e.RunID()] = true
+
+	case *RunFinishedEvent:
+		if !v.activeRuns[e.RunID()] {
+			report(SeverityError, RuleRunNotStarted, nil, "cannot finish run %s that was not started", e.RunID())
+			return
+		}
+		delete(v.activeRuns, e.RunID())
+		v.finishedRuns[e.RunID()] = true
+
+	case *RunErrorEvent:
+		if e.RunID() == "" {
+			return
+		}
+		if !v.activeRuns[e.RunID()] {
+			report(SeverityError, RuleRunNotStarted, nil, "cannot error run %s that was not started", e.RunID())
+			return
+		}
+		delete(v.activeRuns, e.RunID())
+		v.finishedRuns[e.RunID()] = true
+
+	case *StepStartedEvent:
+		if v.activeSteps[e.StepName] {
+			report(SeverityError, RuleStepAlreadyStarted, nil, "step %s already started", e.StepName)
+			return
+		}
+		v.activeSteps[e.StepName] = true
+
+	case *StepFinishedEvent:
+		if !v.activeSteps[e.StepName] {
+			report(SeverityError, RuleStepNotStarted, nil, "cannot finish step %s that was not started", e.StepName)
+			return
+		}
+		delete(v.activeSteps, e.StepName)
+
+	case *TextMessageStartEvent:
+		v.trackLifecycleMessage(e.MessageID, report)
+		if v.activeMessages[e.MessageID] {
+			report(SeverityError, RuleMessageAlreadyStarted, nil, "message %s already started", e.MessageID)
+			return
+		}
+		v.activeMessages[e.MessageID] = true
+
+	case *TextMessageContentEvent:
+		v.trackLifecycleMessage(e.MessageID, report)
+		if !v.activeMessages[e.MessageID] {
+			report(SeverityError, RuleMessageNotStarted, nil, "cannot add content to message %s that was not started", e.MessageID)
+		}
+		if e.Edit != nil {
+			v.checkDeltaLength(len(e.Edit.Insert), report)
+		} else {
+			v.checkDeltaLength(len(e.Delta), report)
+		}
+
+	case *TextMessageEndEvent:
+		v.trackLifecycleMessage(e.MessageID, report)
+		if !v.activeMessages[e.MessageID] {
+			report(SeverityError, RuleMessageNotStarted, nil, "cannot end message %s that was not started", e.MessageID)
+			return
+		}
+		delete(v.activeMessages, e.MessageID)
+
+	case *ToolCallStartEvent:
+		v.trackLifecycleToolCall(e.ToolCallID, report)
+		if v.activeToolCalls[e.ToolCallID] {
+			report(SeverityError, RuleToolCallAlreadyStarted, nil, "tool call %s already started", e.ToolCallID)
+			return
+		}
+		v.activeToolCalls[e.ToolCallID] = true
+
+	case *ToolCallArgsEvent:
+		v.trackLifecycleToolCall(e.ToolCallID, report)
+		if !v.activeToolCalls[e.ToolCallID] {
+			report(SeverityError, RuleToolCallNotStarted, nil, "cannot add args to tool call %s that was not started", e.ToolCallID)
+		}
+		v.checkDeltaLength(len(e.Delta), report)
+
+	case *ToolCallEndEvent:
+		v.trackLifecycleToolCall(e.ToolCallID, report)
+		if !v.activeToolCalls[e.ToolCallID] {
+			report(SeverityError, RuleToolCallNotStarted, nil, "cannot end tool call %s that was not started", e.ToolCallID)
+			return
+		}
+		delete(v.activeToolCalls, e.ToolCallID)
+
+	case *ToolCallResultEvent:
+		if strings.TrimSpace(e.Content) == "" {
+			report(SeverityWarning, RuleBlankToolResult, nil, "tool call %s result content is blank", e.ToolCallID)
+		}
+		if !v.seenToolCalls[e.ToolCallID] {
+			report(SeverityWarning, RuleToolResultUnknownToolCall, nil, "result references tool call %s that was not started", e.ToolCallID)
+		}
+
+	case *TextMessageChunkEvent:
+		v.checkTextMessageChunk(e, report)
+
+	case *ToolCallChunkEvent:
+		v.checkToolCallChunk(e, report)
+
+	case *CustomEvent:
+		if v.knownCustomEvents != nil && !v.knownCustomEvents[e.Name] {
+			report(SeverityWarning, RuleUnknownCustomEvent, nil, "unknown custom event name %s", e.Name)
+		}
+
+	default:
+		// State, messages snapshot, raw and thinking events are always valid
+		// in sequence context; they carry no pairing constraints. The nesting
+		// of thinking events is checked by the optional ThinkingBlockValidator.
+	}
+}
+
+// trackLifecycleMessage records a message ID used by start/content/end events
+// and reports it if the same ID was already used by chunk events
+func (v *EventSequenceValidator) trackLifecycleMessage(messageID string, report findingReporter) {
+	v.lifecycleMessages[messageID] = true
+	if _, ok := v.chunkMessages[messageID]; ok {
+		v.reportMixedMessage(messageID, report)
+	}
+}
+
+// trackLifecycleToolCall records a tool call ID used by start/args/end events
+// and reports it if the same ID was already used by chunk events
+func (v *EventSequenceValidator) trackLifecycleToolCall(toolCallID string, report findingReporter) {
+	v.lifecycleToolCalls[toolCallID] = true
+	v.seenToolCalls[toolCallID] = true
+	if _, ok := v.chunkToolCalls[toolCallID]; ok {
+		v.reportMixedToolCall(toolCallID, report)
+	}
+}
+
+// checkTextMessageChunk applies the chunk rules to a text message chunk.
+// Chunks without a messageId continue the most recent chunked message.
+func (v *EventSequenceValidator) checkTextMessageChunk(e *TextMessageChunkEvent, report findingReporter) {
+	messageID := v.lastChunkMessageID
+	if e.MessageID != nil {
+		messageID = *e.MessageID
+	}
+	v.lastChunkMessageID = messageID
+
+	if e.Delta != nil {
+		v.checkDeltaLength(len(*e.Delta), report)
+	}
+
+	if messageID == "" {
+		return
+	}
+
+	stream, ok := v.chunkMessages[messageID]
+	if !ok {
+		stream = &chunkStream{id: messageID, eventType: EventTypeTextMessageChunk, firstIndex: v.index - 1}
+		v.chunkMessages[messageID] = stream
+		v.chunkOrder = append(v.chunkOrder, stream)
+	}
+
+	if v.lifecycleMessages[messageID] {
+		v.reportMixedMessage(messageID, report)
+	}
+
+	if e.Role != nil {
+		if stream.role != nil && *stream.role != *e.Role {
+			report(SeverityError, RuleChunkRoleChanged, nil,
+				"message %s changed role from %s to %s mid-message", messageID, *stream.role, *e.Role)
+		} else {
+			stream.role = e.Role
+		}
+	}
+
+	if e.Delta != nil && *e.Delta != "" {
+		stream.hasDelta = true
+	}
+}
+
+// checkToolCallChunk applies the chunk rules to a tool call chunk.
+// Chunks without a toolCallId continue the most recent chunked tool call.
+func (v *EventSequenceValidator) checkToolCallChunk(e *ToolCallChunkEvent, report findingReporter) {
+	toolCallID := v.lastChunkToolCallID
+	if e.ToolCallID != nil {
+		toolCallID = *e.ToolCallID
+	}
+	v.lastChunkToolCallID = toolCallID
+
+	if e.Delta != nil {
+		v.checkDeltaLength(len(*e.Delta), report)
+	}
+
+	if toolCallID == "" {
+		return
+	}
+	v.seenToolCalls[toolCallID] = true
+
+	stream, ok := v.chunkToolCalls[toolCallID]
+	if !ok {
+		stream = &chunkStream{id: toolCallID, eventType: EventTypeToolCallChunk, firstIndex: v.index - 1}
+		v.chunkToolCalls[toolCallID] = stream
+		v.chunkOrder = append(v.chunkOrder, stream)
+	}
+
+	if v.lifecycleToolCalls[toolCallID] {
+		v.reportMixedToolCall(toolCallID, report)
+	}
+
+	if e.Delta != nil && *e.Delta != "" {
+		stream.hasDelta = true
+	}
+}
+
+// reportMixedMessage reports, once per ID, a message streamed with both chunk
+// and start/content/end events
+func (v *EventSequenceValidator) reportMixedMessage(messageID string, report findingReporter) {
+	key := "message:" + messageID
+	if v.mixedReported[key] {
+		return
+	}
+	v.mixedReported[key] = true
+	report(SeverityError, RuleMixedMessageStyles, nil,
+		"message %s mixes chunk and start/content/end events", messageID)
+}
+
+// reportMixedToolCall reports, once per ID, a tool call streamed with both
+// chunk and start/args/end events
+func (v *EventSequenceValidator) reportMixedToolCall(toolCallID string, report findingReporter) {
+	key := "tool_call:" + toolCallID
+	if v.mixedReported[key] {
+		return
+	}
+	v.mixedReported[key] = true
+	report(SeverityError, RuleMixedToolCallStyles, nil,
+		"tool call %s mixes chunk and start/args/end events", toolCallID)
+}
+
+// checkDeltaLength reports a warning for deltas above the configured size
+func (v *EventSequenceValidator) checkDeltaLength(length int, report findingReporter) {
+	if v.maxDeltaLength > 0 && length > v.maxDeltaLength {
+		report(SeverityWarning, RuleLargeDelta, nil, "delta of %d bytes exceeds %d bytes", length, v.maxDeltaLength)
+	}
+}
+
+// checkLimits reports events whose serialized size, or whose free-form values,
+// exceed the configured limits
+func (v *EventSequenceValidator) checkLimits(event Event, report findingReporter) {
+	if v.maxEventSize > 0 {
+		data, err := event.ToJSON()
+		if err != nil {
+			report(SeverityError, RuleEventInvalid, err, "event %d could not be serialized: %v", v.index-1, err)
+		} else if len(data) > v.maxEventSize {
+			report(SeverityError, RuleEventTooLarge, nil, "event of %d bytes exceeds %d bytes", len(data), v.maxEventSize)
+		}
+	}
+
+	switch e := event.(type) {
+	case *StateSnapshotEvent:
+		v.checkValueShape("state snapshot", e.Snapshot, report)
+	case *CustomEvent:
+		v.checkValueShape("custom event value", e.Value, report)
+	}
+}
+
+// checkValueShape reports free-form values nested too deeply or with too many keys
+func (v *EventSequenceValidator) checkValueShape(name string, value any, report findingReporter) {
+	if v.maxValueDepth <= 0 && v.maxValueKeys <= 0 {
+		return
+	}
+
+	shape := measureJSONValue(value, v.maxValueDepth, v.maxValueKeys)
+	if v.maxValueDepth > 0 && shape.depth > v.maxValueDepth {
+		report(SeverityError, RuleValueTooDeep, nil, "%s nesting depth exceeds %d", name, v.maxValueDepth)
+	}
+	if v.maxValueKeys > 0 && shape.keys > v.maxValueKeys {
+		report(SeverityError, RuleValueTooManyKeys, nil, "%s has more than %d keys", name, v.maxValueKeys)
+	}
+}
+
+// ValidateSequenceWithResult validates a sequence of events and returns every
+// finding, split into errors, warnings and informational findings
+func ValidateSequenceWithResult(events []Event, options ...SequenceValidatorOption) *SequenceValidationResult {
+	validator := NewEventSequenceValidator(options...)
+	for _, event := range events {
+		validator.ValidateEvent(event)
+	}
+	validator.Finish()
+	return validator.Result()
+}
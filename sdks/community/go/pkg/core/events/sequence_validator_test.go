@@ -0,0 +1,315 @@
+package events
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventSequenceValidatorSeverities(t *testing.T) {
+	warningSequence := func() []Event {
+		return []Event{
+			NewRunStartedEvent("thread-1", "run-1"),
+			NewTextMessageStartEvent("msg-1"),
+			NewTextMessageContentEvent("msg-1", strings.Repeat("x", 16)),
+			NewTextMessageEndEvent("msg-1"),
+			NewToolCallResultEvent("msg-2", "tool-unknown", "  "),
+			NewCustomEvent("unregistered"),
+			NewRunFinishedEvent("thread-1", "run-1"),
+		}
+	}
+
+	t.Run("WarningsPassWhenFailOnError", func(t *testing.T) {
+		result := ValidateSequenceWithResult(warningSequence(),
+			WithMaxDeltaLength(8),
+			WithKnownCustomEvents("progress"))
+
+		assert.False(t, result.Failed)
+		assert.NoError(t, result.Err())
+		assert.Empty(t, result.Errors)
+
+		rules := make([]string, 0, len(result.Warnings))
+		for _, w := range result.Warnings {
+			assert.Equal(t, SeverityWarning, w.Severity)
+			rules = append(rules, w.Rule)
+		}
+		assert.ElementsMatch(t, []string{
+			RuleLargeDelta,
+			RuleBlankToolResult,
+			RuleToolResultUnknownToolCall,
+			RuleUnknownCustomEvent,
+		}, rules)
+	})
+
+	t.Run("WarningsFailWhenFailOnWarning", func(t *testing.T) {
+		result := ValidateSequenceWithResult(warningSequence(),
+			WithMaxDeltaLength(8),
+			FailOn(SeverityWarning))
+
+		assert.True(t, result.Failed)
+		err := result.Err()
+		require.Error(t, err)
+
+		var finding *ValidationFinding
+		require.ErrorAs(t, err, &finding)
+		assert.Equal(t, RuleLargeDelta, finding.Rule)
+		assert.Equal(t, 2, finding.EventIndex)
+	})
+
+	t.Run("ErrorsAndWarningsCollectedTogether", func(t *testing.T) {
+		events := []Event{
+			NewTextMessageEndEvent("msg-1"),
+			NewToolCallResultEvent("msg-2", "tool-1", "done"),
+		}
+
+		result := ValidateSequenceWithResult(events)
+		require.Len(t, result.Errors, 1)
+		require.Len(t, result.Warnings, 1)
+		assert.Equal(t, RuleMessageNotStarted, result.Errors[0].Rule)
+		assert.Equal(t, RuleToolResultUnknownToolCall, result.Warnings[0].Rule)
+		assert.True(t, result.Failed)
+	})
+
+	t.Run("InvalidEventUnwrapsCause", func(t *testing.T) {
+		event := NewTextMessageStartEvent("msg-1")
+		event.MessageID = ""
+
+		_, err := ValidateSequence([]Event{event}, DefaultProfile())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "event 0 validation failed")
+
+		var finding *ValidationFinding
+		require.ErrorAs(t, err, &finding)
+		assert.Equal(t, RuleEventInvalid, finding.Rule)
+		assert.NotNil(t, finding.Unwrap())
+	})
+
+	t.Run("Incremental", func(t *testing.T) {
+		validator := NewEventSequenceValidator()
+
+		assert.Empty(t, validator.ValidateEvent(NewRunStartedEvent("thread-1", "run-1")))
+		findings := validator.ValidateEvent(NewRunStartedEvent("thread-1", "run-1"))
+		require.Len(t, findings, 1)
+		assert.Equal(t, RuleRunAlreadyStarted, findings[0].Rule)
+		assert.Equal(t, 1, findings[0].EventIndex)
+
+		validator.Reset()
+		assert.False(t, validator.Result().Failed)
+		assert.Empty(t, validator.ValidateEvent(NewRunStartedEvent("thread-1", "run-1")))
+	})
+
+	t.Run("JSONIncludesSeverity", func(t *testing.T) {
+		result := ValidateSequenceWithResult([]Event{
+			NewToolCallResultEvent("msg-1", "tool-1", "done"),
+		})
+
+		data, err := json.Marshal(result)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"severity":"warning"`)
+		assert.Contains(t, string(data), `"failOn":"error"`)
+
+		var decoded SequenceValidationResult
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		require.Len(t, decoded.Warnings, 1)
+		assert.Equal(t, SeverityWarning, decoded.Warnings[0].Severity)
+	})
+}
+
+func TestChunkSequenceRules(t *testing.T) {
+	rulesOf := func(result *SequenceValidationResult) []string {
+		var rules []string
+		for _, findings := range [][]ValidationFinding{result.Errors, result.Warnings, result.Infos} {
+			for _, f := range findings {
+				rules = append(rules, f.Rule)
+			}
+		}
+		return rules
+	}
+
+	t.Run("CleanChunkStream", func(t *testing.T) {
+		events := []Event{
+			NewRunStartedEvent("thread-1", "run-1"),
+			NewTextMessageChunkEvent(strPtr("msg-1"), strPtr("assistant"), strPtr("Hel")),
+			NewTextMessageChunkEvent(nil, nil, strPtr("lo")),
+			NewToolCallChunkEvent().WithToolCallChunkID("tool-1").WithToolCallChunkName("search"),
+			NewToolCallChunkEvent().WithToolCallChunkDelta(`{"q":"go"}`),
+			NewToolCallResultEvent("msg-2", "tool-1", "results"),
+			NewRunFinishedEvent("thread-1", "run-1"),
+		}
+
+		result := ValidateSequenceWithResult(events)
+		assert.Empty(t, rulesOf(result))
+		assert.False(t, result.Failed)
+	})
+
+	t.Run("CleanLifecycleStream", func(t *testing.T) {
+		events := []Event{
+			NewRunStartedEvent("thread-1", "run-1"),
+			NewTextMessageStartEvent("msg-1", WithRole("assistant")),
+			NewTextMessageContentEvent("msg-1", "Hello"),
+			NewTextMessageEndEvent("msg-1"),
+			NewToolCallStartEvent("tool-1", "search"),
+			NewToolCallArgsEvent("tool-1", `{"q":"go"}`),
+			NewToolCallEndEvent("tool-1"),
+			NewRunFinishedEvent("thread-1", "run-1"),
+		}
+
+		result := ValidateSequenceWithResult(events)
+		assert.Empty(t, rulesOf(result))
+	})
+
+	t.Run("MixedStylesForOneMessage", func(t *testing.T) {
+		events := []Event{
+			NewTextMessageStartEvent("msg-1"),
+			NewTextMessageContentEvent("msg-1", "Hello"),
+			NewTextMessageChunkEvent(strPtr("msg-1"), nil, strPtr("Hello")),
+			NewTextMessageEndEvent("msg-1"),
+		}
+
+		result := ValidateSequenceWithResult(events)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, RuleMixedMessageStyles, result.Errors[0].Rule)
+		assert.Equal(t, 2, result.Errors[0].EventIndex)
+	})
+
+	t.Run("MixedStylesForOneToolCall", func(t *testing.T) {
+		events := []Event{
+			NewToolCallChunkEvent().WithToolCallChunkID("tool-1").WithToolCallChunkDelta("{}"),
+			NewToolCallStartEvent("tool-1", "search"),
+			NewToolCallEndEvent("tool-1"),
+		}
+
+		result := ValidateSequenceWithResult(events)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, RuleMixedToolCallStyles, result.Errors[0].Rule)
+		assert.Equal(t, 1, result.Errors[0].EventIndex)
+	})
+
+	t.Run("ChunkStreamWithoutDelta", func(t *testing.T) {
+		events := []Event{
+			NewTextMessageChunkEvent(strPtr("msg-1"), strPtr("assistant"), nil),
+			NewTextMessageChunkEvent(strPtr("msg-2"), nil, strPtr("Hi")),
+		}
+
+		result := ValidateSequenceWithResult(events)
+		assert.Empty(t, result.Errors)
+		require.Len(t, result.Warnings, 1)
+		assert.Equal(t, RuleChunkWithoutDelta, result.Warnings[0].Rule)
+		assert.Equal(t, 0, result.Warnings[0].EventIndex)
+		assert.Contains(t, result.Warnings[0].Message, "msg-1")
+	})
+
+	t.Run("RoleChangedMidMessage", func(t *testing.T) {
+		events := []Event{
+			NewTextMessageChunkEvent(strPtr("msg-1"), strPtr("assistant"), strPtr("Hel")),
+			NewTextMessageChunkEvent(nil, strPtr("user"), strPtr("lo")),
+		}
+
+		result := ValidateSequenceWithResult(events)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, RuleChunkRoleChanged, result.Errors[0].Rule)
+		assert.Equal(t, 1, result.Errors[0].EventIndex)
+	})
+}
+
+// nestedValue builds an object nested depth levels deep
+func nestedValue(depth int) any {
+	var value any = "leaf"
+	for i := 0; i < depth; i++ {
+		value = map[string]any{"child": value}
+	}
+	return value
+}
+
+// objectWithKeys builds an object with count keys
+func objectWithKeys(count int) map[string]any {
+	value := make(map[string]any, count)
+	for i := 0; i < count; i++ {
+		value[strings.Repeat("k", i+1)] = i
+	}
+	return value
+}
+
+// findingRules returns the rule codes of the findings
+func findingRules(findings []ValidationFinding) []string {
+	rules := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		rules = append(rules, finding.Rule)
+	}
+	return rules
+}
+
+func TestSizeAndDepthLimits(t *testing.T) {
+	validate := func(event Event, options ...SequenceValidatorOption) []string {
+		return findingRules(NewEventSequenceValidator(options...).ValidateEvent(event))
+	}
+
+	t.Run("EventSize", func(t *testing.T) {
+		event := NewTextMessageContentEvent("msg-1", strings.Repeat("x", 100))
+		data, err := event.ToJSON()
+		require.NoError(t, err)
+
+		assert.NotContains(t, validate(event, WithMaxEventSize(len(data))), RuleEventTooLarge)
+		assert.Contains(t, validate(event, WithMaxEventSize(len(data)-1)), RuleEventTooLarge)
+		assert.NotContains(t, validate(event, WithMaxEventSize(0)), RuleEventTooLarge)
+	})
+
+	t.Run("SnapshotDepth", func(t *testing.T) {
+		assert.Empty(t, validate(NewStateSnapshotEvent(nestedValue(5)), WithMaxValueDepth(5)))
+		assert.Equal(t, []string{RuleValueTooDeep},
+			validate(NewStateSnapshotEvent(nestedValue(6)), WithMaxValueDepth(5)))
+
+		// Arrays count as nesting levels too
+		assert.Equal(t, []string{RuleValueTooDeep},
+			validate(NewStateSnapshotEvent(map[string]any{"list": []any{[]any{1}}}), WithMaxValueDepth(2)))
+	})
+
+	t.Run("VeryDeepSnapshot", func(t *testing.T) {
+		findings := validate(NewStateSnapshotEvent(nestedValue(100000)), WithMaxEventSize(0))
+		assert.Equal(t, []string{RuleValueTooDeep}, findings)
+	})
+
+	t.Run("SnapshotKeys", func(t *testing.T) {
+		assert.Empty(t, validate(NewStateSnapshotEvent(objectWithKeys(10)), WithMaxValueKeys(10)))
+		assert.Equal(t, []string{RuleValueTooManyKeys},
+			validate(NewStateSnapshotEvent(objectWithKeys(11)), WithMaxValueKeys(10)))
+
+		// Keys are counted at every level
+		nested := map[string]any{"a": objectWithKeys(5), "b": objectWithKeys(5)}
+		assert.Equal(t, []string{RuleValueTooManyKeys},
+			validate(NewStateSnapshotEvent(nested), WithMaxValueKeys(11)))
+		assert.Empty(t, validate(NewStateSnapshotEvent(nested), WithMaxValueKeys(12)))
+	})
+
+	t.Run("CustomEventValue", func(t *testing.T) {
+		type payload struct {
+			Inner map[string]any `json:"inner"`
+		}
+
+		under := NewCustomEvent("progress", WithValue(payload{Inner: map[string]any{"x": 1}}))
+		over := NewCustomEvent("progress", WithValue(payload{Inner: map[string]any{"x": []any{1}}}))
+
+		assert.Empty(t, validate(under, WithMaxValueDepth(2)))
+		assert.Equal(t, []string{RuleValueTooDeep}, validate(over, WithMaxValueDepth(2)))
+
+		assert.Empty(t, validate(under, WithMaxValueKeys(2)))
+		assert.Equal(t, []string{RuleValueTooManyKeys}, validate(under, WithMaxValueKeys(1)))
+	})
+
+	t.Run("DeltaLength", func(t *testing.T) {
+		assert.NotContains(t, validate(NewToolCallArgsEvent("tool-1", strings.Repeat("x", 8)), WithMaxDeltaLength(8)), RuleLargeDelta)
+		assert.Contains(t, validate(NewToolCallArgsEvent("tool-1", strings.Repeat("x", 9)), WithMaxDeltaLength(8)), RuleLargeDelta)
+		assert.NotContains(t, validate(NewTextMessageContentEvent("msg-1", strings.Repeat("x", 8)), WithMaxDeltaLength(8)), RuleLargeDelta)
+		assert.Contains(t, validate(NewTextMessageContentEvent("msg-1", strings.Repeat("x", 9)), WithMaxDeltaLength(8)), RuleLargeDelta)
+	})
+
+	t.Run("LimitsAreErrors", func(t *testing.T) {
+		_, err := ValidateSequence([]Event{NewStateSnapshotEvent(nestedValue(DefaultMaxValueDepth + 1))}, DefaultProfile())
+		var finding *ValidationFinding
+		require.ErrorAs(t, err, &finding)
+		assert.Equal(t, RuleValueTooDeep, finding.Rule)
+	})
+}
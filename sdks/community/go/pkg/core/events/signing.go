@@ -0,0 +1,84 @@
+package events
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidSignature is returned when an event does not match its signature
+var ErrInvalidSignature = errors.New("invalid event signature")
+
+// Sign returns the HMAC-SHA256 signature of the event under key, encoded in
+// unpadded URL-safe base64. The signature covers the canonical JSON of the
+// event, as used by HashEvent, without its timestamp and signature, so that
+// the event can be re-stamped in transit and signed in place.
+func Sign(e Event, key []byte) (string, error) {
+	if e == nil {
+		return "", fmt.Errorf("cannot sign a nil event")
+	}
+	if len(key) == 0 {
+		return "", fmt.Errorf("signing key is empty")
+	}
+
+	clone, err := CopyEvent(e)
+	if err != nil {
+		return "", err
+	}
+	if base := clone.GetBaseEvent(); base != nil {
+		base.TimestampMs = nil
+		base.SignatureValue = nil
+	}
+	data, err := canonicalEventJSON(clone)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Verify checks that signature is the signature of the event under key,
+// returning ErrInvalidSignature if it is not. The comparison takes constant
+// time.
+func Verify(e Event, signature string, key []byte) error {
+	expected, err := Sign(e, key)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// SignEvent signs the event under key and stores the signature in the event,
+// where it is serialized as the "signature" field
+func SignEvent(e Event, key []byte) error {
+	signature, err := Sign(e, key)
+	if err != nil {
+		return err
+	}
+	base := e.GetBaseEvent()
+	if base == nil {
+		return fmt.Errorf("cannot store the signature of an event without a base event")
+	}
+	base.SignatureValue = &signature
+	return nil
+}
+
+// VerifyEvent checks the signature stored in the event by SignEvent. An
+// event without a signature fails with ErrInvalidSignature.
+func VerifyEvent(e Event, key []byte) error {
+	if e == nil {
+		return fmt.Errorf("cannot verify a nil event")
+	}
+	base := e.GetBaseEvent()
+	if base == nil || base.SignatureValue == nil {
+		return fmt.Errorf("%w: event is not signed", ErrInvalidSignature)
+	}
+	return Verify(e, *base.SignatureValue, key)
+}
@@ -0,0 +1,76 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	event := NewTextMessageContentEvent("msg-1", "Hello")
+	event.SetCorrelationID("corr-1")
+
+	signature, err := Sign(event, key)
+	require.NoError(t, err)
+	assert.NotEmpty(t, signature)
+	assert.NoError(t, Verify(event, signature, key))
+
+	// The timestamp is not covered by the signature
+	event.SetTimestamp(*event.Timestamp() + 1000)
+	assert.NoError(t, Verify(event, signature, key))
+
+	// Tampering with the content or the base fields is detected
+	tampered := event.Clone()
+	tampered.Delta = "Goodbye"
+	assert.ErrorIs(t, Verify(tampered, signature, key), ErrInvalidSignature)
+
+	tampered = event.Clone()
+	tampered.SetCorrelationID("corr-2")
+	assert.ErrorIs(t, Verify(tampered, signature, key), ErrInvalidSignature)
+
+	assert.ErrorIs(t, Verify(event, signature, []byte("another key")), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify(event, "", key), ErrInvalidSignature)
+
+	_, err = Sign(event, nil)
+	assert.Error(t, err)
+	_, err = Sign(nil, key)
+	assert.Error(t, err)
+}
+
+func TestSignEvent(t *testing.T) {
+	key := []byte("secret")
+	event := NewStateSnapshotEvent(map[string]any{"b": 1, "a": []any{"x"}})
+
+	assert.ErrorIs(t, VerifyEvent(event, key), ErrInvalidSignature)
+
+	require.NoError(t, SignEvent(event, key))
+	require.NotNil(t, event.SignatureValue)
+	assert.NoError(t, VerifyEvent(event, key))
+
+	// The signature travels with the event
+	data, err := event.ToJSON()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"signature":"`+*event.SignatureValue+`"`)
+
+	decoded, err := EventFromJSON(data)
+	require.NoError(t, err)
+	assert.NoError(t, VerifyEvent(decoded, key))
+
+	// Signing again yields the same signature
+	signature := *event.SignatureValue
+	require.NoError(t, SignEvent(event, key))
+	assert.Equal(t, signature, *event.SignatureValue)
+
+	decoded.(*StateSnapshotEvent).Snapshot.(map[string]any)["b"] = 2
+	assert.ErrorIs(t, VerifyEvent(decoded, key), ErrInvalidSignature)
+
+	// The signature does not change the content hash
+	unsigned := NewStateSnapshotEvent(map[string]any{"b": 1, "a": []any{"x"}})
+	signedHash, err := HashEvent(event)
+	require.NoError(t, err)
+	unsignedHash, err := HashEvent(unsigned)
+	require.NoError(t, err)
+	assert.Equal(t, unsignedHash, signedHash)
+}
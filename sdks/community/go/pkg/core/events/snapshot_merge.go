@@ -0,0 +1,126 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrSnapshotMergeConflict is returned by StateSnapshotEvent.Merge with
+// MergeStrategyConflictError when both snapshots hold different values for
+// the same key
+var ErrSnapshotMergeConflict = errors.New("conflicting snapshot keys")
+
+// MergeStrategy selects how StateSnapshotEvent.Merge combines keys present
+// in both snapshots
+type MergeStrategy int
+
+const (
+	// MergeStrategyLastWrite takes the value of the other snapshot for every
+	// top-level key it holds
+	MergeStrategyLastWrite MergeStrategy = iota
+	// MergeStrategyConflictError fails if a top-level key holds different
+	// values in the two snapshots; equal values are not a conflict
+	MergeStrategyConflictError
+	// MergeStrategyDeep merges nested objects key by key, taking the value of
+	// the other snapshot wherever either value is not an object
+	MergeStrategyDeep
+)
+
+// String returns the string representation of the strategy
+func (s MergeStrategy) String() string {
+	switch s {
+	case MergeStrategyLastWrite:
+		return "last-write"
+	case MergeStrategyConflictError:
+		return "conflict-error"
+	case MergeStrategyDeep:
+		return "deep"
+	default:
+		return "unknown"
+	}
+}
+
+// Merge combines the snapshot of the event with that of other, whose values
+// take precedence as the strategy allows, for example to combine the state
+// written by several agents without a full re-snapshot. Both snapshots must
+// be JSON objects. Unlike a JSON Merge Patch, a null value in other is a
+// value and does not remove the key.
+//
+// The result is a new event; neither input is modified, and the merged
+// snapshot shares no maps or slices with them.
+func (e *StateSnapshotEvent) Merge(other *StateSnapshotEvent, strategy MergeStrategy) (*StateSnapshotEvent, error) {
+	if other == nil {
+		return nil, fmt.Errorf("cannot merge nil snapshot")
+	}
+
+	base, err := snapshotObject(e.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+	overlay, err := snapshotObject(other.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strategy {
+	case MergeStrategyLastWrite:
+		for key, value := range overlay {
+			base[key] = value
+		}
+
+	case MergeStrategyConflictError:
+		var conflicts []string
+		for key, value := range overlay {
+			if existing, ok := base[key]; ok && !jsonValuesEqual(existing, value) {
+				conflicts = append(conflicts, key)
+			}
+			base[key] = value
+		}
+		if len(conflicts) > 0 {
+			sort.Strings(conflicts)
+			return nil, fmt.Errorf("%w: %q", ErrSnapshotMergeConflict, conflicts)
+		}
+
+	case MergeStrategyDeep:
+		deepMergeObjects(base, overlay)
+
+	default:
+		return nil, fmt.Errorf("unknown merge strategy %d", strategy)
+	}
+
+	return NewStateSnapshotEvent(base, WithSharedSnapshot()), nil
+}
+
+// deepMergeObjects merges overlay into base, recursing into keys whose
+// values are objects on both sides
+func deepMergeObjects(base, overlay map[string]any) {
+	for key, value := range overlay {
+		existing, ok := base[key].(map[string]any)
+		if nested, isObject := value.(map[string]any); ok && isObject {
+			deepMergeObjects(existing, nested)
+			continue
+		}
+		base[key] = value
+	}
+}
+
+// snapshotObject returns a copy of a snapshot as a generic JSON object,
+// decoding it if it is raw JSON
+func snapshotObject(snapshot any) (map[string]any, error) {
+	value := copySnapshotValue(snapshot)
+	if _, ok := value.(map[string]any); !ok && value != nil {
+		// Raw JSON, structs and typed maps are merged in their generic form
+		decoded, err := normalizeJSONValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("cannot merge snapshot: %w", err)
+		}
+		value = decoded
+	}
+
+	object, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("cannot merge snapshot of type %T: snapshot must be an object", snapshot)
+	}
+	return object, nil
+}
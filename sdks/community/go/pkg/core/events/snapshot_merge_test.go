@@ -0,0 +1,123 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateSnapshotEvent_Merge(t *testing.T) {
+	left := func() *StateSnapshotEvent {
+		return NewStateSnapshotEvent(map[string]any{
+			"planner": map[string]any{"step": 1, "notes": []any{"a"}},
+			"shared":  "left",
+			"same":    1,
+		})
+	}
+	right := func() *StateSnapshotEvent {
+		return NewStateSnapshotEvent(map[string]any{
+			"planner":  map[string]any{"step": 2},
+			"executor": map[string]any{"done": true},
+			"shared":   nil,
+			"same":     1.0,
+		})
+	}
+
+	tests := []struct {
+		name     string
+		strategy MergeStrategy
+		expected map[string]any
+		conflict bool
+	}{
+		{
+			name:     "LastWrite",
+			strategy: MergeStrategyLastWrite,
+			expected: map[string]any{
+				"planner":  map[string]any{"step": 2},
+				"executor": map[string]any{"done": true},
+				"shared":   nil,
+				"same":     1.0,
+			},
+		},
+		{
+			name:     "Deep",
+			strategy: MergeStrategyDeep,
+			expected: map[string]any{
+				"planner":  map[string]any{"step": 2, "notes": []any{"a"}},
+				"executor": map[string]any{"done": true},
+				"shared":   nil,
+				"same":     1.0,
+			},
+		},
+		{
+			name:     "ConflictError",
+			strategy: MergeStrategyConflictError,
+			conflict: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := left(), right()
+			merged, err := a.Merge(b, tt.strategy)
+
+			// Neither input is modified
+			assert.Equal(t, left().Snapshot, a.Snapshot)
+			assert.Equal(t, right().Snapshot, b.Snapshot)
+
+			if tt.conflict {
+				assert.Nil(t, merged)
+				assert.ErrorIs(t, err, ErrSnapshotMergeConflict)
+				assert.Contains(t, err.Error(), `["planner" "shared"]`)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, merged.Validate())
+			assert.Equal(t, EventTypeStateSnapshot, merged.Type())
+			assert.Equal(t, tt.expected, merged.Snapshot)
+
+			// The result shares nothing with the inputs
+			merged.Snapshot.(map[string]any)["planner"].(map[string]any)["step"] = 99
+			assert.Equal(t, left().Snapshot, a.Snapshot)
+			assert.Equal(t, right().Snapshot, b.Snapshot)
+		})
+	}
+}
+
+func TestStateSnapshotEvent_MergeDisjoint(t *testing.T) {
+	a := NewStateSnapshotEvent(map[string]any{"planner": map[string]any{"step": 1}, "count": 2})
+	b := NewStateSnapshotEvent(json.RawMessage(`{"executor":{"done":true},"count":2}`))
+
+	merged, err := a.Merge(b, MergeStrategyConflictError)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"planner":  map[string]any{"step": 1},
+		"executor": map[string]any{"done": true},
+		"count":    float64(2),
+	}, merged.Snapshot)
+}
+
+func TestStateSnapshotEvent_MergeErrors(t *testing.T) {
+	object := NewStateSnapshotEvent(map[string]any{"a": 1})
+
+	_, err := object.Merge(nil, MergeStrategyLastWrite)
+	assert.Error(t, err)
+
+	_, err = object.Merge(NewStateSnapshotEvent([]any{1, 2}), MergeStrategyLastWrite)
+	assert.Error(t, err)
+
+	_, err = NewStateSnapshotEvent("text").Merge(object, MergeStrategyDeep)
+	assert.Error(t, err)
+
+	_, err = object.Merge(object, MergeStrategy(42))
+	assert.Error(t, err)
+}
+
+func TestMergeStrategy_String(t *testing.T) {
+	assert.Equal(t, "last-write", MergeStrategyLastWrite.String())
+	assert.Equal(t, "conflict-error", MergeStrategyConflictError.String())
+	assert.Equal(t, "deep", MergeStrategyDeep.String())
+	assert.Equal(t, "unknown", MergeStrategy(42).String())
+}
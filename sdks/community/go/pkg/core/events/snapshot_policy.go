@@ -0,0 +1,115 @@
+package events
+
+import (
+	"fmt"
+	"time"
+)
+
+// SnapshotPolicy compacts an outgoing event stream by injecting a
+// STATE_SNAPSHOT after every so many state deltas, or once enough time has
+// passed since the last snapshot, so that late-joining clients do not have to
+// replay the whole delta history. It tracks the state from the snapshots and
+// deltas it sees, so injected snapshots reflect every prior delta exactly.
+// It implements EventStage and is not safe for concurrent use.
+type SnapshotPolicy struct {
+	everyDeltas int
+	interval    time.Duration
+	now         EventClock
+
+	state        *StateStore
+	deltas       int
+	lastSnapshot time.Time
+}
+
+// SnapshotPolicyOption defines options for creating snapshot policies
+type SnapshotPolicyOption func(*SnapshotPolicy)
+
+// WithSnapshotEvery injects a snapshot after every n deltas
+func WithSnapshotEvery(n int) SnapshotPolicyOption {
+	return func(p *SnapshotPolicy) {
+		p.everyDeltas = n
+	}
+}
+
+// WithSnapshotInterval injects a snapshot with the first delta emitted once d
+// has passed since the last snapshot
+func WithSnapshotInterval(d time.Duration) SnapshotPolicyOption {
+	return func(p *SnapshotPolicy) {
+		p.interval = d
+	}
+}
+
+// WithSnapshotPolicyClock sets the clock used to time snapshot intervals,
+// instead of time.Now
+func WithSnapshotPolicyClock(clock EventClock) SnapshotPolicyOption {
+	return func(p *SnapshotPolicy) {
+		if clock != nil {
+			p.now = clock
+		}
+	}
+}
+
+// NewSnapshotPolicy creates a snapshot policy. Without options it never
+// injects snapshots. The tracked state starts as an empty object, matching
+// emitters that send deltas before their first snapshot.
+func NewSnapshotPolicy(opts ...SnapshotPolicyOption) *SnapshotPolicy {
+	p := &SnapshotPolicy{
+		now:   time.Now,
+		state: NewStateStore(WithEmptyInitialState()),
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	p.lastSnapshot = p.now()
+	return p
+}
+
+// Process records an outgoing event and returns the events to emit in its
+// place: the event itself, followed by a snapshot of the state when the delta
+// crosses a threshold. A delta that cannot be applied to the tracked state is
+// returned as an error, since the snapshots would no longer be accurate.
+func (p *SnapshotPolicy) Process(e Event) ([]Event, error) {
+	switch event := e.(type) {
+	case *StateSnapshotEvent:
+		p.state.ApplySnapshot(event)
+		p.deltas = 0
+		p.lastSnapshot = p.now()
+		return []Event{e}, nil
+
+	case *StateDeltaEvent:
+		if err := p.state.ApplyDelta(event); err != nil {
+			return nil, fmt.Errorf("snapshot policy: %w", err)
+		}
+		p.deltas++
+		if !p.due() {
+			return []Event{e}, nil
+		}
+
+		p.deltas = 0
+		p.lastSnapshot = p.now()
+		return []Event{e, NewStateSnapshotEvent(p.state.Current(), WithSharedSnapshot())}, nil
+
+	default:
+		return []Event{e}, nil
+	}
+}
+
+// Flush implements EventStage. The policy holds no events.
+func (p *SnapshotPolicy) Flush() []Event {
+	return nil
+}
+
+// State returns a copy of the tracked state
+func (p *SnapshotPolicy) State() any {
+	return p.state.Current()
+}
+
+// due reports whether a threshold has been crossed since the last snapshot
+func (p *SnapshotPolicy) due() bool {
+	if p.everyDeltas > 0 && p.deltas >= p.everyDeltas {
+		return true
+	}
+	return p.interval > 0 && p.now().Sub(p.lastSnapshot) >= p.interval
+}
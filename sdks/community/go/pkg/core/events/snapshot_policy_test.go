@@ -0,0 +1,154 @@
+package events
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// randomStateStream builds a stream that starts with a snapshot and then
+// evolves the state through random deltas, interleaved with other events
+func randomStateStream(t *testing.T, seed int64, deltas int) []Event {
+	t.Helper()
+	rng := rand.New(rand.NewSource(seed))
+
+	state := map[string]any{"a": randomJSONValue(rng, 3)}
+	stream := []Event{NewRunStartedEvent("thread-1", "run-1"), NewStateSnapshotEvent(state)}
+
+	var current any = state
+	for emitted := 0; emitted < deltas; {
+		next := mutateJSONValue(rng, current, 4)
+		ops, err := DiffState(current, next)
+		require.NoError(t, err)
+		if len(ops) == 0 {
+			continue
+		}
+		stream = append(stream, NewStateDeltaEvent(ops))
+		emitted++
+		if rng.Intn(4) == 0 {
+			stream = append(stream, NewStepStartedEvent("step"))
+		}
+		current = next
+	}
+	return append(stream, NewRunFinishedEvent("thread-1", "run-1"))
+}
+
+// replayState applies a stream to a fresh state store
+func replayState(t *testing.T, stream []Event) any {
+	t.Helper()
+	store := NewStateStore()
+	for i, event := range stream {
+		require.NoError(t, store.Apply(event), "event %d", i)
+	}
+	return store.Current()
+}
+
+func compact(t *testing.T, policy *SnapshotPolicy, stream []Event) []Event {
+	t.Helper()
+	var out []Event
+	for _, event := range stream {
+		emitted, err := policy.Process(event)
+		require.NoError(t, err)
+		out = append(out, emitted...)
+	}
+	return out
+}
+
+func TestSnapshotPolicy(t *testing.T) {
+	t.Run("EveryNDeltas", func(t *testing.T) {
+		for seed := int64(0); seed < 20; seed++ {
+			original := randomStateStream(t, seed, 100)
+			policy := NewSnapshotPolicy(WithSnapshotEvery(10))
+			compacted := compact(t, policy, original)
+
+			expected := replayState(t, original)
+			assert.Equal(t, expected, replayState(t, compacted), "seed %d", seed)
+			assert.Equal(t, expected, policy.State(), "seed %d", seed)
+
+			// A client joining at the last snapshot reaches the same state
+			last := 0
+			injected := 0
+			for i, event := range compacted {
+				if _, ok := event.(*StateSnapshotEvent); ok {
+					last = i
+					injected++
+				}
+			}
+			assert.Equal(t, 1+100/10, injected, "seed %d", seed)
+			assert.Equal(t, expected, replayState(t, compacted[last:]), "seed %d", seed)
+		}
+	})
+
+	t.Run("SnapshotFollowsThresholdDelta", func(t *testing.T) {
+		policy := NewSnapshotPolicy(WithSnapshotEvery(2))
+
+		first, err := policy.Process(NewStateDeltaEvent([]JSONPatchOperation{{Op: "add", Path: "/a", Value: 1}}))
+		require.NoError(t, err)
+		assert.Len(t, first, 1)
+
+		second, err := policy.Process(NewStateDeltaEvent([]JSONPatchOperation{{Op: "add", Path: "/b", Value: 2}}))
+		require.NoError(t, err)
+		require.Len(t, second, 2)
+		snapshot, ok := second[1].(*StateSnapshotEvent)
+		require.True(t, ok)
+		assert.Equal(t, map[string]any{"a": 1.0, "b": 2.0}, snapshot.Snapshot)
+		assert.NoError(t, snapshot.Validate())
+
+		// Emitted snapshots reset the count
+		_, err = policy.Process(NewStateSnapshotEvent(map[string]any{}))
+		require.NoError(t, err)
+		third, err := policy.Process(NewStateDeltaEvent([]JSONPatchOperation{{Op: "add", Path: "/c", Value: 3}}))
+		require.NoError(t, err)
+		assert.Len(t, third, 1)
+	})
+
+	t.Run("Interval", func(t *testing.T) {
+		clock := time.Unix(0, 0)
+		policy := NewSnapshotPolicy(
+			WithSnapshotInterval(time.Minute),
+			WithSnapshotPolicyClock(func() time.Time { return clock }),
+		)
+
+		delta := func(value int) []Event {
+			out, err := policy.Process(NewStateDeltaEvent([]JSONPatchOperation{{Op: "add", Path: "/n", Value: value}}))
+			require.NoError(t, err)
+			return out
+		}
+
+		clock = clock.Add(30 * time.Second)
+		assert.Len(t, delta(1), 1)
+		clock = clock.Add(30 * time.Second)
+		assert.Len(t, delta(2), 2)
+		clock = clock.Add(59 * time.Second)
+		assert.Len(t, delta(3), 1)
+		clock = clock.Add(time.Second)
+		out := delta(4)
+		require.Len(t, out, 2)
+		assert.Equal(t, map[string]any{"n": 4.0}, out[1].(*StateSnapshotEvent).Snapshot)
+	})
+
+	t.Run("Stage", func(t *testing.T) {
+		original := randomStateStream(t, 7, 30)
+		policy := NewSnapshotPolicy(WithSnapshotEvery(10))
+
+		out, err := ApplyStages(original, policy)
+		require.NoError(t, err)
+		assert.Equal(t, len(original)+3, len(out))
+		assert.Equal(t, replayState(t, original), replayState(t, out))
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		original := randomStateStream(t, 99, 50)
+		assert.Equal(t, original, compact(t, NewSnapshotPolicy(), original))
+	})
+
+	t.Run("InvalidDelta", func(t *testing.T) {
+		policy := NewSnapshotPolicy(WithSnapshotEvery(1))
+		_, err := policy.Process(NewStateDeltaEvent([]JSONPatchOperation{{Op: "remove", Path: "/missing"}}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "snapshot policy")
+	})
+}
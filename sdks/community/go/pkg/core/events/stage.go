@@ -0,0 +1,46 @@
+package events
+
+// EventStage transforms an event stream one event at a time. Process
+// returns the events to emit in place of an event, and Flush the events
+// still held at the end of the stream.
+type EventStage interface {
+	Process(e Event) ([]Event, error)
+	Flush() []Event
+}
+
+// ApplyStages passes events through the stages in order, flushing each
+// stage at the end, and returns the resulting events
+func ApplyStages(events []Event, stages ...EventStage) ([]Event, error) {
+	for _, stage := range stages {
+		var out []Event
+		for _, e := range events {
+			emitted, err := stage.Process(e)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, emitted...)
+		}
+		events = append(out, stage.Flush()...)
+	}
+	return events, nil
+}
+
+// infallibleStage adapts the stream processors whose Process cannot fail,
+// such as DeltaCoalescer, to EventStage
+type infallibleStage struct {
+	process func(e Event) []Event
+	flush   func() []Event
+}
+
+// Process implements EventStage
+func (s infallibleStage) Process(e Event) ([]Event, error) {
+	return s.process(e), nil
+}
+
+// Flush implements EventStage
+func (s infallibleStage) Flush() []Event {
+	if s.flush == nil {
+		return nil
+	}
+	return s.flush()
+}
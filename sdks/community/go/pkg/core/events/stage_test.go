@@ -0,0 +1,42 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStageAdapters(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	clock := func() time.Time { return now }
+	cache := NewMemoryDeduplicationCache(0)
+	cache.now = clock
+	timeouts := NewToolCallTimeoutMiddleware(clock)
+
+	started := NewRunStartedEvent("thread-1", "run-1")
+	out, err := ApplyStages([]Event{
+		started,
+		started.Clone(),
+		NewStateDeltaEvent([]JSONPatchOperation{{Op: "add", Path: "/a", Value: 1}}),
+		NewStateDeltaEvent([]JSONPatchOperation{{Op: "replace", Path: "/a", Value: 2}}),
+		NewToolCallStartEvent("call-1", "search", WithTimeoutMs(1000)),
+	},
+		NewDeduplicator(cache, time.Minute).Stage(),
+		NewDeltaCoalescer(WithDeltaWindow(0)).Stage(),
+		timeouts.Stage(),
+	)
+	require.NoError(t, err)
+	require.Len(t, out, 3)
+	assert.Equal(t, started, out[0])
+	assert.Equal(t, []JSONPatchOperation{{Op: "add", Path: "/a", Value: 2}}, out[1].(*StateDeltaEvent).Delta)
+	assert.Equal(t, EventTypeToolCallStart, out[2].Type())
+
+	// The timeout stage reports overdue tool calls when it is flushed
+	now = now.Add(time.Second)
+	out, err = ApplyStages(nil, timeouts.Stage())
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	assert.True(t, out[0].(*ToolCallResultEvent).IsTimeout)
+}
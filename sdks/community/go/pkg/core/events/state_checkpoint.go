@@ -0,0 +1,128 @@
+package events
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	// StateCheckpointFormat identifies a serialized state store checkpoint
+	StateCheckpointFormat = "ag-ui/state-checkpoint"
+
+	// StateCheckpointVersion is the checkpoint format version written by
+	// Checkpoint and the newest one RestoreStateStore accepts
+	StateCheckpointVersion = 1
+)
+
+// ErrInvalidCheckpoint is returned by RestoreStateStore for data that is not
+// a valid checkpoint, including corrupted and newer-versioned checkpoints
+var ErrInvalidCheckpoint = errors.New("invalid state checkpoint")
+
+// stateCheckpoint is the self-describing envelope of a checkpoint
+type stateCheckpoint struct {
+	Format        string          `json:"format"`
+	FormatVersion int             `json:"formatVersion"`
+	HasSnapshot   bool            `json:"hasSnapshot"`
+	Version       uint64          `json:"version"`
+	Sequence      uint64          `json:"sequence"`
+	State         json.RawMessage `json:"state"`
+
+	// Checksum is the hex-encoded SHA-256 of the compacted State, which
+	// detects corruption that still parses as JSON
+	Checksum string `json:"checksum"`
+}
+
+// Checkpoint serializes the contents of the store, its version and the
+// sequence number of the last applied event, so that it can be restored with
+// RestoreStateStore without replaying the events. Subscriptions are not
+// included. It fails if the state cannot be represented as JSON.
+func (s *StateStore) Checkpoint() ([]byte, error) {
+	s.mu.RLock()
+	state, hasSnapshot, version, sequence := s.state, s.hasSnapshot, s.version, s.sequence
+	s.mu.RUnlock()
+
+	// The tree is never modified once stored, so it can be encoded without
+	// holding the lock
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checkpoint state: %w", err)
+	}
+
+	return json.Marshal(stateCheckpoint{
+		Format:        StateCheckpointFormat,
+		FormatVersion: StateCheckpointVersion,
+		HasSnapshot:   hasSnapshot,
+		Version:       version,
+		Sequence:      sequence,
+		State:         data,
+		Checksum:      checkpointChecksum(data),
+	})
+}
+
+// RestoreStateStore creates a state store from a checkpoint produced by
+// Checkpoint. The store continues from the checkpointed version and accepts
+// the deltas that followed it. Options apply as for NewStateStore, except
+// that the checkpointed state takes precedence over WithEmptyInitialState.
+// Data that is not a valid checkpoint is rejected with an error wrapping
+// ErrInvalidCheckpoint.
+func RestoreStateStore(data []byte, options ...StateStoreOption) (*StateStore, error) {
+	var checkpoint stateCheckpoint
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&checkpoint); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCheckpoint, err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("%w: trailing data after checkpoint", ErrInvalidCheckpoint)
+	}
+
+	if checkpoint.Format != StateCheckpointFormat {
+		return nil, fmt.Errorf("%w: unknown format %q", ErrInvalidCheckpoint, checkpoint.Format)
+	}
+	if checkpoint.FormatVersion < 1 || checkpoint.FormatVersion > StateCheckpointVersion {
+		return nil, fmt.Errorf("%w: unsupported format version %d, expected at most %d",
+			ErrInvalidCheckpoint, checkpoint.FormatVersion, StateCheckpointVersion)
+	}
+	if checkpoint.State == nil {
+		return nil, fmt.Errorf("%w: missing state", ErrInvalidCheckpoint)
+	}
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, checkpoint.State); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCheckpoint, err)
+	}
+	if checkpoint.Checksum != checkpointChecksum(compacted.Bytes()) {
+		return nil, fmt.Errorf("%w: state checksum mismatch", ErrInvalidCheckpoint)
+	}
+
+	s := NewStateStore(options...)
+
+	var state any
+	stateDecoder := json.NewDecoder(bytes.NewReader(checkpoint.State))
+	if s.preserveNumbers {
+		stateDecoder.UseNumber()
+	}
+	if err := stateDecoder.Decode(&state); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCheckpoint, err)
+	}
+	if !checkpoint.HasSnapshot && state != nil {
+		return nil, fmt.Errorf("%w: state without snapshot", ErrInvalidCheckpoint)
+	}
+
+	s.state = state
+	s.hasSnapshot = checkpoint.HasSnapshot
+	s.version = checkpoint.Version
+	s.sequence = checkpoint.Sequence
+	s.history = nil
+	s.recordHistory()
+	return s, nil
+}
+
+// checkpointChecksum returns the hex-encoded SHA-256 of the serialized state
+func checkpointChecksum(state []byte) string {
+	sum := sha256.Sum256(state)
+	return hex.EncodeToString(sum[:])
+}
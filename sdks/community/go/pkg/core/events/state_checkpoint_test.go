@@ -0,0 +1,164 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkpointEventLog returns a decoded run of state events
+func checkpointEventLog(t *testing.T) []Event {
+	t.Helper()
+	built := []Event{
+		NewStateSnapshotEvent(map[string]any{"counter": 0, "items": []any{}}),
+	}
+	for i := 1; i <= 10; i++ {
+		built = append(built, NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "replace", Path: "/counter", Value: i},
+			{Op: "add", Path: "/items/-", Value: map[string]any{"n": i}},
+		}))
+		if i%4 == 0 {
+			built = append(built, NewMergePatchEvent(map[string]any{"phase": fmt.Sprint("phase-", i)}))
+		}
+	}
+
+	decoder := NewEventDecoder(nil)
+	log := make([]Event, len(built))
+	for i, event := range built {
+		data, err := event.ToJSON()
+		require.NoError(t, err)
+		log[i], err = decoder.DecodeEvent(string(event.Type()), data)
+		require.NoError(t, err)
+	}
+	return log
+}
+
+func TestStateStoreCheckpoint(t *testing.T) {
+	t.Run("RestoreMidRun", func(t *testing.T) {
+		log := checkpointEventLog(t)
+
+		full := NewStateStore()
+		for _, event := range log {
+			require.NoError(t, full.Apply(event))
+		}
+
+		for split := 0; split <= len(log); split++ {
+			partial := NewStateStore()
+			for _, event := range log[:split] {
+				require.NoError(t, partial.Apply(event))
+			}
+			data, err := partial.Checkpoint()
+			require.NoError(t, err)
+
+			restored, err := RestoreStateStore(data)
+			require.NoError(t, err, "split %d", split)
+			assert.Equal(t, partial.Version(), restored.Version())
+			assert.Equal(t, partial.LastSequence(), restored.LastSequence())
+			if split > 0 {
+				assert.Equal(t, log[split-1].GetBaseEvent().Sequence(), restored.LastSequence())
+			}
+
+			for _, event := range log[split:] {
+				require.NoError(t, restored.Apply(event))
+			}
+			assert.Equal(t, full.Current(), restored.Current(), "split %d", split)
+			assert.Equal(t, full.Version(), restored.Version())
+			assert.Equal(t, full.LastSequence(), restored.LastSequence())
+		}
+	})
+
+	t.Run("WithoutSnapshot", func(t *testing.T) {
+		data, err := NewStateStore().Checkpoint()
+		require.NoError(t, err)
+
+		restored, err := RestoreStateStore(data)
+		require.NoError(t, err)
+		assert.Error(t, restored.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{{Op: "add", Path: "/a", Value: 1}})))
+
+		data, err = NewStateStore(WithEmptyInitialState()).Checkpoint()
+		require.NoError(t, err)
+		restored, err = RestoreStateStore(data)
+		require.NoError(t, err)
+		assert.NoError(t, restored.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{{Op: "add", Path: "/a", Value: 1}})))
+	})
+
+	t.Run("RestoredStoreIsIndependent", func(t *testing.T) {
+		store := NewStateStore()
+		store.ApplySnapshot(NewStateSnapshotEvent(map[string]any{"a": 1}))
+		data, err := store.Checkpoint()
+		require.NoError(t, err)
+
+		restored, err := RestoreStateStore(data)
+		require.NoError(t, err)
+		require.NoError(t, restored.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{{Op: "replace", Path: "/a", Value: 2}})))
+		assert.Equal(t, map[string]any{"a": float64(1)}, store.Current())
+	})
+
+	t.Run("Unserializable", func(t *testing.T) {
+		store := NewStateStore()
+		store.ApplySnapshot(NewStateSnapshotEvent(make(chan int)))
+		_, err := store.Checkpoint()
+		assert.Error(t, err)
+	})
+}
+
+func TestRestoreStateStoreRejectsInvalidData(t *testing.T) {
+	store := NewStateStore()
+	store.ApplySnapshot(NewStateSnapshotEvent(map[string]any{"name": "ada", "count": 3}))
+	valid, err := store.Checkpoint()
+	require.NoError(t, err)
+
+	// edit rewrites one field of the valid envelope
+	edit := func(key string, value any) []byte {
+		var envelope map[string]any
+		require.NoError(t, json.Unmarshal(valid, &envelope))
+		if value == nil {
+			delete(envelope, key)
+		} else {
+			envelope[key] = value
+		}
+		data, err := json.Marshal(envelope)
+		require.NoError(t, err)
+		return data
+	}
+
+	cases := map[string][]byte{
+		"Empty":            nil,
+		"NotJSON":          []byte("checkpoint"),
+		"Truncated":        valid[:len(valid)/2],
+		"TrailingData":     append(append([]byte{}, valid...), []byte(`{}`)...),
+		"WrongFormat":      edit("format", "other"),
+		"MissingVersion":   edit("formatVersion", nil),
+		"FutureVersion":    edit("formatVersion", StateCheckpointVersion+1),
+		"MissingState":     edit("state", nil),
+		"AlteredState":     []byte(strings.Replace(string(valid), `"ada"`, `"bob"`, 1)),
+		"WrongChecksum":    edit("checksum", strings.Repeat("0", 64)),
+		"UnknownField":     edit("extra", true),
+		"StateNoSnapshot":  edit("hasSnapshot", false),
+		"NegativeVersion":  edit("version", -1),
+		"NonNumericSeqNum": edit("sequence", "7"),
+	}
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			restored, err := RestoreStateStore(data)
+			assert.Nil(t, restored)
+			assert.True(t, errors.Is(err, ErrInvalidCheckpoint), "got %v", err)
+		})
+	}
+
+	t.Run("PrettyPrinted", func(t *testing.T) {
+		var envelope map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(valid, &envelope))
+		data, err := json.MarshalIndent(envelope, "", "  ")
+		require.NoError(t, err)
+
+		restored, err := RestoreStateStore(data)
+		require.NoError(t, err)
+		assert.Equal(t, store.Current(), restored.Current())
+	})
+}
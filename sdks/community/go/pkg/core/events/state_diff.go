@@ -0,0 +1,175 @@
+package events
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/jsonpointer"
+)
+
+// DiffState computes the JSON Patch operations that transform before into
+// after. Both documents are compared in their generic JSON form, so numbers
+// are equal when their values are, regardless of Go type. Changed values are
+// replaced in place, object members are added or removed by key, and arrays
+// are edited by index. Applying the patch to before reproduces after.
+func DiffState(before, after any) ([]JSONPatchOperation, error) {
+	from, err := normalizeJSONValue(before)
+	if err != nil {
+		return nil, fmt.Errorf("invalid before document: %w", err)
+	}
+	to, err := normalizeJSONValue(after)
+	if err != nil {
+		return nil, fmt.Errorf("invalid after document: %w", err)
+	}
+
+	ops := []JSONPatchOperation{}
+	return diffValues("", from, to, ops), nil
+}
+
+// NewStateDeltaEventFromDiff creates a state delta event carrying the patch
+// from before to after. When the documents are equal the delta is empty,
+// which does not validate, so callers should skip emitting it.
+func NewStateDeltaEventFromDiff(before, after any) (*StateDeltaEvent, error) {
+	ops, err := DiffState(before, after)
+	if err != nil {
+		return nil, err
+	}
+	return NewStateDeltaEvent(ops), nil
+}
+
+// diffPath is the location of a value being diffed, linked to the location
+// of its parent so that nested locations share their prefix. It is only
+// rendered as a JSON Pointer for the values that produce operations.
+type diffPath struct {
+	parent *diffPath
+	token  string
+
+	// root is the pointer of the outermost location, set on it alone
+	root string
+}
+
+// child returns the location of a member of the value at p
+func (p *diffPath) child(token string) *diffPath {
+	return &diffPath{parent: p, token: token}
+}
+
+// String renders the location as a JSON Pointer
+func (p *diffPath) String() string {
+	var tokens []string
+	for ; p.parent != nil; p = p.parent {
+		tokens = append(tokens, p.token)
+	}
+
+	var b strings.Builder
+	b.WriteString(p.root)
+	for i := len(tokens) - 1; i >= 0; i-- {
+		b.WriteByte('/')
+		b.WriteString(jsonpointer.Escape(tokens[i]))
+	}
+	return b.String()
+}
+
+// diffTask is a pending step of diffValues: either an operation to append,
+// or the values at path to diff
+type diffTask struct {
+	op       string
+	path     *diffPath
+	from, to any
+}
+
+// diffValues appends the operations that transform from into to at path.
+// Nested values are diffed with a stack of tasks rather than recursion, so
+// deep documents cannot exhaust the stack; the tasks of a value are pushed in
+// reverse so that operations come out in document order.
+func diffValues(path string, from, to any, ops []JSONPatchOperation) []JSONPatchOperation {
+	tasks := []diffTask{{path: &diffPath{root: path}, from: from, to: to}}
+	for len(tasks) > 0 {
+		task := tasks[len(tasks)-1]
+		tasks = tasks[:len(tasks)-1]
+
+		switch task.op {
+		case "add":
+			ops = append(ops, JSONPatchOperation{Op: "add", Path: task.path.String(), Value: task.to})
+			continue
+		case "remove":
+			ops = append(ops, JSONPatchOperation{Op: "remove", Path: task.path.String()})
+			continue
+		}
+
+		var steps []diffTask
+		switch f := task.from.(type) {
+		case map[string]any:
+			if t, ok := task.to.(map[string]any); ok {
+				steps = diffObjects(task.path, f, t)
+			}
+		case []any:
+			if t, ok := task.to.([]any); ok {
+				steps = diffArrays(task.path, f, t)
+			}
+		}
+
+		if steps == nil {
+			if !jsonValuesEqual(task.from, task.to) {
+				ops = append(ops, JSONPatchOperation{Op: "replace", Path: task.path.String(), Value: task.to})
+			}
+			continue
+		}
+		for i := len(steps) - 1; i >= 0; i-- {
+			tasks = append(tasks, steps[i])
+		}
+	}
+	return ops
+}
+
+// diffObjects returns the steps diffing two objects member by member, in key
+// order. The result is not nil, even for empty objects.
+func diffObjects(path *diffPath, from, to map[string]any) []diffTask {
+	keys := make([]string, 0, len(from)+len(to))
+	for key := range from {
+		keys = append(keys, key)
+	}
+	for key := range to {
+		if _, ok := from[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	steps := make([]diffTask, 0, len(keys))
+	for _, key := range keys {
+		memberPath := path.child(key)
+		fromValue, inFrom := from[key]
+		toValue, inTo := to[key]
+
+		switch {
+		case !inTo:
+			steps = append(steps, diffTask{op: "remove", path: memberPath})
+		case !inFrom:
+			steps = append(steps, diffTask{op: "add", path: memberPath, to: toValue})
+		default:
+			steps = append(steps, diffTask{path: memberPath, from: fromValue, to: toValue})
+		}
+	}
+	return steps
+}
+
+// diffArrays returns the steps diffing two arrays index by index. Trailing
+// elements are removed from the end first so that earlier indexes stay
+// valid. The result is not nil, even for empty arrays.
+func diffArrays(path *diffPath, from, to []any) []diffTask {
+	common := min(len(from), len(to))
+	steps := make([]diffTask, 0, max(len(from), len(to)))
+	for i := 0; i < common; i++ {
+		steps = append(steps, diffTask{path: path.child(strconv.Itoa(i)), from: from[i], to: to[i]})
+	}
+
+	for i := len(from) - 1; i >= common; i-- {
+		steps = append(steps, diffTask{op: "remove", path: path.child(strconv.Itoa(i))})
+	}
+	for i := common; i < len(to); i++ {
+		steps = append(steps, diffTask{op: "add", path: path.child("-"), to: to[i]})
+	}
+	return steps
+}
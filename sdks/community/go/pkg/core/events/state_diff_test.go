@@ -0,0 +1,285 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffState(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   any
+		after    any
+		expected []JSONPatchOperation
+	}{
+		{
+			name:     "Equal",
+			before:   map[string]any{"a": 1, "b": []any{"x"}},
+			after:    map[string]any{"a": 1, "b": []any{"x"}},
+			expected: []JSONPatchOperation{},
+		},
+		{
+			name:     "IntAndFloatAreEqual",
+			before:   map[string]any{"count": 2},
+			after:    map[string]any{"count": 2.0},
+			expected: []JSONPatchOperation{},
+		},
+		{
+			name:     "ReplaceChangedValue",
+			before:   map[string]any{"status": "running", "progress": 10},
+			after:    map[string]any{"status": "running", "progress": 20},
+			expected: []JSONPatchOperation{{Op: "replace", Path: "/progress", Value: float64(20)}},
+		},
+		{
+			name:   "AddAndRemoveKeys",
+			before: map[string]any{"old": true, "keep": 1},
+			after:  map[string]any{"new": "x", "keep": 1},
+			expected: []JSONPatchOperation{
+				{Op: "add", Path: "/new", Value: "x"},
+				{Op: "remove", Path: "/old"},
+			},
+		},
+		{
+			name:   "Nulls",
+			before: map[string]any{"a": nil, "b": 1},
+			after:  map[string]any{"a": 1, "b": nil, "c": nil},
+			expected: []JSONPatchOperation{
+				{Op: "replace", Path: "/a", Value: float64(1)},
+				{Op: "replace", Path: "/b", Value: nil},
+				{Op: "add", Path: "/c", Value: nil},
+			},
+		},
+		{
+			name:   "ArrayEditsByIndex",
+			before: map[string]any{"items": []any{"a", "b", "c", "d"}},
+			after:  map[string]any{"items": []any{"a", "x"}},
+			expected: []JSONPatchOperation{
+				{Op: "replace", Path: "/items/1", Value: "x"},
+				{Op: "remove", Path: "/items/3"},
+				{Op: "remove", Path: "/items/2"},
+			},
+		},
+		{
+			name:   "ArrayAppend",
+			before: []any{1},
+			after:  []any{1, map[string]any{"k": "v"}, 3},
+			expected: []JSONPatchOperation{
+				{Op: "add", Path: "/-", Value: map[string]any{"k": "v"}},
+				{Op: "add", Path: "/-", Value: float64(3)},
+			},
+		},
+		{
+			name:     "TypeChange",
+			before:   map[string]any{"value": map[string]any{"a": 1}},
+			after:    map[string]any{"value": []any{1}},
+			expected: []JSONPatchOperation{{Op: "replace", Path: "/value", Value: []any{float64(1)}}},
+		},
+		{
+			name:     "EscapedKeys",
+			before:   map[string]any{"a/b": 1, "c~d": 1},
+			after:    map[string]any{"a/b": 2, "c~d": 1},
+			expected: []JSONPatchOperation{{Op: "replace", Path: "/a~1b", Value: float64(2)}},
+		},
+		{
+			name:     "ReplaceRoot",
+			before:   "old",
+			after:    map[string]any{"a": 1},
+			expected: []JSONPatchOperation{{Op: "replace", Path: "", Value: map[string]any{"a": float64(1)}}},
+		},
+		{
+			name:   "StructDocuments",
+			before: struct{ Steps []string }{Steps: []string{"plan"}},
+			after:  struct{ Steps []string }{Steps: []string{"plan", "act"}},
+			expected: []JSONPatchOperation{
+				{Op: "add", Path: "/Steps/-", Value: "act"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops, err := DiffState(tt.before, tt.after)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ops)
+
+			patched, err := ApplyPatch(tt.before, ops)
+			require.NoError(t, err)
+			expected, err := normalizeJSONValue(tt.after)
+			require.NoError(t, err)
+			assert.Equal(t, expected, patched)
+		})
+	}
+
+	t.Run("UnserializableDocument", func(t *testing.T) {
+		_, err := DiffState(map[string]any{}, map[string]any{"ch": make(chan int)})
+		assert.Error(t, err)
+	})
+
+	t.Run("NewStateDeltaEventFromDiff", func(t *testing.T) {
+		event, err := NewStateDeltaEventFromDiff(
+			map[string]any{"progress": 10},
+			map[string]any{"progress": 20},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, EventTypeStateDelta, event.Type())
+		assert.NoError(t, event.Validate())
+		assert.Equal(t, []JSONPatchOperation{{Op: "replace", Path: "/progress", Value: float64(20)}}, event.Delta)
+
+		event, err = NewStateDeltaEventFromDiff(map[string]any{"a": 1}, map[string]any{"a": 1})
+		require.NoError(t, err)
+		assert.Empty(t, event.Delta)
+	})
+}
+
+// randomJSONValue builds a random nested document mixing every JSON type,
+// with integers and floats both represented
+func randomJSONValue(rng *rand.Rand, depth int) any {
+	kind := rng.Intn(8)
+	if depth <= 0 {
+		kind = rng.Intn(5)
+	}
+
+	switch kind {
+	case 0:
+		return nil
+	case 1:
+		return rng.Intn(2) == 0
+	case 2:
+		return rng.Intn(100)
+	case 3:
+		return rng.Float64() * 100
+	case 4:
+		return fmt.Sprintf("s%d", rng.Intn(10))
+	case 5, 6:
+		obj := make(map[string]any)
+		for i := rng.Intn(5); i > 0; i-- {
+			obj[randomKey(rng)] = randomJSONValue(rng, depth-1)
+		}
+		return obj
+	default:
+		arr := make([]any, rng.Intn(5))
+		for i := range arr {
+			arr[i] = randomJSONValue(rng, depth-1)
+		}
+		return arr
+	}
+}
+
+// randomKey picks from a small key space, including keys that need escaping,
+// so that mutated documents share members with the original
+func randomKey(rng *rand.Rand) string {
+	keys := []string{"a", "b", "c", "d", "a/b", "m~n", ""}
+	return keys[rng.Intn(len(keys))]
+}
+
+// mutateJSONValue returns a randomly edited copy of a generic JSON value:
+// members are added, removed and changed, and arrays grow and shrink
+func mutateJSONValue(rng *rand.Rand, value any, depth int) any {
+	if rng.Intn(6) == 0 {
+		return randomJSONValue(rng, depth)
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		mutated := make(map[string]any, len(v))
+		for key, item := range v {
+			switch rng.Intn(4) {
+			case 0:
+				// Delete the member
+			case 1:
+				mutated[key] = mutateJSONValue(rng, item, depth-1)
+			default:
+				mutated[key] = item
+			}
+		}
+		if rng.Intn(2) == 0 {
+			mutated[randomKey(rng)] = randomJSONValue(rng, depth-1)
+		}
+		return mutated
+
+	case []any:
+		mutated := make([]any, 0, len(v)+2)
+		for _, item := range v {
+			if rng.Intn(3) == 0 {
+				item = mutateJSONValue(rng, item, depth-1)
+			}
+			mutated = append(mutated, item)
+		}
+		if len(mutated) > 0 && rng.Intn(3) == 0 {
+			mutated = mutated[:rng.Intn(len(mutated))]
+		}
+		for i := rng.Intn(3); i > 0; i-- {
+			mutated = append(mutated, randomJSONValue(rng, depth-1))
+		}
+		return mutated
+
+	default:
+		return value
+	}
+}
+
+func TestDiffStateRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1606))
+
+	for i := 0; i < 500; i++ {
+		before := randomJSONValue(rng, 4)
+		after := mutateJSONValue(rng, before, 4)
+		if i%10 == 0 {
+			after = randomJSONValue(rng, 4)
+		}
+
+		ops, err := DiffState(before, after)
+		require.NoError(t, err)
+
+		// The patch must survive serialization, as it would on the wire
+		data, err := json.Marshal(ops)
+		require.NoError(t, err)
+		var decoded []JSONPatchOperation
+		require.NoError(t, json.Unmarshal(data, &decoded))
+
+		patched, err := ApplyPatch(before, decoded)
+		require.NoError(t, err, "case %d: %s", i, data)
+
+		expected, err := normalizeJSONValue(after)
+		require.NoError(t, err)
+		require.Equal(t, expected, patched, "case %d: %s", i, data)
+	}
+}
+
+// deepestObject returns the innermost object of a value built by nestedValue
+func deepestObject(value any) map[string]any {
+	obj := value.(map[string]any)
+	for {
+		child, ok := obj["child"].(map[string]any)
+		if !ok {
+			return obj
+		}
+		obj = child
+	}
+}
+
+func TestDiffState_DeepNesting(t *testing.T) {
+	// Recursive comparison needs well over 1 MiB of stack for these documents
+	// and would crash the test binary
+	const depth = 200_000
+	defer debug.SetMaxStack(debug.SetMaxStack(1 << 20))
+
+	before, after := nestedValue(depth), nestedValue(depth)
+	assert.True(t, jsonValuesEqual(before, after))
+	assert.Empty(t, diffValues("", before, after, nil))
+
+	deepestObject(after)["child"] = "changed"
+	assert.False(t, jsonValuesEqual(before, after))
+	ops := diffValues("", before, after, nil)
+	require.Len(t, ops, 1)
+	assert.Equal(t, "replace", ops[0].Op)
+	assert.Equal(t, "changed", ops[0].Value)
+	assert.Equal(t, depth, strings.Count(ops[0].Path, "/child"))
+}
@@ -1,8 +1,17 @@
 package events
 
 import (
+	"encoding"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/jsonpointer"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/jsonwalk"
 )
 
 // validJSONPatchOps contains the valid JSON Patch operations for efficient lookup
@@ -21,14 +30,145 @@ type StateSnapshotEvent struct {
 	Snapshot any `json:"snapshot"`
 }
 
-// NewStateSnapshotEvent creates a new state snapshot event
-func NewStateSnapshotEvent(snapshot any) *StateSnapshotEvent {
+// stateSnapshotOptions holds the options of NewStateSnapshotEvent
+type stateSnapshotOptions struct {
+	shared bool
+}
+
+// StateSnapshotOption defines options for creating state snapshot events
+type StateSnapshotOption func(*stateSnapshotOptions)
+
+// WithSharedSnapshot makes the event store the snapshot value by reference
+// instead of copying it. Use it only when the caller hands over ownership of
+// the value and will not modify it afterwards.
+func WithSharedSnapshot() StateSnapshotOption {
+	return func(o *stateSnapshotOptions) {
+		o.shared = true
+	}
+}
+
+// NewStateSnapshotEvent creates a new state snapshot event. The event owns
+// its snapshot: the value is deep-copied, so later changes to the caller's
+// maps, slices and pointed-to structs do not affect the event, for example
+// while it waits in a replay buffer. The copy keeps the Go type of the value
+// at every level. Unexported struct fields, which are not part of the JSON
+// form, are copied shallowly, and channels and functions are shared.
+func NewStateSnapshotEvent(snapshot any, options ...StateSnapshotOption) *StateSnapshotEvent {
+	var opts stateSnapshotOptions
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	if !opts.shared {
+		snapshot = copySnapshotValue(snapshot)
+	}
 	return &StateSnapshotEvent{
 		BaseEvent: NewBaseEvent(EventTypeStateSnapshot),
 		Snapshot:  snapshot,
 	}
 }
 
+// copySnapshotValue deep-copies a snapshot value without changing its type.
+// Generic objects and arrays are copied member by member without recursion;
+// other containers, such as structs and typed maps, are copied with
+// reflection.
+func copySnapshotValue(value any) any {
+	copied, _ := jsonwalk.Transform(value, func(_ []string, v any) (any, error) {
+		switch v.(type) {
+		case nil, map[string]any, []any:
+			return v, nil
+		}
+		typed := typedCopier{seen: make(map[typedCopyKey]reflect.Value)}
+		return typed.copy(reflect.ValueOf(v)).Interface(), jsonwalk.SkipChildren
+	})
+	return copied
+}
+
+// typedCopier deep-copies values of any type with reflection. Pointers and
+// maps reached more than once are copied once, so that cycles terminate and
+// shared values stay shared in the copy.
+type typedCopier struct {
+	seen map[typedCopyKey]reflect.Value
+}
+
+// typedCopyKey identifies a pointer or map already copied
+type typedCopyKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// copy returns a deep copy of v with the same type
+func (c *typedCopier) copy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		key := typedCopyKey{ptr: v.Pointer(), typ: v.Type()}
+		if copied, ok := c.seen[key]; ok {
+			return copied
+		}
+		copied := reflect.New(v.Type().Elem())
+		c.seen[key] = copied
+		copied.Elem().Set(c.copy(v.Elem()))
+		return copied
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		key := typedCopyKey{ptr: v.Pointer(), typ: v.Type()}
+		if copied, ok := c.seen[key]; ok {
+			return copied
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		c.seen[key] = copied
+		for iter := v.MapRange(); iter.Next(); {
+			copied.SetMapIndex(iter.Key(), c.copy(iter.Value()))
+		}
+		return copied
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(copied, v)
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(c.copy(v.Index(i)))
+		}
+		return copied
+
+	case reflect.Array:
+		copied := reflect.New(v.Type()).Elem()
+		reflect.Copy(copied, v)
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(c.copy(v.Index(i)))
+		}
+		return copied
+
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if field := copied.Field(i); field.CanSet() {
+				field.Set(c.copy(v.Field(i)))
+			}
+		}
+		return copied
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		// Generic JSON held in typed containers is copied without recursion
+		return reflect.ValueOf(copySnapshotValue(v.Elem().Interface()))
+	}
+
+	// Scalars are values, and channels and functions cannot be copied
+	return v
+}
+
 // Validate validates the state snapshot event
 func (e *StateSnapshotEvent) Validate() error {
 	if err := e.BaseEvent.Validate(); err != nil {
@@ -36,17 +176,249 @@ func (e *StateSnapshotEvent) Validate() error {
 	}
 
 	if e.Snapshot == nil {
-		return fmt.Errorf("StateSnapshotEvent validation failed: snapshot field is required")
+		return newValidationError(EventTypeStateSnapshot, "snapshot", "snapshot field is required")
 	}
 
 	return nil
 }
 
+// ValidateSerializable validates the event and checks that the snapshot can
+// be marshaled to JSON, so that a channel or a function in the state is
+// reported where the event is built rather than when it is sent. It marshals
+// the whole snapshot, so it is not part of Validate.
+func (e *StateSnapshotEvent) ValidateSerializable() error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := json.Marshal(e.Snapshot); err != nil && !isJSONDepthError(err) {
+		if path, ok := unserializablePath(reflect.ValueOf(e.Snapshot), "", 0); ok {
+			return wrapValidationError(EventTypeStateSnapshot, "snapshot", err,
+				"snapshot is not JSON-serializable: value at %q: %v", path, err)
+		}
+		return wrapValidationError(EventTypeStateSnapshot, "snapshot", err,
+			"snapshot is not JSON-serializable: %v", err)
+	}
+
+	return nil
+}
+
+// isJSONDepthError reports whether err is the error of encoding/json for
+// values nested too deeply. Such snapshots are left to the depth limits of
+// the EventSequenceValidator, which reports them more precisely.
+func isJSONDepthError(err error) bool {
+	var syntaxErr *json.SyntaxError
+	return errors.As(err, &syntaxErr) && strings.Contains(syntaxErr.Error(), "exceeded max depth")
+}
+
+// maxUnserializableDepth bounds the search of unserializablePath, which
+// would not end on cyclic values
+const maxUnserializableDepth = 64
+
+// unserializablePath returns the JSON Pointer of the first value within v
+// that encoding/json cannot marshal, such as a channel, a function or a
+// NaN, following the struct field names of JSON. Values implementing a
+// marshaler are not searched.
+func unserializablePath(v reflect.Value, path string, depth int) (string, bool) {
+	if !v.IsValid() || depth > maxUnserializableDepth {
+		return "", false
+	}
+	if v.Type().Implements(jsonMarshalerType) || v.Type().Implements(textMarshalerType) {
+		return "", false
+	}
+
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		return path, true
+
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		return path, math.IsNaN(f) || math.IsInf(f, 0)
+
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return "", false
+		}
+		return unserializablePath(v.Elem(), path, depth+1)
+
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			if found, ok := unserializablePath(iter.Value(), appendJSONPointer(path, key), depth+1); ok {
+				return found, true
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if found, ok := unserializablePath(v.Index(i), appendJSONPointer(path, strconv.Itoa(i)), depth+1); ok {
+				return found, true
+			}
+		}
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			embedded := field.Anonymous && field.Type.Kind() == reflect.Struct
+			if !field.IsExported() && !embedded {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			// Untagged embedded structs are flattened into their parent
+			fieldPath := path
+			if name != "" || !embedded {
+				if name == "" {
+					name = field.Name
+				}
+				fieldPath = appendJSONPointer(path, name)
+			}
+			if found, ok := unserializablePath(v.Field(i), fieldPath, depth+1); ok {
+				return found, true
+			}
+		}
+	}
+	return "", false
+}
+
+// jsonMarshalerType and textMarshalerType are the interfaces of the values
+// marshaling themselves
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
 // ToJSON serializes the event to JSON
 func (e *StateSnapshotEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// String returns a concise human-readable summary of the event
+func (e *StateSnapshotEvent) String() string {
+	switch snapshot := e.Snapshot.(type) {
+	case map[string]any:
+		return formatEvent(EventTypeStateSnapshot, summaryField("keys", fmt.Sprint(len(snapshot))))
+	case []any:
+		return formatEvent(EventTypeStateSnapshot, summaryField("items", fmt.Sprint(len(snapshot))))
+	default:
+		return formatEvent(EventTypeStateSnapshot)
+	}
+}
+
+// Clone returns a deep copy of the event
+func (e *StateSnapshotEvent) Clone() *StateSnapshotEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	clone.Snapshot = copySnapshotValue(e.Snapshot)
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *StateSnapshotEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *StateSnapshotEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeStateSnapshot, e)
+}
+
+// PathGet returns the value at the RFC 6901 JSON Pointer path in the
+// snapshot, e.g. "/user/name". The empty path refers to the whole snapshot.
+// Maps and slices in the returned value are shared with the snapshot.
+func (e *StateSnapshotEvent) PathGet(path string) (interface{}, error) {
+	tokens, err := jsonpointer.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := e.genericSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := getValue(doc, tokens)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", path, err)
+	}
+	return value, nil
+}
+
+// PathSet sets the value at the RFC 6901 JSON Pointer path in the snapshot,
+// replacing an existing value or adding a new one. The parent of the path
+// must exist; "-" appends to an array. A nil snapshot starts as an empty
+// object. The snapshot is modified in place; snapshots holding other types
+// than generic JSON values, such as structs, are converted to them first.
+func (e *StateSnapshotEvent) PathSet(path string, value interface{}) error {
+	tokens, err := jsonpointer.Parse(path)
+	if err != nil {
+		return err
+	}
+
+	normalized, err := normalizeJSONValue(value)
+	if err != nil {
+		return err
+	}
+
+	if e.Snapshot == nil && len(tokens) > 0 {
+		e.Snapshot = map[string]any{}
+	}
+	doc, err := e.genericSnapshot()
+	if err != nil {
+		return err
+	}
+
+	if _, getErr := getValue(doc, tokens); getErr == nil {
+		doc, err = replaceValue(doc, tokens, normalized)
+	} else {
+		doc, err = addValue(doc, tokens, normalized)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set %s: %w", path, err)
+	}
+
+	e.Snapshot = doc
+	return nil
+}
+
+// PathDelete removes the value at the RFC 6901 JSON Pointer path from the
+// snapshot. The snapshot is modified in place and converted like in PathSet.
+func (e *StateSnapshotEvent) PathDelete(path string) error {
+	tokens, err := jsonpointer.Parse(path)
+	if err != nil {
+		return err
+	}
+
+	doc, err := e.genericSnapshot()
+	if err != nil {
+		return err
+	}
+
+	doc, _, err = removeValue(doc, tokens)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %w", path, err)
+	}
+
+	e.Snapshot = doc
+	return nil
+}
+
+// genericSnapshot returns the snapshot as generic JSON values, converting a
+// copy of it if it holds other types such as structs
+func (e *StateSnapshotEvent) genericSnapshot() (any, error) {
+	switch e.Snapshot.(type) {
+	case map[string]any, []any, nil, string, float64, bool:
+		return e.Snapshot, nil
+	}
+	return normalizeJSONValue(e.Snapshot)
+}
+
 // JSONPatchOperation represents a JSON Patch operation (RFC 6902)
 type JSONPatchOperation struct {
 	Op    string `json:"op"`              // "add", "remove", "replace", "move", "copy", "test"
@@ -59,6 +431,12 @@ type JSONPatchOperation struct {
 type StateDeltaEvent struct {
 	*BaseEvent
 	Delta []JSONPatchOperation `json:"delta"`
+
+	// BaseVersion is the version of the state the delta was computed
+	// against, and NewVersion the version it produces. Both are optional and
+	// used by VersionedStateStore to detect conflicting edits.
+	BaseVersion *uint64 `json:"baseVersion,omitempty"`
+	NewVersion  *uint64 `json:"newVersion,omitempty"`
 }
 
 // NewStateDeltaEvent creates a new state delta event
@@ -69,46 +447,99 @@ func NewStateDeltaEvent(delta []JSONPatchOperation) *StateDeltaEvent {
 	}
 }
 
-// Validate validates the state delta event
+// NewStateDeltaEventWithOptions creates a new state delta event with options
+func NewStateDeltaEventWithOptions(delta []JSONPatchOperation, options ...StateDeltaOption) *StateDeltaEvent {
+	event := NewStateDeltaEvent(delta)
+
+	for _, opt := range options {
+		opt(event)
+	}
+
+	return event
+}
+
+// StateDeltaOption defines options for creating state delta events
+type StateDeltaOption func(*StateDeltaEvent)
+
+// WithBaseVersion sets the version of the state the delta was computed against
+func WithBaseVersion(version uint64) StateDeltaOption {
+	return func(e *StateDeltaEvent) {
+		e.BaseVersion = &version
+	}
+}
+
+// WithNewVersion sets the version of the state after the delta is applied
+func WithNewVersion(version uint64) StateDeltaOption {
+	return func(e *StateDeltaEvent) {
+		e.NewVersion = &version
+	}
+}
+
+// Validate validates the state delta event, returning the first error of
+// ValidateCollect
 func (e *StateDeltaEvent) Validate() error {
+	if errs := e.ValidateCollect(); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// ValidateCollect validates the event, reporting every invalid operation
+// instead of stopping at the first, as Validate does. It returns nil if the
+// event is valid.
+func (e *StateDeltaEvent) ValidateCollect() []error {
+	var errs []error
 	if err := e.BaseEvent.Validate(); err != nil {
-		return err
+		errs = append(errs, err)
 	}
 
 	if len(e.Delta) == 0 {
-		return fmt.Errorf("StateDeltaEvent validation failed: delta field must contain at least one operation")
+		errs = append(errs, newValidationError(EventTypeStateDelta, "delta", "delta field must contain at least one operation"))
 	}
 
-	// Validate each JSON patch operation
 	for i, op := range e.Delta {
 		if err := validateJSONPatchOperation(op); err != nil {
-			return fmt.Errorf("StateDeltaEvent validation failed: invalid operation at index %d: %w", i, err)
+			errs = append(errs, wrapValidationError(EventTypeStateDelta, fmt.Sprintf("delta.%d", i), err, "invalid operation at index %d", i))
 		}
 	}
 
-	return nil
+	return errs
+}
+
+// ApplyTo applies the delta to a state document and returns the patched
+// document, leaving the input unchanged. See ApplyPatch.
+func (e *StateDeltaEvent) ApplyTo(doc any) (any, error) {
+	return ApplyPatch(doc, e.Delta)
 }
 
 // validateJSONPatchOperation validates a single JSON patch operation
 func validateJSONPatchOperation(op JSONPatchOperation) error {
 	// Validate operation type using map lookup for better performance
 	if !validJSONPatchOps[op.Op] {
-		return fmt.Errorf("op field must be one of: add, remove, replace, move, copy, test, got: %s", op.Op)
+		return newValidationError(EventTypeStateDelta, "op", "op field must be one of: add, remove, replace, move, copy, test, got: %s", op.Op)
 	}
 
 	// Validate path
 	if op.Path == "" {
-		return fmt.Errorf("path field is required")
+		return newValidationError(EventTypeStateDelta, "path", "path field is required")
+	}
+	if _, err := jsonpointer.Parse(op.Path); err != nil {
+		return wrapValidationError(EventTypeStateDelta, "path", err, "path field is invalid")
 	}
 
 	// Validate value for operations that require it
 	if (op.Op == "add" || op.Op == "replace" || op.Op == "test") && op.Value == nil {
-		return fmt.Errorf("value field is required for %s operation", op.Op)
+		return newValidationError(EventTypeStateDelta, "value", "value field is required for %s operation", op.Op)
 	}
 
 	// Validate from for operations that require it
 	if (op.Op == "move" || op.Op == "copy") && op.From == "" {
-		return fmt.Errorf("from field is required for %s operation", op.Op)
+		return newValidationError(EventTypeStateDelta, "from", "from field is required for %s operation", op.Op)
+	}
+	if op.From != "" {
+		if _, err := jsonpointer.Parse(op.From); err != nil {
+			return wrapValidationError(EventTypeStateDelta, "from", err, "from field is invalid")
+		}
 	}
 
 	return nil
@@ -119,6 +550,52 @@ func (e *StateDeltaEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// String returns a concise human-readable summary of the event
+func (e *StateDeltaEvent) String() string {
+	return formatEvent(EventTypeStateDelta, summaryField("ops", fmt.Sprint(len(e.Delta))))
+}
+
+// Clone returns a deep copy of the event
+func (e *StateDeltaEvent) Clone() *StateDeltaEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	clone.Delta = cloneJSONPatchOperations(e.Delta)
+	clone.BaseVersion = cloneUint64(e.BaseVersion)
+	clone.NewVersion = cloneUint64(e.NewVersion)
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *StateDeltaEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *StateDeltaEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeStateDelta, e)
+}
+
+// Message roles defined by the AG-UI protocol
+const (
+	RoleDeveloper = "developer"
+	RoleSystem    = "system"
+	RoleAssistant = "assistant"
+	RoleUser      = "user"
+	RoleTool      = "tool"
+)
+
+// validMessageRoles contains the recognized message roles for efficient lookup
+var validMessageRoles = map[string]bool{
+	RoleDeveloper: true,
+	RoleSystem:    true,
+	RoleAssistant: true,
+	RoleUser:      true,
+	RoleTool:      true,
+}
+
 // Message represents a message in the conversation
 type Message struct {
 	ID         string     `json:"id"`
@@ -164,28 +641,70 @@ func (e *MessagesSnapshotEvent) Validate() error {
 
 	// Validate each message
 	for i, msg := range e.Messages {
-		if err := validateMessage(msg); err != nil {
-			return fmt.Errorf("invalid message at index %d: %w", i, err)
+		if err := msg.validate(EventTypeMessagesSnapshot); err != nil {
+			return wrapValidationError(EventTypeMessagesSnapshot, fmt.Sprintf("messages.%d", i), err, "invalid message at index %d", i)
 		}
 	}
 
 	return nil
 }
 
-// validateMessage validates a single message
-func validateMessage(msg Message) error {
-	if msg.ID == "" {
-		return fmt.Errorf("message id field is required")
+// ValidateCollect validates the event like Validate, but reports every
+// invalid message instead of stopping at the first. It returns nil if the
+// event is valid.
+func (e *MessagesSnapshotEvent) ValidateCollect() []error {
+	var errs []error
+	if err := e.BaseEvent.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	for i, msg := range e.Messages {
+		if err := msg.validate(EventTypeMessagesSnapshot); err != nil {
+			errs = append(errs, wrapValidationError(EventTypeMessagesSnapshot, fmt.Sprintf("messages.%d", i), err, "invalid message at index %d", i))
+		}
+	}
+
+	return errs
+}
+
+// Validate validates the message, enforcing the fields required by its role
+func (m Message) Validate() error {
+	return m.validate("")
+}
+
+// validate validates the message, reporting errors for the type of the event
+// carrying it
+func (m Message) validate(t EventType) error {
+	if m.ID == "" {
+		return newValidationError(t, "id", "message id field is required")
+	}
+
+	if m.Role == "" {
+		return newValidationError(t, "role", "message role field is required")
 	}
 
-	if msg.Role == "" {
-		return fmt.Errorf("message role field is required")
+	if !validMessageRoles[m.Role] {
+		return newValidationError(t, "role", "message role must be one of: developer, system, assistant, user, tool, got: %s", m.Role)
+	}
+
+	switch m.Role {
+	case RoleDeveloper, RoleSystem, RoleUser:
+		if m.Content == nil {
+			return newValidationError(t, "content", "message content field is required for %s messages", m.Role)
+		}
+	case RoleTool:
+		if m.Content == nil {
+			return newValidationError(t, "content", "message content field is required for tool messages")
+		}
+		if m.ToolCallID == nil || *m.ToolCallID == "" {
+			return newValidationError(t, "toolCallId", "message toolCallId field is required for tool messages")
+		}
 	}
 
 	// Validate tool calls if present
-	for i, toolCall := range msg.ToolCalls {
-		if err := validateToolCall(toolCall); err != nil {
-			return fmt.Errorf("invalid tool call at index %d: %w", i, err)
+	for i, toolCall := range m.ToolCalls {
+		if err := validateToolCall(t, toolCall); err != nil {
+			return wrapValidationError(t, fmt.Sprintf("toolCalls.%d", i), err, "invalid tool call at index %d", i)
 		}
 	}
 
@@ -193,17 +712,17 @@ func validateMessage(msg Message) error {
 }
 
 // validateToolCall validates a single tool call
-func validateToolCall(toolCall ToolCall) error {
+func validateToolCall(t EventType, toolCall ToolCall) error {
 	if toolCall.ID == "" {
-		return fmt.Errorf("tool call id field is required")
+		return newValidationError(t, "id", "tool call id field is required")
 	}
 
 	if toolCall.Type == "" {
-		return fmt.Errorf("tool call type field is required")
+		return newValidationError(t, "type", "tool call type field is required")
 	}
 
 	if toolCall.Function.Name == "" {
-		return fmt.Errorf("function name field is required")
+		return newValidationError(t, "function.name", "function name field is required")
 	}
 
 	return nil
@@ -213,3 +732,54 @@ func validateToolCall(toolCall ToolCall) error {
 func (e *MessagesSnapshotEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
+
+// String returns a concise human-readable summary of the event
+func (e *MessagesSnapshotEvent) String() string {
+	return formatEvent(EventTypeMessagesSnapshot, summaryField("messages", fmt.Sprint(len(e.Messages))))
+}
+
+// Clone returns a deep copy of the event
+func (e *MessagesSnapshotEvent) Clone() *MessagesSnapshotEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	clone.Messages = cloneMessages(e.Messages)
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *MessagesSnapshotEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *MessagesSnapshotEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeMessagesSnapshot, e)
+}
+
+// FilterByRole returns a copy of the event that keeps only the messages with
+// one of the given roles
+func (e *MessagesSnapshotEvent) FilterByRole(roles ...string) *MessagesSnapshotEvent {
+	filtered := e.Clone()
+	filtered.Messages = FilterMessages(filtered.Messages, roles...)
+	return filtered
+}
+
+// FilterMessages returns the messages with one of the given roles, in their
+// original order. The input slice is not modified.
+func FilterMessages(msgs []Message, roles ...string) []Message {
+	keep := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		keep[role] = true
+	}
+
+	filtered := make([]Message, 0, len(msgs))
+	for _, msg := range msgs {
+		if keep[msg.Role] {
+			filtered = append(filtered, msg)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,100 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrVersionNotRetained is returned when a state version is requested that
+// the store's history does not hold, because it was evicted, never existed
+// or history is disabled
+var ErrVersionNotRetained = errors.New("state version not retained")
+
+// stateVersion is a state retained by the history
+type stateVersion struct {
+	version uint64
+	state   any
+}
+
+// WithStateHistory makes the store retain its last maxVersions versions,
+// including the current one, so that they can be read with At and restored
+// with RevertTo. Once the limit is reached, the oldest version is evicted
+// each time a new one is produced. A maxVersions of zero or less disables
+// history, which is the default.
+//
+// Retained versions share the parts of the state tree that later updates did
+// not change, so each one costs about as much memory as the changes that
+// produced it. A snapshot replaces the whole tree, so each version created by
+// a snapshot costs the full size of the state; choose maxVersions with the
+// snapshot frequency in mind.
+func WithStateHistory(maxVersions int) StateStoreOption {
+	return func(s *StateStore) {
+		s.historyLimit = maxVersions
+	}
+}
+
+// At returns a deep copy of the state at the given version, which must be
+// the current version or one retained by the history. See WithStateHistory.
+func (s *StateStore) At(version uint64) (any, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, ok := s.retained(version)
+	if !ok {
+		return nil, fmt.Errorf("%w: version %d", ErrVersionNotRetained, version)
+	}
+	return deepCopyJSONValue(state), nil
+}
+
+// RevertTo restores the state of an earlier version retained by the history
+// and returns the delta that performs the change, for emitting so that
+// consumers follow. Reverting does not rewrite history: it produces a new
+// version whose state equals the old one, and notifies subscribers like any
+// other update. When the state already equals the old one there is nothing
+// to emit: RevertTo returns a nil delta and the version is unchanged.
+func (s *StateStore) RevertTo(version uint64) (*StateDeltaEvent, error) {
+	ops, err := s.update(0, func(state any) ([]JSONPatchOperation, error) {
+		target, ok := s.retained(version)
+		if !ok {
+			return nil, fmt.Errorf("failed to revert state: %w: version %d", ErrVersionNotRetained, version)
+		}
+		return diffValues("", state, target, []JSONPatchOperation{}), nil
+	})
+	if err != nil || len(ops) == 0 {
+		return nil, err
+	}
+	return NewStateDeltaEvent(ops), nil
+}
+
+// retained returns the state at the given version. The caller must hold the
+// lock.
+func (s *StateStore) retained(version uint64) (any, bool) {
+	if !s.hasSnapshot {
+		return nil, false
+	}
+	if version == s.version {
+		return s.state, true
+	}
+	for _, entry := range s.history {
+		if entry.version == version {
+			return entry.state, true
+		}
+	}
+	return nil, false
+}
+
+// recordHistory retains the current version, evicting the oldest one if the
+// history is full. The caller must hold the write lock.
+func (s *StateStore) recordHistory() {
+	if s.historyLimit <= 0 || !s.hasSnapshot {
+		return
+	}
+
+	if len(s.history) >= s.historyLimit {
+		evict := len(s.history) - s.historyLimit + 1
+		n := copy(s.history, s.history[evict:])
+		clear(s.history[n:])
+		s.history = s.history[:n]
+	}
+	s.history = append(s.history, stateVersion{version: s.version, state: s.state})
+}
@@ -0,0 +1,152 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// historyDelta returns the i-th delta of a test stream, which sets a counter,
+// appends to a log and adds a key of its own
+func historyDelta(i int) *StateDeltaEvent {
+	return NewStateDeltaEvent(NewPatchBuilder().
+		Replace("/counter", i).
+		Append("/log", fmt.Sprintf("step %d", i)).
+		Add(fmt.Sprintf("/keys/k%d", i), i).
+		Build())
+}
+
+func TestStateStore_History(t *testing.T) {
+	snapshot := map[string]any{"counter": 0, "log": []any{}, "keys": map[string]any{}}
+
+	store := NewStateStore(WithStateHistory(25))
+	consumer := NewStateStore()
+	store.ApplySnapshot(NewStateSnapshotEvent(snapshot))
+	consumer.ApplySnapshot(NewStateSnapshotEvent(snapshot))
+	for i := 1; i <= 20; i++ {
+		delta := historyDelta(i)
+		require.NoError(t, store.ApplyDelta(delta))
+		require.NoError(t, consumer.ApplyDelta(delta))
+	}
+	require.Equal(t, uint64(21), store.Version())
+
+	// Version 1 is the snapshot, so version 5 follows the fourth delta
+	old, err := store.At(5)
+	require.NoError(t, err)
+	state := old.(map[string]any)
+	assert.Equal(t, float64(4), state["counter"])
+	assert.Len(t, state["log"], 4)
+	assert.Len(t, state["keys"], 4)
+
+	delta, err := store.RevertTo(5)
+	require.NoError(t, err)
+	require.NoError(t, delta.Validate())
+
+	// The local state is back at version 5, as a new version
+	assert.Equal(t, old, store.Current())
+	assert.Equal(t, uint64(22), store.Version())
+
+	// The emitted delta brings consumers to the same state
+	require.NoError(t, consumer.ApplyDelta(delta))
+	assert.Equal(t, old, consumer.Current())
+
+	// Reverting is itself recorded, and later versions stay reachable
+	latest, err := store.At(21)
+	require.NoError(t, err)
+	assert.Equal(t, float64(20), latest.(map[string]any)["counter"])
+	reverted, err := store.At(22)
+	require.NoError(t, err)
+	assert.Equal(t, old, reverted)
+
+	// Reverting to an equal state has nothing to emit
+	delta, err = store.RevertTo(22)
+	require.NoError(t, err)
+	assert.Nil(t, delta)
+	assert.Equal(t, uint64(22), store.Version())
+}
+
+func TestStateStore_HistoryIsolation(t *testing.T) {
+	store := NewStateStore(WithEmptyInitialState(), WithStateHistory(5))
+	require.NoError(t, store.ApplyDelta(NewStateDeltaEvent(NewPatchBuilder().Add("/items", []any{"a"}).Build())))
+
+	old, err := store.At(1)
+	require.NoError(t, err)
+	old.(map[string]any)["items"].([]any)[0] = "modified"
+
+	again, err := store.At(1)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"items": []any{"a"}}, again)
+
+	initial, err := store.At(0)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{}, initial)
+}
+
+func TestStateStore_HistoryEviction(t *testing.T) {
+	store := NewStateStore(WithEmptyInitialState(), WithStateHistory(3))
+	for i := 1; i <= 10; i++ {
+		require.NoError(t, store.ApplyDelta(historyDeltaFrom(i)))
+	}
+
+	for _, version := range []uint64{8, 9, 10} {
+		state, err := store.At(version)
+		require.NoError(t, err, "version %d", version)
+		assert.Equal(t, float64(version), state.(map[string]any)["counter"])
+	}
+
+	for _, version := range []uint64{0, 7, 11} {
+		_, err := store.At(version)
+		assert.ErrorIs(t, err, ErrVersionNotRetained, "version %d", version)
+	}
+
+	_, err := store.RevertTo(7)
+	assert.ErrorIs(t, err, ErrVersionNotRetained)
+	assert.Equal(t, uint64(10), store.Version())
+}
+
+func TestStateStore_HistoryDisabled(t *testing.T) {
+	store := NewStateStore(WithEmptyInitialState())
+	require.NoError(t, store.ApplyDelta(historyDeltaFrom(1)))
+
+	// The current version is always available
+	state, err := store.At(1)
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), state.(map[string]any)["counter"])
+
+	_, err = store.At(0)
+	assert.ErrorIs(t, err, ErrVersionNotRetained)
+
+	_, err = NewStateStore().At(0)
+	assert.ErrorIs(t, err, ErrVersionNotRetained, "no snapshot yet")
+}
+
+func TestStateStore_HistoryAfterRestore(t *testing.T) {
+	source := NewStateStore(WithEmptyInitialState())
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, source.ApplyDelta(historyDeltaFrom(i)))
+	}
+	data, err := source.Checkpoint()
+	require.NoError(t, err)
+
+	restored, err := RestoreStateStore(data, WithEmptyInitialState(), WithStateHistory(4))
+	require.NoError(t, err)
+	require.NoError(t, restored.ApplyDelta(historyDeltaFrom(4)))
+
+	_, err = restored.At(0)
+	assert.ErrorIs(t, err, ErrVersionNotRetained, "the pre-restore initial state is not history")
+
+	delta, err := restored.RevertTo(3)
+	require.NoError(t, err)
+	raw, err := json.Marshal(delta.Delta)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"op":"replace","path":"/counter","value":3}]`, string(raw))
+}
+
+// historyDeltaFrom returns a delta that sets the counter of an empty initial
+// state
+func historyDeltaFrom(i int) *StateDeltaEvent {
+	return NewStateDeltaEvent(NewPatchBuilder().Add("/counter", i).Build())
+}
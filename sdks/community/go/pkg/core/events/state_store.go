@@ -0,0 +1,414 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/jsonpointer"
+)
+
+// StateStore maintains the live state of a run by accumulating state snapshot
+// and state delta events as they arrive. It is safe for concurrent use.
+//
+// The state is kept as an immutable tree: deltas copy only the objects and
+// arrays along the paths they modify and share the rest with the previous
+// state, so a delta costs the same however large the state is, and readers
+// can share the tree without copying it.
+type StateStore struct {
+	mu          sync.RWMutex
+	state       any
+	hasSnapshot bool
+	version     uint64
+
+	// sequence is the decode sequence number of the last event applied
+	sequence uint64
+
+	// preserveNumbers keeps numbers as json.Number, see WithPreserveNumbers
+	preserveNumbers bool
+
+	// history holds the retained versions, oldest first, when enabled with
+	// WithStateHistory
+	history      []stateVersion
+	historyLimit int
+
+	// raw caches the serialized state of one version
+	raw atomic.Pointer[rawState]
+
+	subscriptions []*stateSubscription
+	nextID        uint64
+	panicHandler  func(pointer string, recovered any)
+
+	// notifyMu serializes callback delivery so that subscribers observe
+	// changes in the order they were applied
+	notifyMu sync.Mutex
+}
+
+// stateSubscription is a callback watching the value at a JSON Pointer
+type stateSubscription struct {
+	id      uint64
+	pointer string
+	path    []string
+	fn      func(old, new any)
+	active  atomic.Bool
+}
+
+// rawState is the serialized state at a version
+type rawState struct {
+	version uint64
+	data    json.RawMessage
+}
+
+// watchedValue is the value at a subscribed path, or its absence
+type watchedValue struct {
+	value  any
+	exists bool
+}
+
+// stateNotification is a pending subscription callback
+type stateNotification struct {
+	subscription *stateSubscription
+	old, new     any
+}
+
+// StateStoreOption defines options for creating state stores
+type StateStoreOption func(*StateStore)
+
+// WithEmptyInitialState starts the store from an empty object, so that deltas
+// can be applied before the first snapshot arrives
+func WithEmptyInitialState() StateStoreOption {
+	return func(s *StateStore) {
+		s.state = map[string]any{}
+		s.hasSnapshot = true
+	}
+}
+
+// WithPreserveNumbers keeps the numbers of the state as json.Number instead of
+// float64, so integers beyond float64 precision, such as 64-bit IDs and
+// counters, survive snapshots, deltas and merge patches exactly. Use
+// CoerceNumber to read them as Go numeric types. Deltas decoded from JSON
+// keep their precision only if the decoder was created with
+// WithDecodedNumbers.
+func WithPreserveNumbers() StateStoreOption {
+	return func(s *StateStore) {
+		s.preserveNumbers = true
+	}
+}
+
+// WithSubscriptionPanicHandler sets a function that is called with the
+// recovered value when a subscription callback panics. Panics are recovered
+// either way, so one failing subscriber does not affect the store or the
+// other subscribers.
+func WithSubscriptionPanicHandler(fn func(pointer string, recovered any)) StateStoreOption {
+	return func(s *StateStore) {
+		s.panicHandler = fn
+	}
+}
+
+// NewStateStore creates a new, empty state store. By default, deltas are
+// rejected until a snapshot has been applied.
+func NewStateStore(options ...StateStoreOption) *StateStore {
+	s := &StateStore{}
+
+	for _, opt := range options {
+		opt(s)
+	}
+	s.recordHistory()
+
+	return s
+}
+
+// Apply applies a state snapshot, state delta or merge patch event to the
+// store. Other event types are ignored and do not change the version.
+func (s *StateStore) Apply(e Event) error {
+	switch event := e.(type) {
+	case *StateSnapshotEvent:
+		s.ApplySnapshot(event)
+		return nil
+	case *StateDeltaEvent:
+		return s.ApplyDelta(event)
+	default:
+		if patch, ok := MergePatchFromEvent(e); ok {
+			return s.applyMergePatch(patch, e.GetBaseEvent().Sequence())
+		}
+		return nil
+	}
+}
+
+// ApplySnapshot replaces the accumulated state with the snapshot, discarding
+// any deltas applied so far. The snapshot is copied, so later changes to the
+// event do not affect the store. Snapshots that cannot be represented as
+// JSON are stored as-is.
+func (s *StateStore) ApplySnapshot(e *StateSnapshotEvent) {
+	state, err := normalizeJSON(e.Snapshot, s.preserveNumbers)
+	if err != nil {
+		state = e.Snapshot
+	}
+
+	s.mu.Lock()
+	before := s.watchedValues()
+	s.state = state
+	s.hasSnapshot = true
+	s.version++
+	s.recordHistory()
+	s.recordSequence(e.Sequence())
+	s.unlockAndNotify(before)
+}
+
+// ApplyDelta applies the delta's JSON Patch operations to the accumulated
+// state. The delta is applied atomically: if any operation fails, the state
+// is left unchanged and the error identifies the failing operation. It also
+// fails if no snapshot has been applied yet, unless the store was created
+// with WithEmptyInitialState.
+func (s *StateStore) ApplyDelta(e *StateDeltaEvent) error {
+	s.mu.Lock()
+	if !s.hasSnapshot {
+		s.mu.Unlock()
+		return fmt.Errorf("failed to apply state delta: no state snapshot received")
+	}
+
+	// Patching never modifies the current tree, so a failing operation
+	// leaves the state untouched
+	before := s.watchedValues()
+	state, err := applyPatchNumbers(s.state, e.Delta, s.preserveNumbers)
+	if err != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("failed to apply state delta: %w", err)
+	}
+	s.state = state
+	s.version++
+	s.recordHistory()
+	s.recordSequence(e.Sequence())
+	s.unlockAndNotify(before)
+	return nil
+}
+
+// ApplyMergePatch applies an RFC 7386 JSON Merge Patch to the accumulated
+// state. Like a delta, it fails if no snapshot has been applied yet, unless
+// the store was created with WithEmptyInitialState, and it only advances the
+// version if the state changes.
+func (s *StateStore) ApplyMergePatch(patch any) error {
+	return s.applyMergePatch(patch, 0)
+}
+
+// applyMergePatch applies a merge patch received in the event with the given
+// sequence number
+func (s *StateStore) applyMergePatch(patch any, sequence uint64) error {
+	normalized, err := normalizeJSON(patch, s.preserveNumbers)
+	if err != nil {
+		return fmt.Errorf("failed to apply merge patch: %w", err)
+	}
+
+	_, err = s.update(sequence, func(state any) ([]JSONPatchOperation, error) {
+		return diffValues("", state, mergePatch(state, normalized), []JSONPatchOperation{}), nil
+	})
+	return err
+}
+
+// update computes a patch from the current state and applies it, holding the
+// lock throughout so that no other event is applied in between. The state
+// passed to compute is shared with the store and must not be modified. An
+// empty patch leaves the store and its version unchanged. A non-zero sequence
+// is recorded as that of the last applied event.
+func (s *StateStore) update(sequence uint64, compute func(state any) ([]JSONPatchOperation, error)) ([]JSONPatchOperation, error) {
+	s.mu.Lock()
+	if !s.hasSnapshot {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("failed to update state: no state snapshot received")
+	}
+
+	ops, err := compute(s.state)
+	if err != nil || len(ops) == 0 {
+		s.mu.Unlock()
+		return nil, err
+	}
+
+	before := s.watchedValues()
+	state, err := applyPatchNumbers(s.state, ops, s.preserveNumbers)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("failed to update state: %w", err)
+	}
+	s.state = state
+	s.version++
+	s.recordHistory()
+	s.recordSequence(sequence)
+	s.unlockAndNotify(before)
+	return ops, nil
+}
+
+// Current returns a deep copy of the accumulated state, which the caller is
+// free to modify
+func (s *StateStore) Current() any {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return deepCopyJSONValue(s.state)
+}
+
+// CurrentShared returns the accumulated state without copying it, for
+// readers such as renderers that inspect the state on every update. The
+// store never modifies a state tree once it holds it, so the value stays
+// valid and consistent after later updates, but it is shared with the store
+// and other readers and must not be modified; use Current for a copy that
+// may be.
+func (s *StateStore) CurrentShared() any {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state
+}
+
+// CurrentRaw returns the accumulated state serialized as JSON, for consumers
+// that only re-serialize it. It is cheaper than Current: the encoding is
+// computed once per version and shared between callers, so it must not be
+// modified. It returns nil if the state cannot be represented as JSON.
+func (s *StateStore) CurrentRaw() json.RawMessage {
+	s.mu.RLock()
+	state, version := s.state, s.version
+	s.mu.RUnlock()
+
+	if cached := s.raw.Load(); cached != nil && cached.version == version {
+		return cached.data
+	}
+
+	// The tree is never modified once stored, so it can be encoded without
+	// holding the lock
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil
+	}
+	s.raw.Store(&rawState{version: version, data: data})
+	return data
+}
+
+// Version returns the number of snapshot and delta events applied so far
+func (s *StateStore) Version() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.version
+}
+
+// LastSequence returns the decode sequence number of the last event applied
+// to the store, or zero if none of the applied events was decoded. See
+// BaseEvent.Sequence.
+func (s *StateStore) LastSequence() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sequence
+}
+
+// recordSequence remembers the sequence number of an applied event. Events
+// built in code have no sequence and leave the last one in place. The caller
+// must hold the write lock.
+func (s *StateStore) recordSequence(sequence uint64) {
+	if sequence != 0 {
+		s.sequence = sequence
+	}
+}
+
+// Subscribe registers a callback that fires whenever the value at the JSON
+// Pointer changes, whether the path itself or one of its parents was
+// modified. The callback receives copies of the old and new values, with nil
+// standing for a missing value, and is not called for updates that leave the
+// value unchanged.
+//
+// Callbacks run after the store has been fully updated, outside its lock, so
+// they may read the store. They are called in subscription order and in the
+// order the changes were applied, and must not apply events to the store
+// themselves. The returned function removes the subscription.
+func (s *StateStore) Subscribe(pointer string, fn func(old, new any)) (unsubscribe func(), err error) {
+	path, err := jsonpointer.Parse(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	sub := &stateSubscription{id: s.nextID, pointer: pointer, path: path, fn: fn}
+	sub.active.Store(true)
+	s.subscriptions = append(s.subscriptions, sub)
+
+	return func() { s.unsubscribe(sub) }, nil
+}
+
+// unsubscribe removes a subscription. It is safe to call more than once.
+func (s *StateStore) unsubscribe(sub *stateSubscription) {
+	if !sub.active.Swap(false) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.subscriptions {
+		if existing.id == sub.id {
+			s.subscriptions = append(s.subscriptions[:i:i], s.subscriptions[i+1:]...)
+			return
+		}
+	}
+}
+
+// watchedValues returns the current value at every subscribed path. The
+// values are shared with the state tree. The caller must hold the write
+// lock.
+func (s *StateStore) watchedValues() []watchedValue {
+	values := make([]watchedValue, len(s.subscriptions))
+	for i, sub := range s.subscriptions {
+		values[i] = s.valueAt(sub.path)
+	}
+	return values
+}
+
+// valueAt returns the value at the path, if it exists
+func (s *StateStore) valueAt(path []string) watchedValue {
+	if !s.hasSnapshot {
+		return watchedValue{}
+	}
+	value, err := getValue(s.state, path)
+	if err != nil {
+		return watchedValue{}
+	}
+	return watchedValue{value: value, exists: true}
+}
+
+// unlockAndNotify compares the watched values against their state before
+// the update, releases the write lock and calls the subscribers whose value
+// changed. The caller must hold the write lock.
+func (s *StateStore) unlockAndNotify(before []watchedValue) {
+	var pending []stateNotification
+	for i, sub := range s.subscriptions {
+		after := s.valueAt(sub.path)
+		if after.exists == before[i].exists && jsonValuesEqual(after.value, before[i].value) {
+			continue
+		}
+		pending = append(pending, stateNotification{
+			subscription: sub,
+			old:          deepCopyJSONValue(before[i].value),
+			new:          deepCopyJSONValue(after.value),
+		})
+	}
+
+	// Take the delivery lock before releasing the state lock so that
+	// notifications for consecutive updates cannot overtake each other
+	s.notifyMu.Lock()
+	defer s.notifyMu.Unlock()
+	s.mu.Unlock()
+
+	for _, n := range pending {
+		if n.subscription.active.Load() {
+			s.invoke(n)
+		}
+	}
+}
+
+// invoke calls a subscription callback, recovering from panics
+func (s *StateStore) invoke(n stateNotification) {
+	defer func() {
+		if recovered := recover(); recovered != nil && s.panicHandler != nil {
+			s.panicHandler(n.subscription.pointer, recovered)
+		}
+	}()
+	n.subscription.fn(n.old, n.new)
+}
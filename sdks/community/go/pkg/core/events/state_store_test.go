@@ -0,0 +1,480 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateStore(t *testing.T) {
+	t.Run("InterleavedSnapshotsAndDeltas", func(t *testing.T) {
+		store := NewStateStore()
+
+		store.ApplySnapshot(NewStateSnapshotEvent(map[string]any{
+			"counter": 1,
+			"items":   []any{"a"},
+		}))
+
+		require.NoError(t, store.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "replace", Path: "/counter", Value: 2},
+			{Op: "add", Path: "/items/-", Value: "b"},
+		})))
+		require.NoError(t, store.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "add", Path: "/status", Value: "running"},
+		})))
+
+		assert.Equal(t, map[string]any{
+			"counter": float64(2),
+			"items":   []any{"a", "b"},
+			"status":  "running",
+		}, store.Current())
+
+		// A new snapshot discards the accumulated deltas
+		store.ApplySnapshot(NewStateSnapshotEvent(map[string]any{"counter": 10}))
+		assert.Equal(t, map[string]any{"counter": float64(10)}, store.Current())
+
+		require.NoError(t, store.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "remove", Path: "/counter"},
+			{Op: "add", Path: "/done", Value: true},
+		})))
+		assert.Equal(t, map[string]any{"done": true}, store.Current())
+	})
+
+	t.Run("SnapshotIsCopied", func(t *testing.T) {
+		source := map[string]any{"nested": map[string]any{"value": "original"}}
+		store := NewStateStore()
+		store.ApplySnapshot(NewStateSnapshotEvent(source))
+
+		source["nested"].(map[string]any)["value"] = "changed"
+
+		state := store.Current().(map[string]any)
+		assert.Equal(t, "original", state["nested"].(map[string]any)["value"])
+	})
+
+	t.Run("MoveCopyAndTest", func(t *testing.T) {
+		store := NewStateStore()
+		store.ApplySnapshot(NewStateSnapshotEvent(map[string]any{
+			"a":    map[string]any{"b": "c"},
+			"list": []any{1, 2, 3},
+		}))
+
+		require.NoError(t, store.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "test", Path: "/a/b", Value: "c"},
+			{Op: "copy", From: "/a", Path: "/copied"},
+			{Op: "move", From: "/list/0", Path: "/list/-"},
+		})))
+
+		assert.Equal(t, map[string]any{
+			"a":      map[string]any{"b": "c"},
+			"copied": map[string]any{"b": "c"},
+			"list":   []any{float64(2), float64(3), float64(1)},
+		}, store.Current())
+	})
+
+	t.Run("DeltaErrors", func(t *testing.T) {
+		store := NewStateStore()
+		store.ApplySnapshot(NewStateSnapshotEvent(map[string]any{"a": 1}))
+
+		err := store.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "replace", Path: "/missing", Value: 1},
+		}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "/missing")
+
+		err = store.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "test", Path: "/a", Value: 2},
+		}))
+		assert.Error(t, err)
+	})
+
+	t.Run("ApplyAndVersion", func(t *testing.T) {
+		store := NewStateStore()
+		assert.Zero(t, store.Version())
+		assert.Nil(t, store.Current())
+
+		require.NoError(t, store.Apply(NewStateSnapshotEvent(map[string]any{"step": "plan"})))
+		require.NoError(t, store.Apply(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "replace", Path: "/step", Value: "act"},
+		})))
+		assert.Equal(t, uint64(2), store.Version())
+
+		// Events that do not carry state are ignored
+		require.NoError(t, store.Apply(NewTextMessageContentEvent("msg-1", "Hello")))
+		assert.Equal(t, uint64(2), store.Version())
+
+		// Failed deltas do not count as applied
+		require.Error(t, store.Apply(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "remove", Path: "/missing"},
+		})))
+		assert.Equal(t, uint64(2), store.Version())
+		assert.Equal(t, map[string]any{"step": "act"}, store.Current())
+	})
+
+	t.Run("DeltaBeforeSnapshot", func(t *testing.T) {
+		delta := NewStateDeltaEvent([]JSONPatchOperation{{Op: "add", Path: "/a", Value: 1}})
+
+		store := NewStateStore()
+		err := store.Apply(delta)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no state snapshot received")
+		assert.Zero(t, store.Version())
+
+		store = NewStateStore(WithEmptyInitialState())
+		assert.Equal(t, map[string]any{}, store.Current())
+		require.NoError(t, store.Apply(delta))
+		assert.Equal(t, map[string]any{"a": float64(1)}, store.Current())
+		assert.Equal(t, uint64(1), store.Version())
+	})
+
+	t.Run("CurrentReturnsCopy", func(t *testing.T) {
+		store := NewStateStore()
+		store.ApplySnapshot(NewStateSnapshotEvent(map[string]any{"items": []any{"a"}}))
+
+		state := store.Current().(map[string]any)
+		state["items"].([]any)[0] = "changed"
+		state["extra"] = true
+
+		assert.Equal(t, map[string]any{"items": []any{"a"}}, store.Current())
+	})
+
+	t.Run("CurrentSharedIsStable", func(t *testing.T) {
+		store := NewStateStore()
+		store.ApplySnapshot(NewStateSnapshotEvent(map[string]any{"items": []any{"a"}}))
+
+		shared := store.CurrentShared()
+		require.NoError(t, store.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "add", Path: "/items/-", Value: "b"},
+		})))
+
+		// Updates replace the tree instead of modifying the one readers hold
+		assert.Equal(t, map[string]any{"items": []any{"a"}}, shared)
+		assert.Equal(t, map[string]any{"items": []any{"a", "b"}}, store.CurrentShared())
+	})
+
+	t.Run("DeltasShareUnchangedSubtrees", func(t *testing.T) {
+		store := NewStateStore()
+		store.ApplySnapshot(NewStateSnapshotEvent(map[string]any{
+			"a": map[string]any{"items": []any{1, 2}},
+			"b": map[string]any{"name": "x"},
+		}))
+		before := store.state.(map[string]any)
+
+		require.NoError(t, store.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "add", Path: "/a/items/1", Value: 5},
+		})))
+		after := store.state.(map[string]any)
+
+		// The untouched branch is shared, the modified path is copied and
+		// the previous tree is left intact
+		assert.Equal(t, reflect.ValueOf(before["b"]).Pointer(), reflect.ValueOf(after["b"]).Pointer())
+		assert.Equal(t, []any{1.0, 2.0}, before["a"].(map[string]any)["items"])
+		assert.Equal(t, []any{1.0, 5.0, 2.0}, after["a"].(map[string]any)["items"])
+	})
+
+	t.Run("CurrentRaw", func(t *testing.T) {
+		store := NewStateStore()
+		assert.JSONEq(t, `null`, string(store.CurrentRaw()))
+
+		store.ApplySnapshot(NewStateSnapshotEvent(map[string]any{"count": 1}))
+		raw := store.CurrentRaw()
+		assert.JSONEq(t, `{"count":1}`, string(raw))
+		assert.Equal(t, &raw[0], &store.CurrentRaw()[0], "encoding should be cached per version")
+
+		require.NoError(t, store.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "replace", Path: "/count", Value: 2},
+		})))
+		assert.JSONEq(t, `{"count":2}`, string(store.CurrentRaw()))
+
+		store.ApplySnapshot(NewStateSnapshotEvent(map[string]any{"fn": func() {}}))
+		assert.Nil(t, store.CurrentRaw())
+	})
+
+	t.Run("ConcurrentReadsAndApplies", func(t *testing.T) {
+		store := NewStateStore(WithEmptyInitialState())
+		require.NoError(t, store.Apply(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "add", Path: "/items", Value: []any{}},
+		})))
+
+		const writers, appliesPerWriter = 4, 50
+		var wg sync.WaitGroup
+		for w := 0; w < writers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < appliesPerWriter; i++ {
+					assert.NoError(t, store.Apply(NewStateDeltaEvent([]JSONPatchOperation{
+						{Op: "add", Path: "/items/-", Value: fmt.Sprintf("%d-%d", w, i)},
+					})))
+				}
+			}(w)
+		}
+		for r := 0; r < 4; r++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < appliesPerWriter; i++ {
+					state := store.Current().(map[string]any)
+					// Readers own their copy and may modify it freely
+					state["items"] = append(state["items"].([]any), "local")
+					_ = store.Version()
+				}
+			}()
+		}
+		wg.Wait()
+
+		state := store.Current().(map[string]any)
+		assert.Len(t, state["items"], writers*appliesPerWriter)
+		assert.Equal(t, uint64(1+writers*appliesPerWriter), store.Version())
+	})
+}
+
+// stateChange records a subscription callback
+type stateChange struct {
+	Old, New any
+}
+
+// recordChanges subscribes to a pointer and collects the reported changes
+func recordChanges(t *testing.T, store *StateStore, pointer string) *[]stateChange {
+	t.Helper()
+
+	changes := &[]stateChange{}
+	_, err := store.Subscribe(pointer, func(old, new any) {
+		*changes = append(*changes, stateChange{Old: old, New: new})
+	})
+	require.NoError(t, err)
+	return changes
+}
+
+func TestStateStoreSubscriptions(t *testing.T) {
+	t.Run("DirectPathChanges", func(t *testing.T) {
+		store := NewStateStore()
+		progress := recordChanges(t, store, "/progress")
+		step := recordChanges(t, store, "/currentStep")
+
+		store.ApplySnapshot(NewStateSnapshotEvent(map[string]any{"progress": 0, "currentStep": "plan"}))
+		require.NoError(t, store.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "replace", Path: "/progress", Value: 50},
+		})))
+		require.NoError(t, store.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "remove", Path: "/currentStep"},
+		})))
+
+		assert.Equal(t, []stateChange{
+			{Old: nil, New: float64(0)},
+			{Old: float64(0), New: float64(50)},
+		}, *progress)
+		assert.Equal(t, []stateChange{
+			{Old: nil, New: "plan"},
+			{Old: "plan", New: nil},
+		}, *step)
+	})
+
+	t.Run("ParentReplacement", func(t *testing.T) {
+		store := NewStateStore()
+		store.ApplySnapshot(NewStateSnapshotEvent(map[string]any{
+			"task": map[string]any{"status": "running", "owner": "agent"},
+		}))
+		status := recordChanges(t, store, "/task/status")
+
+		// Replacing the parent changes the watched value
+		require.NoError(t, store.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "replace", Path: "/task", Value: map[string]any{"status": "done"}},
+		})))
+		// Replacing the parent with an equal value does not
+		require.NoError(t, store.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "replace", Path: "/task", Value: map[string]any{"status": "done", "owner": "user"}},
+		})))
+		// A new snapshot is a parent replacement too
+		store.ApplySnapshot(NewStateSnapshotEvent(map[string]any{"task": map[string]any{"status": "queued"}}))
+
+		assert.Equal(t, []stateChange{
+			{Old: "running", New: "done"},
+			{Old: "done", New: "queued"},
+		}, *status)
+	})
+
+	t.Run("NoOpDeltasDoNotFire", func(t *testing.T) {
+		store := NewStateStore()
+		store.ApplySnapshot(NewStateSnapshotEvent(map[string]any{"progress": 10, "other": 1}))
+		progress := recordChanges(t, store, "/progress")
+
+		require.NoError(t, store.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "replace", Path: "/progress", Value: 10.0},
+			{Op: "replace", Path: "/other", Value: 2},
+			{Op: "test", Path: "/progress", Value: 10},
+		})))
+		require.NoError(t, store.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "replace", Path: "/progress", Value: 11},
+			{Op: "replace", Path: "/progress", Value: 10},
+		})))
+		store.ApplySnapshot(NewStateSnapshotEvent(map[string]any{"progress": 10}))
+
+		assert.Empty(t, *progress)
+	})
+
+	t.Run("CallbacksRunAfterUpdate", func(t *testing.T) {
+		store := NewStateStore()
+		store.ApplySnapshot(NewStateSnapshotEvent(map[string]any{"a": 1, "b": 1}))
+
+		var seen any
+		var version uint64
+		_, err := store.Subscribe("/a", func(old, new any) {
+			seen = store.Current()
+			version = store.Version()
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, store.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "replace", Path: "/a", Value: 2},
+			{Op: "replace", Path: "/b", Value: 2},
+		})))
+		assert.Equal(t, map[string]any{"a": float64(2), "b": float64(2)}, seen)
+		assert.Equal(t, uint64(2), version)
+	})
+
+	t.Run("Unsubscribe", func(t *testing.T) {
+		store := NewStateStore(WithEmptyInitialState())
+		calls := 0
+		unsubscribe, err := store.Subscribe("/a", func(old, new any) { calls++ })
+		require.NoError(t, err)
+
+		require.NoError(t, store.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{{Op: "add", Path: "/a", Value: 1}})))
+		unsubscribe()
+		unsubscribe()
+		require.NoError(t, store.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{{Op: "replace", Path: "/a", Value: 2}})))
+
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("PanicIsolation", func(t *testing.T) {
+		var panics []string
+		store := NewStateStore(WithEmptyInitialState(), WithSubscriptionPanicHandler(func(pointer string, recovered any) {
+			panics = append(panics, fmt.Sprintf("%s: %v", pointer, recovered))
+		}))
+
+		_, err := store.Subscribe("/a", func(old, new any) { panic("boom") })
+		require.NoError(t, err)
+		after := recordChanges(t, store, "/a")
+
+		require.NoError(t, store.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{{Op: "add", Path: "/a", Value: 1}})))
+
+		assert.Equal(t, []string{"/a: boom"}, panics)
+		assert.Equal(t, []stateChange{{Old: nil, New: float64(1)}}, *after)
+		assert.Equal(t, map[string]any{"a": float64(1)}, store.Current())
+	})
+
+	t.Run("InvalidPointer", func(t *testing.T) {
+		_, err := NewStateStore().Subscribe("progress", func(old, new any) {})
+		assert.Error(t, err)
+	})
+
+	t.Run("OrderedDeliveryUnderConcurrency", func(t *testing.T) {
+		store := NewStateStore()
+		store.ApplySnapshot(NewStateSnapshotEvent(map[string]any{"n": 0}))
+
+		var mu sync.Mutex
+		var observed []float64
+		_, err := store.Subscribe("/n", func(old, new any) {
+			mu.Lock()
+			defer mu.Unlock()
+			observed = append(observed, new.(float64))
+		})
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 25; i++ {
+					// Unrelated deltas race with the counter updates
+					assert.NoError(t, store.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{
+						{Op: "add", Path: "/log", Value: i},
+					})))
+				}
+			}()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 1; i <= 50; i++ {
+				assert.NoError(t, store.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{
+					{Op: "replace", Path: "/n", Value: i},
+				})))
+			}
+		}()
+		wg.Wait()
+
+		require.Len(t, observed, 50)
+		for i, n := range observed {
+			assert.Equal(t, float64(i+1), n)
+		}
+	})
+}
+
+// largeState returns a nested document of about 10,000 leaf values
+func largeState() map[string]any {
+	state := make(map[string]any, 100)
+	for i := 0; i < 100; i++ {
+		items := make([]any, 20)
+		for j := range items {
+			items[j] = map[string]any{"id": fmt.Sprintf("%d-%d", i, j), "done": j%2 == 0, "score": float64(j)}
+		}
+		state[fmt.Sprintf("section%d", i)] = map[string]any{"title": fmt.Sprintf("Section %d", i), "items": items}
+	}
+	return state
+}
+
+func BenchmarkStateStoreRead(b *testing.B) {
+	store := NewStateStore()
+	store.ApplySnapshot(NewStateSnapshotEvent(largeState()))
+
+	// Current deep-copies the whole tree on every read, as every read did
+	// before the tree became immutable
+	b.Run("Current", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = store.Current()
+		}
+	})
+
+	b.Run("CurrentShared", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = store.CurrentShared()
+		}
+	})
+
+	// Re-serializing a copy is what consumers did before CurrentRaw
+	b.Run("MarshalCurrent", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := json.Marshal(store.Current()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("CurrentRaw", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = store.CurrentRaw()
+		}
+	})
+}
+
+func BenchmarkStateStoreApplyDelta(b *testing.B) {
+	store := NewStateStore()
+	store.ApplySnapshot(NewStateSnapshotEvent(largeState()))
+	delta := NewStateDeltaEvent([]JSONPatchOperation{
+		{Op: "replace", Path: "/section50/items/10/done", Value: true},
+	})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := store.ApplyDelta(delta); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
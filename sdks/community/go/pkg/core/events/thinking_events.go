@@ -1,9 +1,6 @@
 package events
 
-import (
-	"encoding/json"
-	"fmt"
-)
+import "encoding/json"
 
 // ThinkingStartEvent indicates the start of a thinking/reasoning phase
 type ThinkingStartEvent struct {
@@ -37,6 +34,36 @@ func (e *ThinkingStartEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// String returns a concise human-readable summary of the event
+func (e *ThinkingStartEvent) String() string {
+	var fields []string
+	if e.Title != nil {
+		fields = append(fields, summaryText("title", *e.Title))
+	}
+	return formatEvent(EventTypeThinkingStart, fields...)
+}
+
+// Clone returns a deep copy of the event
+func (e *ThinkingStartEvent) Clone() *ThinkingStartEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	clone.Title = cloneString(e.Title)
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *ThinkingStartEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *ThinkingStartEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeThinkingStart, e)
+}
+
 // ThinkingEndEvent indicates the end of a thinking/reasoning phase
 type ThinkingEndEvent struct {
 	*BaseEvent
@@ -62,6 +89,31 @@ func (e *ThinkingEndEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// String returns a concise human-readable summary of the event
+func (e *ThinkingEndEvent) String() string {
+	return formatEvent(EventTypeThinkingEnd)
+}
+
+// Clone returns a deep copy of the event
+func (e *ThinkingEndEvent) Clone() *ThinkingEndEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *ThinkingEndEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *ThinkingEndEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeThinkingEnd, e)
+}
+
 // ThinkingTextMessageStartEvent indicates the start of a thinking text message
 type ThinkingTextMessageStartEvent struct {
 	*BaseEvent
@@ -87,6 +139,31 @@ func (e *ThinkingTextMessageStartEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// String returns a concise human-readable summary of the event
+func (e *ThinkingTextMessageStartEvent) String() string {
+	return formatEvent(EventTypeThinkingTextMessageStart)
+}
+
+// Clone returns a deep copy of the event
+func (e *ThinkingTextMessageStartEvent) Clone() *ThinkingTextMessageStartEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *ThinkingTextMessageStartEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *ThinkingTextMessageStartEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeThinkingTextMessageStart, e)
+}
+
 // ThinkingTextMessageContentEvent contains streaming thinking text content
 type ThinkingTextMessageContentEvent struct {
 	*BaseEvent
@@ -108,7 +185,7 @@ func (e *ThinkingTextMessageContentEvent) Validate() error {
 	}
 
 	if e.Delta == "" {
-		return fmt.Errorf("ThinkingTextMessageContentEvent validation failed: delta field is required")
+		return newValidationError(EventTypeThinkingTextMessageContent, "delta", "delta field is required")
 	}
 
 	return nil
@@ -119,6 +196,31 @@ func (e *ThinkingTextMessageContentEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// String returns a concise human-readable summary of the event
+func (e *ThinkingTextMessageContentEvent) String() string {
+	return formatEvent(EventTypeThinkingTextMessageContent, summaryText("delta", e.Delta))
+}
+
+// Clone returns a deep copy of the event
+func (e *ThinkingTextMessageContentEvent) Clone() *ThinkingTextMessageContentEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *ThinkingTextMessageContentEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *ThinkingTextMessageContentEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeThinkingTextMessageContent, e)
+}
+
 // ThinkingTextMessageEndEvent indicates the end of a thinking text message
 type ThinkingTextMessageEndEvent struct {
 	*BaseEvent
@@ -143,3 +245,28 @@ func (e *ThinkingTextMessageEndEvent) Validate() error {
 func (e *ThinkingTextMessageEndEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
+
+// String returns a concise human-readable summary of the event
+func (e *ThinkingTextMessageEndEvent) String() string {
+	return formatEvent(EventTypeThinkingTextMessageEnd)
+}
+
+// Clone returns a deep copy of the event
+func (e *ThinkingTextMessageEndEvent) Clone() *ThinkingTextMessageEndEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *ThinkingTextMessageEndEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *ThinkingTextMessageEndEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeThinkingTextMessageEnd, e)
+}
@@ -0,0 +1,158 @@
+package events
+
+import "fmt"
+
+// Rule codes reported by the ThinkingBlockValidator
+const (
+	RuleThinkingAlreadyStarted        = "thinking_already_started"
+	RuleThinkingNotStarted            = "thinking_not_started"
+	RuleThinkingMessageAlreadyStarted = "thinking_message_already_started"
+	RuleThinkingMessageNotStarted     = "thinking_message_not_started"
+	RuleThinkingMessageNotEnded       = "thinking_message_not_ended"
+	RuleThinkingNotEnded              = "thinking_not_ended"
+)
+
+// ThinkingBlockValidator checks the nesting of thinking events: a
+// THINKING_START / THINKING_END block brackets thinking text messages, and
+// THINKING_TEXT_MESSAGE_CONTENT events belong between the start and end of
+// such a message. It reports events outside their enclosing block, blocks
+// and messages started twice, and blocks or messages left open. Other event
+// types are ignored.
+//
+// It can be used on its own or as part of an EventSequenceValidator created
+// with WithThinkingBlockValidation.
+type ThinkingBlockValidator struct {
+	index int
+
+	// blockStart and messageStart are the indexes of the events that opened
+	// the current thinking block and thinking text message, or -1
+	blockStart   int
+	messageStart int
+
+	result *SequenceValidationResult
+}
+
+// NewThinkingBlockValidator creates a thinking block validator failing on
+// errors
+func NewThinkingBlockValidator() *ThinkingBlockValidator {
+	v := &ThinkingBlockValidator{}
+	v.Reset()
+	return v
+}
+
+// WithThinkingBlockValidation makes the sequence validator also check the
+// nesting of thinking events with a ThinkingBlockValidator
+func WithThinkingBlockValidation() SequenceValidatorOption {
+	return func(v *EventSequenceValidator) {
+		v.modules = append(v.modules, NewThinkingBlockValidator())
+	}
+}
+
+// Reset clears all tracked state and findings
+func (v *ThinkingBlockValidator) Reset() {
+	v.index = 0
+	v.blockStart = -1
+	v.messageStart = -1
+	v.result = &SequenceValidationResult{FailOn: SeverityError}
+}
+
+// Result returns the findings collected so far
+func (v *ThinkingBlockValidator) Result() *SequenceValidationResult {
+	return v.result
+}
+
+// ValidateEvent validates the next event in the sequence and returns the
+// findings it produced
+func (v *ThinkingBlockValidator) ValidateEvent(event Event) []ValidationFinding {
+	index := v.index
+	v.index++
+	return validateModuleEvent(v, event, index, v.result)
+}
+
+// Finish reports a thinking block or message left open at the end of the
+// sequence. It should be called once after the last event has been
+// validated.
+func (v *ThinkingBlockValidator) Finish() []ValidationFinding {
+	findings := v.finish()
+	for _, finding := range findings {
+		v.result.add(finding)
+	}
+	return findings
+}
+
+// check applies the nesting rules to the event at the given index
+func (v *ThinkingBlockValidator) check(event Event, index int, report findingReporter) {
+	switch event.Type() {
+	case EventTypeThinkingStart:
+		if v.blockStart >= 0 {
+			report(SeverityError, RuleThinkingAlreadyStarted, nil,
+				"thinking block already started at event %d", v.blockStart)
+			return
+		}
+		v.blockStart = index
+
+	case EventTypeThinkingEnd:
+		if v.blockStart < 0 {
+			report(SeverityError, RuleThinkingNotStarted, nil, "cannot end thinking block that was not started")
+			return
+		}
+		if v.messageStart >= 0 {
+			report(SeverityError, RuleThinkingMessageNotEnded, nil,
+				"thinking block ended inside the thinking text message started at event %d", v.messageStart)
+			v.messageStart = -1
+		}
+		v.blockStart = -1
+
+	case EventTypeThinkingTextMessageStart:
+		if v.blockStart < 0 {
+			report(SeverityError, RuleThinkingNotStarted, nil, "thinking text message started outside of a thinking block")
+			return
+		}
+		if v.messageStart >= 0 {
+			report(SeverityError, RuleThinkingMessageAlreadyStarted, nil,
+				"thinking text message already started at event %d", v.messageStart)
+			return
+		}
+		v.messageStart = index
+
+	case EventTypeThinkingTextMessageContent:
+		if v.blockStart < 0 {
+			report(SeverityError, RuleThinkingNotStarted, nil, "thinking content outside of a thinking block")
+			return
+		}
+		if v.messageStart < 0 {
+			report(SeverityError, RuleThinkingMessageNotStarted, nil, "thinking content outside of a thinking text message")
+		}
+
+	case EventTypeThinkingTextMessageEnd:
+		if v.messageStart < 0 {
+			report(SeverityError, RuleThinkingMessageNotStarted, nil, "cannot end thinking text message that was not started")
+			return
+		}
+		v.messageStart = -1
+	}
+}
+
+// finish returns the findings for the block and message still open
+func (v *ThinkingBlockValidator) finish() []ValidationFinding {
+	var findings []ValidationFinding
+	if v.messageStart >= 0 {
+		findings = append(findings, ValidationFinding{
+			Severity:   SeverityError,
+			Rule:       RuleThinkingMessageNotEnded,
+			Message:    fmt.Sprintf("thinking text message started at event %d was never ended", v.messageStart),
+			EventIndex: v.messageStart,
+			EventType:  EventTypeThinkingTextMessageStart,
+		})
+	}
+	if v.blockStart >= 0 {
+		findings = append(findings, ValidationFinding{
+			Severity:   SeverityError,
+			Rule:       RuleThinkingNotEnded,
+			Message:    fmt.Sprintf("thinking block started at event %d was never ended", v.blockStart),
+			EventIndex: v.blockStart,
+			EventType:  EventTypeThinkingStart,
+		})
+	}
+	return findings
+}
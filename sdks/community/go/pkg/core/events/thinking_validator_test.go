@@ -0,0 +1,158 @@
+package events
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// thinkingFindings validates the events and returns "rule@index" for each
+// finding, including those reported by Finish
+func thinkingFindings(events ...Event) []string {
+	validator := NewThinkingBlockValidator()
+	for _, event := range events {
+		validator.ValidateEvent(event)
+	}
+	validator.Finish()
+
+	var findings []string
+	for _, finding := range validator.Result().Errors {
+		findings = append(findings, fmt.Sprintf("%s@%d", finding.Rule, finding.EventIndex))
+	}
+	return findings
+}
+
+func TestThinkingBlockValidator(t *testing.T) {
+	start := func() Event { return NewThinkingStartEvent() }
+	end := func() Event { return NewThinkingEndEvent() }
+	msgStart := func() Event { return NewThinkingTextMessageStartEvent() }
+	content := func() Event { return NewThinkingTextMessageContentEvent("hmm") }
+	msgEnd := func() Event { return NewThinkingTextMessageEndEvent() }
+
+	cases := []struct {
+		name   string
+		events []Event
+		want   []string
+	}{
+		{
+			name:   "WellNested",
+			events: []Event{start(), msgStart(), content(), content(), msgEnd(), msgStart(), msgEnd(), end(), start(), end()},
+		},
+		{
+			name:   "OtherEventsIgnored",
+			events: []Event{NewRunStartedEvent("t", "r"), start(), NewStepStartedEvent("s"), msgStart(), content(), msgEnd(), end()},
+		},
+		{
+			name:   "ContentOutsideBlock",
+			events: []Event{content()},
+			want:   []string{RuleThinkingNotStarted + "@0"},
+		},
+		{
+			name:   "ContentOutsideMessage",
+			events: []Event{start(), content(), end()},
+			want:   []string{RuleThinkingMessageNotStarted + "@1"},
+		},
+		{
+			name:   "MessageOutsideBlock",
+			events: []Event{msgStart(), msgEnd()},
+			want:   []string{RuleThinkingNotStarted + "@0", RuleThinkingMessageNotStarted + "@1"},
+		},
+		{
+			name:   "NestedBlock",
+			events: []Event{start(), start(), end()},
+			want:   []string{RuleThinkingAlreadyStarted + "@1"},
+		},
+		{
+			name:   "NestedMessage",
+			events: []Event{start(), msgStart(), msgStart(), msgEnd(), end()},
+			want:   []string{RuleThinkingMessageAlreadyStarted + "@2"},
+		},
+		{
+			name:   "EndWithoutStart",
+			events: []Event{end()},
+			want:   []string{RuleThinkingNotStarted + "@0"},
+		},
+		{
+			name:   "BlockEndedInsideMessage",
+			events: []Event{start(), msgStart(), content(), end(), msgEnd()},
+			want:   []string{RuleThinkingMessageNotEnded + "@3", RuleThinkingMessageNotStarted + "@4"},
+		},
+		{
+			name:   "UnclosedBlock",
+			events: []Event{start(), msgStart(), content(), msgEnd()},
+			want:   []string{RuleThinkingNotEnded + "@0"},
+		},
+		{
+			name:   "UnclosedMessage",
+			events: []Event{start(), end(), start(), msgStart(), content()},
+			want:   []string{RuleThinkingMessageNotEnded + "@3", RuleThinkingNotEnded + "@2"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, thinkingFindings(tc.events...))
+		})
+	}
+
+	t.Run("Reset", func(t *testing.T) {
+		validator := NewThinkingBlockValidator()
+		validator.ValidateEvent(NewThinkingStartEvent())
+		validator.ValidateEvent(NewThinkingStartEvent())
+		require.True(t, validator.Result().Failed)
+
+		validator.Reset()
+		assert.False(t, validator.Result().Failed)
+		assert.Empty(t, validator.ValidateEvent(NewThinkingStartEvent()))
+		assert.Empty(t, validator.ValidateEvent(NewThinkingEndEvent()))
+		assert.Empty(t, validator.Finish())
+	})
+}
+
+func TestEventSequenceValidatorThinkingModule(t *testing.T) {
+	events := []Event{
+		NewRunStartedEvent("thread-1", "run-1"),
+		NewThinkingTextMessageContentEvent("stray"),
+		NewThinkingStartEvent(),
+		NewThinkingTextMessageStartEvent(),
+		NewRunFinishedEvent("thread-1", "run-1"),
+	}
+
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		result := ValidateSequenceWithResult(events)
+		assert.False(t, result.Failed)
+		assert.Empty(t, result.Errors)
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		result := ValidateSequenceWithResult(events, WithThinkingBlockValidation())
+		require.True(t, result.Failed)
+
+		var rules []string
+		var indexes []int
+		for _, finding := range result.Errors {
+			rules = append(rules, finding.Rule)
+			indexes = append(indexes, finding.EventIndex)
+		}
+		assert.Equal(t, []string{RuleThinkingNotStarted, RuleThinkingMessageNotEnded, RuleThinkingNotEnded}, rules)
+		assert.Equal(t, []int{1, 3, 2}, indexes)
+	})
+
+	t.Run("ResetClearsThinkingState", func(t *testing.T) {
+		validator := NewEventSequenceValidator(WithThinkingBlockValidation())
+		validator.ValidateEvent(NewThinkingStartEvent())
+		validator.Reset()
+		assert.Empty(t, validator.Finish())
+	})
+
+	t.Run("Profile", func(t *testing.T) {
+		profile := DefaultProfile()
+		profile.Options = append(profile.Options, WithThinkingBlockValidation())
+		report, err := ValidateSequence(events, profile)
+		require.Error(t, err)
+		assert.Equal(t, RuleThinkingNotStarted, err.(*ValidationFinding).Rule)
+		assert.True(t, report.Failed())
+		assert.Len(t, report.FindingsAt(3), 1)
+	})
+}
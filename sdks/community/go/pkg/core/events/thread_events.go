@@ -0,0 +1,174 @@
+package events
+
+import "encoding/json"
+
+// ThreadCreatedEvent indicates that a conversation thread has been created
+type ThreadCreatedEvent struct {
+	*BaseEvent
+	ThreadIDValue string         `json:"threadId"`
+	Metadata      map[string]any `json:"metadata,omitempty"`
+}
+
+// NewThreadCreatedEvent creates a new thread created event
+func NewThreadCreatedEvent(threadID string, options ...ThreadCreatedOption) *ThreadCreatedEvent {
+	event := &ThreadCreatedEvent{
+		BaseEvent:     NewBaseEvent(EventTypeThreadCreated),
+		ThreadIDValue: threadID,
+	}
+
+	for _, opt := range options {
+		opt(event)
+	}
+
+	return event
+}
+
+// ThreadCreatedOption defines options for creating thread created events
+type ThreadCreatedOption func(*ThreadCreatedEvent)
+
+// WithThreadCreatedMetadata attaches application-defined metadata, such as a
+// title or owner, to the new thread
+func WithThreadCreatedMetadata(metadata map[string]any) ThreadCreatedOption {
+	return func(e *ThreadCreatedEvent) {
+		e.Metadata = metadata
+	}
+}
+
+// WithAutoThreadIDCreated automatically generates a unique thread ID if the provided threadID is empty
+func WithAutoThreadIDCreated() ThreadCreatedOption {
+	return func(e *ThreadCreatedEvent) {
+		if e.ThreadIDValue == "" {
+			e.ThreadIDValue = GenerateThreadID()
+		}
+	}
+}
+
+// Validate validates the thread created event
+func (e *ThreadCreatedEvent) Validate() error {
+	if err := e.BaseEvent.Validate(); err != nil {
+		return err
+	}
+
+	if e.ThreadIDValue == "" {
+		return newValidationError(EventTypeThreadCreated, "threadId", "threadId field is required")
+	}
+
+	return nil
+}
+
+// ThreadID returns the thread ID
+func (e *ThreadCreatedEvent) ThreadID() string {
+	return e.ThreadIDValue
+}
+
+// ToJSON serializes the event to JSON
+func (e *ThreadCreatedEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// String returns a concise human-readable summary of the event
+func (e *ThreadCreatedEvent) String() string {
+	return formatEvent(EventTypeThreadCreated, summaryField("thread", e.ThreadIDValue))
+}
+
+// Clone returns a deep copy of the event
+func (e *ThreadCreatedEvent) Clone() *ThreadCreatedEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	clone.Metadata = cloneMetadata(e.Metadata)
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *ThreadCreatedEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *ThreadCreatedEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeThreadCreated, e)
+}
+
+// ThreadDeletedEvent indicates that a conversation thread has been deleted
+type ThreadDeletedEvent struct {
+	*BaseEvent
+	ThreadIDValue string         `json:"threadId"`
+	Metadata      map[string]any `json:"metadata,omitempty"`
+}
+
+// NewThreadDeletedEvent creates a new thread deleted event
+func NewThreadDeletedEvent(threadID string, options ...ThreadDeletedOption) *ThreadDeletedEvent {
+	event := &ThreadDeletedEvent{
+		BaseEvent:     NewBaseEvent(EventTypeThreadDeleted),
+		ThreadIDValue: threadID,
+	}
+
+	for _, opt := range options {
+		opt(event)
+	}
+
+	return event
+}
+
+// ThreadDeletedOption defines options for creating thread deleted events
+type ThreadDeletedOption func(*ThreadDeletedEvent)
+
+// WithThreadDeletedMetadata attaches application-defined metadata, such as
+// the reason for the deletion, to the event
+func WithThreadDeletedMetadata(metadata map[string]any) ThreadDeletedOption {
+	return func(e *ThreadDeletedEvent) {
+		e.Metadata = metadata
+	}
+}
+
+// Validate validates the thread deleted event
+func (e *ThreadDeletedEvent) Validate() error {
+	if err := e.BaseEvent.Validate(); err != nil {
+		return err
+	}
+
+	if e.ThreadIDValue == "" {
+		return newValidationError(EventTypeThreadDeleted, "threadId", "threadId field is required")
+	}
+
+	return nil
+}
+
+// ThreadID returns the thread ID
+func (e *ThreadDeletedEvent) ThreadID() string {
+	return e.ThreadIDValue
+}
+
+// ToJSON serializes the event to JSON
+func (e *ThreadDeletedEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// String returns a concise human-readable summary of the event
+func (e *ThreadDeletedEvent) String() string {
+	return formatEvent(EventTypeThreadDeleted, summaryField("thread", e.ThreadIDValue))
+}
+
+// Clone returns a deep copy of the event
+func (e *ThreadDeletedEvent) Clone() *ThreadDeletedEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	clone.Metadata = cloneMetadata(e.Metadata)
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *ThreadDeletedEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *ThreadDeletedEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeThreadDeleted, e)
+}
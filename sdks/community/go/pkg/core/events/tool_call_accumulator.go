@@ -0,0 +1,209 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// AccumulatedToolCall is a tool call that has ended, with its parsed
+// arguments and, once it arrives, its result
+type AccumulatedToolCall struct {
+	AssembledToolCall
+
+	// ParsedArgs holds the arguments decoded as JSON, or nil when they are
+	// empty or not valid JSON
+	ParsedArgs any `json:"parsedArgs,omitempty"`
+
+	// Result is the TOOL_CALL_RESULT event of the tool call, or nil while
+	// it has not arrived
+	Result *ToolCallResultEvent `json:"result,omitempty"`
+}
+
+// PartialToolCall is a tool call that has started but not ended
+type PartialToolCall struct {
+	ToolCallID      string
+	ToolCallName    string
+	ParentMessageID *string
+
+	// Args holds the arguments received so far
+	Args string
+}
+
+// ToolCallAccumulator tracks tool calls from their start to their result,
+// assembling the streamed arguments with a ToolCallAssembler. Tool calls are
+// completed by their end event, and their result is attached whenever it
+// arrives. Several tool calls may be in flight at once. It is not safe for
+// concurrent use.
+//
+// By default the accumulator is lenient with out-of-order events: a result
+// arriving before the end of its tool call is kept until the end, arguments
+// arriving after the end are appended to the completed tool call, results
+// for unknown tool calls are ignored, and arguments that are not valid JSON
+// leave ParsedArgs nil. StrictToolCalls turns all of these into errors.
+type ToolCallAccumulator struct {
+	strict  bool
+	onReady func(AccumulatedToolCall)
+
+	assembler *ToolCallAssembler
+	completed []AccumulatedToolCall
+	index     map[string]int // position of completed tool calls by ID
+	early     map[string]*ToolCallResultEvent
+}
+
+// ToolCallAccumulatorOption defines options for creating tool call
+// accumulators
+type ToolCallAccumulatorOption func(*ToolCallAccumulator)
+
+// StrictToolCalls makes the accumulator fail on results arriving before the
+// end of their tool call, arguments arriving after it, results for unknown
+// tool calls and arguments that are not valid JSON
+func StrictToolCalls() ToolCallAccumulatorOption {
+	return func(a *ToolCallAccumulator) {
+		a.strict = true
+	}
+}
+
+// OnToolCallReady registers a function called with every tool call as soon
+// as its end event has been processed, before its result is known unless it
+// arrived early. The function receives a copy of the tool call.
+func OnToolCallReady(fn func(AccumulatedToolCall)) ToolCallAccumulatorOption {
+	return func(a *ToolCallAccumulator) {
+		a.onReady = fn
+	}
+}
+
+// NewToolCallAccumulator creates a new tool call accumulator
+func NewToolCallAccumulator(options ...ToolCallAccumulatorOption) *ToolCallAccumulator {
+	a := &ToolCallAccumulator{
+		assembler: NewToolCallAssembler(),
+		index:     make(map[string]int),
+		early:     make(map[string]*ToolCallResultEvent),
+	}
+
+	for _, opt := range options {
+		opt(a)
+	}
+
+	return a
+}
+
+// Process records the next event. Events that are not part of a tool call
+// are ignored. Starting a tool call twice, and arguments or an end event for
+// a tool call that never started, are errors in every mode.
+func (a *ToolCallAccumulator) Process(event Event) error {
+	switch e := event.(type) {
+	case *ToolCallArgsEvent:
+		if i, ok := a.index[e.ToolCallID]; ok {
+			if a.strict {
+				return fmt.Errorf("tool call %s received args after end", e.ToolCallID)
+			}
+			call := &a.completed[i]
+			call.Args += e.Delta
+			call.ParsedArgs, _ = parseToolCallArgs(call.Args)
+			return nil
+		}
+
+	case *ToolCallResultEvent:
+		return a.processResult(e)
+
+	case *ToolCallStartEvent:
+		if _, ok := a.index[e.ToolCallID]; ok {
+			return fmt.Errorf("tool call %s already started", e.ToolCallID)
+		}
+	}
+
+	assembled, err := a.assembler.Feed(event)
+	if err != nil || assembled == nil {
+		return err
+	}
+
+	// A tool call with invalid args is still completed, with its raw args,
+	// before strict mode reports them
+	call := AccumulatedToolCall{AssembledToolCall: *assembled}
+	call.ParsedArgs, err = parseToolCallArgs(call.Args)
+	call.Result = a.early[call.ToolCallID]
+	delete(a.early, call.ToolCallID)
+
+	a.index[call.ToolCallID] = len(a.completed)
+	a.completed = append(a.completed, call)
+
+	if a.onReady != nil {
+		a.onReady(call.clone())
+	}
+	if err != nil && a.strict {
+		return fmt.Errorf("tool call %s ended with invalid args: %w", call.ToolCallID, err)
+	}
+	return nil
+}
+
+// processResult attaches a result to its tool call
+func (a *ToolCallAccumulator) processResult(e *ToolCallResultEvent) error {
+	if i, ok := a.index[e.ToolCallID]; ok {
+		if a.strict && a.completed[i].Result != nil {
+			return fmt.Errorf("tool call %s received a second result", e.ToolCallID)
+		}
+		a.completed[i].Result = e.Clone()
+		return nil
+	}
+
+	if _, ok := a.assembler.pending[e.ToolCallID]; ok {
+		if a.strict {
+			return fmt.Errorf("tool call %s received its result before end", e.ToolCallID)
+		}
+		a.early[e.ToolCallID] = e.Clone()
+		return nil
+	}
+
+	if a.strict {
+		return fmt.Errorf("result for unknown tool call %s", e.ToolCallID)
+	}
+	return nil
+}
+
+// Completed returns copies of the tool calls that have ended, in the order
+// in which they ended
+func (a *ToolCallAccumulator) Completed() []AccumulatedToolCall {
+	completed := make([]AccumulatedToolCall, len(a.completed))
+	for i := range a.completed {
+		completed[i] = a.completed[i].clone()
+	}
+	return completed
+}
+
+// Pending returns the tool calls that have started but not ended, ordered by
+// ID
+func (a *ToolCallAccumulator) Pending() []PartialToolCall {
+	pending := make([]PartialToolCall, 0, len(a.assembler.pending))
+	for id, call := range a.assembler.pending {
+		pending = append(pending, PartialToolCall{
+			ToolCallID:      id,
+			ToolCallName:    call.call.ToolCallName,
+			ParentMessageID: cloneString(call.call.ParentMessageID),
+			Args:            call.args.String(),
+		})
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].ToolCallID < pending[j].ToolCallID })
+	return pending
+}
+
+// clone returns a deep copy of the tool call
+func (c AccumulatedToolCall) clone() AccumulatedToolCall {
+	c.ParentMessageID = cloneString(c.ParentMessageID)
+	c.ParsedArgs = deepCopyJSONValue(c.ParsedArgs)
+	c.Result = c.Result.Clone()
+	return c
+}
+
+// parseToolCallArgs decodes tool call arguments, returning nil for empty
+// arguments
+func parseToolCallArgs(args string) (any, error) {
+	if args == "" {
+		return nil, nil
+	}
+	var parsed any
+	if err := json.Unmarshal([]byte(args), &parsed); err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
@@ -0,0 +1,158 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolCallAccumulator_Interleaved(t *testing.T) {
+	var ready []AccumulatedToolCall
+	a := NewToolCallAccumulator(OnToolCallReady(func(call AccumulatedToolCall) {
+		ready = append(ready, call)
+	}))
+
+	for _, e := range []Event{
+		NewToolCallStartEvent("call-1", "get_weather", WithParentMessageID("msg-1")),
+		NewToolCallStartEvent("call-2", "get_time"),
+		NewToolCallArgsEvent("call-1", `{"city":`),
+		NewToolCallArgsEvent("call-2", `{"tz":"UTC"`),
+		NewTextMessageContentEvent("msg-1", "ignored"),
+	} {
+		require.NoError(t, a.Process(e))
+	}
+
+	parent := "msg-1"
+	assert.Equal(t, []PartialToolCall{
+		{ToolCallID: "call-1", ToolCallName: "get_weather", ParentMessageID: &parent, Args: `{"city":`},
+		{ToolCallID: "call-2", ToolCallName: "get_time", Args: `{"tz":"UTC"`},
+	}, a.Pending())
+	assert.Empty(t, a.Completed())
+
+	for _, e := range []Event{
+		NewToolCallArgsEvent("call-2", `}`),
+		NewToolCallEndEvent("call-2"),
+		NewToolCallArgsEvent("call-1", `"Paris"}`),
+		NewToolCallEndEvent("call-1"),
+	} {
+		require.NoError(t, a.Process(e))
+	}
+
+	// The callback fires at each end, before the results arrive
+	require.Len(t, ready, 2)
+	assert.Equal(t, "call-2", ready[0].ToolCallID)
+	assert.Equal(t, map[string]any{"tz": "UTC"}, ready[0].ParsedArgs)
+	assert.Equal(t, "call-1", ready[1].ToolCallID)
+	assert.Equal(t, map[string]any{"city": "Paris"}, ready[1].ParsedArgs)
+	assert.Nil(t, ready[1].Result)
+
+	require.NoError(t, a.Process(NewToolCallResultEvent("msg-2", "call-1", "sunny")))
+	require.NoError(t, a.Process(NewToolCallResultEvent("msg-3", "call-2", "12:00")))
+
+	completed := a.Completed()
+	require.Len(t, completed, 2)
+	assert.Empty(t, a.Pending())
+	assert.Equal(t, "call-2", completed[0].ToolCallID)
+	assert.Equal(t, "12:00", completed[0].Result.Content)
+	assert.Equal(t, "call-1", completed[1].ToolCallID)
+	assert.Equal(t, `{"city":"Paris"}`, completed[1].Args)
+	assert.Equal(t, "msg-1", *completed[1].ParentMessageID)
+	assert.Equal(t, "sunny", completed[1].Result.Content)
+
+	// Completed returns copies
+	completed[1].Result.Content = "changed"
+	completed[1].ParsedArgs.(map[string]any)["city"] = "Rome"
+	assert.Equal(t, "sunny", a.Completed()[1].Result.Content)
+	assert.Equal(t, "Paris", a.Completed()[1].ParsedArgs.(map[string]any)["city"])
+}
+
+func TestToolCallAccumulator_MalformedArgs(t *testing.T) {
+	events := []Event{
+		NewToolCallStartEvent("call-1", "search"),
+		NewToolCallArgsEvent("call-1", `{"query": "go`),
+		NewToolCallEndEvent("call-1"),
+	}
+
+	t.Run("lenient", func(t *testing.T) {
+		var ready []AccumulatedToolCall
+		a := NewToolCallAccumulator(OnToolCallReady(func(call AccumulatedToolCall) {
+			ready = append(ready, call)
+		}))
+		for _, e := range events {
+			require.NoError(t, a.Process(e))
+		}
+		require.Len(t, ready, 1)
+		assert.Equal(t, `{"query": "go`, ready[0].Args)
+		assert.Nil(t, ready[0].ParsedArgs)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		a := NewToolCallAccumulator(StrictToolCalls())
+		require.NoError(t, a.Process(events[0]))
+		require.NoError(t, a.Process(events[1]))
+		err := a.Process(events[2])
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid args")
+
+		// The tool call is completed with its raw args nonetheless
+		completed := a.Completed()
+		require.Len(t, completed, 1)
+		assert.Equal(t, `{"query": "go`, completed[0].Args)
+		assert.Nil(t, completed[0].ParsedArgs)
+		assert.Empty(t, a.Pending())
+	})
+}
+
+func TestToolCallAccumulator_OutOfOrder(t *testing.T) {
+	start := NewToolCallStartEvent("call-1", "search")
+	args := NewToolCallArgsEvent("call-1", `{"q":1}`)
+	end := NewToolCallEndEvent("call-1")
+	result := NewToolCallResultEvent("msg-2", "call-1", "found")
+
+	t.Run("result before end", func(t *testing.T) {
+		a := NewToolCallAccumulator()
+		for _, e := range []Event{start, args, result, end} {
+			require.NoError(t, a.Process(e))
+		}
+		require.Len(t, a.Completed(), 1)
+		assert.Equal(t, "found", a.Completed()[0].Result.Content)
+
+		strict := NewToolCallAccumulator(StrictToolCalls())
+		require.NoError(t, strict.Process(start))
+		assert.Error(t, strict.Process(result))
+	})
+
+	t.Run("args after end", func(t *testing.T) {
+		a := NewToolCallAccumulator()
+		for _, e := range []Event{start, NewToolCallArgsEvent("call-1", `{"q":`), end, NewToolCallArgsEvent("call-1", `2}`)} {
+			require.NoError(t, a.Process(e))
+		}
+		assert.Equal(t, `{"q":2}`, a.Completed()[0].Args)
+		assert.Equal(t, map[string]any{"q": float64(2)}, a.Completed()[0].ParsedArgs)
+
+		strict := NewToolCallAccumulator(StrictToolCalls())
+		for _, e := range []Event{start, args, end} {
+			require.NoError(t, strict.Process(e))
+		}
+		assert.Error(t, strict.Process(args))
+	})
+
+	t.Run("orphan result", func(t *testing.T) {
+		a := NewToolCallAccumulator()
+		require.NoError(t, a.Process(result))
+		assert.Empty(t, a.Completed())
+
+		assert.Error(t, NewToolCallAccumulator(StrictToolCalls()).Process(result))
+	})
+
+	t.Run("lifecycle errors", func(t *testing.T) {
+		a := NewToolCallAccumulator()
+		assert.Error(t, a.Process(args))
+		assert.Error(t, a.Process(end))
+		for _, e := range []Event{start, end} {
+			require.NoError(t, a.Process(e))
+		}
+		assert.Error(t, a.Process(start))
+	})
+}
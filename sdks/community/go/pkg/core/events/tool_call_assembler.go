@@ -0,0 +1,151 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// AssembledToolCall is a tool call whose streamed arguments have been
+// concatenated
+type AssembledToolCall struct {
+	ToolCallID      string  `json:"toolCallId"`
+	ToolCallName    string  `json:"toolCallName"`
+	ParentMessageID *string `json:"parentMessageId,omitempty"`
+	Args            string  `json:"args"`
+}
+
+// ToolCallAssembler concatenates the TOOL_CALL_ARGS deltas of in-flight tool
+// calls and returns each tool call once its end event arrives
+type ToolCallAssembler struct {
+	rejectCompleteFragments bool
+	validateArgs            bool
+
+	pending map[string]*pendingToolCall
+}
+
+// pendingToolCall is a tool call that has started but not ended
+type pendingToolCall struct {
+	call AssembledToolCall
+	args strings.Builder
+
+	// validator checks the arguments as they arrive, if enabled with
+	// WithArgValidation
+	validator *ProgressiveJSONValidator
+}
+
+// ToolCallAssemblerOption defines options for creating tool call assemblers
+type ToolCallAssemblerOption func(*ToolCallAssembler)
+
+// RejectCompleteFragments makes the assembler fail when an arguments delta of
+// a tool call is a complete JSON object or array on its own and would start a
+// new document: when it is the first delta, or when the arguments received
+// before it are already complete. Agents that stream their arguments send
+// partial fragments, so a complete first one usually means the agent is not
+// streaming at all, and a later one would make the arguments invalid. A
+// complete object or array nested inside the arguments, such as the value of
+// a field, is accepted.
+func RejectCompleteFragments() ToolCallAssemblerOption {
+	return func(a *ToolCallAssembler) {
+		a.rejectCompleteFragments = true
+	}
+}
+
+// WithArgValidation makes the assembler check the arguments of each tool call
+// with a ProgressiveJSONValidator. Feed fails as soon as an arguments delta
+// makes them structurally invalid, and when a tool call ends with arguments
+// that are not a complete JSON object or array. Tool calls that end without
+// any arguments are accepted.
+func WithArgValidation() ToolCallAssemblerOption {
+	return func(a *ToolCallAssembler) {
+		a.validateArgs = true
+	}
+}
+
+// NewToolCallAssembler creates a new tool call assembler
+func NewToolCallAssembler(options ...ToolCallAssemblerOption) *ToolCallAssembler {
+	a := &ToolCallAssembler{
+		pending: make(map[string]*pendingToolCall),
+	}
+
+	for _, opt := range options {
+		opt(a)
+	}
+
+	return a
+}
+
+// Feed processes the next event. It returns the assembled tool call when the
+// event ends one, and nil otherwise. Events that are not part of a tool call
+// are ignored.
+func (a *ToolCallAssembler) Feed(event Event) (*AssembledToolCall, error) {
+	switch e := event.(type) {
+	case *ToolCallStartEvent:
+		if _, ok := a.pending[e.ToolCallID]; ok {
+			return nil, fmt.Errorf("tool call %s already started", e.ToolCallID)
+		}
+		pending := &pendingToolCall{call: AssembledToolCall{
+			ToolCallID:      e.ToolCallID,
+			ToolCallName:    e.ToolCallName,
+			ParentMessageID: cloneString(e.ParentMessageID),
+		}}
+		if a.validateArgs {
+			pending.validator = NewProgressiveJSONValidator()
+		}
+		a.pending[e.ToolCallID] = pending
+
+	case *ToolCallArgsEvent:
+		pending, ok := a.pending[e.ToolCallID]
+		if !ok {
+			return nil, fmt.Errorf("tool call %s received args before start", e.ToolCallID)
+		}
+		if a.rejectCompleteFragments && isCompleteJSONFragment(e.Delta) {
+			if pending.args.Len() == 0 {
+				return nil, fmt.Errorf("tool call %s received its complete args in a single fragment", e.ToolCallID)
+			}
+			if isCompleteJSONFragment(pending.args.String()) {
+				return nil, fmt.Errorf("tool call %s received a complete args fragment after its args were complete", e.ToolCallID)
+			}
+		}
+		if pending.validator != nil {
+			if err := pending.validator.Write(e.Delta); err != nil {
+				return nil, fmt.Errorf("tool call %s received invalid args: %w", e.ToolCallID, err)
+			}
+		}
+		pending.args.WriteString(e.Delta)
+
+	case *ToolCallEndEvent:
+		pending, ok := a.pending[e.ToolCallID]
+		if !ok {
+			return nil, fmt.Errorf("tool call %s ended before start", e.ToolCallID)
+		}
+		delete(a.pending, e.ToolCallID)
+
+		if pending.validator != nil && pending.args.Len() > 0 {
+			if err := pending.validator.Validate(); err != nil {
+				return nil, fmt.Errorf("tool call %s ended with invalid args: %w", e.ToolCallID, err)
+			}
+		}
+
+		call := pending.call
+		call.Args = pending.args.String()
+		return &call, nil
+	}
+
+	return nil, nil
+}
+
+// Pending returns the number of tool calls that have started but not ended
+func (a *ToolCallAssembler) Pending() int {
+	return len(a.pending)
+}
+
+// isCompleteJSONFragment reports whether a fragment is a complete JSON object
+// or array on its own
+func isCompleteJSONFragment(fragment string) bool {
+	trimmed := strings.TrimSpace(fragment)
+	if trimmed == "" || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return false
+	}
+	return json.Valid([]byte(trimmed))
+}
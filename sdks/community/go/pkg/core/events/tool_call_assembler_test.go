@@ -0,0 +1,177 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// feedAll feeds events to the assembler and returns the assembled tool calls
+func feedAll(t *testing.T, assembler *ToolCallAssembler, events []Event) []*AssembledToolCall {
+	t.Helper()
+
+	var calls []*AssembledToolCall
+	for _, event := range events {
+		call, err := assembler.Feed(event)
+		require.NoError(t, err)
+		if call != nil {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func TestToolCallAssembler(t *testing.T) {
+	streamed := []Event{
+		NewToolCallStartEvent("tool-1", "get_weather", WithParentMessageID("msg-1")),
+		NewToolCallArgsEvent("tool-1", `{"location":`),
+		NewTextMessageContentEvent("msg-1", "ignored"),
+		NewToolCallArgsEvent("tool-1", ` "Paris", "days": {}`),
+		NewToolCallArgsEvent("tool-1", `}`),
+		NewToolCallEndEvent("tool-1"),
+	}
+
+	t.Run("StreamedArgs", func(t *testing.T) {
+		assembler := NewToolCallAssembler(RejectCompleteFragments())
+		calls := feedAll(t, assembler, streamed)
+
+		require.Len(t, calls, 1)
+		assert.Equal(t, "tool-1", calls[0].ToolCallID)
+		assert.Equal(t, "get_weather", calls[0].ToolCallName)
+		require.NotNil(t, calls[0].ParentMessageID)
+		assert.Equal(t, "msg-1", *calls[0].ParentMessageID)
+		assert.JSONEq(t, `{"location": "Paris", "days": {}}`, calls[0].Args)
+		assert.Zero(t, assembler.Pending())
+	})
+
+	t.Run("InterleavedToolCalls", func(t *testing.T) {
+		assembler := NewToolCallAssembler()
+		calls := feedAll(t, assembler, []Event{
+			NewToolCallStartEvent("tool-1", "a"),
+			NewToolCallStartEvent("tool-2", "b"),
+			NewToolCallArgsEvent("tool-2", `[1,`),
+			NewToolCallArgsEvent("tool-1", `"x"`),
+			NewToolCallArgsEvent("tool-2", `2]`),
+			NewToolCallEndEvent("tool-2"),
+		})
+
+		require.Len(t, calls, 1)
+		assert.Equal(t, "[1,2]", calls[0].Args)
+		assert.Equal(t, 1, assembler.Pending())
+	})
+
+	t.Run("SingleCompleteFragment", func(t *testing.T) {
+		events := []Event{
+			NewToolCallStartEvent("tool-1", "get_weather"),
+			NewToolCallArgsEvent("tool-1", ` {"location": "Paris"} `),
+			NewToolCallEndEvent("tool-1"),
+		}
+
+		// Accepted by default
+		calls := feedAll(t, NewToolCallAssembler(), events)
+		require.Len(t, calls, 1)
+		assert.Equal(t, ` {"location": "Paris"} `, calls[0].Args)
+
+		assembler := NewToolCallAssembler(RejectCompleteFragments())
+		_, err := assembler.Feed(events[0])
+		require.NoError(t, err)
+		_, err = assembler.Feed(events[1])
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "tool call tool-1 received its complete args in a single fragment")
+	})
+
+	t.Run("LaterCompleteFragment", func(t *testing.T) {
+		assembler := NewToolCallAssembler(RejectCompleteFragments())
+		for _, event := range []Event{
+			NewToolCallStartEvent("tool-1", "get_weather"),
+			NewToolCallArgsEvent("tool-1", `{"location":`),
+			NewToolCallArgsEvent("tool-1", ` "Paris"}`),
+		} {
+			_, err := assembler.Feed(event)
+			require.NoError(t, err)
+		}
+
+		_, err := assembler.Feed(NewToolCallArgsEvent("tool-1", `{"days": 3}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "tool call tool-1 received a complete args fragment after its args were complete")
+	})
+
+	t.Run("NestedCompleteFragmentsAreNotRejected", func(t *testing.T) {
+		calls := feedAll(t, NewToolCallAssembler(RejectCompleteFragments()), []Event{
+			NewToolCallStartEvent("tool-1", "get_weather"),
+			NewToolCallArgsEvent("tool-1", `{"location":`),
+			NewToolCallArgsEvent("tool-1", `{"city": "Paris"}`),
+			NewToolCallArgsEvent("tool-1", `, "days": `),
+			NewToolCallArgsEvent("tool-1", `[1, 2]`),
+			NewToolCallArgsEvent("tool-1", `}`),
+			NewToolCallEndEvent("tool-1"),
+		})
+		require.Len(t, calls, 1)
+		assert.JSONEq(t, `{"location": {"city": "Paris"}, "days": [1, 2]}`, calls[0].Args)
+	})
+
+	t.Run("ScalarFragmentsAreNotRejected", func(t *testing.T) {
+		calls := feedAll(t, NewToolCallAssembler(RejectCompleteFragments()), []Event{
+			NewToolCallStartEvent("tool-1", "count"),
+			NewToolCallArgsEvent("tool-1", `42`),
+			NewToolCallEndEvent("tool-1"),
+		})
+		require.Len(t, calls, 1)
+		assert.Equal(t, "42", calls[0].Args)
+	})
+
+	t.Run("ArgValidation", func(t *testing.T) {
+		calls := feedAll(t, NewToolCallAssembler(WithArgValidation()), append(streamed,
+			NewToolCallStartEvent("tool-2", "no_args"),
+			NewToolCallEndEvent("tool-2"),
+		))
+		require.Len(t, calls, 2)
+		assert.JSONEq(t, `{"location": "Paris", "days": {}}`, calls[0].Args)
+		assert.Empty(t, calls[1].Args)
+
+		// Structural errors are reported by the delta that causes them
+		assembler := NewToolCallAssembler(WithArgValidation())
+		feedAll(t, assembler, []Event{
+			NewToolCallStartEvent("tool-1", "get_weather"),
+			NewToolCallArgsEvent("tool-1", `{"days": [1, 2`),
+		})
+		_, err := assembler.Feed(NewToolCallArgsEvent("tool-1", `}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "tool call tool-1 received invalid args: invalid JSON at offset 14")
+
+		// Incomplete args are reported when the tool call ends
+		assembler = NewToolCallAssembler(WithArgValidation())
+		feedAll(t, assembler, []Event{
+			NewToolCallStartEvent("tool-1", "get_weather"),
+			NewToolCallArgsEvent("tool-1", `{"location": "Par`),
+		})
+		_, err = assembler.Feed(NewToolCallEndEvent("tool-1"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "tool call tool-1 ended with invalid args: incomplete JSON: unterminated string")
+		assert.Zero(t, assembler.Pending())
+
+		// Without validation the same args are assembled as they are
+		calls = feedAll(t, NewToolCallAssembler(), []Event{
+			NewToolCallStartEvent("tool-1", "get_weather"),
+			NewToolCallArgsEvent("tool-1", `{"location": "Par`),
+			NewToolCallEndEvent("tool-1"),
+		})
+		require.Len(t, calls, 1)
+	})
+
+	t.Run("LifecycleErrors", func(t *testing.T) {
+		assembler := NewToolCallAssembler()
+
+		_, err := assembler.Feed(NewToolCallArgsEvent("tool-1", "{}"))
+		assert.EqualError(t, err, "tool call tool-1 received args before start")
+
+		_, err = assembler.Feed(NewToolCallEndEvent("tool-1"))
+		assert.EqualError(t, err, "tool call tool-1 ended before start")
+
+		_, err = assembler.Feed(NewToolCallStartEvent("tool-1", "a"))
+		require.NoError(t, err)
+		_, err = assembler.Feed(NewToolCallStartEvent("tool-1", "a"))
+		assert.EqualError(t, err, "tool call tool-1 already started")
+	})
+}
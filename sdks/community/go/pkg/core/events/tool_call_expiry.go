@@ -0,0 +1,127 @@
+package events
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// RuleToolCallExpired is reported by the ToolCallExpiryValidator for a tool
+// call whose result did not arrive before its deadline
+const RuleToolCallExpired = "tool_call_expired"
+
+// ToolCallExpiryValidator checks a recorded run for tool calls that carry a
+// deadline (see WithExpiresAt) and whose result arrived after it, judged by
+// the result's timestamp, or never arrived at all. Results without a
+// timestamp are assumed to be on time. Other event types are ignored.
+//
+// It can be used on its own or as part of an EventSequenceValidator created
+// with WithToolCallExpiryValidation.
+type ToolCallExpiryValidator struct {
+	index   int
+	pending map[string]expiringToolCall
+	result  *SequenceValidationResult
+}
+
+// expiringToolCall is a tool call with a deadline awaiting its result
+type expiringToolCall struct {
+	index    int
+	deadline time.Time
+}
+
+// NewToolCallExpiryValidator creates a tool call expiry validator failing on
+// errors
+func NewToolCallExpiryValidator() *ToolCallExpiryValidator {
+	v := &ToolCallExpiryValidator{}
+	v.Reset()
+	return v
+}
+
+// WithToolCallExpiryValidation makes the sequence validator also report tool
+// calls whose result did not arrive before their deadline
+func WithToolCallExpiryValidation() SequenceValidatorOption {
+	return func(v *EventSequenceValidator) {
+		v.modules = append(v.modules, NewToolCallExpiryValidator())
+	}
+}
+
+// Reset clears all tracked state and findings
+func (v *ToolCallExpiryValidator) Reset() {
+	v.index = 0
+	v.pending = make(map[string]expiringToolCall)
+	v.result = &SequenceValidationResult{FailOn: SeverityError}
+}
+
+// Result returns the findings collected so far
+func (v *ToolCallExpiryValidator) Result() *SequenceValidationResult {
+	return v.result
+}
+
+// ValidateEvent validates the next event in the sequence and returns the
+// findings it produced
+func (v *ToolCallExpiryValidator) ValidateEvent(event Event) []ValidationFinding {
+	index := v.index
+	v.index++
+	return validateModuleEvent(v, event, index, v.result)
+}
+
+// Finish reports the tool calls with a deadline that never received a
+// result. It should be called once after the last event of the run has been
+// validated.
+func (v *ToolCallExpiryValidator) Finish() []ValidationFinding {
+	findings := v.finish()
+	for _, finding := range findings {
+		v.result.add(finding)
+	}
+	return findings
+}
+
+// check tracks tool calls with a deadline and reports late results
+func (v *ToolCallExpiryValidator) check(event Event, index int, report findingReporter) {
+	switch e := event.(type) {
+	case *ToolCallStartEvent:
+		if e.ExpiresAt != nil {
+			v.pending[e.ToolCallID] = expiringToolCall{index: index, deadline: *e.ExpiresAt}
+		}
+
+	case *ToolCallResultEvent:
+		call, ok := v.pending[e.ToolCallID]
+		if !ok {
+			return
+		}
+		delete(v.pending, e.ToolCallID)
+
+		if ts := e.Timestamp(); ts != nil {
+			arrived := time.UnixMilli(*ts)
+			if !arrived.Before(call.deadline) {
+				report(SeverityError, RuleToolCallExpired, nil,
+					"result of tool call %s arrived %s after its deadline", e.ToolCallID, arrived.Sub(call.deadline))
+			}
+		}
+	}
+}
+
+// finish reports the tool calls still awaiting their result, in the order
+// they started
+func (v *ToolCallExpiryValidator) finish() []ValidationFinding {
+	ids := make([]string, 0, len(v.pending))
+	for id := range v.pending {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return v.pending[ids[i]].index < v.pending[ids[j]].index
+	})
+
+	var findings []ValidationFinding
+	for _, id := range ids {
+		call := v.pending[id]
+		findings = append(findings, ValidationFinding{
+			Severity:   SeverityError,
+			Rule:       RuleToolCallExpired,
+			Message:    fmt.Sprintf("tool call %s expired at %s without a result", id, call.deadline.Format(time.RFC3339)),
+			EventIndex: call.index,
+			EventType:  EventTypeToolCallStart,
+		})
+	}
+	return findings
+}
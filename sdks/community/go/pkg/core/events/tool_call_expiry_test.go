@@ -0,0 +1,117 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolCallExpiresAt(t *testing.T) {
+	deadline := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	start := NewToolCallStartEvent("call-1", "search", WithExpiresAt(deadline))
+
+	t.Run("IsExpired", func(t *testing.T) {
+		assert.False(t, start.IsExpired(deadline.Add(-time.Millisecond)))
+		assert.True(t, start.IsExpired(deadline))
+		assert.True(t, start.IsExpired(deadline.Add(time.Hour)))
+		assert.False(t, NewToolCallStartEvent("call-2", "search").IsExpired(deadline.Add(time.Hour)))
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		data, err := start.ToJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"expiresAt":"2026-03-01T12:00:00Z"`)
+
+		decoded, err := NewEventDecoder(nil).DecodeEvent(string(EventTypeToolCallStart), data)
+		require.NoError(t, err)
+		require.NotNil(t, decoded.(*ToolCallStartEvent).ExpiresAt)
+		assert.True(t, deadline.Equal(*decoded.(*ToolCallStartEvent).ExpiresAt))
+
+		data, err = NewToolCallStartEvent("call-2", "search").ToJSON()
+		require.NoError(t, err)
+		var fields map[string]any
+		require.NoError(t, json.Unmarshal(data, &fields))
+		assert.NotContains(t, fields, "expiresAt")
+	})
+
+	t.Run("Clone", func(t *testing.T) {
+		clone := start.Clone()
+		*clone.ExpiresAt = deadline.Add(time.Hour)
+		assert.Equal(t, deadline, *start.ExpiresAt)
+	})
+}
+
+func TestToolCallExpiryValidator(t *testing.T) {
+	deadline := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	result := func(toolCallID string, at time.Time) *ToolCallResultEvent {
+		event := NewToolCallResultEvent("msg-"+toolCallID, toolCallID, "done")
+		event.SetTimestamp(at.UnixMilli())
+		return event
+	}
+	run := func(events ...Event) *SequenceValidationResult {
+		validator := NewToolCallExpiryValidator()
+		for _, event := range events {
+			validator.ValidateEvent(event)
+		}
+		validator.Finish()
+		return validator.Result()
+	}
+
+	t.Run("Resolved", func(t *testing.T) {
+		res := run(
+			NewToolCallStartEvent("call-1", "search", WithExpiresAt(deadline)),
+			NewToolCallEndEvent("call-1"),
+			result("call-1", deadline.Add(-time.Second)),
+			// Tool calls without a deadline never expire
+			NewToolCallStartEvent("call-2", "search"),
+			// Results without a timestamp are assumed to be on time
+			NewToolCallResultEvent("msg-3", "call-3", "done"),
+		)
+		assert.False(t, res.Failed)
+		assert.Empty(t, res.Errors)
+	})
+
+	t.Run("LateResult", func(t *testing.T) {
+		res := run(
+			NewToolCallStartEvent("call-1", "search", WithExpiresAt(deadline)),
+			NewToolCallEndEvent("call-1"),
+			result("call-1", deadline.Add(1500*time.Millisecond)),
+		)
+		require.Len(t, res.Errors, 1)
+		assert.Equal(t, RuleToolCallExpired, res.Errors[0].Rule)
+		assert.Equal(t, 2, res.Errors[0].EventIndex)
+		assert.Equal(t, EventTypeToolCallResult, res.Errors[0].EventType)
+		assert.Contains(t, res.Errors[0].Message, "1.5s after its deadline")
+	})
+
+	t.Run("NeverResolved", func(t *testing.T) {
+		res := run(
+			NewToolCallStartEvent("call-b", "search", WithExpiresAt(deadline)),
+			NewToolCallStartEvent("call-a", "search", WithExpiresAt(deadline)),
+			result("call-c", deadline),
+		)
+		require.Len(t, res.Errors, 2)
+		assert.Equal(t, 0, res.Errors[0].EventIndex)
+		assert.Contains(t, res.Errors[0].Message, "call-b")
+		assert.Equal(t, 1, res.Errors[1].EventIndex)
+		assert.Contains(t, res.Errors[1].Message, "call-a")
+	})
+
+	t.Run("SequenceValidatorModule", func(t *testing.T) {
+		events := []Event{
+			NewRunStartedEvent("thread-1", "run-1"),
+			NewToolCallStartEvent("call-1", "search", WithExpiresAt(deadline)),
+			NewToolCallEndEvent("call-1"),
+			NewRunFinishedEvent("thread-1", "run-1"),
+		}
+		assert.False(t, ValidateSequenceWithResult(events).Failed)
+
+		res := ValidateSequenceWithResult(events, WithToolCallExpiryValidation(), WithThinkingBlockValidation())
+		require.Len(t, res.Errors, 1)
+		assert.Equal(t, RuleToolCallExpired, res.Errors[0].Rule)
+		assert.Equal(t, 1, res.Errors[0].EventIndex)
+	})
+}
@@ -0,0 +1,297 @@
+package events
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultToolCallLatencyRetention is how long a ToolCallLatencyMiddleware
+// keeps the entries of a tool call by default
+const DefaultToolCallLatencyRetention = 10 * time.Minute
+
+// ToolCallLatencyStat summarizes the latency of the calls to a tool
+type ToolCallLatencyStat struct {
+	ToolName string
+	Calls    int
+	Average  time.Duration
+	Max      time.Duration
+}
+
+// ToolCallLatencyMiddleware measures the latency of tool calls: the time
+// from their TOOL_CALL_START event to their TOOL_CALL_END event, or to their
+// TOOL_CALL_RESULT if it arrives first. The time of an event is its
+// timestamp, or the time at which it is processed if it has none.
+//
+// An end or result may be processed before the start of its tool call, as
+// when events are merged from several streams. It is then held until the
+// start arrives, and a latency that would be negative is recorded as zero.
+//
+// The entries of a tool call are evicted once the retention period has
+// passed since it was last processed: starts without an end, ends without a
+// start, and the latencies of completed calls, which Latency then no longer
+// reports. The statistics of the tools are kept. It is safe for concurrent
+// use.
+type ToolCallLatencyMiddleware struct {
+	mu        sync.Mutex
+	clock     EventClock
+	retention time.Duration
+	lastSweep time.Time
+
+	// started holds the tool calls awaiting their end
+	started map[string]toolCallStart
+
+	// ended holds the ends received before the start of their tool call
+	ended map[string]heldToolCallEnd
+
+	latencies map[string]toolCallLatency
+	tools     map[string]*toolLatency
+}
+
+// toolLatency accumulates the latencies of the calls to a tool
+type toolLatency struct {
+	calls int
+	total time.Duration
+	max   time.Duration
+}
+
+// stat returns the statistics of a tool
+func (l *toolLatency) stat(name string) ToolCallLatencyStat {
+	return ToolCallLatencyStat{
+		ToolName: name,
+		Calls:    l.calls,
+		Average:  l.total / time.Duration(l.calls),
+		Max:      l.max,
+	}
+}
+
+// toolCallStart is the start of a tool call awaiting its end, processed at
+// seen
+type toolCallStart struct {
+	name string
+	at   time.Time
+	seen time.Time
+}
+
+// heldToolCallEnd is the end of a tool call awaiting its start, processed at
+// seen
+type heldToolCallEnd struct {
+	at   time.Time
+	seen time.Time
+}
+
+// toolCallLatency is the latency of a completed tool call, whose last event
+// was processed at seen
+type toolCallLatency struct {
+	latency time.Duration
+	seen    time.Time
+}
+
+// ToolCallLatencyOption defines options for creating tool call latency
+// middlewares
+type ToolCallLatencyOption func(*ToolCallLatencyMiddleware)
+
+// WithLatencyRetention sets how long the entries of a tool call are kept
+// after its last event. Zero keeps them for the lifetime of the middleware.
+func WithLatencyRetention(d time.Duration) ToolCallLatencyOption {
+	return func(m *ToolCallLatencyMiddleware) {
+		m.retention = d
+	}
+}
+
+// NewToolCallLatencyMiddleware creates a tool call latency middleware
+// reading the time from clock, or from time.Now if clock is nil, and
+// keeping entries for DefaultToolCallLatencyRetention unless configured
+// otherwise
+func NewToolCallLatencyMiddleware(clock EventClock, opts ...ToolCallLatencyOption) *ToolCallLatencyMiddleware {
+	if clock == nil {
+		clock = time.Now
+	}
+	m := &ToolCallLatencyMiddleware{
+		clock:     clock,
+		retention: DefaultToolCallLatencyRetention,
+		started:   make(map[string]toolCallStart),
+		ended:     make(map[string]heldToolCallEnd),
+		latencies: make(map[string]toolCallLatency),
+		tools:     make(map[string]*toolLatency),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	m.lastSweep = clock()
+	return m
+}
+
+// Process records an event. Events other than tool call starts, ends and
+// results are ignored, as are the ends and results of tool calls whose
+// latency is already known.
+func (m *ToolCallLatencyMiddleware) Process(e Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.clock()
+	m.sweep(now)
+
+	switch event := e.(type) {
+	case *ToolCallStartEvent:
+		id := event.ToolCallID
+		if m.completed(id, now) {
+			return
+		}
+		start := toolCallStart{name: event.ToolCallName, at: m.eventTime(e, now), seen: now}
+		if end, ok := m.ended[id]; ok {
+			delete(m.ended, id)
+			m.record(id, start, end.at, now)
+			return
+		}
+		m.started[id] = start
+
+	case *ToolCallEndEvent:
+		m.end(event.ToolCallID, m.eventTime(e, now), now)
+
+	case *ToolCallResultEvent:
+		m.end(event.ToolCallID, m.eventTime(e, now), now)
+	}
+}
+
+// Middleware returns an EventMiddleware recording every event before
+// handing it on
+func (m *ToolCallLatencyMiddleware) Middleware() EventMiddleware {
+	return func(next EventHandler) EventHandler {
+		return EventHandlerFunc(func(ctx context.Context, e Event) error {
+			m.Process(e)
+			return next.HandleEvent(ctx, e)
+		})
+	}
+}
+
+// Stage returns the middleware as an EventStage passing every event on
+// unchanged
+func (m *ToolCallLatencyMiddleware) Stage() EventStage {
+	return infallibleStage{process: func(e Event) []Event {
+		m.Process(e)
+		return []Event{e}
+	}}
+}
+
+// end records the end of a tool call. The caller must hold the lock.
+func (m *ToolCallLatencyMiddleware) end(id string, at, now time.Time) {
+	if m.completed(id, now) {
+		return
+	}
+	start, ok := m.started[id]
+	if !ok {
+		if _, held := m.ended[id]; !held {
+			m.ended[id] = heldToolCallEnd{at: at, seen: now}
+		}
+		return
+	}
+	delete(m.started, id)
+	m.record(id, start, at, now)
+}
+
+// completed reports whether the latency of a tool call is known, and keeps
+// it for another retention period if so. The caller must hold the lock.
+func (m *ToolCallLatencyMiddleware) completed(id string, now time.Time) bool {
+	entry, ok := m.latencies[id]
+	if ok {
+		entry.seen = now
+		m.latencies[id] = entry
+	}
+	return ok
+}
+
+// sweep evicts the entries whose retention period has passed, at most once
+// per period. The caller must hold the lock.
+func (m *ToolCallLatencyMiddleware) sweep(now time.Time) {
+	if m.retention <= 0 || now.Sub(m.lastSweep) < m.retention {
+		return
+	}
+	m.lastSweep = now
+
+	cutoff := now.Add(-m.retention)
+	for id, start := range m.started {
+		if !start.seen.After(cutoff) {
+			delete(m.started, id)
+		}
+	}
+	for id, end := range m.ended {
+		if !end.seen.After(cutoff) {
+			delete(m.ended, id)
+		}
+	}
+	for id, entry := range m.latencies {
+		if !entry.seen.After(cutoff) {
+			delete(m.latencies, id)
+		}
+	}
+}
+
+// record stores the latency of a completed tool call. The caller must hold
+// the lock.
+func (m *ToolCallLatencyMiddleware) record(id string, start toolCallStart, end, now time.Time) {
+	latency := max(end.Sub(start.at), 0)
+	m.latencies[id] = toolCallLatency{latency: latency, seen: now}
+
+	tool, ok := m.tools[start.name]
+	if !ok {
+		tool = &toolLatency{}
+		m.tools[start.name] = tool
+	}
+	tool.calls++
+	tool.total += latency
+	tool.max = max(tool.max, latency)
+}
+
+// eventTime returns the timestamp of an event, or now if it has none
+func (m *ToolCallLatencyMiddleware) eventTime(e Event, now time.Time) time.Time {
+	if ts := e.Timestamp(); ts != nil {
+		return time.UnixMilli(*ts)
+	}
+	return now
+}
+
+// Latency returns the latency of a completed tool call, and false if the
+// tool call is unknown, has not ended, or has been evicted
+func (m *ToolCallLatencyMiddleware) Latency(toolCallID string) (time.Duration, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.latencies[toolCallID]
+	return entry.latency, ok
+}
+
+// AvgLatency returns the average latency of the completed calls to a tool,
+// or zero if there are none
+func (m *ToolCallLatencyMiddleware) AvgLatency(toolName string) time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if tool, ok := m.tools[toolName]; ok {
+		return tool.stat(toolName).Average
+	}
+	return 0
+}
+
+// TopSlowTools returns the statistics of the n tools with the highest
+// average latency, slowest first, or of every tool if there are fewer
+func (m *ToolCallLatencyMiddleware) TopSlowTools(n int) []ToolCallLatencyStat {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make([]ToolCallLatencyStat, 0, len(m.tools))
+	for name, tool := range m.tools {
+		stats = append(stats, tool.stat(name))
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Average != stats[j].Average {
+			return stats[i].Average > stats[j].Average
+		}
+		return stats[i].ToolName < stats[j].ToolName
+	})
+	if n < len(stats) {
+		stats = stats[:max(n, 0)]
+	}
+	return stats
+}
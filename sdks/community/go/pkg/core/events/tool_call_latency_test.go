@@ -0,0 +1,168 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolCallLatencyMiddleware(t *testing.T) {
+	t.Run("MeasuresLatency", func(t *testing.T) {
+		m := NewToolCallLatencyMiddleware(nil)
+		m.Process(timed(NewToolCallStartEvent("call-1", "search"), 1000))
+		m.Process(timed(NewToolCallArgsEvent("call-1", "{}"), 1100))
+
+		_, ok := m.Latency("call-1")
+		assert.False(t, ok)
+
+		m.Process(timed(NewToolCallEndEvent("call-1"), 1250))
+		latency, ok := m.Latency("call-1")
+		require.True(t, ok)
+		assert.Equal(t, 250*time.Millisecond, latency)
+
+		// The result of an ended tool call does not change its latency
+		m.Process(timed(NewToolCallResultEvent("msg-1", "call-1", "ok"), 2000))
+		latency, _ = m.Latency("call-1")
+		assert.Equal(t, 250*time.Millisecond, latency)
+
+		_, ok = m.Latency("call-2")
+		assert.False(t, ok)
+	})
+
+	t.Run("ResultEndsToolCall", func(t *testing.T) {
+		m := NewToolCallLatencyMiddleware(nil)
+		m.Process(timed(NewToolCallStartEvent("call-1", "search"), 1000))
+		m.Process(timed(NewToolCallResultEvent("msg-1", "call-1", "ok"), 1400))
+
+		latency, ok := m.Latency("call-1")
+		require.True(t, ok)
+		assert.Equal(t, 400*time.Millisecond, latency)
+	})
+
+	t.Run("EndBeforeStart", func(t *testing.T) {
+		m := NewToolCallLatencyMiddleware(nil)
+		m.Process(timed(NewToolCallEndEvent("call-1"), 1300))
+		_, ok := m.Latency("call-1")
+		assert.False(t, ok)
+
+		m.Process(timed(NewToolCallStartEvent("call-1", "search"), 1000))
+		latency, ok := m.Latency("call-1")
+		require.True(t, ok)
+		assert.Equal(t, 300*time.Millisecond, latency)
+		assert.Equal(t, 300*time.Millisecond, m.AvgLatency("search"))
+
+		// Without timestamps, the end is processed first and the latency
+		// is clamped to zero
+		now := time.Unix(1700000000, 0)
+		m = NewToolCallLatencyMiddleware(func() time.Time { return now })
+		end := NewToolCallEndEvent("call-2")
+		end.BaseEvent.TimestampMs = nil
+		m.Process(end)
+		now = now.Add(time.Second)
+		start := NewToolCallStartEvent("call-2", "search")
+		start.BaseEvent.TimestampMs = nil
+		m.Process(start)
+
+		latency, ok = m.Latency("call-2")
+		require.True(t, ok)
+		assert.Zero(t, latency)
+	})
+
+	t.Run("UsesClockWithoutTimestamps", func(t *testing.T) {
+		now := time.Unix(1700000000, 0)
+		m := NewToolCallLatencyMiddleware(func() time.Time { return now })
+		start := NewToolCallStartEvent("call-1", "search")
+		start.BaseEvent.TimestampMs = nil
+		m.Process(start)
+
+		now = now.Add(2 * time.Second)
+		end := NewToolCallEndEvent("call-1")
+		end.BaseEvent.TimestampMs = nil
+		m.Process(end)
+
+		latency, ok := m.Latency("call-1")
+		require.True(t, ok)
+		assert.Equal(t, 2*time.Second, latency)
+	})
+
+	t.Run("Statistics", func(t *testing.T) {
+		m := NewToolCallLatencyMiddleware(nil)
+		calls := []struct {
+			id, name   string
+			start, end int64
+		}{
+			{"call-1", "search", 0, 100},
+			{"call-2", "search", 0, 300},
+			{"call-3", "weather", 0, 500},
+			{"call-4", "calculator", 0, 10},
+			{"call-5", "lookup", 0, 200},
+		}
+		for _, call := range calls {
+			m.Process(timed(NewToolCallStartEvent(call.id, call.name), call.start))
+			m.Process(timed(NewToolCallEndEvent(call.id), call.end))
+		}
+		m.Process(timed(NewToolCallStartEvent("call-6", "weather"), 0))
+
+		assert.Equal(t, 200*time.Millisecond, m.AvgLatency("search"))
+		assert.Equal(t, 500*time.Millisecond, m.AvgLatency("weather"))
+		assert.Zero(t, m.AvgLatency("unknown"))
+
+		top := m.TopSlowTools(3)
+		require.Len(t, top, 3)
+		assert.Equal(t, ToolCallLatencyStat{ToolName: "weather", Calls: 1, Average: 500 * time.Millisecond, Max: 500 * time.Millisecond}, top[0])
+		assert.Equal(t, ToolCallLatencyStat{ToolName: "lookup", Calls: 1, Average: 200 * time.Millisecond, Max: 200 * time.Millisecond}, top[1])
+		assert.Equal(t, ToolCallLatencyStat{ToolName: "search", Calls: 2, Average: 200 * time.Millisecond, Max: 300 * time.Millisecond}, top[2])
+
+		assert.Len(t, m.TopSlowTools(10), 4)
+		assert.Empty(t, m.TopSlowTools(0))
+		assert.Empty(t, m.TopSlowTools(-1))
+	})
+
+	t.Run("EvictsStaleEntries", func(t *testing.T) {
+		now := time.Unix(1700000000, 0)
+		m := NewToolCallLatencyMiddleware(func() time.Time { return now }, WithLatencyRetention(time.Minute))
+		m.Process(timed(NewToolCallStartEvent("call-1", "search"), 1000))
+		m.Process(timed(NewToolCallEndEvent("call-1"), 1200))
+		m.Process(timed(NewToolCallStartEvent("abandoned", "search"), 1000))
+		m.Process(timed(NewToolCallEndEvent("orphan"), 1000))
+
+		now = now.Add(time.Minute)
+		m.Process(NewRunFinishedEvent("thread-1", "run-1"))
+		_, ok := m.Latency("call-1")
+		assert.False(t, ok)
+		assert.Empty(t, m.started)
+		assert.Empty(t, m.ended)
+		assert.Empty(t, m.latencies)
+
+		// The statistics outlive the evicted tool calls
+		assert.Equal(t, 200*time.Millisecond, m.AvgLatency("search"))
+	})
+
+	t.Run("Middleware", func(t *testing.T) {
+		m := NewToolCallLatencyMiddleware(nil)
+		var handled []Event
+		handler := m.Middleware()(EventHandlerFunc(func(ctx context.Context, e Event) error {
+			handled = append(handled, e)
+			return nil
+		}))
+		start := timed(NewToolCallStartEvent("call-1", "search"), 1000)
+		end := timed(NewToolCallEndEvent("call-1"), 1100)
+		require.NoError(t, handler.HandleEvent(context.Background(), start))
+		require.NoError(t, handler.HandleEvent(context.Background(), end))
+		assert.Equal(t, []Event{start, end}, handled)
+
+		latency, ok := m.Latency("call-1")
+		require.True(t, ok)
+		assert.Equal(t, 100*time.Millisecond, latency)
+
+		m = NewToolCallLatencyMiddleware(nil)
+		out, err := ApplyStages([]Event{start, end}, m.Stage())
+		require.NoError(t, err)
+		assert.Equal(t, []Event{start, end}, out)
+		_, ok = m.Latency("call-1")
+		assert.True(t, ok)
+	})
+}
@@ -0,0 +1,141 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EventClock returns the current time. time.Now is the usual clock; tests
+// substitute a controllable one.
+type EventClock func() time.Time
+
+// ToolCallTimeoutMiddleware watches an event stream for tool calls that
+// exceed the time budget set with WithTimeoutMs, and emits a timeout
+// TOOL_CALL_RESULT for each of them. The budget runs from the moment the
+// TOOL_CALL_START event is processed until its result arrives. It is safe
+// for concurrent use, so Expired can be polled from a timer while events are
+// processed.
+type ToolCallTimeoutMiddleware struct {
+	mu      sync.Mutex
+	clock   EventClock
+	pending map[string]toolCallBudget
+
+	// timedOut holds the tool calls already reported, whose late results
+	// are dropped
+	timedOut map[string]bool
+}
+
+// toolCallBudget is the time budget of a tool call awaiting its result
+type toolCallBudget struct {
+	deadline time.Time
+	timeout  time.Duration
+}
+
+// NewToolCallTimeoutMiddleware creates a tool call timeout middleware reading
+// the time from clock, or from time.Now if clock is nil
+func NewToolCallTimeoutMiddleware(clock EventClock) *ToolCallTimeoutMiddleware {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &ToolCallTimeoutMiddleware{
+		clock:    clock,
+		pending:  make(map[string]toolCallBudget),
+		timedOut: make(map[string]bool),
+	}
+}
+
+// Process records an event and returns the events to emit in its place:
+// timeout results for the tool calls whose budget has run out, followed by
+// the event itself. A result for a tool call that was already reported as
+// timed out is dropped, so that every tool call has exactly one result.
+func (m *ToolCallTimeoutMiddleware) Process(e Event) []Event {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := m.expire()
+
+	switch event := e.(type) {
+	case *ToolCallStartEvent:
+		if event.TimeoutMs != nil && *event.TimeoutMs > 0 {
+			timeout := time.Duration(*event.TimeoutMs) * time.Millisecond
+			m.pending[event.ToolCallID] = toolCallBudget{deadline: m.clock().Add(timeout), timeout: timeout}
+		}
+
+	case *ToolCallResultEvent:
+		if m.timedOut[event.ToolCallID] {
+			delete(m.timedOut, event.ToolCallID)
+			return result
+		}
+		delete(m.pending, event.ToolCallID)
+	}
+
+	return append(result, e)
+}
+
+// Expired returns timeout results for the tool calls whose budget has run
+// out since the last call, for streams that may stall while a tool runs
+func (m *ToolCallTimeoutMiddleware) Expired() []Event {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.expire()
+}
+
+// Middleware returns an EventMiddleware handing on the events Process
+// returns for each event: timeout results ahead of the event, and nothing
+// for a late result. Expired results that no event follows must still be
+// polled with Expired.
+func (m *ToolCallTimeoutMiddleware) Middleware() EventMiddleware {
+	return func(next EventHandler) EventHandler {
+		return EventHandlerFunc(func(ctx context.Context, e Event) error {
+			for _, out := range m.Process(e) {
+				if err := next.HandleEvent(ctx, out); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// Stage returns the middleware as an EventStage, whose Flush emits timeout
+// results for the tool calls whose budget has run out by the end of the
+// stream
+func (m *ToolCallTimeoutMiddleware) Stage() EventStage {
+	return infallibleStage{process: m.Process, flush: m.Expired}
+}
+
+// expire removes the overdue tool calls and returns their timeout results,
+// in deadline order. The caller must hold the lock.
+func (m *ToolCallTimeoutMiddleware) expire() []Event {
+	now := m.clock()
+
+	var overdue []string
+	for id, call := range m.pending {
+		if !now.Before(call.deadline) {
+			overdue = append(overdue, id)
+		}
+	}
+	sort.Slice(overdue, func(i, j int) bool {
+		a, b := m.pending[overdue[i]], m.pending[overdue[j]]
+		if !a.deadline.Equal(b.deadline) {
+			return a.deadline.Before(b.deadline)
+		}
+		return overdue[i] < overdue[j]
+	})
+
+	result := make([]Event, 0, len(overdue))
+	for _, id := range overdue {
+		call := m.pending[id]
+		delete(m.pending, id)
+		m.timedOut[id] = true
+
+		content := fmt.Sprintf("%s after %s", ToolCallTimeoutContent, call.timeout)
+		event := NewToolCallResultEvent(GenerateMessageID(), id, content, WithTimeout())
+		event.SetTimestamp(now.UnixMilli())
+		result = append(result, event)
+	}
+	return result
+}
@@ -0,0 +1,118 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolCallTimeout(t *testing.T) {
+	t.Run("Options", func(t *testing.T) {
+		start := NewToolCallStartEvent("call-1", "search", WithTimeoutMs(1500))
+		require.NotNil(t, start.TimeoutMs)
+		assert.Equal(t, int64(1500), *start.TimeoutMs)
+
+		data, err := start.ToJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"timeoutMs":1500`)
+
+		result := NewToolCallResultEvent("msg-1", "call-1", "", WithTimeout())
+		assert.True(t, result.IsTimeout)
+		assert.Equal(t, ToolCallTimeoutContent, result.Content)
+		assert.NoError(t, result.Validate())
+
+		custom := NewToolCallResultEvent("msg-1", "call-1", "search took too long", WithTimeout())
+		assert.Equal(t, "search took too long", custom.Content)
+
+		data, err = result.ToJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"isTimeout":true`)
+		data, err = NewToolCallResultEvent("msg-1", "call-1", "ok").ToJSON()
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "isTimeout")
+	})
+
+	now := time.Unix(1700000000, 0)
+	clock := func() time.Time { return now }
+
+	t.Run("EmitsTimeoutResult", func(t *testing.T) {
+		m := NewToolCallTimeoutMiddleware(clock)
+		start := NewToolCallStartEvent("call-1", "search", WithTimeoutMs(1000))
+		assert.Equal(t, []Event{start}, m.Process(start))
+
+		now = now.Add(999 * time.Millisecond)
+		assert.Empty(t, m.Expired())
+
+		now = now.Add(time.Millisecond)
+		args := NewToolCallArgsEvent("call-2", "{}")
+		out := m.Process(args)
+		require.Len(t, out, 2)
+		assert.Same(t, args, out[1])
+
+		timeout, ok := out[0].(*ToolCallResultEvent)
+		require.True(t, ok)
+		assert.Equal(t, "call-1", timeout.ToolCallID)
+		assert.True(t, timeout.IsTimeout)
+		assert.Contains(t, timeout.Content, "1s")
+		assert.Equal(t, now.UnixMilli(), *timeout.Timestamp())
+		assert.NoError(t, timeout.Validate())
+
+		// The tool's own late result is dropped
+		assert.Empty(t, m.Process(NewToolCallResultEvent("msg-9", "call-1", "late")))
+		assert.Empty(t, m.Expired())
+	})
+
+	t.Run("ResultInTime", func(t *testing.T) {
+		m := NewToolCallTimeoutMiddleware(clock)
+		m.Process(NewToolCallStartEvent("call-1", "search", WithTimeoutMs(1000)))
+		now = now.Add(500 * time.Millisecond)
+
+		result := NewToolCallResultEvent("msg-1", "call-1", "done")
+		assert.Equal(t, []Event{result}, m.Process(result))
+
+		now = now.Add(time.Hour)
+		assert.Empty(t, m.Expired())
+	})
+
+	t.Run("PollingOrdersByDeadline", func(t *testing.T) {
+		m := NewToolCallTimeoutMiddleware(clock)
+		m.Process(NewToolCallStartEvent("slow", "a", WithTimeoutMs(300)))
+		m.Process(NewToolCallStartEvent("fast", "b", WithTimeoutMs(100)))
+		m.Process(NewToolCallStartEvent("unbounded", "c"))
+
+		now = now.Add(time.Second)
+		expired := m.Expired()
+		require.Len(t, expired, 2)
+		assert.Equal(t, "fast", expired[0].(*ToolCallResultEvent).ToolCallID)
+		assert.Equal(t, "slow", expired[1].(*ToolCallResultEvent).ToolCallID)
+	})
+
+	t.Run("Middleware", func(t *testing.T) {
+		var handled []Event
+		handler := NewToolCallTimeoutMiddleware(clock).Middleware()(
+			EventHandlerFunc(func(ctx context.Context, event Event) error {
+				handled = append(handled, event)
+				return nil
+			}),
+		)
+		handle := func(e Event) {
+			t.Helper()
+			require.NoError(t, handler.HandleEvent(context.Background(), e))
+		}
+
+		start := NewToolCallStartEvent("call-1", "search", WithTimeoutMs(100))
+		handle(start)
+		now = now.Add(time.Second)
+		end := NewToolCallEndEvent("call-1")
+		handle(end)
+		handle(NewToolCallResultEvent("msg-1", "call-1", "late"))
+
+		require.Len(t, handled, 3)
+		assert.Same(t, start, handled[0])
+		assert.True(t, handled[1].(*ToolCallResultEvent).IsTimeout)
+		assert.Same(t, end, handled[2])
+	})
+}
@@ -2,7 +2,8 @@ package events
 
 import (
 	"encoding/json"
-	"fmt"
+	"sort"
+	"time"
 )
 
 // ToolCallStartEvent indicates the start of a tool call
@@ -11,6 +12,12 @@ type ToolCallStartEvent struct {
 	ToolCallID      string  `json:"toolCallId"`
 	ToolCallName    string  `json:"toolCallName"`
 	ParentMessageID *string `json:"parentMessageId,omitempty"`
+
+	// TimeoutMs is the time budget of the tool call in milliseconds
+	TimeoutMs *int64 `json:"timeoutMs,omitempty"`
+
+	// ExpiresAt is the deadline by which the tool call's result must arrive
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
 }
 
 // NewToolCallStartEvent creates a new tool call start event
@@ -38,6 +45,22 @@ func WithParentMessageID(parentMessageID string) ToolCallStartOption {
 	}
 }
 
+// WithTimeoutMs sets the time budget of the tool call in milliseconds, after
+// which ToolCallTimeoutMiddleware reports it as timed out
+func WithTimeoutMs(ms int64) ToolCallStartOption {
+	return func(e *ToolCallStartEvent) {
+		e.TimeoutMs = &ms
+	}
+}
+
+// WithExpiresAt sets the deadline by which the result of the tool call must
+// arrive
+func WithExpiresAt(deadline time.Time) ToolCallStartOption {
+	return func(e *ToolCallStartEvent) {
+		e.ExpiresAt = &deadline
+	}
+}
+
 // WithAutoToolCallID automatically generates a unique tool call ID if the provided toolCallID is empty
 func WithAutoToolCallID() ToolCallStartOption {
 	return func(e *ToolCallStartEvent) {
@@ -54,11 +77,11 @@ func (e *ToolCallStartEvent) Validate() error {
 	}
 
 	if e.ToolCallID == "" {
-		return fmt.Errorf("ToolCallStartEvent validation failed: toolCallId field is required")
+		return newValidationError(EventTypeToolCallStart, "toolCallId", "toolCallId field is required")
 	}
 
 	if e.ToolCallName == "" {
-		return fmt.Errorf("ToolCallStartEvent validation failed: toolCallName field is required")
+		return newValidationError(EventTypeToolCallStart, "toolCallName", "toolCallName field is required")
 	}
 
 	return nil
@@ -69,6 +92,42 @@ func (e *ToolCallStartEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// String returns a concise human-readable summary of the event
+func (e *ToolCallStartEvent) String() string {
+	fields := []string{summaryField("tool", e.ToolCallID), summaryField("name", e.ToolCallName)}
+	fields = appendOptionalField(fields, "parent", e.ParentMessageID)
+	return formatEvent(EventTypeToolCallStart, fields...)
+}
+
+// Clone returns a deep copy of the event
+func (e *ToolCallStartEvent) Clone() *ToolCallStartEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	clone.ParentMessageID = cloneString(e.ParentMessageID)
+	clone.TimeoutMs = cloneInt64(e.TimeoutMs)
+	clone.ExpiresAt = cloneTime(e.ExpiresAt)
+	return &clone
+}
+
+// IsExpired reports whether the tool call has a deadline and it has passed
+// at now
+func (e *ToolCallStartEvent) IsExpired(now time.Time) bool {
+	return e.ExpiresAt != nil && !now.Before(*e.ExpiresAt)
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *ToolCallStartEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *ToolCallStartEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeToolCallStart, e)
+}
+
 // ToolCallArgsEvent contains streaming tool call arguments
 type ToolCallArgsEvent struct {
 	*BaseEvent
@@ -119,11 +178,11 @@ func (e *ToolCallArgsEvent) Validate() error {
 	}
 
 	if e.ToolCallID == "" {
-		return fmt.Errorf("ToolCallArgsEvent validation failed: toolCallId field is required")
+		return newValidationError(EventTypeToolCallArgs, "toolCallId", "toolCallId field is required")
 	}
 
 	if e.Delta == "" {
-		return fmt.Errorf("ToolCallArgsEvent validation failed: delta field is required")
+		return newValidationError(EventTypeToolCallArgs, "delta", "delta field is required")
 	}
 
 	return nil
@@ -134,6 +193,31 @@ func (e *ToolCallArgsEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// String returns a concise human-readable summary of the event
+func (e *ToolCallArgsEvent) String() string {
+	return formatEvent(EventTypeToolCallArgs, summaryField("tool", e.ToolCallID), summaryText("delta", e.Delta))
+}
+
+// Clone returns a deep copy of the event
+func (e *ToolCallArgsEvent) Clone() *ToolCallArgsEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *ToolCallArgsEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *ToolCallArgsEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeToolCallArgs, e)
+}
+
 // ToolCallEndEvent indicates the end of a tool call
 type ToolCallEndEvent struct {
 	*BaseEvent
@@ -181,7 +265,7 @@ func (e *ToolCallEndEvent) Validate() error {
 	}
 
 	if e.ToolCallID == "" {
-		return fmt.Errorf("ToolCallEndEvent validation failed: toolCallId field is required")
+		return newValidationError(EventTypeToolCallEnd, "toolCallId", "toolCallId field is required")
 	}
 
 	return nil
@@ -192,6 +276,31 @@ func (e *ToolCallEndEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// String returns a concise human-readable summary of the event
+func (e *ToolCallEndEvent) String() string {
+	return formatEvent(EventTypeToolCallEnd, summaryField("tool", e.ToolCallID))
+}
+
+// Clone returns a deep copy of the event
+func (e *ToolCallEndEvent) Clone() *ToolCallEndEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *ToolCallEndEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *ToolCallEndEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeToolCallEnd, e)
+}
+
 // ToolCallResultEvent represents the result of a tool call execution
 type ToolCallResultEvent struct {
 	*BaseEvent
@@ -199,18 +308,116 @@ type ToolCallResultEvent struct {
 	ToolCallID string  `json:"toolCallId"`
 	Content    string  `json:"content"`
 	Role       *string `json:"role,omitempty"`
+	Priority   *int    `json:"priority,omitempty"`
+
+	// IsTimeout marks the result of a tool call that exceeded its time
+	// budget
+	IsTimeout bool `json:"isTimeout,omitempty"`
+
+	// CacheKey identifies the cached result, CacheHit tells whether the
+	// result was served from the cache, and CacheTTLSeconds how long it
+	// stays cached, so that clients can show a cached result indicator
+	CacheKey        *string `json:"cacheKey,omitempty"`
+	CacheHit        *bool   `json:"cacheHit,omitempty"`
+	CacheTTLSeconds *int64  `json:"cacheTtlSeconds,omitempty"`
+
+	// StreamingHint asks for the result to be re-streamed as text message
+	// content, see StreamResult
+	StreamingHint *StreamingHint `json:"streamingHint,omitempty"`
 }
 
+// ToolCallTimeoutContent is the content of timeout results created without
+// content of their own
+const ToolCallTimeoutContent = "Error: tool call timed out"
+
 // NewToolCallResultEvent creates a new tool call result event
-func NewToolCallResultEvent(messageID, toolCallID, content string) *ToolCallResultEvent {
-	role := "tool"
-	return &ToolCallResultEvent{
+func NewToolCallResultEvent(messageID, toolCallID, content string, options ...ToolCallResultOption) *ToolCallResultEvent {
+	role := RoleTool
+	event := &ToolCallResultEvent{
 		BaseEvent:  NewBaseEvent(EventTypeToolCallResult),
 		MessageID:  messageID,
 		ToolCallID: toolCallID,
 		Content:    content,
 		Role:       &role,
 	}
+
+	for _, opt := range options {
+		opt(event)
+	}
+
+	return event
+}
+
+// ToolCallResultOption defines options for creating tool call result events
+type ToolCallResultOption func(*ToolCallResultEvent)
+
+// WithPriority sets the display priority of the tool call result. Lower
+// values are displayed first; 0 is the default priority.
+func WithPriority(priority int) ToolCallResultOption {
+	return func(e *ToolCallResultEvent) {
+		e.Priority = &priority
+	}
+}
+
+// WithTimeout marks the result as the outcome of a timed-out tool call. If
+// the result has no content, it is set to ToolCallTimeoutContent.
+func WithTimeout() ToolCallResultOption {
+	return func(e *ToolCallResultEvent) {
+		e.IsTimeout = true
+		if e.Content == "" {
+			e.Content = ToolCallTimeoutContent
+		}
+	}
+}
+
+// WithCacheKey sets the key under which the tool result is cached
+func WithCacheKey(key string) ToolCallResultOption {
+	return func(e *ToolCallResultEvent) {
+		e.CacheKey = &key
+	}
+}
+
+// WithCacheHit records whether the tool result was served from the cache
+func WithCacheHit(hit bool) ToolCallResultOption {
+	return func(e *ToolCallResultEvent) {
+		e.CacheHit = &hit
+	}
+}
+
+// WithCacheTTL sets how long the tool result stays cached. The duration is
+// sent in whole seconds, rounded up so that a short TTL is not sent as zero.
+func WithCacheTTL(d time.Duration) ToolCallResultOption {
+	return func(e *ToolCallResultEvent) {
+		seconds := int64(d / time.Second)
+		if d%time.Second > 0 {
+			seconds++
+		}
+		e.CacheTTLSeconds = &seconds
+	}
+}
+
+// WithStreamingHint sets how the result is re-streamed as text message
+// content by StreamResult
+func WithStreamingHint(hint StreamingHint) ToolCallResultOption {
+	return func(e *ToolCallResultEvent) {
+		e.StreamingHint = &hint
+	}
+}
+
+// SortByPriority returns the results ordered by priority, lowest value first.
+// Results without a priority are placed after all prioritized results, and
+// results with equal priority keep their original order. The input slice is
+// not modified.
+func SortByPriority(results []*ToolCallResultEvent) []*ToolCallResultEvent {
+	sorted := append([]*ToolCallResultEvent(nil), results...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].Priority, sorted[j].Priority
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return *a < *b
+	})
+	return sorted
 }
 
 // Validate validates the tool call result event
@@ -220,15 +427,28 @@ func (e *ToolCallResultEvent) Validate() error {
 	}
 
 	if e.MessageID == "" {
-		return fmt.Errorf("ToolCallResultEvent validation failed: messageId field is required")
+		return newValidationError(EventTypeToolCallResult, "messageId", "messageId field is required")
 	}
 
 	if e.ToolCallID == "" {
-		return fmt.Errorf("ToolCallResultEvent validation failed: toolCallId field is required")
+		return newValidationError(EventTypeToolCallResult, "toolCallId", "toolCallId field is required")
 	}
 
 	if e.Content == "" {
-		return fmt.Errorf("ToolCallResultEvent validation failed: content field is required")
+		return newValidationError(EventTypeToolCallResult, "content", "content field is required")
+	}
+
+	if e.CacheTTLSeconds != nil && *e.CacheTTLSeconds < 0 {
+		return newValidationError(EventTypeToolCallResult, "cacheTtlSeconds", "cacheTtlSeconds field must not be negative")
+	}
+
+	if hint := e.StreamingHint; hint != nil {
+		if hint.ChunkSize < 0 {
+			return newValidationError(EventTypeToolCallResult, "streamingHint.chunkSize", "chunkSize must not be negative")
+		}
+		if hint.DelayMs < 0 {
+			return newValidationError(EventTypeToolCallResult, "streamingHint.delayMs", "delayMs must not be negative")
+		}
 	}
 
 	return nil
@@ -239,6 +459,43 @@ func (e *ToolCallResultEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// String returns a concise human-readable summary of the event
+func (e *ToolCallResultEvent) String() string {
+	return formatEvent(EventTypeToolCallResult,
+		summaryField("msg", e.MessageID),
+		summaryField("tool", e.ToolCallID),
+		summaryText("content", e.Content))
+}
+
+// Clone returns a deep copy of the event
+func (e *ToolCallResultEvent) Clone() *ToolCallResultEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	clone.Role = cloneString(e.Role)
+	clone.Priority = cloneInt(e.Priority)
+	clone.CacheKey = cloneString(e.CacheKey)
+	clone.CacheHit = cloneBool(e.CacheHit)
+	clone.CacheTTLSeconds = cloneInt64(e.CacheTTLSeconds)
+	if e.StreamingHint != nil {
+		hint := *e.StreamingHint
+		clone.StreamingHint = &hint
+	}
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *ToolCallResultEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *ToolCallResultEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeToolCallResult, e)
+}
+
 // ToolCallChunkEvent represents a chunk of tool call data
 type ToolCallChunkEvent struct {
 	*BaseEvent
@@ -287,13 +544,82 @@ func (e *ToolCallChunkEvent) Validate() error {
 
 	// At least one field should be present
 	if e.ToolCallID == nil && e.ToolCallName == nil && e.Delta == nil {
-		return fmt.Errorf("ToolCallChunkEvent validation failed: at least one of toolCallId, toolCallName, or delta must be present")
+		return newValidationError(EventTypeToolCallChunk, "", "at least one of toolCallId, toolCallName, or delta must be present")
 	}
 
 	return nil
 }
 
+// SplitToStartArgsEnd decomposes the chunk into the canonical start, args
+// and end events of a tool call, treating the chunk as a complete call. The
+// start event is omitted when the chunk has no tool call name and the args
+// event when it has no delta; the parent message ID is forwarded to the
+// start event. A chunk without a tool call ID yields no events. All events
+// carry the chunk's timestamp.
+func (e *ToolCallChunkEvent) SplitToStartArgsEnd() ([]*ToolCallStartEvent, []*ToolCallArgsEvent, []*ToolCallEndEvent) {
+	if e.ToolCallID == nil {
+		return nil, nil, nil
+	}
+	toolCallID := *e.ToolCallID
+
+	var starts []*ToolCallStartEvent
+	if e.ToolCallName != nil {
+		start := NewToolCallStartEvent(toolCallID, *e.ToolCallName)
+		start.ParentMessageID = cloneString(e.ParentMessageID)
+		start.TimestampMs = cloneInt64(e.TimestampMs)
+		starts = append(starts, start)
+	}
+
+	var args []*ToolCallArgsEvent
+	if e.Delta != nil && *e.Delta != "" {
+		arg := NewToolCallArgsEvent(toolCallID, *e.Delta)
+		arg.TimestampMs = cloneInt64(e.TimestampMs)
+		args = append(args, arg)
+	}
+
+	end := NewToolCallEndEvent(toolCallID)
+	end.TimestampMs = cloneInt64(e.TimestampMs)
+
+	return starts, args, []*ToolCallEndEvent{end}
+}
+
 // ToJSON serializes the event to JSON
 func (e *ToolCallChunkEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
+
+// String returns a concise human-readable summary of the event
+func (e *ToolCallChunkEvent) String() string {
+	var fields []string
+	fields = appendOptionalField(fields, "tool", e.ToolCallID)
+	fields = appendOptionalField(fields, "name", e.ToolCallName)
+	fields = appendOptionalField(fields, "parent", e.ParentMessageID)
+	if e.Delta != nil {
+		fields = append(fields, summaryText("delta", *e.Delta))
+	}
+	return formatEvent(EventTypeToolCallChunk, fields...)
+}
+
+// Clone returns a deep copy of the event
+func (e *ToolCallChunkEvent) Clone() *ToolCallChunkEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.BaseEvent = e.BaseEvent.copyBase()
+	clone.ToolCallID = cloneString(e.ToolCallID)
+	clone.ToolCallName = cloneString(e.ToolCallName)
+	clone.ParentMessageID = cloneString(e.ParentMessageID)
+	clone.Delta = cloneString(e.Delta)
+	return &clone
+}
+
+// MarshalBinary encodes the event in its binary form
+func (e *ToolCallChunkEvent) MarshalBinary() ([]byte, error) {
+	return marshalEventBinary(e)
+}
+
+// UnmarshalBinary decodes the event from its binary form
+func (e *ToolCallChunkEvent) UnmarshalBinary(data []byte) error {
+	return unmarshalEventBinary(data, EventTypeToolCallChunk, e)
+}
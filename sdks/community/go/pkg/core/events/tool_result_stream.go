@@ -0,0 +1,87 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrStreamingDisabled is returned by StreamResult for results without an
+// enabled streaming hint
+var ErrStreamingDisabled = errors.New("tool call result streaming is not enabled")
+
+// StreamingHint tells how a tool call result is re-streamed as text message
+// content, for clients that display long results as they would display a
+// streamed message
+type StreamingHint struct {
+	Enabled bool `json:"enabled"`
+
+	// ChunkSize is the number of characters of each content event. Zero
+	// sends the whole content in one event.
+	ChunkSize int `json:"chunkSize,omitempty"`
+
+	// DelayMs is the pause in milliseconds between content events
+	DelayMs int `json:"delayMs,omitempty"`
+}
+
+// StreamResult sends the content of the result to handler as a sequence of
+// TEXT_MESSAGE_CONTENT events for the message of the result, split and paced
+// as its streaming hint says. Chunks are split on character boundaries, so
+// each delta is valid UTF-8. The message start and end events are left to
+// the caller. Like DelayedEventEmitter, it emits to an EventHandler, so the
+// events can go through the same middleware as any other. It returns
+// ErrStreamingDisabled if the hint is missing or not enabled, the error of
+// ctx if it is done before the content is sent, and the first error of the
+// handler otherwise.
+func (e *ToolCallResultEvent) StreamResult(ctx context.Context, handler EventHandler) error {
+	hint := e.StreamingHint
+	if hint == nil || !hint.Enabled {
+		return ErrStreamingDisabled
+	}
+
+	chunks := splitRunes(e.Content, hint.ChunkSize)
+	delay := time.Duration(hint.DelayMs) * time.Millisecond
+	for i, chunk := range chunks {
+		if i > 0 && delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := handler.HandleEvent(ctx, NewTextMessageContentEvent(e.MessageID, chunk)); err != nil {
+			return fmt.Errorf("streaming tool call result %s: %w", e.ToolCallID, err)
+		}
+	}
+	return nil
+}
+
+// splitRunes splits s into chunks of size characters, the last one possibly
+// shorter. A size that is not positive gives s as a single chunk, and an
+// empty s gives no chunks.
+func splitRunes(s string, size int) []string {
+	if s == "" {
+		return nil
+	}
+	if size <= 0 {
+		return []string{s}
+	}
+
+	chunks := make([]string, 0, utf8.RuneCountInString(s)/size+1)
+	start, count := 0, 0
+	for i := range s {
+		if count == size {
+			chunks = append(chunks, s[start:i])
+			start, count = i, 0
+		}
+		count++
+	}
+	return append(chunks, s[start:])
+}
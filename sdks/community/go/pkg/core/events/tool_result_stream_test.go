@@ -0,0 +1,112 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collectDeltas streams the result and returns the message IDs and deltas of
+// the content events
+func collectDeltas(t *testing.T, ctx context.Context, result *ToolCallResultEvent) ([]string, error) {
+	t.Helper()
+	var deltas []string
+	err := result.StreamResult(ctx, EventHandlerFunc(func(ctx context.Context, e Event) error {
+		content, ok := e.(*TextMessageContentEvent)
+		require.True(t, ok, "unexpected event %s", e.Type())
+		require.NoError(t, content.Validate())
+		assert.Equal(t, result.MessageID, content.MessageID)
+		deltas = append(deltas, content.Delta)
+		return nil
+	}))
+	return deltas, err
+}
+
+func TestToolCallResultEvent_StreamResult(t *testing.T) {
+	t.Run("Chunks", func(t *testing.T) {
+		result := NewToolCallResultEvent("msg-1", "tool-1", "Sunny, 21°C in Paris",
+			WithStreamingHint(StreamingHint{Enabled: true, ChunkSize: 6}))
+		deltas, err := collectDeltas(t, context.Background(), result)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Sunny,", " 21°C ", "in Par", "is"}, deltas)
+	})
+
+	t.Run("SingleChunk", func(t *testing.T) {
+		result := NewToolCallResultEvent("msg-1", "tool-1", "done",
+			WithStreamingHint(StreamingHint{Enabled: true}))
+		deltas, err := collectDeltas(t, context.Background(), result)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"done"}, deltas)
+	})
+
+	t.Run("Delay", func(t *testing.T) {
+		result := NewToolCallResultEvent("msg-1", "tool-1", "abc",
+			WithStreamingHint(StreamingHint{Enabled: true, ChunkSize: 1, DelayMs: 10}))
+		start := time.Now()
+		deltas, err := collectDeltas(t, context.Background(), result)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c"}, deltas)
+		assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		result := NewToolCallResultEvent("msg-1", "tool-1", "abc",
+			WithStreamingHint(StreamingHint{Enabled: true, ChunkSize: 1, DelayMs: 1000}))
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		deltas, err := collectDeltas(t, ctx, result)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, []string{"a"}, deltas)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		handler := EventHandlerFunc(func(ctx context.Context, e Event) error {
+			t.Fatalf("unexpected event %s", e.Type())
+			return nil
+		})
+		result := NewToolCallResultEvent("msg-1", "tool-1", "done")
+		assert.ErrorIs(t, result.StreamResult(context.Background(), handler), ErrStreamingDisabled)
+
+		result = NewToolCallResultEvent("msg-1", "tool-1", "done", WithStreamingHint(StreamingHint{ChunkSize: 2}))
+		assert.ErrorIs(t, result.StreamResult(context.Background(), handler), ErrStreamingDisabled)
+	})
+
+	t.Run("HandlerError", func(t *testing.T) {
+		failure := errors.New("client gone")
+		calls := 0
+		result := NewToolCallResultEvent("msg-1", "tool-1", "abc",
+			WithStreamingHint(StreamingHint{Enabled: true, ChunkSize: 1}))
+		err := result.StreamResult(context.Background(), EventHandlerFunc(func(ctx context.Context, e Event) error {
+			calls++
+			return failure
+		}))
+		assert.ErrorIs(t, err, failure)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		result := NewToolCallResultEvent("msg-1", "tool-1", "done",
+			WithStreamingHint(StreamingHint{Enabled: true, ChunkSize: -1}))
+		assert.Error(t, result.Validate())
+
+		result = NewToolCallResultEvent("msg-1", "tool-1", "done",
+			WithStreamingHint(StreamingHint{Enabled: true, DelayMs: -1}))
+		assert.Error(t, result.Validate())
+	})
+
+	t.Run("JSONAndClone", func(t *testing.T) {
+		result := NewToolCallResultEvent("msg-1", "tool-1", "done",
+			WithStreamingHint(StreamingHint{Enabled: true, ChunkSize: 4, DelayMs: 25}))
+		data, err := result.ToJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"streamingHint":{"enabled":true,"chunkSize":4,"delayMs":25}`)
+
+		clone := result.Clone()
+		clone.StreamingHint.ChunkSize = 8
+		assert.Equal(t, 4, result.StreamingHint.ChunkSize)
+	})
+}
@@ -0,0 +1,466 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RenderFormat is the output format of RenderTranscript
+type RenderFormat int
+
+const (
+	// RenderMarkdown renders a transcript as Markdown, with tool calls and
+	// their results in fenced code blocks
+	RenderMarkdown RenderFormat = iota
+
+	// RenderPlainText renders a transcript as plain text, with tool calls
+	// and their results indented
+	RenderPlainText
+)
+
+// TranscriptOption configures RenderTranscript
+type TranscriptOption func(*transcriptBuilder)
+
+// WithThinking includes the content of thinking messages in the transcript,
+// instead of a note that it was elided
+func WithThinking() TranscriptOption {
+	return func(b *transcriptBuilder) {
+		b.thinking = true
+	}
+}
+
+// RenderTranscript renders the conversation of an event stream for humans,
+// such as support engineers pasting a run into a ticket. Messages are
+// rendered as paragraphs labeled with their role, tool calls with their
+// arguments and result, and run errors are highlighted. Thinking content is
+// elided unless WithThinking is given.
+//
+// Messages are taken from MESSAGES_SNAPSHOT events, a snapshot replacing
+// everything rendered before it, and from streamed events, which are
+// reconstructed with a MessageAccumulator and a ToolCallAccumulator and
+// rendered in the order they started. Messages that never end are rendered
+// as incomplete. It fails if the format is unknown or the streamed events
+// are inconsistent, such as content for a message that never started.
+func RenderTranscript(events []Event, format RenderFormat, options ...TranscriptOption) (string, error) {
+	if format != RenderMarkdown && format != RenderPlainText {
+		return "", fmt.Errorf("unknown render format %d", format)
+	}
+
+	b := newTranscriptBuilder()
+	for _, opt := range options {
+		opt(b)
+	}
+
+	events, err := ApplyStages(events, ExpandToolCallChunks())
+	if err != nil {
+		return "", err
+	}
+	for _, e := range events {
+		if err := b.process(e); err != nil {
+			return "", err
+		}
+	}
+	b.finish()
+
+	return b.render(format), nil
+}
+
+// transcriptEntryKind is the kind of a transcript entry
+type transcriptEntryKind int
+
+const (
+	transcriptMessage transcriptEntryKind = iota
+	transcriptToolCall
+	transcriptThinking
+	transcriptError
+)
+
+// transcriptEntry is a paragraph of a transcript
+type transcriptEntry struct {
+	kind transcriptEntryKind
+
+	// role and content of a message, the content of thinking, or the
+	// message of an error
+	role       string
+	content    string
+	incomplete bool
+
+	// code of an error
+	code *string
+
+	// id, name, arguments and result of a tool call. The result of a
+	// streamed tool call is looked up when rendering, since it may arrive
+	// after later entries.
+	toolCallID string
+	name       string
+	args       string
+	result     *string
+}
+
+// transcriptBuilder collects the entries of a transcript from events
+type transcriptBuilder struct {
+	thinking bool
+
+	entries   []transcriptEntry
+	messages  *MessageAccumulator
+	toolCalls *ToolCallAccumulator
+	rendered  int            // completed messages already turned into entries
+	slots     map[string]int // entry index of every started message, by ID
+	callSlots map[string]int // entry index of every started tool call, by ID
+
+	thinkingContent strings.Builder
+	inThinking      bool
+}
+
+// newTranscriptBuilder creates an empty transcript builder
+func newTranscriptBuilder() *transcriptBuilder {
+	b := &transcriptBuilder{
+		messages:  NewMessageAccumulator(),
+		slots:     make(map[string]int),
+		callSlots: make(map[string]int),
+	}
+	b.toolCalls = NewToolCallAccumulator(OnToolCallReady(func(call AccumulatedToolCall) {
+		entry := transcriptEntry{
+			kind:       transcriptToolCall,
+			toolCallID: call.ToolCallID,
+			name:       call.ToolCallName,
+			args:       call.Args,
+		}
+		if slot, ok := b.callSlots[call.ToolCallID]; ok {
+			b.entries[slot] = entry
+			delete(b.callSlots, call.ToolCallID)
+		} else {
+			b.entries = append(b.entries, entry)
+		}
+	}))
+	return b
+}
+
+// process records the next event
+func (b *transcriptBuilder) process(e Event) error {
+	switch event := e.(type) {
+	case *MessagesSnapshotEvent:
+		b.entries = snapshotEntries(event.Messages)
+		b.slots = make(map[string]int)
+		b.callSlots = make(map[string]int)
+
+	case *ThinkingTextMessageStartEvent:
+		b.endThinking()
+		b.inThinking = true
+
+	case *ThinkingTextMessageContentEvent:
+		b.inThinking = true
+		b.thinkingContent.WriteString(event.Delta)
+
+	case *ThinkingTextMessageEndEvent, *ThinkingEndEvent:
+		b.endThinking()
+
+	case *RunErrorEvent:
+		b.endThinking()
+		if err := b.messages.Process(e); err != nil {
+			return err
+		}
+		b.addMessages()
+		b.updateOpenMessages()
+		b.entries = append(b.entries, transcriptEntry{
+			kind:    transcriptError,
+			content: event.Message,
+			code:    event.Code,
+		})
+
+	default:
+		if err := b.messages.Process(e); err != nil {
+			return err
+		}
+		if err := b.toolCalls.Process(e); err != nil {
+			return err
+		}
+		b.addMessages()
+		b.placeOpenMessages()
+		if start, ok := e.(*ToolCallStartEvent); ok {
+			b.callSlots[start.ToolCallID] = len(b.entries)
+			b.entries = append(b.entries, transcriptEntry{
+				kind:       transcriptToolCall,
+				toolCallID: start.ToolCallID,
+				name:       start.ToolCallName,
+			})
+		}
+	}
+	return nil
+}
+
+// finish adds the messages and thinking still open at the end of the events
+func (b *transcriptBuilder) finish() {
+	b.endThinking()
+	b.messages.Flush()
+	b.addMessages()
+	b.updateOpenMessages()
+}
+
+// endThinking adds the thinking content received since the last one, if any
+func (b *transcriptBuilder) endThinking() {
+	if !b.inThinking {
+		return
+	}
+	b.inThinking = false
+	content := b.thinkingContent.String()
+	b.thinkingContent.Reset()
+	if content != "" {
+		b.entries = append(b.entries, transcriptEntry{kind: transcriptThinking, content: content})
+	}
+}
+
+// addMessages adds the messages completed since the last call, in the
+// entry reserved when they started if any
+func (b *transcriptBuilder) addMessages() {
+	for _, msg := range b.messages.completed[b.rendered:] {
+		entry := transcriptEntry{
+			kind:    transcriptMessage,
+			role:    msg.Role,
+			content: stringValue(msg.Content),
+		}
+		if slot, ok := b.slots[msg.ID]; ok {
+			b.entries[slot] = entry
+			delete(b.slots, msg.ID)
+		} else {
+			b.entries = append(b.entries, entry)
+		}
+	}
+	b.rendered = len(b.messages.completed)
+}
+
+// placeOpenMessages reserves an entry for the messages that started since
+// the last call, in ID order, so that messages are rendered in the order
+// they started rather than the order they ended
+func (b *transcriptBuilder) placeOpenMessages() {
+	open := b.messages.Open()
+	ids := make([]string, 0, len(open))
+	for id, msg := range open {
+		if _, ok := b.slots[id]; msg.Started && !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		b.slots[id] = len(b.entries)
+		b.entries = append(b.entries, transcriptEntry{kind: transcriptMessage, role: open[id].Role, incomplete: true})
+	}
+}
+
+// updateOpenMessages fills the entries of the messages that started but
+// have not ended with their content so far
+func (b *transcriptBuilder) updateOpenMessages() {
+	b.placeOpenMessages()
+	for id, msg := range b.messages.Open() {
+		if slot, ok := b.slots[id]; ok {
+			b.entries[slot].role = msg.Role
+			b.entries[slot].content = msg.Content
+		}
+	}
+}
+
+// snapshotEntries returns the entries of the messages of a snapshot. Tool
+// messages answering a tool call of the snapshot are rendered as its result.
+func snapshotEntries(messages []Message) []transcriptEntry {
+	results := make(map[string]*string)
+	for _, msg := range messages {
+		if msg.Role == RoleTool && msg.ToolCallID != nil {
+			results[*msg.ToolCallID] = msg.Content
+		}
+	}
+
+	calls := make(map[string]bool)
+	var entries []transcriptEntry
+	for _, msg := range messages {
+		if msg.Role == RoleTool && msg.ToolCallID != nil && calls[*msg.ToolCallID] {
+			continue
+		}
+		if msg.Content != nil || len(msg.ToolCalls) == 0 {
+			entries = append(entries, transcriptEntry{
+				kind:    transcriptMessage,
+				role:    msg.Role,
+				content: stringValue(msg.Content),
+			})
+		}
+		for _, call := range msg.ToolCalls {
+			calls[call.ID] = true
+			entries = append(entries, transcriptEntry{
+				kind:       transcriptToolCall,
+				toolCallID: call.ID,
+				name:       call.Function.Name,
+				args:       call.Function.Arguments,
+				result:     results[call.ID],
+			})
+		}
+	}
+	return entries
+}
+
+// stringValue returns the value of a string pointer, or "" if it is nil
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// render renders the entries in the given format
+func (b *transcriptBuilder) render(format RenderFormat) string {
+	results := make(map[string]string)
+	for _, call := range b.toolCalls.Completed() {
+		if call.Result != nil {
+			results[call.ToolCallID] = call.Result.Content
+		}
+	}
+
+	paragraphs := make([]string, 0, len(b.entries))
+	for _, entry := range b.entries {
+		if entry.kind == transcriptToolCall && entry.result == nil {
+			if result, ok := results[entry.toolCallID]; ok {
+				entry.result = &result
+			}
+		}
+		if format == RenderMarkdown {
+			paragraphs = append(paragraphs, b.markdown(entry))
+		} else {
+			paragraphs = append(paragraphs, b.plainText(entry))
+		}
+	}
+	if len(paragraphs) == 0 {
+		return ""
+	}
+	return strings.Join(paragraphs, "\n\n") + "\n"
+}
+
+// markdown renders an entry as Markdown
+func (b *transcriptBuilder) markdown(entry transcriptEntry) string {
+	switch entry.kind {
+	case transcriptToolCall:
+		var s strings.Builder
+		fmt.Fprintf(&s, "**Tool call** `%s` (`%s`)\n\n", entry.name, entry.toolCallID)
+		s.WriteString(fencedBlock(formatJSONText(entry.args, "{}")))
+		if entry.result == nil {
+			s.WriteString("\n\n_No result_")
+		} else {
+			s.WriteString("\n\n**Result**\n\n")
+			s.WriteString(fencedBlock(formatJSONText(*entry.result, "")))
+		}
+		return s.String()
+
+	case transcriptThinking:
+		if !b.thinking {
+			return "_Thinking elided_"
+		}
+		return "**Thinking**\n\n" + prefixLines(entry.content, "> ")
+
+	case transcriptError:
+		if entry.code != nil {
+			return fmt.Sprintf("> **Error** `%s`: %s", *entry.code, entry.content)
+		}
+		return "> **Error**: " + entry.content
+
+	default:
+		label := "**" + roleLabel(entry.role) + "**"
+		if entry.incomplete {
+			label += " _(incomplete)_"
+		}
+		content := entry.content
+		if content == "" {
+			content = "_(no content)_"
+		}
+		return label + "\n\n" + content
+	}
+}
+
+// plainText renders an entry as plain text
+func (b *transcriptBuilder) plainText(entry transcriptEntry) string {
+	switch entry.kind {
+	case transcriptToolCall:
+		var s strings.Builder
+		fmt.Fprintf(&s, "Tool call: %s (%s)\n", entry.name, entry.toolCallID)
+		s.WriteString(prefixLines(formatJSONText(entry.args, "{}").text, "    "))
+		if entry.result == nil {
+			s.WriteString("\nNo result")
+		} else {
+			s.WriteString("\nResult:\n")
+			s.WriteString(prefixLines(formatJSONText(*entry.result, "").text, "    "))
+		}
+		return s.String()
+
+	case transcriptThinking:
+		if !b.thinking {
+			return "[thinking elided]"
+		}
+		return "Thinking:\n" + prefixLines(entry.content, "    ")
+
+	case transcriptError:
+		if entry.code != nil {
+			return fmt.Sprintf("ERROR [%s]: %s", *entry.code, entry.content)
+		}
+		return "ERROR: " + entry.content
+
+	default:
+		label := roleLabel(entry.role)
+		if entry.incomplete {
+			label += " (incomplete)"
+		}
+		return label + ":\n" + entry.content
+	}
+}
+
+// roleLabel returns the label of a message role, such as "Assistant"
+func roleLabel(role string) string {
+	if role == "" {
+		return "Unknown"
+	}
+	return strings.ToUpper(role[:1]) + role[1:]
+}
+
+// transcriptText is text to render in a code block
+type transcriptText struct {
+	text string
+	json bool
+}
+
+// formatJSONText indents text that is valid JSON, and returns other text
+// as it is, or empty as the given placeholder
+func formatJSONText(text, empty string) transcriptText {
+	if strings.TrimSpace(text) == "" {
+		return transcriptText{text: empty, json: empty != ""}
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, []byte(text), "", "  "); err != nil {
+		return transcriptText{text: text}
+	}
+	return transcriptText{text: indented.String(), json: true}
+}
+
+// fencedBlock returns text as a Markdown fenced code block, with a fence
+// longer than any backtick run in the text
+func fencedBlock(t transcriptText) string {
+	longest, run := 0, 0
+	for _, c := range t.text {
+		if c == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+
+	info := ""
+	if t.json {
+		info = "json"
+	}
+	return fence + info + "\n" + t.text + "\n" + fence
+}
+
+// prefixLines prefixes every line of text
+func prefixLines(text, prefix string) string {
+	return prefix + strings.ReplaceAll(text, "\n", "\n"+prefix)
+}
@@ -0,0 +1,134 @@
+package events
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files of the transcript tests")
+
+// transcriptRun is a representative run: a snapshot of the earlier
+// conversation, then streamed thinking, text, a tool call with its result,
+// a chunked answer, and an error interrupting a last message
+func transcriptRun() []Event {
+	code := "RATE_LIMITED"
+	runError := NewRunErrorEvent("Model rate limit exceeded, retry in 30s")
+	runError.Code = &code
+
+	return []Event{
+		NewRunStartedEvent("thread-1", "run-1"),
+		NewMessagesSnapshotEvent([]Message{
+			diffMessage("msg-0", RoleSystem, "You are a helpful travel assistant."),
+			diffMessage("msg-1", RoleUser, "What's the weather in Paris?\nI'm packing for tomorrow."),
+		}),
+		NewThinkingStartEvent(),
+		NewThinkingTextMessageStartEvent(),
+		NewThinkingTextMessageContentEvent("The user wants the forecast, "),
+		NewThinkingTextMessageContentEvent("I should call the weather tool."),
+		NewThinkingTextMessageEndEvent(),
+		NewThinkingEndEvent(),
+		NewTextMessageStartEvent("msg-2", WithRole(RoleAssistant)),
+		NewTextMessageContentEvent("msg-2", "Let me check "),
+		NewTextMessageContentEvent("msg-2", "the forecast."),
+		NewTextMessageEndEvent("msg-2"),
+		NewToolCallStartEvent("call-1", "get_weather", WithParentMessageID("msg-2")),
+		NewToolCallArgsEvent("call-1", `{"city": "Paris",`),
+		NewToolCallArgsEvent("call-1", ` "days": 1}`),
+		NewToolCallEndEvent("call-1"),
+		NewToolCallResultEvent("msg-3", "call-1", `{"forecast": "rain", "high": 14}`),
+		NewTextMessageChunkEvent(nil, nil, nil).WithChunkMessageID("msg-4").WithChunkRole(RoleAssistant).WithChunkDelta("Expect rain and 14°C. "),
+		NewTextMessageChunkEvent(nil, nil, nil).WithChunkDelta("Pack an umbrella!"),
+		NewToolCallChunkEvent().WithToolCallChunkID("call-2").WithToolCallChunkName("lookup").WithToolCallChunkDelta("not json ```"),
+		NewTextMessageStartEvent("msg-5", WithRole(RoleAssistant)),
+		NewTextMessageContentEvent("msg-5", "Anything else"),
+		runError,
+	}
+}
+
+// assertGolden compares output to a golden file in testdata, rewriting it
+// when the tests run with -update
+func assertGolden(t *testing.T, name, output string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		require.NoError(t, os.MkdirAll("testdata", 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(output), 0o644))
+	}
+	golden, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(golden), output)
+}
+
+func TestRenderTranscript(t *testing.T) {
+	t.Run("Markdown", func(t *testing.T) {
+		output, err := RenderTranscript(transcriptRun(), RenderMarkdown)
+		require.NoError(t, err)
+		assertGolden(t, "transcript.md", output)
+	})
+
+	t.Run("PlainText", func(t *testing.T) {
+		output, err := RenderTranscript(transcriptRun(), RenderPlainText)
+		require.NoError(t, err)
+		assertGolden(t, "transcript.txt", output)
+	})
+
+	t.Run("WithThinking", func(t *testing.T) {
+		output, err := RenderTranscript(transcriptRun(), RenderMarkdown, WithThinking())
+		require.NoError(t, err)
+		assert.Contains(t, output, "**Thinking**\n\n> The user wants the forecast, I should call the weather tool.")
+		assert.NotContains(t, output, "elided")
+
+		output, err = RenderTranscript(transcriptRun(), RenderPlainText, WithThinking())
+		require.NoError(t, err)
+		assert.Contains(t, output, "Thinking:\n    The user wants the forecast")
+	})
+
+	t.Run("SnapshotMatchesStream", func(t *testing.T) {
+		content := `{"city": "Paris"}`
+		snapshot := []Event{NewMessagesSnapshotEvent([]Message{
+			diffMessage("msg-1", RoleUser, "Weather?"),
+			{ID: "msg-2", Role: RoleAssistant, ToolCalls: []ToolCall{
+				{ID: "call-1", Type: "function", Function: Function{Name: "get_weather", Arguments: content}},
+			}},
+			{ID: "msg-3", Role: RoleTool, Content: strPtr("rain"), ToolCallID: strPtr("call-1")},
+			diffMessage("msg-4", RoleAssistant, "It will rain."),
+		})}
+		stream := []Event{
+			NewTextMessageStartEvent("msg-1", WithRole(RoleUser)),
+			NewTextMessageContentEvent("msg-1", "Weather?"),
+			NewTextMessageEndEvent("msg-1"),
+			NewToolCallStartEvent("call-1", "get_weather"),
+			NewToolCallArgsEvent("call-1", content),
+			NewToolCallEndEvent("call-1"),
+			NewToolCallResultEvent("msg-3", "call-1", "rain"),
+			NewTextMessageStartEvent("msg-4", WithRole(RoleAssistant)),
+			NewTextMessageContentEvent("msg-4", "It will rain."),
+			NewTextMessageEndEvent("msg-4"),
+		}
+
+		for _, format := range []RenderFormat{RenderMarkdown, RenderPlainText} {
+			fromSnapshot, err := RenderTranscript(snapshot, format)
+			require.NoError(t, err)
+			fromStream, err := RenderTranscript(stream, format)
+			require.NoError(t, err)
+			assert.Equal(t, fromSnapshot, fromStream)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := RenderTranscript(nil, RenderFormat(42))
+		assert.Error(t, err)
+
+		_, err = RenderTranscript([]Event{NewTextMessageContentEvent("msg-1", "Hi")}, RenderMarkdown)
+		assert.Error(t, err)
+
+		output, err := RenderTranscript(nil, RenderMarkdown)
+		require.NoError(t, err)
+		assert.Empty(t, output)
+	})
+}
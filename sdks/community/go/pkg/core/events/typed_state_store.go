@@ -0,0 +1,137 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// TypedStateStore is a StateStore whose state has the shape of T. The state
+// is kept as generic JSON, so deltas from other producers apply as usual, and
+// is converted to T when it is read. Fields of the state that T does not
+// declare are preserved. It is safe for concurrent use.
+type TypedStateStore[T any] struct {
+	store *StateStore
+}
+
+// NewTypedStateStore creates a typed state store holding the zero value of
+// T, so that deltas and mutations can be applied before the first snapshot
+func NewTypedStateStore[T any](options ...StateStoreOption) *TypedStateStore[T] {
+	store := NewStateStore(options...)
+
+	var zero T
+	state, err := normalizeJSON(zero, store.preserveNumbers)
+	if err != nil {
+		state = map[string]any{}
+	}
+	store.state = state
+	store.hasSnapshot = true
+
+	return &TypedStateStore[T]{store: store}
+}
+
+// Store returns the underlying state store, for subscriptions and access to
+// the generic state
+func (s *TypedStateStore[T]) Store() *StateStore {
+	return s.store
+}
+
+// Apply applies an incoming state snapshot, state delta or merge patch event
+func (s *TypedStateStore[T]) Apply(e Event) error {
+	return s.store.Apply(e)
+}
+
+// Version returns the number of snapshot and delta events applied so far
+func (s *TypedStateStore[T]) Version() uint64 {
+	return s.store.Version()
+}
+
+// Current returns the state as a T. It fails if the state no longer matches
+// the shape of T, for example after a delta replaced an object with a string.
+func (s *TypedStateStore[T]) Current() (T, error) {
+	return decodeTypedState[T](s.store.CurrentShared())
+}
+
+// Set replaces the state with value and returns the snapshot event that
+// describes it
+func (s *TypedStateStore[T]) Set(value T) (*StateSnapshotEvent, error) {
+	state, err := normalizeJSON(value, s.store.preserveNumbers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	event := NewStateSnapshotEvent(state, WithSharedSnapshot())
+	s.store.ApplySnapshot(event)
+	return event, nil
+}
+
+// Mutate calls fn with the current state, applies the changes it makes and
+// returns them as a minimal delta event, or nil if nothing changed. The store
+// is locked while fn runs, so fn must not use the store itself.
+func (s *TypedStateStore[T]) Mutate(fn func(*T)) (*StateDeltaEvent, error) {
+	ops, err := s.store.update(0, func(state any) ([]JSONPatchOperation, error) {
+		value, err := decodeTypedState[T](state)
+		if err != nil {
+			return nil, err
+		}
+
+		before, err := normalizeJSON(value, s.store.preserveNumbers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode state: %w", err)
+		}
+		fn(&value)
+		after, err := normalizeJSON(value, s.store.preserveNumbers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode state: %w", err)
+		}
+
+		// Overlay the changes on the generic state so that fields T does
+		// not declare are left alone rather than removed
+		return diffValues("", state, overlayTypedState(state, before, after), []JSONPatchOperation{}), nil
+	})
+	if err != nil || len(ops) == 0 {
+		return nil, err
+	}
+	return NewStateDeltaEvent(ops), nil
+}
+
+// overlayTypedState returns state with the differences between the typed
+// views before and after applied to it. Object members that are not part of
+// the typed view, or that it leaves unchanged, are kept as they are.
+func overlayTypedState(state, before, after any) any {
+	stateObj, ok1 := state.(map[string]any)
+	beforeObj, ok2 := before.(map[string]any)
+	afterObj, ok3 := after.(map[string]any)
+	if !ok1 || !ok2 || !ok3 {
+		return after
+	}
+
+	result := make(map[string]any, len(stateObj))
+	for key, value := range stateObj {
+		result[key] = value
+	}
+	for key := range beforeObj {
+		if _, ok := afterObj[key]; !ok {
+			delete(result, key)
+		}
+	}
+	for key, value := range afterObj {
+		if previous, ok := beforeObj[key]; ok && jsonValuesEqual(previous, value) {
+			continue
+		}
+		result[key] = overlayTypedState(stateObj[key], beforeObj[key], value)
+	}
+	return result
+}
+
+// decodeTypedState converts a generic JSON state into a T
+func decodeTypedState[T any](state any) (T, error) {
+	var value T
+	data, err := json.Marshal(state)
+	if err != nil {
+		return value, fmt.Errorf("failed to encode state: %w", err)
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, fmt.Errorf("state does not match %T: %w", value, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,176 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type typedTestTask struct {
+	Title string   `json:"title"`
+	Done  bool     `json:"done"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+type typedTestState struct {
+	Status   string            `json:"status"`
+	Count    int               `json:"count"`
+	Tasks    []typedTestTask   `json:"tasks"`
+	Settings map[string]string `json:"settings"`
+}
+
+func TestTypedStateStore(t *testing.T) {
+	t.Run("ZeroValue", func(t *testing.T) {
+		store := NewTypedStateStore[typedTestState]()
+
+		current, err := store.Current()
+		require.NoError(t, err)
+		assert.Equal(t, typedTestState{}, current)
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		store := NewTypedStateStore[typedTestState]()
+		state := typedTestState{
+			Status:   "active",
+			Count:    2,
+			Tasks:    []typedTestTask{{Title: "write", Tags: []string{"docs"}}},
+			Settings: map[string]string{"theme": "dark"},
+		}
+
+		event, err := store.Set(state)
+		require.NoError(t, err)
+		require.NoError(t, event.Validate())
+		assert.Equal(t, map[string]any{
+			"status":   "active",
+			"count":    2.0,
+			"tasks":    []any{map[string]any{"title": "write", "done": false, "tags": []any{"docs"}}},
+			"settings": map[string]any{"theme": "dark"},
+		}, event.Snapshot)
+
+		current, err := store.Current()
+		require.NoError(t, err)
+		assert.Equal(t, state, current)
+
+		// The store keeps its own copy
+		state.Tasks[0].Title = "changed"
+		current, err = store.Current()
+		require.NoError(t, err)
+		assert.Equal(t, "write", current.Tasks[0].Title)
+	})
+
+	t.Run("Mutate", func(t *testing.T) {
+		store := NewTypedStateStore[typedTestState]()
+		_, err := store.Set(typedTestState{
+			Status:   "active",
+			Tasks:    []typedTestTask{{Title: "write"}, {Title: "review"}},
+			Settings: map[string]string{"theme": "dark", "lang": "en"},
+		})
+		require.NoError(t, err)
+
+		delta, err := store.Mutate(func(s *typedTestState) {
+			s.Tasks[1].Done = true
+			s.Tasks = append(s.Tasks, typedTestTask{Title: "ship", Tags: []string{"release"}})
+			delete(s.Settings, "lang")
+			s.Settings["theme"] = "light"
+		})
+		require.NoError(t, err)
+		require.NotNil(t, delta)
+		require.NoError(t, delta.Validate())
+		assert.Equal(t, []JSONPatchOperation{
+			{Op: "remove", Path: "/settings/lang"},
+			{Op: "replace", Path: "/settings/theme", Value: "light"},
+			{Op: "replace", Path: "/tasks/1/done", Value: true},
+			{Op: "add", Path: "/tasks/-", Value: map[string]any{"title": "ship", "done": false, "tags": []any{"release"}}},
+		}, delta.Delta)
+
+		current, err := store.Current()
+		require.NoError(t, err)
+		assert.True(t, current.Tasks[1].Done)
+		assert.Len(t, current.Tasks, 3)
+		assert.Equal(t, map[string]string{"theme": "light"}, current.Settings)
+
+		// The delta reproduces the change on another replica
+		replica := NewStateStore()
+		replica.ApplySnapshot(NewStateSnapshotEvent(map[string]any{
+			"status":   "active",
+			"count":    0,
+			"tasks":    []any{map[string]any{"title": "write", "done": false}, map[string]any{"title": "review", "done": false}},
+			"settings": map[string]any{"theme": "dark", "lang": "en"},
+		}))
+		require.NoError(t, replica.ApplyDelta(delta))
+		assert.Equal(t, store.Store().Current(), replica.Current())
+	})
+
+	t.Run("MutateWithoutChanges", func(t *testing.T) {
+		store := NewTypedStateStore[typedTestState]()
+		version := store.Version()
+
+		delta, err := store.Mutate(func(s *typedTestState) {
+			s.Count = 0
+		})
+		require.NoError(t, err)
+		assert.Nil(t, delta)
+		assert.Equal(t, version, store.Version())
+	})
+
+	t.Run("ForeignDeltas", func(t *testing.T) {
+		store := NewTypedStateStore[typedTestState]()
+		_, err := store.Set(typedTestState{Status: "active", Settings: map[string]string{}})
+		require.NoError(t, err)
+
+		require.NoError(t, store.Apply(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "replace", Path: "/count", Value: 5},
+			{Op: "add", Path: "/tasks", Value: []any{map[string]any{"title": "foreign", "done": true}}},
+			{Op: "add", Path: "/settings/region", Value: "eu"},
+			{Op: "add", Path: "/extra", Value: map[string]any{"owner": "other-agent"}},
+		})))
+
+		current, err := store.Current()
+		require.NoError(t, err)
+		assert.Equal(t, 5, current.Count)
+		assert.Equal(t, []typedTestTask{{Title: "foreign", Done: true}}, current.Tasks)
+		assert.Equal(t, "eu", current.Settings["region"])
+
+		// Mutations leave fields outside T untouched
+		delta, err := store.Mutate(func(s *typedTestState) {
+			s.Count++
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []JSONPatchOperation{{Op: "replace", Path: "/count", Value: 6.0}}, delta.Delta)
+		assert.Equal(t, map[string]any{"owner": "other-agent"}, store.Store().Current().(map[string]any)["extra"])
+	})
+
+	t.Run("ShapeMismatch", func(t *testing.T) {
+		store := NewTypedStateStore[typedTestState]()
+		require.NoError(t, store.Apply(NewStateDeltaEvent([]JSONPatchOperation{
+			{Op: "replace", Path: "/tasks", Value: map[string]any{"not": "a list"}},
+		})))
+
+		assert.NotPanics(t, func() {
+			_, err := store.Current()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "state does not match")
+		})
+
+		called := false
+		delta, err := store.Mutate(func(*typedTestState) { called = true })
+		require.Error(t, err)
+		assert.Nil(t, delta)
+		assert.False(t, called)
+	})
+
+	t.Run("Subscriptions", func(t *testing.T) {
+		store := NewTypedStateStore[typedTestState]()
+
+		var changes []any
+		_, err := store.Store().Subscribe("/status", func(_, new any) {
+			changes = append(changes, new)
+		})
+		require.NoError(t, err)
+
+		_, err = store.Mutate(func(s *typedTestState) { s.Status = "running" })
+		require.NoError(t, err)
+		assert.Equal(t, []any{"running"}, changes)
+	})
+}
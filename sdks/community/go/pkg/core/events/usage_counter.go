@@ -0,0 +1,299 @@
+package events
+
+import (
+	"sync"
+	"unicode/utf8"
+)
+
+// Tokenizer counts the tokens of text, for example with the encoding of the
+// model producing the stream
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// Usage is an amount of streamed content
+type Usage struct {
+	Characters int
+
+	// Tokens is only counted when the counter has a Tokenizer
+	Tokens int
+}
+
+// Add returns the sum of two usages
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		Characters: u.Characters + other.Characters,
+		Tokens:     u.Tokens + other.Tokens,
+	}
+}
+
+// exceeds reports whether u exceeds a non-zero limit of budget
+func (u Usage) exceeds(budget Usage) bool {
+	return (budget.Characters > 0 && u.Characters > budget.Characters) ||
+		(budget.Tokens > 0 && u.Tokens > budget.Tokens)
+}
+
+// RunUsage is the content streamed during a run, by bucket
+type RunUsage struct {
+	RunID string
+
+	// Text is the content of text messages
+	Text Usage
+
+	// Thinking is the content of thinking messages
+	Thinking Usage
+
+	// ToolArgs is the arguments of tool calls
+	ToolArgs Usage
+}
+
+// Total returns the usage of all buckets
+func (u RunUsage) Total() Usage {
+	return u.Text.Add(u.Thinking).Add(u.ToolArgs)
+}
+
+// UsageCounter counts the content streamed by text messages, thinking
+// messages and tool call arguments, per message, per tool call and per run,
+// for billing and rate limiting while the stream is running. Content is
+// attributed to the run of the last RUN_STARTED event, or to the run with an
+// empty ID before the first one.
+//
+// The usage of a message or tool call is kept while it streams, and dropped
+// once it ends: at its end event, or for chunked messages and tool calls when
+// the next one starts or the run finishes. The usage of runs is kept until
+// ResetRun.
+//
+// Characters are Unicode code points. The text inserted by a TextEdit is
+// counted, and deleted text is not subtracted. Tokens are counted delta by
+// delta, which may differ slightly from counting the reassembled content,
+// since deltas can split words. It implements EventStage, passing every event
+// through unchanged, and is safe for concurrent use.
+type UsageCounter struct {
+	tokenizer  Tokenizer
+	budget     Usage
+	onExceeded func(RunUsage)
+
+	mu        sync.Mutex
+	runID     string
+	runs      map[string]*RunUsage
+	exceeded  map[string]bool
+	messages  map[string]Usage
+	toolCalls map[string]Usage
+
+	// chunkMessageID and chunkToolCallID are the IDs continued by chunks
+	// without one
+	chunkMessageID  string
+	chunkToolCallID string
+}
+
+// UsageCounterOption defines options for creating usage counters
+type UsageCounterOption func(*UsageCounter)
+
+// WithTokenizer counts tokens with t in addition to characters
+func WithTokenizer(t Tokenizer) UsageCounterOption {
+	return func(c *UsageCounter) {
+		c.tokenizer = t
+	}
+}
+
+// WithUsageBudget calls onExceeded once for every run whose total usage
+// exceeds a non-zero limit of budget, with the usage of the run at that
+// point. It is called synchronously from Process, after the counter is
+// updated, so it may read the counter.
+func WithUsageBudget(budget Usage, onExceeded func(RunUsage)) UsageCounterOption {
+	return func(c *UsageCounter) {
+		c.budget = budget
+		c.onExceeded = onExceeded
+	}
+}
+
+// NewUsageCounter creates a usage counter counting characters only unless a
+// Tokenizer is configured
+func NewUsageCounter(opts ...UsageCounterOption) *UsageCounter {
+	c := &UsageCounter{
+		runs:      make(map[string]*RunUsage),
+		exceeded:  make(map[string]bool),
+		messages:  make(map[string]Usage),
+		toolCalls: make(map[string]Usage),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Process implements EventStage, counting the content of e and returning it
+// unchanged
+func (c *UsageCounter) Process(e Event) ([]Event, error) {
+	if exceeded, usage := c.count(e); exceeded {
+		c.onExceeded(usage)
+	}
+	return []Event{e}, nil
+}
+
+// Flush implements EventStage. The counter holds no events.
+func (c *UsageCounter) Flush() []Event {
+	return nil
+}
+
+// count counts the content of e, and reports whether it made its run exceed
+// the budget for the first time
+func (c *UsageCounter) count(e Event) (bool, RunUsage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch event := e.(type) {
+	case *RunStartedEvent:
+		c.runID = event.RunID()
+		c.run(c.runID)
+		return false, RunUsage{}
+
+	case *TextMessageEndEvent:
+		delete(c.messages, event.MessageID)
+		return false, RunUsage{}
+
+	case *ToolCallEndEvent:
+		delete(c.toolCalls, event.ToolCallID)
+		return false, RunUsage{}
+
+	case *RunFinishedEvent, *RunErrorEvent:
+		c.endChunkedMessage("")
+		c.endChunkedToolCall("")
+		return false, RunUsage{}
+	}
+
+	run := c.run(c.runID)
+	switch event := e.(type) {
+	case *TextMessageContentEvent:
+		delta := event.Delta
+		if event.Edit != nil {
+			delta = event.Edit.Insert
+		}
+		usage := c.measure(delta)
+		c.messages[event.MessageID] = c.messages[event.MessageID].Add(usage)
+		run.Text = run.Text.Add(usage)
+
+	case *TextMessageChunkEvent:
+		if event.MessageID != nil && *event.MessageID != "" {
+			c.endChunkedMessage(*event.MessageID)
+		}
+		usage := c.measure(stringValue(event.Delta))
+		c.messages[c.chunkMessageID] = c.messages[c.chunkMessageID].Add(usage)
+		run.Text = run.Text.Add(usage)
+
+	case *ThinkingTextMessageContentEvent:
+		run.Thinking = run.Thinking.Add(c.measure(event.Delta))
+
+	case *ToolCallArgsEvent:
+		usage := c.measure(event.Delta)
+		c.toolCalls[event.ToolCallID] = c.toolCalls[event.ToolCallID].Add(usage)
+		run.ToolArgs = run.ToolArgs.Add(usage)
+
+	case *ToolCallChunkEvent:
+		if event.ToolCallID != nil && *event.ToolCallID != "" {
+			c.endChunkedToolCall(*event.ToolCallID)
+		}
+		usage := c.measure(stringValue(event.Delta))
+		c.toolCalls[c.chunkToolCallID] = c.toolCalls[c.chunkToolCallID].Add(usage)
+		run.ToolArgs = run.ToolArgs.Add(usage)
+
+	default:
+		return false, RunUsage{}
+	}
+
+	if c.onExceeded == nil || c.exceeded[run.RunID] || !run.Total().exceeds(c.budget) {
+		return false, RunUsage{}
+	}
+	c.exceeded[run.RunID] = true
+	return true, *run
+}
+
+// endChunkedMessage drops the usage of the current chunked message unless
+// the chunks continue it, and makes next the current one
+func (c *UsageCounter) endChunkedMessage(next string) {
+	if c.chunkMessageID != next {
+		delete(c.messages, c.chunkMessageID)
+	}
+	c.chunkMessageID = next
+}
+
+// endChunkedToolCall drops the usage of the current chunked tool call unless
+// the chunks continue it, and makes next the current one
+func (c *UsageCounter) endChunkedToolCall(next string) {
+	if c.chunkToolCallID != next {
+		delete(c.toolCalls, c.chunkToolCallID)
+	}
+	c.chunkToolCallID = next
+}
+
+// run returns the usage of a run, creating it if needed
+func (c *UsageCounter) run(runID string) *RunUsage {
+	run, ok := c.runs[runID]
+	if !ok {
+		run = &RunUsage{RunID: runID}
+		c.runs[runID] = run
+	}
+	return run
+}
+
+// measure returns the usage of a delta
+func (c *UsageCounter) measure(delta string) Usage {
+	usage := Usage{Characters: utf8.RuneCountInString(delta)}
+	if c.tokenizer != nil && delta != "" {
+		usage.Tokens = c.tokenizer.CountTokens(delta)
+	}
+	return usage
+}
+
+// MessageUsage returns the content streamed so far by a text message that
+// has not ended
+func (c *UsageCounter) MessageUsage(messageID string) Usage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.messages[messageID]
+}
+
+// ToolCallUsage returns the arguments streamed so far by a tool call that
+// has not ended
+func (c *UsageCounter) ToolCallUsage(toolCallID string) Usage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.toolCalls[toolCallID]
+}
+
+// RunUsage returns the content streamed so far during a run
+func (c *UsageCounter) RunUsage(runID string) RunUsage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if run, ok := c.runs[runID]; ok {
+		return *run
+	}
+	return RunUsage{RunID: runID}
+}
+
+// ResetRun forgets the usage of a run, once it has been billed for example,
+// so that the counter does not grow with every run of a long-lived stream.
+// Content streamed afterwards for the run is counted from zero, and its
+// budget may be exceeded again.
+func (c *UsageCounter) ResetRun(runID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.runs, runID)
+	delete(c.exceeded, runID)
+}
+
+// Total returns the content streamed so far during the runs that have not
+// been reset
+func (c *UsageCounter) Total() RunUsage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var total RunUsage
+	for _, run := range c.runs {
+		total.Text = total.Text.Add(run.Text)
+		total.Thinking = total.Thinking.Add(run.Thinking)
+		total.ToolArgs = total.ToolArgs.Add(run.ToolArgs)
+	}
+	return total
+}
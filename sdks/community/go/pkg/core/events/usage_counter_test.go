@@ -0,0 +1,162 @@
+package events
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wordTokenizer counts whitespace separated words as tokens
+type wordTokenizer struct{}
+
+func (wordTokenizer) CountTokens(text string) int {
+	return len(strings.Fields(text))
+}
+
+func TestUsageCounter(t *testing.T) {
+	scriptedRun := func() []Event {
+		return []Event{
+			NewRunStartedEvent("thread-1", "run-1"),
+			NewThinkingTextMessageContentEvent("let me think"),
+			NewTextMessageStartEvent("msg-1", WithRole(RoleAssistant)),
+			NewTextMessageContentEvent("msg-1", "Héllo "),
+			NewTextMessageContentEvent("msg-1", "wörld"),
+			NewTextMessageEndEvent("msg-1"),
+			NewToolCallArgsEvent("call-1", `{"q": "x"}`),
+			NewTextMessageChunkEvent(nil, nil, nil).WithChunkMessageID("msg-2").WithChunkDelta("one two"),
+			NewTextMessageChunkEvent(nil, nil, nil).WithChunkDelta(" three"),
+			NewToolCallChunkEvent().WithToolCallChunkID("call-2").WithToolCallChunkName("search").WithToolCallChunkDelta("{}"),
+			NewRunFinishedEvent("thread-1", "run-1"),
+		}
+	}
+
+	t.Run("ScriptedRun", func(t *testing.T) {
+		c := NewUsageCounter(WithTokenizer(wordTokenizer{}))
+		stream := scriptedRun()
+		out, err := ApplyStages(stream[:5], c)
+		require.NoError(t, err)
+		assert.Equal(t, Usage{Characters: 11, Tokens: 2}, c.MessageUsage("msg-1"))
+
+		rest, err := ApplyStages(stream[5:10], c)
+		require.NoError(t, err)
+		out = append(out, rest...)
+		assert.Zero(t, c.MessageUsage("msg-1"), "dropped at the end of the message")
+		assert.Equal(t, Usage{Characters: 13, Tokens: 3}, c.MessageUsage("msg-2"))
+		assert.Equal(t, Usage{Characters: 10, Tokens: 2}, c.ToolCallUsage("call-1"))
+		assert.Equal(t, Usage{Characters: 2, Tokens: 1}, c.ToolCallUsage("call-2"))
+
+		rest, err = ApplyStages(stream[10:], c)
+		require.NoError(t, err)
+		out = append(out, rest...)
+		assert.Len(t, out, 11)
+		assert.Zero(t, c.MessageUsage("msg-2"), "chunks are ended by the end of the run")
+		assert.Zero(t, c.ToolCallUsage("call-2"))
+		assert.Equal(t, Usage{Characters: 10, Tokens: 2}, c.ToolCallUsage("call-1"), "never ended")
+
+		usage := c.RunUsage("run-1")
+		assert.Equal(t, RunUsage{
+			RunID:    "run-1",
+			Text:     Usage{Characters: 24, Tokens: 5},
+			Thinking: Usage{Characters: 12, Tokens: 3},
+			ToolArgs: Usage{Characters: 12, Tokens: 3},
+		}, usage)
+		assert.Equal(t, Usage{Characters: 48, Tokens: 11}, usage.Total())
+		assert.Equal(t, usage.Total(), c.Total().Total())
+	})
+
+	t.Run("CharactersOnly", func(t *testing.T) {
+		c := NewUsageCounter()
+		_, err := ApplyStages(scriptedRun()[:5], c)
+		require.NoError(t, err)
+		assert.Equal(t, Usage{Characters: 11}, c.MessageUsage("msg-1"))
+		assert.Equal(t, RunUsage{RunID: "unknown"}, c.RunUsage("unknown"))
+	})
+
+	t.Run("SeparateRuns", func(t *testing.T) {
+		c := NewUsageCounter()
+		_, err := ApplyStages([]Event{
+			NewTextMessageContentEvent("msg-0", "before"),
+			NewRunStartedEvent("thread-1", "run-1"),
+			NewTextMessageContentEvent("msg-1", "first"),
+			NewRunStartedEvent("thread-1", "run-2"),
+			NewTextMessageContentEvent("msg-2", "second"),
+		}, c)
+		require.NoError(t, err)
+
+		assert.Equal(t, 6, c.RunUsage("").Text.Characters)
+		assert.Equal(t, 5, c.RunUsage("run-1").Text.Characters)
+		assert.Equal(t, 6, c.RunUsage("run-2").Text.Characters)
+		assert.Equal(t, 17, c.Total().Text.Characters)
+	})
+
+	t.Run("EndedEntriesAreDropped", func(t *testing.T) {
+		c := NewUsageCounter()
+		_, err := ApplyStages([]Event{
+			NewToolCallStartEvent("call-1", "search"),
+			NewToolCallArgsEvent("call-1", "{}"),
+			NewToolCallEndEvent("call-1"),
+			NewTextMessageChunkEvent(nil, nil, nil).WithChunkMessageID("msg-1").WithChunkDelta("a"),
+			NewTextMessageChunkEvent(nil, nil, nil).WithChunkMessageID("msg-2").WithChunkDelta("b"),
+		}, c)
+		require.NoError(t, err)
+
+		assert.Empty(t, c.toolCalls)
+		assert.Equal(t, map[string]Usage{"msg-2": {Characters: 1}}, c.messages)
+		assert.Equal(t, 4, c.RunUsage("").Total().Characters)
+	})
+
+	t.Run("ResetRun", func(t *testing.T) {
+		fired := 0
+		c := NewUsageCounter(WithUsageBudget(Usage{Characters: 40}, func(RunUsage) { fired++ }))
+		_, err := ApplyStages(scriptedRun(), c)
+		require.NoError(t, err)
+		require.Equal(t, 1, fired)
+
+		c.ResetRun("run-1")
+		assert.Equal(t, RunUsage{RunID: "run-1"}, c.RunUsage("run-1"))
+		assert.Empty(t, c.runs)
+		assert.Zero(t, c.Total().Total())
+
+		// The run is counted from zero, against its whole budget
+		_, err = ApplyStages(scriptedRun(), c)
+		require.NoError(t, err)
+		assert.Equal(t, 2, fired)
+	})
+
+	t.Run("BudgetFiresOnce", func(t *testing.T) {
+		var fired []RunUsage
+		var c *UsageCounter
+		c = NewUsageCounter(WithTokenizer(wordTokenizer{}), WithUsageBudget(Usage{Tokens: 6}, func(usage RunUsage) {
+			// The counter is already updated and may be read
+			assert.Equal(t, usage, c.RunUsage(usage.RunID))
+			fired = append(fired, usage)
+		}))
+		_, err := ApplyStages(scriptedRun(), c)
+		require.NoError(t, err)
+
+		require.Len(t, fired, 1)
+		assert.Equal(t, "run-1", fired[0].RunID)
+		// Exceeded by the arguments of call-1
+		assert.Equal(t, Usage{Characters: 33, Tokens: 7}, fired[0].Total())
+
+		// Another run has its own budget
+		_, err = ApplyStages([]Event{
+			NewRunStartedEvent("thread-1", "run-2"),
+			NewTextMessageContentEvent("msg-3", "a b c d e f g"),
+			NewTextMessageContentEvent("msg-3", "h"),
+		}, c)
+		require.NoError(t, err)
+		require.Len(t, fired, 2)
+		assert.Equal(t, "run-2", fired[1].RunID)
+	})
+
+	t.Run("BudgetNotExceeded", func(t *testing.T) {
+		fired := 0
+		c := NewUsageCounter(WithUsageBudget(Usage{Characters: 48}, func(RunUsage) { fired++ }))
+		_, err := ApplyStages(scriptedRun(), c)
+		require.NoError(t, err)
+		assert.Zero(t, fired)
+	})
+}
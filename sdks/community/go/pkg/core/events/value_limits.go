@@ -0,0 +1,59 @@
+package events
+
+import "github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/jsonwalk"
+
+// jsonValueShape describes the size of a free-form JSON value
+type jsonValueShape struct {
+	// depth is the deepest level of object or array nesting; scalars have depth 0
+	depth int
+	// keys is the total number of object keys at every level
+	keys int
+}
+
+// measureJSONValue measures the nesting depth and key count of a value. The
+// value is walked iteratively, so deeply nested input cannot exhaust the
+// stack, and the walk stops as soon as either limit is exceeded (a limit of
+// 0 disables it). Values that are not generic JSON values are converted first.
+func measureJSONValue(value any, maxDepth, maxKeys int) jsonValueShape {
+	var shape jsonValueShape
+	shape.measure(value, 0, maxDepth, maxKeys)
+	return shape
+}
+
+// measure adds the shape of a value nested at the given depth
+func (shape *jsonValueShape) measure(value any, offset, maxDepth, maxKeys int) {
+	_ = jsonwalk.Walk(value, func(path []string, v any) error {
+		switch item := v.(type) {
+		case nil, string, float64, bool:
+			return nil
+		case map[string]any:
+			shape.keys += len(item)
+		case []any:
+		default:
+			// A converted value holds only generic JSON values, so this
+			// recurses at most once
+			normalized, err := normalizeJSONValue(v)
+			if err != nil {
+				return nil
+			}
+			switch normalized.(type) {
+			case map[string]any, []any:
+				shape.measure(normalized, offset+len(path), maxDepth, maxKeys)
+			}
+			return shape.exceeds(maxDepth, maxKeys)
+		}
+
+		if depth := offset + len(path) + 1; depth > shape.depth {
+			shape.depth = depth
+		}
+		return shape.exceeds(maxDepth, maxKeys)
+	})
+}
+
+// exceeds returns jsonwalk.SkipAll once either limit is exceeded
+func (shape *jsonValueShape) exceeds(maxDepth, maxKeys int) error {
+	if (maxDepth > 0 && shape.depth > maxDepth) || (maxKeys > 0 && shape.keys > maxKeys) {
+		return jsonwalk.SkipAll
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrStateConflict is matched by every *StateConflictError, for use with
+// errors.Is
+var ErrStateConflict = errors.New("state conflict")
+
+// StateConflictError reports a delta computed against a different version of
+// the state than the one it is being applied to
+type StateConflictError struct {
+	// BaseVersion is the version the delta was computed against
+	BaseVersion uint64
+
+	// CurrentVersion is the version of the store the delta was applied to
+	CurrentVersion uint64
+}
+
+// Error implements the error interface
+func (e *StateConflictError) Error() string {
+	return fmt.Sprintf("state conflict: delta is based on version %d, current version is %d", e.BaseVersion, e.CurrentVersion)
+}
+
+// Is reports whether target is ErrStateConflict
+func (e *StateConflictError) Is(target error) bool {
+	return target == ErrStateConflict
+}
+
+// StateConflictResolver decides what to do with a conflicting delta. It
+// receives the conflict, the delta and a copy of the current state, and
+// returns a delta rebased onto the current state to apply instead, nil to drop
+// the delta, or an error to reject it.
+type StateConflictResolver func(conflict *StateConflictError, delta *StateDeltaEvent, current any) (*StateDeltaEvent, error)
+
+// VersionedStateStore is a state store that numbers the versions of its
+// state and rejects deltas computed against a stale version. Every applied
+// snapshot or delta produces a new version, unless a delta declares the
+// version it produces. Deltas without a base version are applied
+// unconditionally. It is safe for concurrent use.
+type VersionedStateStore struct {
+	mu       sync.Mutex
+	store    *StateStore
+	version  uint64
+	resolver StateConflictResolver
+
+	storeOptions []StateStoreOption
+}
+
+// VersionedStateStoreOption defines options for creating versioned state stores
+type VersionedStateStoreOption func(*VersionedStateStore)
+
+// WithConflictResolver sets the function that handles conflicting deltas.
+// Without one, conflicting deltas are rejected with a *StateConflictError.
+func WithConflictResolver(resolver StateConflictResolver) VersionedStateStoreOption {
+	return func(s *VersionedStateStore) {
+		s.resolver = resolver
+	}
+}
+
+// WithStateStoreOptions configures the underlying StateStore, for example
+// with WithPreserveNumbers or WithStateHistory. Options given in several
+// calls are all applied, in order.
+func WithStateStoreOptions(options ...StateStoreOption) VersionedStateStoreOption {
+	return func(s *VersionedStateStore) {
+		s.storeOptions = append(s.storeOptions, options...)
+	}
+}
+
+// NewVersionedStateStore creates a new, empty versioned state store at
+// version zero
+func NewVersionedStateStore(options ...VersionedStateStoreOption) *VersionedStateStore {
+	s := &VersionedStateStore{}
+
+	for _, opt := range options {
+		opt(s)
+	}
+
+	s.store = NewStateStore(s.storeOptions...)
+	return s
+}
+
+// Apply applies a state snapshot or state delta event to the store. Other
+// event types are ignored.
+func (s *VersionedStateStore) Apply(e Event) error {
+	switch event := e.(type) {
+	case *StateSnapshotEvent:
+		s.ApplySnapshot(event)
+		return nil
+	case *StateDeltaEvent:
+		return s.ApplyDelta(event)
+	default:
+		return nil
+	}
+}
+
+// ApplySnapshot replaces the state with the snapshot and advances the version
+func (s *VersionedStateStore) ApplySnapshot(e *StateSnapshotEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.store.ApplySnapshot(e)
+	s.version++
+}
+
+// ApplyDelta applies the delta if it is based on the current version, or has
+// no base version. A conflicting delta is passed to the conflict resolver, or
+// rejected with a *StateConflictError if there is none.
+func (s *VersionedStateStore) ApplyDelta(e *StateDeltaEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e.BaseVersion != nil && *e.BaseVersion != s.version {
+		conflict := &StateConflictError{BaseVersion: *e.BaseVersion, CurrentVersion: s.version}
+		if s.resolver == nil {
+			return conflict
+		}
+
+		rebased, err := s.resolver(conflict, e, s.store.Current())
+		if err != nil {
+			return fmt.Errorf("failed to resolve %w: %w", conflict, err)
+		}
+		if rebased == nil {
+			return nil
+		}
+		e = rebased
+	}
+
+	if err := s.store.ApplyDelta(e); err != nil {
+		return err
+	}
+	if e.NewVersion != nil {
+		s.version = *e.NewVersion
+	} else {
+		s.version++
+	}
+	return nil
+}
+
+// NewDelta creates a delta event based on the current version, producing the
+// next one, for edits that should be checked for conflicts by the receiver
+func (s *VersionedStateStore) NewDelta(ops []JSONPatchOperation) *StateDeltaEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return NewStateDeltaEventWithOptions(ops, WithBaseVersion(s.version), WithNewVersion(s.version+1))
+}
+
+// Current returns a deep copy of the state
+func (s *VersionedStateStore) Current() any {
+	return s.store.Current()
+}
+
+// Store returns the underlying state store, for subscribing to paths or
+// reading its history. Its versions count every change and are unrelated to
+// the versions of the versioned store. Updating it directly bypasses conflict
+// detection and leaves the version unchanged.
+func (s *VersionedStateStore) Store() *StateStore {
+	return s.store
+}
+
+// Version returns the current version of the state
+func (s *VersionedStateStore) Version() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.version
+}
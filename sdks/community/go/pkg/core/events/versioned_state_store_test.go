@@ -0,0 +1,171 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionedStateStore(t *testing.T) {
+	// newReplicas returns a server store and two replicas that have all seen
+	// the same initial snapshot
+	newReplicas := func(t *testing.T, options ...VersionedStateStoreOption) (server, agent, client *VersionedStateStore) {
+		t.Helper()
+		snapshot := NewStateSnapshotEvent(map[string]any{"title": "Draft", "count": 0})
+		server = NewVersionedStateStore(options...)
+		agent = NewVersionedStateStore()
+		client = NewVersionedStateStore()
+		for _, store := range []*VersionedStateStore{server, agent, client} {
+			store.ApplySnapshot(snapshot)
+			require.Equal(t, uint64(1), store.Version())
+		}
+		return server, agent, client
+	}
+
+	t.Run("DetectsStaleDelta", func(t *testing.T) {
+		server, agent, client := newReplicas(t)
+
+		// The agent and the client both edit version 1
+		agentDelta := agent.NewDelta([]JSONPatchOperation{{Op: "replace", Path: "/count", Value: 1}})
+		clientDelta := client.NewDelta([]JSONPatchOperation{{Op: "replace", Path: "/title", Value: "Final"}})
+
+		require.NoError(t, server.ApplyDelta(agentDelta))
+		assert.Equal(t, uint64(2), server.Version())
+
+		err := server.ApplyDelta(clientDelta)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrStateConflict))
+		var conflict *StateConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, uint64(1), conflict.BaseVersion)
+		assert.Equal(t, uint64(2), conflict.CurrentVersion)
+
+		// The conflicting delta was not applied
+		assert.Equal(t, map[string]any{"title": "Draft", "count": 1.0}, server.Current())
+		assert.Equal(t, uint64(2), server.Version())
+	})
+
+	t.Run("RebaseHook", func(t *testing.T) {
+		var conflicts []StateConflictError
+		server, agent, client := newReplicas(t, WithConflictResolver(
+			func(conflict *StateConflictError, delta *StateDeltaEvent, current any) (*StateDeltaEvent, error) {
+				conflicts = append(conflicts, *conflict)
+				assert.Equal(t, 1.0, current.(map[string]any)["count"])
+
+				// The edits touch different fields, so the client's
+				// operations can be replayed on top of the agent's
+				return NewStateDeltaEventWithOptions(delta.Delta, WithBaseVersion(conflict.CurrentVersion)), nil
+			}))
+
+		agentDelta := agent.NewDelta([]JSONPatchOperation{{Op: "replace", Path: "/count", Value: 1}})
+		clientDelta := client.NewDelta([]JSONPatchOperation{{Op: "replace", Path: "/title", Value: "Final"}})
+
+		require.NoError(t, server.ApplyDelta(agentDelta))
+		require.NoError(t, server.ApplyDelta(clientDelta))
+
+		assert.Equal(t, []StateConflictError{{BaseVersion: 1, CurrentVersion: 2}}, conflicts)
+		assert.Equal(t, map[string]any{"title": "Final", "count": 1.0}, server.Current())
+		assert.Equal(t, uint64(3), server.Version())
+	})
+
+	t.Run("DropHook", func(t *testing.T) {
+		server, agent, client := newReplicas(t, WithConflictResolver(
+			func(*StateConflictError, *StateDeltaEvent, any) (*StateDeltaEvent, error) { return nil, nil }))
+
+		require.NoError(t, server.ApplyDelta(agent.NewDelta([]JSONPatchOperation{{Op: "replace", Path: "/count", Value: 1}})))
+		require.NoError(t, server.ApplyDelta(client.NewDelta([]JSONPatchOperation{{Op: "replace", Path: "/count", Value: 7}})))
+
+		assert.Equal(t, map[string]any{"title": "Draft", "count": 1.0}, server.Current())
+		assert.Equal(t, uint64(2), server.Version())
+	})
+
+	t.Run("RejectHook", func(t *testing.T) {
+		errRejected := errors.New("edit rejected")
+		server, agent, client := newReplicas(t, WithConflictResolver(
+			func(*StateConflictError, *StateDeltaEvent, any) (*StateDeltaEvent, error) { return nil, errRejected }))
+
+		require.NoError(t, server.ApplyDelta(agent.NewDelta([]JSONPatchOperation{{Op: "replace", Path: "/count", Value: 1}})))
+		err := server.ApplyDelta(client.NewDelta([]JSONPatchOperation{{Op: "replace", Path: "/count", Value: 7}}))
+		assert.ErrorIs(t, err, errRejected)
+		assert.ErrorIs(t, err, ErrStateConflict)
+	})
+
+	t.Run("ConcurrentEdits", func(t *testing.T) {
+		server := NewVersionedStateStore()
+		server.ApplySnapshot(NewStateSnapshotEvent(map[string]any{}))
+
+		// Every editor computes its delta against the same version, so
+		// exactly one of them can win the race
+		const editors = 16
+		deltas := make([]*StateDeltaEvent, editors)
+		for i := range deltas {
+			deltas[i] = server.NewDelta([]JSONPatchOperation{{Op: "add", Path: fmt.Sprintf("/editor%d", i), Value: i}})
+		}
+
+		var wg sync.WaitGroup
+		results := make([]error, editors)
+		for i, delta := range deltas {
+			wg.Add(1)
+			go func(i int, delta *StateDeltaEvent) {
+				defer wg.Done()
+				results[i] = server.ApplyDelta(delta)
+			}(i, delta)
+		}
+		wg.Wait()
+
+		applied := 0
+		for _, err := range results {
+			if err == nil {
+				applied++
+			} else {
+				assert.ErrorIs(t, err, ErrStateConflict)
+			}
+		}
+		assert.Equal(t, 1, applied)
+		assert.Len(t, server.Current(), 1)
+		assert.Equal(t, uint64(2), server.Version())
+	})
+
+	t.Run("UnversionedDeltas", func(t *testing.T) {
+		server, _, _ := newReplicas(t)
+		require.NoError(t, server.ApplyDelta(NewStateDeltaEvent([]JSONPatchOperation{{Op: "replace", Path: "/count", Value: 5}})))
+		assert.Equal(t, uint64(2), server.Version())
+
+		// A declared new version is adopted
+		require.NoError(t, server.ApplyDelta(NewStateDeltaEventWithOptions(
+			[]JSONPatchOperation{{Op: "replace", Path: "/count", Value: 6}}, WithBaseVersion(2), WithNewVersion(10))))
+		assert.Equal(t, uint64(10), server.Version())
+	})
+
+	t.Run("StateStoreOptions", func(t *testing.T) {
+		store := NewVersionedStateStore(
+			WithStateStoreOptions(WithEmptyInitialState()),
+			WithStateStoreOptions(WithStateHistory(5)),
+		)
+		assert.Equal(t, map[string]any{}, store.Current())
+
+		require.NoError(t, store.ApplyDelta(store.NewDelta([]JSONPatchOperation{{Op: "add", Path: "/count", Value: 1}})))
+		require.NoError(t, store.ApplyDelta(store.NewDelta([]JSONPatchOperation{{Op: "replace", Path: "/count", Value: 2}})))
+		assert.Equal(t, uint64(2), store.Version())
+
+		previous, err := store.Store().At(1)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"count": 1.0}, previous)
+	})
+
+	t.Run("VersionsSerialize", func(t *testing.T) {
+		delta := NewStateDeltaEventWithOptions([]JSONPatchOperation{{Op: "add", Path: "/a", Value: 1}}, WithBaseVersion(4), WithNewVersion(5))
+		data, err := delta.ToJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"baseVersion":4`)
+		assert.Contains(t, string(data), `"newVersion":5`)
+
+		data, err = NewStateDeltaEvent([]JSONPatchOperation{{Op: "add", Path: "/a", Value: 1}}).ToJSON()
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "Version")
+	})
+}
@@ -0,0 +1,106 @@
+package events
+
+import (
+	"context"
+	"io"
+
+	"github.com/rs/zerolog"
+	"github.com/sirupsen/logrus"
+)
+
+// ZerologEventLogger adapts a zerolog logger to EventLogger
+type ZerologEventLogger struct {
+	Logger zerolog.Logger
+}
+
+// NewZerologEventLogger wraps a zerolog logger
+func NewZerologEventLogger(logger zerolog.Logger) *ZerologEventLogger {
+	return &ZerologEventLogger{Logger: logger}
+}
+
+// Debug logs a message at debug level
+func (l *ZerologEventLogger) Debug(msg string, fields map[string]any) {
+	l.Logger.Debug().Fields(fields).Msg(msg)
+}
+
+// Info logs a message at info level
+func (l *ZerologEventLogger) Info(msg string, fields map[string]any) {
+	l.Logger.Info().Fields(fields).Msg(msg)
+}
+
+// Warn logs a message at warning level
+func (l *ZerologEventLogger) Warn(msg string, fields map[string]any) {
+	l.Logger.Warn().Fields(fields).Msg(msg)
+}
+
+// Error logs a message at error level
+func (l *ZerologEventLogger) Error(msg string, fields map[string]any) {
+	l.Logger.Error().Fields(fields).Msg(msg)
+}
+
+// ZerologEventMiddleware logs every event passed to the handler at level,
+// with the fields of EventLogFields, before handing it on. Handler errors are
+// logged at error level and returned unchanged.
+func ZerologEventMiddleware(logger zerolog.Logger, level zerolog.Level) EventMiddleware {
+	return func(next EventHandler) EventHandler {
+		return EventHandlerFunc(func(ctx context.Context, event Event) error {
+			fields := EventLogFields(event)
+			logger.WithLevel(level).Fields(fields).Msg("event")
+
+			err := next.HandleEvent(ctx, event)
+			if err != nil {
+				logger.Error().Fields(fields).Err(err).Msg("event handler failed")
+			}
+			return err
+		})
+	}
+}
+
+// LogrusToZerologAdapter is a logrus hook forwarding every entry, with its
+// fields, to a zerolog logger. It lets code that takes a *logrus.Logger,
+// such as NewEventDecoder, log through zerolog; see NewLogrusToZerologLogger.
+type LogrusToZerologAdapter struct {
+	Logger zerolog.Logger
+}
+
+// NewLogrusToZerologLogger returns a logrus logger whose entries are written
+// by the zerolog logger only. The logrus logger passes entries of every
+// level on, leaving the filtering to zerolog.
+func NewLogrusToZerologLogger(logger zerolog.Logger) *logrus.Logger {
+	adapted := logrus.New()
+	adapted.SetOutput(io.Discard)
+	adapted.SetLevel(logrus.TraceLevel)
+	adapted.AddHook(&LogrusToZerologAdapter{Logger: logger})
+	return adapted
+}
+
+// Levels implements logrus.Hook for every level
+func (a *LogrusToZerologAdapter) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook by writing the entry with zerolog
+func (a *LogrusToZerologAdapter) Fire(entry *logrus.Entry) error {
+	a.Logger.WithLevel(zerologLevel(entry.Level)).Fields(map[string]any(entry.Data)).Msg(entry.Message)
+	return nil
+}
+
+// zerologLevel returns the zerolog level matching a logrus level
+func zerologLevel(level logrus.Level) zerolog.Level {
+	switch level {
+	case logrus.PanicLevel:
+		return zerolog.PanicLevel
+	case logrus.FatalLevel:
+		return zerolog.FatalLevel
+	case logrus.ErrorLevel:
+		return zerolog.ErrorLevel
+	case logrus.WarnLevel:
+		return zerolog.WarnLevel
+	case logrus.InfoLevel:
+		return zerolog.InfoLevel
+	case logrus.DebugLevel:
+		return zerolog.DebugLevel
+	default:
+		return zerolog.TraceLevel
+	}
+}
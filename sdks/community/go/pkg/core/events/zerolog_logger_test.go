@@ -0,0 +1,88 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// zerologLines decodes the JSON lines written by a zerolog logger
+func zerologLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+func TestZerologEventLogger(t *testing.T) {
+	var buf bytes.Buffer
+	var logger EventLogger = NewZerologEventLogger(zerolog.New(&buf).Level(zerolog.InfoLevel))
+	logger.Warn("event dropped", map[string]any{"type": "RUN_STARTED"})
+	logger.Debug("not shown at info level", nil)
+
+	assert.Equal(t, []map[string]any{
+		{"level": "warn", "message": "event dropped", "type": "RUN_STARTED"},
+	}, zerologLines(t, &buf))
+}
+
+func TestZerologEventMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	failure := errors.New("client gone")
+	handler := ZerologEventMiddleware(zerolog.New(&buf), zerolog.InfoLevel)(
+		EventHandlerFunc(func(ctx context.Context, e Event) error {
+			if e.Type() == EventTypeTextMessageContent {
+				return failure
+			}
+			return nil
+		}))
+
+	started := NewRunStartedEvent("thread-1", "run-1")
+	started.SetTimestamp(1700000000000)
+	require.NoError(t, handler.HandleEvent(context.Background(), started))
+	content := NewTextMessageContentEvent("msg-1", "Hi")
+	content.BaseEvent.TimestampMs = nil
+	assert.ErrorIs(t, handler.HandleEvent(context.Background(), content), failure)
+
+	assert.Equal(t, []map[string]any{
+		{
+			"level": "info", "message": "event", "type": "RUN_STARTED",
+			"run_id": "run-1", "thread_id": "thread-1", "timestamp": float64(1700000000000),
+		},
+		{"level": "info", "message": "event", "type": "TEXT_MESSAGE_CONTENT", "id": "msg-1"},
+		{
+			"level": "error", "message": "event handler failed", "type": "TEXT_MESSAGE_CONTENT",
+			"id": "msg-1", "error": "client gone",
+		},
+	}, zerologLines(t, &buf))
+}
+
+func TestLogrusToZerologAdapter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogrusToZerologLogger(zerolog.New(&buf).Level(zerolog.InfoLevel))
+	logger.WithField("event", "NOT_A_TYPE").Warn("Unknown event type")
+	logger.Debug("filtered by zerolog")
+
+	assert.Equal(t, []map[string]any{
+		{"level": "warn", "message": "Unknown event type", "event": "NOT_A_TYPE"},
+	}, zerologLines(t, &buf))
+
+	// Callers passing a logrus logger to the decoder log through zerolog
+	buf.Reset()
+	_, err := NewEventDecoder(logger).DecodeEvent("NOT_A_TYPE", []byte(`{}`))
+	require.Error(t, err)
+	assert.Contains(t, buf.String(), `"message":"Unknown event type"`)
+}
@@ -0,0 +1,195 @@
+package encoding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// EventWriter writes events to a sink, such as a live SSE connection or a
+// recorder. The SSE CoalescingEncoder and StreamEncoder sessions implement
+// it.
+type EventWriter interface {
+	// WriteEvent writes a single event
+	WriteEvent(ctx context.Context, event events.Event) error
+}
+
+// encoderWriter adapts an Encoder and an io.Writer to an EventWriter
+type encoderWriter struct {
+	encoder Encoder
+	output  io.Writer
+}
+
+// NewEncoderWriter returns an EventWriter that encodes each event with
+// encoder and writes it to output followed by a newline, as in JSON Lines
+// recordings
+func NewEncoderWriter(encoder Encoder, output io.Writer) EventWriter {
+	return &encoderWriter{encoder: encoder, output: output}
+}
+
+// WriteEvent encodes the event and writes it as a line
+func (w *encoderWriter) WriteEvent(ctx context.Context, event events.Event) error {
+	data, err := w.encoder.Encode(ctx, event)
+	if err != nil {
+		return err
+	}
+	if _, err := w.output.Write(append(data, '\n')); err != nil {
+		return &EncodingError{
+			Format:  w.encoder.ContentType(),
+			Event:   event,
+			Message: "failed to write encoded event",
+			Cause:   err,
+		}
+	}
+	return nil
+}
+
+// MultiEncoder writes every event to several sinks, for example to the live
+// SSE client and to a recorder. Sinks are written in order, and one failing
+// does not prevent the others from receiving the event.
+//
+// It implements Encoder when created with NewMultiEncoderWithPrimary: the
+// events are encoded by the primary encoder, whose output is returned to
+// the caller, and written to every sink as well. A MultiEncoder created
+// without a primary encoder fails to Encode.
+type MultiEncoder struct {
+	primary Encoder
+	sinks   []EventWriter
+}
+
+// NewMultiEncoder creates an encoder writing to the given sinks
+func NewMultiEncoder(sinks ...EventWriter) *MultiEncoder {
+	return &MultiEncoder{sinks: sinks}
+}
+
+// NewMultiEncoderWithPrimary creates an encoder returning the output of
+// primary from Encode and EncodeMultiple, and writing the events to the
+// given sinks
+func NewMultiEncoderWithPrimary(primary Encoder, sinks ...EventWriter) *MultiEncoder {
+	return &MultiEncoder{primary: primary, sinks: sinks}
+}
+
+// WriteEvent writes the event to every sink. If any sink fails, a
+// *MultiEncoderError describing every failure is returned.
+func (m *MultiEncoder) WriteEvent(ctx context.Context, event events.Event) error {
+	var errs []SinkError
+	for i, sink := range m.sinks {
+		if err := sink.WriteEvent(ctx, event); err != nil {
+			errs = append(errs, SinkError{Index: i, Err: err})
+		}
+	}
+
+	if len(errs) > 0 {
+		return &MultiEncoderError{Errors: errs}
+	}
+	return nil
+}
+
+// Encode implements Encoder. The event is encoded by the primary encoder,
+// and then written to every sink unless it failed to encode. If only sinks
+// fail, the encoded event is returned with a *MultiEncoderError.
+func (m *MultiEncoder) Encode(ctx context.Context, event events.Event) ([]byte, error) {
+	if m.primary == nil {
+		return nil, m.noPrimary(event)
+	}
+	data, err := m.primary.Encode(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	return data, m.WriteEvent(ctx, event)
+}
+
+// EncodeMultiple implements Encoder. The events are encoded together by the
+// primary encoder, and then written to every sink in order. If only sinks
+// fail, the encoded events are returned with a *MultiEncoderError holding
+// the failures for every event.
+func (m *MultiEncoder) EncodeMultiple(ctx context.Context, evts []events.Event) ([]byte, error) {
+	if m.primary == nil {
+		return nil, m.noPrimary(nil)
+	}
+	data, err := m.primary.EncodeMultiple(ctx, evts)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []SinkError
+	for _, event := range evts {
+		var multiErr *MultiEncoderError
+		if errors.As(m.WriteEvent(ctx, event), &multiErr) {
+			errs = append(errs, multiErr.Errors...)
+		}
+	}
+	if len(errs) > 0 {
+		return data, &MultiEncoderError{Errors: errs}
+	}
+	return data, nil
+}
+
+// ContentType implements Encoder, returning the MIME type of the primary
+// encoder, or an empty string without one
+func (m *MultiEncoder) ContentType() string {
+	if m.primary == nil {
+		return ""
+	}
+	return m.primary.ContentType()
+}
+
+// noPrimary returns the error of encoding without a primary encoder
+func (m *MultiEncoder) noPrimary(event events.Event) error {
+	return &EncodingError{
+		Event:   event,
+		Message: "multi encoder has no primary encoder",
+	}
+}
+
+// SinkError records the failure of a single sink of a MultiEncoder
+type SinkError struct {
+	// Index is the position of the failing sink
+	Index int
+
+	// Err is the error returned by the sink
+	Err error
+}
+
+// Error implements the error interface
+func (e *SinkError) Error() string {
+	return fmt.Sprintf("sink %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the error returned by the sink
+func (e *SinkError) Unwrap() error {
+	return e.Err
+}
+
+// MultiEncoderError collects the errors of every failing sink of a
+// MultiEncoder
+type MultiEncoderError struct {
+	Errors []SinkError
+}
+
+// Error implements the error interface
+func (e *MultiEncoderError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+
+	msgs := make([]string, len(e.Errors))
+	for i := range e.Errors {
+		msgs[i] = e.Errors[i].Error()
+	}
+	return fmt.Sprintf("%d sinks failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors of all failing sinks so that errors.Is and
+// errors.As match any of them
+func (e *MultiEncoderError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i := range e.Errors {
+		errs[i] = &e.Errors[i]
+	}
+	return errs
+}
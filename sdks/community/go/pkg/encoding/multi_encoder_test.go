@@ -0,0 +1,141 @@
+package encoding_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding/json"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding/sse"
+)
+
+// failingWriter is an io.Writer that always fails
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestMultiEncoder(t *testing.T) {
+	ctx := context.Background()
+	event := events.NewTextMessageContentEvent("msg-1", "Hello")
+
+	t.Run("WritesToEverySink", func(t *testing.T) {
+		var live, recording bytes.Buffer
+		multi := encoding.NewMultiEncoder(
+			sse.NewCoalescingEncoder(&live, sse.WithCoalesceMaxBytes(1)),
+			encoding.NewEncoderWriter(json.NewJSONEncoder(nil), &recording),
+		)
+
+		require.NoError(t, multi.WriteEvent(ctx, event))
+		require.NoError(t, multi.WriteEvent(ctx, events.NewTextMessageEndEvent("msg-1")))
+
+		assert.Contains(t, live.String(), "data: ")
+		assert.Contains(t, live.String(), `"delta":"Hello"`)
+
+		lines := strings.Split(strings.TrimSuffix(recording.String(), "\n"), "\n")
+		require.Len(t, lines, 2)
+		assert.Contains(t, lines[0], `"type":"TEXT_MESSAGE_CONTENT"`)
+		assert.Contains(t, lines[1], `"type":"TEXT_MESSAGE_END"`)
+	})
+
+	t.Run("OneSinkFailing", func(t *testing.T) {
+		var recording bytes.Buffer
+		multi := encoding.NewMultiEncoder(
+			encoding.NewEncoderWriter(json.NewJSONEncoder(nil), failingWriter{}),
+			encoding.NewEncoderWriter(json.NewJSONEncoder(nil), &recording),
+		)
+
+		err := multi.WriteEvent(ctx, event)
+		require.Error(t, err)
+		assert.Contains(t, recording.String(), `"delta":"Hello"`)
+
+		var multiErr *encoding.MultiEncoderError
+		require.ErrorAs(t, err, &multiErr)
+		require.Len(t, multiErr.Errors, 1)
+		assert.Equal(t, 0, multiErr.Errors[0].Index)
+		assert.Contains(t, err.Error(), "sink 0: encoding error: failed to write encoded event: connection reset")
+
+		var encodingErr *encoding.EncodingError
+		assert.ErrorAs(t, err, &encodingErr)
+	})
+
+	t.Run("EverySinkFailing", func(t *testing.T) {
+		multi := encoding.NewMultiEncoder(
+			encoding.NewEncoderWriter(json.NewJSONEncoder(nil), failingWriter{}),
+			encoding.NewEncoderWriter(json.NewJSONEncoder(nil), failingWriter{}),
+		)
+
+		// An invalid event fails to encode in every sink
+		err := multi.WriteEvent(ctx, events.NewTextMessageContentEvent("", "Hello"))
+		var multiErr *encoding.MultiEncoderError
+		require.ErrorAs(t, err, &multiErr)
+		assert.Len(t, multiErr.Errors, 2)
+		assert.Contains(t, err.Error(), "2 sinks failed")
+	})
+
+	t.Run("NoSinks", func(t *testing.T) {
+		assert.NoError(t, encoding.NewMultiEncoder().WriteEvent(ctx, event))
+	})
+
+	t.Run("Encoder", func(t *testing.T) {
+		var recording bytes.Buffer
+		primary := json.NewJSONEncoder(nil)
+		var encoder encoding.Encoder = encoding.NewMultiEncoderWithPrimary(primary,
+			encoding.NewEncoderWriter(json.NewJSONEncoder(nil), &recording))
+		assert.Equal(t, primary.ContentType(), encoder.ContentType())
+
+		data, err := encoder.Encode(ctx, event)
+		require.NoError(t, err)
+		expected, err := primary.Encode(ctx, event)
+		require.NoError(t, err)
+		assert.Equal(t, expected, data)
+		assert.Equal(t, string(expected)+"\n", recording.String())
+
+		recording.Reset()
+		batch := []events.Event{event, events.NewTextMessageEndEvent("msg-1")}
+		data, err = encoder.EncodeMultiple(ctx, batch)
+		require.NoError(t, err)
+		expected, err = primary.EncodeMultiple(ctx, batch)
+		require.NoError(t, err)
+		assert.Equal(t, expected, data)
+		assert.Len(t, strings.Split(strings.TrimSuffix(recording.String(), "\n"), "\n"), 2)
+
+		// Events failing to encode are not written to the sinks
+		recording.Reset()
+		_, err = encoder.Encode(ctx, events.NewTextMessageContentEvent("", "Hello"))
+		require.Error(t, err)
+		assert.Empty(t, recording.String())
+	})
+
+	t.Run("EncoderSinkFailing", func(t *testing.T) {
+		multi := encoding.NewMultiEncoderWithPrimary(json.NewJSONEncoder(nil),
+			encoding.NewEncoderWriter(json.NewJSONEncoder(nil), failingWriter{}))
+
+		data, err := multi.Encode(ctx, event)
+		assert.Contains(t, string(data), `"delta":"Hello"`)
+		var multiErr *encoding.MultiEncoderError
+		require.ErrorAs(t, err, &multiErr)
+
+		_, err = multi.EncodeMultiple(ctx, []events.Event{event, event})
+		require.ErrorAs(t, err, &multiErr)
+		assert.Len(t, multiErr.Errors, 2)
+	})
+
+	t.Run("EncoderWithoutPrimary", func(t *testing.T) {
+		multi := encoding.NewMultiEncoder()
+		_, err := multi.Encode(ctx, event)
+		var encodingErr *encoding.EncodingError
+		assert.ErrorAs(t, err, &encodingErr)
+		_, err = multi.EncodeMultiple(ctx, []events.Event{event})
+		assert.Error(t, err)
+		assert.Empty(t, multi.ContentType())
+	})
+}
@@ -0,0 +1,195 @@
+package sse
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+const (
+	// DefaultCoalesceMaxBytes is the default size, in bytes of delta text,
+	// at which buffered content is flushed
+	DefaultCoalesceMaxBytes = 1024
+
+	// DefaultCoalesceMaxDelay is the default time buffered content may wait
+	// before it is flushed
+	DefaultCoalesceMaxDelay = 50 * time.Millisecond
+)
+
+// CoalescingEncoder writes events as SSE frames, merging consecutive
+// TEXT_MESSAGE_CONTENT deltas for the same message into a single content
+// event with an events.ContentCoalescer. Content events carrying an edit are
+// written as they are. Buffered content is flushed once it reaches the size
+// threshold, once the oldest buffered delta has waited for the time
+// threshold, or as soon as any other event is written. Other events are never
+// delayed. It is safe for concurrent use.
+type CoalescingEncoder struct {
+	writer   *SSEWriter
+	output   io.Writer
+	maxBytes int
+	maxDelay time.Duration
+
+	mu        sync.Mutex
+	coalescer *events.ContentCoalescer
+	timer     *time.Timer
+	err       error
+}
+
+// CoalescingOption defines options for creating coalescing encoders
+type CoalescingOption func(*CoalescingEncoder)
+
+// WithCoalesceMaxBytes sets the amount of buffered delta text, in bytes, that
+// triggers a flush
+func WithCoalesceMaxBytes(n int) CoalescingOption {
+	return func(c *CoalescingEncoder) {
+		c.maxBytes = n
+	}
+}
+
+// WithCoalesceMaxDelay sets how long buffered content may wait before it is
+// flushed. A zero or negative delay disables the time threshold.
+func WithCoalesceMaxDelay(d time.Duration) CoalescingOption {
+	return func(c *CoalescingEncoder) {
+		c.maxDelay = d
+	}
+}
+
+// WithCoalesceSSEWriter sets the SSE writer used to frame events
+func WithCoalesceSSEWriter(w *SSEWriter) CoalescingOption {
+	return func(c *CoalescingEncoder) {
+		c.writer = w
+	}
+}
+
+// NewCoalescingEncoder creates a coalescing encoder writing to output. By
+// default content is flushed at 1024 bytes or after 50ms.
+func NewCoalescingEncoder(output io.Writer, opts ...CoalescingOption) *CoalescingEncoder {
+	c := &CoalescingEncoder{
+		output:   output,
+		maxBytes: DefaultCoalesceMaxBytes,
+		maxDelay: DefaultCoalesceMaxDelay,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.writer == nil {
+		c.writer = NewSSEWriter()
+	}
+	if c.maxBytes < 1 {
+		c.maxBytes = 1
+	}
+	interval := c.maxDelay
+	if interval < 0 {
+		interval = 0
+	}
+	c.coalescer = events.NewContentCoalescer(
+		events.WithContentMaxBytes(c.maxBytes),
+		events.WithContentInterval(interval),
+	)
+
+	return c
+}
+
+// WriteEvent buffers content deltas and writes all other events, including
+// content edits, immediately after flushing any buffered content. It returns
+// the error of a previous time-triggered flush, if one failed.
+func (c *CoalescingEncoder) WriteEvent(ctx context.Context, event events.Event) error {
+	if event == nil {
+		return fmt.Errorf("event cannot be nil")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.takeError(); err != nil {
+		return err
+	}
+
+	out, err := c.coalescer.Process(event)
+	if err != nil {
+		return err
+	}
+	return c.writeLocked(ctx, out)
+}
+
+// Flush writes any buffered content immediately
+func (c *CoalescingEncoder) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.takeError(); err != nil {
+		return err
+	}
+	return c.flushLocked(ctx)
+}
+
+// Close flushes any buffered content and stops the flush timer. The encoder
+// must not be used afterwards.
+func (c *CoalescingEncoder) Close(ctx context.Context) error {
+	return c.Flush(ctx)
+}
+
+// flushLocked writes the buffered content as a single event. The caller must
+// hold the lock.
+func (c *CoalescingEncoder) flushLocked(ctx context.Context) error {
+	return c.writeLocked(ctx, c.coalescer.Flush())
+}
+
+// writeLocked writes the events released by the coalescer, then starts the
+// flush timer if content is still buffered, or stops it otherwise. The caller
+// must hold the lock.
+func (c *CoalescingEncoder) writeLocked(ctx context.Context, out []events.Event) error {
+	if c.timer != nil && (len(out) > 0 || !c.coalescer.Pending()) {
+		c.timer.Stop()
+		c.timer = nil
+	}
+
+	for _, event := range out {
+		if err := c.writer.WriteEvent(ctx, c.output, event); err != nil {
+			return err
+		}
+	}
+
+	if c.timer == nil && c.coalescer.Pending() {
+		c.startTimer()
+	}
+	return nil
+}
+
+// startTimer schedules a time-triggered flush of the buffered content. The
+// caller must hold the lock.
+func (c *CoalescingEncoder) startTimer() {
+	if c.maxDelay <= 0 {
+		return
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(c.maxDelay, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		// A flush since the timer was started makes it stale
+		if c.timer != timer {
+			return
+		}
+		c.timer = nil
+		if err := c.writeLocked(context.Background(), c.coalescer.FlushIfDue()); err != nil && c.err == nil {
+			c.err = err
+		}
+	})
+	c.timer = timer
+}
+
+// takeError returns and clears the error of a time-triggered flush. The
+// caller must hold the lock.
+func (c *CoalescingEncoder) takeError() error {
+	err := c.err
+	c.err = nil
+	return err
+}
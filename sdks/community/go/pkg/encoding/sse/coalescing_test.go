@@ -0,0 +1,235 @@
+package sse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// syncBuffer is a bytes.Buffer that is safe to write from timer goroutines
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// frames decodes the data line of every SSE frame written so far
+func (b *syncBuffer) frames(t *testing.T) []map[string]interface{} {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var frames []map[string]interface{}
+	for _, line := range strings.Split(b.buf.String(), "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+		var frame map[string]interface{}
+		if err := json.Unmarshal([]byte(data), &frame); err != nil {
+			t.Fatalf("invalid frame data %q: %v", data, err)
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+func TestCoalescingEncoder(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("merges deltas until size threshold", func(t *testing.T) {
+		var out syncBuffer
+		enc := NewCoalescingEncoder(&out, WithCoalesceMaxBytes(5), WithCoalesceMaxDelay(0))
+
+		for _, delta := range []string{"H", "e", "l", "l"} {
+			if err := enc.WriteEvent(ctx, events.NewTextMessageContentEvent("msg-1", delta)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if frames := out.frames(t); len(frames) != 0 {
+			t.Fatalf("expected no frames below threshold, got %d", len(frames))
+		}
+
+		if err := enc.WriteEvent(ctx, events.NewTextMessageContentEvent("msg-1", "o")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		frames := out.frames(t)
+		if len(frames) != 1 {
+			t.Fatalf("expected 1 frame at threshold, got %d", len(frames))
+		}
+		if frames[0]["type"] != string(events.EventTypeTextMessageContent) || frames[0]["delta"] != "Hello" {
+			t.Errorf("unexpected merged frame: %v", frames[0])
+		}
+		if frames[0]["messageId"] != "msg-1" {
+			t.Errorf("expected messageId msg-1, got %v", frames[0]["messageId"])
+		}
+	})
+
+	t.Run("keeps split characters buffered", func(t *testing.T) {
+		var out syncBuffer
+		enc := NewCoalescingEncoder(&out, WithCoalesceMaxBytes(3), WithCoalesceMaxDelay(0))
+
+		euro := "€"
+		for _, delta := range []string{"ab", euro[:1], euro[1:]} {
+			if err := enc.WriteEvent(ctx, events.NewTextMessageContentEvent("msg-1", delta)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if err := enc.Flush(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		frames := out.frames(t)
+		if len(frames) != 2 || frames[0]["delta"] != "ab" || frames[1]["delta"] != euro {
+			t.Errorf("unexpected frames: %v", frames)
+		}
+	})
+
+	t.Run("non-content event forces flush", func(t *testing.T) {
+		var out syncBuffer
+		enc := NewCoalescingEncoder(&out, WithCoalesceMaxDelay(0))
+
+		_ = enc.WriteEvent(ctx, events.NewTextMessageContentEvent("msg-1", "Hi"))
+		_ = enc.WriteEvent(ctx, events.NewTextMessageContentEvent("msg-1", " there"))
+		if err := enc.WriteEvent(ctx, events.NewTextMessageEndEvent("msg-1")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		frames := out.frames(t)
+		if len(frames) != 2 {
+			t.Fatalf("expected 2 frames, got %d", len(frames))
+		}
+		if frames[0]["delta"] != "Hi there" {
+			t.Errorf("expected merged delta before the end event, got %v", frames[0]["delta"])
+		}
+		if frames[1]["type"] != string(events.EventTypeTextMessageEnd) {
+			t.Errorf("expected end event second, got %v", frames[1]["type"])
+		}
+	})
+
+	t.Run("different message flushes", func(t *testing.T) {
+		var out syncBuffer
+		enc := NewCoalescingEncoder(&out, WithCoalesceMaxDelay(0))
+
+		_ = enc.WriteEvent(ctx, events.NewTextMessageContentEvent("msg-1", "a"))
+		_ = enc.WriteEvent(ctx, events.NewTextMessageContentEvent("msg-1", "b"))
+		_ = enc.WriteEvent(ctx, events.NewTextMessageContentEvent("msg-2", "c"))
+		if err := enc.Flush(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		frames := out.frames(t)
+		if len(frames) != 2 {
+			t.Fatalf("expected 2 frames, got %d", len(frames))
+		}
+		if frames[0]["messageId"] != "msg-1" || frames[0]["delta"] != "ab" {
+			t.Errorf("unexpected first frame: %v", frames[0])
+		}
+		if frames[1]["messageId"] != "msg-2" || frames[1]["delta"] != "c" {
+			t.Errorf("unexpected second frame: %v", frames[1])
+		}
+	})
+
+	t.Run("edits are not merged", func(t *testing.T) {
+		var out syncBuffer
+		enc := NewCoalescingEncoder(&out, WithCoalesceMaxDelay(0))
+
+		_ = enc.WriteEvent(ctx, events.NewTextMessageContentEvent("msg-1", "The answer is 41"))
+		edit := events.NewTextMessageContentEventWithOptions("msg-1", "", events.WithTextEdit(events.TextEdit{Offset: 14, Delete: 2, Insert: "42"}))
+		if err := enc.WriteEvent(ctx, edit); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := enc.WriteEvent(ctx, events.NewTextMessageContentEvent("msg-1", "!")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := enc.Flush(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		frames := out.frames(t)
+		if len(frames) != 3 {
+			t.Fatalf("expected 3 frames, got %d", len(frames))
+		}
+		if frames[0]["delta"] != "The answer is 41" {
+			t.Errorf("unexpected first frame: %v", frames[0])
+		}
+		if e, ok := frames[1]["edit"].(map[string]interface{}); !ok || e["insert"] != "42" || frames[1]["delta"] != nil {
+			t.Errorf("expected edit frame, got %v", frames[1])
+		}
+		if frames[2]["delta"] != "!" || frames[2]["edit"] != nil {
+			t.Errorf("unexpected last frame: %v", frames[2])
+		}
+	})
+
+	t.Run("flushes after time threshold", func(t *testing.T) {
+		var out syncBuffer
+		enc := NewCoalescingEncoder(&out, WithCoalesceMaxDelay(20*time.Millisecond))
+
+		_ = enc.WriteEvent(ctx, events.NewTextMessageContentEvent("msg-1", "x"))
+		_ = enc.WriteEvent(ctx, events.NewTextMessageContentEvent("msg-1", "y"))
+		if frames := out.frames(t); len(frames) != 0 {
+			t.Fatalf("expected no frames before the delay, got %d", len(frames))
+		}
+
+		deadline := time.Now().Add(2 * time.Second)
+		for len(out.frames(t)) == 0 && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		frames := out.frames(t)
+		if len(frames) != 1 || frames[0]["delta"] != "xy" {
+			t.Fatalf("expected one merged frame after the delay, got %v", frames)
+		}
+	})
+
+	t.Run("keeps timestamp of first delta", func(t *testing.T) {
+		var out syncBuffer
+		enc := NewCoalescingEncoder(&out, WithCoalesceMaxDelay(0))
+
+		first := events.NewTextMessageContentEvent("msg-1", "a")
+		first.SetTimestamp(1000)
+		second := events.NewTextMessageContentEvent("msg-1", "b")
+		second.SetTimestamp(2000)
+		_ = enc.WriteEvent(ctx, first)
+		_ = enc.WriteEvent(ctx, second)
+		_ = enc.Close(ctx)
+
+		frames := out.frames(t)
+		if len(frames) != 1 || frames[0]["timestamp"] != float64(1000) {
+			t.Fatalf("expected merged frame with first timestamp, got %v", frames)
+		}
+		if first.Delta != "a" {
+			t.Errorf("input event was modified: %q", first.Delta)
+		}
+	})
+
+	t.Run("reports failed timed flush", func(t *testing.T) {
+		enc := NewCoalescingEncoder(&errorWriter{err: errors.New("broken pipe")}, WithCoalesceMaxDelay(time.Millisecond))
+
+		_ = enc.WriteEvent(ctx, events.NewTextMessageContentEvent("msg-1", "a"))
+		time.Sleep(50 * time.Millisecond)
+
+		err := enc.WriteEvent(ctx, events.NewTextMessageEndEvent("msg-1"))
+		if err == nil || !strings.Contains(err.Error(), "broken pipe") {
+			t.Errorf("expected timed flush error, got %v", err)
+		}
+	})
+
+	t.Run("nil event", func(t *testing.T) {
+		enc := NewCoalescingEncoder(&syncBuffer{})
+		if err := enc.WriteEvent(ctx, nil); err == nil {
+			t.Error("expected error for nil event")
+		}
+	})
+}
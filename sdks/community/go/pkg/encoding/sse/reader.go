@@ -0,0 +1,196 @@
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// ErrTruncatedStream is returned when a stream ends in the middle of a frame.
+// The events of all complete frames before it have already been delivered,
+// so callers can resume from the last one.
+var ErrTruncatedStream = errors.New("SSE stream truncated mid-frame")
+
+// ErrLineTooLong is returned when a line of the stream exceeds the maximum
+// line length of the decoder, to protect against servers sending unbounded
+// lines
+var ErrLineTooLong = errors.New("SSE line too long")
+
+// DefaultMaxLineBytes is the default maximum length of a line, excluding its
+// terminator. It leaves room for large state snapshots, which are sent on a
+// single data line.
+const DefaultMaxLineBytes = 8 << 20
+
+// SSEDecoder reads Server-Sent Events frames, as written by SSEWriter, and
+// decodes their data into events
+type SSEDecoder struct {
+	logger        *slog.Logger
+	maxLineBytes  int
+	channelBuffer int
+}
+
+// NewSSEDecoder creates a new SSE decoder
+func NewSSEDecoder() *SSEDecoder {
+	return &SSEDecoder{
+		logger:       slog.Default(),
+		maxLineBytes: DefaultMaxLineBytes,
+	}
+}
+
+// WithLogger sets a custom logger for the SSE decoder
+func (d *SSEDecoder) WithLogger(logger *slog.Logger) *SSEDecoder {
+	d.logger = logger
+	return d
+}
+
+// WithMaxLineBytes sets the maximum length of a line, excluding its
+// terminator, beyond which DecodeStream fails with ErrLineTooLong. Zero or
+// less removes the limit.
+func (d *SSEDecoder) WithMaxLineBytes(n int) *SSEDecoder {
+	d.maxLineBytes = n
+	return d
+}
+
+// WithChannelBuffer sets the buffer size of the event channel returned by
+// Stream, letting the decoder run up to n events ahead of a slow consumer at
+// the cost of holding them in memory. The default of zero makes the channel
+// unbuffered.
+func (d *SSEDecoder) WithChannelBuffer(n int) *SSEDecoder {
+	d.channelBuffer = max(n, 0)
+	return d
+}
+
+// Stream decodes input in a new goroutine with DecodeStream, delivering the
+// events on the returned channel, whose buffer size is set with
+// WithChannelBuffer. The event channel is closed when decoding ends, after
+// which the error channel delivers the outcome of DecodeStream, if it is not
+// nil, and is closed.
+func (d *SSEDecoder) Stream(ctx context.Context, input io.Reader) (<-chan events.Event, <-chan error) {
+	output := make(chan events.Event, d.channelBuffer)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		err := d.DecodeStream(ctx, input, output)
+		close(output)
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	return output, errs
+}
+
+// DecodeStream decodes frames from input and sends their events to output
+// until input is exhausted or the context is cancelled. It does not close
+// output. If input ends in the middle of a frame that holds data, the
+// incomplete frame is discarded and an error wrapping ErrTruncatedStream is
+// returned; trailing comments, such as keep-alives, and fields other than
+// data are not truncation. A line
+// longer than the maximum line length fails with an error wrapping
+// ErrLineTooLong, without reading the rest of the line.
+func (d *SSEDecoder) DecodeStream(ctx context.Context, input io.Reader, output chan<- events.Event) error {
+	if input == nil {
+		return fmt.Errorf("reader cannot be nil")
+	}
+
+	reader := bufio.NewReader(input)
+	var data bytes.Buffer
+	pending := 0 // bytes read for the current, incomplete frame
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		line, err := d.readLine(reader)
+		if errors.Is(err, ErrLineTooLong) {
+			d.logger.WarnContext(ctx, "SSE line exceeds maximum length",
+				"max_line_bytes", d.maxLineBytes)
+			return err
+		}
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("SSE read failed: %w", err)
+		}
+		if err == io.EOF {
+			pending += len(line)
+			if data.Len() > 0 || isPartialDataLine(line) {
+				d.logger.WarnContext(ctx, "SSE stream ended mid-frame",
+					"pending_bytes", pending)
+				return fmt.Errorf("%w: %d bytes of incomplete frame discarded", ErrTruncatedStream, pending)
+			}
+			return nil
+		}
+		pending += len(line)
+
+		line = bytes.TrimSuffix(line, []byte("\n"))
+		line = bytes.TrimSuffix(line, []byte("\r"))
+
+		// A blank line completes the frame
+		if len(line) == 0 {
+			if data.Len() > 0 {
+				event, err := events.EventFromJSON(data.Bytes())
+				if err != nil {
+					d.logger.ErrorContext(ctx, "Failed to decode SSE frame",
+						"error", err)
+					return fmt.Errorf("SSE frame decode failed: %w", err)
+				}
+
+				select {
+				case output <- event:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			data.Reset()
+			pending = 0
+			continue
+		}
+
+		// Only data fields carry the event; comments and the event, id and
+		// retry fields are ignored
+		if value, ok := bytes.CutPrefix(line, dataField); ok {
+			value = bytes.TrimPrefix(value, []byte(" "))
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.Write(value)
+		}
+	}
+}
+
+// dataField is the prefix of the lines carrying the event payload
+var dataField = []byte("data:")
+
+// isPartialDataLine reports whether an unterminated last line may be the
+// start of a data field, whose payload is lost with it
+func isPartialDataLine(line []byte) bool {
+	return bytes.HasPrefix(line, dataField) || (len(line) > 0 && bytes.HasPrefix(dataField, line))
+}
+
+// readLine reads a line including its terminator like ReadBytes, failing as
+// soon as its content exceeds the maximum line length
+func (d *SSEDecoder) readLine(reader *bufio.Reader) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		line = append(line, chunk...)
+
+		if d.maxLineBytes > 0 {
+			content := bytes.TrimSuffix(bytes.TrimSuffix(line, []byte("\n")), []byte("\r"))
+			if len(content) > d.maxLineBytes {
+				return nil, fmt.Errorf("%w: exceeds %d bytes", ErrLineTooLong, d.maxLineBytes)
+			}
+		}
+
+		if err != bufio.ErrBufferFull {
+			return line, err
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package sse
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// writeFrames encodes events as SSE frames
+func writeFrames(t *testing.T, evts ...events.Event) string {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := NewSSEWriter()
+	for _, event := range evts {
+		if err := writer.WriteEvent(context.Background(), &buf, event); err != nil {
+			t.Fatalf("failed to write event: %v", err)
+		}
+	}
+	return buf.String()
+}
+
+// decodeAll runs DecodeStream over input and collects the decoded events
+func decodeAll(input string) ([]events.Event, error) {
+	output := make(chan events.Event, 16)
+	err := NewSSEDecoder().DecodeStream(context.Background(), strings.NewReader(input), output)
+	close(output)
+
+	var decoded []events.Event
+	for event := range output {
+		decoded = append(decoded, event)
+	}
+	return decoded, err
+}
+
+func TestSSEDecoder_DecodeStream(t *testing.T) {
+	stream := writeFrames(t,
+		events.NewTextMessageStartEvent("msg-1", events.WithRole("assistant")),
+		events.NewTextMessageContentEvent("msg-1", "Hello"),
+	)
+
+	t.Run("complete stream", func(t *testing.T) {
+		decoded, err := decodeAll(stream + writeFrames(t, events.NewTextMessageEndEvent("msg-1")))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(decoded) != 3 {
+			t.Fatalf("expected 3 events, got %d", len(decoded))
+		}
+		if decoded[2].Type() != events.EventTypeTextMessageEnd {
+			t.Errorf("expected TEXT_MESSAGE_END last, got %s", decoded[2].Type())
+		}
+	})
+
+	t.Run("truncated data line", func(t *testing.T) {
+		decoded, err := decodeAll(stream + `data: {"type":"TEXT_MESSAGE_CONTENT","messageId":"msg-1","del`)
+		if !errors.Is(err, ErrTruncatedStream) {
+			t.Fatalf("expected ErrTruncatedStream, got %v", err)
+		}
+		if len(decoded) != 2 {
+			t.Fatalf("expected the 2 complete events, got %d", len(decoded))
+		}
+		content, ok := decoded[1].(*events.TextMessageContentEvent)
+		if !ok || content.Delta != "Hello" {
+			t.Errorf("unexpected second event: %v", decoded[1])
+		}
+	})
+
+	t.Run("frame missing terminating blank line", func(t *testing.T) {
+		decoded, err := decodeAll(stream + "data: {\"type\":\"TEXT_MESSAGE_END\",\"messageId\":\"msg-1\"}\n")
+		if !errors.Is(err, ErrTruncatedStream) {
+			t.Fatalf("expected ErrTruncatedStream, got %v", err)
+		}
+		if len(decoded) != 2 {
+			t.Errorf("expected the 2 complete events, got %d", len(decoded))
+		}
+	})
+
+	t.Run("trailing comment is not truncation", func(t *testing.T) {
+		for _, trailer := range []string{":keepalive\n", ":keepalive", "event: message\n"} {
+			decoded, err := decodeAll(stream + trailer)
+			if err != nil {
+				t.Errorf("%q: unexpected error: %v", trailer, err)
+			}
+			if len(decoded) != 2 {
+				t.Errorf("%q: expected the 2 complete events, got %d", trailer, len(decoded))
+			}
+		}
+	})
+
+	t.Run("partial data field name", func(t *testing.T) {
+		if _, err := decodeAll(stream + "da"); !errors.Is(err, ErrTruncatedStream) {
+			t.Fatalf("expected ErrTruncatedStream, got %v", err)
+		}
+	})
+
+	t.Run("invalid frame is not truncation", func(t *testing.T) {
+		decoded, err := decodeAll(stream + "data: {not json}\n\n")
+		if err == nil || errors.Is(err, ErrTruncatedStream) {
+			t.Fatalf("expected a decode error, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "SSE frame decode failed") {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if len(decoded) != 2 {
+			t.Errorf("expected the 2 complete events, got %d", len(decoded))
+		}
+	})
+
+	t.Run("ignores comments and other fields", func(t *testing.T) {
+		input := ": keep-alive\r\nevent: message\r\nid: 1\r\ndata:{\"type\":\"STEP_STARTED\",\"stepName\":\"plan\"}\r\n\r\n\n"
+		decoded, err := decodeAll(input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(decoded) != 1 || decoded[0].Type() != events.EventTypeStepStarted {
+			t.Errorf("unexpected events: %v", decoded)
+		}
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := NewSSEDecoder().DecodeStream(ctx, strings.NewReader(stream), make(chan events.Event))
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+}
+
+func TestSSEDecoder_MaxLineBytes(t *testing.T) {
+	stream := writeFrames(t, events.NewStepStartedEvent("plan"))
+
+	decode := func(decoder *SSEDecoder, input string) ([]events.Event, error) {
+		output := make(chan events.Event, 16)
+		err := decoder.DecodeStream(context.Background(), strings.NewReader(input), output)
+		close(output)
+
+		var decoded []events.Event
+		for event := range output {
+			decoded = append(decoded, event)
+		}
+		return decoded, err
+	}
+
+	t.Run("over-long line", func(t *testing.T) {
+		long := "data: " + strings.Repeat("x", 10_000) + "\n\n"
+		decoded, err := decode(NewSSEDecoder().WithMaxLineBytes(1024), stream+long)
+		if !errors.Is(err, ErrLineTooLong) {
+			t.Fatalf("expected ErrLineTooLong, got %v", err)
+		}
+		if len(decoded) != 1 {
+			t.Errorf("expected the event before the long line, got %d events", len(decoded))
+		}
+	})
+
+	t.Run("unterminated over-long line", func(t *testing.T) {
+		_, err := decode(NewSSEDecoder().WithMaxLineBytes(1024), "data: "+strings.Repeat("x", 5000))
+		if !errors.Is(err, ErrLineTooLong) {
+			t.Fatalf("expected ErrLineTooLong, got %v", err)
+		}
+	})
+
+	t.Run("line at the limit", func(t *testing.T) {
+		longest := 0
+		for _, line := range strings.Split(stream, "\n") {
+			longest = max(longest, len(line))
+		}
+		decoded, err := decode(NewSSEDecoder().WithMaxLineBytes(longest), strings.ReplaceAll(stream, "\n", "\r\n"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(decoded) != 1 {
+			t.Errorf("expected 1 event, got %d", len(decoded))
+		}
+
+		_, err = decode(NewSSEDecoder().WithMaxLineBytes(longest-1), stream)
+		if !errors.Is(err, ErrLineTooLong) {
+			t.Errorf("expected ErrLineTooLong one byte below the line length, got %v", err)
+		}
+	})
+
+	t.Run("default and disabled limit", func(t *testing.T) {
+		long := writeFrames(t, events.NewTextMessageContentEvent("msg-1", strings.Repeat("x", 100_000)))
+		if _, err := decode(NewSSEDecoder(), long); err != nil {
+			t.Errorf("unexpected error with the default limit: %v", err)
+		}
+		if _, err := decode(NewSSEDecoder().WithMaxLineBytes(0), long); err != nil {
+			t.Errorf("unexpected error without a limit: %v", err)
+		}
+	})
+}
+
+func TestSSEDecoder_Stream(t *testing.T) {
+	const buffer = 3
+	var evts []events.Event
+	for i := 0; i < 10; i++ {
+		evts = append(evts, events.NewTextMessageContentEvent("msg-1", "x"))
+	}
+	stream := writeFrames(t, evts...)
+
+	t.Run("runs ahead of the consumer up to the buffer size", func(t *testing.T) {
+		output, errs := NewSSEDecoder().WithChannelBuffer(buffer).Stream(context.Background(), strings.NewReader(stream))
+		if cap(output) != buffer {
+			t.Fatalf("expected a buffer of %d, got %d", buffer, cap(output))
+		}
+
+		// Without a consumer the decoder fills the buffer and then blocks
+		deadline := time.Now().Add(time.Second)
+		for len(output) < buffer && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(20 * time.Millisecond)
+		if len(output) != buffer {
+			t.Fatalf("expected %d buffered events, got %d", buffer, len(output))
+		}
+
+		received := 0
+		for range output {
+			received++
+		}
+		if received != len(evts) {
+			t.Errorf("expected %d events, got %d", len(evts), received)
+		}
+		if err := <-errs; err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("unbuffered by default", func(t *testing.T) {
+		output, errs := NewSSEDecoder().Stream(context.Background(), strings.NewReader(stream))
+		if cap(output) != 0 {
+			t.Errorf("expected an unbuffered channel, got a buffer of %d", cap(output))
+		}
+		for range output {
+		}
+		if err := <-errs; err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		output, errs := NewSSEDecoder().WithChannelBuffer(buffer).Stream(context.Background(), strings.NewReader(stream+"data: {"))
+		for range output {
+		}
+		if err := <-errs; !errors.Is(err, ErrTruncatedStream) {
+			t.Errorf("expected ErrTruncatedStream, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// WebSocket frame types, with the values of the RFC 6455 opcodes as used by
+// common WebSocket libraries such as gorilla/websocket
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+)
+
+// FrameReader reads whole WebSocket messages. It is satisfied by
+// *websocket.Conn from gorilla/websocket, so that this package does not
+// depend on a particular WebSocket library.
+type FrameReader interface {
+	ReadMessage() (messageType int, data []byte, err error)
+}
+
+// WSDecoder reads WebSocket text frames, each carrying one event encoded as
+// JSON with its type in the "type" field, and decodes them into events. By
+// default a frame that cannot be decoded fails the stream; see
+// WithFrameErrorHandler to skip such frames instead.
+type WSDecoder struct {
+	logger      *slog.Logger
+	decoder     *events.EventDecoder
+	frameErrors func(data []byte, err error) error
+}
+
+// NewWSDecoder creates a new WebSocket decoder
+func NewWSDecoder() *WSDecoder {
+	return &WSDecoder{
+		logger:  slog.Default(),
+		decoder: events.NewEventDecoder(nil),
+	}
+}
+
+// WithLogger sets a custom logger for the WebSocket decoder
+func (d *WSDecoder) WithLogger(logger *slog.Logger) *WSDecoder {
+	d.logger = logger
+	return d
+}
+
+// WithEventDecoder sets the event decoder used for frame payloads, for
+// example to install decode hooks
+func (d *WSDecoder) WithEventDecoder(decoder *events.EventDecoder) *WSDecoder {
+	d.decoder = decoder
+	return d
+}
+
+// WithFrameErrorHandler sets a function that is called with the payload and
+// decode error of every text frame that cannot be decoded. If it returns nil,
+// the frame is skipped and decoding continues; otherwise DecodeStream fails
+// with the returned error. Long-lived connections, such as those of the
+// websocket transport, use it to survive a single bad frame.
+func (d *WSDecoder) WithFrameErrorHandler(handler func(data []byte, err error) error) *WSDecoder {
+	d.frameErrors = handler
+	return d
+}
+
+// DecodeStream reads frames from reader and sends their events to output
+// until the reader returns io.EOF or the context is cancelled. It does not
+// close output. Frames other than text frames are skipped. A read that is
+// already blocked is not interrupted by cancellation, so callers should close
+// the underlying connection when they cancel the context.
+func (d *WSDecoder) DecodeStream(ctx context.Context, reader FrameReader, output chan<- events.Event) error {
+	if reader == nil {
+		return fmt.Errorf("reader cannot be nil")
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		messageType, data, err := reader.ReadMessage()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("WebSocket read failed: %w", err)
+		}
+
+		if messageType != TextMessage {
+			d.logger.DebugContext(ctx, "Ignoring non-text WebSocket frame",
+				"message_type", messageType)
+			continue
+		}
+
+		event, err := d.decoder.DecodeTypedJSON(data)
+		if err != nil {
+			if d.frameErrors != nil {
+				if err = d.frameErrors(data, err); err == nil {
+					continue
+				}
+			}
+			d.logger.ErrorContext(ctx, "Failed to decode WebSocket frame",
+				"error", err)
+			return fmt.Errorf("WebSocket frame decode failed: %w", err)
+		}
+
+		select {
+		case output <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
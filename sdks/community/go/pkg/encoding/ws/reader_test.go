@@ -0,0 +1,181 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// frame is a message returned by fakeFrameReader
+type frame struct {
+	messageType int
+	data        string
+}
+
+// fakeFrameReader returns its frames in order, then err
+type fakeFrameReader struct {
+	frames []frame
+	err    error
+}
+
+func (r *fakeFrameReader) ReadMessage() (int, []byte, error) {
+	if len(r.frames) == 0 {
+		return 0, nil, r.err
+	}
+	f := r.frames[0]
+	r.frames = r.frames[1:]
+	return f.messageType, []byte(f.data), nil
+}
+
+// textFrames encodes events as text frames
+func textFrames(t *testing.T, evts ...events.Event) []frame {
+	t.Helper()
+	frames := make([]frame, 0, len(evts))
+	for _, event := range evts {
+		data, err := event.ToJSON()
+		if err != nil {
+			t.Fatalf("failed to encode event: %v", err)
+		}
+		frames = append(frames, frame{messageType: TextMessage, data: string(data)})
+	}
+	return frames
+}
+
+// decodeAll runs DecodeStream over reader and collects the decoded events
+func decodeAll(decoder *WSDecoder, reader FrameReader) ([]events.Event, error) {
+	output := make(chan events.Event, 16)
+	err := decoder.DecodeStream(context.Background(), reader, output)
+	close(output)
+
+	var decoded []events.Event
+	for event := range output {
+		decoded = append(decoded, event)
+	}
+	return decoded, err
+}
+
+func TestWSDecoder_DecodeStream(t *testing.T) {
+	stream := textFrames(t,
+		events.NewTextMessageStartEvent("msg-1", events.WithRole("assistant")),
+		events.NewTextMessageContentEvent("msg-1", "Hello"),
+		events.NewTextMessageEndEvent("msg-1"),
+	)
+
+	t.Run("complete stream", func(t *testing.T) {
+		decoded, err := decodeAll(NewWSDecoder(), &fakeFrameReader{frames: stream, err: io.EOF})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(decoded) != 3 {
+			t.Fatalf("expected 3 events, got %d", len(decoded))
+		}
+		content, ok := decoded[1].(*events.TextMessageContentEvent)
+		if !ok || content.Delta != "Hello" {
+			t.Errorf("unexpected second event: %v", decoded[1])
+		}
+		if decoded[2].Type() != events.EventTypeTextMessageEnd {
+			t.Errorf("expected TEXT_MESSAGE_END last, got %s", decoded[2].Type())
+		}
+	})
+
+	t.Run("skips non-text frames", func(t *testing.T) {
+		frames := append([]frame{{messageType: BinaryMessage, data: "\x00\x01"}}, stream...)
+		decoded, err := decodeAll(NewWSDecoder(), &fakeFrameReader{frames: frames, err: io.EOF})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(decoded) != 3 {
+			t.Errorf("expected 3 events, got %d", len(decoded))
+		}
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		readErr := errors.New("connection reset")
+		decoded, err := decodeAll(NewWSDecoder(), &fakeFrameReader{frames: stream[:1], err: readErr})
+		if !errors.Is(err, readErr) {
+			t.Fatalf("expected the read error, got %v", err)
+		}
+		if len(decoded) != 1 {
+			t.Errorf("expected 1 event before the error, got %d", len(decoded))
+		}
+	})
+
+	t.Run("invalid frame", func(t *testing.T) {
+		frames := append(stream[:1:1], frame{messageType: TextMessage, data: "{not json}"})
+		decoded, err := decodeAll(NewWSDecoder(), &fakeFrameReader{frames: frames, err: io.EOF})
+		if err == nil || !strings.Contains(err.Error(), "WebSocket frame decode failed") {
+			t.Fatalf("expected a decode error, got %v", err)
+		}
+		var decodeErr *events.DecodeError
+		if !errors.As(err, &decodeErr) {
+			t.Errorf("expected a *events.DecodeError, got %T", err)
+		}
+		if len(decoded) != 1 {
+			t.Errorf("expected 1 event before the error, got %d", len(decoded))
+		}
+	})
+
+	t.Run("frame error handler", func(t *testing.T) {
+		frames := append(stream[:1:1], frame{messageType: TextMessage, data: "{not json}"})
+		frames = append(frames, stream[1:]...)
+
+		var skipped []string
+		decoder := NewWSDecoder().WithFrameErrorHandler(func(data []byte, err error) error {
+			skipped = append(skipped, string(data))
+			return nil
+		})
+		decoded, err := decodeAll(decoder, &fakeFrameReader{frames: frames, err: io.EOF})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(decoded) != 3 {
+			t.Errorf("expected 3 events, got %d", len(decoded))
+		}
+		if len(skipped) != 1 || skipped[0] != "{not json}" {
+			t.Errorf("unexpected skipped frames: %q", skipped)
+		}
+
+		errRejected := errors.New("rejected")
+		decoder = NewWSDecoder().WithFrameErrorHandler(func([]byte, error) error { return errRejected })
+		if _, err := decodeAll(decoder, &fakeFrameReader{frames: frames, err: io.EOF}); !errors.Is(err, errRejected) {
+			t.Errorf("expected the handler's error, got %v", err)
+		}
+	})
+
+	t.Run("missing type", func(t *testing.T) {
+		frames := []frame{{messageType: TextMessage, data: `{"messageId":"msg-1"}`}}
+		_, err := decodeAll(NewWSDecoder(), &fakeFrameReader{frames: frames, err: io.EOF})
+		if err == nil || !strings.Contains(err.Error(), "missing event type") {
+			t.Errorf("expected a missing type error, got %v", err)
+		}
+	})
+
+	t.Run("custom event decoder", func(t *testing.T) {
+		var seen []events.EventType
+		decoder := NewWSDecoder().WithEventDecoder(events.NewEventDecoder(nil,
+			events.WithPostDecodeHook(func(e events.Event) (events.Event, error) {
+				seen = append(seen, e.Type())
+				return e, nil
+			}),
+		))
+		if _, err := decodeAll(decoder, &fakeFrameReader{frames: stream, err: io.EOF}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(seen) != 3 {
+			t.Errorf("expected the hook to see 3 events, got %v", seen)
+		}
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := NewWSDecoder().DecodeStream(ctx, &fakeFrameReader{frames: stream, err: io.EOF}, make(chan events.Event))
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+}
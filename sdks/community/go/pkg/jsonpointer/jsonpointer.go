@@ -0,0 +1,168 @@
+// Package jsonpointer implements RFC 6901 JSON Pointers over generic JSON
+// values, as produced by decoding into any: objects are map[string]any and
+// arrays are []any.
+package jsonpointer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Escape escapes a reference token for use in a JSON Pointer, encoding ~ as
+// ~0 and / as ~1
+func Escape(token string) string {
+	if !strings.ContainsAny(token, "~/") {
+		return token
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// Unescape decodes an escaped reference token. It fails if the token contains
+// a ~ that is not followed by 0 or 1.
+func Unescape(token string) (string, error) {
+	if !strings.Contains(token, "~") {
+		return token, nil
+	}
+
+	var b strings.Builder
+	b.Grow(len(token))
+	for i := 0; i < len(token); i++ {
+		if token[i] != '~' {
+			b.WriteByte(token[i])
+			continue
+		}
+		if i+1 == len(token) {
+			return "", fmt.Errorf("invalid escape in token %q: ~ at end of token", token)
+		}
+		switch token[i+1] {
+		case '0':
+			b.WriteByte('~')
+		case '1':
+			b.WriteByte('/')
+		default:
+			return "", fmt.Errorf("invalid escape in token %q: ~%c", token, token[i+1])
+		}
+		i++
+	}
+	return b.String(), nil
+}
+
+// Parse splits a JSON Pointer into its unescaped reference tokens. The empty
+// pointer refers to the whole document and has no tokens; any other pointer
+// must start with /.
+func Parse(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must start with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		unescaped, err := Unescape(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON pointer %q: %w", pointer, err)
+		}
+		tokens[i] = unescaped
+	}
+	return tokens, nil
+}
+
+// Get returns the value the pointer refers to in doc. It reports false if the
+// pointer is invalid or does not resolve to a value.
+func Get(doc any, pointer string) (any, bool) {
+	tokens, err := Parse(pointer)
+	if err != nil {
+		return nil, false
+	}
+
+	current := doc
+	for _, token := range tokens {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []any:
+			index, ok := ParseIndex(token)
+			if !ok || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// ParseIndex parses a reference token as an array index. Per RFC 6901,
+// indexes are non-negative decimal integers without leading zeros.
+func ParseIndex(token string) (int, bool) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, false
+	}
+	for i := 0; i < len(token); i++ {
+		if token[i] < '0' || token[i] > '9' {
+			return 0, false
+		}
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, false
+	}
+	return index, true
+}
+
+// Pointer is an immutable JSON Pointer builder. Each method returns a new
+// pointer, so a common prefix can be shared between several paths.
+type Pointer struct {
+	tokens []string
+}
+
+// Ptr returns the root pointer, to be extended with Key and Index
+func Ptr() Pointer {
+	return Pointer{}
+}
+
+// Key returns the pointer extended with an object member name, which is
+// escaped as needed
+func (p Pointer) Key(name string) Pointer {
+	return p.with(name)
+}
+
+// Index returns the pointer extended with an array index
+func (p Pointer) Index(i int) Pointer {
+	return p.with(strconv.Itoa(i))
+}
+
+// End returns the pointer extended with "-", which refers to the position
+// after the last array element in an add operation
+func (p Pointer) End() Pointer {
+	return p.with("-")
+}
+
+// Tokens returns a copy of the unescaped reference tokens
+func (p Pointer) Tokens() []string {
+	return append([]string(nil), p.tokens...)
+}
+
+// String returns the pointer in its escaped string form
+func (p Pointer) String() string {
+	var b strings.Builder
+	for _, token := range p.tokens {
+		b.WriteByte('/')
+		b.WriteString(Escape(token))
+	}
+	return b.String()
+}
+
+func (p Pointer) with(token string) Pointer {
+	tokens := make([]string, len(p.tokens), len(p.tokens)+1)
+	copy(tokens, p.tokens)
+	return Pointer{tokens: append(tokens, token)}
+}
@@ -0,0 +1,156 @@
+package jsonpointer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6901Document is the example document from RFC 6901, section 5
+const rfc6901Document = `{
+	"foo": ["bar", "baz"],
+	"": 0,
+	"a/b": 1,
+	"c%d": 2,
+	"e^f": 3,
+	"g|h": 4,
+	"i\\j": 5,
+	"k\"l": 6,
+	" ": 7,
+	"m~n": 8
+}`
+
+func TestGet(t *testing.T) {
+	var doc any
+	require.NoError(t, json.Unmarshal([]byte(rfc6901Document), &doc))
+
+	t.Run("RFC6901Examples", func(t *testing.T) {
+		tests := []struct {
+			pointer  string
+			expected any
+		}{
+			{"", doc},
+			{"/foo", []any{"bar", "baz"}},
+			{"/foo/0", "bar"},
+			{"/", 0.0},
+			{"/a~1b", 1.0},
+			{"/c%d", 2.0},
+			{"/e^f", 3.0},
+			{"/g|h", 4.0},
+			{"/i\\j", 5.0},
+			{"/k\"l", 6.0},
+			{"/ ", 7.0},
+			{"/m~0n", 8.0},
+		}
+
+		for _, tt := range tests {
+			value, ok := Get(doc, tt.pointer)
+			assert.True(t, ok, tt.pointer)
+			assert.Equal(t, tt.expected, value, tt.pointer)
+		}
+	})
+
+	t.Run("EmptyKeys", func(t *testing.T) {
+		nested := map[string]any{"": map[string]any{"": "deep", "x": []any{"y"}}}
+
+		value, ok := Get(nested, "//")
+		assert.True(t, ok)
+		assert.Equal(t, "deep", value)
+
+		value, ok = Get(nested, "//x/0")
+		assert.True(t, ok)
+		assert.Equal(t, "y", value)
+	})
+
+	t.Run("Unresolved", func(t *testing.T) {
+		for _, pointer := range []string{
+			"foo", // missing leading slash
+			"/missing",
+			"/foo/2",  // out of bounds
+			"/foo/-",  // past the end
+			"/foo/01", // leading zero
+			"/foo/+1",
+			"/foo/0/x", // through a string
+			"/m~2n",    // invalid escape
+		} {
+			_, ok := Get(doc, pointer)
+			assert.False(t, ok, pointer)
+		}
+	})
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		pointer  string
+		expected []string
+	}{
+		{"", nil},
+		{"/", []string{""}},
+		{"//", []string{"", ""}},
+		{"/foo/0", []string{"foo", "0"}},
+		{"/a~1b/m~0n", []string{"a/b", "m~n"}},
+		{"/~01", []string{"~1"}},
+		{"/~10", []string{"/0"}},
+	}
+	for _, tt := range tests {
+		tokens, err := Parse(tt.pointer)
+		require.NoError(t, err, tt.pointer)
+		assert.Equal(t, tt.expected, tokens, tt.pointer)
+	}
+
+	for _, pointer := range []string{"foo", "#/foo", "/a~", "/a~2b"} {
+		_, err := Parse(pointer)
+		assert.Error(t, err, pointer)
+	}
+}
+
+func TestEscape(t *testing.T) {
+	for _, token := range []string{"", "plain", "a/b", "m~n", "~1", "/~", "~~//"} {
+		escaped := Escape(token)
+		assert.NotContains(t, escaped, "/")
+
+		unescaped, err := Unescape(escaped)
+		require.NoError(t, err)
+		assert.Equal(t, token, unescaped)
+	}
+	assert.Equal(t, "~01", Escape("~1"))
+	assert.Equal(t, "a~1b", Escape("a/b"))
+}
+
+func TestPointerBuilder(t *testing.T) {
+	assert.Equal(t, "", Ptr().String())
+	assert.Equal(t, "/users/3/a~1b", Ptr().Key("users").Index(3).Key("a/b").String())
+	assert.Equal(t, "/", Ptr().Key("").String())
+	assert.Equal(t, "/items/-", Ptr().Key("items").End().String())
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		p := Ptr().Key("m~n").Key("").Key("a/b").Index(0)
+		tokens, err := Parse(p.String())
+		require.NoError(t, err)
+		assert.Equal(t, p.Tokens(), tokens)
+	})
+
+	t.Run("Immutable", func(t *testing.T) {
+		base := Ptr().Key("users")
+		first := base.Index(0)
+		second := base.Index(1)
+		assert.Equal(t, "/users", base.String())
+		assert.Equal(t, "/users/0", first.String())
+		assert.Equal(t, "/users/1", second.String())
+	})
+
+	t.Run("ResolvesWithGet", func(t *testing.T) {
+		var doc any
+		require.NoError(t, json.Unmarshal([]byte(rfc6901Document), &doc))
+
+		value, ok := Get(doc, Ptr().Key("a/b").String())
+		assert.True(t, ok)
+		assert.Equal(t, 1.0, value)
+
+		value, ok = Get(doc, Ptr().Key("foo").Index(1).String())
+		assert.True(t, ok)
+		assert.Equal(t, "baz", value)
+	})
+}
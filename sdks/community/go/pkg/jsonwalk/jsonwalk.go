@@ -0,0 +1,262 @@
+// Package jsonwalk traverses generic JSON values, as produced by decoding
+// into any: objects are map[string]any and arrays are []any. The traversal
+// is iterative, so deeply nested documents cannot exhaust the stack, and it
+// can be bounded in depth and in the number of values visited, to protect
+// against pathological documents.
+package jsonwalk
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/jsonpointer"
+)
+
+// SkipChildren can be returned by a visit function to skip the members of
+// the object or array being visited. It is not returned by the walk.
+var SkipChildren = errors.New("skip children")
+
+// SkipAll can be returned by a visit function to end the walk early. It is
+// not returned by the walk.
+var SkipAll = errors.New("skip all")
+
+// ErrMaxDepth is returned when the document is nested deeper than the
+// maximum depth
+var ErrMaxDepth = errors.New("document too deeply nested")
+
+// ErrMaxNodes is returned when the document holds more values than the
+// maximum number of nodes
+var ErrMaxNodes = errors.New("document has too many values")
+
+// Option configures a walk
+type Option func(*config)
+
+// config holds the options of a walk
+type config struct {
+	maxDepth int
+	maxNodes int
+}
+
+// WithMaxDepth fails the walk with ErrMaxDepth when it reaches a value
+// nested in more than n objects and arrays. The document itself is at depth
+// 0. Zero or less removes the limit.
+func WithMaxDepth(n int) Option {
+	return func(c *config) {
+		c.maxDepth = n
+	}
+}
+
+// WithMaxNodes fails the walk with ErrMaxNodes when it is about to visit more
+// than n values, counting the document itself, every object and array, and
+// every scalar. Zero or less removes the limit.
+func WithMaxNodes(n int) Option {
+	return func(c *config) {
+		c.maxNodes = n
+	}
+}
+
+// Walk calls visit for every value of the document in depth-first order,
+// parents before their members, object members in key order and array
+// elements in index order. The path holds the unescaped reference tokens
+// leading to the value; it is only valid during the call and must be copied
+// to be kept. Values other than objects and arrays, including structs and
+// typed maps, are visited as leaves.
+//
+// If visit returns SkipChildren, the members of the value are skipped; if it
+// returns SkipAll, the walk ends and Walk returns nil. Any other error ends
+// the walk and is returned as is.
+func Walk(doc any, visit func(path []string, v any) error, options ...Option) error {
+	w := newWalker(options)
+	w.sorted = true
+	_, err := w.run(doc, func(path []string, v any) (any, error) {
+		return v, visit(path, v)
+	})
+	return err
+}
+
+// Transform returns a copy of the document in which every value has been
+// replaced with the result of visit. Values are visited parents first, and
+// the members visited are those of the replacement, so a visit function can
+// convert a value, such as a struct, into an object whose members it then
+// sees. Every object and array of the result is new: the result shares no
+// maps or slices with the document, which is not modified. Siblings are
+// visited in no particular order; paths are as in Walk.
+//
+// If visit returns SkipChildren with a replacement, the replacement is used
+// as is, without visiting or copying its members. If it returns SkipAll, the
+// replacement is used as is and every value not yet visited is kept as it
+// is, shared with the document. Any other error ends the walk and is returned
+// with a nil result.
+func Transform(doc any, visit func(path []string, v any) (any, error), options ...Option) (any, error) {
+	w := newWalker(options)
+	w.copying = true
+	return w.run(doc, visit)
+}
+
+// walker holds the state of a walk
+type walker struct {
+	config
+	sorted  bool
+	copying bool
+
+	stack []frame
+	path  []string
+	nodes int
+}
+
+// frame is a value waiting to be visited, with where to store its
+// replacement in the copied parent, if copying
+type frame struct {
+	value any
+	depth int
+	key   string
+
+	object map[string]any
+	array  []any
+	index  int
+}
+
+// newWalker creates a walker with the given options
+func newWalker(options []Option) *walker {
+	w := &walker{}
+	for _, opt := range options {
+		opt(&w.config)
+	}
+	return w
+}
+
+// run performs the walk
+func (w *walker) run(doc any, visit func(path []string, v any) (any, error)) (any, error) {
+	var result any
+	w.stack = make([]frame, 1, 16)
+	w.stack[0] = frame{value: doc}
+
+	for len(w.stack) > 0 {
+		f := w.stack[len(w.stack)-1]
+		w.stack = w.stack[:len(w.stack)-1]
+
+		// Frames are popped depth first, so the path of the parent is
+		// always the prefix of the path buffer
+		w.path = w.path[:max(f.depth-1, 0)]
+		if f.depth > 0 {
+			w.path = append(w.path, f.key)
+		}
+
+		if w.maxDepth > 0 && f.depth > w.maxDepth {
+			return nil, fmt.Errorf("%w: value at %q exceeds depth %d", ErrMaxDepth, pointer(w.path), w.maxDepth)
+		}
+		w.nodes++
+		if w.maxNodes > 0 && w.nodes > w.maxNodes {
+			return nil, fmt.Errorf("%w: more than %d values", ErrMaxNodes, w.maxNodes)
+		}
+
+		value, err := visit(w.path, f.value)
+		skipChildren := false
+		switch {
+		case err == nil:
+		case errors.Is(err, SkipChildren):
+			skipChildren = true
+		case errors.Is(err, SkipAll):
+			w.store(f, value, &result)
+			w.keepRemaining()
+			return result, nil
+		default:
+			return nil, err
+		}
+
+		if skipChildren {
+			w.store(f, value, &result)
+			continue
+		}
+
+		switch v := value.(type) {
+		case map[string]any:
+			w.pushObject(f, v, &result)
+		case []any:
+			w.pushArray(f, v, &result)
+		default:
+			w.store(f, value, &result)
+		}
+	}
+
+	return result, nil
+}
+
+// pushObject stores the copy of an object and schedules its members
+func (w *walker) pushObject(f frame, v map[string]any, result *any) {
+	var target map[string]any
+	if w.copying && v != nil {
+		target = make(map[string]any, len(v))
+		w.store(f, target, result)
+	} else {
+		w.store(f, v, result)
+	}
+
+	if !w.sorted {
+		for key, item := range v {
+			w.stack = append(w.stack, frame{value: item, depth: f.depth + 1, key: key, object: target})
+		}
+		return
+	}
+
+	keys := make([]string, 0, len(v))
+	for key := range v {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Push in reverse so that members are popped in key order
+	for i := len(keys) - 1; i >= 0; i-- {
+		w.stack = append(w.stack, frame{value: v[keys[i]], depth: f.depth + 1, key: keys[i], object: target})
+	}
+}
+
+// pushArray stores the copy of an array and schedules its elements
+func (w *walker) pushArray(f frame, v []any, result *any) {
+	var target []any
+	if w.copying && v != nil {
+		target = make([]any, len(v))
+		w.store(f, target, result)
+	} else {
+		w.store(f, v, result)
+	}
+
+	// Push in reverse so that elements are popped in index order
+	for i := len(v) - 1; i >= 0; i-- {
+		w.stack = append(w.stack, frame{value: v[i], depth: f.depth + 1, key: strconv.Itoa(i), array: target, index: i})
+	}
+}
+
+// store places the replacement of a value into its copied parent, or makes
+// it the result if it is the document itself
+func (w *walker) store(f frame, value any, result *any) {
+	switch {
+	case f.depth == 0:
+		*result = value
+	case f.object != nil:
+		f.object[f.key] = value
+	case f.array != nil:
+		f.array[f.index] = value
+	}
+}
+
+// keepRemaining stores the values that were not visited as they are
+func (w *walker) keepRemaining() {
+	for _, f := range w.stack {
+		w.store(f, f.value, nil)
+	}
+	w.stack = nil
+}
+
+// pointer formats a path as a JSON Pointer
+func pointer(path []string) string {
+	var b strings.Builder
+	for _, token := range path {
+		b.WriteByte('/')
+		b.WriteString(jsonpointer.Escape(token))
+	}
+	return b.String()
+}
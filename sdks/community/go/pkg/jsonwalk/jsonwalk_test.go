@@ -0,0 +1,336 @@
+package jsonwalk
+
+import (
+	"encoding/json"
+	"errors"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decode(t testing.TB, data string) any {
+	t.Helper()
+	var doc any
+	require.NoError(t, json.Unmarshal([]byte(data), &doc))
+	return doc
+}
+
+// visited collects the pointer of every value visited by Walk
+func visited(t *testing.T, doc any, options ...Option) []string {
+	t.Helper()
+	var pointers []string
+	require.NoError(t, Walk(doc, func(path []string, _ any) error {
+		pointers = append(pointers, pointer(path))
+		return nil
+	}, options...))
+	return pointers
+}
+
+func TestWalk(t *testing.T) {
+	doc := decode(t, `{"b": [1, {"c": null}], "a": "x", "d/e": {}}`)
+
+	assert.Equal(t, []string{"", "/a", "/b", "/b/0", "/b/1", "/b/1/c", "/d~1e"}, visited(t, doc))
+	assert.Equal(t, []string{""}, visited(t, "scalar"))
+	assert.Equal(t, []string{""}, visited(t, nil))
+
+	t.Run("skip children", func(t *testing.T) {
+		var pointers []string
+		err := Walk(doc, func(path []string, v any) error {
+			pointers = append(pointers, pointer(path))
+			if _, ok := v.([]any); ok {
+				return SkipChildren
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"", "/a", "/b", "/d~1e"}, pointers)
+	})
+
+	t.Run("skip all", func(t *testing.T) {
+		var pointers []string
+		err := Walk(doc, func(path []string, _ any) error {
+			pointers = append(pointers, pointer(path))
+			if len(path) == 2 {
+				return SkipAll
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"", "/a", "/b", "/b/0"}, pointers)
+	})
+
+	t.Run("visit error", func(t *testing.T) {
+		errStop := errors.New("stop")
+		err := Walk(doc, func(path []string, _ any) error {
+			if pointer(path) == "/b/1" {
+				return errStop
+			}
+			return nil
+		})
+		assert.Equal(t, errStop, err)
+	})
+
+	t.Run("non-generic values are leaves", func(t *testing.T) {
+		typed := map[string]any{"s": struct{ A []any }{A: []any{1}}, "m": map[string]int{"x": 1}}
+		assert.Equal(t, []string{"", "/m", "/s"}, visited(t, typed))
+	})
+}
+
+func TestWalk_Limits(t *testing.T) {
+	// Depth 3: the document, /a, /a/b and /a/b/0
+	doc := decode(t, `{"a": {"b": [1, 2]}, "c": 3}`)
+
+	require.Len(t, visited(t, doc, WithMaxDepth(3), WithMaxNodes(6)), 6)
+
+	err := Walk(doc, func([]string, any) error { return nil }, WithMaxDepth(2))
+	require.ErrorIs(t, err, ErrMaxDepth)
+	assert.Contains(t, err.Error(), `"/a/b/0"`)
+
+	err = Walk(doc, func([]string, any) error { return nil }, WithMaxNodes(5))
+	assert.ErrorIs(t, err, ErrMaxNodes)
+
+	// Skipped members are not counted
+	err = Walk(doc, func(path []string, _ any) error {
+		if len(path) == 1 {
+			return SkipChildren
+		}
+		return nil
+	}, WithMaxDepth(1), WithMaxNodes(3))
+	assert.NoError(t, err)
+
+	_, err = Transform(doc, func(_ []string, v any) (any, error) { return v, nil }, WithMaxDepth(2))
+	assert.ErrorIs(t, err, ErrMaxDepth)
+	_, err = Transform(doc, func(_ []string, v any) (any, error) { return v, nil }, WithMaxNodes(5))
+	assert.ErrorIs(t, err, ErrMaxNodes)
+}
+
+func TestTransform(t *testing.T) {
+	t.Run("copies", func(t *testing.T) {
+		doc := decode(t, `{"a": [1, {"b": "x"}], "c": {}, "d": []}`)
+		result, err := Transform(doc, func(_ []string, v any) (any, error) { return v, nil })
+		require.NoError(t, err)
+		assert.Equal(t, doc, result)
+
+		result.(map[string]any)["a"].([]any)[1].(map[string]any)["b"] = "changed"
+		result.(map[string]any)["c"].(map[string]any)["new"] = true
+		assert.Equal(t, decode(t, `{"a": [1, {"b": "x"}], "c": {}, "d": []}`), doc)
+	})
+
+	t.Run("replaces and descends into replacements", func(t *testing.T) {
+		type point struct{ X, Y int }
+		doc := map[string]any{"p": point{X: 1, Y: 2}, "secret": "hunter2", "n": 1.5}
+
+		var paths []string
+		result, err := Transform(doc, func(path []string, v any) (any, error) {
+			paths = append(paths, pointer(path))
+			if len(path) > 0 && path[len(path)-1] == "secret" {
+				return "***", nil
+			}
+			if p, ok := v.(point); ok {
+				return map[string]any{"x": p.X, "y": p.Y}, nil
+			}
+			if n, ok := v.(int); ok {
+				return n * 10, nil
+			}
+			return v, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"p": map[string]any{"x": 10, "y": 20}, "secret": "***", "n": 1.5}, result)
+		assert.ElementsMatch(t, []string{"", "/n", "/p", "/p/x", "/p/y", "/secret"}, paths)
+		assert.Equal(t, "hunter2", doc["secret"])
+	})
+
+	t.Run("skip children keeps the replacement as is", func(t *testing.T) {
+		shared := []any{"kept"}
+		result, err := Transform(map[string]any{"a": []any{1}}, func(path []string, v any) (any, error) {
+			if len(path) == 1 {
+				return shared, SkipChildren
+			}
+			return v, nil
+		})
+		require.NoError(t, err)
+		result.(map[string]any)["a"].([]any)[0] = "same slice"
+		assert.Equal(t, "same slice", shared[0])
+	})
+
+	t.Run("skip all keeps the remaining values", func(t *testing.T) {
+		doc := decode(t, `[1, 2, 3]`)
+		result, err := Transform(doc, func(path []string, v any) (any, error) {
+			if pointer(path) == "/1" {
+				return "stop", SkipAll
+			}
+			return v, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []any{float64(1), "stop", float64(3)}, result)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		errBad := errors.New("bad")
+		result, err := Transform(decode(t, `{"a": 1}`), func(path []string, v any) (any, error) {
+			if len(path) == 1 {
+				return nil, errBad
+			}
+			return v, nil
+		})
+		assert.Nil(t, result)
+		assert.Equal(t, errBad, err)
+	})
+
+	t.Run("nil containers stay nil", func(t *testing.T) {
+		result, err := Transform(map[string]any{"m": map[string]any(nil), "s": []any(nil)}, func(_ []string, v any) (any, error) { return v, nil })
+		require.NoError(t, err)
+		assert.Nil(t, result.(map[string]any)["m"])
+		assert.Nil(t, result.(map[string]any)["s"])
+	})
+}
+
+// nested builds a document of alternating arrays and objects nested depth
+// levels deep, without recursion
+func nested(depth int) any {
+	var doc any = "leaf"
+	for i := 0; i < depth; i++ {
+		if i%2 == 0 {
+			doc = []any{doc}
+		} else {
+			doc = map[string]any{"k": doc}
+		}
+	}
+	return doc
+}
+
+func TestWalk_DeepNesting(t *testing.T) {
+	// A recursive walk needs well over 1 MiB of stack for this document and
+	// would crash the test binary
+	const depth = 200_000
+	defer debug.SetMaxStack(debug.SetMaxStack(1 << 20))
+
+	doc := nested(depth)
+
+	deepest := 0
+	require.NoError(t, Walk(doc, func(path []string, _ any) error {
+		deepest = max(deepest, len(path))
+		return nil
+	}))
+	assert.Equal(t, depth, deepest)
+
+	copied, err := Transform(doc, func(_ []string, v any) (any, error) { return v, nil })
+	require.NoError(t, err)
+	for i := 0; i < depth; i++ {
+		switch v := copied.(type) {
+		case []any:
+			copied = v[0]
+		case map[string]any:
+			copied = v["k"]
+		default:
+			t.Fatalf("unexpected value at depth %d: %v", i, v)
+		}
+	}
+	assert.Equal(t, "leaf", copied)
+
+	err = Walk(doc, func([]string, any) error { return nil }, WithMaxDepth(1000))
+	assert.ErrorIs(t, err, ErrMaxDepth)
+}
+
+// buildDocument turns fuzz input into the JSON text of an array: [ and {
+// open an array or an object, ] and } close the innermost container, and any
+// other byte adds a number. It returns the expected depth and number of
+// values.
+func buildDocument(data []byte) (text string, depth, nodes int) {
+	var b strings.Builder
+	b.WriteByte('[')
+	closers := []byte{']'}
+	counts := []int{0}
+	nodes = 1
+
+	open := func() {
+		level := len(counts) - 1
+		if counts[level] > 0 {
+			b.WriteByte(',')
+		}
+		if closers[level] == '}' {
+			b.WriteString(`"` + strconv.Itoa(counts[level]) + `":`)
+		}
+		counts[level]++
+		nodes++
+		depth = max(depth, len(counts))
+	}
+
+	for _, c := range data {
+		switch c {
+		case '[', '{':
+			open()
+			b.WriteByte(c)
+			closers = append(closers, c+2) // ] and } follow [ and { by two
+			counts = append(counts, 0)
+		case ']', '}':
+			if len(closers) > 1 {
+				b.WriteByte(closers[len(closers)-1])
+				closers = closers[:len(closers)-1]
+				counts = counts[:len(counts)-1]
+			}
+		default:
+			open()
+			b.WriteString(strconv.Itoa(int(c)))
+		}
+	}
+
+	for i := len(closers) - 1; i >= 0; i-- {
+		b.WriteByte(closers[i])
+	}
+	return b.String(), depth, nodes
+}
+
+func FuzzWalk(f *testing.F) {
+	f.Add([]byte("ab[c{d}e]"), 2, 5)
+	f.Add([]byte(strings.Repeat("[{", 500)), 100, 1000)
+	f.Add([]byte(strings.Repeat("[", 4000)+"x"), 4000, 4002)
+	f.Add([]byte("{}{}[][]"), 1, 4)
+
+	f.Fuzz(func(t *testing.T, data []byte, maxDepth, maxNodes int) {
+		text, depth, nodes := buildDocument(data)
+		if depth > 5000 {
+			t.Skip("nesting beyond what encoding/json decodes")
+		}
+		doc := decode(t, text)
+
+		count, deepest := 0, 0
+		var pointers []string
+		require.NoError(t, Walk(doc, func(path []string, _ any) error {
+			count++
+			deepest = max(deepest, len(path))
+			pointers = append(pointers, pointer(path))
+			return nil
+		}))
+		require.Equal(t, nodes, count)
+		require.Equal(t, depth, deepest)
+
+		err := Walk(doc, func([]string, any) error { return nil }, WithMaxDepth(maxDepth))
+		if maxDepth > 0 && depth > maxDepth {
+			require.ErrorIs(t, err, ErrMaxDepth)
+		} else {
+			require.NoError(t, err)
+		}
+
+		err = Walk(doc, func([]string, any) error { return nil }, WithMaxNodes(maxNodes))
+		if maxNodes > 0 && nodes > maxNodes {
+			require.ErrorIs(t, err, ErrMaxNodes)
+		} else {
+			require.NoError(t, err)
+		}
+
+		// A copy has the same structure
+		copied, err := Transform(doc, func(_ []string, v any) (any, error) { return v, nil })
+		require.NoError(t, err)
+		var copiedPointers []string
+		require.NoError(t, Walk(copied, func(path []string, _ any) error {
+			copiedPointers = append(copiedPointers, pointer(path))
+			return nil
+		}))
+		require.Equal(t, pointers, copiedPointers)
+	})
+}
@@ -0,0 +1,218 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// generatorWords is the vocabulary used for synthetic message content
+var generatorWords = []string{
+	"the", "agent", "is", "checking", "weather", "forecast", "for", "today",
+	"results", "show", "a", "chance", "of", "rain", "in", "the", "afternoon",
+	"please", "confirm", "your", "location", "and", "preferred", "units",
+}
+
+// generatorMaxMessages is the maximum number of assistant messages in a run
+const generatorMaxMessages = 3
+
+// generatorTools are the tool names used for synthetic tool calls
+var generatorTools = []string{"get_weather", "search_docs", "lookup_user", "create_ticket"}
+
+// SyntheticEventGenerator produces realistic, properly sequenced AG-UI event
+// streams for load testing. Each run starts with RUN_STARTED, streams one or
+// more assistant messages as start/content/end triplets, optionally calls
+// tools, and ends with RUN_FINISHED. Runs are interleaved when more than one
+// is active, and a finished run is replaced by a new one.
+type SyntheticEventGenerator struct {
+	runCount     int
+	toolCallProb float64
+	minWords     int
+	maxWords     int
+	seed         int64
+}
+
+// GeneratorOption defines options for creating synthetic event generators
+type GeneratorOption func(*SyntheticEventGenerator)
+
+// WithRunCount sets how many runs are active at the same time
+func WithRunCount(n int) GeneratorOption {
+	return func(g *SyntheticEventGenerator) {
+		g.runCount = n
+	}
+}
+
+// WithToolCallProbability sets the probability, between 0 and 1, that an
+// assistant message is followed by a tool call
+func WithToolCallProbability(p float64) GeneratorOption {
+	return func(g *SyntheticEventGenerator) {
+		g.toolCallProb = p
+	}
+}
+
+// WithMessageLength sets the range of words in each assistant message
+func WithMessageLength(minWords, maxWords int) GeneratorOption {
+	return func(g *SyntheticEventGenerator) {
+		g.minWords = minWords
+		g.maxWords = maxWords
+	}
+}
+
+// WithSeed sets the random seed, making the generated sequence reproducible
+func WithSeed(seed int64) GeneratorOption {
+	return func(g *SyntheticEventGenerator) {
+		g.seed = seed
+	}
+}
+
+// NewSyntheticEventGenerator creates a new synthetic event generator. By
+// default it runs a single run at a time, calls tools for a quarter of the
+// messages, writes messages of 5 to 30 words and seeds from the clock.
+func NewSyntheticEventGenerator(opts ...GeneratorOption) *SyntheticEventGenerator {
+	g := &SyntheticEventGenerator{
+		runCount:     1,
+		toolCallProb: 0.25,
+		minWords:     5,
+		maxWords:     30,
+		seed:         time.Now().UnixNano(),
+	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	if g.runCount < 1 {
+		g.runCount = 1
+	}
+	if g.minWords < 1 {
+		g.minWords = 1
+	}
+	if g.maxWords < g.minWords {
+		g.maxWords = g.minWords
+	}
+
+	return g
+}
+
+// Generate streams synthetic events at the given rate in events per second
+// until the context is cancelled, then closes the channel. A rate of zero or
+// less, or one above an event per nanosecond, emits events as fast as the
+// receiver consumes them.
+func (g *SyntheticEventGenerator) Generate(ctx context.Context, rate float64) <-chan events.Event {
+	out := make(chan events.Event)
+
+	go func() {
+		defer close(out)
+
+		var tick <-chan time.Time
+		if interval := time.Duration(float64(time.Second) / rate); rate > 0 && interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		state := &generatorState{generator: g, rng: rand.New(rand.NewSource(g.seed))}
+		runs := make([][]events.Event, g.runCount)
+		for {
+			// Pick one of the active runs, starting a new one when it is done
+			slot := state.rng.Intn(len(runs))
+			if len(runs[slot]) == 0 {
+				runs[slot] = state.newRun()
+			}
+			event := runs[slot][0]
+			runs[slot] = runs[slot][1:]
+
+			if tick != nil {
+				select {
+				case <-tick:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// generatorState holds the random source and ID counters of one Generate call
+type generatorState struct {
+	generator *SyntheticEventGenerator
+	rng       *rand.Rand
+	runs      int
+}
+
+// newRun scripts the events of the next run. IDs are derived from the run
+// number so that a seeded generator is fully reproducible.
+func (s *generatorState) newRun() []events.Event {
+	s.runs++
+	threadID := fmt.Sprintf("thread-%d", s.runs)
+	runID := fmt.Sprintf("run-%d", s.runs)
+
+	script := []events.Event{events.NewRunStartedEvent(threadID, runID)}
+
+	messages := 1 + s.rng.Intn(generatorMaxMessages)
+	for m := 1; m <= messages; m++ {
+		messageID := fmt.Sprintf("%s-msg-%d", runID, m)
+		script = append(script, events.NewTextMessageStartEvent(messageID, events.WithRole(events.RoleAssistant)))
+		for _, delta := range s.contentDeltas() {
+			script = append(script, events.NewTextMessageContentEvent(messageID, delta))
+		}
+		script = append(script, events.NewTextMessageEndEvent(messageID))
+
+		if s.rng.Float64() < s.generator.toolCallProb {
+			script = append(script, s.toolCall(messageID, fmt.Sprintf("%s-tool-%d", runID, m))...)
+		}
+	}
+
+	return append(script, events.NewRunFinishedEvent(threadID, runID))
+}
+
+// contentDeltas splits a random message into deltas of one to three words
+func (s *generatorState) contentDeltas() []string {
+	g := s.generator
+	words := g.minWords + s.rng.Intn(g.maxWords-g.minWords+1)
+
+	var deltas []string
+	for words > 0 {
+		n := min(1+s.rng.Intn(3), words)
+		parts := make([]string, n)
+		for i := range parts {
+			parts[i] = generatorWords[s.rng.Intn(len(generatorWords))]
+		}
+		delta := strings.Join(parts, " ")
+		if len(deltas) > 0 {
+			delta = " " + delta
+		}
+		deltas = append(deltas, delta)
+		words -= n
+	}
+	return deltas
+}
+
+// toolCall scripts a tool call with streamed arguments and its result
+func (s *generatorState) toolCall(messageID, toolCallID string) []events.Event {
+	name := generatorTools[s.rng.Intn(len(generatorTools))]
+	args := fmt.Sprintf(`{"query": "%s", "limit": %d}`, generatorWords[s.rng.Intn(len(generatorWords))], 1+s.rng.Intn(10))
+
+	script := []events.Event{events.NewToolCallStartEvent(toolCallID, name, events.WithParentMessageID(messageID))}
+	for len(args) > 0 {
+		n := min(4+s.rng.Intn(8), len(args))
+		script = append(script, events.NewToolCallArgsEvent(toolCallID, args[:n]))
+		args = args[n:]
+	}
+	script = append(script, events.NewToolCallEndEvent(toolCallID))
+
+	resultID := messageID + "-result"
+	return append(script, events.NewToolCallResultEvent(resultID, toolCallID, fmt.Sprintf(`{"status": "ok", "tool": "%s"}`, name)))
+}
@@ -0,0 +1,154 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// take receives the first n generated events and stops the generator
+func take(t *testing.T, g *SyntheticEventGenerator, rate float64, n int) []events.Event {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream := g.Generate(ctx, rate)
+	received := make([]events.Event, 0, n)
+	for event := range stream {
+		received = append(received, event)
+		if len(received) == n {
+			break
+		}
+	}
+	require.Len(t, received, n)
+
+	cancel()
+	for range stream {
+		// Drain until the generator closes the channel
+	}
+	return received
+}
+
+func summaries(evts []events.Event) []string {
+	out := make([]string, len(evts))
+	for i, event := range evts {
+		out[i] = fmt.Sprint(event)
+	}
+	return out
+}
+
+func TestSyntheticEventGenerator(t *testing.T) {
+	t.Run("ConcurrentRunsAreWellSequenced", func(t *testing.T) {
+		g := NewSyntheticEventGenerator(WithSeed(42), WithRunCount(4), WithToolCallProbability(0.5))
+		received := take(t, g, 0, 500)
+
+		assert.NoError(t, events.ValidateSequenceWithResult(received).Err())
+
+		// Runs are interleaved rather than emitted back to back
+		active, maxActive := 0, 0
+		for _, event := range received {
+			switch event.(type) {
+			case *events.RunStartedEvent:
+				active++
+				maxActive = max(maxActive, active)
+			case *events.RunFinishedEvent:
+				active--
+			}
+		}
+		assert.Equal(t, 4, maxActive)
+	})
+
+	t.Run("SeedIsReproducible", func(t *testing.T) {
+		first := take(t, NewSyntheticEventGenerator(WithSeed(7), WithRunCount(3)), 0, 200)
+		second := take(t, NewSyntheticEventGenerator(WithSeed(7), WithRunCount(3)), 0, 200)
+		other := take(t, NewSyntheticEventGenerator(WithSeed(8), WithRunCount(3)), 0, 200)
+
+		assert.Equal(t, summaries(first), summaries(second))
+		assert.NotEqual(t, summaries(first), summaries(other))
+	})
+
+	t.Run("MessageLength", func(t *testing.T) {
+		g := NewSyntheticEventGenerator(WithSeed(1), WithMessageLength(3, 6))
+		received := take(t, g, 0, 300)
+
+		content := make(map[string]string)
+		completed := 0
+		for _, event := range received {
+			switch e := event.(type) {
+			case *events.TextMessageContentEvent:
+				content[e.MessageID] += e.Delta
+			case *events.TextMessageEndEvent:
+				words := len(strings.Fields(content[e.MessageID]))
+				assert.GreaterOrEqual(t, words, 3)
+				assert.LessOrEqual(t, words, 6)
+				completed++
+			}
+		}
+		assert.Positive(t, completed)
+	})
+
+	t.Run("ToolCallProbability", func(t *testing.T) {
+		count := func(evts []events.Event, eventType events.EventType) int {
+			n := 0
+			for _, event := range evts {
+				if event.Type() == eventType {
+					n++
+				}
+			}
+			return n
+		}
+
+		never := take(t, NewSyntheticEventGenerator(WithSeed(3), WithToolCallProbability(0)), 0, 300)
+		assert.Zero(t, count(never, events.EventTypeToolCallStart))
+
+		always := take(t, NewSyntheticEventGenerator(WithSeed(3), WithToolCallProbability(1)), 0, 300)
+		assembler := events.NewToolCallAssembler()
+		for _, event := range always {
+			call, err := assembler.Feed(event)
+			require.NoError(t, err)
+			if call != nil {
+				assert.NotEmpty(t, call.Args)
+			}
+		}
+		// Every completed message is followed by a tool call
+		assert.InDelta(t, count(always, events.EventTypeTextMessageEnd), count(always, events.EventTypeToolCallStart), 1)
+	})
+
+	t.Run("Rate", func(t *testing.T) {
+		start := time.Now()
+		take(t, NewSyntheticEventGenerator(WithSeed(1)), 200, 20)
+		assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+	})
+
+	t.Run("RateAboveOnePerNanosecond", func(t *testing.T) {
+		// The interval rounds down to zero, which a ticker does not accept
+		assert.Len(t, take(t, NewSyntheticEventGenerator(WithSeed(1)), 2e9, 20), 20)
+	})
+
+	t.Run("StopsOnCancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		stream := NewSyntheticEventGenerator().Generate(ctx, 0)
+		<-stream
+		cancel()
+
+		done := make(chan struct{})
+		go func() {
+			for range stream {
+			}
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("generator did not close its channel after cancellation")
+		}
+	})
+}
@@ -0,0 +1,155 @@
+// Package testutil provides helpers for testing AG-UI clients, such as a mock
+// agent server that streams scripted events.
+package testutil
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding/sse"
+)
+
+// RunAgentInput is the request body accepted by the mock server, mirroring the
+// AG-UI RunAgentInput payload
+type RunAgentInput struct {
+	ThreadID       string           `json:"threadId"`
+	RunID          string           `json:"runId"`
+	State          any              `json:"state,omitempty"`
+	Messages       []events.Message `json:"messages,omitempty"`
+	Tools          []any            `json:"tools,omitempty"`
+	Context        []any            `json:"context,omitempty"`
+	ForwardedProps any              `json:"forwardedProps,omitempty"`
+}
+
+// MockServer is a fake AG-UI agent server built on httptest.Server. Every
+// POST request is answered by streaming the scripted events as SSE.
+type MockServer struct {
+	*httptest.Server
+
+	script          []events.Event
+	delay           time.Duration
+	disconnectAfter int
+	errorAfter      int
+	errorMessage    string
+	writer          *sse.SSEWriter
+
+	mu     sync.Mutex
+	inputs []RunAgentInput
+}
+
+// MockServerOption defines options for creating mock servers
+type MockServerOption func(*MockServer)
+
+// WithEventDelay waits the given duration before sending each event
+func WithEventDelay(delay time.Duration) MockServerOption {
+	return func(s *MockServer) {
+		s.delay = delay
+	}
+}
+
+// WithDisconnectAfter aborts the connection after the given number of events
+// have been sent, without completing the response
+func WithDisconnectAfter(count int) MockServerOption {
+	return func(s *MockServer) {
+		s.disconnectAfter = count
+	}
+}
+
+// WithErrorEvent sends a RUN_ERROR event with the given message after the
+// given number of scripted events, and ends the stream
+func WithErrorEvent(after int, message string) MockServerOption {
+	return func(s *MockServer) {
+		s.errorAfter = after
+		s.errorMessage = message
+	}
+}
+
+// NewMockServer starts a mock server that streams the scripted events. The
+// caller must call Close when done.
+func NewMockServer(script []events.Event, options ...MockServerOption) *MockServer {
+	s := &MockServer{
+		script:          script,
+		disconnectAfter: -1,
+		errorAfter:      -1,
+		writer:          sse.NewSSEWriter().WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	}
+
+	for _, opt := range options {
+		opt(s)
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Inputs returns the run inputs received so far, in order of arrival
+func (s *MockServer) Inputs() []RunAgentInput {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RunAgentInput(nil), s.inputs...)
+}
+
+// handle decodes the run input and streams the scripted events
+func (s *MockServer) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var input RunAgentInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "invalid run input: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.inputs = append(s.inputs, input)
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+	ctx := r.Context()
+	for i := 0; ; i++ {
+		if i == s.disconnectAfter {
+			// Abort the response so the client sees an unexpected disconnect
+			panic(http.ErrAbortHandler)
+		}
+
+		var event events.Event
+		switch {
+		case i == s.errorAfter:
+			event = events.NewRunErrorEvent(s.errorMessage, events.WithRunID(input.RunID))
+		case i < len(s.script):
+			event = s.script[i]
+		default:
+			return
+		}
+
+		if s.delay > 0 {
+			select {
+			case <-time.After(s.delay):
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if err := s.writer.WriteEvent(ctx, w, event); err != nil {
+			return
+		}
+		if i == s.errorAfter {
+			return
+		}
+	}
+}
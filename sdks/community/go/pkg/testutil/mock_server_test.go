@@ -0,0 +1,130 @@
+package testutil
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+func scriptedRun() []events.Event {
+	return []events.Event{
+		events.NewRunStartedEvent("thread-1", "run-1"),
+		events.NewTextMessageStartEvent("msg-1", events.WithRole("assistant")),
+		events.NewTextMessageContentEvent("msg-1", "Hello"),
+		events.NewTextMessageEndEvent("msg-1"),
+		events.NewRunFinishedEvent("thread-1", "run-1"),
+	}
+}
+
+// consume streams a run from the server and returns the decoded events and
+// the stream error, if any
+func consume(t *testing.T, server *MockServer) ([]events.Event, error) {
+	t.Helper()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	client := sse.NewClient(sse.Config{Endpoint: server.URL, BufferSize: 10, Logger: logger})
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	frames, errs, err := client.Stream(sse.StreamOptions{
+		Context: ctx,
+		Payload: RunAgentInput{ThreadID: "thread-1", RunID: "run-1"},
+	})
+	require.NoError(t, err)
+
+	var received []events.Event
+	for frame := range frames {
+		event, err := events.EventFromJSON(frame.Data)
+		require.NoError(t, err)
+		received = append(received, event)
+	}
+	return received, <-errs
+}
+
+func eventTypes(evts []events.Event) []events.EventType {
+	types := make([]events.EventType, len(evts))
+	for i, event := range evts {
+		types[i] = event.Type()
+	}
+	return types
+}
+
+func TestMockServer(t *testing.T) {
+	t.Run("ScriptedRun", func(t *testing.T) {
+		server := NewMockServer(scriptedRun())
+		defer server.Close()
+
+		received, err := consume(t, server)
+		require.NoError(t, err)
+		assert.Equal(t, eventTypes(scriptedRun()), eventTypes(received))
+		assert.NoError(t, events.ValidateSequenceWithResult(received).Err())
+
+		inputs := server.Inputs()
+		require.Len(t, inputs, 1)
+		assert.Equal(t, "run-1", inputs[0].RunID)
+	})
+
+	t.Run("ForcedDisconnect", func(t *testing.T) {
+		server := NewMockServer(scriptedRun(), WithDisconnectAfter(2))
+		defer server.Close()
+
+		received, err := consume(t, server)
+		assert.Error(t, err)
+		assert.Equal(t, []events.EventType{
+			events.EventTypeRunStarted,
+			events.EventTypeTextMessageStart,
+		}, eventTypes(received))
+	})
+
+	t.Run("ErrorEvent", func(t *testing.T) {
+		server := NewMockServer(scriptedRun(), WithErrorEvent(3, "model overloaded"))
+		defer server.Close()
+
+		received, err := consume(t, server)
+		require.NoError(t, err)
+		require.Len(t, received, 4)
+
+		runError, ok := received[3].(*events.RunErrorEvent)
+		require.True(t, ok)
+		assert.Equal(t, "model overloaded", runError.Message)
+		assert.Equal(t, "run-1", runError.RunID())
+	})
+
+	t.Run("EventDelay", func(t *testing.T) {
+		server := NewMockServer(scriptedRun(), WithEventDelay(10*time.Millisecond))
+		defer server.Close()
+
+		start := time.Now()
+		received, err := consume(t, server)
+		require.NoError(t, err)
+		assert.Len(t, received, 5)
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+
+	t.Run("RejectsInvalidRequests", func(t *testing.T) {
+		server := NewMockServer(scriptedRun())
+		defer server.Close()
+
+		resp, err := http.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+		resp, err = http.Post(server.URL, "application/json", nil)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Empty(t, server.Inputs())
+	})
+}
@@ -0,0 +1,143 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	gorilla "github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// WebSocketEventClient connects to a WebSocket event server
+type WebSocketEventClient struct {
+	url        string
+	decoder    *events.EventDecoder
+	dialer     *gorilla.Dialer
+	header     http.Header
+	logger     *logrus.Logger
+	bufferSize int
+
+	mu   sync.Mutex
+	conn *eventConn
+}
+
+// NewWebSocketEventClient creates a new WebSocket event client. A nil decoder
+// uses events.NewEventDecoder(nil).
+func NewWebSocketEventClient(url string, decoder *events.EventDecoder) *WebSocketEventClient {
+	if decoder == nil {
+		decoder = events.NewEventDecoder(nil)
+	}
+
+	return &WebSocketEventClient{
+		url:        url,
+		decoder:    decoder,
+		dialer:     gorilla.DefaultDialer,
+		logger:     logrus.New(),
+		bufferSize: defaultBufferSize,
+	}
+}
+
+// WithDialer sets a custom dialer for the client
+func (c *WebSocketEventClient) WithDialer(dialer *gorilla.Dialer) *WebSocketEventClient {
+	c.dialer = dialer
+	return c
+}
+
+// WithHeader sets additional HTTP headers sent with the handshake request
+func (c *WebSocketEventClient) WithHeader(header http.Header) *WebSocketEventClient {
+	c.header = header
+	return c
+}
+
+// WithLogger sets a custom logger for the client
+func (c *WebSocketEventClient) WithLogger(logger *logrus.Logger) *WebSocketEventClient {
+	c.logger = logger
+	return c
+}
+
+// WithBufferSize sets the capacity of the event channels
+func (c *WebSocketEventClient) WithBufferSize(size int) *WebSocketEventClient {
+	c.bufferSize = size
+	return c
+}
+
+// Connect dials the server and returns a channel of incoming events and a
+// channel for outgoing events. The connection stays open until ctx is
+// cancelled, the outgoing channel is closed, the server closes it, or Close
+// is called. The incoming channel is closed when the connection ends, and
+// Done returns a channel that is closed at the same time.
+func (c *WebSocketEventClient) Connect(ctx context.Context) (<-chan events.Event, chan<- events.Event, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		select {
+		case <-c.conn.done:
+		default:
+			return nil, nil, errors.New("client is already connected")
+		}
+	}
+
+	conn, resp, err := c.dialer.DialContext(ctx, c.url, c.header)
+	if err != nil {
+		if resp != nil {
+			return nil, nil, fmt.Errorf("failed to connect: %w (status %d)", err, resp.StatusCode)
+		}
+		return nil, nil, fmt.Errorf("failed to connect: %w", err)
+	}
+
+	c.logger.WithField("url", c.url).Info("WebSocket connection established")
+
+	c.conn = newEventConn(conn, c.decoder, c.logger, c.bufferSize)
+	c.conn.start(ctx)
+
+	return c.conn.incoming, c.conn.outgoing, nil
+}
+
+// Done returns a channel that is closed when the current connection ends.
+// Once it is closed, events sent on the outgoing channel are no longer
+// delivered, so senders should select on it. Before the first Connect it
+// returns a closed channel.
+func (c *WebSocketEventClient) Done() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return closedChannel
+	}
+	return c.conn.done
+}
+
+// closedChannel is returned by Done when there is no connection
+var closedChannel = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+// Err returns the error that terminated the last connection, if any
+func (c *WebSocketEventClient) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Err()
+}
+
+// Close closes the current connection, if any
+func (c *WebSocketEventClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		c.conn.close(nil)
+	}
+	return nil
+}
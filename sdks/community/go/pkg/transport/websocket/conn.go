@@ -0,0 +1,207 @@
+// Package websocket provides a bidirectional AG-UI event transport over
+// WebSocket connections.
+//
+// Each event is sent as a single text frame containing the event's JSON
+// encoding. The event type is read from the "type" field of the payload;
+// there is no separate event name line as in SSE.
+//
+// WebSocketEventServer and WebSocketEventClient establish connections with
+// gorilla/websocket. Connections established with another library, such as
+// nhooyr.io/websocket, are served by adapting them to Conn and passing them
+// to NewSession.
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	gorilla "github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding/ws"
+)
+
+const (
+	// defaultBufferSize is the default capacity of the incoming and outgoing event channels
+	defaultBufferSize = 100
+
+	// closeGracePeriod bounds how long to wait for the peer to acknowledge a close frame
+	closeGracePeriod = 5 * time.Second
+)
+
+// Frame types of Conn, with the values of the RFC 6455 opcodes as used by
+// gorilla/websocket
+const (
+	TextMessage   = ws.TextMessage
+	BinaryMessage = ws.BinaryMessage
+)
+
+// Conn is a WebSocket connection carrying whole messages. *websocket.Conn
+// from gorilla/websocket satisfies it. Adapters for other libraries should
+// return io.EOF from ReadMessage once the peer has closed the connection
+// normally, and perform the closing handshake in Close.
+type Conn interface {
+	// FrameReader blocks in ReadMessage until the next message arrives and
+	// returns its frame type and payload
+	ws.FrameReader
+
+	// WriteMessage sends a message of the given frame type
+	WriteMessage(messageType int, data []byte) error
+
+	// Close closes the connection
+	Close() error
+}
+
+// closeHandshaker is implemented by connections, such as gorilla's, whose
+// Close does not perform the closing handshake
+type closeHandshaker interface {
+	WriteControl(messageType int, data []byte, deadline time.Time) error
+	SetReadDeadline(deadline time.Time) error
+}
+
+// eventConn pumps events between a WebSocket connection and a pair of channels
+type eventConn struct {
+	conn     Conn
+	decoder  *ws.WSDecoder
+	logger   *logrus.Logger
+	incoming chan events.Event
+	outgoing chan events.Event
+	done     chan struct{}
+
+	// readCtx is the context of the read loop, which stopReading cancels
+	// when the connection closes
+	readCtx     context.Context
+	stopReading context.CancelFunc
+
+	closeOnce sync.Once
+	mu        sync.Mutex
+	err       error
+}
+
+// newEventConn wraps an established WebSocket connection
+func newEventConn(conn Conn, decoder *events.EventDecoder, logger *logrus.Logger, bufferSize int) *eventConn {
+	readCtx, stopReading := context.WithCancel(context.Background())
+	return &eventConn{
+		conn: conn,
+		// A bad frame from the peer is logged and skipped rather than
+		// ending a long-lived connection
+		decoder: ws.NewWSDecoder().
+			WithEventDecoder(decoder).
+			WithFrameErrorHandler(func(_ []byte, err error) error {
+				logger.WithError(err).Warn("Failed to decode WebSocket frame")
+				return nil
+			}),
+		logger:      logger,
+		incoming:    make(chan events.Event, bufferSize),
+		outgoing:    make(chan events.Event, bufferSize),
+		done:        make(chan struct{}),
+		readCtx:     readCtx,
+		stopReading: stopReading,
+	}
+}
+
+// start launches the read and write pumps. The connection is closed when ctx
+// is cancelled, when the peer closes it, or when the outgoing channel is closed.
+func (c *eventConn) start(ctx context.Context) {
+	go c.readLoop()
+	go c.writeLoop(ctx)
+}
+
+// readLoop decodes incoming text frames into events with a ws.WSDecoder until
+// the connection fails. Frames that cannot be decoded are skipped.
+func (c *eventConn) readLoop() {
+	defer close(c.incoming)
+
+	err := c.decoder.DecodeStream(c.readCtx, c.conn, c.incoming)
+	if err != nil && (c.readCtx.Err() != nil || isNormalClosure(err)) {
+		err = nil
+	}
+	c.close(err)
+}
+
+// writeLoop encodes outgoing events as text frames
+func (c *eventConn) writeLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			c.close(ctx.Err())
+			return
+
+		case <-c.done:
+			return
+
+		case event, ok := <-c.outgoing:
+			if !ok {
+				c.sendClose()
+				return
+			}
+
+			data, err := event.ToJSON()
+			if err != nil {
+				c.logger.WithError(err).WithField("event_type", event.Type()).Error("Failed to encode event")
+				continue
+			}
+
+			if err := c.conn.WriteMessage(TextMessage, data); err != nil {
+				c.close(fmt.Errorf("write failed: %w", err))
+				return
+			}
+		}
+	}
+}
+
+// sendClose starts the closing handshake. The read loop finishes closing the
+// connection once the peer acknowledges, or after the grace period.
+// Connections that perform the handshake in Close are closed directly.
+func (c *eventConn) sendClose() {
+	handshaker, ok := c.conn.(closeHandshaker)
+	if !ok {
+		c.close(nil)
+		return
+	}
+
+	msg := gorilla.FormatCloseMessage(gorilla.CloseNormalClosure, "")
+	deadline := time.Now().Add(closeGracePeriod)
+	if err := handshaker.WriteControl(gorilla.CloseMessage, msg, deadline); err != nil {
+		c.close(nil)
+		return
+	}
+	_ = handshaker.SetReadDeadline(deadline)
+}
+
+// isNormalClosure reports whether a read error means that the peer closed the
+// connection normally
+func isNormalClosure(err error) bool {
+	if errors.Is(err, io.EOF) {
+		return true
+	}
+	var closeErr *gorilla.CloseError
+	return errors.As(err, &closeErr) &&
+		(closeErr.Code == gorilla.CloseNormalClosure || closeErr.Code == gorilla.CloseGoingAway)
+}
+
+// close closes the connection once, recording the terminal error if any
+func (c *eventConn) close(err error) {
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		c.err = err
+		c.mu.Unlock()
+
+		close(c.done)
+		c.stopReading()
+		_ = c.conn.Close()
+	})
+}
+
+// Err returns the error that terminated the connection, or nil if it was
+// closed normally or is still open
+func (c *eventConn) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
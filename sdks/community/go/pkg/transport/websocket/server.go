@@ -0,0 +1,142 @@
+package websocket
+
+import (
+	"context"
+	"net/http"
+
+	gorilla "github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// WebSocketEventServer is an http.Handler that upgrades requests to WebSocket
+// connections and exposes each connection as a Session
+type WebSocketEventServer struct {
+	upgrader   *gorilla.Upgrader
+	decoder    *events.EventDecoder
+	logger     *logrus.Logger
+	bufferSize int
+	sessions   chan *Session
+}
+
+// NewWebSocketEventServer creates a new WebSocket event server. A nil upgrader
+// uses gorilla's defaults and a nil decoder uses events.NewEventDecoder(nil).
+func NewWebSocketEventServer(upgrader *gorilla.Upgrader, decoder *events.EventDecoder) *WebSocketEventServer {
+	if upgrader == nil {
+		upgrader = &gorilla.Upgrader{}
+	}
+	if decoder == nil {
+		decoder = events.NewEventDecoder(nil)
+	}
+
+	return &WebSocketEventServer{
+		upgrader:   upgrader,
+		decoder:    decoder,
+		logger:     logrus.New(),
+		bufferSize: defaultBufferSize,
+		sessions:   make(chan *Session),
+	}
+}
+
+// WithLogger sets a custom logger for the server
+func (s *WebSocketEventServer) WithLogger(logger *logrus.Logger) *WebSocketEventServer {
+	s.logger = logger
+	return s
+}
+
+// WithBufferSize sets the capacity of each session's event channels
+func (s *WebSocketEventServer) WithBufferSize(size int) *WebSocketEventServer {
+	s.bufferSize = size
+	return s
+}
+
+// Sessions returns the channel on which newly upgraded connections are
+// delivered. Each request blocks until its session is received.
+func (s *WebSocketEventServer) Sessions() <-chan *Session {
+	return s.sessions
+}
+
+// ServeHTTP upgrades the request and serves the resulting session until the
+// connection is closed
+func (s *WebSocketEventServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already written an HTTP error response
+		s.logger.WithError(err).Warn("WebSocket upgrade failed")
+		return
+	}
+
+	session := &Session{
+		eventConn: newEventConn(conn, s.decoder, s.logger, s.bufferSize),
+		request:   r,
+	}
+	session.start(r.Context())
+
+	select {
+	case s.sessions <- session:
+	case <-session.Done():
+		return
+	case <-r.Context().Done():
+		session.close(r.Context().Err())
+		return
+	}
+
+	s.logger.WithField("remote_addr", r.RemoteAddr).Info("WebSocket session established")
+	<-session.Done()
+	s.logger.WithField("remote_addr", r.RemoteAddr).Info("WebSocket session closed")
+}
+
+// Session is a single server-side WebSocket connection
+type Session struct {
+	*eventConn
+	request *http.Request
+}
+
+// NewSession serves an established connection as a session, for connections
+// that were not accepted by a WebSocketEventServer, such as those of another
+// WebSocket library adapted to Conn. The session ends when ctx is cancelled,
+// when the peer closes the connection, or when the outgoing channel is
+// closed. A nil decoder uses events.NewEventDecoder(nil) and a nil logger
+// logrus.New().
+func NewSession(ctx context.Context, conn Conn, decoder *events.EventDecoder, logger *logrus.Logger) *Session {
+	if decoder == nil {
+		decoder = events.NewEventDecoder(nil)
+	}
+	if logger == nil {
+		logger = logrus.New()
+	}
+
+	session := &Session{eventConn: newEventConn(conn, decoder, logger, defaultBufferSize)}
+	session.start(ctx)
+	return session
+}
+
+// Request returns the HTTP request that opened the session, or nil if the
+// session was created with NewSession
+func (s *Session) Request() *http.Request {
+	return s.request
+}
+
+// Incoming returns the channel of events received from the client. It is
+// closed when the connection ends.
+func (s *Session) Incoming() <-chan events.Event {
+	return s.incoming
+}
+
+// Outgoing returns the channel used to send events to the client. Closing it
+// closes the connection.
+func (s *Session) Outgoing() chan<- events.Event {
+	return s.outgoing
+}
+
+// Done returns a channel that is closed when the connection ends
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close closes the connection immediately
+func (s *Session) Close() error {
+	s.close(nil)
+	return nil
+}
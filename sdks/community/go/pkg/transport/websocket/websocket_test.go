@@ -0,0 +1,258 @@
+package websocket
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gorilla "github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+func quietLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	return logger
+}
+
+func newTestServer(t *testing.T) (*WebSocketEventServer, string) {
+	t.Helper()
+
+	server := NewWebSocketEventServer(nil, nil).WithLogger(quietLogger())
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	return server, "ws" + strings.TrimPrefix(httpServer.URL, "http")
+}
+
+func receive(t *testing.T, ch <-chan events.Event) events.Event {
+	t.Helper()
+
+	select {
+	case event, ok := <-ch:
+		require.True(t, ok, "channel closed unexpectedly")
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for event")
+		return nil
+	}
+}
+
+// pipeConn is an in-memory Conn standing in for a connection of another
+// WebSocket library
+type pipeConn struct {
+	reads     chan []byte
+	writes    chan []byte
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newPipeConn() *pipeConn {
+	return &pipeConn{
+		reads:  make(chan []byte),
+		writes: make(chan []byte, 10),
+		closed: make(chan struct{}),
+	}
+}
+
+func (c *pipeConn) ReadMessage() (int, []byte, error) {
+	select {
+	case data := <-c.reads:
+		return TextMessage, data, nil
+	case <-c.closed:
+		return 0, nil, io.EOF
+	}
+}
+
+func (c *pipeConn) WriteMessage(_ int, data []byte) error {
+	c.writes <- data
+	return nil
+}
+
+func (c *pipeConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return nil
+}
+
+func TestWebSocketTransport(t *testing.T) {
+	t.Run("Bidirectional", func(t *testing.T) {
+		server, url := newTestServer(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		client := NewWebSocketEventClient(url, nil).WithLogger(quietLogger())
+		incoming, outgoing, err := client.Connect(ctx)
+		require.NoError(t, err)
+
+		var session *Session
+		select {
+		case session = <-server.Sessions():
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for session")
+		}
+
+		// Client to server, e.g. a tool result submission
+		outgoing <- events.NewToolCallResultEvent("msg-1", "tool-1", "42")
+		received := receive(t, session.Incoming())
+		result, ok := received.(*events.ToolCallResultEvent)
+		require.True(t, ok)
+		assert.Equal(t, "tool-1", result.ToolCallID)
+		assert.Equal(t, "42", result.Content)
+
+		// Server to client
+		session.Outgoing() <- events.NewTextMessageContentEvent("msg-2", "Hello")
+		received = receive(t, incoming)
+		content, ok := received.(*events.TextMessageContentEvent)
+		require.True(t, ok)
+		assert.Equal(t, "Hello", content.Delta)
+
+		// Closing the client's outgoing channel closes the connection
+		close(outgoing)
+		select {
+		case <-session.Done():
+		case <-time.After(2 * time.Second):
+			t.Fatal("session was not closed")
+		}
+		assert.NoError(t, session.Err())
+	})
+
+	t.Run("SkipsUndecodableFrames", func(t *testing.T) {
+		server, url := newTestServer(t)
+
+		conn, _, err := gorilla.DefaultDialer.Dial(url, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		session := <-server.Sessions()
+
+		require.NoError(t, conn.WriteMessage(gorilla.TextMessage, []byte(`{"delta":"no type"}`)))
+		require.NoError(t, conn.WriteMessage(gorilla.TextMessage, []byte(`not json`)))
+		require.NoError(t, conn.WriteMessage(gorilla.TextMessage,
+			[]byte(`{"type":"RUN_STARTED","threadId":"t1","runId":"r1"}`)))
+
+		received := receive(t, session.Incoming())
+		started, ok := received.(*events.RunStartedEvent)
+		require.True(t, ok)
+		assert.Equal(t, "r1", started.RunID())
+	})
+
+	t.Run("ContextCancellationClosesConnection", func(t *testing.T) {
+		server, url := newTestServer(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		client := NewWebSocketEventClient(url, nil).WithLogger(quietLogger())
+		incoming, _, err := client.Connect(ctx)
+		require.NoError(t, err)
+
+		session := <-server.Sessions()
+		cancel()
+
+		select {
+		case _, ok := <-incoming:
+			assert.False(t, ok)
+		case <-time.After(2 * time.Second):
+			t.Fatal("incoming channel was not closed")
+		}
+
+		select {
+		case <-session.Done():
+		case <-time.After(2 * time.Second):
+			t.Fatal("session was not closed")
+		}
+		assert.ErrorIs(t, client.Err(), context.Canceled)
+	})
+
+	t.Run("DisconnectBeforeSessionIsReceived", func(t *testing.T) {
+		server := NewWebSocketEventServer(nil, nil).WithLogger(quietLogger())
+		returned := make(chan struct{})
+		httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer close(returned)
+			server.ServeHTTP(w, r)
+		}))
+		t.Cleanup(httpServer.Close)
+
+		conn, _, err := gorilla.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http"), nil)
+		require.NoError(t, err)
+		require.NoError(t, conn.Close())
+
+		// Nobody reads Sessions, so only the disconnect ends the handler
+		select {
+		case <-returned:
+		case <-time.After(2 * time.Second):
+			t.Fatal("handler did not return after the client disconnected")
+		}
+	})
+
+	t.Run("ClientDone", func(t *testing.T) {
+		server, url := newTestServer(t)
+
+		client := NewWebSocketEventClient(url, nil).WithLogger(quietLogger())
+		select {
+		case <-client.Done():
+		default:
+			t.Fatal("Done must be closed before Connect")
+		}
+
+		_, _, err := client.Connect(context.Background())
+		require.NoError(t, err)
+		session := <-server.Sessions()
+
+		select {
+		case <-client.Done():
+			t.Fatal("Done closed while connected")
+		default:
+		}
+
+		require.NoError(t, session.Close())
+		select {
+		case <-client.Done():
+		case <-time.After(2 * time.Second):
+			t.Fatal("Done was not closed after the server closed the connection")
+		}
+	})
+
+	t.Run("CustomConn", func(t *testing.T) {
+		conn := newPipeConn()
+		session := NewSession(context.Background(), conn, nil, quietLogger())
+
+		conn.reads <- []byte(`{"type":"RUN_STARTED","threadId":"t1","runId":"r1"}`)
+		started, ok := receive(t, session.Incoming()).(*events.RunStartedEvent)
+		require.True(t, ok)
+		assert.Equal(t, "r1", started.RunID())
+		assert.Nil(t, session.Request())
+
+		session.Outgoing() <- events.NewStepStartedEvent("plan")
+		select {
+		case data := <-conn.writes:
+			assert.Contains(t, string(data), `"type":"STEP_STARTED"`)
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for write")
+		}
+
+		// Closing the outgoing channel closes a connection without a
+		// separate closing handshake directly
+		close(session.Outgoing())
+		select {
+		case <-session.Done():
+		case <-time.After(2 * time.Second):
+			t.Fatal("session was not closed")
+		}
+		assert.NoError(t, session.Err())
+	})
+
+	t.Run("ConnectFailure", func(t *testing.T) {
+		client := NewWebSocketEventClient("ws://127.0.0.1:1/events", nil).WithLogger(quietLogger())
+		_, _, err := client.Connect(context.Background())
+		assert.Error(t, err)
+	})
+}